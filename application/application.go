@@ -38,6 +38,7 @@ func (app Application) Boot() {
 
 	viron.Print(app.env, vironCompatibleLogger{session})
 
+	app.ConfigureMetrics()
 	app.ConfigureSMTP(session)
 	app.RetrieveUAAPublicKey(session)
 
@@ -46,6 +47,7 @@ func (app Application) Boot() {
 	app.StartQueueGauge()
 	app.StartWorkers()
 	app.StartMessageGC()
+	app.StartCacheSweeper()
 	app.StartServer(session)
 }
 
@@ -78,6 +80,12 @@ func (app Application) ConfigureSMTP(logger lager.Logger) {
 	}
 }
 
+func (app Application) ConfigureMetrics() {
+	if !app.env.MetricsLogEnabled {
+		metrics.DefaultLogger = nil
+	}
+}
+
 func (app Application) RetrieveUAAPublicKey(logger lager.Logger) {
 	zonedUAAClient := uaa.NewZonedUAAClient(app.env.UAAClientID, app.env.UAAClientSecret, app.env.VerifySSL, "")
 
@@ -116,6 +124,9 @@ func (app Application) StartWorkers() {
 		Domain:               app.env.Domain,
 		QueueWaitMaxDuration: app.env.GobbleWaitMaxDuration,
 		CCHost:               app.env.CCHost,
+		CCAPIVersion:         app.env.CCAPIVersion,
+		DefaultUAAScopes:     app.env.DefaultUAAScopes,
+		LookupCache:          app.mother.LookupCache(),
 	})
 }
 
@@ -130,11 +141,26 @@ func (app Application) StartMessageGC() {
 	messageGC.Run()
 }
 
+func (app Application) StartCacheSweeper() {
+	lookupCache := app.mother.LookupCache()
+	logger := app.mother.Logger().Session("cache-sweeper")
+
+	go func() {
+		for range time.Tick(time.Minute) {
+			err := lookupCache.Sweep()
+			if err != nil {
+				logger.Error("sweep-failed", err)
+			}
+		}
+	}()
+}
+
 func (app Application) StartServer(logger lager.Logger) {
 	web.NewServer().Run(app.mother, web.Config{
 		DBLoggingEnabled:     app.env.DBLoggingEnabled,
 		SkipVerifySSL:        !app.env.VerifySSL,
 		Port:                 app.env.Port,
+		MetricsPort:          app.env.MetricsPort,
 		Logger:               logger,
 		CORSOrigin:           app.env.CORSOrigin,
 		SQLDB:                app.mother.SQLDatabase(),
@@ -146,6 +172,11 @@ func (app Application) StartServer(logger lager.Logger) {
 		UAAClientSecret:  app.env.UAAClientSecret,
 		DefaultUAAScopes: app.env.DefaultUAAScopes,
 		CCHost:           app.env.CCHost,
+		CCAPIVersion:     app.env.CCAPIVersion,
+		EncryptionKey:    app.env.EncryptionKey,
+		LookupCache:      app.mother.LookupCache(),
+
+		PreferencesTemplateID: app.env.PreferencesTemplateID,
 	})
 }
 
@@ -21,6 +21,7 @@ var SMTPAuthMechanisms = []string{SMTPAuthNone, SMTPAuthPlain, SMTPAuthCRAMMD5}
 var UAAPublicKey string
 
 type Environment struct {
+	CCAPIVersion          int    `env:"CC_API_VERSION"           env-default:"2"`
 	CCHost                string `env:"CC_HOST"                  env-required:"true"`
 	CORSOrigin            string `env:"CORS_ORIGIN"              env-default:"*"`
 	DBLoggingEnabled      bool   `env:"DB_LOGGING_ENABLED"`
@@ -30,7 +31,13 @@ type Environment struct {
 	Domain                string `env:"DOMAIN"                   env-required:"true"`
 	EncryptionKey         []byte `env:"ENCRYPTION_KEY"           env-required:"true"`
 	GobbleWaitMaxDuration int    `env:"GOBBLE_WAIT_MAX_DURATION" env-default:"5000"`
+	LookupCacheShared     bool   `env:"LOOKUP_CACHE_SHARED"      env-default:"false"`
+	LookupCacheSize       int    `env:"LOOKUP_CACHE_SIZE"        env-default:"10000"`
+	LookupCacheTTL        int    `env:"LOOKUP_CACHE_TTL"         env-default:"60"`
+	MetricsLogEnabled     bool   `env:"METRICS_LOG_ENABLED"      env-default:"true"`
+	MetricsPort           int    `env:"METRICS_PORT"             env-default:"0"`
 	Port                  int    `env:"PORT"                     env-default:"3000"`
+	PreferencesTemplateID string `env:"PREFERENCES_TEMPLATE_ID"`
 	RootPath              string `env:"ROOT_PATH"`
 	SMTPAuthMechanism     string `env:"SMTP_AUTH_MECHANISM"      env-required:"true"`
 	SMTPCRAMMD5Secret     string `env:"SMTP_CRAMMD5_SECRET"`
@@ -83,6 +90,11 @@ func NewEnvironment() (Environment, error) {
 		return env, EnvironmentError{err}
 	}
 
+	err = env.validateCCAPIVersion()
+	if err != nil {
+		return env, EnvironmentError{err}
+	}
+
 	env.inferMigrationsDirs()
 	env.parseDefaultUAAScopes()
 
@@ -128,3 +140,11 @@ func (env *Environment) validateSMTPAuthMechanism() error {
 
 	return fmt.Errorf("Could not parse SMTP_AUTH_MECHANISM %q, it is not one of the allowed values: %+v", env.SMTPAuthMechanism, SMTPAuthMechanisms)
 }
+
+func (env *Environment) validateCCAPIVersion() error {
+	if env.CCAPIVersion == 2 || env.CCAPIVersion == 3 {
+		return nil
+	}
+
+	return fmt.Errorf("Could not parse CC_API_VERSION %d, it is not one of the allowed values: [2 3]", env.CCAPIVersion)
+}
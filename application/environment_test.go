@@ -14,6 +14,7 @@ import (
 var _ = Describe("Environment", func() {
 	var variables = map[string]string{}
 	var envVars = []string{
+		"CC_API_VERSION",
 		"CC_HOST",
 		"CORS_ORIGIN",
 		"DATABASE_URL",
@@ -23,6 +24,11 @@ var _ = Describe("Environment", func() {
 		"DOMAIN",
 		"ENCRYPTION_KEY",
 		"GOBBLE_WAIT_MAX_DURATION",
+		"LOOKUP_CACHE_SHARED",
+		"LOOKUP_CACHE_SIZE",
+		"LOOKUP_CACHE_TTL",
+		"METRICS_LOG_ENABLED",
+		"METRICS_PORT",
 		"PORT",
 		"ROOT_PATH",
 		"SENDER",
@@ -475,6 +481,93 @@ var _ = Describe("Environment", func() {
 		})
 	})
 
+	Describe("CC API version", func() {
+		It("sets the value if present", func() {
+			os.Setenv("CC_API_VERSION", "3")
+
+			env, err := application.NewEnvironment()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env.CCAPIVersion).To(Equal(3))
+		})
+
+		It("defaults to 2", func() {
+			os.Setenv("CC_API_VERSION", "")
+
+			env, err := application.NewEnvironment()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env.CCAPIVersion).To(Equal(2))
+		})
+
+		It("errors if it is not a supported version", func() {
+			os.Setenv("CC_API_VERSION", "4")
+
+			_, err := application.NewEnvironment()
+			Expect(err).To(MatchError(application.EnvironmentError{errors.New("Could not parse CC_API_VERSION 4, it is not one of the allowed values: [2 3]")}))
+		})
+	})
+
+	Describe("Lookup cache", func() {
+		It("sets the values if present", func() {
+			os.Setenv("LOOKUP_CACHE_TTL", "300")
+			os.Setenv("LOOKUP_CACHE_SHARED", "true")
+			os.Setenv("LOOKUP_CACHE_SIZE", "500")
+
+			env, err := application.NewEnvironment()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env.LookupCacheTTL).To(Equal(300))
+			Expect(env.LookupCacheShared).To(BeTrue())
+			Expect(env.LookupCacheSize).To(Equal(500))
+		})
+
+		It("defaults to an unshared cache of 10000 entries with a 60 second TTL", func() {
+			os.Setenv("LOOKUP_CACHE_TTL", "")
+			os.Setenv("LOOKUP_CACHE_SHARED", "")
+			os.Setenv("LOOKUP_CACHE_SIZE", "")
+
+			env, err := application.NewEnvironment()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env.LookupCacheTTL).To(Equal(60))
+			Expect(env.LookupCacheShared).To(BeFalse())
+			Expect(env.LookupCacheSize).To(Equal(10000))
+		})
+	})
+
+	Describe("Metrics log", func() {
+		It("sets the value if present", func() {
+			os.Setenv("METRICS_LOG_ENABLED", "false")
+
+			env, err := application.NewEnvironment()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env.MetricsLogEnabled).To(BeFalse())
+		})
+
+		It("defaults to logging metrics", func() {
+			os.Setenv("METRICS_LOG_ENABLED", "")
+
+			env, err := application.NewEnvironment()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env.MetricsLogEnabled).To(BeTrue())
+		})
+	})
+
+	Describe("Metrics port", func() {
+		It("sets the value if present", func() {
+			os.Setenv("METRICS_PORT", "9090")
+
+			env, err := application.NewEnvironment()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env.MetricsPort).To(Equal(9090))
+		})
+
+		It("defaults to not serving metrics", func() {
+			os.Setenv("METRICS_PORT", "")
+
+			env, err := application.NewEnvironment()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env.MetricsPort).To(Equal(0))
+		})
+	})
+
 	Describe("Default UAA scopes", func() {
 		It("sets the value if present", func() {
 			os.Setenv("DEFAULT_UAA_SCOPES", "my-scope,banana,foo,bar")
@@ -9,18 +9,22 @@ import (
 	"sync"
 	"time"
 
+	"github.com/cloudfoundry-incubator/notifications/cache"
 	"github.com/cloudfoundry-incubator/notifications/db"
 	"github.com/cloudfoundry-incubator/notifications/gobble"
 	"github.com/cloudfoundry-incubator/notifications/mail"
+	"github.com/cloudfoundry-incubator/notifications/metrics"
 	"github.com/cloudfoundry-incubator/notifications/util"
 	v1models "github.com/cloudfoundry-incubator/notifications/v1/models"
 	"github.com/pivotal-golang/lager"
 )
 
 type Mother struct {
-	sqlDB *sql.DB
-	mutex sync.Mutex
-	env   Environment
+	sqlDB       *sql.DB
+	mutex       sync.Mutex
+	lookupCache *cache.Cache
+	cacheMutex  sync.Mutex
+	env         Environment
 }
 
 func NewMother(env Environment) *Mother {
@@ -109,3 +113,26 @@ func (m *Mother) Database() db.DatabaseInterface {
 func (m *Mother) MessagesRepo() v1models.MessagesRepo {
 	return v1models.NewMessagesRepo(util.NewIDGenerator(rand.Reader).Generate)
 }
+
+func (m *Mother) LookupCache() cache.Cache {
+	m.cacheMutex.Lock()
+	defer m.cacheMutex.Unlock()
+
+	if m.lookupCache != nil {
+		return *m.lookupCache
+	}
+
+	clock := util.NewClock()
+
+	var store cache.Store
+	if m.env.LookupCacheShared {
+		store = cache.NewDatabaseStore(m.SQLDatabase(), clock)
+	} else {
+		store = cache.NewMemoryStore(clock, m.env.LookupCacheSize)
+	}
+
+	lookupCache := cache.NewCache(store, time.Duration(m.env.LookupCacheTTL)*time.Second, clock, metrics.NewEmitter(metrics.DefaultLogger))
+	m.lookupCache = &lookupCache
+
+	return lookupCache
+}
@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type Store interface {
+	Get(key string) ([]byte, bool, error)
+	Set(key string, value []byte, expiresAt time.Time) error
+	Sweep() error
+	Flush() error
+}
+
+type clock interface {
+	Now() time.Time
+}
+
+type metricsEmitter interface {
+	Increment(counter string)
+}
+
+type Cache struct {
+	store   Store
+	ttl     time.Duration
+	clock   clock
+	emitter metricsEmitter
+}
+
+func NewCache(store Store, ttl time.Duration, clock clock, emitter metricsEmitter) Cache {
+	return Cache{
+		store:   store,
+		ttl:     ttl,
+		clock:   clock,
+		emitter: emitter,
+	}
+}
+
+// Get decodes the cached value for key into value. Any failure to read from
+// the store is treated as a miss so that callers fall back to the origin.
+func (c Cache) Get(key string, value interface{}) bool {
+	if c.ttl <= 0 {
+		return false
+	}
+
+	data, found, err := c.store.Get(key)
+	if err != nil {
+		c.emitter.Increment("notifications.cache.error")
+		return false
+	}
+
+	if !found {
+		c.emitter.Increment("notifications.cache.miss")
+		return false
+	}
+
+	if err := json.Unmarshal(data, value); err != nil {
+		c.emitter.Increment("notifications.cache.error")
+		return false
+	}
+
+	c.emitter.Increment("notifications.cache.hit")
+	return true
+}
+
+func (c Cache) Set(key string, value interface{}) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		c.emitter.Increment("notifications.cache.error")
+		return
+	}
+
+	if err := c.store.Set(key, data, c.clock.Now().Add(c.ttl)); err != nil {
+		c.emitter.Increment("notifications.cache.error")
+	}
+}
+
+func (c Cache) Flush() error {
+	if c.store == nil {
+		return nil
+	}
+
+	return c.store.Flush()
+}
+
+// Sweep removes the expired entries from the store.
+func (c Cache) Sweep() error {
+	if c.store == nil {
+		return nil
+	}
+
+	return c.store.Sweep()
+}
@@ -0,0 +1,146 @@
+package cache_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/cache"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cache", func() {
+	type thing struct {
+		Name string
+	}
+
+	var (
+		lookupCache cache.Cache
+		store       *mocks.CacheStore
+		clock       *mocks.Clock
+		emitter     *mocks.MetricsEmitter
+	)
+
+	BeforeEach(func() {
+		store = mocks.NewCacheStore()
+		clock = mocks.NewClock()
+		clock.NowCall.Returns.Time = time.Date(2015, 1, 1, 12, 0, 0, 0, time.UTC)
+		emitter = mocks.NewMetricsEmitter()
+
+		lookupCache = cache.NewCache(store, 5*time.Minute, clock, emitter)
+	})
+
+	Describe("Get", func() {
+		It("decodes the stored value and records a hit", func() {
+			store.GetCall.Returns.Value = []byte(`{"Name":"some-thing"}`)
+			store.GetCall.Returns.Found = true
+
+			var value thing
+			Expect(lookupCache.Get("some-key", &value)).To(BeTrue())
+			Expect(value).To(Equal(thing{Name: "some-thing"}))
+
+			Expect(store.GetCall.Receives.Key).To(Equal("some-key"))
+			Expect(emitter.IncrementCall.Receives.Counter).To(Equal("notifications.cache.hit"))
+		})
+
+		It("records a miss when the key is not stored", func() {
+			var value thing
+			Expect(lookupCache.Get("some-key", &value)).To(BeFalse())
+			Expect(emitter.IncrementCall.Receives.Counter).To(Equal("notifications.cache.miss"))
+		})
+
+		Context("when the store errors", func() {
+			It("treats the lookup as a miss", func() {
+				store.GetCall.Returns.Error = errors.New("store is down")
+
+				var value thing
+				Expect(lookupCache.Get("some-key", &value)).To(BeFalse())
+				Expect(emitter.IncrementCall.Receives.Counter).To(Equal("notifications.cache.error"))
+			})
+		})
+
+		Context("when the stored value cannot be decoded", func() {
+			It("treats the lookup as a miss", func() {
+				store.GetCall.Returns.Value = []byte(`%%%`)
+				store.GetCall.Returns.Found = true
+
+				var value thing
+				Expect(lookupCache.Get("some-key", &value)).To(BeFalse())
+				Expect(emitter.IncrementCall.Receives.Counter).To(Equal("notifications.cache.error"))
+			})
+		})
+
+		Context("when the cache is disabled", func() {
+			It("never reads from the store", func() {
+				lookupCache = cache.NewCache(store, 0, clock, emitter)
+
+				var value thing
+				Expect(lookupCache.Get("some-key", &value)).To(BeFalse())
+				Expect(store.GetCall.Receives.Key).To(BeEmpty())
+				Expect(emitter.IncrementCall.Receives.Counter).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("Set", func() {
+		It("stores the encoded value with an expiry of now plus the ttl", func() {
+			lookupCache.Set("some-key", thing{Name: "some-thing"})
+
+			Expect(store.SetCall.Receives.Key).To(Equal("some-key"))
+			Expect(store.SetCall.Receives.Value).To(MatchJSON(`{"Name":"some-thing"}`))
+			Expect(store.SetCall.Receives.ExpiresAt).To(Equal(time.Date(2015, 1, 1, 12, 5, 0, 0, time.UTC)))
+		})
+
+		It("records an error when the store fails", func() {
+			store.SetCall.Returns.Error = errors.New("store is down")
+
+			lookupCache.Set("some-key", thing{Name: "some-thing"})
+			Expect(emitter.IncrementCall.Receives.Counter).To(Equal("notifications.cache.error"))
+		})
+
+		Context("when the cache is disabled", func() {
+			It("does not write to the store", func() {
+				lookupCache = cache.NewCache(store, 0, clock, emitter)
+
+				lookupCache.Set("some-key", thing{Name: "some-thing"})
+				Expect(store.SetCall.WasCalled).To(BeFalse())
+			})
+		})
+	})
+
+	Describe("Flush", func() {
+		It("flushes the store", func() {
+			Expect(lookupCache.Flush()).To(Succeed())
+			Expect(store.FlushCall.WasCalled).To(BeTrue())
+		})
+
+		It("returns store errors", func() {
+			store.FlushCall.Returns.Error = errors.New("store is down")
+
+			Expect(lookupCache.Flush()).To(MatchError(errors.New("store is down")))
+		})
+
+		It("does nothing for an unconfigured cache", func() {
+			Expect(cache.Cache{}.Flush()).To(Succeed())
+		})
+	})
+
+	Describe("Sweep", func() {
+		It("sweeps the store", func() {
+			Expect(lookupCache.Sweep()).To(Succeed())
+			Expect(store.SweepCall.CallCount).To(Equal(1))
+		})
+
+		It("returns store errors", func() {
+			store.SweepCall.Returns.Error = errors.New("store is down")
+
+			Expect(lookupCache.Sweep()).To(MatchError(errors.New("store is down")))
+		})
+
+		It("does nothing for an unconfigured cache", func() {
+			Expect(cache.Cache{}.Sweep()).To(Succeed())
+		})
+	})
+})
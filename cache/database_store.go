@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"database/sql"
+	"time"
+)
+
+// DatabaseStore keeps entries in the lookup_cache table so that every
+// instance of the application shares the same cached lookups.
+type DatabaseStore struct {
+	db    *sql.DB
+	clock clock
+}
+
+func NewDatabaseStore(db *sql.DB, clock clock) DatabaseStore {
+	return DatabaseStore{
+		db:    db,
+		clock: clock,
+	}
+}
+
+func (s DatabaseStore) Get(key string) ([]byte, bool, error) {
+	var value []byte
+	err := s.db.QueryRow("SELECT `value` FROM `lookup_cache` WHERE `key` = ? AND `expires_at` > ?", key, s.clock.Now().UTC()).Scan(&value)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, false, nil
+		}
+
+		return nil, false, err
+	}
+
+	return value, true, nil
+}
+
+func (s DatabaseStore) Set(key string, value []byte, expiresAt time.Time) error {
+	_, err := s.db.Exec("INSERT INTO `lookup_cache` (`key`, `value`, `expires_at`) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE `value` = VALUES(`value`), `expires_at` = VALUES(`expires_at`)", key, value, expiresAt.UTC())
+	return err
+}
+
+// Sweep deletes every expired entry, which Get already ignores.
+func (s DatabaseStore) Sweep() error {
+	_, err := s.db.Exec("DELETE FROM `lookup_cache` WHERE `expires_at` <= ?", s.clock.Now().UTC())
+	return err
+}
+
+func (s DatabaseStore) Flush() error {
+	_, err := s.db.Exec("DELETE FROM `lookup_cache`")
+	return err
+}
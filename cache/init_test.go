@@ -0,0 +1,13 @@
+package cache_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestCacheSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "cache")
+}
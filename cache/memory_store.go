@@ -0,0 +1,113 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+type memoryEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+// MemoryStore keeps entries in the memory of a single instance. It holds at
+// most maxEntries entries: once full, expired entries are swept and, if that
+// is not enough, the entry closest to expiring is evicted.
+type MemoryStore struct {
+	clock      clock
+	maxEntries int
+	mutex      *sync.Mutex
+	entries    map[string]memoryEntry
+}
+
+func NewMemoryStore(clock clock, maxEntries int) MemoryStore {
+	return MemoryStore{
+		clock:      clock,
+		maxEntries: maxEntries,
+		mutex:      &sync.Mutex{},
+		entries:    map[string]memoryEntry{},
+	}
+}
+
+func (s MemoryStore) Get(key string) ([]byte, bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	if !s.clock.Now().Before(entry.expiresAt) {
+		delete(s.entries, key)
+		return nil, false, nil
+	}
+
+	return entry.value, true, nil
+}
+
+func (s MemoryStore) Set(key string, value []byte, expiresAt time.Time) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.entries[key]; !ok && len(s.entries) >= s.maxEntries {
+		s.sweep()
+		if len(s.entries) >= s.maxEntries {
+			s.evictSoonestExpiring()
+		}
+	}
+
+	s.entries[key] = memoryEntry{
+		value:     value,
+		expiresAt: expiresAt,
+	}
+
+	return nil
+}
+
+// Sweep removes every expired entry.
+func (s MemoryStore) Sweep() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.sweep()
+
+	return nil
+}
+
+// Flush only empties the cache of this instance; other instances keep their
+// own entries until they expire.
+func (s MemoryStore) Flush() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for key := range s.entries {
+		delete(s.entries, key)
+	}
+
+	return nil
+}
+
+func (s MemoryStore) sweep() {
+	now := s.clock.Now()
+	for key, entry := range s.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+}
+
+func (s MemoryStore) evictSoonestExpiring() {
+	var (
+		soonestKey string
+		soonest    time.Time
+	)
+
+	for key, entry := range s.entries {
+		if soonestKey == "" || entry.expiresAt.Before(soonest) {
+			soonestKey, soonest = key, entry.expiresAt
+		}
+	}
+
+	delete(s.entries, soonestKey)
+}
@@ -0,0 +1,129 @@
+package cache_test
+
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/cache"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MemoryStore", func() {
+	var (
+		store cache.MemoryStore
+		clock *mocks.Clock
+		now   time.Time
+	)
+
+	BeforeEach(func() {
+		now = time.Date(2015, 1, 1, 12, 0, 0, 0, time.UTC)
+		clock = mocks.NewClock()
+		clock.NowCall.Returns.Time = now
+
+		store = cache.NewMemoryStore(clock, 3)
+	})
+
+	It("returns values that have not expired", func() {
+		Expect(store.Set("some-key", []byte("some-value"), now.Add(time.Minute))).To(Succeed())
+
+		value, found, err := store.Get("some-key")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found).To(BeTrue())
+		Expect(value).To(Equal([]byte("some-value")))
+	})
+
+	It("does not return values that have expired", func() {
+		Expect(store.Set("some-key", []byte("some-value"), now.Add(time.Minute))).To(Succeed())
+
+		clock.NowCall.Returns.Time = now.Add(time.Minute)
+
+		_, found, err := store.Get("some-key")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found).To(BeFalse())
+	})
+
+	It("does not return values that were never set", func() {
+		_, found, err := store.Get("missing-key")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found).To(BeFalse())
+	})
+
+	It("removes expired values when swept", func() {
+		Expect(store.Set("some-key", []byte("some-value"), now.Add(time.Minute))).To(Succeed())
+		Expect(store.Set("other-key", []byte("other-value"), now.Add(time.Hour))).To(Succeed())
+
+		clock.NowCall.Returns.Time = now.Add(time.Minute)
+		Expect(store.Sweep()).To(Succeed())
+
+		clock.NowCall.Returns.Time = now
+		_, found, err := store.Get("some-key")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found).To(BeFalse())
+
+		_, found, err = store.Get("other-key")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found).To(BeTrue())
+	})
+
+	Context("when the store is full", func() {
+		BeforeEach(func() {
+			Expect(store.Set("first-key", []byte("first-value"), now.Add(2*time.Minute))).To(Succeed())
+			Expect(store.Set("second-key", []byte("second-value"), now.Add(time.Minute))).To(Succeed())
+			Expect(store.Set("third-key", []byte("third-value"), now.Add(3*time.Minute))).To(Succeed())
+		})
+
+		It("evicts the value closest to expiring", func() {
+			Expect(store.Set("fourth-key", []byte("fourth-value"), now.Add(4*time.Minute))).To(Succeed())
+
+			_, found, err := store.Get("second-key")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeFalse())
+
+			for _, key := range []string{"first-key", "third-key", "fourth-key"} {
+				_, found, err := store.Get(key)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(found).To(BeTrue())
+			}
+		})
+
+		It("evicts expired values first", func() {
+			clock.NowCall.Returns.Time = now.Add(2 * time.Minute)
+
+			Expect(store.Set("fourth-key", []byte("fourth-value"), now.Add(4*time.Minute))).To(Succeed())
+			Expect(store.Set("fifth-key", []byte("fifth-value"), now.Add(5*time.Minute))).To(Succeed())
+
+			for _, key := range []string{"third-key", "fourth-key", "fifth-key"} {
+				_, found, err := store.Get(key)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(found).To(BeTrue())
+			}
+		})
+
+		It("replaces existing values without evicting anything", func() {
+			Expect(store.Set("second-key", []byte("new-value"), now.Add(time.Minute))).To(Succeed())
+
+			for _, key := range []string{"first-key", "second-key", "third-key"} {
+				_, found, err := store.Get(key)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(found).To(BeTrue())
+			}
+		})
+	})
+
+	It("removes every value when flushed", func() {
+		Expect(store.Set("some-key", []byte("some-value"), now.Add(time.Minute))).To(Succeed())
+		Expect(store.Set("other-key", []byte("other-value"), now.Add(time.Minute))).To(Succeed())
+
+		Expect(store.Flush()).To(Succeed())
+
+		_, found, err := store.Get("some-key")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found).To(BeFalse())
+
+		_, found, err = store.Get("other-key")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found).To(BeFalse())
+	})
+})
@@ -0,0 +1,117 @@
+package cf
+
+type lookupCache interface {
+	Get(key string, value interface{}) bool
+	Set(key string, value interface{})
+}
+
+// CachedCloudController answers lookups from the cache when it can. Only
+// successful responses are cached, keyed by resource GUID rather than token.
+type CachedCloudController struct {
+	cc    CloudControllerInterface
+	cache lookupCache
+}
+
+func NewCachedCloudController(cc CloudControllerInterface, cache lookupCache) CachedCloudController {
+	return CachedCloudController{
+		cc:    cc,
+		cache: cache,
+	}
+}
+
+func (c CachedCloudController) LoadSpace(spaceGuid, token string) (CloudControllerSpace, error) {
+	key := "cc.space." + spaceGuid
+
+	var space CloudControllerSpace
+	if c.cache.Get(key, &space) {
+		return space, nil
+	}
+
+	space, err := c.cc.LoadSpace(spaceGuid, token)
+	if err != nil {
+		return space, err
+	}
+
+	c.cache.Set(key, space)
+	return space, nil
+}
+
+func (c CachedCloudController) LoadOrganization(guid, token string) (CloudControllerOrganization, error) {
+	key := "cc.organization." + guid
+
+	var org CloudControllerOrganization
+	if c.cache.Get(key, &org) {
+		return org, nil
+	}
+
+	org, err := c.cc.LoadOrganization(guid, token)
+	if err != nil {
+		return org, err
+	}
+
+	c.cache.Set(key, org)
+	return org, nil
+}
+
+func (c CachedCloudController) GetUsersByOrgGuid(guid, token string) ([]CloudControllerUser, error) {
+	return c.users("cc.users-by-org-guid."+guid, func() ([]CloudControllerUser, error) {
+		return c.cc.GetUsersByOrgGuid(guid, token)
+	})
+}
+
+func (c CachedCloudController) GetManagersByOrgGuid(guid, token string) ([]CloudControllerUser, error) {
+	return c.users("cc.managers-by-org-guid."+guid, func() ([]CloudControllerUser, error) {
+		return c.cc.GetManagersByOrgGuid(guid, token)
+	})
+}
+
+func (c CachedCloudController) GetAuditorsByOrgGuid(guid, token string) ([]CloudControllerUser, error) {
+	return c.users("cc.auditors-by-org-guid."+guid, func() ([]CloudControllerUser, error) {
+		return c.cc.GetAuditorsByOrgGuid(guid, token)
+	})
+}
+
+func (c CachedCloudController) GetBillingManagersByOrgGuid(guid, token string) ([]CloudControllerUser, error) {
+	return c.users("cc.billing-managers-by-org-guid."+guid, func() ([]CloudControllerUser, error) {
+		return c.cc.GetBillingManagersByOrgGuid(guid, token)
+	})
+}
+
+func (c CachedCloudController) GetUsersBySpaceGuid(guid, token string) ([]CloudControllerUser, error) {
+	return c.users("cc.users-by-space-guid."+guid, func() ([]CloudControllerUser, error) {
+		return c.cc.GetUsersBySpaceGuid(guid, token)
+	})
+}
+
+func (c CachedCloudController) GetManagersBySpaceGuid(guid, token string) ([]CloudControllerUser, error) {
+	return c.users("cc.managers-by-space-guid."+guid, func() ([]CloudControllerUser, error) {
+		return c.cc.GetManagersBySpaceGuid(guid, token)
+	})
+}
+
+func (c CachedCloudController) GetDevelopersBySpaceGuid(guid, token string) ([]CloudControllerUser, error) {
+	return c.users("cc.developers-by-space-guid."+guid, func() ([]CloudControllerUser, error) {
+		return c.cc.GetDevelopersBySpaceGuid(guid, token)
+	})
+}
+
+func (c CachedCloudController) GetAuditorsBySpaceGuid(guid, token string) ([]CloudControllerUser, error) {
+	return c.users("cc.auditors-by-space-guid."+guid, func() ([]CloudControllerUser, error) {
+		return c.cc.GetAuditorsBySpaceGuid(guid, token)
+	})
+}
+
+func (c CachedCloudController) users(key string, load func() ([]CloudControllerUser, error)) ([]CloudControllerUser, error) {
+	var users []CloudControllerUser
+	if c.cache.Get(key, &users) {
+		return users, nil
+	}
+
+	users, err := load()
+	if err != nil {
+		return users, err
+	}
+
+	c.cache.Set(key, users)
+	return users, nil
+}
@@ -0,0 +1,120 @@
+package cf_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/cache"
+	"github.com/cloudfoundry-incubator/notifications/cf"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CachedCloudController", func() {
+	var (
+		cachedCC        cf.CachedCloudController
+		cloudController *mocks.CloudController
+	)
+
+	BeforeEach(func() {
+		clock := mocks.NewClock()
+		clock.NowCall.Returns.Time = time.Now()
+
+		lookupCache := cache.NewCache(cache.NewMemoryStore(clock, 100), time.Minute, clock, mocks.NewMetricsEmitter())
+		cloudController = mocks.NewCloudController()
+		cachedCC = cf.NewCachedCloudController(cloudController, lookupCache)
+	})
+
+	Describe("LoadSpace", func() {
+		It("only asks the cloud controller for the space once", func() {
+			cloudController.LoadSpaceCall.Returns.Space = cf.CloudControllerSpace{
+				GUID:             "space-001",
+				Name:             "some-space",
+				OrganizationGUID: "org-001",
+			}
+
+			for i := 0; i < 3; i++ {
+				space, err := cachedCC.LoadSpace("space-001", "some-token")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(space).To(Equal(cf.CloudControllerSpace{
+					GUID:             "space-001",
+					Name:             "some-space",
+					OrganizationGUID: "org-001",
+				}))
+			}
+
+			Expect(cloudController.LoadSpaceCall.CallCount).To(Equal(1))
+			Expect(cloudController.LoadSpaceCall.Receives.SpaceGUID).To(Equal("space-001"))
+			Expect(cloudController.LoadSpaceCall.Receives.Token).To(Equal("some-token"))
+		})
+
+		It("does not cache failed lookups", func() {
+			cloudController.LoadSpaceCall.Returns.Error = cf.NotFoundError{Message: "Space \"space-001\" could not be found"}
+
+			_, err := cachedCC.LoadSpace("space-001", "some-token")
+			Expect(err).To(MatchError(cf.NotFoundError{Message: "Space \"space-001\" could not be found"}))
+
+			_, err = cachedCC.LoadSpace("space-001", "some-token")
+			Expect(err).To(HaveOccurred())
+
+			Expect(cloudController.LoadSpaceCall.CallCount).To(Equal(2))
+		})
+	})
+
+	Describe("LoadOrganization", func() {
+		It("only asks the cloud controller for the organization once", func() {
+			cloudController.LoadOrganizationCall.Returns.Organization = cf.CloudControllerOrganization{
+				GUID: "org-001",
+				Name: "some-org",
+			}
+
+			for i := 0; i < 3; i++ {
+				org, err := cachedCC.LoadOrganization("org-001", "some-token")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(org).To(Equal(cf.CloudControllerOrganization{
+					GUID: "org-001",
+					Name: "some-org",
+				}))
+			}
+
+			Expect(cloudController.LoadOrganizationCall.CallCount).To(Equal(1))
+		})
+	})
+
+	Describe("GetUsersBySpaceGuid", func() {
+		It("caches the users per space", func() {
+			cloudController.GetUsersBySpaceGuidCall.Returns.Users = []cf.CloudControllerUser{
+				{GUID: "user-123"},
+				{GUID: "user-456"},
+			}
+
+			users, err := cachedCC.GetUsersBySpaceGuid("space-001", "some-token")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(users).To(Equal([]cf.CloudControllerUser{
+				{GUID: "user-123"},
+				{GUID: "user-456"},
+			}))
+
+			users, err = cachedCC.GetUsersBySpaceGuid("space-001", "some-token")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(users).To(Equal([]cf.CloudControllerUser{
+				{GUID: "user-123"},
+				{GUID: "user-456"},
+			}))
+			Expect(cloudController.GetUsersBySpaceGuidCall.CallCount).To(Equal(1))
+
+			_, err = cachedCC.GetUsersBySpaceGuid("space-002", "some-token")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cloudController.GetUsersBySpaceGuidCall.CallCount).To(Equal(2))
+		})
+
+		It("returns errors from the cloud controller", func() {
+			cloudController.GetUsersBySpaceGuidCall.Returns.Error = errors.New("cc is down")
+
+			_, err := cachedCC.GetUsersBySpaceGuid("space-001", "some-token")
+			Expect(err).To(MatchError(errors.New("cc is down")))
+		})
+	})
+})
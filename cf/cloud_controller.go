@@ -1,13 +1,20 @@
 package cf
 
 import (
+	"crypto/tls"
 	"fmt"
+	"net/http"
 
 	"github.com/pivotal-cf-experimental/rainmaker"
 )
 
+// CloudController talks to the Cloud Controller v2 API, mostly through
+// rainmaker. The space role endpoints, which the vendored rainmaker does not
+// cover, are requested directly with httpClient.
 type CloudController struct {
-	client rainmaker.Client
+	client     rainmaker.Client
+	host       string
+	httpClient *http.Client
 }
 
 func NewCloudController(host string, skipVerifySSL bool) CloudController {
@@ -16,9 +23,40 @@ func NewCloudController(host string, skipVerifySSL bool) CloudController {
 			Host:          host,
 			SkipVerifySSL: skipVerifySSL,
 		}),
+		host: host,
+		httpClient: &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{
+					InsecureSkipVerify: skipVerifySSL,
+				},
+			},
+		},
 	}
 }
 
+type CloudControllerInterface interface {
+	LoadSpace(spaceGuid, token string) (CloudControllerSpace, error)
+	LoadOrganization(guid, token string) (CloudControllerOrganization, error)
+	GetUsersByOrgGuid(guid, token string) ([]CloudControllerUser, error)
+	GetManagersByOrgGuid(guid, token string) ([]CloudControllerUser, error)
+	GetAuditorsByOrgGuid(guid, token string) ([]CloudControllerUser, error)
+	GetBillingManagersByOrgGuid(guid, token string) ([]CloudControllerUser, error)
+	GetUsersBySpaceGuid(guid, token string) ([]CloudControllerUser, error)
+	GetManagersBySpaceGuid(guid, token string) ([]CloudControllerUser, error)
+	GetDevelopersBySpaceGuid(guid, token string) ([]CloudControllerUser, error)
+	GetAuditorsBySpaceGuid(guid, token string) ([]CloudControllerUser, error)
+}
+
+// NewCloudControllerForVersion returns a client for the given Cloud Controller
+// API version, falling back to the v2 client for anything but 3.
+func NewCloudControllerForVersion(host string, skipVerifySSL bool, apiVersion int) CloudControllerInterface {
+	if apiVersion == 3 {
+		return NewCloudControllerV3(host, skipVerifySSL)
+	}
+
+	return NewCloudController(host, skipVerifySSL)
+}
+
 type CloudControllerUser struct {
 	GUID string
 }
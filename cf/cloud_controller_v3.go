@@ -0,0 +1,240 @@
+package cf
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/metrics"
+)
+
+const v3RolesPerPage = 5000
+
+// CloudControllerV3 talks to the Cloud Controller v3 API. Role memberships
+// are read from /v3/roles rather than the per-role v2 user endpoints.
+type CloudControllerV3 struct {
+	host   string
+	client *http.Client
+}
+
+func NewCloudControllerV3(host string, skipVerifySSL bool) CloudControllerV3 {
+	return CloudControllerV3{
+		host: host,
+		client: &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{
+					InsecureSkipVerify: skipVerifySSL,
+				},
+			},
+		},
+	}
+}
+
+type v3Relationship struct {
+	Data *struct {
+		GUID string `json:"guid"`
+	} `json:"data"`
+}
+
+func (r v3Relationship) guid() string {
+	if r.Data == nil {
+		return ""
+	}
+
+	return r.Data.GUID
+}
+
+type v3Space struct {
+	GUID          string `json:"guid"`
+	Name          string `json:"name"`
+	Relationships struct {
+		Organization v3Relationship `json:"organization"`
+	} `json:"relationships"`
+}
+
+type v3Organization struct {
+	GUID string `json:"guid"`
+	Name string `json:"name"`
+}
+
+type v3RolesPage struct {
+	Pagination struct {
+		Next *struct {
+			Href string `json:"href"`
+		} `json:"next"`
+	} `json:"pagination"`
+	Resources []struct {
+		Type          string `json:"type"`
+		Relationships struct {
+			User v3Relationship `json:"user"`
+		} `json:"relationships"`
+	} `json:"resources"`
+}
+
+func (cc CloudControllerV3) LoadSpace(spaceGuid, token string) (CloudControllerSpace, error) {
+	then := time.Now()
+
+	var space v3Space
+	status, err := cc.get(cc.host+"/v3/spaces/"+url.QueryEscape(spaceGuid), token, &space)
+	if err != nil {
+		if status == http.StatusNotFound {
+			return CloudControllerSpace{}, NotFoundError{fmt.Sprintf("Space %q could not be found", spaceGuid)}
+		}
+
+		return CloudControllerSpace{}, err
+	}
+
+	logDuration("notifications.external-requests.cc.space", then)
+
+	return CloudControllerSpace{
+		GUID:             space.GUID,
+		Name:             space.Name,
+		OrganizationGUID: space.Relationships.Organization.guid(),
+	}, nil
+}
+
+func (cc CloudControllerV3) LoadOrganization(guid, token string) (CloudControllerOrganization, error) {
+	then := time.Now()
+
+	var org v3Organization
+	status, err := cc.get(cc.host+"/v3/organizations/"+url.QueryEscape(guid), token, &org)
+	if err != nil {
+		if status == http.StatusNotFound {
+			return CloudControllerOrganization{}, NotFoundError{fmt.Sprintf("Organization %q could not be found", guid)}
+		}
+
+		return CloudControllerOrganization{}, err
+	}
+
+	logDuration("notifications.external-requests.cc.organization", then)
+
+	return CloudControllerOrganization{
+		GUID: org.GUID,
+		Name: org.Name,
+	}, nil
+}
+
+func (cc CloudControllerV3) GetUsersByOrgGuid(guid, token string) ([]CloudControllerUser, error) {
+	return cc.roleUsers("notifications.external-requests.cc.users-by-org-guid", "organization_guids", guid, token, "organization_user")
+}
+
+func (cc CloudControllerV3) GetManagersByOrgGuid(guid, token string) ([]CloudControllerUser, error) {
+	return cc.roleUsers("notifications.external-requests.cc.managers-by-org-guid", "organization_guids", guid, token, "organization_manager")
+}
+
+func (cc CloudControllerV3) GetAuditorsByOrgGuid(guid, token string) ([]CloudControllerUser, error) {
+	return cc.roleUsers("notifications.external-requests.cc.auditors-by-org-guid", "organization_guids", guid, token, "organization_auditor")
+}
+
+func (cc CloudControllerV3) GetBillingManagersByOrgGuid(guid, token string) ([]CloudControllerUser, error) {
+	return cc.roleUsers("notifications.external-requests.cc.billing-managers-by-org-guid", "organization_guids", guid, token, "organization_billing_manager")
+}
+
+// GetUsersBySpaceGuid returns everyone holding any role in the space, which is
+// what the v2 /v2/users?q=space_guid filter returned.
+func (cc CloudControllerV3) GetUsersBySpaceGuid(guid, token string) ([]CloudControllerUser, error) {
+	return cc.roleUsers("notifications.external-requests.cc.users-by-space-guid", "space_guids", guid, token, "space_developer", "space_manager", "space_auditor")
+}
+
+func (cc CloudControllerV3) GetManagersBySpaceGuid(guid, token string) ([]CloudControllerUser, error) {
+	return cc.roleUsers("notifications.external-requests.cc.managers-by-space-guid", "space_guids", guid, token, "space_manager")
+}
+
+func (cc CloudControllerV3) GetDevelopersBySpaceGuid(guid, token string) ([]CloudControllerUser, error) {
+	return cc.roleUsers("notifications.external-requests.cc.developers-by-space-guid", "space_guids", guid, token, "space_developer")
+}
+
+func (cc CloudControllerV3) GetAuditorsBySpaceGuid(guid, token string) ([]CloudControllerUser, error) {
+	return cc.roleUsers("notifications.external-requests.cc.auditors-by-space-guid", "space_guids", guid, token, "space_auditor")
+}
+
+func (cc CloudControllerV3) roleUsers(metricName, filter, guid, token string, roleTypes ...string) ([]CloudControllerUser, error) {
+	then := time.Now()
+
+	query := url.Values{}
+	query.Set("types", strings.Join(roleTypes, ","))
+	query.Set(filter, guid)
+	query.Set("per_page", fmt.Sprintf("%d", v3RolesPerPage))
+
+	ccUsers := []CloudControllerUser{}
+	seen := map[string]bool{}
+
+	next := cc.host + "/v3/roles?" + query.Encode()
+	for next != "" {
+		var page v3RolesPage
+		_, err := cc.get(next, token, &page)
+		if err != nil {
+			return []CloudControllerUser{}, err
+		}
+
+		for _, role := range page.Resources {
+			userGUID := role.Relationships.User.guid()
+			if userGUID == "" || seen[userGUID] {
+				continue
+			}
+
+			seen[userGUID] = true
+			ccUsers = append(ccUsers, CloudControllerUser{
+				GUID: userGUID,
+			})
+		}
+
+		next = ""
+		if page.Pagination.Next != nil {
+			next = page.Pagination.Next.Href
+		}
+	}
+
+	logDuration(metricName, then)
+
+	return ccUsers, nil
+}
+
+func (cc CloudControllerV3) get(location, token string, response interface{}) (int, error) {
+	return getJSON(cc.client, location, token, response)
+}
+
+// getJSON decodes the response to a GET of the location into response,
+// returning a Failure for transport errors and non-2xx statuses.
+func getJSON(client *http.Client, location, token string, response interface{}) (int, error) {
+	request, err := http.NewRequest("GET", location, nil)
+	if err != nil {
+		return 0, NewFailure(0, err.Error())
+	}
+	request.Header.Set("Authorization", "Bearer "+token)
+	request.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(request)
+	if err != nil {
+		return 0, NewFailure(0, err.Error())
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, NewFailure(resp.StatusCode, err.Error())
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, NewFailure(resp.StatusCode, string(body))
+	}
+
+	err = json.Unmarshal(body, response)
+	if err != nil {
+		return resp.StatusCode, NewFailure(resp.StatusCode, err.Error())
+	}
+
+	return resp.StatusCode, nil
+}
+
+func logDuration(name string, then time.Time) {
+	metrics.NewMetric("histogram", map[string]interface{}{
+		"name":  name,
+		"value": time.Now().Sub(then).Seconds(),
+	}).Log()
+}
@@ -0,0 +1,187 @@
+package cf_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/notifications/cf"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CloudControllerV3", func() {
+	var (
+		ccServer    *httptest.Server
+		cc          cf.CloudControllerV3
+		rolesQuery  []string
+		authHeaders []string
+	)
+
+	BeforeEach(func() {
+		rolesQuery = []string{}
+		authHeaders = []string{}
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("/v3/spaces/space-guid", func(w http.ResponseWriter, req *http.Request) {
+			authHeaders = append(authHeaders, req.Header.Get("Authorization"))
+			w.Write([]byte(`{
+				"guid": "space-guid",
+				"name": "duh space",
+				"relationships": {
+					"organization": {"data": {"guid": "first-rate"}}
+				}
+			}`))
+		})
+		mux.HandleFunc("/v3/organizations/org-guid", func(w http.ResponseWriter, req *http.Request) {
+			w.Write([]byte(`{"guid": "org-guid", "name": "duh org"}`))
+		})
+		mux.HandleFunc("/v3/spaces/", func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors": [{"code": 10010, "title": "CF-ResourceNotFound", "detail": "Space not found"}]}`))
+		})
+		mux.HandleFunc("/v3/organizations/", func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors": [{"code": 10010, "title": "CF-ResourceNotFound", "detail": "Organization not found"}]}`))
+		})
+		mux.HandleFunc("/v3/roles", func(w http.ResponseWriter, req *http.Request) {
+			authHeaders = append(authHeaders, req.Header.Get("Authorization"))
+			rolesQuery = append(rolesQuery, req.URL.RawQuery)
+
+			if req.URL.Query().Get("space_guids") == "broken-space" {
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write([]byte(`{"errors": [{"code": 10001, "title": "CF-UnknownError", "detail": "oops"}]}`))
+				return
+			}
+
+			if req.URL.Query().Get("page") == "2" {
+				w.Write([]byte(`{
+					"pagination": {"total_results": 3, "next": null},
+					"resources": [
+						{"type": "space_auditor", "relationships": {"user": {"data": {"guid": "user-789"}}}}
+					]
+				}`))
+				return
+			}
+
+			w.Write([]byte(fmt.Sprintf(`{
+				"pagination": {"total_results": 3, "next": {"href": "http://%s/v3/roles?page=2"}},
+				"resources": [
+					{"type": "space_developer", "relationships": {"user": {"data": {"guid": "user-123"}}}},
+					{"type": "space_manager", "relationships": {"user": {"data": {"guid": "user-456"}}}},
+					{"type": "space_auditor", "relationships": {"user": {"data": {"guid": "user-123"}}}}
+				]
+			}`, req.Host)))
+		})
+
+		ccServer = httptest.NewServer(mux)
+		cc = cf.NewCloudControllerV3(ccServer.URL, false)
+	})
+
+	AfterEach(func() {
+		ccServer.Close()
+	})
+
+	Describe("LoadSpace", func() {
+		It("loads the space", func() {
+			space, err := cc.LoadSpace("space-guid", "some-token")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(space).To(Equal(cf.CloudControllerSpace{
+				GUID:             "space-guid",
+				Name:             "duh space",
+				OrganizationGUID: "first-rate",
+			}))
+			Expect(authHeaders).To(Equal([]string{"Bearer some-token"}))
+		})
+
+		It("returns a NotFoundError when the space cannot be found", func() {
+			_, err := cc.LoadSpace("banana", "some-token")
+			Expect(err).To(MatchError(cf.NotFoundError{Message: `Space "banana" could not be found`}))
+		})
+	})
+
+	Describe("LoadOrganization", func() {
+		It("loads the organization", func() {
+			org, err := cc.LoadOrganization("org-guid", "some-token")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(org).To(Equal(cf.CloudControllerOrganization{
+				GUID: "org-guid",
+				Name: "duh org",
+			}))
+		})
+
+		It("returns a NotFoundError when the organization cannot be found", func() {
+			_, err := cc.LoadOrganization("banana", "some-token")
+			Expect(err).To(MatchError(cf.NotFoundError{Message: `Organization "banana" could not be found`}))
+		})
+	})
+
+	Describe("GetUsersBySpaceGuid", func() {
+		It("follows every page of roles and returns each user once", func() {
+			users, err := cc.GetUsersBySpaceGuid("space-guid", "some-token")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(users).To(Equal([]cf.CloudControllerUser{
+				{GUID: "user-123"},
+				{GUID: "user-456"},
+				{GUID: "user-789"},
+			}))
+
+			Expect(rolesQuery).To(HaveLen(2))
+			Expect(rolesQuery[0]).To(Equal("per_page=5000&space_guids=space-guid&types=space_developer%2Cspace_manager%2Cspace_auditor"))
+			Expect(authHeaders).To(Equal([]string{"Bearer some-token", "Bearer some-token"}))
+		})
+
+		It("returns a Failure when the roles cannot be listed", func() {
+			_, err := cc.GetUsersBySpaceGuid("broken-space", "some-token")
+			Expect(err).To(BeAssignableToTypeOf(cf.Failure{}))
+			Expect(err.(cf.Failure).Code).To(Equal(http.StatusInternalServerError))
+		})
+	})
+
+	Describe("role filters", func() {
+		It("asks for the role type matching each lookup", func() {
+			lookups := []func(string, string) ([]cf.CloudControllerUser, error){
+				cc.GetUsersByOrgGuid,
+				cc.GetManagersByOrgGuid,
+				cc.GetAuditorsByOrgGuid,
+				cc.GetBillingManagersByOrgGuid,
+				cc.GetManagersBySpaceGuid,
+				cc.GetDevelopersBySpaceGuid,
+				cc.GetAuditorsBySpaceGuid,
+			}
+
+			for _, lookup := range lookups {
+				_, err := lookup("some-guid", "some-token")
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			var firstPages []string
+			for _, query := range rolesQuery {
+				if query != "page=2" {
+					firstPages = append(firstPages, query)
+				}
+			}
+
+			Expect(firstPages).To(Equal([]string{
+				"organization_guids=some-guid&per_page=5000&types=organization_user",
+				"organization_guids=some-guid&per_page=5000&types=organization_manager",
+				"organization_guids=some-guid&per_page=5000&types=organization_auditor",
+				"organization_guids=some-guid&per_page=5000&types=organization_billing_manager",
+				"per_page=5000&space_guids=some-guid&types=space_manager",
+				"per_page=5000&space_guids=some-guid&types=space_developer",
+				"per_page=5000&space_guids=some-guid&types=space_auditor",
+			}))
+		})
+	})
+})
+
+var _ = Describe("NewCloudControllerForVersion", func() {
+	It("returns a v3 client for version 3", func() {
+		Expect(cf.NewCloudControllerForVersion("http://cc.example.com", false, 3)).To(BeAssignableToTypeOf(cf.CloudControllerV3{}))
+	})
+
+	It("returns a v2 client otherwise", func() {
+		Expect(cf.NewCloudControllerForVersion("http://cc.example.com", false, 2)).To(BeAssignableToTypeOf(cf.CloudController{}))
+	})
+})
@@ -0,0 +1,5 @@
+package cf
+
+func (cc CloudController) GetAuditorsBySpaceGuid(guid, token string) ([]CloudControllerUser, error) {
+	return cc.spaceRoleUsers("notifications.external-requests.cc.auditors-by-space-guid", guid, "auditors", token)
+}
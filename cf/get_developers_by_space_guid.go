@@ -0,0 +1,5 @@
+package cf
+
+func (cc CloudController) GetDevelopersBySpaceGuid(guid, token string) ([]CloudControllerUser, error) {
+	return cc.spaceRoleUsers("notifications.external-requests.cc.developers-by-space-guid", guid, "developers", token)
+}
@@ -0,0 +1,5 @@
+package cf
+
+func (cc CloudController) GetManagersBySpaceGuid(guid, token string) ([]CloudControllerUser, error) {
+	return cc.spaceRoleUsers("notifications.external-requests.cc.managers-by-space-guid", guid, "managers", token)
+}
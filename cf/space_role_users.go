@@ -0,0 +1,47 @@
+package cf
+
+import (
+	"net/url"
+	"time"
+)
+
+type v2UsersPage struct {
+	NextURL   *string `json:"next_url"`
+	Resources []struct {
+		Metadata struct {
+			GUID string `json:"guid"`
+		} `json:"metadata"`
+	} `json:"resources"`
+}
+
+// spaceRoleUsers follows every page of /v2/spaces/:guid/:role, where role is
+// managers, developers or auditors.
+func (cc CloudController) spaceRoleUsers(metricName, guid, role, token string) ([]CloudControllerUser, error) {
+	then := time.Now()
+
+	ccUsers := []CloudControllerUser{}
+
+	next := "/v2/spaces/" + url.QueryEscape(guid) + "/" + role
+	for next != "" {
+		var page v2UsersPage
+		_, err := getJSON(cc.httpClient, cc.host+next, token, &page)
+		if err != nil {
+			return []CloudControllerUser{}, err
+		}
+
+		for _, resource := range page.Resources {
+			ccUsers = append(ccUsers, CloudControllerUser{
+				GUID: resource.Metadata.GUID,
+			})
+		}
+
+		next = ""
+		if page.NextURL != nil {
+			next = *page.NextURL
+		}
+	}
+
+	logDuration(metricName, then)
+
+	return ccUsers, nil
+}
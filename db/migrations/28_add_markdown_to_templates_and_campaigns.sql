@@ -0,0 +1,11 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+ALTER TABLE `v2_templates` ADD `markdown` longtext DEFAULT NULL;
+UPDATE `v2_templates` SET `markdown` = '';
+ALTER TABLE `campaigns` ADD `markdown` longtext DEFAULT NULL;
+UPDATE `campaigns` SET `markdown` = '';
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+ALTER TABLE `v2_templates` DROP COLUMN `markdown`;
+ALTER TABLE `campaigns` DROP COLUMN `markdown`;
@@ -0,0 +1,31 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS `digest_preferences` (
+      `user_guid` varchar(255) NOT NULL,
+      `client_id` varchar(255) NOT NULL,
+      `campaign_type_id` varchar(255) NOT NULL,
+      `frequency` varchar(255) NOT NULL,
+      PRIMARY KEY (`user_guid`, `client_id`, `campaign_type_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `digests` (
+      `id` varchar(36) NOT NULL,
+      `message_id` varchar(255) NOT NULL,
+      `campaign_id` varchar(255) DEFAULT NULL,
+      `user_guid` varchar(255) NOT NULL,
+      `email` varchar(255) NOT NULL,
+      `client_id` varchar(255) NOT NULL,
+      `campaign_type_id` varchar(255) NOT NULL,
+      `frequency` varchar(255) NOT NULL,
+      `subject` text DEFAULT NULL,
+      `text` longtext DEFAULT NULL,
+      `html` longtext DEFAULT NULL,
+      `created_at` datetime DEFAULT NULL,
+      PRIMARY KEY (`id`),
+      KEY `frequency_user_guid` (`frequency`, `user_guid`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE digest_preferences;
+DROP TABLE digests;
@@ -0,0 +1,16 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS `quiet_hours` (
+      `user_guid` varchar(255) NOT NULL,
+      `time_zone` varchar(255) NOT NULL DEFAULT '',
+      `start_time` varchar(5) NOT NULL DEFAULT '',
+      `end_time` varchar(5) NOT NULL DEFAULT '',
+      PRIMARY KEY (`user_guid`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+ALTER TABLE `campaigns` ADD `local_business_hours` bool NOT NULL DEFAULT false;
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE quiet_hours;
+ALTER TABLE `campaigns` DROP COLUMN `local_business_hours`;
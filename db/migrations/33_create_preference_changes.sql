@@ -0,0 +1,22 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS `preference_changes` (
+      `id` int(11) NOT NULL AUTO_INCREMENT,
+      `user_guid` varchar(255) NOT NULL DEFAULT '',
+      `email` varchar(255) NOT NULL DEFAULT '',
+      `actor_type` varchar(255) NOT NULL,
+      `actor_id` varchar(255) NOT NULL DEFAULT '',
+      `source` varchar(255) NOT NULL,
+      `preference` varchar(255) NOT NULL,
+      `client_id` varchar(255) NOT NULL DEFAULT '',
+      `target_id` varchar(255) NOT NULL DEFAULT '',
+      `old_value` varchar(255) NOT NULL,
+      `new_value` varchar(255) NOT NULL,
+      `created_at` datetime NOT NULL,
+      PRIMARY KEY (`id`),
+      KEY `user_guid` (`user_guid`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE preference_changes;
@@ -0,0 +1,13 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+ALTER TABLE `campaigns` ADD `exclude` longtext DEFAULT NULL;
+UPDATE `campaigns` SET `exclude` = '';
+ALTER TABLE `campaigns` ADD `intersect` longtext DEFAULT NULL;
+UPDATE `campaigns` SET `intersect` = '';
+ALTER TABLE `campaigns` ADD `excluded_count` integer NOT NULL DEFAULT 0;
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+ALTER TABLE `campaigns` DROP COLUMN `exclude`;
+ALTER TABLE `campaigns` DROP COLUMN `intersect`;
+ALTER TABLE `campaigns` DROP COLUMN `excluded_count`;
@@ -0,0 +1,15 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS `campaign_recipients` (
+      `campaign_id` varchar(255) NOT NULL,
+      `recipient` varchar(255) NOT NULL,
+      `message_id` varchar(255) NOT NULL DEFAULT '',
+      PRIMARY KEY (`campaign_id`, `recipient`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+ALTER TABLE `campaigns` ADD `fanout_progress` integer NOT NULL DEFAULT 0;
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE campaign_recipients;
+ALTER TABLE `campaigns` DROP COLUMN `fanout_progress`;
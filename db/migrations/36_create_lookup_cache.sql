@@ -0,0 +1,13 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS `lookup_cache` (
+      `key` varchar(255) NOT NULL,
+      `value` longtext NOT NULL,
+      `expires_at` datetime NOT NULL,
+      PRIMARY KEY (`key`),
+      INDEX (`expires_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE lookup_cache;
@@ -0,0 +1,27 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS `audiences` (
+      `id` varchar(36) NOT NULL,
+      `name` varchar(255) NOT NULL,
+      `sender_id` varchar(255) NOT NULL,
+      `version` integer NOT NULL DEFAULT 1,
+      `definition` longtext,
+      `created_at` datetime NOT NULL,
+      `updated_at` datetime NOT NULL,
+      PRIMARY KEY (`id`),
+      UNIQUE KEY `name_sender_id` (`name`, `sender_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `audience_versions` (
+      `audience_id` varchar(36) NOT NULL,
+      `version` integer NOT NULL,
+      `name` varchar(255) NOT NULL,
+      `definition` longtext,
+      `created_at` datetime NOT NULL,
+      PRIMARY KEY (`audience_id`, `version`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE audience_versions;
+DROP TABLE audiences;
@@ -0,0 +1,21 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS `message_timings` (
+      `message_id` varchar(255) NOT NULL,
+      `campaign_id` varchar(255) NOT NULL,
+      `queue_wait` double NOT NULL,
+      `send_duration` double NOT NULL,
+      `delivered_at` datetime NOT NULL,
+      PRIMARY KEY (`message_id`),
+      INDEX (`campaign_id`),
+      INDEX (`delivered_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+ALTER TABLE `campaigns` ADD `fanout_started_at` datetime DEFAULT NULL;
+ALTER TABLE `campaigns` ADD `fanout_completed_at` datetime DEFAULT NULL;
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE message_timings;
+ALTER TABLE `campaigns` DROP COLUMN `fanout_started_at`;
+ALTER TABLE `campaigns` DROP COLUMN `fanout_completed_at`;
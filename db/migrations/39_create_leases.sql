@@ -0,0 +1,12 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS `leases` (
+      `name` varchar(255) NOT NULL,
+      `holder` varchar(255) NOT NULL,
+      `expires_at` datetime NOT NULL,
+      PRIMARY KEY (`name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE leases;
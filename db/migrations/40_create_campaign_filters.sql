@@ -0,0 +1,19 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS `campaign_filters` (
+      `campaign_id` varchar(255) NOT NULL,
+      `kind` varchar(255) NOT NULL,
+      `recipient` varchar(255) NOT NULL,
+      PRIMARY KEY (`campaign_id`, `recipient`, `kind`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `campaign_exclusions` (
+      `campaign_id` varchar(255) NOT NULL,
+      `recipient` varchar(255) NOT NULL,
+      PRIMARY KEY (`campaign_id`, `recipient`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE campaign_filters;
+DROP TABLE campaign_exclusions;
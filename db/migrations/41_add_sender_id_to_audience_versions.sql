@@ -0,0 +1,8 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+ALTER TABLE `audience_versions` ADD `sender_id` varchar(255) NOT NULL DEFAULT "";
+UPDATE `audience_versions` JOIN `audiences` ON `audiences`.`id` = `audience_versions`.`audience_id` SET `audience_versions`.`sender_id` = `audiences`.`sender_id`;
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+ALTER TABLE `audience_versions` DROP COLUMN `sender_id`;
@@ -0,0 +1,9 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+ALTER TABLE `message_timings` ADD INDEX `campaign_id_queue_wait` (`campaign_id`, `queue_wait`);
+ALTER TABLE `message_timings` ADD INDEX `campaign_id_send_duration` (`campaign_id`, `send_duration`);
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+ALTER TABLE `message_timings` DROP INDEX `campaign_id_queue_wait`;
+ALTER TABLE `message_timings` DROP INDEX `campaign_id_send_duration`;
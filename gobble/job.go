@@ -13,6 +13,10 @@ type Job struct {
 	RetryCount  int       `db:"retry_count"`
 	ActiveAt    time.Time `db:"active_at"`
 	ShouldRetry bool      `db:"-"`
+
+	// QueueWait is how long the job waited to be reserved after it became
+	// active. It is only set on reserved jobs.
+	QueueWait time.Duration `db:"-"`
 }
 
 func NewJob(data interface{}) *Job {
@@ -37,6 +41,14 @@ func (job *Job) Retry(duration time.Duration) {
 	job.ShouldRetry = true
 }
 
+// Defer puts the job back on the queue until the given time without counting
+// it as a failed attempt.
+func (job *Job) Defer(until time.Time) {
+	job.WorkerID = ""
+	job.ActiveAt = until
+	job.ShouldRetry = true
+}
+
 func (job *Job) State() (int, time.Time) {
 	return job.RetryCount, job.ActiveAt
 }
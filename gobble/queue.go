@@ -163,6 +163,10 @@ func (queue *Queue) updateJob(job *Job, workerID string) (*Job, error) {
 		return job, nil
 	}
 
+	if workerID != "" {
+		job.QueueWait = time.Since(job.ActiveAt)
+	}
+
 	job.WorkerID = workerID
 	job.ActiveAt = time.Now()
 	_, err := queue.database.Connection.Update(job)
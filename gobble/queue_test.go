@@ -115,6 +115,20 @@ var _ = Describe("Queue", func() {
 			Expect(reservedJob.ActiveAt).To(BeTemporally("~", time.Now(), 250*time.Millisecond))
 		})
 
+		It("records how long the job waited to be reserved", func() {
+			job := gobble.Job{
+				Payload:  "something",
+				ActiveAt: time.Now().UTC().Add(-1 * time.Minute).Truncate(time.Second),
+			}
+
+			err := database.Connection.Insert(&job)
+			Expect(err).NotTo(HaveOccurred())
+
+			reservedJob := <-queue.Reserve("workerId")
+
+			Expect(reservedJob.QueueWait).To(BeNumerically("~", time.Minute, 2*time.Second))
+		})
+
 		It("keeps trying to reserve a job until one becomes available", func() {
 			jobChannel := queue.Reserve("my-id")
 
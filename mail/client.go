@@ -10,6 +10,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/cloudfoundry-incubator/notifications/metrics"
 	"github.com/pivotal-golang/lager"
 )
 
@@ -115,6 +116,14 @@ func (c *Client) Send(msg Message, logger lager.Logger) error {
 		return nil
 	}
 
+	then := time.Now()
+	defer func() {
+		metrics.NewMetric("histogram", map[string]interface{}{
+			"name":  "notifications.external-requests.smtp.send",
+			"value": time.Now().Sub(then).Seconds(),
+		}).Log()
+	}()
+
 	err := c.Connect(logger)
 	if err != nil {
 		return c.Error(logger, err)
@@ -0,0 +1,260 @@
+package markdown
+
+import (
+	"regexp"
+	"strings"
+)
+
+type blockKind int
+
+const (
+	paragraphBlock blockKind = iota
+	headingBlock
+	codeBlock
+	quoteBlock
+	listBlock
+	ruleBlock
+)
+
+type block struct {
+	kind     blockKind
+	level    int
+	ordered  bool
+	start    string
+	language string
+	lines    []string
+	children []block
+	items    [][]block
+}
+
+var (
+	headingFormat  = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	fenceFormat    = regexp.MustCompile("^ {0,3}(```+|~~~+)[ \t]*([^ \t`]*)")
+	quoteFormat    = regexp.MustCompile(`^ {0,3}> ?(.*)$`)
+	listItemFormat = regexp.MustCompile(`^ {0,3}([-*+]|(\d{1,9})[.)])(?:[ \t]+(.*))?$`)
+)
+
+func parseBlocks(lines []string) []block {
+	var blocks []block
+
+	for i := 0; i < len(lines); {
+		line := lines[i]
+
+		switch {
+		case strings.TrimSpace(line) == "":
+			i++
+		case fenceFormat.MatchString(line):
+			var code block
+			code, i = parseFence(lines, i)
+			blocks = append(blocks, code)
+		case headingFormat.MatchString(line):
+			matches := headingFormat.FindStringSubmatch(line)
+			blocks = append(blocks, block{
+				kind:  headingBlock,
+				level: len(matches[1]),
+				lines: []string{matches[2]},
+			})
+			i++
+		case isRule(line):
+			blocks = append(blocks, block{kind: ruleBlock})
+			i++
+		case quoteFormat.MatchString(line):
+			var quote block
+			quote, i = parseQuote(lines, i)
+			blocks = append(blocks, quote)
+		case listItemFormat.MatchString(line):
+			var list block
+			list, i = parseList(lines, i)
+			blocks = append(blocks, list)
+		default:
+			var paragraph block
+			paragraph, i = parseParagraph(lines, i)
+			blocks = append(blocks, paragraph)
+		}
+	}
+
+	return blocks
+}
+
+func parseFence(lines []string, i int) (block, int) {
+	matches := fenceFormat.FindStringSubmatch(lines[i])
+	fence := matches[1]
+	code := block{
+		kind:     codeBlock,
+		language: matches[2],
+	}
+
+	for i++; i < len(lines); i++ {
+		if strings.HasPrefix(strings.TrimSpace(lines[i]), fence) {
+			return code, i + 1
+		}
+
+		code.lines = append(code.lines, lines[i])
+	}
+
+	return code, i
+}
+
+func parseQuote(lines []string, i int) (block, int) {
+	var content []string
+
+	for ; i < len(lines); i++ {
+		matches := quoteFormat.FindStringSubmatch(lines[i])
+		if matches == nil {
+			if strings.TrimSpace(lines[i]) == "" || len(content) == 0 || startsBlock(lines[i]) {
+				break
+			}
+
+			content = append(content, lines[i])
+			continue
+		}
+
+		content = append(content, matches[1])
+	}
+
+	return block{
+		kind:     quoteBlock,
+		children: parseBlocks(content),
+	}, i
+}
+
+func parseList(lines []string, i int) (block, int) {
+	first := listItemFormat.FindStringSubmatch(lines[i])
+	list := block{
+		kind:    listBlock,
+		ordered: first[2] != "",
+		start:   first[2],
+	}
+
+	for i < len(lines) {
+		matches := listItemFormat.FindStringSubmatch(lines[i])
+		if matches == nil || (matches[2] != "") != list.ordered {
+			break
+		}
+
+		content := []string{matches[3]}
+		i++
+
+		for i < len(lines) {
+			line := lines[i]
+
+			if strings.TrimSpace(line) == "" {
+				if i+1 < len(lines) && indentation(lines[i+1]) >= 2 {
+					content = append(content, "")
+					i++
+					continue
+				}
+				break
+			}
+
+			if indentation(line) >= 2 {
+				content = append(content, dedent(line, 4))
+				i++
+				continue
+			}
+
+			if listItemFormat.MatchString(line) || startsBlock(line) {
+				break
+			}
+
+			content = append(content, line)
+			i++
+		}
+
+		list.items = append(list.items, parseBlocks(content))
+
+		if i < len(lines) && strings.TrimSpace(lines[i]) == "" {
+			next := i
+			for next < len(lines) && strings.TrimSpace(lines[next]) == "" {
+				next++
+			}
+
+			if next < len(lines) && listItemFormat.MatchString(lines[next]) {
+				i = next
+				continue
+			}
+			break
+		}
+	}
+
+	return list, i
+}
+
+func parseParagraph(lines []string, i int) (block, int) {
+	paragraph := block{kind: paragraphBlock}
+
+	for ; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "" {
+			break
+		}
+
+		if len(paragraph.lines) > 0 && (startsBlock(lines[i]) || listItemFormat.MatchString(lines[i])) {
+			break
+		}
+
+		paragraph.lines = append(paragraph.lines, strings.TrimLeft(lines[i], " \t"))
+	}
+
+	return paragraph, i
+}
+
+func startsBlock(line string) bool {
+	return fenceFormat.MatchString(line) || headingFormat.MatchString(line) || quoteFormat.MatchString(line) || isRule(line)
+}
+
+func isRule(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	if len(trimmed) < 3 || indentation(line) > 3 {
+		return false
+	}
+
+	marker := trimmed[0]
+	if marker != '-' && marker != '*' && marker != '_' {
+		return false
+	}
+
+	count := 0
+	for _, char := range trimmed {
+		switch {
+		case byte(char) == marker:
+			count++
+		case char == ' ' || char == '\t':
+		default:
+			return false
+		}
+	}
+
+	return count >= 3
+}
+
+func indentation(line string) int {
+	count := 0
+	for _, char := range line {
+		switch char {
+		case ' ':
+			count++
+		case '\t':
+			count += 4
+		default:
+			return count
+		}
+	}
+
+	return count
+}
+
+func dedent(line string, width int) string {
+	for width > 0 && len(line) > 0 {
+		switch line[0] {
+		case ' ':
+			width--
+		case '\t':
+			width -= 4
+		default:
+			return line
+		}
+		line = line[1:]
+	}
+
+	return line
+}
@@ -0,0 +1,265 @@
+package markdown
+
+import (
+	"strings"
+	"unicode"
+)
+
+type inlineKind int
+
+const (
+	textInline inlineKind = iota
+	actionInline
+	codeInline
+	strongInline
+	emphasisInline
+	linkInline
+	imageInline
+	breakInline
+)
+
+type inline struct {
+	kind     inlineKind
+	text     string
+	url      string
+	children []inline
+}
+
+const escapable = "\\`*_{}[]()#+-.!<>|~"
+
+func parseInlines(source string) []inline {
+	var (
+		nodes  []inline
+		buffer []byte
+	)
+
+	flush := func() {
+		if len(buffer) > 0 {
+			nodes = append(nodes, inline{kind: textInline, text: string(buffer)})
+			buffer = nil
+		}
+	}
+
+	for i := 0; i < len(source); {
+		char := source[i]
+		rest := source[i:]
+
+		switch {
+		case strings.HasPrefix(rest, "{{"):
+			end := strings.Index(rest, "}}")
+			if end < 0 {
+				buffer = append(buffer, rest...)
+				i = len(source)
+				continue
+			}
+
+			flush()
+			nodes = append(nodes, inline{kind: actionInline, text: rest[:end+2]})
+			i += end + 2
+
+		case char == '\\' && i+1 < len(source) && strings.IndexByte(escapable, source[i+1]) >= 0:
+			buffer = append(buffer, source[i+1])
+			i += 2
+
+		case char == '\\' && i+1 < len(source) && source[i+1] == '\n':
+			flush()
+			nodes = append(nodes, inline{kind: breakInline})
+			i += 2
+
+		case char == '\n':
+			trimmed := strings.TrimRight(string(buffer), " ")
+			if len(buffer)-len(trimmed) >= 2 {
+				buffer = []byte(trimmed)
+				flush()
+				nodes = append(nodes, inline{kind: breakInline})
+			} else {
+				buffer = append([]byte(trimmed), '\n')
+			}
+			i++
+
+		case char == '`':
+			run := countRun(rest, '`')
+			closing := strings.Index(rest[run:], strings.Repeat("`", run))
+			if closing < 0 {
+				buffer = append(buffer, rest[:run]...)
+				i += run
+				continue
+			}
+
+			flush()
+			nodes = append(nodes, inline{kind: codeInline, text: strings.TrimSpace(rest[run : run+closing])})
+			i += run + closing + run
+
+		case char == '!' && strings.HasPrefix(rest, "!["):
+			text, url, length, ok := parseLink(rest[1:])
+			if !ok {
+				buffer = append(buffer, char)
+				i++
+				continue
+			}
+
+			flush()
+			nodes = append(nodes, inline{kind: imageInline, text: text, url: url})
+			i += 1 + length
+
+		case char == '[':
+			text, url, length, ok := parseLink(rest)
+			if !ok {
+				buffer = append(buffer, char)
+				i++
+				continue
+			}
+
+			flush()
+			nodes = append(nodes, inline{kind: linkInline, url: url, children: parseInlines(text)})
+			i += length
+
+		case char == '<' && isAutolink(rest):
+			end := strings.IndexByte(rest, '>')
+			url := rest[1:end]
+
+			flush()
+			nodes = append(nodes, inline{kind: linkInline, url: url, children: []inline{{kind: textInline, text: strings.TrimPrefix(url, "mailto:")}}})
+			i += end + 1
+
+		case (char == '*' || char == '_') && canOpen(source, i):
+			run := countRun(rest, char)
+			if run > 2 {
+				run = 2
+			}
+
+			closing := findClosing(source, i+run, char, run)
+			if closing < 0 && run == 2 {
+				run = 1
+				closing = findClosing(source, i+run, char, run)
+			}
+
+			if closing < 0 {
+				buffer = append(buffer, rest[:run]...)
+				i += run
+				continue
+			}
+
+			kind := emphasisInline
+			if run == 2 {
+				kind = strongInline
+			}
+
+			flush()
+			nodes = append(nodes, inline{kind: kind, children: parseInlines(source[i+run : closing])})
+			i = closing + run
+
+		default:
+			buffer = append(buffer, char)
+			i++
+		}
+	}
+
+	flush()
+	return nodes
+}
+
+func parseLink(source string) (text, url string, length int, ok bool) {
+	depth := 0
+	for i := 0; i < len(source); i++ {
+		switch source[i] {
+		case '\\':
+			i++
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				if i+1 >= len(source) || source[i+1] != '(' {
+					return "", "", 0, false
+				}
+
+				end := strings.IndexByte(source[i+2:], ')')
+				if end < 0 {
+					return "", "", 0, false
+				}
+
+				destination := strings.TrimSpace(source[i+2 : i+2+end])
+				if fields := strings.Fields(destination); len(fields) > 0 {
+					destination = strings.Trim(fields[0], "<>")
+				}
+
+				return source[1:i], destination, i + 2 + end + 1, true
+			}
+		}
+	}
+
+	return "", "", 0, false
+}
+
+func isAutolink(source string) bool {
+	end := strings.IndexByte(source, '>')
+	if end < 0 {
+		return false
+	}
+
+	url := source[1:end]
+	if strings.ContainsAny(url, " \t\n<") {
+		return false
+	}
+
+	return strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://") || strings.HasPrefix(url, "mailto:")
+}
+
+func canOpen(source string, i int) bool {
+	run := countRun(source[i:], source[i])
+	next := i + run
+	if next >= len(source) || unicode.IsSpace(rune(source[next])) {
+		return false
+	}
+
+	if source[i] == '_' && i > 0 && isWordCharacter(source[i-1]) {
+		return false
+	}
+
+	return true
+}
+
+func findClosing(source string, from int, delimiter byte, run int) int {
+	for i := from; i < len(source); i++ {
+		switch source[i] {
+		case '\\':
+			i++
+		case '`':
+			skip := countRun(source[i:], '`')
+			closing := strings.Index(source[i+skip:], strings.Repeat("`", skip))
+			if closing >= 0 {
+				i += skip + closing + skip - 1
+			}
+		case delimiter:
+			length := countRun(source[i:], delimiter)
+			after := i + length
+			if length != run || i == from || unicode.IsSpace(rune(source[i-1])) {
+				i = after - 1
+				continue
+			}
+
+			if delimiter == '_' && after < len(source) && isWordCharacter(source[after]) {
+				i = after - 1
+				continue
+			}
+
+			return i
+		}
+	}
+
+	return -1
+}
+
+func countRun(source string, char byte) int {
+	count := 0
+	for count < len(source) && source[count] == char {
+		count++
+	}
+
+	return count
+}
+
+func isWordCharacter(char byte) bool {
+	return char == '_' || unicode.IsLetter(rune(char)) || unicode.IsDigit(rune(char))
+}
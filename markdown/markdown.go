@@ -0,0 +1,258 @@
+package markdown
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+)
+
+type Renderer struct{}
+
+func NewRenderer() Renderer {
+	return Renderer{}
+}
+
+var safeSchemes = map[string]bool{
+	"http":   true,
+	"https":  true,
+	"mailto": true,
+}
+
+func (r Renderer) Render(source string) (htmlContent, textContent string) {
+	source = strings.Replace(source, "\r\n", "\n", -1)
+	blocks := parseBlocks(strings.Split(source, "\n"))
+
+	return renderHTMLBlocks(blocks, false), renderTextBlocks(blocks)
+}
+
+// Fill renders the source into whichever of the HTML and text bodies is
+// empty, keeping a body that was given alongside it.
+func (r Renderer) Fill(source, htmlContent, textContent string) (string, string) {
+	renderedHTML, renderedText := r.Render(source)
+
+	if htmlContent == "" {
+		htmlContent = renderedHTML
+	}
+
+	if textContent == "" {
+		textContent = renderedText
+	}
+
+	return htmlContent, textContent
+}
+
+func renderHTMLBlocks(blocks []block, tight bool) string {
+	var parts []string
+
+	for _, b := range blocks {
+		switch b.kind {
+		case paragraphBlock:
+			content := renderHTMLInlines(parseInlines(strings.Join(b.lines, "\n")))
+			if tight {
+				parts = append(parts, content)
+			} else {
+				parts = append(parts, "<p>"+content+"</p>")
+			}
+		case headingBlock:
+			parts = append(parts, fmt.Sprintf("<h%d>%s</h%d>", b.level, renderHTMLInlines(parseInlines(b.lines[0])), b.level))
+		case codeBlock:
+			class := ""
+			if b.language != "" {
+				class = fmt.Sprintf(` class="language-%s"`, html.EscapeString(b.language))
+			}
+
+			code := ""
+			if len(b.lines) > 0 {
+				code = html.EscapeString(strings.Join(b.lines, "\n")) + "\n"
+			}
+			parts = append(parts, fmt.Sprintf("<pre><code%s>%s</code></pre>", class, code))
+		case quoteBlock:
+			parts = append(parts, "<blockquote>\n"+renderHTMLBlocks(b.children, false)+"\n</blockquote>")
+		case listBlock:
+			parts = append(parts, renderHTMLList(b))
+		case ruleBlock:
+			parts = append(parts, "<hr />")
+		}
+	}
+
+	return strings.Join(parts, "\n")
+}
+
+func renderHTMLList(list block) string {
+	tag := "ul"
+	opening := "<ul>"
+	if list.ordered {
+		tag = "ol"
+		opening = "<ol>"
+		if start, err := strconv.Atoi(list.start); err == nil && start != 1 {
+			opening = fmt.Sprintf(`<ol start="%d">`, start)
+		}
+	}
+
+	items := []string{opening}
+	for _, item := range list.items {
+		tight := len(item) <= 1 || item[0].kind == paragraphBlock && item[1].kind == listBlock
+		items = append(items, "<li>"+renderHTMLBlocks(item, tight)+"</li>")
+	}
+	items = append(items, "</"+tag+">")
+
+	return strings.Join(items, "\n")
+}
+
+func renderHTMLInlines(nodes []inline) string {
+	var output []string
+
+	for _, node := range nodes {
+		switch node.kind {
+		case textInline:
+			output = append(output, html.EscapeString(node.text))
+		case actionInline:
+			output = append(output, node.text)
+		case codeInline:
+			output = append(output, "<code>"+html.EscapeString(node.text)+"</code>")
+		case strongInline:
+			output = append(output, "<strong>"+renderHTMLInlines(node.children)+"</strong>")
+		case emphasisInline:
+			output = append(output, "<em>"+renderHTMLInlines(node.children)+"</em>")
+		case linkInline:
+			output = append(output, fmt.Sprintf(`<a href="%s">%s</a>`, escapeURL(node.url), renderHTMLInlines(node.children)))
+		case imageInline:
+			output = append(output, fmt.Sprintf(`<img src="%s" alt="%s" />`, escapeURL(node.url), html.EscapeString(node.text)))
+		case breakInline:
+			output = append(output, "<br />\n")
+		}
+	}
+
+	return strings.Join(output, "")
+}
+
+// escapeURL drops URLs with a scheme that could run script, such as
+// javascript:, keeping relative URLs and template actions.
+func escapeURL(url string) string {
+	if strings.HasPrefix(url, "{{") {
+		return url
+	}
+
+	if !safeScheme(url) {
+		return ""
+	}
+
+	return html.EscapeString(url)
+}
+
+func safeScheme(url string) bool {
+	// Browsers ignore whitespace and control characters inside a scheme.
+	cleaned := strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, url)
+
+	colon := strings.Index(cleaned, ":")
+	if colon == -1 || strings.ContainsAny(cleaned[:colon], "/?#") {
+		return true
+	}
+
+	return safeSchemes[strings.ToLower(cleaned[:colon])]
+}
+
+func renderTextBlocks(blocks []block) string {
+	var parts []string
+
+	for _, b := range blocks {
+		switch b.kind {
+		case paragraphBlock:
+			parts = append(parts, renderTextInlines(parseInlines(strings.Join(b.lines, "\n"))))
+		case headingBlock:
+			heading := renderTextInlines(parseInlines(b.lines[0]))
+			switch b.level {
+			case 1:
+				heading += "\n" + strings.Repeat("=", len(heading))
+			case 2:
+				heading += "\n" + strings.Repeat("-", len(heading))
+			}
+			parts = append(parts, heading)
+		case codeBlock:
+			var lines []string
+			for _, line := range b.lines {
+				lines = append(lines, "    "+line)
+			}
+			parts = append(parts, strings.Join(lines, "\n"))
+		case quoteBlock:
+			parts = append(parts, prefixLines(renderTextBlocks(b.children), "> ", "> "))
+		case listBlock:
+			parts = append(parts, renderTextList(b))
+		case ruleBlock:
+			parts = append(parts, strings.Repeat("-", 10))
+		}
+	}
+
+	return strings.Join(parts, "\n\n")
+}
+
+func renderTextList(list block) string {
+	start := 1
+	if list.ordered {
+		if value, err := strconv.Atoi(list.start); err == nil {
+			start = value
+		}
+	}
+
+	var items []string
+	for index, item := range list.items {
+		marker := "- "
+		if list.ordered {
+			marker = fmt.Sprintf("%d. ", start+index)
+		}
+
+		items = append(items, prefixLines(renderTextBlocks(item), marker, strings.Repeat(" ", len(marker))))
+	}
+
+	return strings.Join(items, "\n")
+}
+
+func renderTextInlines(nodes []inline) string {
+	var output []string
+
+	for _, node := range nodes {
+		switch node.kind {
+		case textInline, actionInline, codeInline:
+			output = append(output, node.text)
+		case strongInline, emphasisInline:
+			output = append(output, renderTextInlines(node.children))
+		case linkInline:
+			text := renderTextInlines(node.children)
+			if text == node.url || "mailto:"+text == node.url {
+				output = append(output, text)
+			} else {
+				output = append(output, fmt.Sprintf("%s (%s)", text, node.url))
+			}
+		case imageInline:
+			output = append(output, fmt.Sprintf("[%s] (%s)", node.text, node.url))
+		case breakInline:
+			output = append(output, "\n")
+		}
+	}
+
+	return strings.Join(output, "")
+}
+
+func prefixLines(content, first, rest string) string {
+	lines := strings.Split(content, "\n")
+	for index, line := range lines {
+		prefix := rest
+		if index == 0 {
+			prefix = first
+		}
+
+		if line == "" {
+			lines[index] = strings.TrimRight(prefix, " ")
+		} else {
+			lines[index] = prefix + line
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,97 @@
+package markdown_test
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/markdown"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Renderer", func() {
+	var renderer markdown.Renderer
+
+	BeforeEach(func() {
+		renderer = markdown.NewRenderer()
+	})
+
+	Describe("Render", func() {
+		It("renders paragraphs and headings", func() {
+			html, text := renderer.Render("# Maintenance\n\nThe platform will be\nunavailable tonight.\n\n### Details")
+
+			Expect(html).To(Equal("<h1>Maintenance</h1>\n<p>The platform will be\nunavailable tonight.</p>\n<h3>Details</h3>"))
+			Expect(text).To(Equal("Maintenance\n===========\n\nThe platform will be\nunavailable tonight.\n\nDetails"))
+		})
+
+		It("renders emphasis, code spans and hard line breaks", func() {
+			html, text := renderer.Render("Some **bold** and *italic* and `code`  \nand snake_case_words")
+
+			Expect(html).To(Equal("<p>Some <strong>bold</strong> and <em>italic</em> and <code>code</code><br />\nand snake_case_words</p>"))
+			Expect(text).To(Equal("Some bold and italic and code\nand snake_case_words"))
+		})
+
+		It("renders links and images, keeping the URL in the text part", func() {
+			html, text := renderer.Render("See [the docs](https://example.com/docs) or <https://example.com>.\n\n![logo](https://example.com/logo.png)")
+
+			Expect(html).To(Equal(`<p>See <a href="https://example.com/docs">the docs</a> or <a href="https://example.com">https://example.com</a>.</p>` + "\n" +
+				`<p><img src="https://example.com/logo.png" alt="logo" /></p>`))
+			Expect(text).To(Equal("See the docs (https://example.com/docs) or https://example.com.\n\n[logo] (https://example.com/logo.png)"))
+		})
+
+		It("renders unordered, ordered and nested lists", func() {
+			html, text := renderer.Render("- first\n- second\n  1. nested\n  2. another\n\n3. three\n4. four")
+
+			Expect(html).To(Equal("<ul>\n<li>first</li>\n<li>second\n<ol>\n<li>nested</li>\n<li>another</li>\n</ol></li>\n</ul>\n" +
+				"<ol start=\"3\">\n<li>three</li>\n<li>four</li>\n</ol>"))
+			Expect(text).To(Equal("- first\n- second\n\n  1. nested\n  2. another\n\n3. three\n4. four"))
+		})
+
+		It("renders fenced code blocks, block quotes and rules", func() {
+			html, text := renderer.Render("```sh\ncf push <app>\n```\n\n> quoted *text*\n\n---")
+
+			Expect(html).To(Equal("<pre><code class=\"language-sh\">cf push &lt;app&gt;\n</code></pre>\n<blockquote>\n<p>quoted <em>text</em></p>\n</blockquote>\n<hr />"))
+			Expect(text).To(Equal("    cf push <app>\n\n> quoted text\n\n----------"))
+		})
+
+		It("escapes HTML in the source", func() {
+			html, _ := renderer.Render(`<script>alert("hi")</script>`)
+
+			Expect(html).To(Equal("<p>&lt;script&gt;alert(&#34;hi&#34;)&lt;/script&gt;</p>"))
+		})
+
+		It("passes template actions through untouched", func() {
+			html, text := renderer.Render(`Hello {{.To}}, see [{{template "link" .}}]({{.Domain}}) and *{{.Subject}}*`)
+
+			Expect(html).To(Equal(`<p>Hello {{.To}}, see <a href="{{.Domain}}">{{template "link" .}}</a> and <em>{{.Subject}}</em></p>`))
+			Expect(text).To(Equal(`Hello {{.To}}, see {{template "link" .}} ({{.Domain}}) and {{.Subject}}`))
+		})
+
+		It("drops link and image URLs with an unsafe scheme", func() {
+			html, _ := renderer.Render("[click](javascript:alert%281%29) [again](JaVaScRiPt:void) ![img](data:text/html,x) [ok](/relative:path)")
+
+			Expect(html).To(Equal(`<p><a href="">click</a> <a href="">again</a> <img src="" alt="img" /> <a href="/relative:path">ok</a></p>`))
+		})
+
+		It("treats unmatched delimiters as literal text", func() {
+			html, text := renderer.Render("2 * 3 = 6 and [not a link] and **open")
+
+			Expect(html).To(Equal("<p>2 * 3 = 6 and [not a link] and **open</p>"))
+			Expect(text).To(Equal("2 * 3 = 6 and [not a link] and **open"))
+		})
+	})
+
+	Describe("Fill", func() {
+		It("renders the bodies that are empty", func() {
+			html, text := renderer.Fill("*hi*", "", "hand written")
+
+			Expect(html).To(Equal("<p><em>hi</em></p>"))
+			Expect(text).To(Equal("hand written"))
+		})
+
+		It("leaves the bodies alone when there is no source", func() {
+			html, text := renderer.Fill("", "", "hand written")
+
+			Expect(html).To(BeEmpty())
+			Expect(text).To(Equal("hand written"))
+		})
+	})
+})
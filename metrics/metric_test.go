@@ -37,4 +37,17 @@ var _ = Describe("Metric", func() {
 
 		Expect(message).To(Equal(`[METRIC] {"kind":"counter","payload":{"name":"test"}}` + "\n"))
 	})
+
+	It("records itself in the default registry, even without a logger", func() {
+		metric := metrics.NewMetric("counter", map[string]interface{}{
+			"name": "notifications.metric.test",
+		})
+		metric.LogWith(nil)
+
+		registry := bytes.NewBuffer([]byte{})
+		_, err := metrics.DefaultRegistry.WriteTo(registry)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(registry.String()).To(ContainSubstring("notifications_metric_test_total 1\n"))
+		Expect(buffer.String()).To(BeEmpty())
+	})
 })
@@ -29,7 +29,15 @@ func (metric Metric) Log() {
 	metric.LogWith(DefaultLogger)
 }
 
+// LogWith records the metric in the DefaultRegistry and writes it to the
+// given logger. A nil logger only records the metric.
 func (metric Metric) LogWith(logger *log.Logger) {
+	DefaultRegistry.Record(metric)
+
+	if logger == nil {
+		return
+	}
+
 	message, err := json.Marshal(metric)
 	if err != nil {
 		panic(err)
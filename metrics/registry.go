@@ -0,0 +1,245 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var DefaultRegistry = NewRegistry()
+
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+var invalidNameCharacters = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// labelValueEscaper escapes label values as the Prometheus text format
+// requires. Unlike Go quoting, every other character is written as it is.
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+type series struct {
+	name   string
+	labels map[string]string
+}
+
+// key sorts every series of a metric together, ahead of any metric whose name
+// it is a prefix of.
+func (s series) key() string {
+	return s.name + "\x00" + s.labelString("")
+}
+
+func (s series) labelString(extra string) string {
+	var pairs []string
+	for _, name := range sortedKeys(s.labels) {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, invalidNameCharacters.ReplaceAllString(name, "_"), labelValueEscaper.Replace(s.labels[name])))
+	}
+
+	if extra != "" {
+		pairs = append(pairs, extra)
+	}
+
+	if len(pairs) == 0 {
+		return ""
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+type histogram struct {
+	series
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+type sample struct {
+	series
+	value float64
+}
+
+// Registry keeps the latest value of every metric so that it can be scraped
+// in the Prometheus text exposition format.
+type Registry struct {
+	mutex      sync.Mutex
+	buckets    []float64
+	counters   map[string]*sample
+	gauges     map[string]*sample
+	histograms map[string]*histogram
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		buckets:    DefaultBuckets,
+		counters:   map[string]*sample{},
+		gauges:     map[string]*sample{},
+		histograms: map[string]*histogram{},
+	}
+}
+
+func (r *Registry) Record(metric Metric) {
+	name, _ := metric.Payload["name"].(string)
+	if name == "" {
+		return
+	}
+
+	s := series{
+		name:   invalidNameCharacters.ReplaceAllString(name, "_"),
+		labels: tagsToLabels(metric.Payload["tags"]),
+	}
+	value, hasValue := toFloat(metric.Payload["value"])
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	switch metric.Kind {
+	case "counter":
+		s.name += "_total"
+		counter, ok := r.counters[s.key()]
+		if !ok {
+			counter = &sample{series: s}
+			r.counters[s.key()] = counter
+		}
+
+		if !hasValue {
+			value = 1
+		}
+		counter.value += value
+	case "gauge":
+		r.gauges[s.key()] = &sample{series: s, value: value}
+	case "histogram":
+		s.name += "_seconds"
+		h, ok := r.histograms[s.key()]
+		if !ok {
+			h = &histogram{series: s, counts: make([]uint64, len(r.buckets))}
+			r.histograms[s.key()] = h
+		}
+
+		for i, bound := range r.buckets {
+			if value <= bound {
+				h.counts[i]++
+			}
+		}
+		h.sum += value
+		h.count++
+	}
+}
+
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var lines []string
+	lines = append(lines, writeSamples("counter", r.counters)...)
+	lines = append(lines, writeSamples("gauge", r.gauges)...)
+
+	var typed string
+	for _, key := range sortedHistogramKeys(r.histograms) {
+		h := r.histograms[key]
+		if h.name != typed {
+			lines = append(lines, fmt.Sprintf("# TYPE %s histogram", h.name))
+			typed = h.name
+		}
+
+		for i, bound := range r.buckets {
+			le := fmt.Sprintf(`le="%s"`, strconv.FormatFloat(bound, 'g', -1, 64))
+			lines = append(lines, fmt.Sprintf("%s_bucket%s %d", h.name, h.labelString(le), h.counts[i]))
+		}
+		lines = append(lines, fmt.Sprintf("%s_bucket%s %d", h.name, h.labelString(`le="+Inf"`), h.count))
+		lines = append(lines, fmt.Sprintf("%s_sum%s %s", h.name, h.labelString(""), formatValue(h.sum)))
+		lines = append(lines, fmt.Sprintf("%s_count%s %d", h.name, h.labelString(""), h.count))
+	}
+
+	if len(lines) == 0 {
+		return 0, nil
+	}
+
+	n, err := io.WriteString(w, strings.Join(lines, "\n")+"\n")
+	return int64(n), err
+}
+
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	r.WriteTo(w)
+}
+
+func writeSamples(kind string, samples map[string]*sample) []string {
+	var keys []string
+	for key := range samples {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var lines []string
+	var typed string
+	for _, key := range keys {
+		s := samples[key]
+		if s.name != typed {
+			lines = append(lines, fmt.Sprintf("# TYPE %s %s", s.name, kind))
+			typed = s.name
+		}
+
+		lines = append(lines, fmt.Sprintf("%s%s %s", s.name, s.labelString(""), formatValue(s.value)))
+	}
+
+	return lines
+}
+
+func sortedHistogramKeys(histograms map[string]*histogram) []string {
+	var keys []string
+	for key := range histograms {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func sortedKeys(labels map[string]string) []string {
+	var keys []string
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func tagsToLabels(tags interface{}) map[string]string {
+	labels := map[string]string{}
+
+	switch t := tags.(type) {
+	case map[string]string:
+		for key, value := range t {
+			labels[key] = value
+		}
+	case map[string]interface{}:
+		for key, value := range t {
+			labels[key] = fmt.Sprintf("%v", value)
+		}
+	}
+
+	return labels
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+func formatValue(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
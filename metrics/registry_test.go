@@ -0,0 +1,131 @@
+package metrics_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/notifications/metrics"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Registry", func() {
+	var registry *metrics.Registry
+
+	BeforeEach(func() {
+		registry = metrics.NewRegistry()
+	})
+
+	It("counts counters by name and tags", func() {
+		registry.Record(metrics.NewMetric("counter", map[string]interface{}{
+			"name": "notifications.worker.delivered",
+		}))
+		registry.Record(metrics.NewMetric("counter", map[string]interface{}{
+			"name": "notifications.worker.delivered",
+		}))
+		registry.Record(metrics.NewMetric("counter", map[string]interface{}{
+			"name": "notifications.web",
+			"tags": map[string]string{
+				"endpoint": "GET/info",
+			},
+		}))
+
+		buffer := bytes.NewBuffer([]byte{})
+		_, err := registry.WriteTo(buffer)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(buffer.String()).To(Equal(`# TYPE notifications_web_total counter
+notifications_web_total{endpoint="GET/info"} 1
+# TYPE notifications_worker_delivered_total counter
+notifications_worker_delivered_total 2
+`))
+	})
+
+	It("keeps the latest value of each gauge", func() {
+		for _, value := range []int{3, 1} {
+			registry.Record(metrics.NewMetric("gauge", map[string]interface{}{
+				"name":  "notifications.queue.retry",
+				"tags":  map[string]interface{}{"count": "0"},
+				"value": value,
+			}))
+		}
+
+		buffer := bytes.NewBuffer([]byte{})
+		_, err := registry.WriteTo(buffer)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(buffer.String()).To(Equal(`# TYPE notifications_queue_retry gauge
+notifications_queue_retry{count="0"} 1
+`))
+	})
+
+	It("buckets histogram values in seconds", func() {
+		for _, value := range []float64{0.02, 0.3, 20} {
+			registry.Record(metrics.NewMetric("histogram", map[string]interface{}{
+				"name":  "notifications.external-requests.smtp.send",
+				"value": value,
+			}))
+		}
+
+		buffer := bytes.NewBuffer([]byte{})
+		_, err := registry.WriteTo(buffer)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(buffer.String()).To(Equal(`# TYPE notifications_external_requests_smtp_send_seconds histogram
+notifications_external_requests_smtp_send_seconds_bucket{le="0.005"} 0
+notifications_external_requests_smtp_send_seconds_bucket{le="0.01"} 0
+notifications_external_requests_smtp_send_seconds_bucket{le="0.025"} 1
+notifications_external_requests_smtp_send_seconds_bucket{le="0.05"} 1
+notifications_external_requests_smtp_send_seconds_bucket{le="0.1"} 1
+notifications_external_requests_smtp_send_seconds_bucket{le="0.25"} 1
+notifications_external_requests_smtp_send_seconds_bucket{le="0.5"} 2
+notifications_external_requests_smtp_send_seconds_bucket{le="1"} 2
+notifications_external_requests_smtp_send_seconds_bucket{le="2.5"} 2
+notifications_external_requests_smtp_send_seconds_bucket{le="5"} 2
+notifications_external_requests_smtp_send_seconds_bucket{le="10"} 2
+notifications_external_requests_smtp_send_seconds_bucket{le="+Inf"} 3
+notifications_external_requests_smtp_send_seconds_sum 20.32
+notifications_external_requests_smtp_send_seconds_count 3
+`))
+	})
+
+	It("ignores metrics without a name", func() {
+		registry.Record(metrics.NewMetric("counter", map[string]interface{}{}))
+
+		buffer := bytes.NewBuffer([]byte{})
+		_, err := registry.WriteTo(buffer)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(buffer.String()).To(BeEmpty())
+	})
+
+	It("escapes only backslashes, double quotes and newlines in label values", func() {
+		registry.Record(metrics.NewMetric("counter", map[string]interface{}{
+			"name": "notifications.web",
+			"tags": map[string]string{
+				"endpoint": "GET/caf\u00e9\t\"quoted\"\\path\nnext",
+			},
+		}))
+
+		buffer := bytes.NewBuffer([]byte{})
+		_, err := registry.WriteTo(buffer)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(buffer.String()).To(Equal("# TYPE notifications_web_total counter\n" +
+			"notifications_web_total{endpoint=\"GET/caf\u00e9\t\\\"quoted\\\"\\\\path\\nnext\"} 1\n"))
+	})
+
+	It("serves the metrics in the Prometheus text format", func() {
+		registry.Record(metrics.NewMetric("gauge", map[string]interface{}{
+			"name":  "notifications.queue.length",
+			"value": 7,
+		}))
+
+		writer := httptest.NewRecorder()
+		request, err := http.NewRequest("GET", "/metrics", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		registry.ServeHTTP(writer, request)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Header().Get("Content-Type")).To(Equal("text/plain; version=0.0.4"))
+		Expect(writer.Body.String()).To(Equal("# TYPE notifications_queue_length gauge\nnotifications_queue_length 7\n"))
+	})
+})
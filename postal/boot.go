@@ -6,6 +6,7 @@ import (
 	"os"
 	"time"
 
+	"github.com/cloudfoundry-incubator/notifications/cache"
 	"github.com/cloudfoundry-incubator/notifications/cf"
 	"github.com/cloudfoundry-incubator/notifications/db"
 	"github.com/cloudfoundry-incubator/notifications/gobble"
@@ -47,10 +48,13 @@ type Config struct {
 	Domain               string
 	QueueWaitMaxDuration int
 	CCHost               string
+	CCAPIVersion         int
+	DefaultUAAScopes     []string
+	LookupCache          cache.Cache
 }
 
 func Boot(mom mother, config Config) {
-	uaaClient := uaa.NewZonedUAAClient(config.UAAClientID, config.UAAClientSecret, config.VerifySSL, config.UAAPublicKey)
+	uaaClient := uaa.NewCachedZonedUAAClient(uaa.NewZonedUAAClient(config.UAAClientID, config.UAAClientSecret, config.VerifySSL, config.UAAPublicKey), config.LookupCache)
 
 	logger := lager.NewLogger("notifications")
 	logger.RegisterSink(lager.NewWriterSink(os.Stdout, lager.DEBUG))
@@ -70,6 +74,7 @@ func Boot(mom mother, config Config) {
 		panic(err)
 	}
 
+	signer := util.NewSigner(config.EncryptionKey)
 	guidGenerator := util.NewIDGenerator(rand.Reader)
 
 	// V1
@@ -85,36 +90,80 @@ func Boot(mom mother, config Config) {
 	messageStatusUpdater := v1.NewMessageStatusUpdater(messagesRepo)
 	userLoader := common.NewUserLoader(uaaClient)
 	tokenLoader := uaa.NewTokenLoader(uaaClient)
-	packager := common.NewPackager(v1TemplateLoader, cloak)
-
 	// V2
+	v2database := v2models.NewDatabase(sqlDatabase, v2models.Config{})
+	partialsRepository := v2models.NewPartialsRepository(guidGenerator.Generate)
+	partialsCollection := collections.NewPartialsCollection(partialsRepository)
+	partialsLoader := v2.NewPartialsLoader(v2database, partialsCollection, clock)
+	packager := common.NewPackager(v1TemplateLoader, partialsLoader, cloak, signer)
+
 	metricsEmitter := metrics.NewEmitter(metrics.DefaultLogger)
 
 	messagesRepository := v2models.NewMessagesRepository(clock, guidGenerator.Generate)
 	gobbleInitializer := gobble.Initializer{}
 
-	v2enqueuer := queue.NewJobEnqueuer(gobbleQueue, messagesRepository, gobbleInitializer)
+	campaignRecipientsRepository := v2models.NewCampaignRecipientsRepository()
 
-	cloudController := cf.NewCloudController(config.CCHost, !config.VerifySSL)
+	v2enqueuer := queue.NewJobEnqueuer(gobbleQueue, messagesRepository, campaignRecipientsRepository, gobbleInitializer)
+
+	cloudController := cf.NewCachedCloudController(cf.NewCloudControllerForVersion(config.CCHost, !config.VerifySSL, config.CCAPIVersion), config.LookupCache)
 	spaceLoader := services.NewSpaceLoader(cloudController)
 	organizationLoader := services.NewOrganizationLoader(cloudController)
 	findsUserIDs := services.NewFindsUserIDs(cloudController, uaaClient)
+	allUsers := services.NewAllUsers(uaaClient)
+
+	audienceGenerators := horde.NewGenerators(horde.GeneratorsConfig{
+		UserFinder:       findsUserIDs,
+		OrgFinder:        organizationLoader,
+		SpaceFinder:      spaceLoader,
+		AllUsers:         allUsers,
+		TokenLoader:      tokenLoader,
+		Database:         v2database,
+		SavedAudiences:   v2models.NewAudiencesRepository(guidGenerator.Generate, clock),
+		UAAHost:          config.UAAHost,
+		DefaultUAAScopes: config.DefaultUAAScopes,
+	})
 
-	usersAudienceGenerator := horde.NewUsers()
-	emailsAudienceGenerator := horde.NewEmails()
-	spacesAudienceGenerator := horde.NewSpaces(findsUserIDs, organizationLoader, spaceLoader, tokenLoader, config.UAAHost)
-	orgsAudienceGenerator := horde.NewOrganizations(findsUserIDs, organizationLoader, tokenLoader, config.UAAHost)
-
-	v2database := v2models.NewDatabase(sqlDatabase, v2models.Config{})
 	v2messageStatusUpdater := v2.NewV2MessageStatusUpdater(messagesRepository)
 	unsubscribersRepository := v2models.NewUnsubscribersRepository(guidGenerator.Generate)
 	campaignsRepository := v2models.NewCampaignsRepository(guidGenerator.Generate, clock)
+	campaignTypesRepository := v2models.NewCampaignTypesRepository(guidGenerator.Generate)
+	globalUnsubscribesRepository := v2models.NewGlobalUnsubscribesRepository(clock)
+	emailUnsubscribesRepository := v2models.NewEmailUnsubscribesRepository(clock)
+	digestPreferencesRepository := v2models.NewDigestPreferencesRepository()
+	digestsRepository := v2models.NewDigestsRepository(clock, guidGenerator.Generate)
+	quietHoursRepository := v2models.NewQuietHoursRepository()
+	messageTimingsRepository := v2models.NewMessageTimingsRepository(clock)
 	v2templatesRepo := v2models.NewTemplatesRepository(guidGenerator.Generate)
 	templatesCollection := collections.NewTemplatesCollection(v2templatesRepo)
 	v2TemplateLoader := v2.NewTemplatesLoader(v2database, templatesCollection)
 	v2deliveryFailureHandler := common.NewDeliveryFailureHandler()
 	campaignJobProcessor := v2.NewCampaignJobProcessor(notify.EmailFormatter{}, notify.HTMLExtractor{},
-		emailsAudienceGenerator, spacesAudienceGenerator, orgsAudienceGenerator, usersAudienceGenerator, v2enqueuer)
+		audienceGenerators, campaignsRepository, v2models.NewCampaignFiltersRepository(), campaignRecipientsRepository, v2enqueuer, tokenLoader, userLoader, clock)
+
+	digestSenderHolder, err := guidGenerator.Generate()
+	if err != nil {
+		panic(err)
+	}
+
+	NewDigestSender(DigestSenderConfig{
+		Database:             v2database,
+		Digests:              digestsRepository,
+		Leases:               v2models.NewLeasesRepository(clock),
+		GlobalUnsubscribes:   globalUnsubscribesRepository,
+		Unsubscribers:        unsubscribersRepository,
+		KindUnsubscribes:     v2models.NewKindUnsubscribesRepository(clock),
+		MailClient:           mom.MailClient(),
+		MessageStatusUpdater: v2messageStatusUpdater,
+		Signer:               signer,
+		Clock:                clock,
+		Logger:               logger,
+		Sender:               config.Sender,
+		Domain:               config.Domain,
+		Holder:               digestSenderHolder,
+		PollingInterval:      time.Minute,
+		LeaseDuration:        10 * time.Minute,
+	}).Run()
 
 	WorkerGenerator{
 		InstanceIndex: config.InstanceIndex,
@@ -139,15 +188,21 @@ func Boot(mom mother, config Config) {
 			ReceiptsRepo:           receiptsRepo,
 			UnsubscribesRepo:       unsubscribesRepo,
 			GlobalUnsubscribesRepo: globalUnsubscribesRepo,
+			EmailUnsubscribesRepo:  emailUnsubscribesRepository,
+			DigestPreferencesRepo:  digestPreferencesRepository,
+			DigestsRepo:            digestsRepository,
+			QuietHoursRepo:         quietHoursRepository,
 			MessageStatusUpdater:   messageStatusUpdater,
 			DeliveryFailureHandler: deliveryFailureHandler,
 		})
 
 		v2mailClient := mom.MailClient()
 
-		v2DeliveryJobProcessor := v2.NewDeliveryJobProcessor(v2mailClient, common.NewPackager(v2TemplateLoader, cloak),
+		v2DeliveryJobProcessor := v2.NewDeliveryJobProcessor(v2mailClient, common.NewPackager(v2TemplateLoader, partialsLoader, cloak, signer),
 			common.NewUserLoader(uaaClient), uaa.NewTokenLoader(uaaClient), v2messageStatusUpdater, v2database,
-			unsubscribersRepository, campaignsRepository, config.Sender, config.Domain, config.UAAHost, metricsEmitter)
+			unsubscribersRepository, globalUnsubscribesRepository, emailUnsubscribesRepository,
+			digestPreferencesRepository, digestsRepository, quietHoursRepository, campaignsRepository, campaignTypesRepository,
+			messageTimingsRepository, config.Sender, config.Domain, config.UAAHost, metricsEmitter, clock)
 
 		worker := NewDeliveryWorker(v1DeliveryJobProcessor, v2DeliveryJobProcessor, DeliveryWorkerConfig{
 			ID:      index,
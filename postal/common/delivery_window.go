@@ -0,0 +1,100 @@
+package common
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	businessHoursStart = 9 * 60
+	businessHoursEnd   = 17 * 60
+)
+
+// DeliveryDeferredError is returned by a delivery job processor when the
+// recipient is outside of their delivery window. The job should be requeued to
+// run again at Until.
+type DeliveryDeferredError struct {
+	Until time.Time
+}
+
+func (e DeliveryDeferredError) Error() string {
+	return fmt.Sprintf("delivery deferred until %s", e.Until.Format(time.RFC3339))
+}
+
+// DeliveryWindow describes when a recipient may be sent non-critical
+// notifications. Quiet hours are "HH:MM" times in the recipient's time zone,
+// and business hours are 09:00 to 17:00, Monday to Friday, in that same zone.
+type DeliveryWindow struct {
+	TimeZone      string
+	QuietStart    string
+	QuietEnd      string
+	BusinessHours bool
+}
+
+// Next returns the earliest time at or after now that falls inside the window.
+func (w DeliveryWindow) Next(now time.Time) time.Time {
+	location, err := time.LoadLocation(w.TimeZone)
+	if err != nil {
+		location = time.UTC
+	}
+
+	quietStart, hasQuietStart := minuteOfDay(w.QuietStart)
+	quietEnd, hasQuietEnd := minuteOfDay(w.QuietEnd)
+	hasQuietHours := hasQuietStart && hasQuietEnd && quietStart != quietEnd
+
+	moment := now.In(location)
+
+	// Quiet hours can cover all of business hours, so give up after a week's
+	// worth of adjustments rather than looping forever.
+	for attempts := 0; attempts < 14; attempts++ {
+		minute := moment.Hour()*60 + moment.Minute()
+
+		if hasQuietHours && inRange(minute, quietStart, quietEnd) {
+			moment = atMinute(moment, quietEnd)
+			if minute >= quietEnd {
+				moment = moment.AddDate(0, 0, 1)
+			}
+			continue
+		}
+
+		if w.BusinessHours && !(isWeekday(moment) && inRange(minute, businessHoursStart, businessHoursEnd)) {
+			if !isWeekday(moment) || minute >= businessHoursEnd {
+				moment = moment.AddDate(0, 0, 1)
+			}
+			for !isWeekday(moment) {
+				moment = moment.AddDate(0, 0, 1)
+			}
+			moment = atMinute(moment, businessHoursStart)
+			continue
+		}
+
+		return moment
+	}
+
+	return moment
+}
+
+func minuteOfDay(clockTime string) (int, bool) {
+	parsed, err := time.Parse("15:04", clockTime)
+	if err != nil {
+		return 0, false
+	}
+
+	return parsed.Hour()*60 + parsed.Minute(), true
+}
+
+func inRange(minute, start, end int) bool {
+	if start < end {
+		return minute >= start && minute < end
+	}
+
+	return minute >= start || minute < end
+}
+
+func atMinute(moment time.Time, minute int) time.Time {
+	return time.Date(moment.Year(), moment.Month(), moment.Day(), minute/60, minute%60, 0, 0, moment.Location())
+}
+
+func isWeekday(moment time.Time) bool {
+	return moment.Weekday() != time.Saturday && moment.Weekday() != time.Sunday
+}
@@ -0,0 +1,116 @@
+package common_test
+
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DeliveryWindow", func() {
+	var newYork *time.Location
+
+	BeforeEach(func() {
+		var err error
+		newYork, err = time.LoadLocation("America/New_York")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	Describe("Next", func() {
+		It("returns now when there are no restrictions", func() {
+			now := time.Date(2015, time.June, 10, 3, 0, 0, 0, time.UTC)
+
+			Expect(common.DeliveryWindow{}.Next(now)).To(BeTemporally("==", now))
+		})
+
+		Context("with overnight quiet hours", func() {
+			var window common.DeliveryWindow
+
+			BeforeEach(func() {
+				window = common.DeliveryWindow{
+					TimeZone:   "America/New_York",
+					QuietStart: "22:00",
+					QuietEnd:   "07:00",
+				}
+			})
+
+			It("returns now outside of quiet hours", func() {
+				now := time.Date(2015, time.June, 10, 12, 0, 0, 0, newYork)
+
+				Expect(window.Next(now)).To(BeTemporally("==", now))
+			})
+
+			It("pushes late evening deliveries to the next morning", func() {
+				now := time.Date(2015, time.June, 10, 23, 15, 0, 0, newYork)
+
+				Expect(window.Next(now)).To(BeTemporally("==", time.Date(2015, time.June, 11, 7, 0, 0, 0, newYork)))
+			})
+
+			It("pushes early morning deliveries to the end of quiet hours", func() {
+				now := time.Date(2015, time.June, 10, 7, 0, 0, 0, time.UTC)
+
+				Expect(window.Next(now)).To(BeTemporally("==", time.Date(2015, time.June, 10, 7, 0, 0, 0, newYork)))
+			})
+		})
+
+		It("handles quiet hours within a single day", func() {
+			window := common.DeliveryWindow{QuietStart: "12:00", QuietEnd: "13:30"}
+			now := time.Date(2015, time.June, 10, 12, 45, 0, 0, time.UTC)
+
+			Expect(window.Next(now)).To(BeTemporally("==", time.Date(2015, time.June, 10, 13, 30, 0, 0, time.UTC)))
+		})
+
+		Context("with business hours", func() {
+			var window common.DeliveryWindow
+
+			BeforeEach(func() {
+				window = common.DeliveryWindow{
+					TimeZone:      "America/New_York",
+					BusinessHours: true,
+				}
+			})
+
+			It("returns now during business hours", func() {
+				now := time.Date(2015, time.June, 10, 10, 0, 0, 0, newYork)
+
+				Expect(window.Next(now)).To(BeTemporally("==", now))
+			})
+
+			It("pushes early deliveries to the start of the business day", func() {
+				now := time.Date(2015, time.June, 10, 6, 0, 0, 0, newYork)
+
+				Expect(window.Next(now)).To(BeTemporally("==", time.Date(2015, time.June, 10, 9, 0, 0, 0, newYork)))
+			})
+
+			It("pushes Friday evening deliveries to Monday morning", func() {
+				now := time.Date(2015, time.June, 12, 18, 0, 0, 0, newYork)
+
+				Expect(window.Next(now)).To(BeTemporally("==", time.Date(2015, time.June, 15, 9, 0, 0, 0, newYork)))
+			})
+
+			It("respects quiet hours that start during business hours", func() {
+				window.QuietStart = "08:00"
+				window.QuietEnd = "10:00"
+				now := time.Date(2015, time.June, 10, 6, 0, 0, 0, newYork)
+
+				Expect(window.Next(now)).To(BeTemporally("==", time.Date(2015, time.June, 10, 10, 0, 0, 0, newYork)))
+			})
+		})
+
+		It("falls back to UTC for an unknown time zone", func() {
+			window := common.DeliveryWindow{TimeZone: "Mars/Olympus_Mons", BusinessHours: true}
+			now := time.Date(2015, time.June, 10, 6, 0, 0, 0, time.UTC)
+
+			Expect(window.Next(now)).To(BeTemporally("==", time.Date(2015, time.June, 10, 9, 0, 0, 0, time.UTC)))
+		})
+
+		It("gives up when quiet hours cover every business hour", func() {
+			window := common.DeliveryWindow{QuietStart: "08:00", QuietEnd: "18:00", BusinessHours: true}
+			now := time.Date(2015, time.June, 10, 12, 0, 0, 0, time.UTC)
+
+			Expect(window.Next(now)).To(BeTemporally(">", now))
+		})
+	})
+})
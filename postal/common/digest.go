@@ -0,0 +1,111 @@
+package common
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/mail"
+)
+
+const DigestSubjectTemplate = `Your {{.Frequency}} digest: {{len .Entries}} notification{{if ne (len .Entries) 1}}s{{end}}`
+
+const DigestTextTemplate = `{{range .Entries}}{{.Subject}} ({{.CreatedAt.Format "2006-01-02 15:04 MST"}})
+{{.Text}}
+
+{{end}}{{if .PreferencesURL}}Manage or unsubscribe from these notifications: {{.PreferencesURL}}{{end}}`
+
+const DigestHTMLTemplate = `<!DOCTYPE html>
+<html>
+	<body>
+		{{range .Entries}}<section>
+			<h3>{{.Subject}} <small>{{.CreatedAt.Format "2006-01-02 15:04 MST"}}</small></h3>
+			{{if .HTML}}{{.HTML}}{{else}}<pre>{{.Text}}</pre>{{end}}
+		</section>
+		{{end}}{{if .PreferencesURL}}<p><a href="{{.PreferencesURL}}">Manage or unsubscribe from these notifications</a></p>{{end}}
+	</body>
+</html>`
+
+type DigestEntry struct {
+	Subject   string
+	Text      string
+	HTML      htmltemplate.HTML
+	CreatedAt time.Time
+}
+
+type DigestContext struct {
+	From           string
+	To             string
+	Frequency      string
+	PreferencesURL string
+	Entries        []DigestEntry
+}
+
+// PackDigest compiles the notifications held for a user into a single
+// summary message.
+func PackDigest(context DigestContext) (mail.Message, error) {
+	subject, err := compileDigestText(DigestSubjectTemplate, context)
+	if err != nil {
+		return mail.Message{}, err
+	}
+
+	text, err := compileDigestText(DigestTextTemplate, context)
+	if err != nil {
+		return mail.Message{}, err
+	}
+
+	source, err := htmltemplate.New("digest").Parse(DigestHTMLTemplate)
+	if err != nil {
+		return mail.Message{}, err
+	}
+
+	buffer := bytes.NewBuffer([]byte{})
+	err = source.Execute(buffer, context)
+	if err != nil {
+		return mail.Message{}, err
+	}
+
+	headers := []string{
+		"X-CF-Notification-Digest: " + context.Frequency,
+		"X-CF-Notification-Timestamp: " + time.Now().Format(time.RFC3339Nano),
+	}
+
+	if context.PreferencesURL != "" {
+		headers = append(headers, fmt.Sprintf("List-Unsubscribe: <%s>", context.PreferencesURL))
+	}
+
+	return mail.Message{
+		From:    context.From,
+		To:      context.To,
+		Subject: subject,
+		Body: []mail.Part{
+			{
+				ContentType: "text/plain",
+				Content:     strings.TrimSpace(text),
+			},
+			{
+				ContentType: "text/html",
+				Content:     buffer.String(),
+			},
+		},
+		Headers: headers,
+	}, nil
+}
+
+func compileDigestText(theTemplate string, context DigestContext) (string, error) {
+	source, err := template.New("digest").Parse(theTemplate)
+	if err != nil {
+		return "", err
+	}
+
+	buffer := bytes.NewBuffer([]byte{})
+	err = source.Execute(buffer, context)
+	if err != nil {
+		return "", err
+	}
+
+	return buffer.String(), nil
+}
@@ -0,0 +1,75 @@
+package common_test
+
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PackDigest", func() {
+	var context common.DigestContext
+
+	BeforeEach(func() {
+		createdAt := time.Date(2015, time.June, 8, 14, 38, 0, 0, time.UTC)
+
+		context = common.DigestContext{
+			From:      "sender@example.com",
+			To:        "user@example.com",
+			Frequency: "hourly",
+			Entries: []common.DigestEntry{
+				{
+					Subject:   "Build passed",
+					Text:      "build #1 passed",
+					HTML:      "<p>build #1 <b>passed</b></p>",
+					CreatedAt: createdAt,
+				},
+				{
+					Subject:   "Scaled <up>",
+					Text:      "scaled to 3 instances",
+					CreatedAt: createdAt.Add(time.Minute),
+				},
+			},
+		}
+	})
+
+	It("compiles the held notifications into a single message", func() {
+		message, err := common.PackDigest(context)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(message.From).To(Equal("sender@example.com"))
+		Expect(message.To).To(Equal("user@example.com"))
+		Expect(message.Subject).To(Equal("Your hourly digest: 2 notifications"))
+		Expect(message.Headers).To(ContainElement("X-CF-Notification-Digest: hourly"))
+
+		Expect(message.Body).To(HaveLen(2))
+		Expect(message.Body[0].ContentType).To(Equal("text/plain"))
+		Expect(message.Body[0].Content).To(Equal("Build passed (2015-06-08 14:38 UTC)\nbuild #1 passed\n\nScaled <up> (2015-06-08 14:39 UTC)\nscaled to 3 instances"))
+
+		Expect(message.Body[1].ContentType).To(Equal("text/html"))
+		Expect(message.Body[1].Content).To(ContainSubstring("<p>build #1 <b>passed</b></p>"))
+		Expect(message.Body[1].Content).To(ContainSubstring("Scaled &lt;up&gt;"))
+		Expect(message.Body[1].Content).To(ContainSubstring("<pre>scaled to 3 instances</pre>"))
+	})
+
+	It("links to the preference center when there is one", func() {
+		context.PreferencesURL = "https://notifications.example.com/preferences?token=some-token"
+
+		message, err := common.PackDigest(context)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(message.Headers).To(ContainElement("List-Unsubscribe: <https://notifications.example.com/preferences?token=some-token>"))
+		Expect(message.Body[0].Content).To(HaveSuffix("Manage or unsubscribe from these notifications: https://notifications.example.com/preferences?token=some-token"))
+		Expect(message.Body[1].Content).To(ContainSubstring(`<a href="https://notifications.example.com/preferences?token=some-token">`))
+	})
+
+	It("uses the singular for a single notification", func() {
+		context.Entries = context.Entries[:1]
+
+		message, err := common.PackDigest(context)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(message.Subject).To(Equal("Your hourly digest: 1 notification"))
+	})
+})
@@ -0,0 +1,66 @@
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+)
+
+type tokenSigner interface {
+	Sign(payload []byte) string
+}
+
+type tokenVerifier interface {
+	Verify(token string) ([]byte, error)
+}
+
+// EmailUnsubscribe identifies what a recipient who was addressed by email
+// rather than by UAA user is opting out of. For v1 notifications the
+// CampaignTypeID holds the kind ID.
+type EmailUnsubscribe struct {
+	Email          string `json:"email"`
+	ClientID       string `json:"client_id"`
+	CampaignTypeID string `json:"campaign_type_id"`
+}
+
+func NewEmailUnsubscribeToken(signer tokenSigner, unsubscribe EmailUnsubscribe) (string, error) {
+	payload, err := json.Marshal(unsubscribe)
+	if err != nil {
+		return "", err
+	}
+
+	return signer.Sign(payload), nil
+}
+
+func ParseEmailUnsubscribeToken(verifier tokenVerifier, token string) (EmailUnsubscribe, error) {
+	payload, err := verifier.Verify(token)
+	if err != nil {
+		return EmailUnsubscribe{}, err
+	}
+
+	var unsubscribe EmailUnsubscribe
+	err = json.Unmarshal(payload, &unsubscribe)
+	if err != nil {
+		return EmailUnsubscribe{}, err
+	}
+
+	if unsubscribe.Email == "" {
+		return EmailUnsubscribe{}, errors.New("token is missing an email")
+	}
+
+	return unsubscribe, nil
+}
+
+func EmailUnsubscribeURL(domain, token string) string {
+	return serviceURL(domain, "/unsubscribe", token)
+}
+
+func serviceURL(domain, path, token string) string {
+	base := strings.TrimSuffix(domain, "/")
+	if !strings.Contains(base, "://") {
+		base = "https://" + base
+	}
+
+	return base + path + "?token=" + url.QueryEscape(token)
+}
@@ -0,0 +1,42 @@
+package common
+
+import (
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+)
+
+func NewFuncMap(context MessageContext) template.FuncMap {
+	location := context.TimeZone
+	if location == nil {
+		location = time.UTC
+	}
+
+	return template.FuncMap{
+		"upper": strings.ToUpper,
+		"lower": strings.ToLower,
+		"default": func(fallback, value string) string {
+			if value == "" {
+				return fallback
+			}
+
+			return value
+		},
+		"date": func(layout string, moment time.Time) string {
+			return moment.In(location).Format(layout)
+		},
+		"url": func(segments ...string) string {
+			base := strings.TrimSuffix(context.Domain, "/")
+			if !strings.Contains(base, "://") {
+				base = "https://" + base
+			}
+
+			for _, segment := range segments {
+				base += "/" + url.PathEscape(strings.Trim(segment, "/"))
+			}
+
+			return base
+		},
+	}
+}
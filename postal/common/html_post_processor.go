@@ -0,0 +1,434 @@
+package common
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/andybalholm/cascadia"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	cssCommentFormat    = regexp.MustCompile(`(?s)/\*.*?\*/`)
+	idSelectorFormat    = regexp.MustCompile(`#[\w-]+`)
+	classSelectorFormat = regexp.MustCompile(`\.[\w-]+|\[[^\]]*\]|:[\w-]+(\([^)]*\))?`)
+	typeSelectorFormat  = regexp.MustCompile(`(^|[\s>+~])[a-zA-Z][\w-]*`)
+)
+
+var blockElements = map[string]int{
+	"address":    1,
+	"article":    2,
+	"blockquote": 2,
+	"dd":         1,
+	"div":        1,
+	"dl":         2,
+	"dt":         1,
+	"footer":     1,
+	"form":       1,
+	"h1":         2,
+	"h2":         2,
+	"h3":         2,
+	"h4":         2,
+	"h5":         2,
+	"h6":         2,
+	"header":     1,
+	"ol":         2,
+	"p":          2,
+	"section":    2,
+	"table":      2,
+	"tr":         1,
+	"ul":         2,
+}
+
+type HTMLPostProcessor struct{}
+
+func (HTMLPostProcessor) PlainText(body string) (string, error) {
+	nodes, err := html.ParseFragment(strings.NewReader(body), &html.Node{
+		Type:     html.ElementNode,
+		Data:     "body",
+		DataAtom: atom.Body,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	writer := &plainTextWriter{}
+	for _, node := range nodes {
+		writer.walk(node)
+	}
+
+	if len(writer.links) > 0 {
+		writer.lineBreak(2)
+		for index, link := range writer.links {
+			writer.lineBreak(1)
+			writer.word(fmt.Sprintf("[%d] %s", index+1, link))
+		}
+	}
+
+	return writer.buffer.String(), nil
+}
+
+func (HTMLPostProcessor) InlineCSS(head, body string) (string, error) {
+	document, err := html.Parse(strings.NewReader("<html><head>" + head + "</head><body>" + body + "</body></html>"))
+	if err != nil {
+		return "", err
+	}
+
+	var stylesheet []string
+	for _, style := range cascadia.MustCompile("style").MatchAll(document) {
+		if style.FirstChild != nil {
+			stylesheet = append(stylesheet, style.FirstChild.Data)
+		}
+	}
+
+	rules := parseCSSRules(strings.Join(stylesheet, "\n"))
+	if len(rules) == 0 {
+		return body, nil
+	}
+
+	styles := map[*html.Node][]cssDeclaration{}
+	var matched []*html.Node
+	for _, rule := range rules {
+		for _, node := range rule.selector.MatchAll(document) {
+			if _, ok := styles[node]; !ok {
+				matched = append(matched, node)
+			}
+			styles[node] = append(styles[node], rule.declarations...)
+		}
+	}
+
+	for _, node := range matched {
+		declarations := styles[node]
+		for index, attribute := range node.Attr {
+			if attribute.Key == "style" {
+				declarations = append(declarations, parseCSSDeclarations(attribute.Val)...)
+				node.Attr = append(node.Attr[:index], node.Attr[index+1:]...)
+				break
+			}
+		}
+
+		node.Attr = append(node.Attr, html.Attribute{
+			Key: "style",
+			Val: renderCSSDeclarations(declarations),
+		})
+	}
+
+	buffer := bytes.NewBuffer([]byte{})
+	bodyNode := cascadia.MustCompile("body").MatchFirst(document)
+	for child := bodyNode.FirstChild; child != nil; child = child.NextSibling {
+		err = html.Render(buffer, child)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return buffer.String(), nil
+}
+
+type plainTextWriter struct {
+	buffer  bytes.Buffer
+	links   []string
+	breaks  int
+	space   bool
+	started bool
+}
+
+func (w *plainTextWriter) walk(node *html.Node) {
+	switch node.Type {
+	case html.TextNode:
+		w.text(node.Data)
+		return
+	case html.ElementNode:
+	default:
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			w.walk(child)
+		}
+		return
+	}
+
+	switch node.Data {
+	case "head", "script", "style", "title":
+		return
+	case "br":
+		if w.started {
+			w.breaks++
+		}
+		w.space = false
+		return
+	case "hr":
+		w.lineBreak(2)
+		w.word(strings.Repeat("-", 10))
+		w.lineBreak(2)
+		return
+	case "img":
+		if alt := attributeValue(node, "alt"); alt != "" {
+			w.text(alt)
+		}
+		return
+	case "pre":
+		w.lineBreak(2)
+		w.word(strings.Trim(textContent(node), "\n"))
+		w.lineBreak(2)
+		return
+	case "td", "th":
+		w.space = true
+	case "li":
+		w.lineBreak(1)
+		marker := "-"
+		if node.Parent != nil && node.Parent.Data == "ol" {
+			position := 1
+			for sibling := node.PrevSibling; sibling != nil; sibling = sibling.PrevSibling {
+				if sibling.Type == html.ElementNode && sibling.Data == "li" {
+					position++
+				}
+			}
+			marker = fmt.Sprintf("%d.", position)
+		}
+		w.word(marker)
+		w.space = true
+	}
+
+	breaks, isBlock := blockElements[node.Data]
+	if isBlock {
+		w.lineBreak(breaks)
+	}
+
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		w.walk(child)
+	}
+
+	if node.Data == "a" {
+		w.footnote(node)
+	}
+
+	if isBlock {
+		w.lineBreak(breaks)
+	}
+}
+
+func (w *plainTextWriter) footnote(node *html.Node) {
+	href := strings.TrimSpace(attributeValue(node, "href"))
+	if href == "" || strings.HasPrefix(href, "#") || href == strings.TrimSpace(textContent(node)) {
+		return
+	}
+
+	number := 0
+	for index, link := range w.links {
+		if link == href {
+			number = index + 1
+		}
+	}
+
+	if number == 0 {
+		w.links = append(w.links, href)
+		number = len(w.links)
+	}
+
+	w.space = true
+	w.word(fmt.Sprintf("[%d]", number))
+}
+
+func (w *plainTextWriter) text(content string) {
+	if content == "" {
+		return
+	}
+
+	if strings.TrimLeft(content, " \t\r\n") != content {
+		w.space = true
+	}
+
+	for _, field := range strings.Fields(content) {
+		w.word(field)
+		w.space = true
+	}
+
+	if strings.TrimRight(content, " \t\r\n") == content {
+		w.space = false
+	}
+}
+
+func (w *plainTextWriter) word(content string) {
+	if w.started {
+		if w.breaks > 0 {
+			w.buffer.WriteString(strings.Repeat("\n", w.breaks))
+		} else if w.space {
+			w.buffer.WriteString(" ")
+		}
+	}
+
+	w.buffer.WriteString(content)
+	w.started = true
+	w.breaks = 0
+	w.space = false
+}
+
+func (w *plainTextWriter) lineBreak(count int) {
+	if w.started && count > w.breaks {
+		w.breaks = count
+	}
+	w.space = false
+}
+
+func attributeValue(node *html.Node, key string) string {
+	for _, attribute := range node.Attr {
+		if attribute.Key == key {
+			return attribute.Val
+		}
+	}
+
+	return ""
+}
+
+func textContent(node *html.Node) string {
+	if node.Type == html.TextNode {
+		return node.Data
+	}
+
+	var content []string
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		content = append(content, textContent(child))
+	}
+
+	return strings.Join(content, "")
+}
+
+type cssDeclaration struct {
+	property string
+	value    string
+}
+
+type cssRule struct {
+	selector     cascadia.Selector
+	specificity  int
+	declarations []cssDeclaration
+}
+
+type cssRulesBySpecificity []cssRule
+
+func (r cssRulesBySpecificity) Len() int           { return len(r) }
+func (r cssRulesBySpecificity) Swap(i, j int)      { r[i], r[j] = r[j], r[i] }
+func (r cssRulesBySpecificity) Less(i, j int) bool { return r[i].specificity < r[j].specificity }
+
+func parseCSSRules(stylesheet string) []cssRule {
+	var rules []cssRule
+
+	stylesheet = cssCommentFormat.ReplaceAllString(stylesheet, "")
+	for {
+		opening := strings.Index(stylesheet, "{")
+		if opening < 0 {
+			break
+		}
+
+		selectors := strings.TrimSpace(stylesheet[:opening])
+		if strings.HasPrefix(selectors, "@") {
+			stylesheet = stylesheet[opening+closingBrace(stylesheet[opening:])+1:]
+			continue
+		}
+
+		closing := strings.Index(stylesheet[opening:], "}")
+		if closing < 0 {
+			break
+		}
+
+		declarations := parseCSSDeclarations(stylesheet[opening+1 : opening+closing])
+		stylesheet = stylesheet[opening+closing+1:]
+
+		for _, selector := range strings.Split(selectors, ",") {
+			selector = strings.TrimSpace(selector)
+			if selector == "" || strings.Contains(selector, ":") {
+				continue
+			}
+
+			compiled, err := cascadia.Compile(selector)
+			if err != nil {
+				continue
+			}
+
+			rules = append(rules, cssRule{
+				selector:     compiled,
+				specificity:  selectorSpecificity(selector),
+				declarations: declarations,
+			})
+		}
+	}
+
+	sort.Stable(cssRulesBySpecificity(rules))
+
+	return rules
+}
+
+func parseCSSDeclarations(block string) []cssDeclaration {
+	var declarations []cssDeclaration
+
+	for _, declaration := range strings.Split(block, ";") {
+		parts := strings.SplitN(declaration, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		property := strings.ToLower(strings.TrimSpace(parts[0]))
+		value := strings.TrimSpace(parts[1])
+		if property == "" || value == "" {
+			continue
+		}
+
+		declarations = append(declarations, cssDeclaration{
+			property: property,
+			value:    value,
+		})
+	}
+
+	return declarations
+}
+
+func renderCSSDeclarations(declarations []cssDeclaration) string {
+	var properties []string
+	values := map[string]string{}
+
+	for _, declaration := range declarations {
+		current, ok := values[declaration.property]
+		if !ok {
+			properties = append(properties, declaration.property)
+		} else if strings.HasSuffix(current, "!important") && !strings.HasSuffix(declaration.value, "!important") {
+			continue
+		}
+
+		values[declaration.property] = declaration.value
+	}
+
+	var rendered []string
+	for _, property := range properties {
+		rendered = append(rendered, fmt.Sprintf("%s: %s", property, values[property]))
+	}
+
+	return strings.Join(rendered, "; ")
+}
+
+func closingBrace(source string) int {
+	depth := 0
+	for index, char := range source {
+		switch char {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return index
+			}
+		}
+	}
+
+	return len(source) - 1
+}
+
+func selectorSpecificity(selector string) int {
+	ids := len(idSelectorFormat.FindAllString(selector, -1))
+	classes := len(classSelectorFormat.FindAllString(selector, -1))
+	types := len(typeSelectorFormat.FindAllString(selector, -1))
+
+	return ids*10000 + classes*100 + types
+}
@@ -0,0 +1,73 @@
+package common_test
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HTMLPostProcessor", func() {
+	var processor common.HTMLPostProcessor
+
+	Describe("PlainText", func() {
+		It("converts block elements into paragraphs and lines", func() {
+			text, err := processor.PlainText(`<h1>Maintenance</h1>
+				<p>The   platform will be<br>unavailable.</p>
+				<ul><li>API</li><li>Router</li></ul>
+				<ol><li>first</li><li>second</li></ol>
+				<hr>
+				<div>Thanks, <img src="logo.png" alt="the team"></div>`)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(text).To(Equal("Maintenance\n\nThe platform will be\nunavailable.\n\n- API\n- Router\n\n1. first\n2. second\n\n----------\n\nThanks, the team"))
+		})
+
+		It("preserves links as footnotes", func() {
+			text, err := processor.PlainText(`<p>Read <a href="https://example.com/docs">the docs</a> or the <a href="https://example.com/faq">FAQ</a>.</p>
+				<p>Again, <a href="https://example.com/docs">the docs</a>, <a href="https://example.com">https://example.com</a> and <a href="#top">top</a>.</p>`)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(text).To(Equal("Read the docs [1] or the FAQ [2].\n\nAgain, the docs [1], https://example.com and top.\n\n[1] https://example.com/docs\n[2] https://example.com/faq"))
+		})
+
+		It("skips scripts and styles and keeps preformatted text", func() {
+			text, err := processor.PlainText("<style>p { color: red }</style><script>alert(1)</script><pre>cf push\n  my-app</pre>")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(text).To(Equal("cf push\n  my-app"))
+		})
+	})
+
+	Describe("InlineCSS", func() {
+		It("applies stylesheet rules in order of specificity", func() {
+			html, err := processor.InlineCSS(
+				`<style>
+					/* base styles */
+					#main p { color: green }
+					p, td { color: red; margin: 0 }
+					.note { color: blue }
+					@media (max-width: 600px) { p { color: black } }
+					a:hover { color: pink }
+				</style>`,
+				`<div id="main"><p class="note">one</p></div><p class="note" style="margin: 4px">two</p><a href="#">link</a>`)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(html).To(Equal(`<div id="main"><p class="note" style="color: green; margin: 0">one</p></div><p class="note" style="color: blue; margin: 4px">two</p><a href="#">link</a>`))
+		})
+
+		It("respects important declarations", func() {
+			html, err := processor.InlineCSS(`<style>p { color: red !important } .note { color: blue }</style>`, `<p class="note">text</p>`)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(html).To(Equal(`<p class="note" style="color: red !important">text</p>`))
+		})
+
+		It("returns the body untouched when there are no styles", func() {
+			html, err := processor.InlineCSS("<title>hi</title>", "<p>some <b>html</br></p>")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(html).To(Equal("<p>some <b>html</br></p>"))
+		})
+	})
+})
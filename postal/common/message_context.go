@@ -1,6 +1,7 @@
 package common
 
 import (
+	"encoding/json"
 	"html"
 	"time"
 
@@ -18,6 +19,7 @@ type Options struct {
 	KindID            string
 	To                string
 	Role              string
+	Group             string
 	Endorsement       string
 	TemplateID        string
 }
@@ -35,13 +37,31 @@ type Delivery struct {
 	VCAPRequestID   string
 	RequestReceived time.Time
 	CampaignID      string
+	CampaignTypeID  string
+	TimeZone        string
+	ResolvedEmail   string
+	EmailResolvedAt time.Time
+	QueueWait       time.Duration `json:"-"`
 }
 
 type Templates struct {
-	Name    string
-	Subject string
-	Text    string
-	HTML    string
+	Name     string
+	Subject  string
+	Text     string
+	HTML     string
+	Metadata string
+}
+
+type Partial struct {
+	Name string
+	Text string
+	HTML string
+}
+
+type templateMetadata struct {
+	DerivePlainText bool   `json:"derive_plain_text"`
+	InlineCSS       bool   `json:"inline_css"`
+	Layout          string `json:"layout"`
 }
 
 type HTML struct {
@@ -72,11 +92,22 @@ type MessageContext struct {
 	Organization      string
 	OrganizationGUID  string
 	UnsubscribeID     string
+	UnsubscribeToken  string
+	UnsubscribeURL    string
+	PreferencesToken  string
+	PreferencesURL    string
 	Scope             string
 	Endorsement       string
 	OrganizationRole  string
+	SpaceRole         string
+	Group             string
 	RequestReceived   time.Time
 	Domain            string
+	DerivePlainText   bool
+	InlineCSS         bool
+	TimeZone          *time.Location
+	Partials          []Partial
+	Layout            string
 }
 
 func NewMessageContext(delivery Delivery, sender, domain string, cloak conceal.CloakInterface, templates Templates) MessageContext {
@@ -119,8 +150,28 @@ func NewMessageContext(delivery Delivery, sender, domain string, cloak conceal.C
 		Scope:             delivery.Scope,
 		Endorsement:       options.Endorsement,
 		OrganizationRole:  options.Role,
+		SpaceRole:         options.Role,
+		Group:             options.Group,
 		RequestReceived:   delivery.RequestReceived,
 		Domain:            domain,
+		TimeZone:          time.UTC,
+	}
+
+	if delivery.TimeZone != "" {
+		location, err := time.LoadLocation(delivery.TimeZone)
+		if err == nil {
+			messageContext.TimeZone = location
+		}
+	}
+
+	var metadata templateMetadata
+	if templates.Metadata != "" {
+		err := json.Unmarshal([]byte(templates.Metadata), &metadata)
+		if err == nil {
+			messageContext.DerivePlainText = metadata.DerivePlainText
+			messageContext.InlineCSS = metadata.InlineCSS
+			messageContext.Layout = metadata.Layout
+		}
 	}
 
 	if messageContext.Subject == "" {
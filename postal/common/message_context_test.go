@@ -45,6 +45,7 @@ var _ = Describe("MessageContext", func() {
 			KindID:            "the-kind-id",
 			Endorsement:       "this is the endorsement",
 			Role:              "OrgRole",
+			Group:             "some-group",
 		}
 
 		reqReceived, _ = time.Parse(time.RFC3339Nano, "2015-06-08T14:40:12.207187819-07:00")
@@ -100,6 +101,8 @@ var _ = Describe("MessageContext", func() {
 			Expect(context.Scope).To(Equal("this.scope"))
 			Expect(context.Endorsement).To(Equal("this is the endorsement"))
 			Expect(context.OrganizationRole).To(Equal("OrgRole"))
+			Expect(context.SpaceRole).To(Equal("OrgRole"))
+			Expect(context.Group).To(Equal("some-group"))
 			Expect(context.RequestReceived).To(Equal(reqReceived))
 			Expect(context.Domain).To(Equal(domain))
 		})
@@ -123,6 +126,31 @@ var _ = Describe("MessageContext", func() {
 			context := common.NewMessageContext(delivery, sender, domain, cloak, templates)
 			Expect(context.Subject).To(Equal("[no subject]"))
 		})
+
+		It("reads the post-processing options from the template metadata", func() {
+			templates.Metadata = `{"derive_plain_text": true, "inline_css": true}`
+			context := common.NewMessageContext(delivery, sender, domain, cloak, templates)
+			Expect(context.DerivePlainText).To(BeTrue())
+			Expect(context.InlineCSS).To(BeTrue())
+
+			templates.Metadata = `{}`
+			context = common.NewMessageContext(delivery, sender, domain, cloak, templates)
+			Expect(context.DerivePlainText).To(BeFalse())
+			Expect(context.InlineCSS).To(BeFalse())
+		})
+
+		It("uses the recipient's time zone when it is known", func() {
+			context := common.NewMessageContext(delivery, sender, domain, cloak, templates)
+			Expect(context.TimeZone).To(Equal(time.UTC))
+
+			delivery.TimeZone = "America/New_York"
+			context = common.NewMessageContext(delivery, sender, domain, cloak, templates)
+			Expect(context.TimeZone.String()).To(Equal("America/New_York"))
+
+			delivery.TimeZone = "Mars/Olympus_Mons"
+			context = common.NewMessageContext(delivery, sender, domain, cloak, templates)
+			Expect(context.TimeZone).To(Equal(time.UTC))
+		})
 	})
 
 	Describe("Escape", func() {
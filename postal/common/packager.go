@@ -23,15 +23,23 @@ type templatesLoader interface {
 	LoadTemplates(clientID, kindID, templateID string) (Templates, error)
 }
 
+type partialsLoader interface {
+	LoadPartials(clientID string) ([]Partial, error)
+}
+
 type Packager struct {
 	templates templatesLoader
+	partials  partialsLoader
 	cloak     conceal.CloakInterface
+	signer    tokenSigner
 }
 
-func NewPackager(templates templatesLoader, cloak conceal.CloakInterface) Packager {
+func NewPackager(templates templatesLoader, partials partialsLoader, cloak conceal.CloakInterface, signer tokenSigner) Packager {
 	return Packager{
 		templates: templates,
+		partials:  partials,
 		cloak:     cloak,
+		signer:    signer,
 	}
 }
 
@@ -41,7 +49,44 @@ func (packager Packager) PrepareContext(delivery Delivery, sender, domain string
 		return MessageContext{}, err
 	}
 
-	return NewMessageContext(delivery, sender, domain, packager.cloak, templates), nil
+	partials, err := packager.partials.LoadPartials(delivery.ClientID)
+	if err != nil {
+		return MessageContext{}, err
+	}
+
+	context := NewMessageContext(delivery, sender, domain, packager.cloak, templates)
+	context.Partials = partials
+
+	if delivery.UserGUID != "" {
+		token, err := NewPreferencesToken(packager.signer, delivery.UserGUID, time.Now().Add(PreferencesTokenLifetime))
+		if err != nil {
+			return MessageContext{}, err
+		}
+
+		context.PreferencesToken = token
+		context.PreferencesURL = PreferencesURL(domain, token)
+	}
+
+	if delivery.UserGUID == "" && delivery.Email != "" {
+		campaignTypeID := delivery.CampaignTypeID
+		if campaignTypeID == "" {
+			campaignTypeID = delivery.Options.KindID
+		}
+
+		token, err := NewEmailUnsubscribeToken(packager.signer, EmailUnsubscribe{
+			Email:          delivery.Email,
+			ClientID:       delivery.ClientID,
+			CampaignTypeID: campaignTypeID,
+		})
+		if err != nil {
+			return MessageContext{}, err
+		}
+
+		context.UnsubscribeToken = token
+		context.UnsubscribeURL = EmailUnsubscribeURL(domain, token)
+	}
+
+	return context, nil
 }
 
 func (packager Packager) Pack(context MessageContext) (mail.Message, error) {
@@ -55,18 +100,28 @@ func (packager Packager) Pack(context MessageContext) (mail.Message, error) {
 		return mail.Message{}, err
 	}
 
+	headers := []string{
+		fmt.Sprintf("X-CF-Client-ID: %s", context.ClientID),
+		fmt.Sprintf("X-CF-Notification-ID: %s", context.MessageID),
+		fmt.Sprintf("X-CF-Notification-Timestamp: %s", time.Now().Format(time.RFC3339Nano)),
+		fmt.Sprintf("X-CF-Notification-Request-Received: %s", context.RequestReceived.Format(time.RFC3339Nano)),
+	}
+
+	if context.UnsubscribeURL != "" {
+		headers = append(headers,
+			fmt.Sprintf("List-Unsubscribe: <%s>", context.UnsubscribeURL),
+			"List-Unsubscribe-Post: List-Unsubscribe=One-Click")
+	} else if context.PreferencesURL != "" {
+		headers = append(headers, fmt.Sprintf("List-Unsubscribe: <%s>", context.PreferencesURL))
+	}
+
 	return mail.Message{
 		From:    context.From,
 		ReplyTo: context.ReplyTo,
 		To:      context.To,
 		Subject: compiledSubject,
 		Body:    parts,
-		Headers: []string{
-			fmt.Sprintf("X-CF-Client-ID: %s", context.ClientID),
-			fmt.Sprintf("X-CF-Notification-ID: %s", context.MessageID),
-			fmt.Sprintf("X-CF-Notification-Timestamp: %s", time.Now().Format(time.RFC3339Nano)),
-			fmt.Sprintf("X-CF-Notification-Request-Received: %s", context.RequestReceived.Format(time.RFC3339Nano)),
-		},
+		Headers: headers,
 	}, nil
 }
 
@@ -80,7 +135,7 @@ func (packager Packager) CompileParts(context MessageContext) ([]mail.Part, erro
 	}
 
 	if context.Text != "" {
-		plainText, err := packager.compileTemplate(context, context.TextTemplate, false)
+		plainText, err := packager.compileBody(context, context.TextTemplate, false)
 		if err != nil {
 			return parts, err
 		}
@@ -95,11 +150,30 @@ func (packager Packager) CompileParts(context MessageContext) ([]mail.Part, erro
 	if context.HTML != "" {
 		var err error
 
-		context.HTMLComponents.BodyContent, err = packager.compileTemplate(context, context.HTMLTemplate, true)
+		context.HTMLComponents.BodyContent, err = packager.compileBody(context, context.HTMLTemplate, true)
 		if err != nil {
 			return parts, err
 		}
 
+		if context.InlineCSS {
+			context.HTMLComponents.BodyContent, err = HTMLPostProcessor{}.InlineCSS(context.HTMLComponents.Head, context.HTMLComponents.BodyContent)
+			if err != nil {
+				return parts, err
+			}
+		}
+
+		if context.Text == "" && context.DerivePlainText {
+			plainText, err := HTMLPostProcessor{}.PlainText(context.HTMLComponents.BodyContent)
+			if err != nil {
+				return parts, err
+			}
+
+			parts = append(parts, mail.Part{
+				ContentType: "text/plain",
+				Content:     plainText,
+			})
+		}
+
 		htmlPart, err := packager.compileTemplate(context, HTMLWrapperTemplate, true)
 		if err != nil {
 			return parts, err
@@ -114,20 +188,81 @@ func (packager Packager) CompileParts(context MessageContext) ([]mail.Part, erro
 	return parts, nil
 }
 
+// ValidateTemplate reports whether the body of a template or partial can be
+// parsed with the helper functions that are available when it is rendered.
+func ValidateTemplate(body string) error {
+	_, err := template.New("validate").Funcs(NewFuncMap(MessageContext{})).Parse(body)
+	return err
+}
+
 func (packager Packager) compileTemplate(context MessageContext, theTemplate string, escapeContext bool) (string, error) {
-	buffer := bytes.NewBuffer([]byte{})
+	source, err := packager.parse(context, theTemplate, escapeContext)
+	if err != nil {
+		return "", err
+	}
+
+	return packager.execute(source, source.Name(), context, escapeContext)
+}
+
+// compileBody renders a text or HTML body inside the layout named by the
+// template metadata, which includes the body with {{template "content" .}}.
+func (packager Packager) compileBody(context MessageContext, theTemplate string, escapeContext bool) (string, error) {
+	if context.Layout == "" {
+		return packager.compileTemplate(context, theTemplate, escapeContext)
+	}
+
+	source, err := packager.parse(context, "", escapeContext)
+	if err != nil {
+		return "", err
+	}
 
-	source, err := template.New("compileTemplate").Parse(theTemplate)
+	_, err = source.New("content").Parse(theTemplate)
 	if err != nil {
 		return "", err
 	}
 
+	return packager.execute(source, context.Layout, context, escapeContext)
+}
+
+// parse defines the client's partials alongside the template. A partial that
+// no longer parses is left out, so that only the templates that use it fail.
+func (packager Packager) parse(context MessageContext, theTemplate string, escapeContext bool) (*template.Template, error) {
+	source := template.New("compileTemplate").Funcs(NewFuncMap(context))
+	for _, partial := range context.Partials {
+		body, fallback := partial.Text, partial.HTML
+		if escapeContext {
+			body, fallback = partial.HTML, partial.Text
+		}
+
+		if body == "" {
+			body = fallback
+		}
+
+		_, err := source.New(partial.Name).Parse(body)
+		if err != nil {
+			continue
+		}
+	}
+
+	_, err := source.Parse(theTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	return source, nil
+}
+
+func (packager Packager) execute(source *template.Template, name string, context MessageContext, escapeContext bool) (string, error) {
+	buffer := bytes.NewBuffer([]byte{})
+
 	if escapeContext {
 		context.Escape()
 	}
 
-	source.Execute(buffer, context)
-	compiledTemplate := strings.TrimSuffix(buffer.String(), "\n")
+	err := source.ExecuteTemplate(buffer, name, context)
+	if err != nil {
+		return "", err
+	}
 
-	return compiledTemplate, nil
+	return strings.TrimSuffix(buffer.String(), "\n"), nil
 }
@@ -1,6 +1,7 @@
 package common_test
 
 import (
+	"encoding/json"
 	"errors"
 	"strings"
 	"time"
@@ -19,14 +20,18 @@ var _ = Describe("Packager", func() {
 		context         common.MessageContext
 		client          mail.Client
 		templatesLoader *mocks.TemplatesLoader
+		partialsLoader  *mocks.PartialsLoader
 		delivery        common.Delivery
 		cloak           *mocks.Cloak
+		signer          *mocks.Signer
 	)
 
 	BeforeEach(func() {
 		client = mail.Client{}
 		templatesLoader = mocks.NewTemplatesLoader()
+		partialsLoader = mocks.NewPartialsLoader()
 		cloak = mocks.NewCloak()
+		signer = mocks.NewSigner()
 
 		delivery = common.Delivery{
 			UserGUID: "some-user-guid",
@@ -45,7 +50,7 @@ var _ = Describe("Packager", func() {
 			},
 		}
 
-		packager = common.NewPackager(templatesLoader, cloak)
+		packager = common.NewPackager(templatesLoader, partialsLoader, cloak, signer)
 
 		requestReceivedTime, _ := time.Parse(time.RFC3339Nano, "2015-06-08T14:38:03.180764129-07:00")
 
@@ -83,6 +88,13 @@ var _ = Describe("Packager", func() {
 				Text:    "Some {{.Text}} text",
 				HTML:    "<h1>{{.HTML}}</h1>",
 			}
+			partialsLoader.LoadPartialsCall.Returns.Partials = []common.Partial{
+				{
+					Name: "footer",
+					Text: "-- the team",
+					HTML: "<footer>the team</footer>",
+				},
+			}
 		})
 
 		It("sets the context on the Packager", func() {
@@ -96,6 +108,8 @@ var _ = Describe("Packager", func() {
 			Expect(templatesLoader.LoadTemplatesCall.Receives.KindID).To(Equal("some-kind-id"))
 			Expect(templatesLoader.LoadTemplatesCall.Receives.TemplateID).To(Equal("some-template-id"))
 
+			Expect(partialsLoader.LoadPartialsCall.Receives.ClientID).To(Equal("some-client-id"))
+
 			Expect(cloak.VeilCall.Receives.PlainText).To(Equal([]byte("some-user-guid|some-client-id|some-kind-id")))
 
 			Expect(context).To(Equal(common.MessageContext{
@@ -118,9 +132,75 @@ var _ = Describe("Packager", func() {
 				SubjectTemplate:   "subject template: {{.Subject}}",
 				KindDescription:   "some-kind-id",
 				SourceDescription: "some-client-id",
+				PreferencesURL:    "https://example.com/preferences?token=",
+				TimeZone:          time.UTC,
+				Partials: []common.Partial{
+					{
+						Name: "footer",
+						Text: "-- the team",
+						HTML: "<footer>the team</footer>",
+					},
+				},
 			}))
 		})
 
+		It("does not include an email unsubscribe link for users", func() {
+			context, err := packager.PrepareContext(delivery, "some-sender@example.com", "example.com")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(string(signer.SignCall.Receives.Payload)).NotTo(ContainSubstring("email"))
+			Expect(context.UnsubscribeToken).To(BeEmpty())
+			Expect(context.UnsubscribeURL).To(BeEmpty())
+		})
+
+		It("includes a signed preference center link for users", func() {
+			signer.SignCall.Returns.Token = "some-token"
+
+			context, err := packager.PrepareContext(delivery, "some-sender@example.com", "example.com")
+			Expect(err).NotTo(HaveOccurred())
+
+			var token common.PreferencesToken
+			Expect(json.Unmarshal(signer.SignCall.Receives.Payload, &token)).To(Succeed())
+			Expect(token.UserGUID).To(Equal("some-user-guid"))
+			Expect(time.Unix(token.ExpiresAt, 0)).To(BeTemporally("~", time.Now().Add(common.PreferencesTokenLifetime), 2*time.Second))
+			Expect(context.PreferencesToken).To(Equal("some-token"))
+			Expect(context.PreferencesURL).To(Equal("https://example.com/preferences?token=some-token"))
+		})
+
+		Context("when the recipient is an email address", func() {
+			BeforeEach(func() {
+				delivery.UserGUID = ""
+				delivery.Email = "someone@example.com"
+				signer.SignCall.Returns.Token = "some-token"
+			})
+
+			It("includes a signed email unsubscribe link", func() {
+				context, err := packager.PrepareContext(delivery, "some-sender@example.com", "example.com")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(signer.SignCall.Receives.Payload).To(MatchJSON(`{
+					"email": "someone@example.com",
+					"client_id": "some-client-id",
+					"campaign_type_id": "some-kind-id"
+				}`))
+				Expect(context.UnsubscribeToken).To(Equal("some-token"))
+				Expect(context.UnsubscribeURL).To(Equal("https://example.com/unsubscribe?token=some-token"))
+			})
+
+			It("prefers the campaign type id when there is one", func() {
+				delivery.CampaignTypeID = "some-campaign-type-id"
+
+				_, err := packager.PrepareContext(delivery, "some-sender@example.com", "example.com")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(signer.SignCall.Receives.Payload).To(MatchJSON(`{
+					"email": "someone@example.com",
+					"client_id": "some-client-id",
+					"campaign_type_id": "some-campaign-type-id"
+				}`))
+			})
+		})
+
 		Context("when the template cannot be loaded", func() {
 			It("returns an error", func() {
 				templatesLoader.LoadTemplatesCall.Returns.Error = errors.New("some error")
@@ -129,6 +209,15 @@ var _ = Describe("Packager", func() {
 				Expect(err).To(MatchError(errors.New("some error")))
 			})
 		})
+
+		Context("when the partials cannot be loaded", func() {
+			It("returns an error", func() {
+				partialsLoader.LoadPartialsCall.Returns.Error = errors.New("some partials error")
+
+				_, err := packager.PrepareContext(delivery, "some-sender", "some-domain")
+				Expect(err).To(MatchError(errors.New("some partials error")))
+			})
+		})
 	})
 
 	Describe("Pack", func() {
@@ -166,6 +255,30 @@ var _ = Describe("Packager", func() {
 			timestamp, err := time.Parse(time.RFC3339Nano, formattedTimestamp)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(timestamp).To(BeTemporally("~", time.Now(), 2*time.Second))
+
+			Expect(msg.Headers).NotTo(ContainElement(HavePrefix("List-Unsubscribe")))
+		})
+
+		Context("when the context has an unsubscribe url", func() {
+			It("adds one-click unsubscribe headers", func() {
+				context.UnsubscribeURL = "https://example.com/unsubscribe?token=some-token"
+
+				msg, err := packager.Pack(context)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(msg.Headers).To(ContainElement("List-Unsubscribe: <https://example.com/unsubscribe?token=some-token>"))
+				Expect(msg.Headers).To(ContainElement("List-Unsubscribe-Post: List-Unsubscribe=One-Click"))
+			})
+		})
+
+		Context("when the context only has a preferences url", func() {
+			It("points the unsubscribe header at the preference center", func() {
+				context.PreferencesURL = "https://example.com/preferences?token=some-token"
+
+				msg, err := packager.Pack(context)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(msg.Headers).To(ContainElement("List-Unsubscribe: <https://example.com/preferences?token=some-token>"))
+				Expect(msg.Headers).NotTo(ContainElement(HavePrefix("List-Unsubscribe-Post")))
+			})
 		})
 	})
 
@@ -241,6 +354,141 @@ Banana preamble <p>user supplied banana html</p>  3&amp;3 4&#39;4 user-123
 					},
 				}))
 			})
+
+			Context("when the template opts into deriving plain text", func() {
+				It("derives the plaintext portion from the compiled html", func() {
+					context.Text = ""
+					context.DerivePlainText = true
+					context.HTMLTemplate = `<h1>Hello</h1><p>{{.HTML}} Visit <a href="https://example.com/docs">the docs</a>.</p>`
+
+					parts, err := packager.CompileParts(context)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(parts).To(HaveLen(2))
+					Expect(parts[0]).To(Equal(mail.Part{
+						ContentType: "text/plain",
+						Content:     "Hello\n\nuser supplied banana html\n\nVisit the docs [1].\n\n[1] https://example.com/docs",
+					}))
+					Expect(parts[1].ContentType).To(Equal("text/html"))
+				})
+			})
+		})
+
+		Context("when the template opts into inlining css", func() {
+			It("applies the styles from the head to the html body", func() {
+				context.InlineCSS = true
+				context.HTMLComponents.Head = "<style>p { color: red; } .banana { font-weight: bold }</style>"
+				context.HTMLTemplate = `<div class="banana" style="color: blue">{{.HTML}}</div>`
+
+				parts, err := packager.CompileParts(context)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(parts).To(ContainElement(mail.Part{
+					ContentType: "text/html",
+					Content: `<!DOCTYPE html>
+<head><style>p { color: red; } .banana { font-weight: bold }</style></head>
+<html>
+	<body class="bananaBody">
+		<div class="banana" style="font-weight: bold; color: blue"><p style="color: red">user supplied banana html</p></div>
+	</body>
+</html>`,
+				}))
+			})
+		})
+
+		Context("when the client has partials", func() {
+			BeforeEach(func() {
+				context.Partials = []common.Partial{
+					{
+						Name: "footer",
+						Text: "-- {{.Organization}} team",
+						HTML: "<footer>{{.Organization}} & team</footer>",
+					},
+					{
+						Name: "signature",
+						Text: "the signature",
+					},
+				}
+			})
+
+			It("renders the partials referenced by the templates", func() {
+				context.TextTemplate = `{{.Text}}
+{{template "footer" .}}`
+				context.HTMLTemplate = `{{.HTML}}{{template "footer" .}}{{template "signature" .}}`
+
+				parts, err := packager.CompileParts(context)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(parts[0]).To(Equal(mail.Part{
+					ContentType: "text/plain",
+					Content:     "User <supplied> \"banana\" text\n-- banana team",
+				}))
+				Expect(parts[1].Content).To(ContainSubstring("<p>user supplied banana html</p><footer>banana & team</footer>the signature"))
+			})
+
+			It("allows a partial to be used as a layout", func() {
+				context.Partials = append(context.Partials, common.Partial{
+					Name: "layout",
+					Text: `== {{block "content" .}}nothing{{end}} ==`,
+				})
+				context.TextTemplate = `{{define "content"}}{{.Space}}{{end}}{{template "layout" .}}`
+
+				parts, err := packager.CompileParts(context)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(parts[0].Content).To(Equal("== development =="))
+			})
+
+			It("wraps the templates in the layout named by the metadata", func() {
+				context.Partials = append(context.Partials, common.Partial{
+					Name: "frame",
+					Text: `== {{template "content" .}} ==`,
+					HTML: `<main>{{template "content" .}}</main>`,
+				})
+				context.Layout = "frame"
+				context.TextTemplate = "{{.Space}}"
+				context.HTMLTemplate = "{{.HTML}}"
+
+				parts, err := packager.CompileParts(context)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(parts[0].Content).To(Equal("== development =="))
+				Expect(parts[1].Content).To(ContainSubstring("<main><p>user supplied banana html</p></main>"))
+			})
+
+			It("ignores a partial that cannot be parsed when it is not referenced", func() {
+				context.Partials[1].Text = "{{.Broken"
+				context.TextTemplate = `{{template "footer" .}}`
+
+				parts, err := packager.CompileParts(context)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(parts[0].Content).To(Equal("-- banana team"))
+			})
+
+			It("returns an error when a referenced partial cannot be parsed", func() {
+				context.Partials[0].Text = "{{.Broken"
+				context.TextTemplate = `{{template "footer" .}}`
+
+				_, err := packager.CompileParts(context)
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		Context("when the templates use helper functions", func() {
+			It("renders them", func() {
+				pacific, err := time.LoadLocation("America/Los_Angeles")
+				Expect(err).NotTo(HaveOccurred())
+
+				context.TimeZone = pacific
+				context.Domain = "example.com"
+				context.TextTemplate = `{{upper .Space}} {{lower .Organization | upper}} {{.KindDescription | default "none"}} {{date "2006-01-02 15:04 MST" .RequestReceived}} {{url "orgs" .Organization "spaces"}}`
+
+				parts, err := packager.CompileParts(context)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(parts[0].Content).To(Equal("DEVELOPMENT BANANA none 2015-06-08 14:38 PDT https://example.com/orgs/banana/spaces"))
+			})
 		})
 	})
 })
@@ -0,0 +1,60 @@
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// PreferencesTokenLifetime is how long a preference center link in an email
+// stays valid.
+const PreferencesTokenLifetime = 90 * 24 * time.Hour
+
+// PreferencesToken identifies the UAA user whose preference center a link
+// opens. Unlike the UnsubscribeID it is signed rather than veiled, so it
+// cannot be altered to point at another user.
+type PreferencesToken struct {
+	UserGUID  string `json:"user_guid"`
+	ExpiresAt int64  `json:"expires_at"`
+}
+
+func NewPreferencesToken(signer tokenSigner, userGUID string, expiresAt time.Time) (string, error) {
+	payload, err := json.Marshal(PreferencesToken{
+		UserGUID:  userGUID,
+		ExpiresAt: expiresAt.Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return signer.Sign(payload), nil
+}
+
+// ParsePreferencesToken rejects tokens that have expired by now, including
+// those issued without an expiry.
+func ParsePreferencesToken(verifier tokenVerifier, token string, now time.Time) (PreferencesToken, error) {
+	payload, err := verifier.Verify(token)
+	if err != nil {
+		return PreferencesToken{}, err
+	}
+
+	var preferences PreferencesToken
+	err = json.Unmarshal(payload, &preferences)
+	if err != nil {
+		return PreferencesToken{}, err
+	}
+
+	if preferences.UserGUID == "" {
+		return PreferencesToken{}, errors.New("token is missing a user guid")
+	}
+
+	if !now.Before(time.Unix(preferences.ExpiresAt, 0)) {
+		return PreferencesToken{}, errors.New("token has expired")
+	}
+
+	return preferences, nil
+}
+
+func PreferencesURL(domain, token string) string {
+	return serviceURL(domain, "/preferences", token)
+}
@@ -0,0 +1,21 @@
+package common
+
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/metrics"
+)
+
+// RecordTiming emits a histogram of the duration, in seconds, tagged so that
+// deliveries can be compared by API version, client and campaign type.
+func RecordTiming(name, version, clientID, campaignTypeID string, duration time.Duration) {
+	metrics.NewMetric("histogram", map[string]interface{}{
+		"name":  name,
+		"value": duration.Seconds(),
+		"tags": map[string]string{
+			"version":       version,
+			"client":        clientID,
+			"campaign_type": campaignTypeID,
+		},
+	}).Log()
+}
@@ -0,0 +1,23 @@
+package common_test
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/metrics"
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RecordTiming", func() {
+	It("records a histogram tagged by version, client and campaign type", func() {
+		common.RecordTiming("notifications.timing.test", "2", "some-client-id", "some-campaign-type-id", 1500*time.Millisecond)
+
+		registry := bytes.NewBuffer([]byte{})
+		_, err := metrics.DefaultRegistry.WriteTo(registry)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(registry.String()).To(ContainSubstring(`notifications_timing_test_seconds_sum{campaign_type="some-campaign-type-id",client="some-client-id",version="2"} 1.5` + "\n"))
+	})
+})
@@ -98,9 +98,12 @@ func (worker DeliveryWorker) Deliver(job *gobble.Job) {
 	case "v2":
 		var delivery common.Delivery
 		job.Unmarshal(&delivery)
+		delivery.QueueWait = job.QueueWait
 
 		err = worker.V2DeliveryJobProcessor.Process(delivery, worker.logger)
-		if err != nil {
+		if deferred, ok := err.(common.DeliveryDeferredError); ok {
+			job.Defer(deferred.Until)
+		} else if err != nil {
 			worker.deliveryFailureHandler.Handle(job, worker.logger)
 			status := common.StatusFailed
 			if job.ShouldRetry {
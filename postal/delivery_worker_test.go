@@ -186,6 +186,21 @@ var _ = Describe("DeliveryWorker", func() {
 					Expect(messageStatusUpdater.UpdateCall.Receives.MessageStatus).To(Equal("failed"))
 				})
 			})
+
+			Context("when the workflow defers the delivery", func() {
+				It("requeues the job for later without treating it as a failure", func() {
+					until := time.Now().Add(3 * time.Hour)
+					v2DeliveryJobProcessor.ProcessCall.Returns.Error = common.DeliveryDeferredError{Until: until}
+
+					worker.Deliver(job)
+
+					Expect(job.ShouldRetry).To(BeTrue())
+					Expect(job.RetryCount).To(Equal(0))
+					Expect(job.ActiveAt).To(Equal(until))
+					Expect(deliveryFailureHandler.HandleCall.WasCalled).To(BeFalse())
+					Expect(messageStatusUpdater.UpdateCall.Receives.MessageID).To(BeEmpty())
+				})
+			})
 		})
 
 		Context("when the job cannot be unmarshalled", func() {
@@ -0,0 +1,285 @@
+package postal
+
+import (
+	"html/template"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/mail"
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+	"github.com/pivotal-golang/lager"
+)
+
+var DigestPeriods = map[string]time.Duration{
+	models.DigestHourly: time.Hour,
+	models.DigestDaily:  24 * time.Hour,
+}
+
+type digestsListerDeleter interface {
+	ListDue(connection models.ConnectionInterface, frequency string, threshold time.Time) ([]models.Digest, error)
+	Delete(connection models.ConnectionInterface, digest models.Digest) error
+}
+
+type leaseAcquirer interface {
+	Acquire(connection models.ConnectionInterface, name, holder string, duration time.Duration) (bool, error)
+}
+
+type globalUnsubscribesGetter interface {
+	Get(connection models.ConnectionInterface, userGUID string) (bool, error)
+}
+
+type unsubscribersGetter interface {
+	Get(connection models.ConnectionInterface, userGUID, campaignTypeID string) (models.Unsubscriber, error)
+}
+
+type kindUnsubscribesGetter interface {
+	Get(connection models.ConnectionInterface, unsubscriber models.KindUnsubscriber) (bool, error)
+}
+
+type digestSigner interface {
+	Sign(payload []byte) string
+}
+
+type digestMailer interface {
+	Connect(lager.Logger) error
+	Send(mail.Message, lager.Logger) error
+}
+
+type clock interface {
+	Now() time.Time
+}
+
+const digestSenderLease = "digest-sender"
+
+type DigestSenderConfig struct {
+	Database             db.DatabaseInterface
+	Digests              digestsListerDeleter
+	Leases               leaseAcquirer
+	GlobalUnsubscribes   globalUnsubscribesGetter
+	Unsubscribers        unsubscribersGetter
+	KindUnsubscribes     kindUnsubscribesGetter
+	MailClient           digestMailer
+	MessageStatusUpdater messageStatusUpdater
+	Signer               digestSigner
+	Clock                clock
+	Logger               lager.Logger
+	Sender               string
+	Domain               string
+	Holder               string
+	PollingInterval      time.Duration
+	LeaseDuration        time.Duration
+}
+
+// DigestSender periodically sends each user one message summarizing the
+// notifications that were held for their hourly or daily digest. Every
+// instance runs one, and a lease in the database picks the one that sends.
+type DigestSender struct {
+	database             db.DatabaseInterface
+	digests              digestsListerDeleter
+	leases               leaseAcquirer
+	globalUnsubscribes   globalUnsubscribesGetter
+	unsubscribers        unsubscribersGetter
+	kindUnsubscribes     kindUnsubscribesGetter
+	mailClient           digestMailer
+	messageStatusUpdater messageStatusUpdater
+	signer               digestSigner
+	clock                clock
+	logger               lager.Logger
+	sender               string
+	domain               string
+	holder               string
+	pollingInterval      time.Duration
+	leaseDuration        time.Duration
+	timer                <-chan time.Time
+}
+
+func NewDigestSender(config DigestSenderConfig) DigestSender {
+	return DigestSender{
+		database:             config.Database,
+		digests:              config.Digests,
+		leases:               config.Leases,
+		globalUnsubscribes:   config.GlobalUnsubscribes,
+		unsubscribers:        config.Unsubscribers,
+		kindUnsubscribes:     config.KindUnsubscribes,
+		mailClient:           config.MailClient,
+		messageStatusUpdater: config.MessageStatusUpdater,
+		signer:               config.Signer,
+		clock:                config.Clock,
+		logger:               config.Logger.Session("digest-sender"),
+		sender:               config.Sender,
+		domain:               config.Domain,
+		holder:               config.Holder,
+		pollingInterval:      config.PollingInterval,
+		leaseDuration:        config.LeaseDuration,
+		timer:                time.After(0),
+	}
+}
+
+func (s DigestSender) Run() {
+	go func() {
+		for {
+			<-s.timer
+			s.Send()
+			s.timer = time.After(s.pollingInterval)
+		}
+	}()
+}
+
+// Send delivers every digest whose period has elapsed, provided this instance
+// holds the digest sender lease. A digest that fails to send is left in place
+// and retried on the next poll.
+func (s DigestSender) Send() {
+	conn := s.database.Connection()
+	now := s.clock.Now()
+
+	acquired, err := s.leases.Acquire(conn, digestSenderLease, s.holder, s.leaseDuration)
+	if err != nil {
+		s.logger.Error("digest-lease-failed", err)
+		return
+	}
+
+	if !acquired {
+		return
+	}
+
+	for _, frequency := range []string{models.DigestHourly, models.DigestDaily} {
+		digests, err := s.digests.ListDue(conn, frequency, now.Add(-DigestPeriods[frequency]))
+		if err != nil {
+			s.logger.Error("digest-list-failed", err, lager.Data{"frequency": frequency})
+			continue
+		}
+
+		for start := 0; start < len(digests); {
+			end := start + 1
+			for end < len(digests) && digests[end].UserGUID == digests[start].UserGUID {
+				end++
+			}
+
+			s.sendDigest(conn, frequency, digests[start:end])
+			start = end
+		}
+	}
+}
+
+func (s DigestSender) sendDigest(conn db.ConnectionInterface, frequency string, digests []models.Digest) {
+	logger := s.logger.WithData(lager.Data{
+		"frequency": frequency,
+		"user_guid": digests[0].UserGUID,
+	})
+
+	digests = s.dropUnsubscribed(conn, digests, logger)
+	if len(digests) == 0 {
+		return
+	}
+
+	token, err := common.NewPreferencesToken(s.signer, digests[0].UserGUID, s.clock.Now().Add(common.PreferencesTokenLifetime))
+	if err != nil {
+		logger.Error("preferences-token-failed", err)
+		return
+	}
+
+	context := common.DigestContext{
+		From:           s.sender,
+		To:             digests[len(digests)-1].Email,
+		Frequency:      frequency,
+		PreferencesURL: common.PreferencesURL(s.domain, token),
+	}
+
+	for _, digest := range digests {
+		context.Entries = append(context.Entries, common.DigestEntry{
+			Subject:   digest.Subject,
+			Text:      digest.Text,
+			HTML:      template.HTML(digest.HTML),
+			CreatedAt: digest.CreatedAt,
+		})
+	}
+
+	message, err := common.PackDigest(context)
+	if err != nil {
+		logger.Error("digest-pack-failed", err)
+		return
+	}
+
+	err = s.mailClient.Connect(logger)
+	if err != nil {
+		logger.Error("smtp-connection-error", err)
+		return
+	}
+
+	err = s.mailClient.Send(message, logger)
+	if err != nil {
+		logger.Error("digest-send-failed", err)
+		return
+	}
+
+	for _, digest := range digests {
+		err = s.digests.Delete(conn, digest)
+		if err != nil {
+			logger.Error("digest-delete-failed", err, lager.Data{"message_id": digest.MessageID})
+		}
+
+		s.messageStatusUpdater.Update(conn, digest.MessageID, common.StatusDelivered, digest.CampaignID, logger)
+	}
+
+	logger.Info("digest-sent", lager.Data{"count": len(digests)})
+}
+
+// dropUnsubscribed removes the notifications the user has unsubscribed from
+// since they were held, marking them undeliverable as an immediate delivery
+// would have been. Digests from v1 notifications carry their kind ID as the
+// campaign type ID.
+func (s DigestSender) dropUnsubscribed(conn db.ConnectionInterface, digests []models.Digest, logger lager.Logger) []models.Digest {
+	globallyUnsubscribed, err := s.globalUnsubscribes.Get(conn, digests[0].UserGUID)
+	if err != nil {
+		logger.Error("unsubscribe-lookup-failed", err)
+		return nil
+	}
+
+	var subscribed []models.Digest
+	for _, digest := range digests {
+		unsubscribed := globallyUnsubscribed
+		if !unsubscribed {
+			unsubscribed, err = s.isUnsubscribed(conn, digest)
+			if err != nil {
+				logger.Error("unsubscribe-lookup-failed", err)
+				return nil
+			}
+		}
+
+		if !unsubscribed {
+			subscribed = append(subscribed, digest)
+			continue
+		}
+
+		err = s.digests.Delete(conn, digest)
+		if err != nil {
+			logger.Error("digest-delete-failed", err, lager.Data{"message_id": digest.MessageID})
+		}
+
+		s.messageStatusUpdater.Update(conn, digest.MessageID, common.StatusUndeliverable, digest.CampaignID, logger)
+	}
+
+	return subscribed
+}
+
+func (s DigestSender) isUnsubscribed(conn db.ConnectionInterface, digest models.Digest) (bool, error) {
+	if digest.CampaignID == "" {
+		return s.kindUnsubscribes.Get(conn, models.KindUnsubscriber{
+			UserGUID: digest.UserGUID,
+			ClientID: digest.ClientID,
+			KindID:   digest.CampaignTypeID,
+		})
+	}
+
+	_, err := s.unsubscribers.Get(conn, digest.UserGUID, digest.CampaignTypeID)
+	if err != nil {
+		if _, ok := err.(models.RecordNotFoundError); ok {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
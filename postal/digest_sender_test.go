@@ -0,0 +1,186 @@
+package postal_test
+
+import (
+	"bytes"
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/postal"
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+	"github.com/pivotal-golang/lager"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DigestSender", func() {
+	var (
+		sender               postal.DigestSender
+		digests              *mocks.DigestsRepository
+		leases               *mocks.LeasesRepository
+		globalUnsubscribes   *mocks.GlobalUnsubscribesRepository
+		unsubscribers        *mocks.UnsubscribersRepository
+		kindUnsubscribes     *mocks.KindUnsubscribesRepository
+		mailClient           *mocks.MailClient
+		messageStatusUpdater *mocks.MessageStatusUpdater
+		conn                 *mocks.Connection
+		now                  time.Time
+	)
+
+	BeforeEach(func() {
+		conn = mocks.NewConnection()
+		database := mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = conn
+
+		now = time.Date(2015, time.June, 8, 14, 0, 0, 0, time.UTC)
+		clock := mocks.NewClock()
+		clock.NowCall.Returns.Time = now
+
+		digests = mocks.NewDigestsRepository()
+		digests.ListDueCall.Returns.Digests = map[string][]models.Digest{
+			models.DigestHourly: {
+				{ID: "digest-1", MessageID: "message-1", UserGUID: "user-1", Email: "user-1@example.com", Subject: "first", Text: "first text"},
+				{ID: "digest-2", MessageID: "message-2", CampaignID: "campaign-1", UserGUID: "user-1", Email: "user-1@example.com", Subject: "second", Text: "second text"},
+				{ID: "digest-3", MessageID: "message-3", UserGUID: "user-2", Email: "user-2@example.com", Subject: "third", Text: "third text"},
+			},
+		}
+
+		leases = mocks.NewLeasesRepository()
+		leases.AcquireCall.Returns.Acquired = true
+
+		globalUnsubscribes = mocks.NewGlobalUnsubscribesRepository()
+		unsubscribers = mocks.NewUnsubscribersRepository()
+		unsubscribers.GetCall.Returns.Error = models.NewRecordNotFoundError("not unsubscribed")
+		kindUnsubscribes = mocks.NewKindUnsubscribesRepository()
+
+		signer := mocks.NewSigner()
+		signer.SignCall.Returns.Token = "some-token"
+
+		mailClient = mocks.NewMailClient()
+		messageStatusUpdater = mocks.NewMessageStatusUpdater()
+
+		logger := lager.NewLogger("notifications")
+		logger.RegisterSink(lager.NewWriterSink(bytes.NewBuffer([]byte{}), lager.DEBUG))
+
+		sender = postal.NewDigestSender(postal.DigestSenderConfig{
+			Database:             database,
+			Digests:              digests,
+			Leases:               leases,
+			GlobalUnsubscribes:   globalUnsubscribes,
+			Unsubscribers:        unsubscribers,
+			KindUnsubscribes:     kindUnsubscribes,
+			MailClient:           mailClient,
+			MessageStatusUpdater: messageStatusUpdater,
+			Signer:               signer,
+			Clock:                clock,
+			Logger:               logger,
+			Sender:               "sender@example.com",
+			Domain:               "notifications.example.com",
+			Holder:               "some-instance",
+			PollingInterval:      time.Minute,
+			LeaseDuration:        10 * time.Minute,
+		})
+	})
+
+	Describe("Send", func() {
+		It("looks up the digests that are due for each frequency", func() {
+			sender.Send()
+
+			Expect(digests.ListDueCall.Receives.Connection).To(Equal(conn))
+			Expect(digests.ListDueCall.Receives.Frequencies).To(Equal([]string{models.DigestHourly, models.DigestDaily}))
+			Expect(digests.ListDueCall.Receives.Thresholds).To(Equal([]time.Time{now.Add(-1 * time.Hour), now.Add(-24 * time.Hour)}))
+		})
+
+		It("sends one message per user", func() {
+			sender.Send()
+
+			Expect(mailClient.SendCall.CallCount).To(Equal(2))
+
+			message := mailClient.SendCall.Receives.Message
+			Expect(message.From).To(Equal("sender@example.com"))
+			Expect(message.To).To(Equal("user-2@example.com"))
+			Expect(message.Subject).To(Equal("Your hourly digest: 1 notification"))
+		})
+
+		It("removes the sent digests and marks their messages as delivered", func() {
+			sender.Send()
+
+			Expect(digests.DeleteCall.Receives.Digests).To(HaveLen(3))
+			Expect(digests.DeleteCall.Receives.Digests[0].ID).To(Equal("digest-1"))
+			Expect(digests.DeleteCall.Receives.Digests[2].ID).To(Equal("digest-3"))
+
+			Expect(messageStatusUpdater.UpdateCall.Receives.Connection).To(Equal(conn))
+			Expect(messageStatusUpdater.UpdateCall.Receives.MessageID).To(Equal("message-3"))
+			Expect(messageStatusUpdater.UpdateCall.Receives.MessageStatus).To(Equal(common.StatusDelivered))
+		})
+
+		It("links each digest to the preference center", func() {
+			sender.Send()
+
+			message := mailClient.SendCall.Receives.Message
+			Expect(message.Headers).To(ContainElement("List-Unsubscribe: <https://notifications.example.com/preferences?token=some-token>"))
+			Expect(message.Body[0].Content).To(ContainSubstring("https://notifications.example.com/preferences?token=some-token"))
+		})
+
+		Context("when another instance holds the lease", func() {
+			It("does not send anything", func() {
+				leases.AcquireCall.Returns.Acquired = false
+
+				sender.Send()
+
+				Expect(leases.AcquireCall.Receives.Connection).To(Equal(conn))
+				Expect(leases.AcquireCall.Receives.Name).To(Equal("digest-sender"))
+				Expect(leases.AcquireCall.Receives.Holder).To(Equal("some-instance"))
+				Expect(leases.AcquireCall.Receives.Duration).To(Equal(10 * time.Minute))
+				Expect(digests.ListDueCall.Receives.Frequencies).To(BeEmpty())
+				Expect(mailClient.SendCall.CallCount).To(Equal(0))
+			})
+		})
+
+		Context("when the user unsubscribed after a notification was held", func() {
+			It("drops the notifications from the campaign types the user left", func() {
+				unsubscribers.GetCall.Returns.Error = nil
+
+				sender.Send()
+
+				Expect(unsubscribers.GetCall.Receives.UserGUID).To(Equal("user-1"))
+				Expect(mailClient.SendCall.CallCount).To(Equal(2))
+				Expect(digests.DeleteCall.Receives.Digests[0].ID).To(Equal("digest-2"))
+				Expect(mailClient.SendCall.Receives.Message.Subject).To(Equal("Your hourly digest: 1 notification"))
+			})
+
+			It("drops every notification for a user who unsubscribed from everything", func() {
+				globalUnsubscribes.GetCall.Returns.Unsubscribed = true
+
+				sender.Send()
+
+				Expect(mailClient.SendCall.CallCount).To(Equal(0))
+				Expect(digests.DeleteCall.Receives.Digests).To(HaveLen(3))
+				Expect(messageStatusUpdater.UpdateCall.Receives.MessageStatus).To(Equal(common.StatusUndeliverable))
+			})
+		})
+
+		Context("when a digest fails to send", func() {
+			It("keeps the held notifications for the next attempt", func() {
+				mailClient.SendCall.Returns.Error = errors.New("some smtp error")
+
+				sender.Send()
+
+				Expect(digests.DeleteCall.Receives.Digests).To(BeEmpty())
+				Expect(messageStatusUpdater.UpdateCall.Receives.MessageID).To(BeEmpty())
+			})
+		})
+
+		Context("when the digests cannot be listed", func() {
+			It("does not send anything", func() {
+				digests.ListDueCall.Returns.Error = errors.New("some database error")
+
+				sender.Send()
+
+				Expect(mailClient.SendCall.CallCount).To(Equal(0))
+			})
+		})
+	})
+})
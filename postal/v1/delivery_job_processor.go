@@ -2,6 +2,7 @@ package v1
 
 import (
 	"strings"
+	"time"
 
 	"github.com/cloudfoundry-incubator/notifications/db"
 	"github.com/cloudfoundry-incubator/notifications/gobble"
@@ -10,6 +11,7 @@ import (
 	"github.com/cloudfoundry-incubator/notifications/postal/common"
 	"github.com/cloudfoundry-incubator/notifications/uaa"
 	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	v2models "github.com/cloudfoundry-incubator/notifications/v2/models"
 	"github.com/pivotal-golang/lager"
 )
 
@@ -50,6 +52,22 @@ type globalUnsubscribesGetter interface {
 	Get(connection models.ConnectionInterface, userGUID string) (bool, error)
 }
 
+type emailUnsubscribesGetter interface {
+	Get(connection v2models.ConnectionInterface, email, clientID, campaignTypeID string) (bool, error)
+}
+
+type digestPreferencesGetter interface {
+	Get(connection v2models.ConnectionInterface, userGUID, clientID, campaignTypeID string) (string, error)
+}
+
+type digestsInserter interface {
+	Insert(connection v2models.ConnectionInterface, digest v2models.Digest) (v2models.Digest, error)
+}
+
+type quietHoursGetter interface {
+	Get(connection v2models.ConnectionInterface, userGUID string) (v2models.QuietHours, error)
+}
+
 type DeliveryJobProcessorConfig struct {
 	DBTrace bool
 	UAAHost string
@@ -66,6 +84,10 @@ type DeliveryJobProcessorConfig struct {
 	ReceiptsRepo           receiptsCreator
 	UnsubscribesRepo       unsubscribesGetter
 	GlobalUnsubscribesRepo globalUnsubscribesGetter
+	EmailUnsubscribesRepo  emailUnsubscribesGetter
+	DigestPreferencesRepo  digestPreferencesGetter
+	DigestsRepo            digestsInserter
+	QuietHoursRepo         quietHoursGetter
 	MessageStatusUpdater   messageStatusUpdater
 	DeliveryFailureHandler deliveryFailureHandler
 }
@@ -86,6 +108,10 @@ type DeliveryJobProcessor struct {
 	receiptsRepo           receiptsCreator
 	unsubscribesRepo       unsubscribesGetter
 	globalUnsubscribesRepo globalUnsubscribesGetter
+	emailUnsubscribesRepo  emailUnsubscribesGetter
+	digestPreferencesRepo  digestPreferencesGetter
+	digestsRepo            digestsInserter
+	quietHoursRepo         quietHoursGetter
 	messageStatusUpdater   messageStatusUpdater
 	deliveryFailureHandler deliveryFailureHandler
 }
@@ -107,6 +133,10 @@ func NewDeliveryJobProcessor(config DeliveryJobProcessorConfig) DeliveryJobProce
 		receiptsRepo:           config.ReceiptsRepo,
 		unsubscribesRepo:       config.UnsubscribesRepo,
 		globalUnsubscribesRepo: config.GlobalUnsubscribesRepo,
+		emailUnsubscribesRepo:  config.EmailUnsubscribesRepo,
+		digestPreferencesRepo:  config.DigestPreferencesRepo,
+		digestsRepo:            config.DigestsRepo,
+		quietHoursRepo:         config.QuietHoursRepo,
 		messageStatusUpdater:   config.MessageStatusUpdater,
 		deliveryFailureHandler: config.DeliveryFailureHandler,
 	}
@@ -124,6 +154,8 @@ func (p DeliveryJobProcessor) Process(job *gobble.Job, logger lager.Logger) erro
 		return nil
 	}
 
+	common.RecordTiming("notifications.queue.wait", "1", delivery.ClientID, delivery.Options.KindID, job.QueueWait)
+
 	logger = logger.WithData(lager.Data{
 		"message_id":      delivery.MessageID,
 		"vcap_request_id": delivery.VCAPRequestID,
@@ -164,7 +196,41 @@ func (p DeliveryJobProcessor) Process(job *gobble.Job, logger lager.Logger) erro
 		"recipient": delivery.Email,
 	})
 
-	if p.shouldDeliver(delivery, logger) {
+	critical := p.isCritical(p.database.Connection(), delivery.Options.KindID, delivery.ClientID)
+	quietHours := p.quietHours(delivery, logger)
+	delivery.TimeZone = quietHours.TimeZone
+
+	if p.shouldDeliver(delivery, critical, logger) {
+		if !critical && p.holdForDigest(delivery, logger) {
+			metrics.NewMetric("counter", map[string]interface{}{
+				"name": "notifications.worker.digested",
+			}).Log()
+
+			return nil
+		}
+
+		if !critical {
+			now := time.Now()
+			next := common.DeliveryWindow{
+				TimeZone:   quietHours.TimeZone,
+				QuietStart: quietHours.Start,
+				QuietEnd:   quietHours.End,
+			}.Next(now)
+
+			if next.After(now) {
+				logger.Info("delivery-deferred", lager.Data{
+					"active_at": next.Format(time.RFC3339),
+				})
+				job.Defer(next)
+
+				metrics.NewMetric("counter", map[string]interface{}{
+					"name": "notifications.worker.deferred",
+				}).Log()
+
+				return nil
+			}
+		}
+
 		status := p.process(delivery, logger)
 
 		if status != common.StatusDelivered {
@@ -187,7 +253,9 @@ func (p DeliveryJobProcessor) Process(job *gobble.Job, logger lager.Logger) erro
 func (p DeliveryJobProcessor) process(delivery common.Delivery, logger lager.Logger) string {
 	context, err := p.packager.PrepareContext(delivery, p.sender, p.domain)
 	if err != nil {
-		panic(err)
+		logger.Error("prepare-context-failed", err)
+		p.messageStatusUpdater.Update(p.database.Connection(), delivery.MessageID, common.StatusFailed, "", logger)
+		return common.StatusFailed
 	}
 
 	message, err := p.packager.Pack(context)
@@ -197,18 +265,18 @@ func (p DeliveryJobProcessor) process(delivery common.Delivery, logger lager.Log
 		return common.StatusFailed
 	}
 
-	status := p.sendMail(delivery.MessageID, message, logger)
+	status := p.sendMail(delivery, message, logger)
 	p.messageStatusUpdater.Update(p.database.Connection(), delivery.MessageID, status, "", logger)
 
 	return status
 }
 
-func (p DeliveryJobProcessor) shouldDeliver(delivery common.Delivery, logger lager.Logger) bool {
-	conn := p.database.Connection()
-	if p.isCritical(conn, delivery.Options.KindID, delivery.ClientID) {
+func (p DeliveryJobProcessor) shouldDeliver(delivery common.Delivery, critical bool, logger lager.Logger) bool {
+	if critical {
 		return true
 	}
 
+	conn := p.database.Connection()
 	globallyUnsubscribed, err := p.globalUnsubscribesRepo.Get(conn, delivery.UserGUID)
 	if err != nil || globallyUnsubscribed {
 		logger.Info("user-unsubscribed")
@@ -223,6 +291,15 @@ func (p DeliveryJobProcessor) shouldDeliver(delivery common.Delivery, logger lag
 		return false
 	}
 
+	if delivery.UserGUID == "" {
+		isUnsubscribed, err = p.emailUnsubscribesRepo.Get(conn, delivery.Email, delivery.ClientID, delivery.Options.KindID)
+		if err != nil || isUnsubscribed {
+			logger.Info("email-unsubscribed")
+			p.messageStatusUpdater.Update(p.database.Connection(), delivery.MessageID, common.StatusUndeliverable, "", logger)
+			return false
+		}
+	}
+
 	if delivery.Email == "" {
 		logger.Info("no-email-address-for-user")
 		p.messageStatusUpdater.Update(p.database.Connection(), delivery.MessageID, common.StatusUndeliverable, "", logger)
@@ -238,7 +315,46 @@ func (p DeliveryJobProcessor) shouldDeliver(delivery common.Delivery, logger lag
 	return true
 }
 
-func (p DeliveryJobProcessor) sendMail(messageID string, message mail.Message, logger lager.Logger) string {
+// holdForDigest stores the delivery to be sent in the user's next digest when
+// they have asked for this kind to be batched. The message stays queued until
+// the digest goes out. Critical kinds are never passed in.
+func (p DeliveryJobProcessor) holdForDigest(delivery common.Delivery, logger lager.Logger) bool {
+	if delivery.UserGUID == "" {
+		return false
+	}
+
+	conn := p.database.Connection()
+	frequency, err := p.digestPreferencesRepo.Get(conn, delivery.UserGUID, delivery.ClientID, delivery.Options.KindID)
+	if err != nil {
+		logger.Error("digest-preference-lookup-failed", err)
+		return false
+	}
+
+	if frequency == v2models.DigestImmediate {
+		return false
+	}
+
+	_, err = p.digestsRepo.Insert(conn, v2models.Digest{
+		MessageID:      delivery.MessageID,
+		UserGUID:       delivery.UserGUID,
+		Email:          delivery.Email,
+		ClientID:       delivery.ClientID,
+		CampaignTypeID: delivery.Options.KindID,
+		Frequency:      frequency,
+		Subject:        delivery.Options.Subject,
+		Text:           delivery.Options.Text,
+		HTML:           delivery.Options.HTML.BodyContent,
+	})
+	if err != nil {
+		logger.Error("digest-insert-failed", err)
+		return false
+	}
+
+	logger.Info("held-for-digest", lager.Data{"frequency": frequency})
+	return true
+}
+
+func (p DeliveryJobProcessor) sendMail(delivery common.Delivery, message mail.Message, logger lager.Logger) string {
 	err := p.mailClient.Connect(logger)
 	if err != nil {
 		logger.Error("smtp-connection-error", err)
@@ -247,17 +363,39 @@ func (p DeliveryJobProcessor) sendMail(messageID string, message mail.Message, l
 
 	logger.Info("delivery-start")
 
+	sendStarted := time.Now()
 	err = p.mailClient.Send(message, logger)
 	if err != nil {
 		logger.Error("delivery-failed-smtp-error", err)
 		return common.StatusFailed
 	}
 
+	common.RecordTiming("notifications.delivery.send", "1", delivery.ClientID, delivery.Options.KindID, time.Since(sendStarted))
+	if !delivery.RequestReceived.IsZero() {
+		common.RecordTiming("notifications.delivery.end-to-end", "1", delivery.ClientID, delivery.Options.KindID, time.Since(delivery.RequestReceived))
+	}
+
 	logger.Info("message-sent")
 
 	return common.StatusDelivered
 }
 
+// quietHours loads the recipient's quiet hours and time zone. Failing to load
+// them should not hold up the notification, so errors are only logged.
+func (p DeliveryJobProcessor) quietHours(delivery common.Delivery, logger lager.Logger) v2models.QuietHours {
+	if delivery.UserGUID == "" {
+		return v2models.QuietHours{}
+	}
+
+	quietHours, err := p.quietHoursRepo.Get(p.database.Connection(), delivery.UserGUID)
+	if err != nil {
+		logger.Error("quiet-hours-lookup-failed", err)
+		return v2models.QuietHours{}
+	}
+
+	return quietHours
+}
+
 func (p DeliveryJobProcessor) isCritical(conn db.ConnectionInterface, kindID, clientID string) bool {
 	kind, err := p.kindsRepo.Find(conn, kindID, clientID)
 	if _, ok := err.(models.NotFoundError); ok {
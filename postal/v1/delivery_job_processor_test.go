@@ -14,6 +14,7 @@ import (
 	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
 	"github.com/cloudfoundry-incubator/notifications/uaa"
 	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	v2models "github.com/cloudfoundry-incubator/notifications/v2/models"
 	"github.com/pivotal-golang/conceal"
 	"github.com/pivotal-golang/lager"
 
@@ -31,6 +32,10 @@ var _ = Describe("DeliveryJobProcessor", func() {
 		queue                  *mocks.Queue
 		unsubscribesRepo       *mocks.UnsubscribesRepo
 		globalUnsubscribesRepo *mocks.GlobalUnsubscribesRepo
+		emailUnsubscribesRepo  *mocks.EmailUnsubscribesRepository
+		digestPreferencesRepo  *mocks.DigestPreferencesRepository
+		digestsRepo            *mocks.DigestsRepository
+		quietHoursRepo         *mocks.QuietHoursRepository
 		kindsRepo              *mocks.KindsRepo
 		database               *mocks.Database
 		campaignJobProcessor   *mocks.CampaignJobProcessor
@@ -57,6 +62,11 @@ var _ = Describe("DeliveryJobProcessor", func() {
 		queue = mocks.NewQueue()
 		unsubscribesRepo = mocks.NewUnsubscribesRepo()
 		globalUnsubscribesRepo = mocks.NewGlobalUnsubscribesRepo()
+		emailUnsubscribesRepo = mocks.NewEmailUnsubscribesRepository()
+		digestPreferencesRepo = mocks.NewDigestPreferencesRepository()
+		digestPreferencesRepo.GetCall.Returns.Frequency = v2models.DigestImmediate
+		digestsRepo = mocks.NewDigestsRepository()
+		quietHoursRepo = mocks.NewQuietHoursRepository()
 
 		kindsRepo = mocks.NewKindsRepo()
 		kindsRepo.FindCall.Returns.Kinds = []models.Kind{
@@ -101,7 +111,7 @@ var _ = Describe("DeliveryJobProcessor", func() {
 			Sender:  "from@example.com",
 			Domain:  "example.com",
 
-			Packager:    common.NewPackager(templateLoader, cloak),
+			Packager:    common.NewPackager(templateLoader, mocks.NewPartialsLoader(), cloak, mocks.NewSigner()),
 			MailClient:  mailClient,
 			Database:    database,
 			TokenLoader: tokenLoader,
@@ -111,6 +121,10 @@ var _ = Describe("DeliveryJobProcessor", func() {
 			ReceiptsRepo:           receiptsRepo,
 			UnsubscribesRepo:       unsubscribesRepo,
 			GlobalUnsubscribesRepo: globalUnsubscribesRepo,
+			EmailUnsubscribesRepo:  emailUnsubscribesRepo,
+			DigestPreferencesRepo:  digestPreferencesRepo,
+			DigestsRepo:            digestsRepo,
+			QuietHoursRepo:         quietHoursRepo,
 			MessageStatusUpdater:   messageStatusUpdater,
 			DeliveryFailureHandler: deliveryFailureHandler,
 		})
@@ -197,7 +211,7 @@ var _ = Describe("DeliveryJobProcessor", func() {
 				Sender:  "from@example.com",
 				Domain:  "example.com",
 
-				Packager:    common.NewPackager(templateLoader, cloak),
+				Packager:    common.NewPackager(templateLoader, mocks.NewPartialsLoader(), cloak, mocks.NewSigner()),
 				MailClient:  mailClient,
 				Database:    database,
 				TokenLoader: tokenLoader,
@@ -207,6 +221,10 @@ var _ = Describe("DeliveryJobProcessor", func() {
 				ReceiptsRepo:           receiptsRepo,
 				UnsubscribesRepo:       unsubscribesRepo,
 				GlobalUnsubscribesRepo: globalUnsubscribesRepo,
+				EmailUnsubscribesRepo:  emailUnsubscribesRepo,
+				DigestPreferencesRepo:  digestPreferencesRepo,
+				DigestsRepo:            digestsRepo,
+				QuietHoursRepo:         quietHoursRepo,
 				MessageStatusUpdater:   messageStatusUpdater,
 				DeliveryFailureHandler: deliveryFailureHandler,
 			})
@@ -576,6 +594,153 @@ var _ = Describe("DeliveryJobProcessor", func() {
 			})
 		})
 
+		Context("when the user receives the kind in a digest", func() {
+			BeforeEach(func() {
+				digestPreferencesRepo.GetCall.Returns.Frequency = v2models.DigestDaily
+			})
+
+			It("holds the notification for the digest instead of sending it", func() {
+				processor.Process(job, logger)
+
+				Expect(digestPreferencesRepo.GetCall.Receives.UserGUID).To(Equal("user-123"))
+				Expect(digestPreferencesRepo.GetCall.Receives.ClientID).To(Equal("some-client"))
+				Expect(digestPreferencesRepo.GetCall.Receives.CampaignTypeID).To(Equal("some-kind"))
+
+				Expect(digestsRepo.InsertCall.Receives.Digest).To(Equal(v2models.Digest{
+					MessageID:      messageID,
+					UserGUID:       "user-123",
+					Email:          "user-123@example.com",
+					ClientID:       "some-client",
+					CampaignTypeID: "some-kind",
+					Frequency:      v2models.DigestDaily,
+					Subject:        "the subject",
+					Text:           "body content",
+				}))
+				Expect(mailClient.SendCall.CallCount).To(Equal(0))
+			})
+
+			Context("and the kind is critical", func() {
+				BeforeEach(func() {
+					kindsRepo.FindCall.Returns.Kinds = []models.Kind{
+						{
+							ID:       "some-kind",
+							ClientID: "some-client",
+							Critical: true,
+						},
+					}
+				})
+
+				It("sends the email immediately", func() {
+					processor.Process(job, logger)
+
+					Expect(digestsRepo.InsertCall.Receives.Digest).To(Equal(v2models.Digest{}))
+					Expect(mailClient.SendCall.CallCount).To(Equal(1))
+				})
+			})
+
+			Context("and the notification cannot be held", func() {
+				It("sends the email immediately", func() {
+					digestsRepo.InsertCall.Returns.Error = errors.New("some database error")
+
+					processor.Process(job, logger)
+
+					Expect(mailClient.SendCall.CallCount).To(Equal(1))
+				})
+			})
+		})
+
+		Context("when the user is in their quiet hours", func() {
+			var quietHoursEnd time.Time
+
+			BeforeEach(func() {
+				now := time.Now().UTC()
+				quietHoursEnd = now.Add(time.Hour).Truncate(time.Minute)
+				quietHoursRepo.GetCall.Returns.QuietHours = v2models.QuietHours{
+					UserGUID: "user-123",
+					TimeZone: "UTC",
+					Start:    now.Add(-time.Hour).Format("15:04"),
+					End:      quietHoursEnd.Format("15:04"),
+				}
+			})
+
+			It("defers the job until the quiet hours end", func() {
+				processor.Process(job, logger)
+
+				Expect(quietHoursRepo.GetCall.Receives.UserGUID).To(Equal("user-123"))
+				Expect(mailClient.SendCall.CallCount).To(Equal(0))
+				Expect(job.ShouldRetry).To(BeTrue())
+				Expect(job.RetryCount).To(Equal(0))
+				Expect(job.ActiveAt).To(BeTemporally("==", quietHoursEnd))
+				Expect(deliveryFailureHandler.HandleCall.WasCalled).To(BeFalse())
+			})
+
+			Context("and the kind is critical", func() {
+				BeforeEach(func() {
+					kindsRepo.FindCall.Returns.Kinds = []models.Kind{
+						{
+							ID:       "some-kind",
+							ClientID: "some-client",
+							Critical: true,
+						},
+					}
+				})
+
+				It("sends the email immediately", func() {
+					processor.Process(job, logger)
+
+					Expect(mailClient.SendCall.CallCount).To(Equal(1))
+					Expect(job.ShouldRetry).To(BeFalse())
+				})
+			})
+
+			Context("and the quiet hours cannot be loaded", func() {
+				It("sends the email immediately", func() {
+					quietHoursRepo.GetCall.Returns.Error = errors.New("some database error")
+
+					processor.Process(job, logger)
+
+					Expect(mailClient.SendCall.CallCount).To(Equal(1))
+				})
+			})
+		})
+
+		Context("when the recipient is an email address", func() {
+			BeforeEach(func() {
+				delivery.UserGUID = ""
+				delivery.Email = "someone@example.com"
+				job = gobble.NewJob(delivery)
+			})
+
+			It("checks whether the email address has unsubscribed", func() {
+				processor.Process(job, logger)
+
+				Expect(emailUnsubscribesRepo.GetCall.Receives.Connection).To(Equal(conn))
+				Expect(emailUnsubscribesRepo.GetCall.Receives.Email).To(Equal("someone@example.com"))
+				Expect(emailUnsubscribesRepo.GetCall.Receives.ClientID).To(Equal("some-client"))
+				Expect(emailUnsubscribesRepo.GetCall.Receives.CampaignTypeID).To(Equal("some-kind"))
+				Expect(mailClient.SendCall.CallCount).To(Equal(1))
+			})
+
+			Context("and the email address has unsubscribed", func() {
+				BeforeEach(func() {
+					emailUnsubscribesRepo.GetCall.Returns.Unsubscribed = true
+				})
+
+				It("does not send the email", func() {
+					processor.Process(job, logger)
+
+					Expect(mailClient.SendCall.CallCount).To(Equal(0))
+				})
+
+				It("updates the message status as undeliverable", func() {
+					processor.Process(job, logger)
+
+					Expect(messageStatusUpdater.UpdateCall.Receives.MessageID).To(Equal(messageID))
+					Expect(messageStatusUpdater.UpdateCall.Receives.MessageStatus).To(Equal(common.StatusUndeliverable))
+				})
+			})
+		})
+
 		Context("when the template contains syntax errors", func() {
 			BeforeEach(func() {
 				templateLoader.LoadTemplatesCall.Returns.Templates = common.Templates{
@@ -629,6 +794,31 @@ var _ = Describe("DeliveryJobProcessor", func() {
 			})
 		})
 
+		Context("when the message context cannot be prepared", func() {
+			BeforeEach(func() {
+				templateLoader.LoadTemplatesCall.Returns.Error = errors.New("templates are unavailable")
+			})
+
+			It("does not panic", func() {
+				Expect(func() {
+					processor.Process(job, logger)
+				}).ToNot(Panic())
+			})
+
+			It("marks the job for retry later", func() {
+				processor.Process(job, logger)
+
+				Expect(deliveryFailureHandler.HandleCall.Receives.Job).To(Equal(job))
+			})
+
+			It("updates the message status as failed", func() {
+				processor.Process(job, logger)
+
+				Expect(messageStatusUpdater.UpdateCall.Receives.MessageID).To(Equal(messageID))
+				Expect(messageStatusUpdater.UpdateCall.Receives.MessageStatus).To(Equal(common.StatusFailed))
+			})
+		})
+
 		Context("when the job contains malformed JSON", func() {
 			BeforeEach(func() {
 				job.Payload = `{"Space":"my-space","Options":{"HTML":"<p>some text that just abruptly ends`
@@ -64,8 +64,9 @@ func (loader TemplatesLoader) loadTemplate(conn db.ConnectionInterface, template
 	}
 
 	return common.Templates{
-		Subject: template.Subject,
-		Text:    template.Text,
-		HTML:    template.HTML,
+		Subject:  template.Subject,
+		Text:     template.Text,
+		HTML:     template.HTML,
+		Metadata: template.Metadata,
 	}, nil
 }
@@ -62,11 +62,12 @@ var _ = Describe("TemplateLoader", func() {
 		Context("when the kind has a template", func() {
 			BeforeEach(func() {
 				templatesRepo.FindByIDCall.Returns.Template = models.Template{
-					ID:      "my-kind-template",
-					Name:    "my-kind-template",
-					HTML:    "<p>kind template</p>",
-					Text:    "some kind template text",
-					Subject: "kind subject",
+					ID:       "my-kind-template",
+					Name:     "my-kind-template",
+					HTML:     "<p>kind template</p>",
+					Text:     "some kind template text",
+					Subject:  "kind subject",
+					Metadata: `{"derive_plain_text": true}`,
 				}
 
 				kindsRepo.FindCall.Returns.Kinds = []models.Kind{
@@ -82,9 +83,10 @@ var _ = Describe("TemplateLoader", func() {
 				templates, err := loader.LoadTemplates("my-client-id", "my-kind-id", "")
 				Expect(err).ToNot(HaveOccurred())
 				Expect(templates).To(Equal(common.Templates{
-					HTML:    "<p>kind template</p>",
-					Text:    "some kind template text",
-					Subject: "kind subject",
+					HTML:     "<p>kind template</p>",
+					Text:     "some kind template text",
+					Subject:  "kind subject",
+					Metadata: `{"derive_plain_text": true}`,
 				}))
 
 				Expect(templatesRepo.FindByIDCall.Receives.Connection).To(Equal(conn))
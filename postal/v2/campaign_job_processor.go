@@ -1,13 +1,16 @@
 package v2
 
 import (
-	"fmt"
+	"sort"
 	"time"
 
 	"github.com/cloudfoundry-incubator/notifications/cf"
 	"github.com/cloudfoundry-incubator/notifications/gobble"
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
 	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
 	"github.com/cloudfoundry-incubator/notifications/v2/horde"
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
 	"github.com/cloudfoundry-incubator/notifications/v2/queue"
 	"github.com/pivotal-golang/lager"
 )
@@ -33,44 +36,78 @@ type htmlPartsExtractor interface {
 	Extract(html string) (doctype, head, bodyContent, bodyAttributes string, err error)
 }
 
+// fanoutBatchSize bounds how many recipients are enqueued in one transaction.
+const fanoutBatchSize = 500
+
+// emailResolutionBatchSize bounds how many user IDs are looked up in UAA at
+// once when resolving recipient addresses during the fan-out.
+const emailResolutionBatchSize = 250
+
+type clock interface {
+	Now() time.Time
+}
+
+type campaignFanoutRecorder interface {
+	Get(conn models.ConnectionInterface, campaignID string) (models.Campaign, error)
+	UpdateExcludedCount(conn models.ConnectionInterface, campaignID string) error
+	SetFanoutProgress(conn models.ConnectionInterface, campaignID string, progress int) error
+	StartFanout(conn models.ConnectionInterface, campaignID string, startedAt time.Time) error
+	CompleteFanout(conn models.ConnectionInterface, campaignID string, completedAt time.Time) error
+}
+
+type campaignFiltersRepository interface {
+	Insert(conn models.ConnectionInterface, campaignID, kind string, recipients []string) error
+	List(conn models.ConnectionInterface, campaignID string, recipients []string) ([]models.CampaignFilter, error)
+	RecordExcluded(conn models.ConnectionInterface, campaignID string, recipients []string) error
+}
+
+type campaignRecipientsLister interface {
+	ListExisting(conn models.ConnectionInterface, campaignID string, recipients []string) ([]string, error)
+}
+
 type CampaignJobProcessor struct {
 	emailFormatter emailAddressFormatter
 	htmlExtractor  htmlPartsExtractor
 	enqueuer       enqueuer
-
-	emails audienceGenerator
-	spaces audienceGenerator
-	orgs   audienceGenerator
-	users  audienceGenerator
-}
-
-type audienceGenerator interface {
-	GenerateAudiences(inputs []string, logger lager.Logger) ([]horde.Audience, error)
+	campaigns      campaignFanoutRecorder
+	filters        campaignFiltersRepository
+	recipients     campaignRecipientsLister
+	tokenLoader    tokenLoader
+	userLoader     userLoader
+	clock          clock
+	generators     horde.Generators
 }
 
 type enqueuer interface {
-	Enqueue(conn queue.ConnectionInterface, users []queue.User, options queue.Options, space cf.CloudControllerSpace, organization cf.CloudControllerOrganization, clientID, uaaHost, scope, vcapRequestID string, reqReceived time.Time, campaignID string)
+	Enqueue(conn queue.ConnectionInterface, users []queue.User, options queue.Options, space cf.CloudControllerSpace, organization cf.CloudControllerOrganization, clientID, uaaHost, scope, vcapRequestID string, reqReceived time.Time, campaignID string) error
 }
 
-func NewCampaignJobProcessor(emailFormatter emailAddressFormatter, htmlExtractor htmlPartsExtractor, emails, spaces, orgs, users audienceGenerator, enqueuer enqueuer) CampaignJobProcessor {
+func NewCampaignJobProcessor(emailFormatter emailAddressFormatter, htmlExtractor htmlPartsExtractor, generators horde.Generators, campaigns campaignFanoutRecorder, filters campaignFiltersRepository, recipients campaignRecipientsLister, enqueuer enqueuer, tokenLoader tokenLoader, userLoader userLoader, clock clock) CampaignJobProcessor {
 	return CampaignJobProcessor{
 		emailFormatter: emailFormatter,
 		htmlExtractor:  htmlExtractor,
 		enqueuer:       enqueuer,
-		emails:         emails,
-		spaces:         spaces,
-		orgs:           orgs,
-		users:          users,
+		campaigns:      campaigns,
+		filters:        filters,
+		recipients:     recipients,
+		tokenLoader:    tokenLoader,
+		userLoader:     userLoader,
+		clock:          clock,
+		generators:     generators,
 	}
 }
 
-func key(user horde.User) string {
-	if user.GUID != "" {
-		return user.GUID
-	}
-	return user.Email
+type audienceMember struct {
+	audience string
+	inputs   []string
 }
 
+// Process fans the campaign out one audience member at a time, enqueueing the
+// recipients of each member in bounded batches. Progress is checkpointed on
+// the campaign after every member, so a retried job resumes where the last
+// attempt stopped instead of resolving the whole audience again. The exclude
+// and intersect blocks are stored before the first member and each batch is
+// checked against them, so they are not held in memory during the fan-out.
 func (p CampaignJobProcessor) Process(conn services.ConnectionInterface, uaaHost string, job gobble.Job, logger lager.Logger) error {
 	var campaignJob queue.CampaignJob
 
@@ -78,73 +115,344 @@ func (p CampaignJobProcessor) Process(conn services.ConnectionInterface, uaaHost
 	if err != nil {
 		return err
 	}
+	campaign := campaignJob.Campaign
+
+	// Saved audiences are only expanded for the sender that saved them.
+	p.generators = p.generators.ForSender(campaign.SenderID)
 
-	doctype, head, bodyContent, bodyAttributes, err := p.htmlExtractor.Extract(campaignJob.Campaign.HTML)
+	common.RecordTiming("notifications.queue.wait", "2", campaign.ClientID, campaign.CampaignTypeID, job.QueueWait)
+
+	doctype, head, bodyContent, bodyAttributes, err := p.htmlExtractor.Extract(campaign.HTML)
 	if err != nil {
 		return err
 	}
 
-	var audiences []horde.Audience
-	for audienceName, audienceMembers := range campaignJob.Campaign.SendTo {
-		generator, err := p.findAudienceGenerator(audienceName)
+	options := queue.Options{
+		ReplyTo: campaign.ReplyTo,
+		Subject: campaign.Subject,
+		Text:    campaign.Text,
+		HTML: queue.HTML{
+			Doctype:        doctype,
+			Head:           head,
+			BodyContent:    bodyContent,
+			BodyAttributes: bodyAttributes,
+		},
+		TemplateID: campaign.TemplateID,
+	}
+
+	checkpoint, err := p.campaigns.Get(conn, campaign.ID)
+	if err != nil {
+		if _, ok := err.(models.RecordNotFoundError); !ok {
+			return err
+		}
+	}
+
+	fanoutStarted := p.clock.Now()
+	if checkpoint.FanoutStartedAt.Valid {
+		fanoutStarted = checkpoint.FanoutStartedAt.Time
+	}
+
+	err = p.campaigns.StartFanout(conn, campaign.ID, fanoutStarted)
+	if err != nil {
+		return err
+	}
+
+	intersect := len(campaign.Intersect) > 0
+	filtered := intersect || len(campaign.Exclude) > 0
+	if filtered && checkpoint.FanoutProgress == 0 {
+		err = p.storeFilters(conn, campaign, logger)
 		if err != nil {
 			return err
 		}
+	}
 
-		aud, err := generator.GenerateAudiences(audienceMembers, logger)
+	members := audienceMembers(campaign.SendTo)
+	for index := checkpoint.FanoutProgress; index < len(members); index++ {
+		member := members[index]
+
+		users, err := p.generateUsers(map[string][]string{member.audience: member.inputs}, logger)
 		if err != nil {
 			return err
 		}
 
-		audiences = append(audiences, aud...)
-	}
+		userKeys := sortedKeys(users)
+		for start := 0; start < len(userKeys); start += fanoutBatchSize {
+			end := start + fanoutBatchSize
+			if end > len(userKeys) {
+				end = len(userKeys)
+			}
 
-	users := map[string]queue.User{}
-	for _, audience := range audiences {
-		for _, user := range audience.Users {
-			users[key(user)] = queue.User{
-				GUID:        user.GUID,
-				Email:       user.Email,
-				Endorsement: audience.Endorsement,
+			keys := userKeys[start:end]
+			if filtered {
+				keys, err = p.filter(conn, campaign.ID, intersect, keys)
+				if err != nil {
+					return err
+				}
+			}
+
+			var batch []queue.User
+			for _, userKey := range keys {
+				batch = append(batch, users[userKey])
+			}
+
+			if len(batch) > 0 {
+				err = p.enqueue(conn, batch, options, campaign, uaaHost, logger)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		if filtered {
+			err = p.campaigns.UpdateExcludedCount(conn, campaign.ID)
+			if err != nil {
+				return err
 			}
 		}
+
+		err = p.campaigns.SetFanoutProgress(conn, campaign.ID, index+1)
+		if err != nil {
+			return err
+		}
 	}
 
-	usersSlice := []queue.User{}
-	for _, v := range users {
-		usersSlice = append(usersSlice, v)
+	if !checkpoint.FanoutCompletedAt.Valid {
+		fanoutCompleted := p.clock.Now()
+		err = p.campaigns.CompleteFanout(conn, campaign.ID, fanoutCompleted)
+		if err != nil {
+			return err
+		}
+
+		common.RecordTiming("notifications.campaign.fanout", "2", campaign.ClientID, campaign.CampaignTypeID, fanoutCompleted.Sub(fanoutStarted))
 	}
 
-	options := queue.Options{
-		ReplyTo: campaignJob.Campaign.ReplyTo,
-		Subject: campaignJob.Campaign.Subject,
-		Text:    campaignJob.Campaign.Text,
-		HTML: queue.HTML{
-			Doctype:        doctype,
-			Head:           head,
-			BodyContent:    bodyContent,
-			BodyAttributes: bodyAttributes,
-		},
-		TemplateID: campaignJob.Campaign.TemplateID,
+	return nil
+}
+
+// storeFilters expands the intersect and exclude blocks of the campaign and
+// stores their recipients in bounded batches.
+func (p CampaignJobProcessor) storeFilters(conn services.ConnectionInterface, campaign collections.Campaign, logger lager.Logger) error {
+	blocks := []struct {
+		kind   string
+		sendTo map[string][]string
+	}{
+		{models.CampaignFilterIntersect, campaign.Intersect},
+		{models.CampaignFilterExclude, campaign.Exclude},
+	}
+
+	for _, block := range blocks {
+		if len(block.sendTo) == 0 {
+			continue
+		}
+
+		keys, err := p.generators.Keys(block.sendTo, logger)
+		if err != nil {
+			return audienceError(err)
+		}
+
+		for start := 0; start < len(keys); start += fanoutBatchSize {
+			end := start + fanoutBatchSize
+			if end > len(keys) {
+				end = len(keys)
+			}
+
+			err = p.filters.Insert(conn, campaign.ID, block.kind, keys[start:end])
+			if err != nil {
+				return err
+			}
+		}
 	}
 
-	p.enqueuer.Enqueue(conn, usersSlice, options, cf.CloudControllerSpace{},
-		cf.CloudControllerOrganization{}, campaignJob.Campaign.ClientID,
-		uaaHost, "", "", time.Time{}, campaignJob.Campaign.ID)
 	return nil
 }
 
-func (p CampaignJobProcessor) findAudienceGenerator(audience string) (audienceGenerator, error) {
-	switch audience {
-	case "users":
-		return p.users, nil
-	case "spaces":
-		return p.spaces, nil
-	case "orgs":
-		return p.orgs, nil
-	case "emails":
-		return p.emails, nil
-	default:
-		return nil, NoAudienceError{fmt.Errorf("generator for %q audience could not be found", audience)}
+// filter returns the recipients left once the stored blocks are applied and
+// records the ones it leaves out.
+func (p CampaignJobProcessor) filter(conn services.ConnectionInterface, campaignID string, intersect bool, keys []string) ([]string, error) {
+	stored, err := p.filters.List(conn, campaignID, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	var intersection, exclusions []string
+	for _, entry := range stored {
+		switch entry.Kind {
+		case models.CampaignFilterIntersect:
+			intersection = append(intersection, entry.Recipient)
+		case models.CampaignFilterExclude:
+			exclusions = append(exclusions, entry.Recipient)
+		}
+	}
+
+	filter := horde.NewFilter(intersect, intersection, exclusions)
+
+	var kept, excluded []string
+	for _, key := range keys {
+		if filter.Excludes(key) {
+			excluded = append(excluded, key)
+			continue
+		}
+
+		kept = append(kept, key)
+	}
+
+	err = p.filters.RecordExcluded(conn, campaignID, excluded)
+	if err != nil {
+		return nil, err
+	}
+
+	return kept, nil
+}
+
+// enqueue skips the users that an earlier attempt already queued before
+// resolving the addresses of the rest, so a resumed fan-out does not look
+// them up again. The enqueuer still skips queued users within its
+// transaction.
+func (p CampaignJobProcessor) enqueue(conn services.ConnectionInterface, users []queue.User, options queue.Options, campaign collections.Campaign, uaaHost string, logger lager.Logger) error {
+	var keys []string
+	for _, user := range users {
+		keys = append(keys, horde.Key(horde.User{GUID: user.GUID, Email: user.Email}))
+	}
+
+	existing, err := p.recipients.ListExisting(conn, campaign.ID, keys)
+	if err != nil {
+		return err
+	}
+
+	if len(existing) > 0 {
+		queued := map[string]bool{}
+		for _, recipient := range existing {
+			queued[recipient] = true
+		}
+
+		var unqueued []queue.User
+		for i, user := range users {
+			if !queued[keys[i]] {
+				unqueued = append(unqueued, user)
+			}
+		}
+		users = unqueued
+	}
+
+	if len(users) == 0 {
+		return nil
+	}
+
+	p.resolveEmails(users, uaaHost, logger)
+
+	return p.enqueuer.Enqueue(conn, users, options, cf.CloudControllerSpace{},
+		cf.CloudControllerOrganization{}, campaign.ClientID,
+		uaaHost, "", "", campaign.RequestReceived, campaign.ID)
+}
+
+// resolveEmails looks up the addresses of the users in batches so that the
+// delivery jobs do not each have to ask UAA for one user. Failing to resolve
+// an address is not fatal: the delivery looks the user up itself instead.
+func (p CampaignJobProcessor) resolveEmails(users []queue.User, uaaHost string, logger lager.Logger) {
+	var guids []string
+	for _, user := range users {
+		if user.GUID != "" && user.Email == "" {
+			guids = append(guids, user.GUID)
+		}
+	}
+
+	if len(guids) == 0 {
+		return
+	}
+
+	token, err := p.tokenLoader.Load(uaaHost)
+	if err != nil {
+		logger.Error("email-resolution-failed", err)
+		return
 	}
+
+	resolvedAt := p.clock.Now()
+	emails := map[string]string{}
+	for start := 0; start < len(guids); start += emailResolutionBatchSize {
+		end := start + emailResolutionBatchSize
+		if end > len(guids) {
+			end = len(guids)
+		}
+
+		loaded, err := p.userLoader.Load(guids[start:end], token)
+		if err != nil {
+			logger.Error("email-resolution-failed", err)
+			continue
+		}
+
+		for guid, user := range loaded {
+			if len(user.Emails) > 0 {
+				emails[guid] = user.Emails[0]
+			}
+		}
+	}
+
+	for i, user := range users {
+		if email, ok := emails[user.GUID]; ok && user.Email == "" {
+			users[i].ResolvedEmail = email
+			users[i].EmailResolvedAt = resolvedAt
+		}
+	}
+}
+
+func (p CampaignJobProcessor) generateUsers(sendTo map[string][]string, logger lager.Logger) (map[string]queue.User, error) {
+	recipients, err := p.generators.Recipients(sendTo, logger)
+	if err != nil {
+		return nil, audienceError(err)
+	}
+
+	users := map[string]queue.User{}
+	for userKey, recipient := range recipients {
+		users[userKey] = queue.User{
+			GUID:        recipient.User.GUID,
+			Email:       recipient.User.Email,
+			Endorsement: recipient.Endorsement,
+		}
+	}
+
+	return users, nil
+}
+
+func audienceError(err error) error {
+	if _, ok := err.(horde.UnknownAudienceError); ok {
+		return NoAudienceError{err}
+	}
+
+	return err
+}
+
+// audienceMembers lists every member of every audience in a stable order so
+// that the fan-out checkpoint refers to the same member on every attempt.
+// Audiences without members, such as everyone, count as a single member.
+func audienceMembers(sendTo map[string][]string) []audienceMember {
+	var audiences []string
+	for audience := range sendTo {
+		audiences = append(audiences, audience)
+	}
+	sort.Strings(audiences)
+
+	var members []audienceMember
+	for _, audience := range audiences {
+		inputs := sendTo[audience]
+		if len(inputs) == 0 {
+			members = append(members, audienceMember{audience: audience, inputs: inputs})
+			continue
+		}
+
+		for _, input := range inputs {
+			members = append(members, audienceMember{audience: audience, inputs: []string{input}})
+		}
+	}
+
+	return members
+}
+
+func sortedKeys(users map[string]queue.User) []string {
+	var keys []string
+	for userKey := range users {
+		keys = append(keys, userKey)
+	}
+	sort.Strings(keys)
+
+	return keys
 }
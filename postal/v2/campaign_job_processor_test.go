@@ -3,16 +3,20 @@ package v2_test
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/cloudfoundry-incubator/notifications/cf"
 	"github.com/cloudfoundry-incubator/notifications/gobble"
 	"github.com/cloudfoundry-incubator/notifications/postal/v2"
 	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/uaa"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/notify"
 	"github.com/cloudfoundry-incubator/notifications/v2/collections"
 	"github.com/cloudfoundry-incubator/notifications/v2/horde"
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
 	"github.com/cloudfoundry-incubator/notifications/v2/queue"
+	"github.com/go-sql-driver/mysql"
 	"github.com/pivotal-golang/lager"
 
 	. "github.com/onsi/ginkgo"
@@ -25,7 +29,22 @@ var _ = Describe("CampaignJobProcessor", func() {
 		database                    *mocks.Database
 		connection                  *mocks.Connection
 		enqueuer                    *mocks.V2Enqueuer
+		campaignsRepository         *mocks.CampaignsRepository
+		filtersRepository           *mocks.CampaignFiltersRepository
+		recipientsRepository        *mocks.CampaignRecipientsRepository
 		users, orgs, emails, spaces *mocks.Audiences
+		uaaScopes, uaaGroups        *mocks.Audiences
+		everyone                    *mocks.Audiences
+		orgManagers, orgAuditors    *mocks.Audiences
+		billingManagers             *mocks.Audiences
+		spaceManagers               *mocks.Audiences
+		spaceDevelopers             *mocks.Audiences
+		spaceAuditors               *mocks.Audiences
+		savedAudiences              *mocks.Audiences
+		generators                  horde.Generators
+		tokenLoader                 *mocks.TokenLoader
+		userLoader                  *mocks.UserLoader
+		clock                       *mocks.Clock
 		buffer                      *bytes.Buffer
 		logger                      lager.Logger
 	)
@@ -36,12 +55,45 @@ var _ = Describe("CampaignJobProcessor", func() {
 		database.ConnectionCall.Returns.Connection = connection
 
 		enqueuer = mocks.NewV2Enqueuer()
+		campaignsRepository = mocks.NewCampaignsRepository()
+		filtersRepository = mocks.NewCampaignFiltersRepository()
+		recipientsRepository = mocks.NewCampaignRecipientsRepository()
 		emails = mocks.NewAudiences()
 		spaces = mocks.NewAudiences()
 		orgs = mocks.NewAudiences()
 		users = mocks.NewAudiences()
+		uaaScopes = mocks.NewAudiences()
+		uaaGroups = mocks.NewAudiences()
+		everyone = mocks.NewAudiences()
+		orgManagers = mocks.NewAudiences()
+		orgAuditors = mocks.NewAudiences()
+		billingManagers = mocks.NewAudiences()
+		spaceManagers = mocks.NewAudiences()
+		spaceDevelopers = mocks.NewAudiences()
+		spaceAuditors = mocks.NewAudiences()
+		savedAudiences = mocks.NewAudiences()
+		generators = horde.Generators{
+			"emails":           emails,
+			"spaces":           spaces,
+			"orgs":             orgs,
+			"users":            users,
+			"uaa_scopes":       uaaScopes,
+			"groups":           uaaGroups,
+			"everyone":         everyone,
+			"org_managers":     orgManagers,
+			"org_auditors":     orgAuditors,
+			"billing_managers": billingManagers,
+			"space_managers":   spaceManagers,
+			"space_developers": spaceDevelopers,
+			"space_auditors":   spaceAuditors,
+			"audiences":        savedAudiences,
+		}
+		tokenLoader = mocks.NewTokenLoader()
+		userLoader = mocks.NewUserLoader()
+		clock = mocks.NewClock()
 		processor = v2.NewCampaignJobProcessor(notify.EmailFormatter{},
-			notify.HTMLExtractor{}, emails, spaces, orgs, users, enqueuer)
+			notify.HTMLExtractor{}, generators, campaignsRepository, filtersRepository, recipientsRepository, enqueuer,
+			tokenLoader, userLoader, clock)
 		buffer = bytes.NewBuffer([]byte{})
 		logger = lager.NewLogger("notifications")
 		logger.RegisterSink(lager.NewWriterSink(buffer, lager.DEBUG))
@@ -76,10 +128,8 @@ var _ = Describe("CampaignJobProcessor", func() {
 			}), logger)
 			Expect(err).NotTo(HaveOccurred())
 
-			Expect(users.GenerateAudiencesCall.Receives.Inputs).To(Equal([]string{
-				"some-user-guid",
-				"some-other-user-guid",
-			}))
+			Expect(users.GenerateAudiencesCall.CallCount).To(Equal(2))
+			Expect(users.GenerateAudiencesCall.Receives.Inputs).To(Equal([]string{"some-other-user-guid"}))
 
 			Expect(enqueuer.EnqueueCall.Receives.Connection).To(Equal(connection))
 			Expect(enqueuer.EnqueueCall.Receives.Users).To(ConsistOf([]queue.User{
@@ -149,11 +199,8 @@ var _ = Describe("CampaignJobProcessor", func() {
 			}), logger)
 			Expect(err).NotTo(HaveOccurred())
 
-			Expect(emails.GenerateAudiencesCall.Receives.Inputs).To(Equal([]string{
-				"some-user@example.com",
-				"some-other-user@example.com",
-				"some-user@example.com",
-			}))
+			Expect(emails.GenerateAudiencesCall.CallCount).To(Equal(3))
+			Expect(emails.GenerateAudiencesCall.Receives.Inputs).To(Equal([]string{"some-user@example.com"}))
 
 			Expect(enqueuer.EnqueueCall.Receives.Connection).To(Equal(connection))
 			Expect(enqueuer.EnqueueCall.Receives.Users).To(ConsistOf([]queue.User{
@@ -223,10 +270,8 @@ var _ = Describe("CampaignJobProcessor", func() {
 			}), logger)
 			Expect(err).NotTo(HaveOccurred())
 
-			Expect(spaces.GenerateAudiencesCall.Receives.Inputs).To(Equal([]string{
-				"some-space-guid",
-				"some-other-space-guid",
-			}))
+			Expect(spaces.GenerateAudiencesCall.CallCount).To(Equal(2))
+			Expect(spaces.GenerateAudiencesCall.Receives.Inputs).To(Equal([]string{"some-other-space-guid"}))
 			Expect(spaces.GenerateAudiencesCall.Receives.Logger).To(Equal(logger))
 
 			Expect(enqueuer.EnqueueCall.Receives.Connection).To(Equal(connection))
@@ -297,10 +342,8 @@ var _ = Describe("CampaignJobProcessor", func() {
 			}), logger)
 			Expect(err).NotTo(HaveOccurred())
 
-			Expect(orgs.GenerateAudiencesCall.Receives.Inputs).To(Equal([]string{
-				"some-org-guid",
-				"some-other-org-guid",
-			}))
+			Expect(orgs.GenerateAudiencesCall.CallCount).To(Equal(2))
+			Expect(orgs.GenerateAudiencesCall.Receives.Inputs).To(Equal([]string{"some-other-org-guid"}))
 
 			Expect(enqueuer.EnqueueCall.Receives.Connection).To(Equal(connection))
 			Expect(enqueuer.EnqueueCall.Receives.Users).To(ConsistOf([]queue.User{
@@ -336,6 +379,232 @@ var _ = Describe("CampaignJobProcessor", func() {
 		})
 	})
 
+	Context("when the audience is uaa scopes, uaa groups, everyone, an organization or space role, or a saved audience", func() {
+		It("finds the generator for each audience", func() {
+			generators := map[string]*mocks.Audiences{
+				"uaa_scopes":       uaaScopes,
+				"groups":           uaaGroups,
+				"everyone":         everyone,
+				"org_managers":     orgManagers,
+				"org_auditors":     orgAuditors,
+				"billing_managers": billingManagers,
+				"space_managers":   spaceManagers,
+				"space_developers": spaceDevelopers,
+				"space_auditors":   spaceAuditors,
+				"audiences":        savedAudiences,
+			}
+
+			for audienceName, generator := range generators {
+				generator.GenerateAudiencesCall.Returns.Audiences = []horde.Audience{
+					{
+						Users:       []horde.User{{GUID: "some-user-guid-for-" + audienceName}},
+						Endorsement: "some endorsement",
+					},
+				}
+
+				err := processor.Process(database.Connection(), "some-uaa-host", *gobble.NewJob(queue.CampaignJob{
+					Campaign: collections.Campaign{
+						ID:       "some-id",
+						SendTo:   map[string][]string{audienceName: {"some-input"}},
+						HTML:     "<h1>my-html</h1>",
+						ClientID: "some-client-id",
+					},
+				}), logger)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(generator.GenerateAudiencesCall.Receives.Inputs).To(Equal([]string{"some-input"}))
+				Expect(enqueuer.EnqueueCall.Receives.Users).To(Equal([]queue.User{
+					{GUID: "some-user-guid-for-" + audienceName, Endorsement: "some endorsement"},
+				}))
+			}
+		})
+	})
+
+	Context("when the campaign excludes audiences", func() {
+		BeforeEach(func() {
+			orgs.GenerateAudiencesCall.Returns.Audiences = []horde.Audience{
+				{
+					Users: []horde.User{
+						{GUID: "some-user-guid"},
+						{GUID: "some-ops-user-guid"},
+						{GUID: "some-other-ops-user-guid"},
+					},
+					Endorsement: "some endorsement",
+				},
+			}
+			users.GenerateAudiencesCall.Returns.Audiences = []horde.Audience{
+				{
+					Users: []horde.User{
+						{GUID: "some-ops-user-guid"},
+						{GUID: "some-other-ops-user-guid"},
+						{GUID: "some-user-outside-the-org"},
+					},
+					Endorsement: "some other endorsement",
+				},
+			}
+			filtersRepository.ListCall.Returns.Filters = []models.CampaignFilter{
+				{CampaignID: "some-id", Kind: "exclude", Recipient: "some-ops-user-guid"},
+				{CampaignID: "some-id", Kind: "exclude", Recipient: "some-other-ops-user-guid"},
+			}
+		})
+
+		It("stores the excluded users, subtracts them and records how many were excluded", func() {
+			err := processor.Process(database.Connection(), "some-uaa-host", *gobble.NewJob(queue.CampaignJob{
+				Campaign: collections.Campaign{
+					ID:      "some-id",
+					SendTo:  map[string][]string{"orgs": {"some-org-guid"}},
+					Exclude: map[string][]string{"users": {"some-ops-user-guid", "some-other-ops-user-guid", "some-user-outside-the-org"}},
+					HTML:    "<h1>my-html</h1>",
+				},
+			}), logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(users.GenerateAudiencesCall.Receives.Inputs).To(Equal([]string{"some-ops-user-guid", "some-other-ops-user-guid", "some-user-outside-the-org"}))
+			Expect(filtersRepository.InsertCall.Receives.Connection).To(Equal(connection))
+			Expect(filtersRepository.InsertCall.Receives.Filters).To(Equal([]models.CampaignFilter{
+				{CampaignID: "some-id", Kind: "exclude", Recipient: "some-ops-user-guid"},
+				{CampaignID: "some-id", Kind: "exclude", Recipient: "some-other-ops-user-guid"},
+				{CampaignID: "some-id", Kind: "exclude", Recipient: "some-user-outside-the-org"},
+			}))
+
+			Expect(filtersRepository.ListCall.Receives.Connection).To(Equal(connection))
+			Expect(filtersRepository.ListCall.Receives.CampaignID).To(Equal("some-id"))
+			Expect(filtersRepository.ListCall.Receives.Recipients).To(Equal([]string{"some-ops-user-guid", "some-other-ops-user-guid", "some-user-guid"}))
+
+			Expect(enqueuer.EnqueueCall.Receives.Users).To(Equal([]queue.User{
+				{GUID: "some-user-guid", Endorsement: "some endorsement"},
+			}))
+
+			Expect(filtersRepository.RecordExcludedCall.Receives.Connection).To(Equal(connection))
+			Expect(filtersRepository.RecordExcludedCall.Receives.CampaignID).To(Equal("some-id"))
+			Expect(filtersRepository.RecordExcludedCall.Receives.Recipients).To(Equal([]string{"some-ops-user-guid", "some-other-ops-user-guid"}))
+
+			Expect(campaignsRepository.UpdateExcludedCountCall.Receives.Connection).To(Equal(connection))
+			Expect(campaignsRepository.UpdateExcludedCountCall.Receives.CampaignID).To(Equal("some-id"))
+		})
+
+		Context("when the excluded users cannot be stored", func() {
+			It("returns the error", func() {
+				filtersRepository.InsertCall.Returns.Error = errors.New("some database error")
+
+				err := processor.Process(database.Connection(), "some-uaa-host", *gobble.NewJob(queue.CampaignJob{
+					Campaign: collections.Campaign{
+						ID:      "some-id",
+						SendTo:  map[string][]string{"orgs": {"some-org-guid"}},
+						Exclude: map[string][]string{"users": {"some-ops-user-guid"}},
+					},
+				}), logger)
+				Expect(err).To(MatchError(errors.New("some database error")))
+				Expect(enqueuer.EnqueueCall.CallCount).To(Equal(0))
+			})
+		})
+
+		Context("when the stored filters cannot be read", func() {
+			It("returns the error", func() {
+				filtersRepository.ListCall.Returns.Error = errors.New("some database error")
+
+				err := processor.Process(database.Connection(), "some-uaa-host", *gobble.NewJob(queue.CampaignJob{
+					Campaign: collections.Campaign{
+						ID:      "some-id",
+						SendTo:  map[string][]string{"orgs": {"some-org-guid"}},
+						Exclude: map[string][]string{"users": {"some-ops-user-guid"}},
+					},
+				}), logger)
+				Expect(err).To(MatchError(errors.New("some database error")))
+			})
+		})
+
+		Context("when the excluded users cannot be recorded", func() {
+			It("returns the error", func() {
+				filtersRepository.RecordExcludedCall.Returns.Error = errors.New("some database error")
+
+				err := processor.Process(database.Connection(), "some-uaa-host", *gobble.NewJob(queue.CampaignJob{
+					Campaign: collections.Campaign{
+						ID:      "some-id",
+						SendTo:  map[string][]string{"orgs": {"some-org-guid"}},
+						Exclude: map[string][]string{"users": {"some-ops-user-guid"}},
+					},
+				}), logger)
+				Expect(err).To(MatchError(errors.New("some database error")))
+			})
+		})
+
+		Context("when recording the excluded count fails", func() {
+			It("returns the error", func() {
+				campaignsRepository.UpdateExcludedCountCall.Returns.Error = errors.New("some database error")
+
+				err := processor.Process(database.Connection(), "some-uaa-host", *gobble.NewJob(queue.CampaignJob{
+					Campaign: collections.Campaign{
+						ID:      "some-id",
+						SendTo:  map[string][]string{"orgs": {"some-org-guid"}},
+						Exclude: map[string][]string{"users": {"some-ops-user-guid"}},
+					},
+				}), logger)
+				Expect(err).To(MatchError(errors.New("some database error")))
+			})
+		})
+	})
+
+	Context("when the campaign intersects audiences", func() {
+		It("keeps only the users who are also in the intersected audiences", func() {
+			orgs.GenerateAudiencesCall.Returns.Audiences = []horde.Audience{
+				{
+					Users: []horde.User{
+						{GUID: "some-user-guid"},
+						{GUID: "some-other-user-guid"},
+						{GUID: "some-ops-user-guid"},
+					},
+					Endorsement: "some endorsement",
+				},
+			}
+			spaces.GenerateAudiencesCall.Returns.Audiences = []horde.Audience{
+				{
+					Users: []horde.User{
+						{GUID: "some-user-guid"},
+						{GUID: "some-ops-user-guid"},
+					},
+					Endorsement: "some space endorsement",
+				},
+			}
+			users.GenerateAudiencesCall.Returns.Audiences = []horde.Audience{
+				{
+					Users:       []horde.User{{GUID: "some-ops-user-guid"}},
+					Endorsement: "some other endorsement",
+				},
+			}
+			filtersRepository.ListCall.Returns.Filters = []models.CampaignFilter{
+				{CampaignID: "some-id", Kind: "intersect", Recipient: "some-ops-user-guid"},
+				{CampaignID: "some-id", Kind: "intersect", Recipient: "some-user-guid"},
+				{CampaignID: "some-id", Kind: "exclude", Recipient: "some-ops-user-guid"},
+			}
+
+			err := processor.Process(database.Connection(), "some-uaa-host", *gobble.NewJob(queue.CampaignJob{
+				Campaign: collections.Campaign{
+					ID:        "some-id",
+					SendTo:    map[string][]string{"orgs": {"some-org-guid"}},
+					Intersect: map[string][]string{"spaces": {"some-space-guid"}},
+					Exclude:   map[string][]string{"users": {"some-ops-user-guid"}},
+					HTML:      "<h1>my-html</h1>",
+				},
+			}), logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(spaces.GenerateAudiencesCall.Receives.Inputs).To(Equal([]string{"some-space-guid"}))
+			Expect(filtersRepository.InsertCall.Receives.Filters).To(Equal([]models.CampaignFilter{
+				{CampaignID: "some-id", Kind: "intersect", Recipient: "some-ops-user-guid"},
+				{CampaignID: "some-id", Kind: "intersect", Recipient: "some-user-guid"},
+				{CampaignID: "some-id", Kind: "exclude", Recipient: "some-ops-user-guid"},
+			}))
+
+			Expect(enqueuer.EnqueueCall.Receives.Users).To(Equal([]queue.User{
+				{GUID: "some-user-guid", Endorsement: "some endorsement"},
+			}))
+
+			Expect(filtersRepository.RecordExcludedCall.Receives.Recipients).To(Equal([]string{"some-ops-user-guid", "some-other-user-guid"}))
+			Expect(campaignsRepository.UpdateExcludedCountCall.CallCount).To(Equal(1))
+		})
+	})
+
 	Context("when there are multiple audience types", func() {
 		BeforeEach(func() {
 			orgs.GenerateAudiencesCall.Returns.Audiences = []horde.Audience{
@@ -410,25 +679,14 @@ var _ = Describe("CampaignJobProcessor", func() {
 			}), logger)
 			Expect(err).NotTo(HaveOccurred())
 
-			Expect(orgs.GenerateAudiencesCall.Receives.Inputs).To(Equal([]string{
-				"some-org-guid",
-				"some-other-org-guid",
-			}))
-			Expect(spaces.GenerateAudiencesCall.Receives.Inputs).To(Equal([]string{
-				"some-space-guid",
-				"some-other-space-guid",
-			}))
-			Expect(users.GenerateAudiencesCall.Receives.Inputs).To(Equal([]string{
-				"some-user-guid",
-				"some-other-user-guid",
-			}))
-			Expect(emails.GenerateAudiencesCall.Receives.Inputs).To(Equal([]string{
-				"some-user@example.com",
-				"some-other-user@example.com",
-			}))
+			Expect(orgs.GenerateAudiencesCall.CallCount).To(Equal(2))
+			Expect(spaces.GenerateAudiencesCall.CallCount).To(Equal(2))
+			Expect(users.GenerateAudiencesCall.CallCount).To(Equal(2))
+			Expect(emails.GenerateAudiencesCall.CallCount).To(Equal(2))
 
+			Expect(enqueuer.EnqueueCall.CallCount).To(Equal(8))
 			Expect(enqueuer.EnqueueCall.Receives.Connection).To(Equal(connection))
-			Expect(enqueuer.EnqueueCall.Receives.Users).To(ConsistOf([]queue.User{
+			for _, user := range []queue.User{
 				{GUID: "some-user-guid-for-org", Endorsement: "some-org endorsement"},
 				{GUID: "some-other-user-guid-for-org", Endorsement: "some-other-org endorsement"},
 				{GUID: "some-user-guid-for-space", Endorsement: "some-space endorsement"},
@@ -437,7 +695,9 @@ var _ = Describe("CampaignJobProcessor", func() {
 				{GUID: "some-other-user-guid", Endorsement: "some users endorsement"},
 				{Email: "some-user@example.com", Endorsement: "some emails endorsement"},
 				{Email: "some-other-user@example.com", Endorsement: "some emails endorsement"},
-			}))
+			} {
+				Expect(enqueuer.EnqueueCall.EnqueuedUsers).To(ContainElement(user))
+			}
 			Expect(enqueuer.EnqueueCall.Receives.Options).To(Equal(queue.Options{
 				ReplyTo: "noreply@example.com",
 				Subject: "The Best subject",
@@ -454,6 +714,360 @@ var _ = Describe("CampaignJobProcessor", func() {
 		})
 	})
 
+	Context("when fanning out a large audience", func() {
+		BeforeEach(func() {
+			var audienceUsers []horde.User
+			for i := 0; i < 1200; i++ {
+				audienceUsers = append(audienceUsers, horde.User{GUID: fmt.Sprintf("user-%04d", i)})
+			}
+
+			orgs.GenerateAudiencesCall.Returns.Audiences = []horde.Audience{
+				{Users: audienceUsers, Endorsement: "some endorsement"},
+			}
+		})
+
+		It("enqueues the recipients in bounded batches", func() {
+			err := processor.Process(database.Connection(), "some-uaa-host", *gobble.NewJob(queue.CampaignJob{
+				Campaign: collections.Campaign{
+					ID:     "some-id",
+					SendTo: map[string][]string{"orgs": {"some-org-guid"}},
+				},
+			}), logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(enqueuer.EnqueueCall.CallCount).To(Equal(3))
+			Expect(enqueuer.EnqueueCall.Receives.Users).To(HaveLen(200))
+			Expect(enqueuer.EnqueueCall.EnqueuedUsers).To(HaveLen(1200))
+		})
+	})
+
+	Context("when resolving recipient email addresses", func() {
+		var resolvedAt time.Time
+
+		BeforeEach(func() {
+			resolvedAt = time.Date(2015, 1, 1, 12, 0, 0, 0, time.UTC)
+			clock.NowCall.Returns.Time = resolvedAt
+			tokenLoader.LoadCall.Returns.Token = "some-token"
+
+			var audienceUsers []horde.User
+			loadedUsers := map[string]uaa.User{}
+			for i := 0; i < 600; i++ {
+				guid := fmt.Sprintf("user-%04d", i)
+				audienceUsers = append(audienceUsers, horde.User{GUID: guid})
+				loadedUsers[guid] = uaa.User{ID: guid, Emails: []string{guid + "@example.com"}}
+			}
+			loadedUsers["user-0599"] = uaa.User{ID: "user-0599"}
+
+			orgs.GenerateAudiencesCall.Returns.Audiences = []horde.Audience{
+				{Users: audienceUsers, Endorsement: "some endorsement"},
+			}
+			userLoader.LoadCall.Returns.Users = loadedUsers
+		})
+
+		It("looks the users up in batches and queues their addresses", func() {
+			err := processor.Process(database.Connection(), "some-uaa-host", *gobble.NewJob(queue.CampaignJob{
+				Campaign: collections.Campaign{
+					ID:     "some-id",
+					SendTo: map[string][]string{"orgs": {"some-org-guid"}},
+				},
+			}), logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(tokenLoader.LoadCall.Receives.UAAHost).To(Equal("some-uaa-host"))
+			Expect(userLoader.LoadCall.CallCount).To(Equal(3))
+			Expect(userLoader.LoadCall.Receives.UserGUIDs).To(HaveLen(100))
+			Expect(userLoader.LoadCall.Receives.Token).To(Equal("some-token"))
+
+			enqueued := enqueuer.EnqueueCall.EnqueuedUsers
+			Expect(enqueued).To(HaveLen(600))
+			Expect(enqueued[0]).To(Equal(queue.User{
+				GUID:            "user-0000",
+				Endorsement:     "some endorsement",
+				ResolvedEmail:   "user-0000@example.com",
+				EmailResolvedAt: resolvedAt,
+			}))
+			Expect(enqueued[599]).To(Equal(queue.User{
+				GUID:        "user-0599",
+				Endorsement: "some endorsement",
+			}))
+		})
+
+		It("does not look up users that are addressed by email", func() {
+			emails.GenerateAudiencesCall.Returns.Audiences = []horde.Audience{
+				{Users: []horde.User{{Email: "someone@example.com"}}},
+			}
+
+			err := processor.Process(database.Connection(), "some-uaa-host", *gobble.NewJob(queue.CampaignJob{
+				Campaign: collections.Campaign{
+					ID:     "some-id",
+					SendTo: map[string][]string{"emails": {"someone@example.com"}},
+				},
+			}), logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(userLoader.LoadCall.CallCount).To(Equal(0))
+			Expect(enqueuer.EnqueueCall.Receives.Users).To(Equal([]queue.User{
+				{Email: "someone@example.com"},
+			}))
+		})
+
+		It("does not look up users that an earlier attempt already queued", func() {
+			var existing []string
+			for i := 0; i < 500; i++ {
+				existing = append(existing, fmt.Sprintf("user-%04d", i))
+			}
+			recipientsRepository.ListExistingCall.Returns.Existing = existing
+
+			err := processor.Process(database.Connection(), "some-uaa-host", *gobble.NewJob(queue.CampaignJob{
+				Campaign: collections.Campaign{
+					ID:     "some-id",
+					SendTo: map[string][]string{"orgs": {"some-org-guid"}},
+				},
+			}), logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(recipientsRepository.ListExistingCall.Receives.CampaignID).To(Equal("some-id"))
+			Expect(userLoader.LoadCall.CallCount).To(Equal(1))
+			Expect(userLoader.LoadCall.Receives.UserGUIDs).To(HaveLen(100))
+			Expect(userLoader.LoadCall.Receives.UserGUIDs[0]).To(Equal("user-0500"))
+
+			Expect(enqueuer.EnqueueCall.CallCount).To(Equal(1))
+			Expect(enqueuer.EnqueueCall.EnqueuedUsers).To(HaveLen(100))
+		})
+
+		Context("when the queued recipients cannot be listed", func() {
+			It("returns the error without looking anyone up", func() {
+				recipientsRepository.ListExistingCall.Returns.Error = errors.New("some database error")
+
+				err := processor.Process(database.Connection(), "some-uaa-host", *gobble.NewJob(queue.CampaignJob{
+					Campaign: collections.Campaign{
+						ID:     "some-id",
+						SendTo: map[string][]string{"orgs": {"some-org-guid"}},
+					},
+				}), logger)
+				Expect(err).To(MatchError(errors.New("some database error")))
+
+				Expect(userLoader.LoadCall.CallCount).To(Equal(0))
+				Expect(enqueuer.EnqueueCall.CallCount).To(Equal(0))
+			})
+		})
+
+		Context("when the users cannot be looked up", func() {
+			It("enqueues them without an address", func() {
+				userLoader.LoadCall.Returns.Error = errors.New("uaa is down")
+
+				err := processor.Process(database.Connection(), "some-uaa-host", *gobble.NewJob(queue.CampaignJob{
+					Campaign: collections.Campaign{
+						ID:     "some-id",
+						SendTo: map[string][]string{"orgs": {"some-org-guid"}},
+					},
+				}), logger)
+				Expect(err).NotTo(HaveOccurred())
+
+				enqueued := enqueuer.EnqueueCall.EnqueuedUsers
+				Expect(enqueued).To(HaveLen(600))
+				Expect(enqueued[0].ResolvedEmail).To(BeEmpty())
+				Expect(buffer.String()).To(ContainSubstring("email-resolution-failed"))
+			})
+		})
+
+		Context("when the token cannot be loaded", func() {
+			It("enqueues them without an address", func() {
+				tokenLoader.LoadCall.Returns.Error = errors.New("no token")
+
+				err := processor.Process(database.Connection(), "some-uaa-host", *gobble.NewJob(queue.CampaignJob{
+					Campaign: collections.Campaign{
+						ID:     "some-id",
+						SendTo: map[string][]string{"orgs": {"some-org-guid"}},
+					},
+				}), logger)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(userLoader.LoadCall.CallCount).To(Equal(0))
+				Expect(enqueuer.EnqueueCall.EnqueuedUsers).To(HaveLen(600))
+			})
+		})
+	})
+
+	Context("when checkpointing the fan-out", func() {
+		var job gobble.Job
+
+		BeforeEach(func() {
+			orgs.GenerateAudiencesCall.Returns.Audiences = []horde.Audience{
+				{
+					Users:       []horde.User{{GUID: "some-user-guid"}, {GUID: "some-ops-user-guid"}},
+					Endorsement: "some endorsement",
+				},
+			}
+			users.GenerateAudiencesCall.Returns.Audiences = []horde.Audience{
+				{Users: []horde.User{{GUID: "some-ops-user-guid"}}},
+			}
+			everyone.GenerateAudiencesCall.Returns.Audiences = []horde.Audience{
+				{Users: []horde.User{{GUID: "some-other-user-guid"}}},
+			}
+
+			job = *gobble.NewJob(queue.CampaignJob{
+				Campaign: collections.Campaign{
+					ID: "some-id",
+					SendTo: map[string][]string{
+						"orgs":     {"some-org-guid", "some-other-org-guid"},
+						"everyone": {},
+					},
+					Exclude: map[string][]string{"users": {"some-ops-user-guid"}},
+				},
+			})
+			filtersRepository.ListCall.Returns.Filters = []models.CampaignFilter{
+				{CampaignID: "some-id", Kind: "exclude", Recipient: "some-ops-user-guid"},
+			}
+		})
+
+		It("records the progress after each audience member", func() {
+			err := processor.Process(database.Connection(), "some-uaa-host", job, logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(campaignsRepository.GetCall.Receives.Connection).To(Equal(connection))
+			Expect(campaignsRepository.GetCall.Receives.CampaignID).To(Equal("some-id"))
+
+			Expect(everyone.GenerateAudiencesCall.CallCount).To(Equal(1))
+			Expect(orgs.GenerateAudiencesCall.CallCount).To(Equal(2))
+
+			Expect(campaignsRepository.SetFanoutProgressCall.CallCount).To(Equal(3))
+			Expect(campaignsRepository.SetFanoutProgressCall.Receives.Connection).To(Equal(connection))
+			Expect(campaignsRepository.SetFanoutProgressCall.Receives.CampaignID).To(Equal("some-id"))
+			Expect(campaignsRepository.SetFanoutProgressCall.Receives.Progress).To(Equal(3))
+			Expect(filtersRepository.InsertCall.CallCount).To(Equal(1))
+			Expect(campaignsRepository.UpdateExcludedCountCall.CallCount).To(Equal(3))
+		})
+
+		It("resumes from the recorded progress", func() {
+			campaignsRepository.GetCall.Returns.Campaign = models.Campaign{
+				ID:             "some-id",
+				FanoutProgress: 2,
+			}
+
+			err := processor.Process(database.Connection(), "some-uaa-host", job, logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(everyone.GenerateAudiencesCall.CallCount).To(Equal(0))
+			Expect(orgs.GenerateAudiencesCall.CallCount).To(Equal(1))
+			Expect(orgs.GenerateAudiencesCall.Receives.Inputs).To(Equal([]string{"some-other-org-guid"}))
+
+			Expect(campaignsRepository.SetFanoutProgressCall.CallCount).To(Equal(1))
+			Expect(campaignsRepository.SetFanoutProgressCall.Receives.Progress).To(Equal(3))
+			Expect(filtersRepository.InsertCall.CallCount).To(Equal(0))
+			Expect(filtersRepository.RecordExcludedCall.Receives.Recipients).To(Equal([]string{"some-ops-user-guid"}))
+			Expect(campaignsRepository.UpdateExcludedCountCall.CallCount).To(Equal(1))
+		})
+
+		It("records when the fan-out started and completed", func() {
+			clock.NowCall.Returns.Time = time.Date(2016, 1, 2, 3, 4, 5, 0, time.UTC)
+
+			err := processor.Process(database.Connection(), "some-uaa-host", job, logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(campaignsRepository.StartFanoutCall.Receives.Connection).To(Equal(connection))
+			Expect(campaignsRepository.StartFanoutCall.Receives.CampaignID).To(Equal("some-id"))
+			Expect(campaignsRepository.StartFanoutCall.Receives.StartedAt).To(Equal(time.Date(2016, 1, 2, 3, 4, 5, 0, time.UTC)))
+
+			Expect(campaignsRepository.CompleteFanoutCall.CallCount).To(Equal(1))
+			Expect(campaignsRepository.CompleteFanoutCall.Receives.CampaignID).To(Equal("some-id"))
+			Expect(campaignsRepository.CompleteFanoutCall.Receives.CompletedAt).To(Equal(time.Date(2016, 1, 2, 3, 4, 5, 0, time.UTC)))
+		})
+
+		It("keeps the original start time when resuming", func() {
+			startedAt := time.Date(2016, 1, 2, 3, 0, 0, 0, time.UTC)
+			campaignsRepository.GetCall.Returns.Campaign = models.Campaign{
+				ID:              "some-id",
+				FanoutProgress:  2,
+				FanoutStartedAt: mysql.NullTime{Time: startedAt, Valid: true},
+			}
+
+			err := processor.Process(database.Connection(), "some-uaa-host", job, logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(campaignsRepository.StartFanoutCall.Receives.StartedAt).To(Equal(startedAt))
+			Expect(campaignsRepository.CompleteFanoutCall.CallCount).To(Equal(1))
+		})
+
+		It("does not complete a fan-out that has already completed", func() {
+			campaignsRepository.GetCall.Returns.Campaign = models.Campaign{
+				ID:                "some-id",
+				FanoutProgress:    3,
+				FanoutCompletedAt: mysql.NullTime{Time: time.Now(), Valid: true},
+			}
+
+			err := processor.Process(database.Connection(), "some-uaa-host", job, logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(campaignsRepository.CompleteFanoutCall.CallCount).To(Equal(0))
+		})
+
+		It("passes along when the campaign request was received", func() {
+			requestReceived := time.Date(2016, 1, 2, 3, 4, 5, 0, time.UTC)
+			job = *gobble.NewJob(queue.CampaignJob{
+				Campaign: collections.Campaign{
+					ID:              "some-id",
+					SendTo:          map[string][]string{"everyone": {}},
+					RequestReceived: requestReceived,
+				},
+			})
+
+			err := processor.Process(database.Connection(), "some-uaa-host", job, logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(enqueuer.EnqueueCall.Receives.RequestReceived).To(Equal(requestReceived))
+		})
+
+		Context("when the fan-out start cannot be recorded", func() {
+			It("returns the error", func() {
+				campaignsRepository.StartFanoutCall.Returns.Error = errors.New("some database error")
+
+				err := processor.Process(database.Connection(), "some-uaa-host", job, logger)
+				Expect(err).To(MatchError(errors.New("some database error")))
+				Expect(enqueuer.EnqueueCall.CallCount).To(Equal(0))
+			})
+		})
+
+		Context("when the fan-out completion cannot be recorded", func() {
+			It("returns the error", func() {
+				campaignsRepository.CompleteFanoutCall.Returns.Error = errors.New("some database error")
+
+				err := processor.Process(database.Connection(), "some-uaa-host", job, logger)
+				Expect(err).To(MatchError(errors.New("some database error")))
+			})
+		})
+
+		Context("when a batch cannot be enqueued", func() {
+			It("returns the error without recording progress", func() {
+				enqueuer.EnqueueCall.Returns.Error = errors.New("some database error")
+
+				err := processor.Process(database.Connection(), "some-uaa-host", job, logger)
+				Expect(err).To(MatchError(errors.New("some database error")))
+				Expect(campaignsRepository.SetFanoutProgressCall.CallCount).To(Equal(0))
+			})
+		})
+
+		Context("when the progress cannot be read", func() {
+			It("returns the error", func() {
+				campaignsRepository.GetCall.Returns.Error = errors.New("some database error")
+
+				err := processor.Process(database.Connection(), "some-uaa-host", job, logger)
+				Expect(err).To(MatchError(errors.New("some database error")))
+				Expect(enqueuer.EnqueueCall.CallCount).To(Equal(0))
+			})
+		})
+
+		Context("when the progress cannot be recorded", func() {
+			It("returns the error", func() {
+				campaignsRepository.SetFanoutProgressCall.Returns.Error = errors.New("some database error")
+
+				err := processor.Process(database.Connection(), "some-uaa-host", job, logger)
+				Expect(err).To(MatchError(errors.New("some database error")))
+				Expect(campaignsRepository.SetFanoutProgressCall.CallCount).To(Equal(1))
+			})
+		})
+	})
+
 	Context("when an error occurs", func() {
 		Context("when the campaign cannot be unmarshalled", func() {
 			It("returns the error", func() {
@@ -469,7 +1083,7 @@ var _ = Describe("CampaignJobProcessor", func() {
 						SendTo: map[string][]string{"some-audience": {"wut"}},
 					},
 				}), logger)
-				Expect(err).To(MatchError(v2.NoAudienceError{errors.New("generator for \"some-audience\" audience could not be found")}))
+				Expect(err).To(MatchError(v2.NoAudienceError{horde.UnknownAudienceError{Audience: "some-audience"}}))
 			})
 		})
 
@@ -478,7 +1092,8 @@ var _ = Describe("CampaignJobProcessor", func() {
 				htmlExtractor := mocks.NewHTMLExtractor()
 				htmlExtractor.ExtractCall.Returns.Error = errors.New("some extraction error")
 				processor = v2.NewCampaignJobProcessor(notify.EmailFormatter{},
-					htmlExtractor, emails, spaces, orgs, users, enqueuer)
+					htmlExtractor, generators, campaignsRepository, filtersRepository, recipientsRepository, enqueuer,
+					tokenLoader, userLoader, clock)
 
 				err := processor.Process(database.Connection(), "some-uaa-host", *gobble.NewJob(queue.CampaignJob{
 					Campaign: collections.Campaign{
@@ -2,15 +2,21 @@ package v2
 
 import (
 	"strings"
+	"time"
 
 	"github.com/cloudfoundry-incubator/notifications/db"
 	"github.com/cloudfoundry-incubator/notifications/mail"
 	"github.com/cloudfoundry-incubator/notifications/postal/common"
 	"github.com/cloudfoundry-incubator/notifications/uaa"
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
 	"github.com/cloudfoundry-incubator/notifications/v2/models"
 	"github.com/pivotal-golang/lager"
 )
 
+// resolvedEmailMaxAge is how long an address resolved during the campaign
+// fan-out is trusted before the delivery looks the user up again.
+const resolvedEmailMaxAge = 24 * time.Hour
+
 type tokenLoader interface {
 	Load(string) (string, error)
 }
@@ -37,46 +43,89 @@ type unsubscribersRepositoryInterface interface {
 	Get(connection models.ConnectionInterface, userGUID, campaignTypeID string) (models.Unsubscriber, error)
 }
 
+type globalUnsubscribesRepositoryInterface interface {
+	Get(connection models.ConnectionInterface, userGUID string) (bool, error)
+}
+
+type emailUnsubscribesRepositoryInterface interface {
+	Get(connection models.ConnectionInterface, email, clientID, campaignTypeID string) (bool, error)
+}
+
+type digestPreferencesRepositoryInterface interface {
+	Get(connection models.ConnectionInterface, userGUID, clientID, campaignTypeID string) (string, error)
+}
+
+type digestsRepositoryInterface interface {
+	Insert(connection models.ConnectionInterface, digest models.Digest) (models.Digest, error)
+}
+
+type quietHoursRepositoryInterface interface {
+	Get(connection models.ConnectionInterface, userGUID string) (models.QuietHours, error)
+}
+
+type campaignTypesRepositoryInterface interface {
+	Get(connection models.ConnectionInterface, campaignTypeID string) (models.CampaignType, error)
+}
+
 type campaignsRepositoryInterface interface {
 	Get(connection models.ConnectionInterface, campaignID string) (models.Campaign, error)
 }
 
+type messageTimingsRepositoryInterface interface {
+	Insert(connection models.ConnectionInterface, timing models.MessageTiming) error
+}
+
 type metricsEmitter interface {
 	Increment(counter string)
 }
 
 type DeliveryJobProcessor struct {
-	mailClient              mailSender
-	packager                messagePackager
-	userLoader              userLoader
-	tokenLoader             tokenLoader
-	messageStatusUpdater    messageStatusUpdater
-	unsubscribersRepository unsubscribersRepositoryInterface
-	campaignsRepository     campaignsRepositoryInterface
-	database                db.DatabaseInterface
-	sender                  string
-	domain                  string
-	uaaHost                 string
-	metricsEmitter          metricsEmitter
+	mailClient                  mailSender
+	packager                    messagePackager
+	userLoader                  userLoader
+	tokenLoader                 tokenLoader
+	messageStatusUpdater        messageStatusUpdater
+	unsubscribes                collections.UnsubscribeFilter
+	digestPreferencesRepository digestPreferencesRepositoryInterface
+	digestsRepository           digestsRepositoryInterface
+	quietHoursRepository        quietHoursRepositoryInterface
+	campaignsRepository         campaignsRepositoryInterface
+	campaignTypesRepository     campaignTypesRepositoryInterface
+	messageTimingsRepository    messageTimingsRepositoryInterface
+	database                    db.DatabaseInterface
+	sender                      string
+	domain                      string
+	uaaHost                     string
+	metricsEmitter              metricsEmitter
+	clock                       clock
 }
 
 func NewDeliveryJobProcessor(mailClient mailSender, packager messagePackager, userLoader userLoader, tokenLoader tokenLoader,
 	messageStatusUpdater messageStatusUpdater, database db.DatabaseInterface, unsubscribersRepository unsubscribersRepositoryInterface,
-	campaignsRepository campaignsRepositoryInterface, sender, domain, uaaHost string, metricsEmitter metricsEmitter) DeliveryJobProcessor {
+	globalUnsubscribesRepository globalUnsubscribesRepositoryInterface, emailUnsubscribesRepository emailUnsubscribesRepositoryInterface,
+	digestPreferencesRepository digestPreferencesRepositoryInterface, digestsRepository digestsRepositoryInterface, quietHoursRepository quietHoursRepositoryInterface,
+	campaignsRepository campaignsRepositoryInterface, campaignTypesRepository campaignTypesRepositoryInterface,
+	messageTimingsRepository messageTimingsRepositoryInterface, sender, domain, uaaHost string, metricsEmitter metricsEmitter, clock clock) DeliveryJobProcessor {
 
 	return DeliveryJobProcessor{
-		mailClient:              mailClient,
-		packager:                packager,
-		userLoader:              userLoader,
-		tokenLoader:             tokenLoader,
-		messageStatusUpdater:    messageStatusUpdater,
-		campaignsRepository:     campaignsRepository,
-		unsubscribersRepository: unsubscribersRepository,
-		database:                database,
-		sender:                  sender,
-		domain:                  domain,
-		uaaHost:                 uaaHost,
-		metricsEmitter:          metricsEmitter,
+		mailClient:                  mailClient,
+		packager:                    packager,
+		userLoader:                  userLoader,
+		tokenLoader:                 tokenLoader,
+		messageStatusUpdater:        messageStatusUpdater,
+		campaignsRepository:         campaignsRepository,
+		campaignTypesRepository:     campaignTypesRepository,
+		messageTimingsRepository:    messageTimingsRepository,
+		unsubscribes:                collections.NewUnsubscribeFilter(unsubscribersRepository, globalUnsubscribesRepository, emailUnsubscribesRepository, campaignTypesRepository),
+		digestPreferencesRepository: digestPreferencesRepository,
+		digestsRepository:           digestsRepository,
+		quietHoursRepository:        quietHoursRepository,
+		database:                    database,
+		sender:                      sender,
+		domain:                      domain,
+		uaaHost:                     uaaHost,
+		metricsEmitter:              metricsEmitter,
+		clock:                       clock,
 	}
 }
 
@@ -88,14 +137,14 @@ func (p DeliveryJobProcessor) Process(delivery common.Delivery, logger lager.Log
 		return err
 	}
 
-	unsubscriber, err := p.unsubscribersRepository.Get(conn, delivery.UserGUID, campaign.CampaignTypeID)
+	common.RecordTiming("notifications.queue.wait", "2", delivery.ClientID, campaign.CampaignTypeID, delivery.QueueWait)
+
+	unsubscribed, err := p.unsubscribes.Unsubscribed(conn, delivery.UserGUID, delivery.Email, delivery.ClientID, campaign.CampaignTypeID)
 	if err != nil {
-		if _, ok := err.(models.RecordNotFoundError); !ok {
-			return err
-		}
+		return err
 	}
 
-	if unsubscriber.ID != "" {
+	if unsubscribed {
 		p.messageStatusUpdater.Update(conn, delivery.MessageID, common.StatusDelivered, delivery.CampaignID, logger)
 		p.metricsEmitter.Increment("notifications.worker.unsubscribed")
 		return nil
@@ -107,25 +156,47 @@ func (p DeliveryJobProcessor) Process(delivery common.Delivery, logger lager.Log
 			return nil
 		}
 
-		token, err := p.tokenLoader.Load(p.uaaHost)
+		delivery.Email, err = p.recipientEmail(delivery)
 		if err != nil {
 			return err
 		}
+	}
 
-		users, err := p.userLoader.Load([]string{delivery.UserGUID}, token)
+	if !strings.Contains(delivery.Email, "@") {
+		p.messageStatusUpdater.Update(conn, delivery.MessageID, common.StatusUndeliverable, delivery.CampaignID, logger)
+		return nil
+	}
+
+	delivery.CampaignTypeID = campaign.CampaignTypeID
+
+	quietHours := models.QuietHours{}
+	if delivery.UserGUID != "" {
+		held, err := p.holdForDigest(conn, delivery)
 		if err != nil {
 			return err
 		}
 
-		emails := users[delivery.UserGUID].Emails
-		if len(emails) > 0 {
-			delivery.Email = emails[0]
+		if held {
+			p.metricsEmitter.Increment("notifications.worker.digested")
+			return nil
+		}
+
+		quietHours, err = p.quietHoursRepository.Get(conn, delivery.UserGUID)
+		if err != nil {
+			return err
 		}
+
+		delivery.TimeZone = quietHours.TimeZone
 	}
 
-	if !strings.Contains(delivery.Email, "@") {
-		p.messageStatusUpdater.Update(conn, delivery.MessageID, common.StatusUndeliverable, delivery.CampaignID, logger)
-		return nil
+	until, err := p.deferUntil(conn, delivery, quietHours, campaign.BusinessHours)
+	if err != nil {
+		return err
+	}
+
+	if !until.IsZero() {
+		p.metricsEmitter.Increment("notifications.worker.deferred")
+		return common.DeliveryDeferredError{Until: until}
 	}
 
 	context, err := p.packager.PrepareContext(delivery, p.sender, p.domain)
@@ -138,14 +209,129 @@ func (p DeliveryJobProcessor) Process(delivery common.Delivery, logger lager.Log
 		return err
 	}
 
+	sendStarted := time.Now()
 	err = p.mailClient.Send(message, logger)
 	if err != nil {
 		return err
 	}
+	sendDuration := time.Since(sendStarted)
 
 	p.messageStatusUpdater.Update(conn, delivery.MessageID, common.StatusDelivered, delivery.CampaignID, logger)
+	p.recordTimings(conn, delivery, sendDuration, logger)
 
 	p.metricsEmitter.Increment("notifications.worker.delivered")
 
 	return nil
 }
+
+// recordTimings emits the latency histograms of a delivered message and keeps
+// its timing for the campaign status. Failing to keep the timing is only
+// logged since the message has already been sent.
+func (p DeliveryJobProcessor) recordTimings(conn db.ConnectionInterface, delivery common.Delivery, sendDuration time.Duration, logger lager.Logger) {
+	common.RecordTiming("notifications.delivery.send", "2", delivery.ClientID, delivery.CampaignTypeID, sendDuration)
+	if !delivery.RequestReceived.IsZero() {
+		common.RecordTiming("notifications.delivery.end-to-end", "2", delivery.ClientID, delivery.CampaignTypeID, time.Since(delivery.RequestReceived))
+	}
+
+	err := p.messageTimingsRepository.Insert(conn, models.MessageTiming{
+		MessageID:    delivery.MessageID,
+		CampaignID:   delivery.CampaignID,
+		QueueWait:    delivery.QueueWait.Seconds(),
+		SendDuration: sendDuration.Seconds(),
+	})
+	if err != nil {
+		logger.Error("message-timing-failed", err)
+	}
+}
+
+// recipientEmail uses the address resolved when the campaign was fanned out
+// while it is fresh, and otherwise looks the user up in UAA.
+func (p DeliveryJobProcessor) recipientEmail(delivery common.Delivery) (string, error) {
+	if delivery.ResolvedEmail != "" && p.clock.Now().Sub(delivery.EmailResolvedAt) < resolvedEmailMaxAge {
+		return delivery.ResolvedEmail, nil
+	}
+
+	token, err := p.tokenLoader.Load(p.uaaHost)
+	if err != nil {
+		return "", err
+	}
+
+	users, err := p.userLoader.Load([]string{delivery.UserGUID}, token)
+	if err != nil {
+		return "", err
+	}
+
+	emails := users[delivery.UserGUID].Emails
+	if len(emails) > 0 {
+		return emails[0], nil
+	}
+
+	return "", nil
+}
+
+// holdForDigest stores the delivery to be sent in the user's next digest when
+// they have asked for this campaign type to be batched. The message stays
+// queued until the digest goes out. Critical campaign types are never held.
+func (p DeliveryJobProcessor) holdForDigest(conn db.ConnectionInterface, delivery common.Delivery) (bool, error) {
+	frequency, err := p.digestPreferencesRepository.Get(conn, delivery.UserGUID, delivery.ClientID, delivery.CampaignTypeID)
+	if err != nil || frequency == models.DigestImmediate {
+		return false, err
+	}
+
+	nonCritical, err := p.nonCritical(conn, delivery.CampaignTypeID)
+	if err != nil || !nonCritical {
+		return false, err
+	}
+
+	_, err = p.digestsRepository.Insert(conn, models.Digest{
+		MessageID:      delivery.MessageID,
+		CampaignID:     delivery.CampaignID,
+		UserGUID:       delivery.UserGUID,
+		Email:          delivery.Email,
+		ClientID:       delivery.ClientID,
+		CampaignTypeID: delivery.CampaignTypeID,
+		Frequency:      frequency,
+		Subject:        delivery.Options.Subject,
+		Text:           delivery.Options.Text,
+		HTML:           delivery.Options.HTML.BodyContent,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// deferUntil returns when the delivery should next be attempted if the
+// recipient is outside of their quiet hours or, for campaigns that ask for it,
+// their local business hours. It returns the zero time when the delivery can
+// go now. Critical campaign types are never deferred.
+func (p DeliveryJobProcessor) deferUntil(conn db.ConnectionInterface, delivery common.Delivery, quietHours models.QuietHours, businessHours bool) (time.Time, error) {
+	now := p.clock.Now()
+	next := common.DeliveryWindow{
+		TimeZone:      quietHours.TimeZone,
+		QuietStart:    quietHours.Start,
+		QuietEnd:      quietHours.End,
+		BusinessHours: businessHours,
+	}.Next(now)
+
+	if !next.After(now) {
+		return time.Time{}, nil
+	}
+
+	nonCritical, err := p.nonCritical(conn, delivery.CampaignTypeID)
+	if err != nil || !nonCritical {
+		return time.Time{}, err
+	}
+
+	return next, nil
+}
+
+func (p DeliveryJobProcessor) nonCritical(conn db.ConnectionInterface, campaignTypeID string) (bool, error) {
+	campaignType, err := p.campaignTypesRepository.Get(conn, campaignTypeID)
+	if err != nil {
+		return false, err
+	}
+
+	return !campaignType.Critical, nil
+}
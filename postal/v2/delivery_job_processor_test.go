@@ -3,9 +3,11 @@ package v2_test
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/cloudfoundry-incubator/notifications/mail"
+	"github.com/cloudfoundry-incubator/notifications/metrics"
 	"github.com/cloudfoundry-incubator/notifications/postal/common"
 	"github.com/cloudfoundry-incubator/notifications/postal/v2"
 	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
@@ -32,7 +34,15 @@ var _ = Describe("DeliveryJobProcessor", func() {
 		delivery                common.Delivery
 		campaignsRepository     *mocks.CampaignsRepository
 		unsubscribersRepository *mocks.UnsubscribersRepository
+		globalUnsubscribes      *mocks.GlobalUnsubscribesRepository
+		emailUnsubscribes       *mocks.EmailUnsubscribesRepository
+		digestPreferences       *mocks.DigestPreferencesRepository
+		digests                 *mocks.DigestsRepository
+		quietHours              *mocks.QuietHoursRepository
+		campaignTypesRepository *mocks.CampaignTypesRepository
+		messageTimings          *mocks.MessageTimingsRepository
 		metricsEmitter          *mocks.MetricsEmitter
+		clock                   *mocks.Clock
 	)
 
 	BeforeEach(func() {
@@ -60,6 +70,14 @@ var _ = Describe("DeliveryJobProcessor", func() {
 		campaignsRepository = mocks.NewCampaignsRepository()
 		unsubscribersRepository = mocks.NewUnsubscribersRepository()
 		unsubscribersRepository.GetCall.Returns.Error = models.RecordNotFoundError{errors.New("not unsubscribed == will be delivered!")}
+		globalUnsubscribes = mocks.NewGlobalUnsubscribesRepository()
+		emailUnsubscribes = mocks.NewEmailUnsubscribesRepository()
+		digestPreferences = mocks.NewDigestPreferencesRepository()
+		digestPreferences.GetCall.Returns.Frequency = models.DigestImmediate
+		digests = mocks.NewDigestsRepository()
+		quietHours = mocks.NewQuietHoursRepository()
+		campaignTypesRepository = mocks.NewCampaignTypesRepository()
+		messageTimings = mocks.NewMessageTimingsRepository()
 
 		packager = mocks.NewPackager()
 		packager.PrepareContextCall.Returns.MessageContext = common.MessageContext{
@@ -137,9 +155,12 @@ var _ = Describe("DeliveryJobProcessor", func() {
 
 		metricsEmitter = mocks.NewMetricsEmitter()
 
+		clock = mocks.NewClock()
+		clock.NowCall.Returns.Time = time.Now()
+
 		processor = v2.NewDeliveryJobProcessor(mailClient, packager, userLoader, tokenLoader,
-			messageStatusUpdater, database, unsubscribersRepository, campaignsRepository,
-			"from@example.com", "example.com", "uaa-host", metricsEmitter)
+			messageStatusUpdater, database, unsubscribersRepository, globalUnsubscribes, emailUnsubscribes,
+			digestPreferences, digests, quietHours, campaignsRepository, campaignTypesRepository, messageTimings, "from@example.com", "example.com", "uaa-host", metricsEmitter, clock)
 	})
 
 	It("ensures message delivery", func() {
@@ -196,6 +217,89 @@ var _ = Describe("DeliveryJobProcessor", func() {
 		Expect(metricsEmitter.IncrementCall.Receives.Counter).To(Equal("notifications.worker.delivered"))
 	})
 
+	It("records the timing of the delivered message", func() {
+		delivery.QueueWait = 2 * time.Second
+
+		err := processor.Process(delivery, logger)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(messageTimings.InsertCall.CallCount).To(Equal(1))
+		Expect(messageTimings.InsertCall.Receives.Connection).To(Equal(conn))
+
+		timing := messageTimings.InsertCall.Receives.Timing
+		Expect(timing.MessageID).To(Equal("randomly-generated-guid"))
+		Expect(timing.CampaignID).To(Equal("some-campaign-id"))
+		Expect(timing.QueueWait).To(Equal(2.0))
+		Expect(timing.SendDuration).To(BeNumerically(">=", 0))
+	})
+
+	It("tags the latency histograms with the campaign type", func() {
+		campaignsRepository.GetCall.Returns.Campaign.CampaignTypeID = "timed-campaign-type-id"
+		delivery.ClientID = "timed-client"
+
+		err := processor.Process(delivery, logger)
+		Expect(err).NotTo(HaveOccurred())
+
+		registry := bytes.NewBuffer([]byte{})
+		_, err = metrics.DefaultRegistry.WriteTo(registry)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(registry.String()).To(ContainSubstring(`notifications_queue_wait_seconds_count{campaign_type="timed-campaign-type-id",client="timed-client",version="2"} 1`))
+		Expect(registry.String()).To(ContainSubstring(`notifications_delivery_send_seconds_count{campaign_type="timed-campaign-type-id",client="timed-client",version="2"} 1`))
+	})
+
+	It("still succeeds when the timing cannot be recorded", func() {
+		messageTimings.InsertCall.Returns.Error = errors.New("timing failed")
+
+		err := processor.Process(delivery, logger)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(buffer.String()).To(ContainSubstring("message-timing-failed"))
+	})
+
+	It("does not record a timing when the message cannot be sent", func() {
+		mailClient.SendCall.Returns.Error = errors.New("smtp failed")
+
+		err := processor.Process(delivery, logger)
+		Expect(err).To(MatchError(errors.New("smtp failed")))
+
+		Expect(messageTimings.InsertCall.CallCount).To(Equal(0))
+	})
+
+	Context("when the address was resolved during the campaign fan-out", func() {
+		BeforeEach(func() {
+			clock.NowCall.Returns.Time = time.Date(2016, 1, 2, 3, 4, 5, 0, time.UTC)
+
+			delivery.ResolvedEmail = "resolved-123@example.com"
+			delivery.EmailResolvedAt = time.Date(2016, 1, 2, 2, 4, 5, 0, time.UTC)
+		})
+
+		It("uses the resolved address without asking UAA", func() {
+			err := processor.Process(delivery, logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(tokenLoader.LoadCall.Receives.UAAHost).To(BeEmpty())
+			Expect(userLoader.LoadCall.CallCount).To(Equal(0))
+
+			delivery.Email = "resolved-123@example.com"
+			Expect(packager.PrepareContextCall.Receives.Delivery).To(Equal(delivery))
+		})
+
+		Context("when the resolved address is stale", func() {
+			It("looks the user up again", func() {
+				clock.NowCall.Returns.Time = time.Date(2016, 1, 3, 2, 4, 6, 0, time.UTC)
+
+				err := processor.Process(delivery, logger)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(userLoader.LoadCall.CallCount).To(Equal(1))
+				Expect(userLoader.LoadCall.Receives.UserGUIDs).To(Equal([]string{"user-123"}))
+
+				delivery.Email = "user-123@example.com"
+				Expect(packager.PrepareContextCall.Receives.Delivery).To(Equal(delivery))
+			})
+		})
+	})
+
 	Context("when the delivery does not have a user GUID", func() {
 		BeforeEach(func() {
 			delivery.Email = "user-123@example.com"
@@ -342,7 +446,285 @@ var _ = Describe("DeliveryJobProcessor", func() {
 		})
 	})
 
+	Context("when the user is globally unsubscribed", func() {
+		BeforeEach(func() {
+			globalUnsubscribes.GetCall.Returns.Unsubscribed = true
+			campaignsRepository.GetCall.Returns.Campaign = models.Campaign{
+				CampaignTypeID: "some-campaign-type-id",
+			}
+			campaignTypesRepository.GetCall.Returns.CampaignType = models.CampaignType{
+				ID: "some-campaign-type-id",
+			}
+		})
+
+		It("does not send the notification", func() {
+			err := processor.Process(delivery, logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(globalUnsubscribes.GetCall.Receives.Connection).To(Equal(conn))
+			Expect(globalUnsubscribes.GetCall.Receives.UserGUID).To(Equal("user-123"))
+			Expect(campaignTypesRepository.GetCall.Receives.CampaignTypeID).To(Equal("some-campaign-type-id"))
+
+			Expect(mailClient.SendCall.CallCount).To(Equal(0))
+			Expect(messageStatusUpdater.UpdateCall.Receives.MessageStatus).To(Equal(common.StatusDelivered))
+			Expect(metricsEmitter.IncrementCall.Receives.Counter).To(Equal("notifications.worker.unsubscribed"))
+		})
+
+		It("still sends notifications of critical campaign types", func() {
+			campaignTypesRepository.GetCall.Returns.CampaignType.Critical = true
+
+			err := processor.Process(delivery, logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(mailClient.SendCall.CallCount).To(Equal(1))
+			Expect(metricsEmitter.IncrementCall.Receives.Counter).To(Equal("notifications.worker.delivered"))
+		})
+	})
+
+	Context("when the delivery is addressed to an email", func() {
+		BeforeEach(func() {
+			delivery.UserGUID = ""
+			delivery.Email = "user-123@example.com"
+			campaignsRepository.GetCall.Returns.Campaign = models.Campaign{
+				CampaignTypeID: "some-campaign-type-id",
+			}
+			campaignTypesRepository.GetCall.Returns.CampaignType = models.CampaignType{
+				ID: "some-campaign-type-id",
+			}
+		})
+
+		It("does not check for a global unsubscribe", func() {
+			err := processor.Process(delivery, logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(globalUnsubscribes.GetCall.Receives.UserGUID).To(BeEmpty())
+		})
+
+		It("passes the campaign type to the packager for the unsubscribe link", func() {
+			err := processor.Process(delivery, logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(packager.PrepareContextCall.Receives.Delivery.CampaignTypeID).To(Equal("some-campaign-type-id"))
+		})
+
+		Context("when the email address has unsubscribed from the campaign type", func() {
+			BeforeEach(func() {
+				emailUnsubscribes.GetCall.Returns.Unsubscribed = true
+			})
+
+			It("does not send the notification", func() {
+				err := processor.Process(delivery, logger)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(emailUnsubscribes.GetCall.Receives.Connection).To(Equal(conn))
+				Expect(emailUnsubscribes.GetCall.Receives.Email).To(Equal("user-123@example.com"))
+				Expect(emailUnsubscribes.GetCall.Receives.ClientID).To(Equal("some-client"))
+				Expect(emailUnsubscribes.GetCall.Receives.CampaignTypeID).To(Equal("some-campaign-type-id"))
+
+				Expect(mailClient.SendCall.CallCount).To(Equal(0))
+				Expect(messageStatusUpdater.UpdateCall.Receives.MessageStatus).To(Equal(common.StatusDelivered))
+				Expect(metricsEmitter.IncrementCall.Receives.Counter).To(Equal("notifications.worker.unsubscribed"))
+			})
+
+			It("still sends notifications of critical campaign types", func() {
+				campaignTypesRepository.GetCall.Returns.CampaignType.Critical = true
+
+				err := processor.Process(delivery, logger)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(mailClient.SendCall.CallCount).To(Equal(1))
+			})
+		})
+
+		Context("when the email unsubscribes repository has an error", func() {
+			It("returns the error", func() {
+				emailUnsubscribes.GetCall.Returns.Error = errors.New("some-email-unsubscribes-error")
+
+				err := processor.Process(delivery, logger)
+				Expect(err).To(MatchError(errors.New("some-email-unsubscribes-error")))
+			})
+		})
+	})
+
+	Context("when the user receives the campaign type in a digest", func() {
+		BeforeEach(func() {
+			digestPreferences.GetCall.Returns.Frequency = models.DigestHourly
+			campaignsRepository.GetCall.Returns.Campaign = models.Campaign{
+				CampaignTypeID: "some-campaign-type-id",
+			}
+			campaignTypesRepository.GetCall.Returns.CampaignType = models.CampaignType{
+				ID: "some-campaign-type-id",
+			}
+		})
+
+		It("holds the notification for the digest instead of sending it", func() {
+			err := processor.Process(delivery, logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(digestPreferences.GetCall.Receives.Connection).To(Equal(conn))
+			Expect(digestPreferences.GetCall.Receives.UserGUID).To(Equal("user-123"))
+			Expect(digestPreferences.GetCall.Receives.ClientID).To(Equal("some-client"))
+			Expect(digestPreferences.GetCall.Receives.CampaignTypeID).To(Equal("some-campaign-type-id"))
+
+			Expect(digests.InsertCall.Receives.Connection).To(Equal(conn))
+			Expect(digests.InsertCall.Receives.Digest).To(Equal(models.Digest{
+				MessageID:      "randomly-generated-guid",
+				CampaignID:     "some-campaign-id",
+				UserGUID:       "user-123",
+				Email:          "user-123@example.com",
+				ClientID:       "some-client",
+				CampaignTypeID: "some-campaign-type-id",
+				Frequency:      models.DigestHourly,
+				Subject:        "the subject",
+				Text:           "body content",
+			}))
+
+			Expect(mailClient.SendCall.CallCount).To(Equal(0))
+			Expect(messageStatusUpdater.UpdateCall.Receives.MessageID).To(BeEmpty())
+			Expect(metricsEmitter.IncrementCall.Receives.Counter).To(Equal("notifications.worker.digested"))
+		})
+
+		It("still sends notifications of critical campaign types immediately", func() {
+			campaignTypesRepository.GetCall.Returns.CampaignType.Critical = true
+
+			err := processor.Process(delivery, logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(digests.InsertCall.Receives.Digest).To(Equal(models.Digest{}))
+			Expect(mailClient.SendCall.CallCount).To(Equal(1))
+		})
+
+		It("returns the error when the notification cannot be held", func() {
+			digests.InsertCall.Returns.Error = errors.New("some-digests-error")
+
+			err := processor.Process(delivery, logger)
+			Expect(err).To(MatchError(errors.New("some-digests-error")))
+		})
+
+		It("returns the error when the digest preference cannot be loaded", func() {
+			digestPreferences.GetCall.Returns.Error = errors.New("some-digest-preferences-error")
+
+			err := processor.Process(delivery, logger)
+			Expect(err).To(MatchError(errors.New("some-digest-preferences-error")))
+		})
+	})
+
+	Context("when the user is in their quiet hours", func() {
+		var quietHoursEnd time.Time
+
+		BeforeEach(func() {
+			now := time.Now().UTC()
+			quietHoursEnd = now.Add(time.Hour).Truncate(time.Minute)
+			quietHours.GetCall.Returns.QuietHours = models.QuietHours{
+				UserGUID: "user-123",
+				TimeZone: "UTC",
+				Start:    now.Add(-time.Hour).Format("15:04"),
+				End:      quietHoursEnd.Format("15:04"),
+			}
+		})
+
+		It("defers the delivery until the quiet hours end", func() {
+			err := processor.Process(delivery, logger)
+			Expect(err).To(BeAssignableToTypeOf(common.DeliveryDeferredError{}))
+			Expect(err.(common.DeliveryDeferredError).Until).To(BeTemporally("==", quietHoursEnd))
+
+			Expect(quietHours.GetCall.Receives.Connection).To(Equal(conn))
+			Expect(quietHours.GetCall.Receives.UserGUID).To(Equal("user-123"))
+			Expect(mailClient.SendCall.CallCount).To(Equal(0))
+			Expect(messageStatusUpdater.UpdateCall.Receives.MessageID).To(BeEmpty())
+			Expect(metricsEmitter.IncrementCall.Receives.Counter).To(Equal("notifications.worker.deferred"))
+		})
+
+		It("still sends notifications of critical campaign types immediately", func() {
+			campaignTypesRepository.GetCall.Returns.CampaignType.Critical = true
+
+			err := processor.Process(delivery, logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mailClient.SendCall.CallCount).To(Equal(1))
+		})
+
+		It("returns the error when the quiet hours cannot be loaded", func() {
+			quietHours.GetCall.Returns.Error = errors.New("some-quiet-hours-error")
+
+			err := processor.Process(delivery, logger)
+			Expect(err).To(MatchError(errors.New("some-quiet-hours-error")))
+		})
+	})
+
+	It("renders the message in the user's time zone", func() {
+		quietHours.GetCall.Returns.QuietHours = models.QuietHours{
+			UserGUID: "user-123",
+			TimeZone: "America/New_York",
+		}
+
+		err := processor.Process(delivery, logger)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(packager.PrepareContextCall.Receives.Delivery.TimeZone).To(Equal("America/New_York"))
+	})
+
+	Context("when the campaign is delivered in local business hours", func() {
+		BeforeEach(func() {
+			campaignsRepository.GetCall.Returns.Campaign.BusinessHours = true
+
+			// Pick a fixed-offset zone where it is currently 3am.
+			offset := 3 - time.Now().UTC().Hour()
+			if offset < -12 {
+				offset += 24
+			}
+			if offset > 14 {
+				offset -= 24
+			}
+			quietHours.GetCall.Returns.QuietHours = models.QuietHours{
+				UserGUID: "user-123",
+				TimeZone: fmt.Sprintf("Etc/GMT%+d", -offset),
+			}
+		})
+
+		It("defers the delivery until the start of the user's business day", func() {
+			err := processor.Process(delivery, logger)
+			Expect(err).To(BeAssignableToTypeOf(common.DeliveryDeferredError{}))
+			deferred := err.(common.DeliveryDeferredError)
+
+			location, err := time.LoadLocation(quietHours.GetCall.Returns.QuietHours.TimeZone)
+			Expect(err).NotTo(HaveOccurred())
+
+			until := deferred.Until.In(location)
+			Expect(until.Hour()).To(Equal(9))
+			Expect(until.Minute()).To(Equal(0))
+			Expect(until.Weekday()).NotTo(Equal(time.Saturday))
+			Expect(until.Weekday()).NotTo(Equal(time.Sunday))
+			Expect(mailClient.SendCall.CallCount).To(Equal(0))
+		})
+
+		It("still sends notifications of critical campaign types immediately", func() {
+			campaignTypesRepository.GetCall.Returns.CampaignType.Critical = true
+
+			err := processor.Process(delivery, logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mailClient.SendCall.CallCount).To(Equal(1))
+		})
+	})
+
 	Context("failure cases", func() {
+		Context("when the global unsubscribes repository has an error", func() {
+			It("returns the error", func() {
+				globalUnsubscribes.GetCall.Returns.Error = errors.New("some-global-unsubscribes-error")
+
+				err := processor.Process(delivery, logger)
+				Expect(err).To(MatchError(errors.New("some-global-unsubscribes-error")))
+			})
+		})
+
+		Context("when the campaign type cannot be loaded for a globally unsubscribed user", func() {
+			It("returns the error", func() {
+				globalUnsubscribes.GetCall.Returns.Unsubscribed = true
+				campaignTypesRepository.GetCall.Returns.Error = errors.New("some-campaign-types-error")
+
+				err := processor.Process(delivery, logger)
+				Expect(err).To(MatchError(errors.New("some-campaign-types-error")))
+			})
+		})
+
 		Context("when the campaigns repository has an error", func() {
 			It("returns the error", func() {
 				campaignsRepository.GetCall.Returns.Error = errors.New("some-campaigns-repository-error")
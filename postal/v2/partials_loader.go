@@ -0,0 +1,82 @@
+package v2
+
+import (
+	"sync"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+)
+
+const partialsCacheTTL = 30 * time.Second
+
+type partialsLister interface {
+	List(connection collections.ConnectionInterface, clientID string) ([]collections.Partial, error)
+}
+
+type cachedPartials struct {
+	partials  []common.Partial
+	expiresAt time.Time
+}
+
+type PartialsLoader struct {
+	database           db.DatabaseInterface
+	partialsCollection partialsLister
+	clock              clock
+	mutex              *sync.Mutex
+	cache              map[string]cachedPartials
+}
+
+func NewPartialsLoader(database db.DatabaseInterface, partialsCollection partialsLister, clock clock) PartialsLoader {
+	return PartialsLoader{
+		database:           database,
+		partialsCollection: partialsCollection,
+		clock:              clock,
+		mutex:              &sync.Mutex{},
+		cache:              map[string]cachedPartials{},
+	}
+}
+
+// LoadPartials returns the partials belonging to the client. Every delivery
+// loads them, so they are cached per client for a short while; edits to a
+// partial reach deliveries once the cached copy expires.
+func (loader PartialsLoader) LoadPartials(clientID string) ([]common.Partial, error) {
+	now := loader.clock.Now()
+
+	loader.mutex.Lock()
+	cached, ok := loader.cache[clientID]
+	loader.mutex.Unlock()
+
+	if ok && now.Before(cached.expiresAt) {
+		return cached.partials, nil
+	}
+
+	partials, err := loader.partialsCollection.List(loader.database.Connection(), clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	var loaded []common.Partial
+	for _, partial := range partials {
+		loaded = append(loaded, common.Partial{
+			Name: partial.Name,
+			Text: partial.Text,
+			HTML: partial.HTML,
+		})
+	}
+
+	loader.mutex.Lock()
+	for id, entry := range loader.cache {
+		if !now.Before(entry.expiresAt) {
+			delete(loader.cache, id)
+		}
+	}
+	loader.cache[clientID] = cachedPartials{
+		partials:  loaded,
+		expiresAt: now.Add(partialsCacheTTL),
+	}
+	loader.mutex.Unlock()
+
+	return loaded, nil
+}
@@ -0,0 +1,102 @@
+package v2_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/postal/v2"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PartialsLoader", func() {
+	var (
+		conn               db.ConnectionInterface
+		database           *mocks.Database
+		partialsCollection *mocks.PartialsCollection
+		clock              *mocks.Clock
+		loader             v2.PartialsLoader
+	)
+
+	BeforeEach(func() {
+		conn = mocks.NewConnection()
+		database = mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = conn
+
+		partialsCollection = mocks.NewPartialsCollection()
+		clock = mocks.NewClock()
+		clock.NowCall.Returns.Time = time.Now()
+
+		loader = v2.NewPartialsLoader(database, partialsCollection, clock)
+	})
+
+	Describe("LoadPartials", func() {
+		It("returns the partials belonging to the client", func() {
+			partialsCollection.ListCall.Returns.Partials = []collections.Partial{
+				{
+					ID:       "footer-id",
+					Name:     "footer",
+					Text:     "-- the team",
+					HTML:     "<footer>the team</footer>",
+					ClientID: "my-client-id",
+				},
+			}
+
+			partials, err := loader.LoadPartials("my-client-id")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(partials).To(Equal([]common.Partial{
+				{
+					Name: "footer",
+					Text: "-- the team",
+					HTML: "<footer>the team</footer>",
+				},
+			}))
+			Expect(partialsCollection.ListCall.Receives.Connection).To(Equal(conn))
+			Expect(partialsCollection.ListCall.Receives.ClientID).To(Equal("my-client-id"))
+		})
+
+		It("caches the partials of each client for a short while", func() {
+			partialsCollection.ListCall.Returns.Partials = []collections.Partial{
+				{Name: "footer", Text: "-- the team"},
+			}
+
+			_, err := loader.LoadPartials("my-client-id")
+			Expect(err).NotTo(HaveOccurred())
+
+			partialsCollection.ListCall.Returns.Partials = []collections.Partial{
+				{Name: "footer", Text: "-- the new team"},
+			}
+
+			partials, err := loader.LoadPartials("my-client-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(partials).To(Equal([]common.Partial{{Name: "footer", Text: "-- the team"}}))
+			Expect(partialsCollection.ListCall.CallCount).To(Equal(1))
+
+			_, err = loader.LoadPartials("other-client-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(partialsCollection.ListCall.CallCount).To(Equal(2))
+
+			clock.NowCall.Returns.Time = clock.NowCall.Returns.Time.Add(31 * time.Second)
+
+			partials, err = loader.LoadPartials("my-client-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(partials).To(Equal([]common.Partial{{Name: "footer", Text: "-- the new team"}}))
+			Expect(partialsCollection.ListCall.CallCount).To(Equal(3))
+		})
+
+		Context("when the partials collection has an error", func() {
+			It("returns the error", func() {
+				partialsCollection.ListCall.Returns.Error = errors.New("some error on the collection")
+
+				_, err := loader.LoadPartials("my-client-id")
+				Expect(err).To(MatchError("some error on the collection"))
+			})
+		})
+	})
+})
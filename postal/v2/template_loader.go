@@ -30,8 +30,9 @@ func (loader TemplatesLoader) LoadTemplates(clientID, kindID, templateID string)
 	}
 
 	return common.Templates{
-		Subject: template.Subject,
-		Text:    template.Text,
-		HTML:    template.HTML,
+		Subject:  template.Subject,
+		Text:     template.Text,
+		HTML:     template.HTML,
+		Metadata: template.Metadata,
 	}, nil
 }
@@ -37,6 +37,7 @@ var _ = Describe("V2TemplateLoader", func() {
 					Text:     "some testing text",
 					Subject:  "some subject",
 					HTML:     "<p>v2 awesome</p>",
+					Metadata: `{"inline_css": true}`,
 					ClientID: "my-client-id",
 				}
 			})
@@ -46,9 +47,10 @@ var _ = Describe("V2TemplateLoader", func() {
 				Expect(err).ToNot(HaveOccurred())
 
 				Expect(templates).To(Equal(common.Templates{
-					HTML:    "<p>v2 awesome</p>",
-					Text:    "some testing text",
-					Subject: "some subject",
+					HTML:     "<p>v2 awesome</p>",
+					Text:     "some testing text",
+					Subject:  "some subject",
+					Metadata: `{"inline_css": true}`,
 				}))
 				Expect(templatesCollection.GetCall.Receives.TemplateID).To(Equal("some-v2-template-id"))
 				Expect(templatesCollection.GetCall.Receives.Connection).To(Equal(conn))
@@ -7,7 +7,8 @@ import (
 
 type Audiences struct {
 	GenerateAudiencesCall struct {
-		Receives struct {
+		CallCount int
+		Receives  struct {
 			Inputs []string
 			Logger lager.Logger
 		}
@@ -23,6 +24,7 @@ func NewAudiences() *Audiences {
 }
 
 func (a *Audiences) GenerateAudiences(inputs []string, logger lager.Logger) ([]horde.Audience, error) {
+	a.GenerateAudiencesCall.CallCount++
 	a.GenerateAudiencesCall.Receives.Inputs = inputs
 	a.GenerateAudiencesCall.Receives.Logger = logger
 
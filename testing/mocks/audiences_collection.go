@@ -0,0 +1,110 @@
+package mocks
+
+import "github.com/cloudfoundry-incubator/notifications/v2/collections"
+
+type AudiencesCollection struct {
+	SetCall struct {
+		Receives struct {
+			Connection collections.ConnectionInterface
+			Audience   collections.Audience
+			ClientID   string
+		}
+		Returns struct {
+			Audience collections.Audience
+			Error    error
+		}
+	}
+
+	UpdateCall struct {
+		Receives struct {
+			Connection collections.ConnectionInterface
+			AudienceID string
+			ClientID   string
+			Update     collections.AudienceUpdate
+		}
+		Returns struct {
+			Audience collections.Audience
+			Error    error
+		}
+	}
+
+	GetCall struct {
+		Receives struct {
+			Connection collections.ConnectionInterface
+			AudienceID string
+			ClientID   string
+		}
+		Returns struct {
+			Audience collections.Audience
+			Error    error
+		}
+	}
+
+	ListCall struct {
+		Receives struct {
+			Connection collections.ConnectionInterface
+			SenderID   string
+			ClientID   string
+		}
+		Returns struct {
+			Audiences []collections.Audience
+			Error     error
+		}
+	}
+
+	DeleteCall struct {
+		Receives struct {
+			Connection collections.ConnectionInterface
+			AudienceID string
+			ClientID   string
+		}
+		Returns struct {
+			Error error
+		}
+	}
+}
+
+func NewAudiencesCollection() *AudiencesCollection {
+	return &AudiencesCollection{}
+}
+
+func (c *AudiencesCollection) Set(conn collections.ConnectionInterface, audience collections.Audience, clientID string) (collections.Audience, error) {
+	c.SetCall.Receives.Connection = conn
+	c.SetCall.Receives.Audience = audience
+	c.SetCall.Receives.ClientID = clientID
+
+	return c.SetCall.Returns.Audience, c.SetCall.Returns.Error
+}
+
+func (c *AudiencesCollection) Update(conn collections.ConnectionInterface, audienceID, clientID string, update collections.AudienceUpdate) (collections.Audience, error) {
+	c.UpdateCall.Receives.Connection = conn
+	c.UpdateCall.Receives.AudienceID = audienceID
+	c.UpdateCall.Receives.ClientID = clientID
+	c.UpdateCall.Receives.Update = update
+
+	return c.UpdateCall.Returns.Audience, c.UpdateCall.Returns.Error
+}
+
+func (c *AudiencesCollection) Get(conn collections.ConnectionInterface, audienceID, clientID string) (collections.Audience, error) {
+	c.GetCall.Receives.Connection = conn
+	c.GetCall.Receives.AudienceID = audienceID
+	c.GetCall.Receives.ClientID = clientID
+
+	return c.GetCall.Returns.Audience, c.GetCall.Returns.Error
+}
+
+func (c *AudiencesCollection) List(conn collections.ConnectionInterface, senderID, clientID string) ([]collections.Audience, error) {
+	c.ListCall.Receives.Connection = conn
+	c.ListCall.Receives.SenderID = senderID
+	c.ListCall.Receives.ClientID = clientID
+
+	return c.ListCall.Returns.Audiences, c.ListCall.Returns.Error
+}
+
+func (c *AudiencesCollection) Delete(conn collections.ConnectionInterface, audienceID, clientID string) error {
+	c.DeleteCall.Receives.Connection = conn
+	c.DeleteCall.Receives.AudienceID = audienceID
+	c.DeleteCall.Receives.ClientID = clientID
+
+	return c.DeleteCall.Returns.Error
+}
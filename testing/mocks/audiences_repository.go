@@ -0,0 +1,136 @@
+package mocks
+
+import "github.com/cloudfoundry-incubator/notifications/v2/models"
+
+type AudiencesRepository struct {
+	InsertCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			Audience   models.Audience
+		}
+		Returns struct {
+			Audience models.Audience
+			Error    error
+		}
+	}
+
+	UpdateCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			Audience   models.Audience
+		}
+		Returns struct {
+			Audience models.Audience
+			Error    error
+		}
+	}
+
+	GetCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			AudienceID string
+		}
+		Returns struct {
+			Audience models.Audience
+			Error    error
+		}
+	}
+
+	GetForUpdateCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			AudienceID string
+		}
+		Returns struct {
+			Audience models.Audience
+			Error    error
+		}
+	}
+
+	GetVersionCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			AudienceID string
+			Version    int
+		}
+		Returns struct {
+			AudienceVersion models.AudienceVersion
+			Error           error
+		}
+	}
+
+	ListCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			SenderID   string
+		}
+		Returns struct {
+			Audiences []models.Audience
+			Error     error
+		}
+	}
+
+	DeleteCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			Audience   models.Audience
+		}
+		Returns struct {
+			Error error
+		}
+	}
+}
+
+func NewAudiencesRepository() *AudiencesRepository {
+	return &AudiencesRepository{}
+}
+
+func (r *AudiencesRepository) Insert(conn models.ConnectionInterface, audience models.Audience) (models.Audience, error) {
+	r.InsertCall.Receives.Connection = conn
+	r.InsertCall.Receives.Audience = audience
+
+	return r.InsertCall.Returns.Audience, r.InsertCall.Returns.Error
+}
+
+func (r *AudiencesRepository) Update(conn models.ConnectionInterface, audience models.Audience) (models.Audience, error) {
+	r.UpdateCall.Receives.Connection = conn
+	r.UpdateCall.Receives.Audience = audience
+
+	return r.UpdateCall.Returns.Audience, r.UpdateCall.Returns.Error
+}
+
+func (r *AudiencesRepository) Get(conn models.ConnectionInterface, audienceID string) (models.Audience, error) {
+	r.GetCall.Receives.Connection = conn
+	r.GetCall.Receives.AudienceID = audienceID
+
+	return r.GetCall.Returns.Audience, r.GetCall.Returns.Error
+}
+
+func (r *AudiencesRepository) GetForUpdate(conn models.ConnectionInterface, audienceID string) (models.Audience, error) {
+	r.GetForUpdateCall.Receives.Connection = conn
+	r.GetForUpdateCall.Receives.AudienceID = audienceID
+
+	return r.GetForUpdateCall.Returns.Audience, r.GetForUpdateCall.Returns.Error
+}
+
+func (r *AudiencesRepository) GetVersion(conn models.ConnectionInterface, audienceID string, version int) (models.AudienceVersion, error) {
+	r.GetVersionCall.Receives.Connection = conn
+	r.GetVersionCall.Receives.AudienceID = audienceID
+	r.GetVersionCall.Receives.Version = version
+
+	return r.GetVersionCall.Returns.AudienceVersion, r.GetVersionCall.Returns.Error
+}
+
+func (r *AudiencesRepository) List(conn models.ConnectionInterface, senderID string) ([]models.Audience, error) {
+	r.ListCall.Receives.Connection = conn
+	r.ListCall.Receives.SenderID = senderID
+
+	return r.ListCall.Returns.Audiences, r.ListCall.Returns.Error
+}
+
+func (r *AudiencesRepository) Delete(conn models.ConnectionInterface, audience models.Audience) error {
+	r.DeleteCall.Receives.Connection = conn
+	r.DeleteCall.Receives.Audience = audience
+
+	return r.DeleteCall.Returns.Error
+}
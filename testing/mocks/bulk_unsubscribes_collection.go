@@ -0,0 +1,52 @@
+package mocks
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+)
+
+type BulkUnsubscribesCollection struct {
+	ImportCall struct {
+		Receives struct {
+			Connection   collections.ConnectionInterface
+			Unsubscribes []collections.BulkUnsubscribe
+			Actor        models.Actor
+		}
+		Returns struct {
+			Result collections.BulkUnsubscribeResult
+			Error  error
+		}
+	}
+
+	ExportCall struct {
+		Receives struct {
+			Connection collections.ConnectionInterface
+			Offset     int
+			Limit      int
+		}
+		Returns struct {
+			Unsubscribes []collections.BulkUnsubscribe
+			Error        error
+		}
+	}
+}
+
+func NewBulkUnsubscribesCollection() *BulkUnsubscribesCollection {
+	return &BulkUnsubscribesCollection{}
+}
+
+func (c *BulkUnsubscribesCollection) Import(conn collections.ConnectionInterface, unsubscribes []collections.BulkUnsubscribe, actor models.Actor) (collections.BulkUnsubscribeResult, error) {
+	c.ImportCall.Receives.Connection = conn
+	c.ImportCall.Receives.Unsubscribes = unsubscribes
+	c.ImportCall.Receives.Actor = actor
+
+	return c.ImportCall.Returns.Result, c.ImportCall.Returns.Error
+}
+
+func (c *BulkUnsubscribesCollection) Export(conn collections.ConnectionInterface, offset, limit int) ([]collections.BulkUnsubscribe, error) {
+	c.ExportCall.Receives.Connection = conn
+	c.ExportCall.Receives.Offset = offset
+	c.ExportCall.Receives.Limit = limit
+
+	return c.ExportCall.Returns.Unsubscribes, c.ExportCall.Returns.Error
+}
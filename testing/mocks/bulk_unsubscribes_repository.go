@@ -0,0 +1,29 @@
+package mocks
+
+import "github.com/cloudfoundry-incubator/notifications/v2/models"
+
+type BulkUnsubscribesRepository struct {
+	ListCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			Offset     int
+			Limit      int
+		}
+		Returns struct {
+			Unsubscribers []models.BulkUnsubscriber
+			Error         error
+		}
+	}
+}
+
+func NewBulkUnsubscribesRepository() *BulkUnsubscribesRepository {
+	return &BulkUnsubscribesRepository{}
+}
+
+func (r *BulkUnsubscribesRepository) List(conn models.ConnectionInterface, offset, limit int) ([]models.BulkUnsubscriber, error) {
+	r.ListCall.Receives.Connection = conn
+	r.ListCall.Receives.Offset = offset
+	r.ListCall.Receives.Limit = limit
+
+	return r.ListCall.Returns.Unsubscribers, r.ListCall.Returns.Error
+}
@@ -0,0 +1,52 @@
+package mocks
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/v2/bundle"
+)
+
+type Bundler struct {
+	ExportCall struct {
+		Receives struct {
+			Connection db.ConnectionInterface
+			ClientID   string
+		}
+		Returns struct {
+			Bundle bundle.Bundle
+			Error  error
+		}
+	}
+
+	ImportCall struct {
+		Receives struct {
+			Connection db.ConnectionInterface
+			ClientID   string
+			Bundle     bundle.Bundle
+			DryRun     bool
+		}
+		Returns struct {
+			Diff  bundle.Diff
+			Error error
+		}
+	}
+}
+
+func NewBundler() *Bundler {
+	return &Bundler{}
+}
+
+func (b *Bundler) Export(conn db.ConnectionInterface, clientID string) (bundle.Bundle, error) {
+	b.ExportCall.Receives.Connection = conn
+	b.ExportCall.Receives.ClientID = clientID
+
+	return b.ExportCall.Returns.Bundle, b.ExportCall.Returns.Error
+}
+
+func (b *Bundler) Import(conn db.ConnectionInterface, clientID string, imported bundle.Bundle, dryRun bool) (bundle.Diff, error) {
+	b.ImportCall.Receives.Connection = conn
+	b.ImportCall.Receives.ClientID = clientID
+	b.ImportCall.Receives.Bundle = imported
+	b.ImportCall.Receives.DryRun = dryRun
+
+	return b.ImportCall.Returns.Diff, b.ImportCall.Returns.Error
+}
@@ -0,0 +1,73 @@
+package mocks
+
+import "time"
+
+type CacheStore struct {
+	GetCall struct {
+		Receives struct {
+			Key string
+		}
+		Returns struct {
+			Value []byte
+			Found bool
+			Error error
+		}
+	}
+
+	SetCall struct {
+		WasCalled bool
+		Receives  struct {
+			Key       string
+			Value     []byte
+			ExpiresAt time.Time
+		}
+		Returns struct {
+			Error error
+		}
+	}
+
+	SweepCall struct {
+		CallCount int
+		Returns   struct {
+			Error error
+		}
+	}
+
+	FlushCall struct {
+		WasCalled bool
+		Returns   struct {
+			Error error
+		}
+	}
+}
+
+func NewCacheStore() *CacheStore {
+	return &CacheStore{}
+}
+
+func (s *CacheStore) Get(key string) ([]byte, bool, error) {
+	s.GetCall.Receives.Key = key
+
+	return s.GetCall.Returns.Value, s.GetCall.Returns.Found, s.GetCall.Returns.Error
+}
+
+func (s *CacheStore) Set(key string, value []byte, expiresAt time.Time) error {
+	s.SetCall.WasCalled = true
+	s.SetCall.Receives.Key = key
+	s.SetCall.Receives.Value = value
+	s.SetCall.Receives.ExpiresAt = expiresAt
+
+	return s.SetCall.Returns.Error
+}
+
+func (s *CacheStore) Sweep() error {
+	s.SweepCall.CallCount++
+
+	return s.SweepCall.Returns.Error
+}
+
+func (s *CacheStore) Flush() error {
+	s.FlushCall.WasCalled = true
+
+	return s.FlushCall.Returns.Error
+}
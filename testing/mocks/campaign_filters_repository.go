@@ -0,0 +1,73 @@
+package mocks
+
+import "github.com/cloudfoundry-incubator/notifications/v2/models"
+
+type CampaignFiltersRepository struct {
+	InsertCall struct {
+		CallCount int
+		Receives  struct {
+			Connection models.ConnectionInterface
+			Filters    []models.CampaignFilter
+		}
+		Returns struct {
+			Error error
+		}
+	}
+
+	ListCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			CampaignID string
+			Recipients []string
+		}
+		Returns struct {
+			Filters []models.CampaignFilter
+			Error   error
+		}
+	}
+
+	RecordExcludedCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			CampaignID string
+			Recipients []string
+		}
+		Returns struct {
+			Error error
+		}
+	}
+}
+
+func NewCampaignFiltersRepository() *CampaignFiltersRepository {
+	return &CampaignFiltersRepository{}
+}
+
+func (r *CampaignFiltersRepository) Insert(conn models.ConnectionInterface, campaignID, kind string, recipients []string) error {
+	r.InsertCall.CallCount++
+	r.InsertCall.Receives.Connection = conn
+	for _, recipient := range recipients {
+		r.InsertCall.Receives.Filters = append(r.InsertCall.Receives.Filters, models.CampaignFilter{
+			CampaignID: campaignID,
+			Kind:       kind,
+			Recipient:  recipient,
+		})
+	}
+
+	return r.InsertCall.Returns.Error
+}
+
+func (r *CampaignFiltersRepository) List(conn models.ConnectionInterface, campaignID string, recipients []string) ([]models.CampaignFilter, error) {
+	r.ListCall.Receives.Connection = conn
+	r.ListCall.Receives.CampaignID = campaignID
+	r.ListCall.Receives.Recipients = recipients
+
+	return r.ListCall.Returns.Filters, r.ListCall.Returns.Error
+}
+
+func (r *CampaignFiltersRepository) RecordExcluded(conn models.ConnectionInterface, campaignID string, recipients []string) error {
+	r.RecordExcludedCall.Receives.Connection = conn
+	r.RecordExcludedCall.Receives.CampaignID = campaignID
+	r.RecordExcludedCall.Receives.Recipients = append(r.RecordExcludedCall.Receives.Recipients, recipients...)
+
+	return r.RecordExcludedCall.Returns.Error
+}
@@ -0,0 +1,36 @@
+package mocks
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/pivotal-golang/lager"
+)
+
+type CampaignPreviewsCollection struct {
+	PreviewCall struct {
+		Receives struct {
+			Connection collections.ConnectionInterface
+			Campaign   collections.Campaign
+			ClientID   string
+			Logger     lager.Logger
+		}
+		Returns struct {
+			Preview collections.CampaignPreview
+			Error   error
+		}
+		WasCalled bool
+	}
+}
+
+func NewCampaignPreviewsCollection() *CampaignPreviewsCollection {
+	return &CampaignPreviewsCollection{}
+}
+
+func (c *CampaignPreviewsCollection) Preview(conn collections.ConnectionInterface, campaign collections.Campaign, clientID string, logger lager.Logger) (collections.CampaignPreview, error) {
+	c.PreviewCall.Receives.Connection = conn
+	c.PreviewCall.Receives.Campaign = campaign
+	c.PreviewCall.Receives.ClientID = clientID
+	c.PreviewCall.Receives.Logger = logger
+	c.PreviewCall.WasCalled = true
+
+	return c.PreviewCall.Returns.Preview, c.PreviewCall.Returns.Error
+}
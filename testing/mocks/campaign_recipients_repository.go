@@ -0,0 +1,48 @@
+package mocks
+
+import "github.com/cloudfoundry-incubator/notifications/v2/models"
+
+type CampaignRecipientsRepository struct {
+	InsertCall struct {
+		CallCount int
+		Receives  struct {
+			Connection models.ConnectionInterface
+			Recipients []models.CampaignRecipient
+		}
+		Returns struct {
+			Error error
+		}
+	}
+
+	ListExistingCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			CampaignID string
+			Recipients []string
+		}
+		Returns struct {
+			Existing []string
+			Error    error
+		}
+	}
+}
+
+func NewCampaignRecipientsRepository() *CampaignRecipientsRepository {
+	return &CampaignRecipientsRepository{}
+}
+
+func (r *CampaignRecipientsRepository) Insert(conn models.ConnectionInterface, recipient models.CampaignRecipient) error {
+	r.InsertCall.CallCount++
+	r.InsertCall.Receives.Connection = conn
+	r.InsertCall.Receives.Recipients = append(r.InsertCall.Receives.Recipients, recipient)
+
+	return r.InsertCall.Returns.Error
+}
+
+func (r *CampaignRecipientsRepository) ListExisting(conn models.ConnectionInterface, campaignID string, recipients []string) ([]string, error) {
+	r.ListExistingCall.Receives.Connection = conn
+	r.ListExistingCall.Receives.CampaignID = campaignID
+	r.ListExistingCall.Receives.Recipients = recipients
+
+	return r.ListExistingCall.Returns.Existing, r.ListExistingCall.Returns.Error
+}
@@ -25,8 +25,31 @@ type CampaignTypesRepository struct {
 		}
 	}
 
-	GetCall struct {
+	ListNonCriticalCall struct {
 		Receives struct {
+			Connection models.ConnectionInterface
+		}
+		Returns struct {
+			CampaignTypeList []models.CampaignType
+			Error            error
+		}
+	}
+
+	ListByTemplateIDCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			TemplateID string
+			ClientID   string
+		}
+		Returns struct {
+			CampaignTypeList []models.CampaignType
+			Error            error
+		}
+	}
+
+	GetCall struct {
+		CallCount int
+		Receives  struct {
 			Connection     models.ConnectionInterface
 			CampaignTypeID string
 		}
@@ -81,6 +104,7 @@ func (r *CampaignTypesRepository) List(conn models.ConnectionInterface, senderID
 }
 
 func (r *CampaignTypesRepository) Get(conn models.ConnectionInterface, campaignTypeID string) (models.CampaignType, error) {
+	r.GetCall.CallCount++
 	r.GetCall.Receives.Connection = conn
 	r.GetCall.Receives.CampaignTypeID = campaignTypeID
 
@@ -100,3 +124,17 @@ func (r *CampaignTypesRepository) Delete(conn models.ConnectionInterface, campai
 
 	return r.DeleteCall.Returns.Error
 }
+
+func (r *CampaignTypesRepository) ListByTemplateID(conn models.ConnectionInterface, templateID, clientID string) ([]models.CampaignType, error) {
+	r.ListByTemplateIDCall.Receives.Connection = conn
+	r.ListByTemplateIDCall.Receives.TemplateID = templateID
+	r.ListByTemplateIDCall.Receives.ClientID = clientID
+
+	return r.ListByTemplateIDCall.Returns.CampaignTypeList, r.ListByTemplateIDCall.Returns.Error
+}
+
+func (r *CampaignTypesRepository) ListNonCritical(conn models.ConnectionInterface) ([]models.CampaignType, error) {
+	r.ListNonCriticalCall.Receives.Connection = conn
+
+	return r.ListNonCriticalCall.Returns.CampaignTypeList, r.ListNonCriticalCall.Returns.Error
+}
@@ -17,6 +17,19 @@ type CampaignsCollection struct {
 		WasCalled bool
 	}
 
+	ValidateCall struct {
+		Receives struct {
+			Connection       collections.ConnectionInterface
+			Campaign         collections.Campaign
+			ClientID         string
+			HasCriticalScope bool
+		}
+		Returns struct {
+			Campaign collections.Campaign
+			Error    error
+		}
+	}
+
 	GetCall struct {
 		Receives struct {
 			Connection collections.ConnectionInterface
@@ -51,3 +64,12 @@ func (c *CampaignsCollection) Get(connection collections.ConnectionInterface, ca
 
 	return c.GetCall.Returns.Campaign, c.GetCall.Returns.Error
 }
+
+func (c *CampaignsCollection) Validate(conn collections.ConnectionInterface, campaign collections.Campaign, clientID string, hasCriticalScope bool) (collections.Campaign, error) {
+	c.ValidateCall.Receives.Connection = conn
+	c.ValidateCall.Receives.Campaign = campaign
+	c.ValidateCall.Receives.ClientID = clientID
+	c.ValidateCall.Receives.HasCriticalScope = hasCriticalScope
+
+	return c.ValidateCall.Returns.Campaign, c.ValidateCall.Returns.Error
+}
@@ -40,6 +40,65 @@ type CampaignsRepository struct {
 		}
 	}
 
+	ListByTemplateIDCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			TemplateID string
+			ClientID   string
+			Since      time.Time
+		}
+		Returns struct {
+			Campaigns []models.Campaign
+			Error     error
+		}
+	}
+
+	UpdateExcludedCountCall struct {
+		CallCount int
+		Receives  struct {
+			Connection models.ConnectionInterface
+			CampaignID string
+		}
+		Returns struct {
+			Error error
+		}
+	}
+
+	SetFanoutProgressCall struct {
+		CallCount int
+		Receives  struct {
+			Connection models.ConnectionInterface
+			CampaignID string
+			Progress   int
+		}
+		Returns struct {
+			Error error
+		}
+	}
+
+	StartFanoutCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			CampaignID string
+			StartedAt  time.Time
+		}
+		Returns struct {
+			Error error
+		}
+	}
+
+	CompleteFanoutCall struct {
+		CallCount int
+		Receives  struct {
+			Connection  models.ConnectionInterface
+			CampaignID  string
+			CompletedAt time.Time
+		}
+		Returns struct {
+			Error error
+		}
+	}
+
 	UpdateCall struct {
 		Receives struct {
 			Connection   models.ConnectionInterface
@@ -77,9 +136,52 @@ func (r *CampaignsRepository) ListSendingCampaigns(conn models.ConnectionInterfa
 	return r.ListSendingCampaignsCall.Returns.Campaigns, r.ListSendingCampaignsCall.Returns.Error
 }
 
+func (r *CampaignsRepository) UpdateExcludedCount(conn models.ConnectionInterface, campaignID string) error {
+	r.UpdateExcludedCountCall.CallCount++
+	r.UpdateExcludedCountCall.Receives.Connection = conn
+	r.UpdateExcludedCountCall.Receives.CampaignID = campaignID
+
+	return r.UpdateExcludedCountCall.Returns.Error
+}
+
+func (r *CampaignsRepository) SetFanoutProgress(conn models.ConnectionInterface, campaignID string, progress int) error {
+	r.SetFanoutProgressCall.CallCount++
+	r.SetFanoutProgressCall.Receives.Connection = conn
+	r.SetFanoutProgressCall.Receives.CampaignID = campaignID
+	r.SetFanoutProgressCall.Receives.Progress = progress
+
+	return r.SetFanoutProgressCall.Returns.Error
+}
+
+func (r *CampaignsRepository) StartFanout(conn models.ConnectionInterface, campaignID string, startedAt time.Time) error {
+	r.StartFanoutCall.Receives.Connection = conn
+	r.StartFanoutCall.Receives.CampaignID = campaignID
+	r.StartFanoutCall.Receives.StartedAt = startedAt
+
+	return r.StartFanoutCall.Returns.Error
+}
+
+func (r *CampaignsRepository) CompleteFanout(conn models.ConnectionInterface, campaignID string, completedAt time.Time) error {
+	r.CompleteFanoutCall.CallCount++
+	r.CompleteFanoutCall.Receives.Connection = conn
+	r.CompleteFanoutCall.Receives.CampaignID = campaignID
+	r.CompleteFanoutCall.Receives.CompletedAt = completedAt
+
+	return r.CompleteFanoutCall.Returns.Error
+}
+
 func (r *CampaignsRepository) Update(conn models.ConnectionInterface, campaign models.Campaign) (models.Campaign, error) {
 	r.UpdateCall.Receives.Connection = conn
 	r.UpdateCall.Receives.CampaignList = append(r.UpdateCall.Receives.CampaignList, campaign)
 
 	return r.UpdateCall.Returns.Campaign, r.UpdateCall.Returns.Error
 }
+
+func (r *CampaignsRepository) ListByTemplateID(conn models.ConnectionInterface, templateID, clientID string, since time.Time) ([]models.Campaign, error) {
+	r.ListByTemplateIDCall.Receives.Connection = conn
+	r.ListByTemplateIDCall.Receives.TemplateID = templateID
+	r.ListByTemplateIDCall.Receives.ClientID = clientID
+	r.ListByTemplateIDCall.Receives.Since = since
+
+	return r.ListByTemplateIDCall.Returns.Campaigns, r.ListByTemplateIDCall.Returns.Error
+}
@@ -47,6 +47,39 @@ type CloudController struct {
 		}
 	}
 
+	GetAuditorsBySpaceGuidCall struct {
+		Receives struct {
+			SpaceGUID string
+			Token     string
+		}
+		Returns struct {
+			Users []cf.CloudControllerUser
+			Error error
+		}
+	}
+
+	GetDevelopersBySpaceGuidCall struct {
+		Receives struct {
+			SpaceGUID string
+			Token     string
+		}
+		Returns struct {
+			Users []cf.CloudControllerUser
+			Error error
+		}
+	}
+
+	GetManagersBySpaceGuidCall struct {
+		Receives struct {
+			SpaceGUID string
+			Token     string
+		}
+		Returns struct {
+			Users []cf.CloudControllerUser
+			Error error
+		}
+	}
+
 	GetUsersBySpaceGuidCall struct {
 		Receives struct {
 			SpaceGUID string
@@ -56,6 +89,7 @@ type CloudController struct {
 			Users []cf.CloudControllerUser
 			Error error
 		}
+		CallCount int
 	}
 
 	LoadOrganizationCall struct {
@@ -67,6 +101,7 @@ type CloudController struct {
 			Organization cf.CloudControllerOrganization
 			Error        error
 		}
+		CallCount int
 	}
 
 	LoadSpaceCall struct {
@@ -78,6 +113,7 @@ type CloudController struct {
 			Space cf.CloudControllerSpace
 			Error error
 		}
+		CallCount int
 	}
 }
 
@@ -113,9 +149,31 @@ func (cc *CloudController) GetUsersByOrgGuid(orgGUID, token string) ([]cf.CloudC
 	return cc.GetUsersByOrgGuidCall.Returns.Users, cc.GetUsersByOrgGuidCall.Returns.Error
 }
 
+func (cc *CloudController) GetAuditorsBySpaceGuid(spaceGUID, token string) ([]cf.CloudControllerUser, error) {
+	cc.GetAuditorsBySpaceGuidCall.Receives.SpaceGUID = spaceGUID
+	cc.GetAuditorsBySpaceGuidCall.Receives.Token = token
+
+	return cc.GetAuditorsBySpaceGuidCall.Returns.Users, cc.GetAuditorsBySpaceGuidCall.Returns.Error
+}
+
+func (cc *CloudController) GetDevelopersBySpaceGuid(spaceGUID, token string) ([]cf.CloudControllerUser, error) {
+	cc.GetDevelopersBySpaceGuidCall.Receives.SpaceGUID = spaceGUID
+	cc.GetDevelopersBySpaceGuidCall.Receives.Token = token
+
+	return cc.GetDevelopersBySpaceGuidCall.Returns.Users, cc.GetDevelopersBySpaceGuidCall.Returns.Error
+}
+
+func (cc *CloudController) GetManagersBySpaceGuid(spaceGUID, token string) ([]cf.CloudControllerUser, error) {
+	cc.GetManagersBySpaceGuidCall.Receives.SpaceGUID = spaceGUID
+	cc.GetManagersBySpaceGuidCall.Receives.Token = token
+
+	return cc.GetManagersBySpaceGuidCall.Returns.Users, cc.GetManagersBySpaceGuidCall.Returns.Error
+}
+
 func (cc *CloudController) GetUsersBySpaceGuid(spaceGUID, token string) ([]cf.CloudControllerUser, error) {
 	cc.GetUsersBySpaceGuidCall.Receives.SpaceGUID = spaceGUID
 	cc.GetUsersBySpaceGuidCall.Receives.Token = token
+	cc.GetUsersBySpaceGuidCall.CallCount++
 
 	return cc.GetUsersBySpaceGuidCall.Returns.Users, cc.GetUsersBySpaceGuidCall.Returns.Error
 }
@@ -123,6 +181,7 @@ func (cc *CloudController) GetUsersBySpaceGuid(spaceGUID, token string) ([]cf.Cl
 func (cc *CloudController) LoadOrganization(orgGUID, token string) (cf.CloudControllerOrganization, error) {
 	cc.LoadOrganizationCall.Receives.OrgGUID = orgGUID
 	cc.LoadOrganizationCall.Receives.Token = token
+	cc.LoadOrganizationCall.CallCount++
 
 	return cc.LoadOrganizationCall.Returns.Organization, cc.LoadOrganizationCall.Returns.Error
 }
@@ -130,6 +189,7 @@ func (cc *CloudController) LoadOrganization(orgGUID, token string) (cf.CloudCont
 func (cc *CloudController) LoadSpace(spaceGUID, token string) (cf.CloudControllerSpace, error) {
 	cc.LoadSpaceCall.Receives.SpaceGUID = spaceGUID
 	cc.LoadSpaceCall.Receives.Token = token
+	cc.LoadSpaceCall.CallCount++
 
 	return cc.LoadSpaceCall.Returns.Space, cc.LoadSpaceCall.Returns.Error
 }
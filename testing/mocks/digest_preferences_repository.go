@@ -0,0 +1,68 @@
+package mocks
+
+import "github.com/cloudfoundry-incubator/notifications/v2/models"
+
+type DigestPreferencesRepository struct {
+	SetCall struct {
+		CallCount int
+		Receives  struct {
+			Connection  models.ConnectionInterface
+			Preferences []models.DigestPreference
+		}
+		Returns struct {
+			Error error
+		}
+	}
+
+	GetCall struct {
+		Receives struct {
+			Connection     models.ConnectionInterface
+			UserGUID       string
+			ClientID       string
+			CampaignTypeID string
+		}
+		Returns struct {
+			Frequency string
+			Error     error
+		}
+	}
+
+	ListByUserGUIDCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			UserGUID   string
+		}
+		Returns struct {
+			Preferences []models.DigestPreference
+			Error       error
+		}
+	}
+}
+
+func NewDigestPreferencesRepository() *DigestPreferencesRepository {
+	return &DigestPreferencesRepository{}
+}
+
+func (r *DigestPreferencesRepository) Set(conn models.ConnectionInterface, preference models.DigestPreference) error {
+	r.SetCall.CallCount++
+	r.SetCall.Receives.Connection = conn
+	r.SetCall.Receives.Preferences = append(r.SetCall.Receives.Preferences, preference)
+
+	return r.SetCall.Returns.Error
+}
+
+func (r *DigestPreferencesRepository) Get(conn models.ConnectionInterface, userGUID, clientID, campaignTypeID string) (string, error) {
+	r.GetCall.Receives.Connection = conn
+	r.GetCall.Receives.UserGUID = userGUID
+	r.GetCall.Receives.ClientID = clientID
+	r.GetCall.Receives.CampaignTypeID = campaignTypeID
+
+	return r.GetCall.Returns.Frequency, r.GetCall.Returns.Error
+}
+
+func (r *DigestPreferencesRepository) ListByUserGUID(conn models.ConnectionInterface, userGUID string) ([]models.DigestPreference, error) {
+	r.ListByUserGUIDCall.Receives.Connection = conn
+	r.ListByUserGUIDCall.Receives.UserGUID = userGUID
+
+	return r.ListByUserGUIDCall.Returns.Preferences, r.ListByUserGUIDCall.Returns.Error
+}
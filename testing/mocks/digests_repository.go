@@ -0,0 +1,68 @@
+package mocks
+
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+)
+
+type DigestsRepository struct {
+	InsertCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			Digest     models.Digest
+		}
+		Returns struct {
+			Digest models.Digest
+			Error  error
+		}
+	}
+
+	ListDueCall struct {
+		Receives struct {
+			Connection  models.ConnectionInterface
+			Frequencies []string
+			Thresholds  []time.Time
+		}
+		Returns struct {
+			Digests map[string][]models.Digest
+			Error   error
+		}
+	}
+
+	DeleteCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			Digests    []models.Digest
+		}
+		Returns struct {
+			Error error
+		}
+	}
+}
+
+func NewDigestsRepository() *DigestsRepository {
+	return &DigestsRepository{}
+}
+
+func (r *DigestsRepository) Insert(conn models.ConnectionInterface, digest models.Digest) (models.Digest, error) {
+	r.InsertCall.Receives.Connection = conn
+	r.InsertCall.Receives.Digest = digest
+
+	return r.InsertCall.Returns.Digest, r.InsertCall.Returns.Error
+}
+
+func (r *DigestsRepository) ListDue(conn models.ConnectionInterface, frequency string, threshold time.Time) ([]models.Digest, error) {
+	r.ListDueCall.Receives.Connection = conn
+	r.ListDueCall.Receives.Frequencies = append(r.ListDueCall.Receives.Frequencies, frequency)
+	r.ListDueCall.Receives.Thresholds = append(r.ListDueCall.Receives.Thresholds, threshold)
+
+	return r.ListDueCall.Returns.Digests[frequency], r.ListDueCall.Returns.Error
+}
+
+func (r *DigestsRepository) Delete(conn models.ConnectionInterface, digest models.Digest) error {
+	r.DeleteCall.Receives.Connection = conn
+	r.DeleteCall.Receives.Digests = append(r.DeleteCall.Receives.Digests, digest)
+
+	return r.DeleteCall.Returns.Error
+}
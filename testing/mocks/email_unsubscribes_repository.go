@@ -0,0 +1,52 @@
+package mocks
+
+import "github.com/cloudfoundry-incubator/notifications/v2/models"
+
+type EmailUnsubscribesRepository struct {
+	InsertCall struct {
+		Receives struct {
+			Connection     models.ConnectionInterface
+			Email          string
+			ClientID       string
+			CampaignTypeID string
+		}
+		Returns struct {
+			Error error
+		}
+	}
+
+	GetCall struct {
+		Receives struct {
+			Connection     models.ConnectionInterface
+			Email          string
+			ClientID       string
+			CampaignTypeID string
+		}
+		Returns struct {
+			Unsubscribed bool
+			Error        error
+		}
+	}
+}
+
+func NewEmailUnsubscribesRepository() *EmailUnsubscribesRepository {
+	return &EmailUnsubscribesRepository{}
+}
+
+func (r *EmailUnsubscribesRepository) Insert(conn models.ConnectionInterface, email, clientID, campaignTypeID string) error {
+	r.InsertCall.Receives.Connection = conn
+	r.InsertCall.Receives.Email = email
+	r.InsertCall.Receives.ClientID = clientID
+	r.InsertCall.Receives.CampaignTypeID = campaignTypeID
+
+	return r.InsertCall.Returns.Error
+}
+
+func (r *EmailUnsubscribesRepository) Get(conn models.ConnectionInterface, email, clientID, campaignTypeID string) (bool, error) {
+	r.GetCall.Receives.Connection = conn
+	r.GetCall.Receives.Email = email
+	r.GetCall.Receives.ClientID = clientID
+	r.GetCall.Receives.CampaignTypeID = campaignTypeID
+
+	return r.GetCall.Returns.Unsubscribed, r.GetCall.Returns.Error
+}
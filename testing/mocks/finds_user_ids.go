@@ -13,6 +13,17 @@ type FindsUserIDs struct {
 		}
 	}
 
+	UserIDsBelongingToGroupCall struct {
+		Receives struct {
+			Token     string
+			GroupName string
+		}
+		Returns struct {
+			UserIDs []string
+			Error   error
+		}
+	}
+
 	UserIDsBelongingToScopeCall struct {
 		Receives struct {
 			Token string
@@ -27,6 +38,7 @@ type FindsUserIDs struct {
 	UserIDsBelongingToSpaceCall struct {
 		Receives struct {
 			SpaceGUID string
+			Role      string
 			Token     string
 		}
 		Returns struct {
@@ -48,6 +60,13 @@ func (f *FindsUserIDs) UserIDsBelongingToOrganization(orgGUID, role, token strin
 	return f.UserIDsBelongingToOrganizationCall.Returns.UserIDs, f.UserIDsBelongingToOrganizationCall.Returns.Error
 }
 
+func (f *FindsUserIDs) UserIDsBelongingToGroup(token, groupName string) ([]string, error) {
+	f.UserIDsBelongingToGroupCall.Receives.Token = token
+	f.UserIDsBelongingToGroupCall.Receives.GroupName = groupName
+
+	return f.UserIDsBelongingToGroupCall.Returns.UserIDs, f.UserIDsBelongingToGroupCall.Returns.Error
+}
+
 func (f *FindsUserIDs) UserIDsBelongingToScope(token, scope string) ([]string, error) {
 	f.UserIDsBelongingToScopeCall.Receives.Token = token
 	f.UserIDsBelongingToScopeCall.Receives.Scope = scope
@@ -55,8 +74,9 @@ func (f *FindsUserIDs) UserIDsBelongingToScope(token, scope string) ([]string, e
 	return f.UserIDsBelongingToScopeCall.Returns.UserIDs, f.UserIDsBelongingToScopeCall.Returns.Error
 }
 
-func (f *FindsUserIDs) UserIDsBelongingToSpace(spaceGUID, token string) ([]string, error) {
+func (f *FindsUserIDs) UserIDsBelongingToSpace(spaceGUID, role, token string) ([]string, error) {
 	f.UserIDsBelongingToSpaceCall.Receives.SpaceGUID = spaceGUID
+	f.UserIDsBelongingToSpaceCall.Receives.Role = role
 	f.UserIDsBelongingToSpaceCall.Receives.Token = token
 
 	return f.UserIDsBelongingToSpaceCall.Returns.UserIDs, f.UserIDsBelongingToSpaceCall.Returns.Error
@@ -0,0 +1,50 @@
+package mocks
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+)
+
+type GlobalUnsubscribersCollection struct {
+	SetCall struct {
+		Receives struct {
+			Connection collections.ConnectionInterface
+			UserGUID   string
+			Actor      models.Actor
+		}
+		Returns struct {
+			Error error
+		}
+	}
+
+	DeleteCall struct {
+		Receives struct {
+			Connection collections.ConnectionInterface
+			UserGUID   string
+			Actor      models.Actor
+		}
+		Returns struct {
+			Error error
+		}
+	}
+}
+
+func NewGlobalUnsubscribersCollection() *GlobalUnsubscribersCollection {
+	return &GlobalUnsubscribersCollection{}
+}
+
+func (c *GlobalUnsubscribersCollection) Set(conn collections.ConnectionInterface, userGUID string, actor models.Actor) error {
+	c.SetCall.Receives.Connection = conn
+	c.SetCall.Receives.UserGUID = userGUID
+	c.SetCall.Receives.Actor = actor
+
+	return c.SetCall.Returns.Error
+}
+
+func (c *GlobalUnsubscribersCollection) Delete(conn collections.ConnectionInterface, userGUID string, actor models.Actor) error {
+	c.DeleteCall.Receives.Connection = conn
+	c.DeleteCall.Receives.UserGUID = userGUID
+	c.DeleteCall.Receives.Actor = actor
+
+	return c.DeleteCall.Returns.Error
+}
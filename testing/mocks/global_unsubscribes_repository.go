@@ -0,0 +1,65 @@
+package mocks
+
+import "github.com/cloudfoundry-incubator/notifications/v2/models"
+
+type GlobalUnsubscribesRepository struct {
+	InsertCall struct {
+		CallCount int
+		Receives  struct {
+			Connection models.ConnectionInterface
+			UserGUID   string
+		}
+		Returns struct {
+			Error error
+		}
+	}
+
+	GetCall struct {
+		CallCount int
+		Receives  struct {
+			Connection models.ConnectionInterface
+			UserGUID   string
+		}
+		Returns struct {
+			Unsubscribed bool
+			Error        error
+		}
+	}
+
+	DeleteCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			UserGUID   string
+		}
+		Returns struct {
+			Error error
+		}
+	}
+}
+
+func NewGlobalUnsubscribesRepository() *GlobalUnsubscribesRepository {
+	return &GlobalUnsubscribesRepository{}
+}
+
+func (r *GlobalUnsubscribesRepository) Insert(conn models.ConnectionInterface, userGUID string) error {
+	r.InsertCall.CallCount++
+	r.InsertCall.Receives.Connection = conn
+	r.InsertCall.Receives.UserGUID = userGUID
+
+	return r.InsertCall.Returns.Error
+}
+
+func (r *GlobalUnsubscribesRepository) Get(conn models.ConnectionInterface, userGUID string) (bool, error) {
+	r.GetCall.CallCount++
+	r.GetCall.Receives.Connection = conn
+	r.GetCall.Receives.UserGUID = userGUID
+
+	return r.GetCall.Returns.Unsubscribed, r.GetCall.Returns.Error
+}
+
+func (r *GlobalUnsubscribesRepository) Delete(conn models.ConnectionInterface, userGUID string) error {
+	r.DeleteCall.Receives.Connection = conn
+	r.DeleteCall.Receives.UserGUID = userGUID
+
+	return r.DeleteCall.Returns.Error
+}
@@ -0,0 +1,25 @@
+package mocks
+
+type GroupMembersFinder struct {
+	MembersCall struct {
+		CallCount int
+		Receives  struct {
+			GroupName string
+		}
+		Returns struct {
+			UserGUIDs []string
+			Error     error
+		}
+	}
+}
+
+func NewGroupMembersFinder() *GroupMembersFinder {
+	return &GroupMembersFinder{}
+}
+
+func (f *GroupMembersFinder) Members(groupName string) ([]string, error) {
+	f.MembersCall.CallCount++
+	f.MembersCall.Receives.GroupName = groupName
+
+	return f.MembersCall.Returns.UserGUIDs, f.MembersCall.Returns.Error
+}
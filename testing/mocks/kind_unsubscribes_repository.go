@@ -0,0 +1,66 @@
+package mocks
+
+import "github.com/cloudfoundry-incubator/notifications/v2/models"
+
+type KindUnsubscribesRepository struct {
+	InsertCall struct {
+		CallCount int
+		Receives  struct {
+			Connection   models.ConnectionInterface
+			Unsubscriber models.KindUnsubscriber
+		}
+		Returns struct {
+			Error error
+		}
+	}
+
+	GetCall struct {
+		Receives struct {
+			Connection   models.ConnectionInterface
+			Unsubscriber models.KindUnsubscriber
+		}
+		Returns struct {
+			Unsubscribed bool
+			Error        error
+		}
+	}
+
+	KindCriticalCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			ClientID   string
+			KindID     string
+		}
+		Returns struct {
+			Critical bool
+			Error    error
+		}
+	}
+}
+
+func NewKindUnsubscribesRepository() *KindUnsubscribesRepository {
+	return &KindUnsubscribesRepository{}
+}
+
+func (r *KindUnsubscribesRepository) Insert(conn models.ConnectionInterface, unsubscriber models.KindUnsubscriber) error {
+	r.InsertCall.CallCount++
+	r.InsertCall.Receives.Connection = conn
+	r.InsertCall.Receives.Unsubscriber = unsubscriber
+
+	return r.InsertCall.Returns.Error
+}
+
+func (r *KindUnsubscribesRepository) Get(conn models.ConnectionInterface, unsubscriber models.KindUnsubscriber) (bool, error) {
+	r.GetCall.Receives.Connection = conn
+	r.GetCall.Receives.Unsubscriber = unsubscriber
+
+	return r.GetCall.Returns.Unsubscribed, r.GetCall.Returns.Error
+}
+
+func (r *KindUnsubscribesRepository) KindCritical(conn models.ConnectionInterface, clientID, kindID string) (bool, error) {
+	r.KindCriticalCall.Receives.Connection = conn
+	r.KindCriticalCall.Receives.ClientID = clientID
+	r.KindCriticalCall.Receives.KindID = kindID
+
+	return r.KindCriticalCall.Returns.Critical, r.KindCriticalCall.Returns.Error
+}
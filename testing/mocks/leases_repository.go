@@ -0,0 +1,35 @@
+package mocks
+
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+)
+
+type LeasesRepository struct {
+	AcquireCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			Name       string
+			Holder     string
+			Duration   time.Duration
+		}
+		Returns struct {
+			Acquired bool
+			Error    error
+		}
+	}
+}
+
+func NewLeasesRepository() *LeasesRepository {
+	return &LeasesRepository{}
+}
+
+func (r *LeasesRepository) Acquire(conn models.ConnectionInterface, name, holder string, duration time.Duration) (bool, error) {
+	r.AcquireCall.Receives.Connection = conn
+	r.AcquireCall.Receives.Name = name
+	r.AcquireCall.Receives.Holder = holder
+	r.AcquireCall.Receives.Duration = duration
+
+	return r.AcquireCall.Returns.Acquired, r.AcquireCall.Returns.Error
+}
@@ -0,0 +1,20 @@
+package mocks
+
+type LookupCache struct {
+	FlushCall struct {
+		WasCalled bool
+		Returns   struct {
+			Error error
+		}
+	}
+}
+
+func NewLookupCache() *LookupCache {
+	return &LookupCache{}
+}
+
+func (c *LookupCache) Flush() error {
+	c.FlushCall.WasCalled = true
+
+	return c.FlushCall.Returns.Error
+}
@@ -0,0 +1,46 @@
+package mocks
+
+import "github.com/cloudfoundry-incubator/notifications/v2/models"
+
+type MessageTimingsRepository struct {
+	InsertCall struct {
+		CallCount int
+		Receives  struct {
+			Connection models.ConnectionInterface
+			Timing     models.MessageTiming
+		}
+		Returns struct {
+			Error error
+		}
+	}
+
+	PercentilesByCampaignIDCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			CampaignID string
+		}
+		Returns struct {
+			Percentiles models.MessageTimingPercentiles
+			Error       error
+		}
+	}
+}
+
+func NewMessageTimingsRepository() *MessageTimingsRepository {
+	return &MessageTimingsRepository{}
+}
+
+func (r *MessageTimingsRepository) Insert(conn models.ConnectionInterface, timing models.MessageTiming) error {
+	r.InsertCall.CallCount++
+	r.InsertCall.Receives.Connection = conn
+	r.InsertCall.Receives.Timing = timing
+
+	return r.InsertCall.Returns.Error
+}
+
+func (r *MessageTimingsRepository) PercentilesByCampaignID(conn models.ConnectionInterface, campaignID string) (models.MessageTimingPercentiles, error) {
+	r.PercentilesByCampaignIDCall.Receives.Connection = conn
+	r.PercentilesByCampaignIDCall.Receives.CampaignID = campaignID
+
+	return r.PercentilesByCampaignIDCall.Returns.Percentiles, r.PercentilesByCampaignIDCall.Returns.Error
+}
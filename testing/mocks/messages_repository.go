@@ -1,6 +1,10 @@
 package mocks
 
-import "github.com/cloudfoundry-incubator/notifications/v2/models"
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+)
 
 type MessagesRepository struct {
 	InsertCall       messagesRepositoryInsertCall
@@ -19,6 +23,20 @@ type MessagesRepository struct {
 		}
 	}
 
+	CountByTemplateIDSinceCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			TemplateID string
+			ClientID   string
+			Since      time.Time
+		}
+
+		Returns struct {
+			MessageCounts models.MessageCounts
+			Error         error
+		}
+	}
+
 	MostRecentlyUpdatedByCampaignIDCall struct {
 		Receives struct {
 			CampaignID string
@@ -106,3 +124,12 @@ func (mr *MessagesRepository) Update(conn models.ConnectionInterface, message mo
 
 	return mr.UpdateCall.Returns.Message, mr.UpdateCall.Returns.Error
 }
+
+func (mr *MessagesRepository) CountByTemplateIDSince(conn models.ConnectionInterface, templateID, clientID string, since time.Time) (models.MessageCounts, error) {
+	mr.CountByTemplateIDSinceCall.Receives.Connection = conn
+	mr.CountByTemplateIDSinceCall.Receives.TemplateID = templateID
+	mr.CountByTemplateIDSinceCall.Receives.ClientID = clientID
+	mr.CountByTemplateIDSinceCall.Receives.Since = since
+
+	return mr.CountByTemplateIDSinceCall.Returns.MessageCounts, mr.CountByTemplateIDSinceCall.Returns.Error
+}
@@ -0,0 +1,86 @@
+package mocks
+
+import "github.com/cloudfoundry-incubator/notifications/v2/collections"
+
+type PartialsCollection struct {
+	SetCall struct {
+		Receives struct {
+			Connection collections.ConnectionInterface
+			Partial    collections.Partial
+		}
+		Returns struct {
+			Partial collections.Partial
+			Error   error
+		}
+	}
+
+	GetCall struct {
+		Receives struct {
+			Connection collections.ConnectionInterface
+			PartialID  string
+			ClientID   string
+		}
+		Returns struct {
+			Partial collections.Partial
+			Error   error
+		}
+	}
+
+	ListCall struct {
+		CallCount int
+		Receives  struct {
+			Connection collections.ConnectionInterface
+			ClientID   string
+		}
+		Returns struct {
+			Partials []collections.Partial
+			Error    error
+		}
+	}
+
+	DeleteCall struct {
+		Receives struct {
+			Connection collections.ConnectionInterface
+			PartialID  string
+			ClientID   string
+		}
+		Returns struct {
+			Error error
+		}
+	}
+}
+
+func NewPartialsCollection() *PartialsCollection {
+	return &PartialsCollection{}
+}
+
+func (c *PartialsCollection) Set(conn collections.ConnectionInterface, partial collections.Partial) (collections.Partial, error) {
+	c.SetCall.Receives.Connection = conn
+	c.SetCall.Receives.Partial = partial
+
+	return c.SetCall.Returns.Partial, c.SetCall.Returns.Error
+}
+
+func (c *PartialsCollection) Get(conn collections.ConnectionInterface, partialID, clientID string) (collections.Partial, error) {
+	c.GetCall.Receives.Connection = conn
+	c.GetCall.Receives.PartialID = partialID
+	c.GetCall.Receives.ClientID = clientID
+
+	return c.GetCall.Returns.Partial, c.GetCall.Returns.Error
+}
+
+func (c *PartialsCollection) List(conn collections.ConnectionInterface, clientID string) ([]collections.Partial, error) {
+	c.ListCall.CallCount++
+	c.ListCall.Receives.Connection = conn
+	c.ListCall.Receives.ClientID = clientID
+
+	return c.ListCall.Returns.Partials, c.ListCall.Returns.Error
+}
+
+func (c *PartialsCollection) Delete(conn collections.ConnectionInterface, partialID, clientID string) error {
+	c.DeleteCall.Receives.Connection = conn
+	c.DeleteCall.Receives.PartialID = partialID
+	c.DeleteCall.Receives.ClientID = clientID
+
+	return c.DeleteCall.Returns.Error
+}
@@ -0,0 +1,25 @@
+package mocks
+
+import "github.com/cloudfoundry-incubator/notifications/postal/common"
+
+type PartialsLoader struct {
+	LoadPartialsCall struct {
+		Receives struct {
+			ClientID string
+		}
+		Returns struct {
+			Partials []common.Partial
+			Error    error
+		}
+	}
+}
+
+func NewPartialsLoader() *PartialsLoader {
+	return &PartialsLoader{}
+}
+
+func (pl *PartialsLoader) LoadPartials(clientID string) ([]common.Partial, error) {
+	pl.LoadPartialsCall.Receives.ClientID = clientID
+
+	return pl.LoadPartialsCall.Returns.Partials, pl.LoadPartialsCall.Returns.Error
+}
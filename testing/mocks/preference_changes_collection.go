@@ -0,0 +1,31 @@
+package mocks
+
+import "github.com/cloudfoundry-incubator/notifications/v2/collections"
+
+type PreferenceChangesCollection struct {
+	ListCall struct {
+		Receives struct {
+			Connection collections.ConnectionInterface
+			UserGUID   string
+			Offset     int
+			Limit      int
+		}
+		Returns struct {
+			Changes []collections.PreferenceChange
+			Error   error
+		}
+	}
+}
+
+func NewPreferenceChangesCollection() *PreferenceChangesCollection {
+	return &PreferenceChangesCollection{}
+}
+
+func (c *PreferenceChangesCollection) List(conn collections.ConnectionInterface, userGUID string, offset, limit int) ([]collections.PreferenceChange, error) {
+	c.ListCall.Receives.Connection = conn
+	c.ListCall.Receives.UserGUID = userGUID
+	c.ListCall.Receives.Offset = offset
+	c.ListCall.Receives.Limit = limit
+
+	return c.ListCall.Returns.Changes, c.ListCall.Returns.Error
+}
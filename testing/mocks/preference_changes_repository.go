@@ -0,0 +1,54 @@
+package mocks
+
+import "github.com/cloudfoundry-incubator/notifications/v2/models"
+
+type PreferenceChangesRepository struct {
+	RecordCall struct {
+		CallCount int
+		Receives  struct {
+			Connection models.ConnectionInterface
+			Actor      models.Actor
+			Changes    []models.PreferenceChange
+		}
+		Returns struct {
+			Error error
+		}
+	}
+
+	ListCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			UserGUID   string
+			Offset     int
+			Limit      int
+		}
+		Returns struct {
+			Changes []models.PreferenceChange
+			Error   error
+		}
+	}
+}
+
+func NewPreferenceChangesRepository() *PreferenceChangesRepository {
+	return &PreferenceChangesRepository{}
+}
+
+// Record keeps every change it receives, including those that leave the value
+// unchanged, so tests can see exactly what was passed in.
+func (r *PreferenceChangesRepository) Record(connection models.ConnectionInterface, actor models.Actor, change models.PreferenceChange) error {
+	r.RecordCall.CallCount++
+	r.RecordCall.Receives.Connection = connection
+	r.RecordCall.Receives.Actor = actor
+	r.RecordCall.Receives.Changes = append(r.RecordCall.Receives.Changes, change)
+
+	return r.RecordCall.Returns.Error
+}
+
+func (r *PreferenceChangesRepository) List(connection models.ConnectionInterface, userGUID string, offset, limit int) ([]models.PreferenceChange, error) {
+	r.ListCall.Receives.Connection = connection
+	r.ListCall.Receives.UserGUID = userGUID
+	r.ListCall.Receives.Offset = offset
+	r.ListCall.Receives.Limit = limit
+
+	return r.ListCall.Returns.Changes, r.ListCall.Returns.Error
+}
@@ -3,6 +3,7 @@ package mocks
 import (
 	"github.com/cloudfoundry-incubator/notifications/v1/models"
 	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	v2models "github.com/cloudfoundry-incubator/notifications/v2/models"
 )
 
 type PreferenceUpdater struct {
@@ -12,6 +13,21 @@ type PreferenceUpdater struct {
 			Preferences       []models.Preference
 			GlobalUnsubscribe bool
 			UserID            string
+			Actor             v2models.Actor
+		}
+		Returns struct {
+			Error error
+		}
+	}
+
+	UpdateQuietHoursCall struct {
+		CallCount int
+		Receives  struct {
+			Connection services.ConnectionInterface
+			TimeZone   *string
+			QuietHours *services.QuietHours
+			UserID     string
+			Actor      v2models.Actor
 		}
 		Returns struct {
 			Error error
@@ -23,11 +39,23 @@ func NewPreferenceUpdater() *PreferenceUpdater {
 	return &PreferenceUpdater{}
 }
 
-func (pu *PreferenceUpdater) Update(conn services.ConnectionInterface, preferences []models.Preference, globalUnsubscribe bool, userID string) error {
+func (pu *PreferenceUpdater) Update(conn services.ConnectionInterface, preferences []models.Preference, globalUnsubscribe bool, userID string, actor v2models.Actor) error {
 	pu.UpdateCall.Receives.Connection = conn
 	pu.UpdateCall.Receives.Preferences = preferences
 	pu.UpdateCall.Receives.GlobalUnsubscribe = globalUnsubscribe
 	pu.UpdateCall.Receives.UserID = userID
+	pu.UpdateCall.Receives.Actor = actor
 
 	return pu.UpdateCall.Returns.Error
 }
+
+func (pu *PreferenceUpdater) UpdateQuietHours(conn services.ConnectionInterface, timeZone *string, quietHours *services.QuietHours, userID string, actor v2models.Actor) error {
+	pu.UpdateQuietHoursCall.CallCount++
+	pu.UpdateQuietHoursCall.Receives.Connection = conn
+	pu.UpdateQuietHoursCall.Receives.TimeZone = timeZone
+	pu.UpdateQuietHoursCall.Receives.QuietHours = quietHours
+	pu.UpdateQuietHoursCall.Receives.UserID = userID
+	pu.UpdateQuietHoursCall.Receives.Actor = actor
+
+	return pu.UpdateQuietHoursCall.Returns.Error
+}
@@ -0,0 +1,46 @@
+package mocks
+
+import "github.com/cloudfoundry-incubator/notifications/v2/models"
+
+type QuietHoursRepository struct {
+	SetCall struct {
+		CallCount int
+		Receives  struct {
+			Connection models.ConnectionInterface
+			QuietHours models.QuietHours
+		}
+		Returns struct {
+			Error error
+		}
+	}
+
+	GetCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			UserGUID   string
+		}
+		Returns struct {
+			QuietHours models.QuietHours
+			Error      error
+		}
+	}
+}
+
+func NewQuietHoursRepository() *QuietHoursRepository {
+	return &QuietHoursRepository{}
+}
+
+func (r *QuietHoursRepository) Set(conn models.ConnectionInterface, quietHours models.QuietHours) error {
+	r.SetCall.CallCount++
+	r.SetCall.Receives.Connection = conn
+	r.SetCall.Receives.QuietHours = quietHours
+
+	return r.SetCall.Returns.Error
+}
+
+func (r *QuietHoursRepository) Get(conn models.ConnectionInterface, userGUID string) (models.QuietHours, error) {
+	r.GetCall.Receives.Connection = conn
+	r.GetCall.Receives.UserGUID = userGUID
+
+	return r.GetCall.Returns.QuietHours, r.GetCall.Returns.Error
+}
@@ -0,0 +1,38 @@
+package mocks
+
+type Signer struct {
+	SignCall struct {
+		Receives struct {
+			Payload []byte
+		}
+		Returns struct {
+			Token string
+		}
+	}
+
+	VerifyCall struct {
+		Receives struct {
+			Token string
+		}
+		Returns struct {
+			Payload []byte
+			Error   error
+		}
+	}
+}
+
+func NewSigner() *Signer {
+	return &Signer{}
+}
+
+func (s *Signer) Sign(payload []byte) string {
+	s.SignCall.Receives.Payload = payload
+
+	return s.SignCall.Returns.Token
+}
+
+func (s *Signer) Verify(token string) ([]byte, error) {
+	s.VerifyCall.Receives.Token = token
+
+	return s.VerifyCall.Returns.Payload, s.VerifyCall.Returns.Error
+}
@@ -0,0 +1,31 @@
+package mocks
+
+import "github.com/cloudfoundry-incubator/notifications/v2/collections"
+
+type TemplateAssociationsCollection struct {
+	ListCall struct {
+		Receives struct {
+			Connection collections.ConnectionInterface
+			TemplateID string
+			ClientID   string
+			Days       int
+		}
+		Returns struct {
+			Associations collections.TemplateAssociations
+			Error        error
+		}
+	}
+}
+
+func NewTemplateAssociationsCollection() *TemplateAssociationsCollection {
+	return &TemplateAssociationsCollection{}
+}
+
+func (c *TemplateAssociationsCollection) List(conn collections.ConnectionInterface, templateID, clientID string, days int) (collections.TemplateAssociations, error) {
+	c.ListCall.Receives.Connection = conn
+	c.ListCall.Receives.TemplateID = templateID
+	c.ListCall.Receives.ClientID = clientID
+	c.ListCall.Receives.Days = days
+
+	return c.ListCall.Returns.Associations, c.ListCall.Returns.Error
+}
@@ -24,6 +24,17 @@ type ZonedUAAClient struct {
 		}
 	}
 
+	UsersGUIDsByGroupCall struct {
+		Receives struct {
+			Token     string
+			GroupName string
+		}
+		Returns struct {
+			UserGUIDs []string
+			Error     error
+		}
+	}
+
 	GetClientTokenCall struct {
 		Receives struct {
 			Host string
@@ -43,6 +54,7 @@ type ZonedUAAClient struct {
 			Users []uaa.User
 			Error error
 		}
+		CallCount int
 	}
 }
 
@@ -63,6 +75,13 @@ func (c *ZonedUAAClient) UsersGUIDsByScope(token, scope string) ([]string, error
 	return c.UsersGUIDsByScopeCall.Returns.UserGUIDs, c.UsersGUIDsByScopeCall.Returns.Error
 }
 
+func (c *ZonedUAAClient) UsersGUIDsByGroup(token, groupName string) ([]string, error) {
+	c.UsersGUIDsByGroupCall.Receives.Token = token
+	c.UsersGUIDsByGroupCall.Receives.GroupName = groupName
+
+	return c.UsersGUIDsByGroupCall.Returns.UserGUIDs, c.UsersGUIDsByGroupCall.Returns.Error
+}
+
 func (c *ZonedUAAClient) GetClientToken(host string) (string, error) {
 	c.GetClientTokenCall.Receives.Host = host
 
@@ -72,6 +91,7 @@ func (c *ZonedUAAClient) GetClientToken(host string) (string, error) {
 func (c *ZonedUAAClient) UsersEmailsByIDs(token string, ids ...string) ([]uaa.User, error) {
 	c.UsersEmailsByIDsCall.Receives.Token = token
 	c.UsersEmailsByIDsCall.Receives.IDs = ids
+	c.UsersEmailsByIDsCall.CallCount++
 
 	return c.UsersEmailsByIDsCall.Returns.Users, c.UsersEmailsByIDsCall.Returns.Error
 }
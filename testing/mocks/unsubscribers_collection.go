@@ -3,6 +3,7 @@ package mocks
 import (
 	"github.com/cloudfoundry-incubator/notifications/db"
 	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
 )
 
 type UnsubscribersCollection struct {
@@ -10,6 +11,7 @@ type UnsubscribersCollection struct {
 		Receives struct {
 			Unsubscriber collections.Unsubscriber
 			Connection   db.ConnectionInterface
+			Actor        models.Actor
 		}
 		Returns struct {
 			Unsubscriber collections.Unsubscriber
@@ -20,6 +22,7 @@ type UnsubscribersCollection struct {
 		Receives struct {
 			Unsubscriber collections.Unsubscriber
 			Connection   db.ConnectionInterface
+			Actor        models.Actor
 		}
 		Returns struct {
 			Error error
@@ -31,16 +34,18 @@ func NewUnsubscribersCollection() *UnsubscribersCollection {
 	return &UnsubscribersCollection{}
 }
 
-func (u *UnsubscribersCollection) Set(connection collections.ConnectionInterface, unsubscriber collections.Unsubscriber) (collections.Unsubscriber, error) {
+func (u *UnsubscribersCollection) Set(connection collections.ConnectionInterface, unsubscriber collections.Unsubscriber, actor models.Actor) (collections.Unsubscriber, error) {
 	u.SetCall.Receives.Unsubscriber = unsubscriber
 	u.SetCall.Receives.Connection = connection
+	u.SetCall.Receives.Actor = actor
 
 	return u.SetCall.Returns.Unsubscriber, u.SetCall.Returns.Error
 }
 
-func (u *UnsubscribersCollection) Delete(connection collections.ConnectionInterface, unsubscriber collections.Unsubscriber) error {
+func (u *UnsubscribersCollection) Delete(connection collections.ConnectionInterface, unsubscriber collections.Unsubscriber, actor models.Actor) error {
 	u.DeleteCall.Receives.Unsubscriber = unsubscriber
 	u.DeleteCall.Receives.Connection = connection
+	u.DeleteCall.Receives.Actor = actor
 
 	return u.DeleteCall.Returns.Error
 }
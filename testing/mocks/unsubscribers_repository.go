@@ -7,7 +7,8 @@ import (
 
 type UnsubscribersRepository struct {
 	InsertCall struct {
-		Receives struct {
+		CallCount int
+		Receives  struct {
 			Unsubscriber models.Unsubscriber
 			Connection   db.ConnectionInterface
 		}
@@ -27,6 +28,16 @@ type UnsubscribersRepository struct {
 			Error        error
 		}
 	}
+	ListByUserGUIDCall struct {
+		Receives struct {
+			UserGUID   string
+			Connection db.ConnectionInterface
+		}
+		Returns struct {
+			Unsubscribers []models.Unsubscriber
+			Error         error
+		}
+	}
 	DeleteCall struct {
 		Receives struct {
 			Unsubscriber models.Unsubscriber
@@ -43,6 +54,7 @@ func NewUnsubscribersRepository() *UnsubscribersRepository {
 }
 
 func (ur *UnsubscribersRepository) Insert(connection models.ConnectionInterface, unsubscriber models.Unsubscriber) (models.Unsubscriber, error) {
+	ur.InsertCall.CallCount++
 	ur.InsertCall.Receives.Connection = connection
 	ur.InsertCall.Receives.Unsubscriber = unsubscriber
 	return ur.InsertCall.Returns.Unsubscriber, ur.InsertCall.Returns.Error
@@ -56,6 +68,13 @@ func (ur *UnsubscribersRepository) Get(connection models.ConnectionInterface, us
 	return ur.GetCall.Returns.Unsubscriber, ur.GetCall.Returns.Error
 }
 
+func (ur *UnsubscribersRepository) ListByUserGUID(connection models.ConnectionInterface, userGUID string) ([]models.Unsubscriber, error) {
+	ur.ListByUserGUIDCall.Receives.UserGUID = userGUID
+	ur.ListByUserGUIDCall.Receives.Connection = connection
+
+	return ur.ListByUserGUIDCall.Returns.Unsubscribers, ur.ListByUserGUIDCall.Returns.Error
+}
+
 func (ur *UnsubscribersRepository) Delete(connection models.ConnectionInterface, unsubscriber models.Unsubscriber) error {
 	ur.DeleteCall.Receives.Connection = connection
 	ur.DeleteCall.Receives.Unsubscriber = unsubscriber
@@ -12,6 +12,7 @@ type UserLoader struct {
 			Users map[string]uaa.User
 			Error error
 		}
+		CallCount int
 	}
 }
 
@@ -22,6 +23,7 @@ func NewUserLoader() *UserLoader {
 func (ul *UserLoader) Load(userGUIDs []string, token string) (map[string]uaa.User, error) {
 	ul.LoadCall.Receives.UserGUIDs = userGUIDs
 	ul.LoadCall.Receives.Token = token
+	ul.LoadCall.CallCount++
 
 	return ul.LoadCall.Returns.Users, ul.LoadCall.Returns.Error
 }
@@ -0,0 +1,81 @@
+package mocks
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+)
+
+type UserPreferencesCollection struct {
+	ListCall struct {
+		Receives struct {
+			Connection collections.ConnectionInterface
+			UserGUID   string
+		}
+		Returns struct {
+			Preferences collections.UserPreferences
+			Error       error
+		}
+	}
+
+	UpdateCall struct {
+		Receives struct {
+			Connection              collections.ConnectionInterface
+			UserGUID                string
+			CampaignTypePreferences []collections.CampaignTypePreference
+			GlobalUnsubscribe       *bool
+			TimeZone                *string
+			QuietHours              *collections.QuietHours
+			Actor                   models.Actor
+		}
+		Returns struct {
+			Preferences collections.UserPreferences
+			Error       error
+		}
+	}
+
+	UpdateCampaignTypesCall struct {
+		Receives struct {
+			Connection              collections.ConnectionInterface
+			UserGUID                string
+			CampaignTypePreferences []collections.CampaignTypePreference
+			Actor                   models.Actor
+		}
+		Returns struct {
+			Error error
+		}
+	}
+}
+
+func NewUserPreferencesCollection() *UserPreferencesCollection {
+	return &UserPreferencesCollection{}
+}
+
+func (c *UserPreferencesCollection) List(conn collections.ConnectionInterface, userGUID string) (collections.UserPreferences, error) {
+	c.ListCall.Receives.Connection = conn
+	c.ListCall.Receives.UserGUID = userGUID
+
+	return c.ListCall.Returns.Preferences, c.ListCall.Returns.Error
+}
+
+func (c *UserPreferencesCollection) Update(conn collections.ConnectionInterface, userGUID string, campaignTypePreferences []collections.CampaignTypePreference,
+	globalUnsubscribe *bool, timeZone *string, quietHours *collections.QuietHours, actor models.Actor) (collections.UserPreferences, error) {
+
+	c.UpdateCall.Receives.Connection = conn
+	c.UpdateCall.Receives.UserGUID = userGUID
+	c.UpdateCall.Receives.CampaignTypePreferences = campaignTypePreferences
+	c.UpdateCall.Receives.GlobalUnsubscribe = globalUnsubscribe
+	c.UpdateCall.Receives.TimeZone = timeZone
+	c.UpdateCall.Receives.QuietHours = quietHours
+	c.UpdateCall.Receives.Actor = actor
+
+	return c.UpdateCall.Returns.Preferences, c.UpdateCall.Returns.Error
+}
+
+func (c *UserPreferencesCollection) UpdateCampaignTypes(conn collections.ConnectionInterface, userGUID string, campaignTypePreferences []collections.CampaignTypePreference, actor models.Actor) error {
+	c.UpdateCampaignTypesCall.Receives.Connection = conn
+	c.UpdateCampaignTypesCall.Receives.UserGUID = userGUID
+	c.UpdateCampaignTypesCall.Receives.CampaignTypePreferences = campaignTypePreferences
+	c.UpdateCampaignTypesCall.Receives.Actor = actor
+
+	return c.UpdateCampaignTypesCall.Returns.Error
+}
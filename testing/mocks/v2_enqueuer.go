@@ -9,7 +9,9 @@ import (
 
 type V2Enqueuer struct {
 	EnqueueCall struct {
-		Receives struct {
+		CallCount     int
+		EnqueuedUsers []queue.User
+		Receives      struct {
 			Connection      queue.ConnectionInterface
 			Users           []queue.User
 			Options         queue.Options
@@ -22,6 +24,9 @@ type V2Enqueuer struct {
 			UAAHost         string
 			CampaignID      string
 		}
+		Returns struct {
+			Error error
+		}
 	}
 }
 
@@ -30,7 +35,9 @@ func NewV2Enqueuer() *V2Enqueuer {
 }
 
 func (m *V2Enqueuer) Enqueue(conn queue.ConnectionInterface, users []queue.User, options queue.Options,
-	space cf.CloudControllerSpace, org cf.CloudControllerOrganization, client, uaaHost, scope, vcapRequestID string, reqReceived time.Time, campaignID string) {
+	space cf.CloudControllerSpace, org cf.CloudControllerOrganization, client, uaaHost, scope, vcapRequestID string, reqReceived time.Time, campaignID string) error {
+	m.EnqueueCall.CallCount++
+	m.EnqueueCall.EnqueuedUsers = append(m.EnqueueCall.EnqueuedUsers, users...)
 
 	m.EnqueueCall.Receives.Connection = conn
 	m.EnqueueCall.Receives.Users = users
@@ -43,4 +50,6 @@ func (m *V2Enqueuer) Enqueue(conn queue.ConnectionInterface, users []queue.User,
 	m.EnqueueCall.Receives.VCAPRequestID = vcapRequestID
 	m.EnqueueCall.Receives.RequestReceived = reqReceived
 	m.EnqueueCall.Receives.CampaignID = campaignID
+
+	return m.EnqueueCall.Returns.Error
 }
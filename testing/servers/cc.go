@@ -7,6 +7,7 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/gorilla/mux"
@@ -25,12 +26,18 @@ func NewCC(userNameToIdMap map[string]string) CC {
 	}
 
 	router.HandleFunc("/v2/spaces/{guid}", cc.GetSpace).Methods("GET")
+	router.HandleFunc("/v2/spaces/{guid}/managers", cc.GetSpaceRoleUsers).Methods("GET")
+	router.HandleFunc("/v2/spaces/{guid}/developers", cc.GetSpaceRoleUsers).Methods("GET")
+	router.HandleFunc("/v2/spaces/{guid}/auditors", cc.GetSpaceRoleUsers).Methods("GET")
 	router.HandleFunc("/v2/organizations/{guid}/users", cc.GetOrgUsers).Methods("GET")
 	router.HandleFunc("/v2/organizations/{guid}/managers", cc.GetOrgManagers).Methods("GET")
 	router.HandleFunc("/v2/organizations/{guid}/auditors", cc.GetOrgAuditors).Methods("GET")
 	router.HandleFunc("/v2/organizations/{guid}/billing_managers", cc.GetOrgBillingManagers).Methods("GET")
 	router.HandleFunc("/v2/organizations/{guid}", cc.GetOrg).Methods("GET")
 	router.HandleFunc("/v2/users", cc.GetSpaceUsers).Methods("GET")
+	router.HandleFunc("/v3/spaces/{guid}", cc.GetV3Space).Methods("GET")
+	router.HandleFunc("/v3/organizations/{guid}", cc.GetV3Org).Methods("GET")
+	router.HandleFunc("/v3/roles", cc.GetV3Roles).Methods("GET")
 	router.HandleFunc("/{anything:.*}", http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		fmt.Printf("CC ROUTE REQUEST ---> %+v\n", req)
 		w.WriteHeader(http.StatusTeapot)
@@ -129,23 +136,7 @@ func (cc CC) GetOrg(w http.ResponseWriter, req *http.Request) {
 
 func (cc CC) GetOrgUsers(w http.ResponseWriter, req *http.Request) {
 	orgGUID := strings.Split(req.URL.Path, "/")[3]
-
-	var desiredUsers []string
-	switch orgGUID {
-	case "org-123":
-		desiredUsers = []string{
-			"user-456",
-			"user-789",
-			"user-000",
-		}
-	case "org-456":
-		desiredUsers = []string{
-			"user-123",
-			"user-456",
-		}
-	default:
-		desiredUsers = []string{}
-	}
+	desiredUsers := orgUserNames(orgGUID)
 
 	users := []map[string]interface{}{}
 	for _, userName := range desiredUsers {
@@ -227,6 +218,40 @@ func (cc CC) GetOrgManagers(w http.ResponseWriter, req *http.Request) {
 	w.Write([]byte(json))
 }
 
+func (cc CC) GetSpaceRoleUsers(w http.ResponseWriter, req *http.Request) {
+	json := `{
+       "total_results": 1,
+       "total_pages": 1,
+       "prev_url": null,
+       "next_url": null,
+       "resources": [
+          {
+             "metadata": {
+                "guid": "user-123",
+                "url": "/v2/users/user-123",
+                "created_at": "2014-10-16T21:05:40+00:00",
+                "updated_at": null
+             },
+             "entity": {
+                "admin": false,
+                "active": true,
+                "default_space_guid": null,
+                "spaces_url": "/v2/users/user-123/spaces",
+                "organizations_url": "/v2/users/user-123/organizations",
+                "managed_organizations_url": "/v2/users/user-123/managed_organizations",
+                "billing_managed_organizations_url": "/v2/users/user-123/billing_managed_organizations",
+                "audited_organizations_url": "/v2/users/user-123/audited_organizations",
+                "managed_spaces_url": "/v2/users/user-123/managed_spaces",
+                "audited_spaces_url": "/v2/users/user-123/audited_spaces"
+             }
+          }
+       ]
+    }`
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(json))
+}
+
 func (cc CC) GetOrgAuditors(w http.ResponseWriter, req *http.Request) {
 	json := `{
        "total_results": 1,
@@ -302,23 +327,7 @@ func (cc CC) GetSpaceUsers(w http.ResponseWriter, req *http.Request) {
 	}
 	filter := query.Get("q")
 	spaceGUID := strings.TrimPrefix(filter, "space_guid:")
-
-	var desiredUsers []string
-	switch spaceGUID {
-	case "space-123":
-		desiredUsers = []string{
-			"user-456",
-			"user-789",
-			"user-000",
-		}
-	case "space-456":
-		desiredUsers = []string{
-			"user-123",
-			"user-456",
-		}
-	default:
-		desiredUsers = []string{}
-	}
+	desiredUsers := spaceUserNames(spaceGUID)
 
 	users := []map[string]interface{}{}
 	for _, userName := range desiredUsers {
@@ -365,3 +374,175 @@ func (cc CC) GetSpaceUsers(w http.ResponseWriter, req *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte(uaaJSON))
 }
+
+func orgUserNames(orgGUID string) []string {
+	switch orgGUID {
+	case "org-123":
+		return []string{
+			"user-456",
+			"user-789",
+			"user-000",
+		}
+	case "org-456":
+		return []string{
+			"user-123",
+			"user-456",
+		}
+	default:
+		return []string{}
+	}
+}
+
+func spaceUserNames(spaceGUID string) []string {
+	switch spaceGUID {
+	case "space-123":
+		return []string{
+			"user-456",
+			"user-789",
+			"user-000",
+		}
+	case "space-456":
+		return []string{
+			"user-123",
+			"user-456",
+		}
+	default:
+		return []string{}
+	}
+}
+
+func (cc CC) userGUID(userName string) string {
+	if guid, ok := cc.userNameToIdMap[userName]; ok {
+		return guid
+	}
+
+	return userName
+}
+
+func (cc CC) GetV3Space(w http.ResponseWriter, req *http.Request) {
+	guid := mux.Vars(req)["guid"]
+	if guid != "space-123" && guid != "space-456" {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"errors":[{"code":10010,"title":"CF-ResourceNotFound","detail":"Space not found"}]}`))
+		return
+	}
+
+	response, err := json.Marshal(map[string]interface{}{
+		"guid":       guid,
+		"name":       "notifications-service",
+		"created_at": "2014-08-01T17:36:18Z",
+		"relationships": map[string]interface{}{
+			"organization": map[string]interface{}{
+				"data": map[string]string{"guid": "org-123"},
+			},
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
+
+func (cc CC) GetV3Org(w http.ResponseWriter, req *http.Request) {
+	guid := mux.Vars(req)["guid"]
+	if guid != "org-123" && guid != "org-456" {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"errors":[{"code":10010,"title":"CF-ResourceNotFound","detail":"Organization not found"}]}`))
+		return
+	}
+
+	response, err := json.Marshal(map[string]interface{}{
+		"guid":       guid,
+		"name":       "notifications-service",
+		"created_at": "2014-08-01T17:36:17Z",
+		"suspended":  false,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
+
+// GetV3Roles serves the same memberships as the v2 endpoints, two roles per
+// page so that clients have to follow the pagination links.
+func (cc CC) GetV3Roles(w http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+	types := query.Get("types")
+
+	var roleType string
+	var userNames []string
+	switch types {
+	case "organization_user":
+		roleType = types
+		for _, userName := range orgUserNames(query.Get("organization_guids")) {
+			userNames = append(userNames, cc.userGUID(userName))
+		}
+	case "organization_manager":
+		roleType, userNames = types, []string{"user-456"}
+	case "organization_auditor":
+		roleType, userNames = types, []string{"user-123"}
+	case "organization_billing_manager":
+		roleType, userNames = types, []string{"user-111"}
+	case "space_manager", "space_developer", "space_auditor":
+		roleType, userNames = types, []string{"user-123"}
+	case "space_developer,space_manager,space_auditor":
+		roleType = "space_developer"
+		for _, userName := range spaceUserNames(query.Get("space_guids")) {
+			userNames = append(userNames, cc.userGUID(userName))
+		}
+	}
+
+	perPage := 2
+	page, err := strconv.Atoi(query.Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	start := (page - 1) * perPage
+	if start > len(userNames) {
+		start = len(userNames)
+	}
+	end := start + perPage
+	if end > len(userNames) {
+		end = len(userNames)
+	}
+
+	roles := []map[string]interface{}{}
+	for _, userGUID := range userNames[start:end] {
+		roles = append(roles, map[string]interface{}{
+			"guid": fmt.Sprintf("role-%s-%s", roleType, userGUID),
+			"type": roleType,
+			"relationships": map[string]interface{}{
+				"user": map[string]interface{}{
+					"data": map[string]string{"guid": userGUID},
+				},
+			},
+		})
+	}
+
+	var next interface{}
+	if end < len(userNames) {
+		query.Set("page", strconv.Itoa(page+1))
+		next = map[string]string{
+			"href": fmt.Sprintf("http://%s/v3/roles?%s", req.Host, query.Encode()),
+		}
+	}
+
+	response, err := json.Marshal(map[string]interface{}{
+		"pagination": map[string]interface{}{
+			"total_results": len(userNames),
+			"next":          next,
+		},
+		"resources": roles,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
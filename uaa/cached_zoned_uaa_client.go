@@ -0,0 +1,60 @@
+package uaa
+
+type lookupCache interface {
+	Get(key string, value interface{}) bool
+	Set(key string, value interface{})
+}
+
+type zonedClient interface {
+	GetClientToken(host string) (string, error)
+	UsersEmailsByIDs(token string, ids ...string) ([]User, error)
+	AllUsers(token string) ([]User, error)
+	UsersGUIDsByScope(token, scope string) ([]string, error)
+	UsersGUIDsByGroup(token, groupName string) ([]string, error)
+}
+
+// CachedZonedUAAClient caches user email lookups per user ID so that only the
+// IDs missing from the cache are requested from UAA. User GUIDs are unique
+// across identity zones, so the zone is not part of the cache key.
+type CachedZonedUAAClient struct {
+	zonedClient
+	cache lookupCache
+}
+
+func NewCachedZonedUAAClient(client zonedClient, cache lookupCache) CachedZonedUAAClient {
+	return CachedZonedUAAClient{
+		zonedClient: client,
+		cache:       cache,
+	}
+}
+
+func (c CachedZonedUAAClient) UsersEmailsByIDs(token string, ids ...string) ([]User, error) {
+	var users []User
+	var missingIDs []string
+
+	for _, id := range ids {
+		var user User
+		if c.cache.Get("uaa.user."+id, &user) {
+			users = append(users, user)
+			continue
+		}
+
+		missingIDs = append(missingIDs, id)
+	}
+
+	if len(missingIDs) == 0 {
+		return users, nil
+	}
+
+	fetchedUsers, err := c.zonedClient.UsersEmailsByIDs(token, missingIDs...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, user := range fetchedUsers {
+		c.cache.Set("uaa.user."+user.ID, user)
+		users = append(users, user)
+	}
+
+	return users, nil
+}
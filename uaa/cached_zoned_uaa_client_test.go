@@ -0,0 +1,87 @@
+package uaa_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/cache"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/uaa"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CachedZonedUAAClient", func() {
+	var (
+		client    uaa.CachedZonedUAAClient
+		uaaClient *mocks.ZonedUAAClient
+	)
+
+	BeforeEach(func() {
+		clock := mocks.NewClock()
+		clock.NowCall.Returns.Time = time.Now()
+
+		lookupCache := cache.NewCache(cache.NewMemoryStore(clock, 100), time.Minute, clock, mocks.NewMetricsEmitter())
+		uaaClient = mocks.NewZonedUAAClient()
+		client = uaa.NewCachedZonedUAAClient(uaaClient, lookupCache)
+	})
+
+	Describe("UsersEmailsByIDs", func() {
+		It("only requests the users that are not already cached", func() {
+			uaaClient.UsersEmailsByIDsCall.Returns.Users = []uaa.User{
+				{ID: "user-123", Emails: []string{"user-123@example.com"}},
+			}
+
+			users, err := client.UsersEmailsByIDs("some-token", "user-123")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(users).To(ConsistOf(uaa.User{ID: "user-123", Emails: []string{"user-123@example.com"}}))
+
+			uaaClient.UsersEmailsByIDsCall.Returns.Users = []uaa.User{
+				{ID: "user-456", Emails: []string{"user-456@example.com"}},
+			}
+
+			users, err = client.UsersEmailsByIDs("some-token", "user-123", "user-456")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(users).To(ConsistOf(
+				uaa.User{ID: "user-123", Emails: []string{"user-123@example.com"}},
+				uaa.User{ID: "user-456", Emails: []string{"user-456@example.com"}},
+			))
+
+			Expect(uaaClient.UsersEmailsByIDsCall.CallCount).To(Equal(2))
+			Expect(uaaClient.UsersEmailsByIDsCall.Receives.Token).To(Equal("some-token"))
+			Expect(uaaClient.UsersEmailsByIDsCall.Receives.IDs).To(Equal([]string{"user-456"}))
+		})
+
+		It("does not call UAA when every user is cached", func() {
+			uaaClient.UsersEmailsByIDsCall.Returns.Users = []uaa.User{
+				{ID: "user-123", Emails: []string{"user-123@example.com"}},
+			}
+
+			_, err := client.UsersEmailsByIDs("some-token", "user-123")
+			Expect(err).NotTo(HaveOccurred())
+
+			users, err := client.UsersEmailsByIDs("some-token", "user-123")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(users).To(ConsistOf(uaa.User{ID: "user-123", Emails: []string{"user-123@example.com"}}))
+
+			Expect(uaaClient.UsersEmailsByIDsCall.CallCount).To(Equal(1))
+		})
+
+		It("returns errors from UAA", func() {
+			uaaClient.UsersEmailsByIDsCall.Returns.Error = errors.New("uaa is down")
+
+			_, err := client.UsersEmailsByIDs("some-token", "user-123")
+			Expect(err).To(MatchError(errors.New("uaa is down")))
+		})
+	})
+
+	It("passes other calls straight through to the client", func() {
+		uaaClient.GetClientTokenCall.Returns.Token = "some-token"
+
+		token, err := client.GetClientToken("some-host")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(token).To(Equal("some-token"))
+		Expect(uaaClient.GetClientTokenCall.Receives.Host).To(Equal("some-host"))
+	})
+})
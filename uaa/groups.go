@@ -0,0 +1,122 @@
+package uaa
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+)
+
+type GroupNotFoundError struct {
+	Name string
+}
+
+func (e GroupNotFoundError) Error() string {
+	return fmt.Sprintf("UAA group %q could not be found", e.Name)
+}
+
+type groupMember struct {
+	Value string `json:"value"`
+	Type  string `json:"type"`
+}
+
+type groupResource struct {
+	ID          string        `json:"id"`
+	DisplayName string        `json:"displayName"`
+	Members     []groupMember `json:"members"`
+}
+
+type groupsListResponse struct {
+	Resources []groupResource `json:"resources"`
+}
+
+// UsersGUIDsByGroup returns the GUIDs of every user that is a member of the
+// named group, following members that are themselves groups. External (LDAP)
+// group mappings are reflected in UAA group membership, so they are included
+// without any extra lookups.
+func (z ZonedUAAClient) UsersGUIDsByGroup(token, groupName string) ([]string, error) {
+	uaaHost, err := z.tokenHost(token)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: !z.verifySSL,
+			},
+		},
+	}
+
+	filter := url.QueryEscape(fmt.Sprintf("displayName eq %q", groupName))
+	var list groupsListResponse
+	err = getGroupDocument(client, token, fmt.Sprintf("%s/Groups?filter=%s", uaaHost, filter), &list)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(list.Resources) == 0 {
+		return nil, GroupNotFoundError{Name: groupName}
+	}
+
+	var guids []string
+	seenUsers := map[string]bool{}
+	seenGroups := map[string]bool{list.Resources[0].ID: true}
+	pending := list.Resources[0].Members
+
+	for len(pending) > 0 {
+		member := pending[0]
+		pending = pending[1:]
+
+		if member.Type != "GROUP" {
+			if !seenUsers[member.Value] {
+				seenUsers[member.Value] = true
+				guids = append(guids, member.Value)
+			}
+			continue
+		}
+
+		if seenGroups[member.Value] {
+			continue
+		}
+		seenGroups[member.Value] = true
+
+		var nested groupResource
+		err = getGroupDocument(client, token, fmt.Sprintf("%s/Groups/%s", uaaHost, url.QueryEscape(member.Value)), &nested)
+		if err != nil {
+			return nil, err
+		}
+
+		pending = append(pending, nested.Members...)
+	}
+
+	return guids, nil
+}
+
+func getGroupDocument(client *http.Client, token, uri string, document interface{}) error {
+	request, err := http.NewRequest("GET", uri, nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Authorization", "Bearer "+token)
+	request.Header.Set("Accept", "application/json")
+
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+
+	if response.StatusCode > 399 {
+		return NewFailure(response.StatusCode, body)
+	}
+
+	return json.Unmarshal(body, document)
+}
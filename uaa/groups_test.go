@@ -0,0 +1,105 @@
+package uaa_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	"github.com/cloudfoundry-incubator/notifications/uaa"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("UsersGUIDsByGroup", func() {
+	var (
+		server        *httptest.Server
+		client        uaa.ZonedUAAClient
+		token         string
+		groups        map[string]string
+		authorization string
+	)
+
+	BeforeEach(func() {
+		groups = map[string]string{
+			"security-oncall": `{
+				"id": "group-1",
+				"displayName": "security-oncall",
+				"members": [
+					{"value": "user-1", "type": "USER"},
+					{"value": "group-2", "type": "GROUP"},
+					{"value": "user-2", "type": "USER"}
+				]
+			}`,
+			"group-2": `{
+				"id": "group-2",
+				"displayName": "security-leads",
+				"members": [
+					{"value": "user-2", "type": "USER"},
+					{"value": "user-3", "type": "USER"},
+					{"value": "group-1", "type": "GROUP"}
+				]
+			}`,
+		}
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			authorization = req.Header.Get("Authorization")
+
+			if req.URL.Path == "/Groups" {
+				if req.URL.Query().Get("filter") != `displayName eq "security-oncall"` {
+					fmt.Fprint(w, `{"resources": []}`)
+					return
+				}
+
+				fmt.Fprintf(w, `{"resources": [%s]}`, groups["security-oncall"])
+				return
+			}
+
+			group, ok := groups[req.URL.Path[len("/Groups/"):]]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"error": "not found"}`)
+				return
+			}
+
+			fmt.Fprint(w, group)
+		}))
+
+		token = helpers.BuildToken(map[string]interface{}{
+			"alg": "FAST",
+		}, map[string]interface{}{
+			"iss": server.URL + "/oauth/token",
+		})
+
+		client = uaa.NewZonedUAAClient("client-id", "client-secret", true, helpers.UAAPublicKey)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("returns the user GUIDs of the group, including nested group members", func() {
+		guids, err := client.UsersGUIDsByGroup(token, "security-oncall")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(guids).To(Equal([]string{"user-1", "user-2", "user-3"}))
+
+		Expect(authorization).To(Equal("Bearer " + token))
+	})
+
+	Context("when the group does not exist", func() {
+		It("returns a GroupNotFoundError", func() {
+			_, err := client.UsersGUIDsByGroup(token, "missing-group")
+			Expect(err).To(MatchError(uaa.GroupNotFoundError{Name: "missing-group"}))
+		})
+	})
+
+	Context("when a nested group cannot be retrieved", func() {
+		It("returns the error", func() {
+			delete(groups, "group-2")
+
+			_, err := client.UsersGUIDsByGroup(token, "security-oncall")
+			Expect(err).To(BeAssignableToTypeOf(uaa.Failure{}))
+		})
+	})
+})
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/cloudfoundry-incubator/notifications/metrics"
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
@@ -15,6 +16,8 @@ func TestUAASuite(t *testing.T) {
 	metricsLogger := metrics.DefaultLogger
 	metrics.DefaultLogger = log.New(buffer, "", 0)
 
+	helpers.RegisterFastTokenSigningMethod()
+
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "uaa")
 	metrics.DefaultLogger = metricsLogger
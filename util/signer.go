@@ -0,0 +1,78 @@
+package util
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+var ErrInvalidSignature = errors.New("invalid signature")
+
+const signingKeyInfo = "notifications signing key"
+
+type Signer struct {
+	key []byte
+}
+
+// NewSigner derives its HMAC key from the given secret so that the secret,
+// which is also used as an encryption key, is never used to sign directly.
+func NewSigner(secret []byte) Signer {
+	return Signer{
+		key: deriveKey(secret, []byte(signingKeyInfo)),
+	}
+}
+
+// Sign returns the payload and its HMAC-SHA256 signature, each base64 URL
+// encoded and joined by a "." so the token can be used in a query string.
+func (s Signer) Sign(payload []byte) string {
+	encoding := base64.RawURLEncoding
+
+	return encoding.EncodeToString(payload) + "." + encoding.EncodeToString(s.mac(payload))
+}
+
+func (s Signer) Verify(token string) ([]byte, error) {
+	encoding := base64.RawURLEncoding
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return nil, ErrInvalidSignature
+	}
+
+	payload, err := encoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+
+	signature, err := encoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+
+	if !hmac.Equal(signature, s.mac(payload)) {
+		return nil, ErrInvalidSignature
+	}
+
+	return payload, nil
+}
+
+// deriveKey is HKDF-SHA256 (RFC 5869) with an empty salt, expanded to a
+// single 32 byte block.
+func deriveKey(secret, info []byte) []byte {
+	extract := hmac.New(sha256.New, make([]byte, sha256.Size))
+	extract.Write(secret)
+
+	expand := hmac.New(sha256.New, extract.Sum(nil))
+	expand.Write(info)
+	expand.Write([]byte{1})
+
+	return expand.Sum(nil)
+}
+
+func (s Signer) mac(payload []byte) []byte {
+	hash := hmac.New(sha256.New, s.key)
+	hash.Write(payload)
+
+	return hash.Sum(nil)
+}
@@ -0,0 +1,63 @@
+package util_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/util"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Signer", func() {
+	var signer util.Signer
+
+	BeforeEach(func() {
+		signer = util.NewSigner([]byte("some-key"))
+	})
+
+	It("verifies tokens that it signed", func() {
+		token := signer.Sign([]byte("some-payload"))
+
+		payload, err := signer.Verify(token)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(payload).To(Equal([]byte("some-payload")))
+	})
+
+	It("signs with a key derived from the secret rather than the secret itself", func() {
+		hash := hmac.New(sha256.New, []byte("some-key"))
+		hash.Write([]byte("some-payload"))
+		rawSignature := base64.RawURLEncoding.EncodeToString(hash.Sum(nil))
+
+		token := signer.Sign([]byte("some-payload"))
+		Expect(strings.Split(token, ".")[1]).NotTo(Equal(rawSignature))
+	})
+
+	It("rejects tokens signed with a different key", func() {
+		token := util.NewSigner([]byte("other-key")).Sign([]byte("some-payload"))
+
+		_, err := signer.Verify(token)
+		Expect(err).To(Equal(util.ErrInvalidSignature))
+	})
+
+	It("rejects tokens whose payload has been changed", func() {
+		token := signer.Sign([]byte("some-payload"))
+		otherToken := signer.Sign([]byte("other-payload"))
+
+		tampered := strings.Split(otherToken, ".")[0] + "." + strings.Split(token, ".")[1]
+
+		_, err := signer.Verify(tampered)
+		Expect(err).To(Equal(util.ErrInvalidSignature))
+	})
+
+	It("rejects malformed tokens", func() {
+		_, err := signer.Verify("not-a-token")
+		Expect(err).To(Equal(util.ErrInvalidSignature))
+
+		_, err = signer.Verify("%%%.%%%")
+		Expect(err).To(Equal(util.ErrInvalidSignature))
+	})
+})
@@ -31,8 +31,8 @@ var _ = Describe("Default Template", func() {
 		Expect(template).To(Equal(support.Template{
 			Name:     "Default Template",
 			Subject:  "CF Notification: {{.Subject}}",
-			HTML:     "<p>{{.Endorsement}}</p>{{.HTML}}",
-			Text:     "{{.Endorsement}}\n{{.Text}}",
+			HTML:     "<p>{{.Endorsement}}</p>{{.HTML}}{{if .PreferencesURL}}<p><a href=\"{{.PreferencesURL}}\">Manage your notification preferences</a></p>{{end}}",
+			Text:     "{{.Endorsement}}\n{{.Text}}{{if .PreferencesURL}}\n\nManage your notification preferences: {{.PreferencesURL}}{{end}}",
 			Metadata: map[string]interface{}{},
 		}))
 	})
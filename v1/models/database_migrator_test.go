@@ -82,8 +82,8 @@ var _ = Describe("DatabaseMigrator", func() {
 			Expect(err).NotTo(HaveOccurred())
 			Expect(template.Name).To(Equal("Default Template"))
 			Expect(template.Subject).To(Equal("CF Notification: {{.Subject}}"))
-			Expect(template.HTML).To(Equal("<p>{{.Endorsement}}</p>{{.HTML}}"))
-			Expect(template.Text).To(Equal("{{.Endorsement}}\n{{.Text}}"))
+			Expect(template.HTML).To(Equal("<p>{{.Endorsement}}</p>{{.HTML}}{{if .PreferencesURL}}<p><a href=\"{{.PreferencesURL}}\">Manage your notification preferences</a></p>{{end}}"))
+			Expect(template.Text).To(Equal("{{.Endorsement}}\n{{.Text}}{{if .PreferencesURL}}\n\nManage your notification preferences: {{.PreferencesURL}}{{end}}"))
 			Expect(template.Metadata).To(Equal("{}"))
 		})
 
@@ -117,8 +117,8 @@ var _ = Describe("DatabaseMigrator", func() {
 				Expect(err).NotTo(HaveOccurred())
 				Expect(template.Name).To(Equal("Default Template"))
 				Expect(template.Subject).To(Equal("CF Notification: {{.Subject}}"))
-				Expect(template.HTML).To(Equal("<p>{{.Endorsement}}</p>{{.HTML}}"))
-				Expect(template.Text).To(Equal("{{.Endorsement}}\n{{.Text}}"))
+				Expect(template.HTML).To(Equal("<p>{{.Endorsement}}</p>{{.HTML}}{{if .PreferencesURL}}<p><a href=\"{{.PreferencesURL}}\">Manage your notification preferences</a></p>{{end}}"))
+				Expect(template.Text).To(Equal("{{.Endorsement}}\n{{.Text}}{{if .PreferencesURL}}\n\nManage your notification preferences: {{.PreferencesURL}}{{end}}"))
 				Expect(template.Metadata).To(Equal("{}"))
 				Expect(template.Overridden).To(BeFalse())
 			})
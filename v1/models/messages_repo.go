@@ -68,6 +68,9 @@ func (repo MessagesRepo) Upsert(conn ConnectionInterface, message Message) (Mess
 	}
 }
 
+// DeleteBefore removes the messages, and the delivery timings recorded for
+// them, that have not changed since the threshold. It returns the number of
+// messages removed.
 func (repo MessagesRepo) DeleteBefore(conn ConnectionInterface, threshold time.Time) (int, error) {
 	result, err := conn.Exec("DELETE FROM `messages` WHERE `updated_at` < ?", threshold.UTC())
 	if err != nil {
@@ -77,5 +80,11 @@ func (repo MessagesRepo) DeleteBefore(conn ConnectionInterface, threshold time.T
 	if err != nil {
 		return 0, err
 	}
+
+	_, err = conn.Exec("DELETE FROM `message_timings` WHERE `delivered_at` < ?", threshold.UTC())
+	if err != nil {
+		return 0, err
+	}
+
 	return int(count), nil
 }
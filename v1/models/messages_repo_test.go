@@ -138,5 +138,19 @@ var _ = Describe("MessagesRepo", func() {
 			_, err = repo.FindByID(conn, message.ID)
 			Expect(err).ToNot(HaveOccurred())
 		})
+
+		It("deletes delivery timings recorded before the input time", func() {
+			_, err := conn.Exec("INSERT INTO `message_timings` (`message_id`, `campaign_id`, `queue_wait`, `send_duration`, `delivered_at`) VALUES (?, ?, ?, ?, ?), (?, ?, ?, ?, ?)",
+				"old-message-id", "campaign-id", 1, 1, time.Now().UTC().Add(-2*time.Hour),
+				"new-message-id", "campaign-id", 1, 1, time.Now().UTC())
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = repo.DeleteBefore(conn, time.Now().Add(-1*time.Hour))
+			Expect(err).ToNot(HaveOccurred())
+
+			count, err := conn.GetDbMap().SelectInt("SELECT COUNT(*) FROM `message_timings`")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(count).To(Equal(int64(1)))
+		})
 	})
 })
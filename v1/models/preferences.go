@@ -5,5 +5,6 @@ type Preference struct {
 	KindID            string `db:"kind_id"`
 	KindDescription   string `db:"kind_description"`
 	SourceDescription string `db:"source_description"`
+	Digest            string `db:"digest"`
 	Email             bool
 }
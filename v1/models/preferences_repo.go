@@ -13,9 +13,14 @@ func (repo PreferencesRepo) FindNonCriticalPreferences(conn ConnectionInterface,
 	sql := `SELECT DISTINCT kinds.id AS kind_id,
 				clients.id AS client_id,
 				kinds.description AS kind_description,
-				clients.description AS source_description
+				clients.description AS source_description,
+				COALESCE(digest_preferences.frequency, 'immediate') AS digest
 			FROM kinds
 			JOIN clients on kinds.client_id = clients.id
+			LEFT JOIN digest_preferences
+				ON digest_preferences.user_guid = ?
+				AND digest_preferences.client_id = kinds.client_id
+				AND digest_preferences.campaign_type_id = kinds.id
 			WHERE kinds.client_id IN (
 				SELECT client_id
 				FROM receipts
@@ -23,7 +28,7 @@ func (repo PreferencesRepo) FindNonCriticalPreferences(conn ConnectionInterface,
 			)
 			AND kinds.critical = false`
 
-	_, err := conn.Select(&preferences, sql, userGUID)
+	_, err := conn.Select(&preferences, sql, userGUID, userGUID)
 	if err != nil {
 		return preferences, err
 	}
@@ -141,6 +141,7 @@ var _ = Describe("PreferencesRepo", func() {
 					Email:             false,
 					KindDescription:   "sleepy description",
 					SourceDescription: "raptors description",
+					Digest:            "immediate",
 				}))
 
 				Expect(results).To(ContainElement(models.Preference{
@@ -149,6 +150,7 @@ var _ = Describe("PreferencesRepo", func() {
 					Email:             true,
 					KindDescription:   "dead description",
 					SourceDescription: "raptors description",
+					Digest:            "immediate",
 				}))
 
 				Expect(results).To(ContainElement(models.Preference{
@@ -157,6 +159,7 @@ var _ = Describe("PreferencesRepo", func() {
 					Email:             true,
 					KindDescription:   "orange description",
 					SourceDescription: "raptors description",
+					Digest:            "immediate",
 				}))
 			})
 		})
@@ -22,6 +22,7 @@ type Options struct {
 	KindID            string
 	To                string
 	Role              string
+	Group             string
 	Endorsement       string
 	TemplateID        string
 }
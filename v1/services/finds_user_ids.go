@@ -2,8 +2,9 @@ package services
 
 import "github.com/cloudfoundry-incubator/notifications/cf"
 
-type uaaUsersGUIDsByScope interface {
+type uaaUsersGUIDs interface {
 	UsersGUIDsByScope(token, scope string) ([]string, error)
+	UsersGUIDsByGroup(token, groupName string) ([]string, error)
 }
 
 type cloudController interface {
@@ -12,26 +13,43 @@ type cloudController interface {
 	GetBillingManagersByOrgGuid(orgGUID, token string) ([]cf.CloudControllerUser, error)
 	GetUsersByOrgGuid(orgGUID, token string) ([]cf.CloudControllerUser, error)
 	GetUsersBySpaceGuid(spaceGUID, token string) ([]cf.CloudControllerUser, error)
+	GetManagersBySpaceGuid(spaceGUID, token string) ([]cf.CloudControllerUser, error)
+	GetDevelopersBySpaceGuid(spaceGUID, token string) ([]cf.CloudControllerUser, error)
+	GetAuditorsBySpaceGuid(spaceGUID, token string) ([]cf.CloudControllerUser, error)
 	LoadSpace(spaceGUID, token string) (cf.CloudControllerSpace, error)
 	LoadOrganization(orgGUID, token string) (cf.CloudControllerOrganization, error)
 }
 
 type FindsUserIDs struct {
 	cc  cloudController
-	uaa uaaUsersGUIDsByScope
+	uaa uaaUsersGUIDs
 }
 
-func NewFindsUserIDs(cc cloudController, uaa uaaUsersGUIDsByScope) FindsUserIDs {
+func NewFindsUserIDs(cc cloudController, uaa uaaUsersGUIDs) FindsUserIDs {
 	return FindsUserIDs{
 		cc:  cc,
 		uaa: uaa,
 	}
 }
 
-func (finder FindsUserIDs) UserIDsBelongingToSpace(spaceGUID, token string) ([]string, error) {
-	var userIDs []string
+func (finder FindsUserIDs) UserIDsBelongingToSpace(spaceGUID, role, token string) ([]string, error) {
+	var (
+		userIDs []string
+		users   []cf.CloudControllerUser
+		err     error
+	)
+
+	switch role {
+	case "SpaceManager":
+		users, err = finder.cc.GetManagersBySpaceGuid(spaceGUID, token)
+	case "SpaceDeveloper":
+		users, err = finder.cc.GetDevelopersBySpaceGuid(spaceGUID, token)
+	case "SpaceAuditor":
+		users, err = finder.cc.GetAuditorsBySpaceGuid(spaceGUID, token)
+	default:
+		users, err = finder.cc.GetUsersBySpaceGuid(spaceGUID, token)
+	}
 
-	users, err := finder.cc.GetUsersBySpaceGuid(spaceGUID, token)
 	if err != nil {
 		return userIDs, err
 	}
@@ -75,3 +93,7 @@ func (finder FindsUserIDs) UserIDsBelongingToOrganization(orgGUID, role, token s
 func (finder FindsUserIDs) UserIDsBelongingToScope(token, scope string) ([]string, error) {
 	return finder.uaa.UsersGUIDsByScope(token, scope)
 }
+
+func (finder FindsUserIDs) UserIDsBelongingToGroup(token, groupName string) ([]string, error) {
+	return finder.uaa.UsersGUIDsByGroup(token, groupName)
+}
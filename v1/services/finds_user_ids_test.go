@@ -49,6 +49,31 @@ var _ = Describe("FindsUserIDs", func() {
 		})
 	})
 
+	Context("UserIDsBelongingToGroup", func() {
+		BeforeEach(func() {
+			uaa.UsersGUIDsByGroupCall.Returns.UserGUIDs = []string{"user-402", "user-525"}
+		})
+
+		It("returns the userIDs that are members of that group", func() {
+			guids, err := finder.UserIDsBelongingToGroup("token", "security-oncall")
+
+			Expect(guids).To(Equal([]string{"user-402", "user-525"}))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(uaa.UsersGUIDsByGroupCall.Receives.Token).To(Equal("token"))
+			Expect(uaa.UsersGUIDsByGroupCall.Receives.GroupName).To(Equal("security-oncall"))
+		})
+
+		Context("when uaa has an error", func() {
+			It("returns the error", func() {
+				uaa.UsersGUIDsByGroupCall.Returns.Error = errors.New("foobar")
+
+				_, err := finder.UserIDsBelongingToGroup("token", "security-oncall")
+				Expect(err).To(MatchError(errors.New("foobar")))
+			})
+		})
+	})
+
 	Context("UserIDsBelongingToSpace", func() {
 		BeforeEach(func() {
 			cc.GetUsersBySpaceGuidCall.Returns.Users = []cf.CloudControllerUser{
@@ -58,7 +83,7 @@ var _ = Describe("FindsUserIDs", func() {
 		})
 
 		It("returns the user IDs for the space", func() {
-			guids, err := finder.UserIDsBelongingToSpace("space-001", "token")
+			guids, err := finder.UserIDsBelongingToSpace("space-001", "", "token")
 			Expect(err).NotTo(HaveOccurred())
 			Expect(guids).To(Equal([]string{"user-123", "user-789"}))
 
@@ -70,10 +95,88 @@ var _ = Describe("FindsUserIDs", func() {
 			It("returns the error", func() {
 				cc.GetUsersBySpaceGuidCall.Returns.Error = errors.New("BOOM!")
 
-				_, err := finder.UserIDsBelongingToSpace("space-001", "token")
+				_, err := finder.UserIDsBelongingToSpace("space-001", "", "token")
 				Expect(err).To(MatchError(errors.New("BOOM!")))
 			})
 		})
+
+		Context("when the role is SpaceManager", func() {
+			BeforeEach(func() {
+				cc.GetManagersBySpaceGuidCall.Returns.Users = []cf.CloudControllerUser{
+					{GUID: "user-m1"},
+				}
+			})
+
+			It("returns the space managers for the space", func() {
+				guids, err := finder.UserIDsBelongingToSpace("space-001", "SpaceManager", "token")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(guids).To(Equal([]string{"user-m1"}))
+
+				Expect(cc.GetManagersBySpaceGuidCall.Receives.SpaceGUID).To(Equal("space-001"))
+				Expect(cc.GetManagersBySpaceGuidCall.Receives.Token).To(Equal("token"))
+			})
+
+			Context("when CloudController causes an error", func() {
+				It("returns the error", func() {
+					cc.GetManagersBySpaceGuidCall.Returns.Error = errors.New("BOOM!")
+
+					_, err := finder.UserIDsBelongingToSpace("space-001", "SpaceManager", "token")
+					Expect(err).To(MatchError(errors.New("BOOM!")))
+				})
+			})
+		})
+
+		Context("when the role is SpaceDeveloper", func() {
+			BeforeEach(func() {
+				cc.GetDevelopersBySpaceGuidCall.Returns.Users = []cf.CloudControllerUser{
+					{GUID: "user-d1"},
+				}
+			})
+
+			It("returns the space developers for the space", func() {
+				guids, err := finder.UserIDsBelongingToSpace("space-001", "SpaceDeveloper", "token")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(guids).To(Equal([]string{"user-d1"}))
+
+				Expect(cc.GetDevelopersBySpaceGuidCall.Receives.SpaceGUID).To(Equal("space-001"))
+				Expect(cc.GetDevelopersBySpaceGuidCall.Receives.Token).To(Equal("token"))
+			})
+
+			Context("when CloudController causes an error", func() {
+				It("returns the error", func() {
+					cc.GetDevelopersBySpaceGuidCall.Returns.Error = errors.New("BOOM!")
+
+					_, err := finder.UserIDsBelongingToSpace("space-001", "SpaceDeveloper", "token")
+					Expect(err).To(MatchError(errors.New("BOOM!")))
+				})
+			})
+		})
+
+		Context("when the role is SpaceAuditor", func() {
+			BeforeEach(func() {
+				cc.GetAuditorsBySpaceGuidCall.Returns.Users = []cf.CloudControllerUser{
+					{GUID: "user-a1"},
+				}
+			})
+
+			It("returns the space auditors for the space", func() {
+				guids, err := finder.UserIDsBelongingToSpace("space-001", "SpaceAuditor", "token")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(guids).To(Equal([]string{"user-a1"}))
+
+				Expect(cc.GetAuditorsBySpaceGuidCall.Receives.SpaceGUID).To(Equal("space-001"))
+				Expect(cc.GetAuditorsBySpaceGuidCall.Receives.Token).To(Equal("token"))
+			})
+
+			Context("when CloudController causes an error", func() {
+				It("returns the error", func() {
+					cc.GetAuditorsBySpaceGuidCall.Returns.Error = errors.New("BOOM!")
+
+					_, err := finder.UserIDsBelongingToSpace("space-001", "SpaceAuditor", "token")
+					Expect(err).To(MatchError(errors.New("BOOM!")))
+				})
+			})
+		})
 	})
 
 	Context("UserIDsBelongingToOrganization", func() {
@@ -4,24 +4,50 @@ import (
 	"fmt"
 
 	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	v2models "github.com/cloudfoundry-incubator/notifications/v2/models"
 )
 
 type PreferenceUpdater struct {
 	globalUnsubscribesRepo GlobalUnsubscribesRepo
 	unsubscribesRepo       UnsubscribesRepo
 	kindsRepo              KindsRepo
+	digestPreferencesRepo  DigestPreferencesRepo
+	quietHoursRepo         QuietHoursRepo
+	preferenceChangesRepo  PreferenceChangesRepo
 }
 
-func NewPreferenceUpdater(globalUnsubscribesRepo GlobalUnsubscribesRepo, unsubscribesRepo UnsubscribesRepo, kindsRepo KindsRepo) PreferenceUpdater {
+func NewPreferenceUpdater(globalUnsubscribesRepo GlobalUnsubscribesRepo, unsubscribesRepo UnsubscribesRepo, kindsRepo KindsRepo,
+	digestPreferencesRepo DigestPreferencesRepo, quietHoursRepo QuietHoursRepo, preferenceChangesRepo PreferenceChangesRepo) PreferenceUpdater {
+
 	return PreferenceUpdater{
 		globalUnsubscribesRepo: globalUnsubscribesRepo,
 		unsubscribesRepo:       unsubscribesRepo,
 		kindsRepo:              kindsRepo,
+		digestPreferencesRepo:  digestPreferencesRepo,
+		quietHoursRepo:         quietHoursRepo,
+		preferenceChangesRepo:  preferenceChangesRepo,
 	}
 }
 
-func (updater PreferenceUpdater) Update(conn ConnectionInterface, preferences []models.Preference, globalUnsubscribe bool, userID string) error {
-	err := updater.globalUnsubscribesRepo.Set(conn, userID, globalUnsubscribe)
+// Update records each subscription it changes in the audit trail as made by
+// the actor.
+func (updater PreferenceUpdater) Update(conn ConnectionInterface, preferences []models.Preference, globalUnsubscribe bool, userID string, actor v2models.Actor) error {
+	globallyUnsubscribed, err := updater.globalUnsubscribesRepo.Get(conn, userID)
+	if err != nil {
+		return err
+	}
+
+	err = updater.globalUnsubscribesRepo.Set(conn, userID, globalUnsubscribe)
+	if err != nil {
+		return err
+	}
+
+	err = updater.preferenceChangesRepo.Record(conn, actor, v2models.PreferenceChange{
+		UserGUID:   userID,
+		Preference: v2models.PreferenceGlobal,
+		OldValue:   v2models.SubscriptionValue(globallyUnsubscribed),
+		NewValue:   v2models.SubscriptionValue(globalUnsubscribe),
+	})
 	if err != nil {
 		return err
 	}
@@ -36,10 +62,93 @@ func (updater PreferenceUpdater) Update(conn ConnectionInterface, preferences []
 			return CriticalKindError{fmt.Errorf("The kind '%s' for the '%s' client is critical and cannot be unsubscribed from", preference.KindID, preference.ClientID)}
 		}
 
+		unsubscribed, err := updater.unsubscribesRepo.Get(conn, userID, preference.ClientID, preference.KindID)
+		if err != nil {
+			return err
+		}
+
 		err = updater.unsubscribesRepo.Set(conn, userID, preference.ClientID, preference.KindID, !preference.Email)
 		if err != nil {
 			return err
 		}
+
+		err = updater.preferenceChangesRepo.Record(conn, actor, v2models.PreferenceChange{
+			UserGUID:   userID,
+			Preference: v2models.PreferenceKind,
+			ClientID:   preference.ClientID,
+			TargetID:   preference.KindID,
+			OldValue:   v2models.SubscriptionValue(unsubscribed),
+			NewValue:   v2models.SubscriptionValue(!preference.Email),
+		})
+		if err != nil {
+			return err
+		}
+
+		if preference.Digest != "" {
+			frequency, err := updater.digestPreferencesRepo.Get(conn, userID, preference.ClientID, preference.KindID)
+			if err != nil {
+				return err
+			}
+
+			err = updater.digestPreferencesRepo.Set(conn, v2models.DigestPreference{
+				UserGUID:       userID,
+				ClientID:       preference.ClientID,
+				CampaignTypeID: preference.KindID,
+				Frequency:      preference.Digest,
+			})
+			if err != nil {
+				return err
+			}
+
+			err = updater.preferenceChangesRepo.Record(conn, actor, v2models.PreferenceChange{
+				UserGUID:   userID,
+				Preference: v2models.PreferenceKindDigest,
+				ClientID:   preference.ClientID,
+				TargetID:   preference.KindID,
+				OldValue:   frequency,
+				NewValue:   preference.Digest,
+			})
+			if err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
+
+// UpdateQuietHours records the time zone and quiet hours changes in the audit
+// trail as made by the actor.
+func (updater PreferenceUpdater) UpdateQuietHours(conn ConnectionInterface, timeZone *string, quietHours *QuietHours, userID string, actor v2models.Actor) error {
+	existing, err := updater.quietHoursRepo.Get(conn, userID)
+	if err != nil {
+		return err
+	}
+
+	previous := existing
+	previous.UserGUID = userID
+
+	if timeZone != nil {
+		existing.TimeZone = *timeZone
+	}
+
+	if quietHours != nil {
+		existing.Start = quietHours.Start
+		existing.End = quietHours.End
+	}
+
+	existing.UserGUID = userID
+
+	err = updater.quietHoursRepo.Set(conn, existing)
+	if err != nil {
+		return err
+	}
+
+	for _, change := range v2models.QuietHoursChanges(userID, previous, existing) {
+		err = updater.preferenceChangesRepo.Record(conn, actor, change)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
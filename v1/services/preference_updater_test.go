@@ -6,6 +6,7 @@ import (
 	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
 	"github.com/cloudfoundry-incubator/notifications/v1/models"
 	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	v2models "github.com/cloudfoundry-incubator/notifications/v2/models"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -19,6 +20,10 @@ var _ = Describe("PreferenceUpdater", func() {
 			unsubscribesRepo           *mocks.UnsubscribesRepo
 			kindsRepo                  *mocks.KindsRepo
 			fakeGlobalUnsubscribesRepo *mocks.GlobalUnsubscribesRepo
+			digestPreferencesRepo      *mocks.DigestPreferencesRepository
+			quietHoursRepo             *mocks.QuietHoursRepository
+			preferenceChangesRepo      *mocks.PreferenceChangesRepository
+			actor                      v2models.Actor
 			conn                       *mocks.Connection
 			updater                    services.PreferenceUpdater
 		)
@@ -28,15 +33,23 @@ var _ = Describe("PreferenceUpdater", func() {
 			unsubscribesRepo = mocks.NewUnsubscribesRepo()
 			kindsRepo = mocks.NewKindsRepo()
 			fakeGlobalUnsubscribesRepo = mocks.NewGlobalUnsubscribesRepo()
-			updater = services.NewPreferenceUpdater(fakeGlobalUnsubscribesRepo, unsubscribesRepo, kindsRepo)
+			digestPreferencesRepo = mocks.NewDigestPreferencesRepository()
+			quietHoursRepo = mocks.NewQuietHoursRepository()
+			preferenceChangesRepo = mocks.NewPreferenceChangesRepository()
+			actor = v2models.Actor{
+				Type:   v2models.ActorUser,
+				ID:     "the-user",
+				Source: v2models.SourceAPI,
+			}
+			updater = services.NewPreferenceUpdater(fakeGlobalUnsubscribesRepo, unsubscribesRepo, kindsRepo, digestPreferencesRepo, quietHoursRepo, preferenceChangesRepo)
 		})
 
 		Context("when globally unsubscribing", func() {
 			It("inserts a record into the global unsubscribes repo", func() {
-				updater.Update(conn, []models.Preference{}, true, "user-guid")
+				updater.Update(conn, []models.Preference{}, true, "user-guid", actor)
 				Expect(fakeGlobalUnsubscribesRepo.SetCall.Receives.Unsubscribed).To(BeTrue())
 
-				updater.Update(conn, []models.Preference{}, false, "user-guid")
+				updater.Update(conn, []models.Preference{}, false, "user-guid", actor)
 				Expect(fakeGlobalUnsubscribesRepo.SetCall.Receives.Unsubscribed).To(BeFalse())
 			})
 
@@ -44,10 +57,27 @@ var _ = Describe("PreferenceUpdater", func() {
 				It("returns the error", func() {
 					fakeGlobalUnsubscribesRepo.SetCall.Returns.Error = errors.New("global unsubscribe db error")
 
-					err := updater.Update(conn, []models.Preference{}, true, "user-guid")
+					err := updater.Update(conn, []models.Preference{}, true, "user-guid", actor)
 					Expect(err).To(MatchError(errors.New("global unsubscribe db error")))
 				})
 			})
+
+			It("records the change", func() {
+				err := updater.Update(conn, []models.Preference{}, true, "user-guid", actor)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeGlobalUnsubscribesRepo.GetCall.Receives.UserID).To(Equal("user-guid"))
+				Expect(preferenceChangesRepo.RecordCall.Receives.Connection).To(Equal(conn))
+				Expect(preferenceChangesRepo.RecordCall.Receives.Actor).To(Equal(actor))
+				Expect(preferenceChangesRepo.RecordCall.Receives.Changes).To(Equal([]v2models.PreferenceChange{
+					{
+						UserGUID:   "user-guid",
+						Preference: v2models.PreferenceGlobal,
+						OldValue:   v2models.Subscribed,
+						NewValue:   v2models.Unsubscribed,
+					},
+				}))
+			})
 		})
 
 		Context("When unsubscribing from existing kinds of existing clients", func() {
@@ -83,7 +113,7 @@ var _ = Describe("PreferenceUpdater", func() {
 						KindID:   "door-open",
 						Email:    false,
 					},
-				}, false, "the-user")
+				}, false, "the-user", actor)
 
 				Expect(unsubscribesRepo.SetCall.Receives.Connection).To(Equal(conn))
 				Expect(unsubscribesRepo.SetCall.Receives.UserID).To(Equal("the-user"))
@@ -92,6 +122,39 @@ var _ = Describe("PreferenceUpdater", func() {
 				Expect(unsubscribesRepo.SetCall.Receives.Unsubscribe).To(BeTrue())
 			})
 
+			It("records each kind change", func() {
+				err := updater.Update(conn, []models.Preference{
+					{
+						ClientID: "raptors",
+						KindID:   "door-open",
+						Email:    false,
+					},
+				}, false, "the-user", actor)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(preferenceChangesRepo.RecordCall.Receives.Changes).To(ContainElement(v2models.PreferenceChange{
+					UserGUID:   "the-user",
+					Preference: v2models.PreferenceKind,
+					ClientID:   "raptors",
+					TargetID:   "door-open",
+					OldValue:   v2models.Subscribed,
+					NewValue:   v2models.Unsubscribed,
+				}))
+			})
+
+			It("returns the error when a change cannot be recorded", func() {
+				preferenceChangesRepo.RecordCall.Returns.Error = errors.New("audit db error")
+
+				err := updater.Update(conn, []models.Preference{
+					{
+						ClientID: "raptors",
+						KindID:   "door-open",
+						Email:    false,
+					},
+				}, false, "the-user", actor)
+				Expect(err).To(MatchError(errors.New("audit db error")))
+			})
+
 			It("does not add resubscriptions to the unsubscribes Repo", func() {
 				updater.Update(conn, []models.Preference{
 					{
@@ -99,7 +162,7 @@ var _ = Describe("PreferenceUpdater", func() {
 						KindID:   "barking",
 						Email:    true,
 					},
-				}, false, "the-user")
+				}, false, "the-user", actor)
 
 				unsubscribed, err := unsubscribesRepo.Get(conn, "the-user", "dogs", "barking")
 				Expect(err).NotTo(HaveOccurred())
@@ -116,13 +179,80 @@ var _ = Describe("PreferenceUpdater", func() {
 						KindID:   "door-open",
 						Email:    true,
 					},
-				}, false, "my-user")
+				}, false, "my-user", actor)
 				Expect(err).NotTo(HaveOccurred())
 
 				unsubscribed, err := unsubscribesRepo.Get(conn, "my-user", "raptors", "door-open")
 				Expect(err).NotTo(HaveOccurred())
 				Expect(unsubscribed).To(BeFalse())
 			})
+
+			It("sets the digest frequency when one is given", func() {
+				err := updater.Update(conn, []models.Preference{
+					{
+						ClientID: "raptors",
+						KindID:   "door-open",
+						Email:    true,
+						Digest:   "daily",
+					},
+					{
+						ClientID: "dogs",
+						KindID:   "barking",
+						Email:    true,
+					},
+				}, false, "the-user", actor)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(digestPreferencesRepo.SetCall.Receives.Connection).To(Equal(conn))
+				Expect(digestPreferencesRepo.SetCall.Receives.Preferences).To(Equal([]v2models.DigestPreference{
+					{
+						UserGUID:       "the-user",
+						ClientID:       "raptors",
+						CampaignTypeID: "door-open",
+						Frequency:      "daily",
+					},
+				}))
+			})
+
+			It("records the digest frequency change", func() {
+				digestPreferencesRepo.GetCall.Returns.Frequency = v2models.DigestImmediate
+
+				err := updater.Update(conn, []models.Preference{
+					{
+						ClientID: "raptors",
+						KindID:   "door-open",
+						Email:    true,
+						Digest:   "daily",
+					},
+				}, false, "the-user", actor)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(digestPreferencesRepo.GetCall.Receives.UserGUID).To(Equal("the-user"))
+				Expect(digestPreferencesRepo.GetCall.Receives.ClientID).To(Equal("raptors"))
+				Expect(digestPreferencesRepo.GetCall.Receives.CampaignTypeID).To(Equal("door-open"))
+				Expect(preferenceChangesRepo.RecordCall.Receives.Changes).To(ContainElement(v2models.PreferenceChange{
+					UserGUID:   "the-user",
+					Preference: v2models.PreferenceKindDigest,
+					ClientID:   "raptors",
+					TargetID:   "door-open",
+					OldValue:   v2models.DigestImmediate,
+					NewValue:   "daily",
+				}))
+			})
+
+			It("returns the error when the digest frequency cannot be set", func() {
+				digestPreferencesRepo.SetCall.Returns.Error = errors.New("digest db error")
+
+				err := updater.Update(conn, []models.Preference{
+					{
+						ClientID: "raptors",
+						KindID:   "door-open",
+						Email:    true,
+						Digest:   "hourly",
+					},
+				}, false, "the-user", actor)
+				Expect(err).To(MatchError(errors.New("digest db error")))
+			})
 		})
 
 		Context("when unsubscribing from missing client", func() {
@@ -153,7 +283,7 @@ var _ = Describe("PreferenceUpdater", func() {
 				}
 				kindsRepo.FindCall.Returns.Error = errors.New("something bad happened")
 
-				err := updater.Update(conn, preferences, false, "the-user")
+				err := updater.Update(conn, preferences, false, "the-user", actor)
 				Expect(err).To(MatchError(services.MissingKindOrClientError{errors.New("The kind 'boo' cannot be found for client 'ghosts'")}))
 			})
 		})
@@ -181,7 +311,7 @@ var _ = Describe("PreferenceUpdater", func() {
 				}
 				kindsRepo.FindCall.Returns.Error = errors.New("something bad happened")
 
-				err := updater.Update(conn, preferences, false, "the-user")
+				err := updater.Update(conn, preferences, false, "the-user", actor)
 				Expect(err).To(Equal(services.MissingKindOrClientError{errors.New("The kind 'dead' cannot be found for client 'raptors'")}))
 			})
 		})
@@ -213,9 +343,94 @@ var _ = Describe("PreferenceUpdater", func() {
 					},
 				}
 
-				err := updater.Update(conn, preferences, false, "the-user")
+				err := updater.Update(conn, preferences, false, "the-user", actor)
 				Expect(err).To(Equal(services.CriticalKindError{errors.New("The kind 'hungry' for the 'raptors' client is critical and cannot be unsubscribed from")}))
 			})
 		})
 	})
+
+	Describe("UpdateQuietHours", func() {
+		var (
+			quietHoursRepo        *mocks.QuietHoursRepository
+			preferenceChangesRepo *mocks.PreferenceChangesRepository
+			actor                 v2models.Actor
+			conn                  *mocks.Connection
+			updater               services.PreferenceUpdater
+		)
+
+		BeforeEach(func() {
+			conn = mocks.NewConnection()
+			quietHoursRepo = mocks.NewQuietHoursRepository()
+			quietHoursRepo.GetCall.Returns.QuietHours = v2models.QuietHours{
+				UserGUID: "the-user",
+				TimeZone: "America/Chicago",
+				Start:    "22:00",
+				End:      "07:00",
+			}
+			preferenceChangesRepo = mocks.NewPreferenceChangesRepository()
+			actor = v2models.Actor{
+				Type:   v2models.ActorUser,
+				ID:     "the-user",
+				Source: v2models.SourceAPI,
+			}
+			updater = services.NewPreferenceUpdater(mocks.NewGlobalUnsubscribesRepo(), mocks.NewUnsubscribesRepo(), mocks.NewKindsRepo(), mocks.NewDigestPreferencesRepository(), quietHoursRepo, preferenceChangesRepo)
+		})
+
+		It("sets the time zone, keeping the existing quiet hours", func() {
+			timeZone := "Europe/Paris"
+			err := updater.UpdateQuietHours(conn, &timeZone, nil, "the-user", actor)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(quietHoursRepo.GetCall.Receives.UserGUID).To(Equal("the-user"))
+			Expect(quietHoursRepo.SetCall.Receives.Connection).To(Equal(conn))
+			Expect(quietHoursRepo.SetCall.Receives.QuietHours).To(Equal(v2models.QuietHours{
+				UserGUID: "the-user",
+				TimeZone: "Europe/Paris",
+				Start:    "22:00",
+				End:      "07:00",
+			}))
+		})
+
+		It("sets the quiet hours, keeping the existing time zone", func() {
+			err := updater.UpdateQuietHours(conn, nil, &services.QuietHours{Start: "23:00", End: "06:30"}, "the-user", actor)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(quietHoursRepo.SetCall.Receives.QuietHours).To(Equal(v2models.QuietHours{
+				UserGUID: "the-user",
+				TimeZone: "America/Chicago",
+				Start:    "23:00",
+				End:      "06:30",
+			}))
+		})
+
+		It("records the time zone and quiet hours changes", func() {
+			timeZone := "Europe/Paris"
+			err := updater.UpdateQuietHours(conn, &timeZone, &services.QuietHours{Start: "23:00", End: "06:30"}, "the-user", actor)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(preferenceChangesRepo.RecordCall.Receives.Connection).To(Equal(conn))
+			Expect(preferenceChangesRepo.RecordCall.Receives.Actor).To(Equal(actor))
+			Expect(preferenceChangesRepo.RecordCall.Receives.Changes).To(Equal([]v2models.PreferenceChange{
+				{
+					UserGUID:   "the-user",
+					Preference: v2models.PreferenceTimeZone,
+					OldValue:   "America/Chicago",
+					NewValue:   "Europe/Paris",
+				},
+				{
+					UserGUID:   "the-user",
+					Preference: v2models.PreferenceQuietHours,
+					OldValue:   "22:00-07:00",
+					NewValue:   "23:00-06:30",
+				},
+			}))
+		})
+
+		It("returns an error when the quiet hours cannot be saved", func() {
+			quietHoursRepo.SetCall.Returns.Error = errors.New("db error")
+
+			err := updater.UpdateQuietHours(conn, nil, &services.QuietHours{}, "the-user", actor)
+			Expect(err).To(MatchError(errors.New("db error")))
+		})
+	})
 })
@@ -4,20 +4,29 @@ import (
 	"errors"
 
 	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	v2models "github.com/cloudfoundry-incubator/notifications/v2/models"
 )
 
 type Kind struct {
 	Email             *bool  `json:"email"`
 	KindDescription   string `json:"kind_description"`
 	SourceDescription string `json:"source_description"`
+	Digest            string `json:"digest,omitempty"`
 }
 
 type ClientMap map[string]Kind
 type ClientsMap map[string]ClientMap
 
+type QuietHours struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
 type PreferencesBuilder struct {
-	GlobalUnsubscribe bool       `json:"global_unsubscribe"`
-	Clients           ClientsMap `json:"clients"`
+	GlobalUnsubscribe bool        `json:"global_unsubscribe"`
+	TimeZone          *string     `json:"time_zone,omitempty"`
+	QuietHours        *QuietHours `json:"quiet_hours,omitempty"`
+	Clients           ClientsMap  `json:"clients"`
 }
 
 func NewPreferencesBuilder() PreferencesBuilder {
@@ -39,6 +48,7 @@ func (pref PreferencesBuilder) Add(preference models.Preference) {
 		Email:             &preference.Email,
 		KindDescription:   preference.KindDescription,
 		SourceDescription: preference.SourceDescription,
+		Digest:            preference.Digest,
 	}
 
 	if clientMap, ok := pref.Clients[preference.ClientID]; ok {
@@ -52,6 +62,17 @@ func (pref PreferencesBuilder) Add(preference models.Preference) {
 
 func (pref PreferencesBuilder) ToPreferences() ([]models.Preference, error) {
 	preferences := []models.Preference{}
+
+	if pref.TimeZone != nil && !v2models.ValidTimeZone(*pref.TimeZone) {
+		return preferences, errors.New("Invalid time zone")
+	}
+
+	if pref.QuietHours != nil && (pref.QuietHours.Start != "" || pref.QuietHours.End != "") {
+		if !v2models.ValidClockTime(pref.QuietHours.Start) || !v2models.ValidClockTime(pref.QuietHours.End) {
+			return preferences, errors.New("Invalid quiet hours")
+		}
+	}
+
 	for clientID, kinds := range pref.Clients {
 		if len(kinds) == 0 {
 			return preferences, errors.New("Missing kinds")
@@ -63,10 +84,15 @@ func (pref PreferencesBuilder) ToPreferences() ([]models.Preference, error) {
 				return preferences, errors.New("Missing the email field")
 			}
 
+			if kind.Digest != "" && !v2models.ValidDigestFrequency(kind.Digest) {
+				return preferences, errors.New("Invalid digest frequency")
+			}
+
 			preferences = append(preferences, models.Preference{
 				ClientID: clientID,
 				KindID:   kindID,
 				Email:    *kind.Email,
+				Digest:   kind.Digest,
 			})
 		}
 	}
@@ -150,6 +150,7 @@ var _ = Describe("NotificationsPreferences", func() {
 				ClientID: "dogs",
 				KindID:   "barking",
 				Email:    true,
+				Digest:   "hourly",
 			})
 
 			preferences, err := builder.ToPreferences()
@@ -172,6 +173,7 @@ var _ = Describe("NotificationsPreferences", func() {
 				ClientID: "dogs",
 				KindID:   "barking",
 				Email:    true,
+				Digest:   "hourly",
 			}))
 		})
 
@@ -213,6 +215,39 @@ var _ = Describe("NotificationsPreferences", func() {
 
 				Expect(err).ToNot(BeNil())
 			})
+
+			It("returns an error when the digest frequency is not valid", func() {
+				badBuilder.Add(models.Preference{
+					ClientID: "TRex",
+					KindID:   "glass-of-water",
+					Email:    true,
+					Digest:   "weekly",
+				})
+
+				_, err := badBuilder.ToPreferences()
+
+				Expect(err).To(MatchError("Invalid digest frequency"))
+			})
+
+			It("returns an error when the time zone is not valid", func() {
+				timeZone := "Mars/Olympus_Mons"
+				badBuilder.TimeZone = &timeZone
+
+				_, err := badBuilder.ToPreferences()
+
+				Expect(err).To(MatchError("Invalid time zone"))
+			})
+
+			It("returns an error when the quiet hours are not valid", func() {
+				badBuilder.QuietHours = &services.QuietHours{
+					Start: "22:00",
+					End:   "7am",
+				}
+
+				_, err := badBuilder.ToPreferences()
+
+				Expect(err).To(MatchError("Invalid quiet hours"))
+			})
 		})
 	})
 })
@@ -3,12 +3,14 @@ package services
 type PreferencesFinder struct {
 	preferencesRepo        PreferencesRepo
 	globalUnsubscribesRepo GlobalUnsubscribesRepo
+	quietHoursRepo         QuietHoursRepo
 }
 
-func NewPreferencesFinder(preferencesRepo PreferencesRepo, globalUnsubscribesRepo GlobalUnsubscribesRepo) *PreferencesFinder {
+func NewPreferencesFinder(preferencesRepo PreferencesRepo, globalUnsubscribesRepo GlobalUnsubscribesRepo, quietHoursRepo QuietHoursRepo) *PreferencesFinder {
 	return &PreferencesFinder{
 		preferencesRepo:        preferencesRepo,
 		globalUnsubscribesRepo: globalUnsubscribesRepo,
+		quietHoursRepo:         quietHoursRepo,
 	}
 }
 
@@ -26,7 +28,24 @@ func (finder PreferencesFinder) Find(database DatabaseInterface, userGUID string
 		return builder, err
 	}
 
+	quietHours, err := finder.quietHoursRepo.Get(conn, userGUID)
+	if err != nil {
+		return builder, err
+	}
+
 	builder.GlobalUnsubscribe = globallyUnsubscribed
+
+	if quietHours.TimeZone != "" {
+		builder.TimeZone = &quietHours.TimeZone
+	}
+
+	if quietHours.Start != "" || quietHours.End != "" {
+		builder.QuietHours = &QuietHours{
+			Start: quietHours.Start,
+			End:   quietHours.End,
+		}
+	}
+
 	for _, preference := range preferences {
 		builder.Add(preference)
 	}
@@ -6,6 +6,7 @@ import (
 	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
 	"github.com/cloudfoundry-incubator/notifications/v1/models"
 	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	v2models "github.com/cloudfoundry-incubator/notifications/v2/models"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -15,6 +16,7 @@ var _ = Describe("PreferencesFinder", func() {
 	var (
 		finder          *services.PreferencesFinder
 		preferencesRepo *mocks.PreferencesRepo
+		quietHoursRepo  *mocks.QuietHoursRepository
 		preferences     []models.Preference
 		database        *mocks.Database
 		conn            *mocks.Connection
@@ -44,11 +46,13 @@ var _ = Describe("PreferencesFinder", func() {
 		preferencesRepo = mocks.NewPreferencesRepo()
 		preferencesRepo.FindNonCriticalPreferencesCall.Returns.Preferences = preferences
 
+		quietHoursRepo = mocks.NewQuietHoursRepository()
+
 		conn = mocks.NewConnection()
 		database = mocks.NewDatabase()
 		database.ConnectionCall.Returns.Connection = conn
 
-		finder = services.NewPreferencesFinder(preferencesRepo, fakeGlobalUnsubscribesRepo)
+		finder = services.NewPreferencesFinder(preferencesRepo, fakeGlobalUnsubscribesRepo, quietHoursRepo)
 	})
 
 	Describe("Find", func() {
@@ -66,6 +70,35 @@ var _ = Describe("PreferencesFinder", func() {
 			Expect(preferencesRepo.FindNonCriticalPreferencesCall.Receives.UserGUID).To(Equal("correct-user"))
 		})
 
+		It("includes the time zone and quiet hours when they are set", func() {
+			quietHoursRepo.GetCall.Returns.QuietHours = v2models.QuietHours{
+				UserGUID: "correct-user",
+				TimeZone: "America/Chicago",
+				Start:    "22:00",
+				End:      "07:00",
+			}
+
+			resultPreferences, err := finder.Find(database, "correct-user")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*resultPreferences.TimeZone).To(Equal("America/Chicago"))
+			Expect(*resultPreferences.QuietHours).To(Equal(services.QuietHours{
+				Start: "22:00",
+				End:   "07:00",
+			}))
+
+			Expect(quietHoursRepo.GetCall.Receives.Connection).To(Equal(conn))
+			Expect(quietHoursRepo.GetCall.Receives.UserGUID).To(Equal("correct-user"))
+		})
+
+		Context("when the quiet hours repo returns an error", func() {
+			It("should propagate the error", func() {
+				quietHoursRepo.GetCall.Returns.Error = errors.New("BOOM!")
+
+				_, err := finder.Find(database, "correct-user")
+				Expect(err).To(MatchError(errors.New("BOOM!")))
+			})
+		})
+
 		Context("when the preferences repo returns an error", func() {
 			It("should propagate the error", func() {
 				preferencesRepo.FindNonCriticalPreferencesCall.Returns.Error = errors.New("BOOM!")
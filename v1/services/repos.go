@@ -1,6 +1,9 @@
 package services
 
-import "github.com/cloudfoundry-incubator/notifications/v1/models"
+import (
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	v2models "github.com/cloudfoundry-incubator/notifications/v2/models"
+)
 
 type ClientsRepo interface {
 	Find(connection models.ConnectionInterface, clientID string) (models.Client, error)
@@ -32,9 +35,24 @@ type TemplatesRepo interface {
 }
 
 type UnsubscribesRepo interface {
+	Get(connection models.ConnectionInterface, userID, clientID, kindID string) (bool, error)
 	Set(connection models.ConnectionInterface, userID string, clientID string, kindID string, unsubscribe bool) error
 }
 
+type DigestPreferencesRepo interface {
+	Get(connection v2models.ConnectionInterface, userGUID, clientID, campaignTypeID string) (string, error)
+	Set(connection v2models.ConnectionInterface, preference v2models.DigestPreference) error
+}
+
+type QuietHoursRepo interface {
+	Get(connection v2models.ConnectionInterface, userGUID string) (v2models.QuietHours, error)
+	Set(connection v2models.ConnectionInterface, quietHours v2models.QuietHours) error
+}
+
+type PreferenceChangesRepo interface {
+	Record(connection v2models.ConnectionInterface, actor v2models.Actor, change v2models.PreferenceChange) error
+}
+
 type GlobalUnsubscribesRepo interface {
 	Get(connection models.ConnectionInterface, userGUID string) (bool, error)
 	Set(connection models.ConnectionInterface, userGUID string, unsubscribe bool) error
@@ -2,10 +2,13 @@ package services
 
 import "github.com/cloudfoundry-incubator/notifications/cf"
 
-const SpaceEndorsement = `You received this message because you belong to the "{{.Space}}" space in the "{{.Organization}}" organization.`
+const (
+	SpaceEndorsement     = `You received this message because you belong to the "{{.Space}}" space in the "{{.Organization}}" organization.`
+	SpaceRoleEndorsement = `You received this message because you are a {{.SpaceRole}} in the "{{.Space}}" space in the "{{.Organization}}" organization.`
+)
 
 type spaceUserIDFinder interface {
-	UserIDsBelongingToSpace(spaceGUID, token string) (userIDs []string, err error)
+	UserIDsBelongingToSpace(spaceGUID, role, token string) (userIDs []string, err error)
 }
 
 type loadsSpaces interface {
@@ -52,12 +55,16 @@ func (strategy SpaceStrategy) Dispatch(dispatch Dispatch) ([]Response, error) {
 		},
 	}
 
+	if dispatch.Role != "" {
+		options.Endorsement = SpaceRoleEndorsement
+	}
+
 	token, err := strategy.tokenLoader.Load(dispatch.UAAHost)
 	if err != nil {
 		return responses, err
 	}
 
-	userGUIDs, err := strategy.findsUserIDs.UserIDsBelongingToSpace(dispatch.GUID, token)
+	userGUIDs, err := strategy.findsUserIDs.UserIDsBelongingToSpace(dispatch.GUID, options.Role, token)
 	if err != nil {
 		return responses, err
 	}
@@ -146,8 +146,43 @@ var _ = Describe("Space Strategy", func() {
 					Expect(tokenLoader.LoadCall.Receives.UAAHost).To(Equal("uaa"))
 
 					Expect(findsUserIDs.UserIDsBelongingToSpaceCall.Receives.SpaceGUID).To(Equal("space-001"))
+					Expect(findsUserIDs.UserIDsBelongingToSpaceCall.Receives.Role).To(Equal(""))
 					Expect(findsUserIDs.UserIDsBelongingToSpaceCall.Receives.Token).To(Equal(token))
 				})
+
+				Context("when the dispatch includes a space role", func() {
+					It("finds the users with that role and endorses them accordingly", func() {
+						_, err := strategy.Dispatch(services.Dispatch{
+							GUID:       "space-001",
+							Role:       "SpaceDeveloper",
+							Connection: conn,
+							Message: services.DispatchMessage{
+								Subject: "this is the subject",
+								Text:    "Please reset your password by clicking on this link...",
+							},
+							Kind: services.DispatchKind{
+								ID: "forgot_password",
+							},
+							Client: services.DispatchClient{
+								ID: "mister-client",
+							},
+							UAAHost: "uaa",
+						})
+						Expect(err).NotTo(HaveOccurred())
+
+						Expect(enqueuer.EnqueueCall.Receives.Options).To(Equal(services.Options{
+							Subject:     "this is the subject",
+							KindID:      "forgot_password",
+							Text:        "Please reset your password by clicking on this link...",
+							Role:        "SpaceDeveloper",
+							Endorsement: services.SpaceRoleEndorsement,
+						}))
+
+						Expect(findsUserIDs.UserIDsBelongingToSpaceCall.Receives.SpaceGUID).To(Equal("space-001"))
+						Expect(findsUserIDs.UserIDsBelongingToSpaceCall.Receives.Role).To(Equal("SpaceDeveloper"))
+						Expect(findsUserIDs.UserIDsBelongingToSpaceCall.Receives.Token).To(Equal(token))
+					})
+				})
 			})
 		})
 
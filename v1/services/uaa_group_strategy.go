@@ -0,0 +1,64 @@
+package services
+
+import "github.com/cloudfoundry-incubator/notifications/cf"
+
+const GroupEndorsement = "You received this message because you are a member of the {{.Group}} group."
+
+type groupUserIDFinder interface {
+	UserIDsBelongingToGroup(token, groupName string) (userIDs []string, err error)
+}
+
+type UAAGroupStrategy struct {
+	findsUserIDs groupUserIDFinder
+	tokenLoader  loadsTokens
+	enqueuer     enqueuer
+}
+
+func NewUAAGroupStrategy(tokenLoader loadsTokens, findsUserIDs groupUserIDFinder, enqueuer enqueuer) UAAGroupStrategy {
+	return UAAGroupStrategy{
+		findsUserIDs: findsUserIDs,
+		tokenLoader:  tokenLoader,
+		enqueuer:     enqueuer,
+	}
+}
+
+func (strategy UAAGroupStrategy) Dispatch(dispatch Dispatch) ([]Response, error) {
+	responses := []Response{}
+	options := Options{
+		ReplyTo:           dispatch.Message.ReplyTo,
+		Subject:           dispatch.Message.Subject,
+		To:                dispatch.Message.To,
+		Group:             dispatch.GUID,
+		Endorsement:       GroupEndorsement,
+		KindID:            dispatch.Kind.ID,
+		KindDescription:   dispatch.Kind.Description,
+		SourceDescription: dispatch.Client.Description,
+		Text:              dispatch.Message.Text,
+		TemplateID:        dispatch.TemplateID,
+		HTML: HTML{
+			BodyContent:    dispatch.Message.HTML.BodyContent,
+			BodyAttributes: dispatch.Message.HTML.BodyAttributes,
+			Head:           dispatch.Message.HTML.Head,
+			Doctype:        dispatch.Message.HTML.Doctype,
+		},
+	}
+
+	token, err := strategy.tokenLoader.Load(dispatch.UAAHost)
+	if err != nil {
+		return responses, err
+	}
+
+	userGUIDs, err := strategy.findsUserIDs.UserIDsBelongingToGroup(token, dispatch.GUID)
+	if err != nil {
+		return responses, err
+	}
+
+	var users []User
+	for _, guid := range userGUIDs {
+		users = append(users, User{GUID: guid})
+	}
+
+	responses = strategy.enqueuer.Enqueue(dispatch.Connection, users, options, cf.CloudControllerSpace{}, cf.CloudControllerOrganization{}, dispatch.Client.ID, dispatch.UAAHost, "", dispatch.VCAPRequest.ID, dispatch.VCAPRequest.ReceiptTime)
+
+	return responses, nil
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/PuerkitoBio/goquery"
+	"github.com/cloudfoundry-incubator/notifications/markdown"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
 )
 
@@ -15,17 +16,19 @@ const InvalidEmail = "<>InvalidEmail<>"
 
 var (
 	validOrganizationRoles = []string{"OrgManager", "OrgAuditor", "BillingManager"}
+	validSpaceRoles        = []string{"SpaceManager", "SpaceDeveloper", "SpaceAuditor"}
 	emailRegexp            = regexp.MustCompile("[^<]*<([^@]*@[^@]*)>|([^<][^@]*@[^@]*)")
 )
 
 type NotifyParams struct {
-	ReplyTo string `json:"reply_to"`
-	Subject string `json:"subject"`
-	Text    string `json:"text"`
-	RawHTML string `json:"html"`
-	KindID  string `json:"kind_id"`
-	To      string `json:"to"`
-	Role    string `json:"role"`
+	ReplyTo  string `json:"reply_to"`
+	Subject  string `json:"subject"`
+	Text     string `json:"text"`
+	RawHTML  string `json:"html"`
+	Markdown string `json:"markdown"`
+	KindID   string `json:"kind_id"`
+	To       string `json:"to"`
+	Role     string `json:"role"`
 
 	ParsedHTML        HTML
 	KindDescription   string
@@ -73,6 +76,8 @@ func (notify *NotifyParams) parseRequestBody(body io.ReadCloser) error {
 func (notify *NotifyParams) FormatEmailAndExtractHTML() error {
 	notify.To = EmailFormatter{}.Format(notify.To)
 
+	notify.RawHTML, notify.Text = markdown.NewRenderer().Fill(notify.Markdown, notify.RawHTML, notify.Text)
+
 	doctype, head, bodyContent, bodyAttributes, err := HTMLExtractor{}.Extract(notify.RawHTML)
 	if err != nil {
 		return err
@@ -36,6 +36,33 @@ var _ = Describe("NotifyParams", func() {
 			}).NotTo(Panic())
 		})
 
+		Describe("markdown parsing", func() {
+			It("renders the markdown into the html and text fields", func() {
+				parameters, err := notify.NewNotifyParams(ioutil.NopCloser(strings.NewReader(`{
+                    "kind_id": "test_email",
+                    "markdown": "# Outage\n\nSee [status](https://status.example.com)"
+                }`)))
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(parameters.RawHTML).To(Equal(`<h1>Outage</h1>` + "\n" + `<p>See <a href="https://status.example.com">status</a></p>`))
+				Expect(parameters.ParsedHTML.BodyContent).To(Equal(`<h1>Outage</h1>` + "\n" + `<p>See <a href="https://status.example.com">status</a></p>`))
+				Expect(parameters.Text).To(Equal("Outage\n======\n\nSee status (https://status.example.com)"))
+			})
+
+			It("does not overwrite explicitly provided html or text", func() {
+				parameters, err := notify.NewNotifyParams(ioutil.NopCloser(strings.NewReader(`{
+                    "kind_id": "test_email",
+                    "markdown": "*rendered*",
+                    "html": "<p>provided</p>",
+                    "text": "provided"
+                }`)))
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(parameters.ParsedHTML.BodyContent).To(Equal("<p>provided</p>"))
+				Expect(parameters.Text).To(Equal("provided"))
+			})
+		})
+
 		Describe("to field parsing", func() {
 			It("handles when a name is attached to the address", func() {
 				parameters, err := notify.NewNotifyParams(ioutil.NopCloser(strings.NewReader(`{
@@ -1,6 +1,10 @@
 package notify
 
-import "regexp"
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
 
 var kindIDFormat = regexp.MustCompile(`^[0-9a-zA-Z_\-.]+$`)
 
@@ -24,7 +28,9 @@ func (validator EmailValidator) Validate(notify *NotifyParams) bool {
 	return len(notify.Errors) == 0
 }
 
-type GUIDValidator struct{}
+type GUIDValidator struct {
+	Roles []string
+}
 
 func (validator GUIDValidator) Validate(notify *NotifyParams) bool {
 	notify.Errors = []string{}
@@ -36,7 +42,7 @@ func (validator GUIDValidator) Validate(notify *NotifyParams) bool {
 	}
 
 	if validator.invalidRoleField(notify.Role) {
-		notify.Errors = append(notify.Errors, `"role" must be "OrgManager", "OrgAuditor", "BillingManager" or unset`)
+		notify.Errors = append(notify.Errors, validator.roleFieldError())
 	}
 
 	return len(notify.Errors) == 0
@@ -51,7 +57,7 @@ func (validator GUIDValidator) invalidRoleField(roleName string) bool {
 		return false
 	}
 
-	for _, role := range validOrganizationRoles {
+	for _, role := range validator.roles() {
 		if roleName == role {
 			return false
 		}
@@ -59,6 +65,23 @@ func (validator GUIDValidator) invalidRoleField(roleName string) bool {
 	return true
 }
 
+func (validator GUIDValidator) roles() []string {
+	if validator.Roles == nil {
+		return validOrganizationRoles
+	}
+
+	return validator.Roles
+}
+
+func (validator GUIDValidator) roleFieldError() string {
+	var roles []string
+	for _, role := range validator.roles() {
+		roles = append(roles, fmt.Sprintf("%q", role))
+	}
+
+	return fmt.Sprintf(`"role" must be %s or unset`, strings.Join(roles, ", "))
+}
+
 func (validator GUIDValidator) checkKindIDField(notify *NotifyParams) {
 	if notify.KindID == "" {
 		notify.Errors = append(notify.Errors, `"kind_id" is a required field`)
@@ -124,6 +124,23 @@ var _ = Describe("Validator", func() {
 				Expect(len(params.Errors)).To(Equal(1))
 				Expect(params.Errors).To(ContainElement(`"role" must be "OrgManager", "OrgAuditor", "BillingManager" or unset`))
 			})
+
+			Context("when the validator is given its own roles", func() {
+				It("validates that the role is one of them or empty", func() {
+					validator = notify.GUIDValidator{Roles: []string{"SpaceManager", "SpaceDeveloper", "SpaceAuditor"}}
+
+					for _, role := range []string{"SpaceManager", "SpaceDeveloper", "SpaceAuditor", ""} {
+						params.Role = role
+						Expect(validator.Validate(params)).To(BeTrue())
+						Expect(len(params.Errors)).To(Equal(0))
+					}
+
+					params.Role = "OrgManager"
+					Expect(validator.Validate(params)).To(BeFalse())
+					Expect(len(params.Errors)).To(Equal(1))
+					Expect(params.Errors).To(ContainElement(`"role" must be "SpaceManager", "SpaceDeveloper", "SpaceAuditor" or unset`))
+				})
+			})
 		})
 	})
 })
@@ -20,6 +20,7 @@ type Routes struct {
 	OrganizationStrategy Dispatcher
 	EveryoneStrategy     Dispatcher
 	UAAScopeStrategy     Dispatcher
+	UAAGroupStrategy     Dispatcher
 	EmailStrategy        Dispatcher
 }
 
@@ -29,5 +30,6 @@ func (r Routes) Register(m muxer) {
 	m.Handle("POST", "/organizations/{org_id}", NewOrganizationHandler(r.Notify, r.ErrorWriter, r.OrganizationStrategy), r.RequestLogging, r.RequestCounter, r.NotificationsWriteAuthenticator, r.DatabaseAllocator)
 	m.Handle("POST", "/everyone", NewEveryoneHandler(r.Notify, r.ErrorWriter, r.EveryoneStrategy), r.RequestLogging, r.RequestCounter, r.NotificationsWriteAuthenticator, r.DatabaseAllocator)
 	m.Handle("POST", "/uaa_scopes/{scope}", NewUAAScopeHandler(r.Notify, r.ErrorWriter, r.UAAScopeStrategy), r.RequestLogging, r.RequestCounter, r.NotificationsWriteAuthenticator, r.DatabaseAllocator)
+	m.Handle("POST", "/groups/{group_name}", NewUAAGroupHandler(r.Notify, r.ErrorWriter, r.UAAGroupStrategy), r.RequestLogging, r.RequestCounter, r.NotificationsWriteAuthenticator, r.DatabaseAllocator)
 	m.Handle("POST", "/emails", NewEmailHandler(r.Notify, r.ErrorWriter, r.EmailStrategy), r.RequestLogging, r.RequestCounter, r.EmailsWriteAuthenticator, r.DatabaseAllocator)
 }
@@ -27,6 +27,7 @@ var _ = Describe("Routes", func() {
 			OrganizationStrategy: mocks.NewStrategy(),
 			EveryoneStrategy:     mocks.NewStrategy(),
 			UAAScopeStrategy:     mocks.NewStrategy(),
+			UAAGroupStrategy:     mocks.NewStrategy(),
 			EmailStrategy:        mocks.NewStrategy(),
 
 			RequestCounter:                  middleware.RequestCounter{},
@@ -97,6 +98,18 @@ var _ = Describe("Routes", func() {
 		Expect(authenticator.Scopes).To(Equal([]string{"notifications.write"}))
 	})
 
+	It("routes POST /groups/{group_name}", func() {
+		request, err := http.NewRequest("POST", "/groups/{group_name}", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		s := muxer.Match(request).(stack.Stack)
+		Expect(s.Handler).To(BeAssignableToTypeOf(notify.UAAGroupHandler{}))
+		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+
+		authenticator := s.Middleware[2].(middleware.Authenticator)
+		Expect(authenticator.Scopes).To(Equal([]string{"notifications.write"}))
+	})
+
 	It("routes POST /emails", func() {
 		request, err := http.NewRequest("POST", "/emails", nil)
 		Expect(err).NotTo(HaveOccurred())
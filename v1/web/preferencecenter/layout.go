@@ -0,0 +1,79 @@
+package preferencecenter
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+)
+
+var defaultLayout = template.Must(template.New("layout").Parse(`<!DOCTYPE html>
+<html>
+	<head><title>{{.Subject}}</title></head>
+	<body>
+		{{.HTML}}
+	</body>
+</html>
+`))
+
+// Layout wraps each preference center page. Operators can brand the pages by
+// pointing it at a template whose HTML renders the page body with {{.HTML}}
+// and its title with {{.Subject}}, just as notification templates do. The
+// built-in layout is used when no template is configured or it cannot be
+// rendered.
+type Layout struct {
+	finder     templateFinder
+	templateID string
+}
+
+func NewLayout(finder templateFinder, templateID string) Layout {
+	return Layout{
+		finder:     finder,
+		templateID: templateID,
+	}
+}
+
+// Write renders the page inside the layout. Nothing is written when the page
+// cannot be rendered; the error is returned for the handler to report.
+func (l Layout) Write(w http.ResponseWriter, database DatabaseInterface, status int, title string, page *template.Template, data interface{}) error {
+	body := &bytes.Buffer{}
+	err := page.Execute(body, data)
+	if err != nil {
+		return err
+	}
+
+	layoutData := map[string]interface{}{
+		"Subject": title,
+		"HTML":    template.HTML(body.String()),
+	}
+
+	output := &bytes.Buffer{}
+	err = l.load(database).Execute(output, layoutData)
+	if err != nil {
+		output.Reset()
+		defaultLayout.Execute(output, layoutData)
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write(output.Bytes())
+
+	return nil
+}
+
+func (l Layout) load(database DatabaseInterface) *template.Template {
+	if l.templateID == "" {
+		return defaultLayout
+	}
+
+	brand, err := l.finder.FindByID(database, l.templateID)
+	if err != nil {
+		return defaultLayout
+	}
+
+	layout, err := template.New("brand").Parse(brand.HTML)
+	if err != nil {
+		return defaultLayout
+	}
+
+	return layout
+}
@@ -0,0 +1,49 @@
+package preferencecenter_test
+
+import (
+	"html/template"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/preferencecenter"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Layout", func() {
+	var (
+		layout   preferencecenter.Layout
+		database *mocks.Database
+		writer   *httptest.ResponseRecorder
+	)
+
+	BeforeEach(func() {
+		layout = preferencecenter.NewLayout(mocks.NewTemplateFinder(), "")
+		database = mocks.NewDatabase()
+		writer = httptest.NewRecorder()
+	})
+
+	It("renders the page inside the layout", func() {
+		page := template.Must(template.New("page").Parse(`<p>{{.}}</p>`))
+
+		err := layout.Write(writer, database, 201, "Some title", page, "some-data")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(writer.Code).To(Equal(201))
+		Expect(writer.Body.String()).To(ContainSubstring(`<title>Some title</title>`))
+		Expect(writer.Body.String()).To(ContainSubstring(`<p>some-data</p>`))
+	})
+
+	Context("when the page cannot be rendered", func() {
+		It("returns the error without writing a response", func() {
+			page := template.Must(template.New("page").Parse(`<p>{{.Missing}}</p>`))
+
+			err := layout.Write(writer, database, 200, "Some title", page, "some-data")
+			Expect(err).To(MatchError(ContainSubstring("Missing")))
+
+			Expect(writer.Body.Len()).To(BeZero())
+			Expect(writer.Header().Get("Content-Type")).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,172 @@
+package preferencecenter
+
+import (
+	"html/template"
+	"sort"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+)
+
+const (
+	pageTitle = "Notification preferences"
+
+	kindsField         = "kinds"
+	campaignTypesField = "campaign_types"
+)
+
+var preferencesPage = template.Must(template.New("preferences").Parse(`<h1>Notification preferences</h1>
+{{if .Notice}}<p class="notice">{{.Notice}}</p>
+{{end}}<form method="POST" action="/preferences?token={{.Token}}">
+	<fieldset>
+		<label><input type="checkbox" name="global_unsubscribe" value="true"{{if .GlobalUnsubscribe}} checked{{end}}> Stop sending me all non-critical notifications</label>
+	</fieldset>
+{{range .Sources}}	<fieldset>
+		<legend>{{.Name}}</legend>
+{{range .Notifications}}		<input type="hidden" name="{{.Field}}" value="{{.Value}}">
+		<label><input type="checkbox" name="subscribed_{{.Field}}" value="{{.Value}}"{{if .Subscribed}} checked{{end}}> {{.Description}}</label>
+{{end}}	</fieldset>
+{{end}}	<button type="submit">Save preferences</button>
+</form>
+`))
+
+var errorPage = template.Must(template.New("error").Parse(`<p>{{.}}</p>
+`))
+
+type preferencesForm struct {
+	Token             string
+	Notice            string
+	GlobalUnsubscribe bool
+	Sources           []source
+}
+
+type source struct {
+	Name          string
+	Notifications []notification
+}
+
+// notification is a single checkbox. Field says whether it is a v1 kind or a
+// v2 campaign type, and Value identifies it: "client-id/kind-id" for kinds,
+// which cannot contain a "/", or the campaign type ID.
+type notification struct {
+	Field       string
+	Value       string
+	Description string
+	Subscribed  bool
+}
+
+func findPreferences(finder preferencesFinder, campaignTypes campaignTypePreferences, database DatabaseInterface, userGUID string) (preferencesForm, error) {
+	builder, err := finder.Find(database, userGUID)
+	if err != nil {
+		return preferencesForm{}, err
+	}
+
+	userPreferences, err := campaignTypes.List(database.Connection(), userGUID)
+	if err != nil {
+		return preferencesForm{}, err
+	}
+
+	form := preferencesForm{
+		GlobalUnsubscribe: builder.GlobalUnsubscribe,
+		Sources:           []source{},
+	}
+
+	clientIDs := []string{}
+	for clientID := range builder.Clients {
+		clientIDs = append(clientIDs, clientID)
+	}
+	sort.Strings(clientIDs)
+
+	for _, clientID := range clientIDs {
+		kinds := builder.Clients[clientID]
+
+		kindIDs := []string{}
+		for kindID := range kinds {
+			kindIDs = append(kindIDs, kindID)
+		}
+		sort.Strings(kindIDs)
+
+		clientSource := source{
+			Name: kinds[kindIDs[0]].SourceDescription,
+		}
+
+		for _, kindID := range kindIDs {
+			kind := kinds[kindID]
+			clientSource.Notifications = append(clientSource.Notifications, notification{
+				Field:       kindsField,
+				Value:       clientID + "/" + kindID,
+				Description: kind.KindDescription,
+				Subscribed:  kind.Email != nil && *kind.Email,
+			})
+		}
+
+		form.Sources = append(form.Sources, clientSource)
+	}
+
+	for _, sender := range userPreferences.Senders {
+		senderSource := source{
+			Name: sender.Name,
+		}
+
+		for _, campaignType := range sender.CampaignTypes {
+			description := campaignType.Description
+			if description == "" {
+				description = campaignType.Name
+			}
+
+			senderSource.Notifications = append(senderSource.Notifications, notification{
+				Field:       campaignTypesField,
+				Value:       campaignType.ID,
+				Description: description,
+				Subscribed:  campaignType.Subscribed,
+			})
+		}
+
+		if len(senderSource.Notifications) > 0 {
+			form.Sources = append(form.Sources, senderSource)
+		}
+	}
+
+	return form, nil
+}
+
+func parseKinds(values, subscribed []string) []models.Preference {
+	checked := map[string]bool{}
+	for _, value := range subscribed {
+		checked[value] = true
+	}
+
+	preferences := []models.Preference{}
+	for _, value := range values {
+		separator := strings.LastIndex(value, "/")
+		if separator < 0 {
+			continue
+		}
+
+		preferences = append(preferences, models.Preference{
+			ClientID: value[:separator],
+			KindID:   value[separator+1:],
+			Email:    checked[value],
+		})
+	}
+
+	return preferences
+}
+
+func parseCampaignTypes(values, subscribed []string) []collections.CampaignTypePreference {
+	checked := map[string]bool{}
+	for _, value := range subscribed {
+		checked[value] = true
+	}
+
+	preferences := []collections.CampaignTypePreference{}
+	for _, value := range values {
+		preferences = append(preferences, collections.CampaignTypePreference{
+			ID:         value,
+			Subscribed: checked[value],
+		})
+	}
+
+	return preferences
+}
@@ -0,0 +1,70 @@
+package preferencecenter
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	v2models "github.com/cloudfoundry-incubator/notifications/v2/models"
+	"github.com/ryanmoran/stack"
+)
+
+type muxer interface {
+	Handle(method, path string, handler stack.Handler, middleware ...stack.Middleware)
+}
+
+type tokenVerifier interface {
+	Verify(token string) ([]byte, error)
+}
+
+type clock interface {
+	Now() time.Time
+}
+
+type preferencesFinder interface {
+	Find(database services.DatabaseInterface, userGUID string) (services.PreferencesBuilder, error)
+}
+
+type preferenceUpdater interface {
+	Update(conn services.ConnectionInterface, preferences []models.Preference, globalUnsubscribe bool, userID string, actor v2models.Actor) error
+}
+
+type campaignTypePreferences interface {
+	List(conn collections.ConnectionInterface, userGUID string) (collections.UserPreferences, error)
+	UpdateCampaignTypes(conn collections.ConnectionInterface, userGUID string, campaignTypePreferences []collections.CampaignTypePreference, actor v2models.Actor) error
+}
+
+type errorWriter interface {
+	Write(w http.ResponseWriter, err error)
+}
+
+type templateFinder interface {
+	FindByID(database services.DatabaseInterface, templateID string) (models.Template, error)
+}
+
+type Routes struct {
+	RequestCounter    stack.Middleware
+	RequestLogging    stack.Middleware
+	DatabaseAllocator stack.Middleware
+
+	Verifier                tokenVerifier
+	Clock                   clock
+	PreferencesFinder       preferencesFinder
+	PreferenceUpdater       preferenceUpdater
+	CampaignTypePreferences campaignTypePreferences
+	TemplateFinder          templateFinder
+	TemplateID              string
+	ErrorWriter             errorWriter
+}
+
+// Register adds the preference center linked from the PreferencesURL in each
+// email to a UAA user. Like the unsubscribe pages, it is authorized by the
+// signed token alone and so sits on the default (v1) router.
+func (r Routes) Register(m muxer) {
+	layout := NewLayout(r.TemplateFinder, r.TemplateID)
+
+	m.Handle("GET", "/preferences", NewShowHandler(r.Verifier, r.Clock, layout, r.ErrorWriter, r.PreferencesFinder, r.CampaignTypePreferences), r.RequestLogging, r.RequestCounter, r.DatabaseAllocator)
+	m.Handle("POST", "/preferences", NewUpdateHandler(r.Verifier, r.Clock, layout, r.ErrorWriter, r.PreferencesFinder, r.PreferenceUpdater, r.CampaignTypePreferences), r.RequestLogging, r.RequestCounter, r.DatabaseAllocator)
+}
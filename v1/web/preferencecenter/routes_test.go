@@ -0,0 +1,54 @@
+package preferencecenter_test
+
+import (
+	"net/http"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/middleware"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/preferencecenter"
+	"github.com/cloudfoundry-incubator/notifications/web"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Routes", func() {
+	var muxer web.Muxer
+
+	BeforeEach(func() {
+		muxer = web.NewMuxer()
+		preferencecenter.Routes{
+			RequestCounter:    middleware.RequestCounter{},
+			RequestLogging:    middleware.RequestLogging{},
+			DatabaseAllocator: middleware.DatabaseAllocator{},
+
+			Verifier:                mocks.NewSigner(),
+			Clock:                   mocks.NewClock(),
+			PreferencesFinder:       mocks.NewPreferencesFinder(),
+			PreferenceUpdater:       mocks.NewPreferenceUpdater(),
+			CampaignTypePreferences: mocks.NewUserPreferencesCollection(),
+			TemplateFinder:          mocks.NewTemplateFinder(),
+			ErrorWriter:             mocks.NewErrorWriter(),
+		}.Register(muxer)
+	})
+
+	It("routes GET /preferences", func() {
+		request, err := http.NewRequest("GET", "/preferences?token=some-token", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		s := muxer.Match(request).(stack.Stack)
+		Expect(s.Handler).To(BeAssignableToTypeOf(preferencecenter.ShowHandler{}))
+		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.DatabaseAllocator{})
+	})
+
+	It("routes POST /preferences", func() {
+		request, err := http.NewRequest("POST", "/preferences?token=some-token", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		s := muxer.Match(request).(stack.Stack)
+		Expect(s.Handler).To(BeAssignableToTypeOf(preferencecenter.UpdateHandler{}))
+		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.DatabaseAllocator{})
+	})
+})
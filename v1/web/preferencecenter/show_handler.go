@@ -0,0 +1,71 @@
+package preferencecenter
+
+import (
+	"html/template"
+	"net/http"
+
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/ryanmoran/stack"
+)
+
+type ShowHandler struct {
+	verifier      tokenVerifier
+	clock         clock
+	pages         pageWriter
+	finder        preferencesFinder
+	campaignTypes campaignTypePreferences
+}
+
+func NewShowHandler(verifier tokenVerifier, clock clock, layout Layout, errorWriter errorWriter, finder preferencesFinder, campaignTypes campaignTypePreferences) ShowHandler {
+	return ShowHandler{
+		verifier:      verifier,
+		clock:         clock,
+		pages:         pageWriter{layout: layout, errorWriter: errorWriter},
+		finder:        finder,
+		campaignTypes: campaignTypes,
+	}
+}
+
+func (h ShowHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	database := context.Get("database").(DatabaseInterface)
+	token := req.URL.Query().Get("token")
+
+	preferences, err := common.ParsePreferencesToken(h.verifier, token, h.clock.Now())
+	if err != nil {
+		h.pages.write(w, database, http.StatusBadRequest, errorPage, "This preferences link is invalid.")
+		return
+	}
+
+	form, err := findPreferences(h.finder, h.campaignTypes, database, preferences.UserGUID)
+	if err != nil {
+		h.pages.writeError(w, database, err)
+		return
+	}
+
+	form.Token = token
+	h.pages.write(w, database, http.StatusOK, preferencesPage, form)
+}
+
+// pageWriter renders the preference center pages, falling back to the error
+// writer when a page cannot be rendered.
+type pageWriter struct {
+	layout      Layout
+	errorWriter errorWriter
+}
+
+func (p pageWriter) write(w http.ResponseWriter, database DatabaseInterface, status int, page *template.Template, data interface{}) {
+	err := p.layout.Write(w, database, status, pageTitle, page, data)
+	if err != nil {
+		p.errorWriter.Write(w, err)
+	}
+}
+
+func (p pageWriter) writeError(w http.ResponseWriter, database DatabaseInterface, err error) {
+	switch err.(type) {
+	case collections.NotFoundError:
+		p.write(w, database, http.StatusNotFound, errorPage, "This preferences link is no longer valid.")
+	default:
+		p.write(w, database, http.StatusInternalServerError, errorPage, "Something went wrong. Please try again later.")
+	}
+}
@@ -0,0 +1,229 @@
+package preferencecenter_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/preferencecenter"
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ShowHandler", func() {
+	var (
+		handler        preferencecenter.ShowHandler
+		verifier       *mocks.Signer
+		clock          *mocks.Clock
+		finder         *mocks.PreferencesFinder
+		campaignTypes  *mocks.UserPreferencesCollection
+		templateFinder *mocks.TemplateFinder
+		database       *mocks.Database
+		conn           *mocks.Connection
+		writer         *httptest.ResponseRecorder
+		request        *http.Request
+		context        stack.Context
+	)
+
+	BeforeEach(func() {
+		clock = mocks.NewClock()
+		clock.NowCall.Returns.Time = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		verifier = mocks.NewSigner()
+		verifier.VerifyCall.Returns.Payload = []byte(fmt.Sprintf(`{"user_guid": "some-user-guid", "expires_at": %d}`, clock.NowCall.Returns.Time.Add(time.Hour).Unix()))
+
+		builder := services.NewPreferencesBuilder()
+		builder.GlobalUnsubscribe = false
+		builder.Add(models.Preference{
+			ClientID:          "some-client-id",
+			KindID:            "some-kind-id",
+			KindDescription:   "Some kind",
+			SourceDescription: "Some client",
+			Email:             true,
+		})
+		builder.Add(models.Preference{
+			ClientID:          "some-client-id",
+			KindID:            "another-kind-id",
+			KindDescription:   "Another kind",
+			SourceDescription: "Some client",
+			Email:             false,
+		})
+
+		finder = mocks.NewPreferencesFinder()
+		finder.FindCall.Returns.PreferencesBuilder = builder
+
+		campaignTypes = mocks.NewUserPreferencesCollection()
+		campaignTypes.ListCall.Returns.Preferences = collections.UserPreferences{
+			UserGUID: "some-user-guid",
+			Senders: []collections.SenderPreferences{
+				{
+					ID:   "some-sender-id",
+					Name: "Some sender",
+					CampaignTypes: []collections.CampaignTypePreference{
+						{
+							ID:          "some-campaign-type-id",
+							Name:        "some-campaign-type",
+							Description: "Some campaign type",
+							Subscribed:  true,
+						},
+					},
+				},
+			},
+		}
+
+		templateFinder = mocks.NewTemplateFinder()
+
+		conn = mocks.NewConnection()
+		database = mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = conn
+
+		context = stack.NewContext()
+		context.Set("database", database)
+
+		writer = httptest.NewRecorder()
+
+		var err error
+		request, err = http.NewRequest("GET", "/preferences?token=some-token", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		handler = preferencecenter.NewShowHandler(verifier, clock, preferencecenter.NewLayout(templateFinder, ""), mocks.NewErrorWriter(), finder, campaignTypes)
+	})
+
+	It("renders the kinds and campaign types the user can unsubscribe from", func() {
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(verifier.VerifyCall.Receives.Token).To(Equal("some-token"))
+		Expect(finder.FindCall.Receives.Database).To(Equal(database))
+		Expect(finder.FindCall.Receives.UserGUID).To(Equal("some-user-guid"))
+		Expect(campaignTypes.ListCall.Receives.Connection).To(Equal(conn))
+		Expect(campaignTypes.ListCall.Receives.UserGUID).To(Equal("some-user-guid"))
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Header().Get("Content-Type")).To(Equal("text/html; charset=utf-8"))
+
+		body := writer.Body.String()
+		Expect(body).To(ContainSubstring(`<title>Notification preferences</title>`))
+		Expect(body).To(ContainSubstring(`action="/preferences?token=some-token"`))
+		Expect(body).To(ContainSubstring(`<input type="checkbox" name="global_unsubscribe" value="true"> Stop sending me all non-critical notifications`))
+		Expect(body).To(ContainSubstring(`<legend>Some client</legend>`))
+		Expect(body).To(ContainSubstring(`<input type="hidden" name="kinds" value="some-client-id/some-kind-id">`))
+		Expect(body).To(ContainSubstring(`<input type="checkbox" name="subscribed_kinds" value="some-client-id/some-kind-id" checked> Some kind`))
+		Expect(body).To(ContainSubstring(`<input type="checkbox" name="subscribed_kinds" value="some-client-id/another-kind-id"> Another kind`))
+		Expect(body).To(ContainSubstring(`<legend>Some sender</legend>`))
+		Expect(body).To(ContainSubstring(`<input type="hidden" name="campaign_types" value="some-campaign-type-id">`))
+		Expect(body).To(ContainSubstring(`<input type="checkbox" name="subscribed_campaign_types" value="some-campaign-type-id" checked> Some campaign type`))
+	})
+
+	It("checks the global unsubscribe box when the user has globally unsubscribed", func() {
+		finder.FindCall.Returns.PreferencesBuilder.GlobalUnsubscribe = true
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Body.String()).To(ContainSubstring(`<input type="checkbox" name="global_unsubscribe" value="true" checked>`))
+	})
+
+	Context("when the operator has configured a branding template", func() {
+		BeforeEach(func() {
+			templateFinder.FindByIDCall.Returns.Template = models.Template{
+				ID:   "some-template-id",
+				HTML: `<html><head><title>Acme | {{.Subject}}</title></head><body><div class="acme">{{.HTML}}</div></body></html>`,
+			}
+
+			handler = preferencecenter.NewShowHandler(verifier, clock, preferencecenter.NewLayout(templateFinder, "some-template-id"), mocks.NewErrorWriter(), finder, campaignTypes)
+		})
+
+		It("wraps the page in the template", func() {
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(templateFinder.FindByIDCall.Receives.Database).To(Equal(database))
+			Expect(templateFinder.FindByIDCall.Receives.TemplateID).To(Equal("some-template-id"))
+
+			body := writer.Body.String()
+			Expect(body).To(HavePrefix(`<html><head><title>Acme | Notification preferences</title></head><body><div class="acme"><h1>Notification preferences</h1>`))
+			Expect(body).To(ContainSubstring(`<legend>Some sender</legend>`))
+		})
+
+		It("falls back to the built-in layout when the template cannot be found", func() {
+			templateFinder.FindByIDCall.Returns.Error = errors.New("not found")
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusOK))
+			Expect(writer.Body.String()).To(ContainSubstring(`<title>Notification preferences</title>`))
+			Expect(writer.Body.String()).NotTo(ContainSubstring("Acme"))
+		})
+
+		It("falls back to the built-in layout when the template is invalid", func() {
+			templateFinder.FindByIDCall.Returns.Template.HTML = `{{.HTML`
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusOK))
+			Expect(writer.Body.String()).To(ContainSubstring(`<title>Notification preferences</title>`))
+		})
+	})
+
+	Context("failure cases", func() {
+		It("returns a 400 when the token is invalid", func() {
+			verifier.VerifyCall.Returns.Error = errors.New("invalid signature")
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusBadRequest))
+			Expect(writer.Body.String()).To(ContainSubstring("This preferences link is invalid."))
+			Expect(finder.FindCall.Receives.UserGUID).To(BeEmpty())
+		})
+
+		It("returns a 400 when the token has expired", func() {
+			clock.NowCall.Returns.Time = clock.NowCall.Returns.Time.Add(2 * time.Hour)
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusBadRequest))
+			Expect(writer.Body.String()).To(ContainSubstring("This preferences link is invalid."))
+			Expect(finder.FindCall.Receives.UserGUID).To(BeEmpty())
+		})
+
+		It("returns a 400 when the token has no expiry", func() {
+			verifier.VerifyCall.Returns.Payload = []byte(`{"user_guid": "some-user-guid"}`)
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusBadRequest))
+		})
+
+		It("returns a 400 when the token has no user guid", func() {
+			verifier.VerifyCall.Returns.Payload = []byte(`{"email": "someone@example.com"}`)
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusBadRequest))
+		})
+
+		It("returns a 404 when the user no longer exists", func() {
+			campaignTypes.ListCall.Returns.Error = collections.NotFoundError{errors.New("User \"some-user-guid\" not found")}
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusNotFound))
+			Expect(writer.Body.String()).To(ContainSubstring("This preferences link is no longer valid."))
+		})
+
+		It("returns a 500 when the preferences cannot be found", func() {
+			finder.FindCall.Returns.Error = errors.New("some database error")
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusInternalServerError))
+			Expect(writer.Body.String()).To(ContainSubstring("Something went wrong. Please try again later."))
+		})
+	})
+})
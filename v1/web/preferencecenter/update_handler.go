@@ -0,0 +1,110 @@
+package preferencecenter
+
+import (
+	"net/http"
+
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	v2models "github.com/cloudfoundry-incubator/notifications/v2/models"
+	"github.com/ryanmoran/stack"
+)
+
+type UpdateHandler struct {
+	verifier      tokenVerifier
+	clock         clock
+	pages         pageWriter
+	finder        preferencesFinder
+	updater       preferenceUpdater
+	campaignTypes campaignTypePreferences
+}
+
+func NewUpdateHandler(verifier tokenVerifier, clock clock, layout Layout, errorWriter errorWriter, finder preferencesFinder, updater preferenceUpdater, campaignTypes campaignTypePreferences) UpdateHandler {
+	return UpdateHandler{
+		verifier:      verifier,
+		clock:         clock,
+		pages:         pageWriter{layout: layout, errorWriter: errorWriter},
+		finder:        finder,
+		updater:       updater,
+		campaignTypes: campaignTypes,
+	}
+}
+
+// ServeHTTP saves the submitted form. Every kind and campaign type shown on the
+// page is posted back in a hidden field, so that unchecked boxes can be told
+// apart from notifications the page did not list.
+func (h UpdateHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	database := context.Get("database").(DatabaseInterface)
+	token := req.URL.Query().Get("token")
+
+	preferences, err := common.ParsePreferencesToken(h.verifier, token, h.clock.Now())
+	if err != nil {
+		h.pages.write(w, database, http.StatusBadRequest, errorPage, "This preferences link is invalid.")
+		return
+	}
+
+	err = req.ParseForm()
+	if err != nil {
+		h.pages.write(w, database, http.StatusBadRequest, errorPage, "The form could not be read.")
+		return
+	}
+
+	kinds := parseKinds(req.PostForm[kindsField], req.PostForm["subscribed_"+kindsField])
+	campaignTypes := parseCampaignTypes(req.PostForm[campaignTypesField], req.PostForm["subscribed_"+campaignTypesField])
+	globalUnsubscribe := req.PostForm.Get("global_unsubscribe") == "true"
+
+	connection := database.Connection()
+	transaction := connection.Transaction()
+	err = transaction.Begin()
+	if err != nil {
+		h.pages.writeError(w, database, err)
+		return
+	}
+
+	actor := v2models.Actor{
+		Type:   v2models.ActorRecipient,
+		ID:     preferences.UserGUID,
+		Source: v2models.SourceLink,
+	}
+
+	err = h.updater.Update(transaction, kinds, globalUnsubscribe, preferences.UserGUID, actor)
+	if err != nil {
+		transaction.Rollback()
+		h.pages.writeUpdateError(w, database, err)
+		return
+	}
+
+	if len(campaignTypes) > 0 {
+		err = h.campaignTypes.UpdateCampaignTypes(transaction, preferences.UserGUID, campaignTypes, actor)
+		if err != nil {
+			transaction.Rollback()
+			h.pages.writeUpdateError(w, database, err)
+			return
+		}
+	}
+
+	err = transaction.Commit()
+	if err != nil {
+		h.pages.writeError(w, database, err)
+		return
+	}
+
+	form, err := findPreferences(h.finder, h.campaignTypes, database, preferences.UserGUID)
+	if err != nil {
+		h.pages.writeError(w, database, err)
+		return
+	}
+
+	form.Token = token
+	form.Notice = "Your preferences have been saved."
+	h.pages.write(w, database, http.StatusOK, preferencesPage, form)
+}
+
+func (p pageWriter) writeUpdateError(w http.ResponseWriter, database DatabaseInterface, err error) {
+	switch err.(type) {
+	case services.MissingKindOrClientError, services.CriticalKindError, collections.NotFoundError, collections.PermissionsError:
+		p.write(w, database, 422, errorPage, err.Error())
+	default:
+		p.writeError(w, database, err)
+	}
+}
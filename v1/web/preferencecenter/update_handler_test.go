@@ -0,0 +1,196 @@
+package preferencecenter_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/preferencecenter"
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	v2models "github.com/cloudfoundry-incubator/notifications/v2/models"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("UpdateHandler", func() {
+	var (
+		handler       preferencecenter.UpdateHandler
+		verifier      *mocks.Signer
+		clock         *mocks.Clock
+		finder        *mocks.PreferencesFinder
+		updater       *mocks.PreferenceUpdater
+		campaignTypes *mocks.UserPreferencesCollection
+		conn          *mocks.Connection
+		transaction   *mocks.Transaction
+		writer        *httptest.ResponseRecorder
+		form          url.Values
+		context       stack.Context
+	)
+
+	BeforeEach(func() {
+		clock = mocks.NewClock()
+		clock.NowCall.Returns.Time = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		verifier = mocks.NewSigner()
+		verifier.VerifyCall.Returns.Payload = []byte(fmt.Sprintf(`{"user_guid": "some-user-guid", "expires_at": %d}`, clock.NowCall.Returns.Time.Add(time.Hour).Unix()))
+
+		finder = mocks.NewPreferencesFinder()
+		finder.FindCall.Returns.PreferencesBuilder = services.NewPreferencesBuilder()
+		updater = mocks.NewPreferenceUpdater()
+		campaignTypes = mocks.NewUserPreferencesCollection()
+
+		transaction = mocks.NewTransaction()
+		conn = mocks.NewConnection()
+		conn.TransactionCall.Returns.Transaction = transaction
+		database := mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = conn
+
+		context = stack.NewContext()
+		context.Set("database", database)
+
+		writer = httptest.NewRecorder()
+
+		form = url.Values{
+			"global_unsubscribe":        {"true"},
+			"kinds":                     {"some-client-id/some-kind-id", "some-client-id/another-kind-id"},
+			"subscribed_kinds":          {"some-client-id/some-kind-id"},
+			"campaign_types":            {"some-campaign-type-id", "another-campaign-type-id"},
+			"subscribed_campaign_types": {"another-campaign-type-id"},
+		}
+
+		handler = preferencecenter.NewUpdateHandler(verifier, clock, preferencecenter.NewLayout(mocks.NewTemplateFinder(), ""), mocks.NewErrorWriter(), finder, updater, campaignTypes)
+	})
+
+	serve := func() {
+		request, err := http.NewRequest("POST", "/preferences?token=some-token", strings.NewReader(form.Encode()))
+		Expect(err).NotTo(HaveOccurred())
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		handler.ServeHTTP(writer, request, context)
+	}
+
+	It("saves the kinds, campaign types and global unsubscribe", func() {
+		serve()
+
+		Expect(verifier.VerifyCall.Receives.Token).To(Equal("some-token"))
+
+		Expect(transaction.BeginCall.WasCalled).To(BeTrue())
+		Expect(updater.UpdateCall.Receives.Connection).To(Equal(transaction))
+		Expect(updater.UpdateCall.Receives.UserID).To(Equal("some-user-guid"))
+		Expect(updater.UpdateCall.Receives.GlobalUnsubscribe).To(BeTrue())
+		Expect(updater.UpdateCall.Receives.Actor).To(Equal(v2models.Actor{
+			Type:   v2models.ActorRecipient,
+			ID:     "some-user-guid",
+			Source: v2models.SourceLink,
+		}))
+		Expect(updater.UpdateCall.Receives.Preferences).To(Equal([]models.Preference{
+			{
+				ClientID: "some-client-id",
+				KindID:   "some-kind-id",
+				Email:    true,
+			},
+			{
+				ClientID: "some-client-id",
+				KindID:   "another-kind-id",
+				Email:    false,
+			},
+		}))
+
+		Expect(campaignTypes.UpdateCampaignTypesCall.Receives.Connection).To(Equal(transaction))
+		Expect(campaignTypes.UpdateCampaignTypesCall.Receives.UserGUID).To(Equal("some-user-guid"))
+		Expect(campaignTypes.UpdateCampaignTypesCall.Receives.Actor).To(Equal(updater.UpdateCall.Receives.Actor))
+		Expect(campaignTypes.UpdateCampaignTypesCall.Receives.CampaignTypePreferences).To(Equal([]collections.CampaignTypePreference{
+			{
+				ID:         "some-campaign-type-id",
+				Subscribed: false,
+			},
+			{
+				ID:         "another-campaign-type-id",
+				Subscribed: true,
+			},
+		}))
+
+		Expect(transaction.CommitCall.WasCalled).To(BeTrue())
+		Expect(transaction.RollbackCall.WasCalled).To(BeFalse())
+
+		Expect(finder.FindCall.Receives.UserGUID).To(Equal("some-user-guid"))
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.String()).To(ContainSubstring("Your preferences have been saved."))
+	})
+
+	It("clears the global unsubscribe when the box is unchecked", func() {
+		form.Del("global_unsubscribe")
+
+		serve()
+
+		Expect(updater.UpdateCall.Receives.GlobalUnsubscribe).To(BeFalse())
+	})
+
+	It("does not update campaign types when none were listed", func() {
+		form.Del("campaign_types")
+		form.Del("subscribed_campaign_types")
+
+		serve()
+
+		Expect(campaignTypes.UpdateCampaignTypesCall.Receives.UserGUID).To(BeEmpty())
+		Expect(writer.Code).To(Equal(http.StatusOK))
+	})
+
+	Context("failure cases", func() {
+		It("returns a 400 when the token is invalid", func() {
+			verifier.VerifyCall.Returns.Error = errors.New("invalid signature")
+
+			serve()
+
+			Expect(writer.Code).To(Equal(http.StatusBadRequest))
+			Expect(transaction.BeginCall.WasCalled).To(BeFalse())
+		})
+
+		It("returns a 422 when a kind is critical", func() {
+			updater.UpdateCall.Returns.Error = services.CriticalKindError{errors.New("The kind 'some-kind-id' for the 'some-client-id' client is critical and cannot be unsubscribed from")}
+
+			serve()
+
+			Expect(writer.Code).To(Equal(422))
+			Expect(writer.Body.String()).To(ContainSubstring("is critical and cannot be unsubscribed from"))
+			Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+			Expect(transaction.CommitCall.WasCalled).To(BeFalse())
+		})
+
+		It("returns a 422 when a campaign type cannot be unsubscribed from", func() {
+			campaignTypes.UpdateCampaignTypesCall.Returns.Error = collections.PermissionsError{errors.New(`Campaign type "some-campaign-type-id" cannot be unsubscribed from`)}
+
+			serve()
+
+			Expect(writer.Code).To(Equal(422))
+			Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+			Expect(transaction.CommitCall.WasCalled).To(BeFalse())
+		})
+
+		It("returns a 500 when the preferences cannot be saved", func() {
+			updater.UpdateCall.Returns.Error = errors.New("some database error")
+
+			serve()
+
+			Expect(writer.Code).To(Equal(http.StatusInternalServerError))
+			Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+		})
+
+		It("returns a 500 when the transaction cannot be committed", func() {
+			transaction.CommitCall.Returns.Error = errors.New("some commit error")
+
+			serve()
+
+			Expect(writer.Code).To(Equal(http.StatusInternalServerError))
+		})
+	})
+})
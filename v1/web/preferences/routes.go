@@ -3,6 +3,7 @@ package preferences
 import (
 	"github.com/cloudfoundry-incubator/notifications/v1/models"
 	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	v2models "github.com/cloudfoundry-incubator/notifications/v2/models"
 	"github.com/ryanmoran/stack"
 )
 
@@ -11,7 +12,8 @@ type muxer interface {
 }
 
 type preferenceUpdater interface {
-	Update(connection services.ConnectionInterface, preferences []models.Preference, globallyUnsubscribe bool, userID string) error
+	Update(connection services.ConnectionInterface, preferences []models.Preference, globallyUnsubscribe bool, userID string, actor v2models.Actor) error
+	UpdateQuietHours(connection services.ConnectionInterface, timeZone *string, quietHours *services.QuietHours, userID string, actor v2models.Actor) error
 }
 
 type Routes struct {
@@ -7,6 +7,7 @@ import (
 	"github.com/cloudfoundry-incubator/notifications/v1/models"
 	"github.com/cloudfoundry-incubator/notifications/v1/services"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	v2models "github.com/cloudfoundry-incubator/notifications/v2/models"
 	"github.com/cloudfoundry-incubator/notifications/valiant"
 	"github.com/dgrijalva/jwt-go"
 	"github.com/ryanmoran/stack"
@@ -51,9 +52,15 @@ func (h UpdatePreferencesHandler) ServeHTTP(w http.ResponseWriter, req *http.Req
 		return
 	}
 
+	actor := v2models.Actor{
+		Type:   v2models.ActorUser,
+		ID:     userID,
+		Source: v2models.SourceAPI,
+	}
+
 	transaction := connection.Transaction()
 	transaction.Begin()
-	err = h.preferences.Update(transaction, preferences, builder.GlobalUnsubscribe, userID)
+	err = h.preferences.Update(transaction, preferences, builder.GlobalUnsubscribe, userID, actor)
 	if err != nil {
 		transaction.Rollback()
 
@@ -66,6 +73,15 @@ func (h UpdatePreferencesHandler) ServeHTTP(w http.ResponseWriter, req *http.Req
 		return
 	}
 
+	if builder.TimeZone != nil || builder.QuietHours != nil {
+		err = h.preferences.UpdateQuietHours(transaction, builder.TimeZone, builder.QuietHours, userID, actor)
+		if err != nil {
+			transaction.Rollback()
+			h.errorWriter.Write(w, err)
+			return
+		}
+	}
+
 	err = transaction.Commit()
 	if err != nil {
 		h.errorWriter.Write(w, models.TransactionCommitError{err})
@@ -15,6 +15,7 @@ import (
 	"github.com/cloudfoundry-incubator/notifications/v1/services"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/preferences"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	v2models "github.com/cloudfoundry-incubator/notifications/v2/models"
 	"github.com/dgrijalva/jwt-go"
 	"github.com/ryanmoran/stack"
 
@@ -120,6 +121,37 @@ var _ = Describe("UpdatePreferencesHandler", func() {
 
 			Expect(updater.UpdateCall.Receives.GlobalUnsubscribe).To(BeTrue())
 			Expect(updater.UpdateCall.Receives.UserID).To(Equal("correct-user"))
+			Expect(updater.UpdateCall.Receives.Actor).To(Equal(v2models.Actor{
+				Type:   v2models.ActorUser,
+				ID:     "correct-user",
+				Source: v2models.SourceAPI,
+			}))
+			Expect(updater.UpdateQuietHoursCall.CallCount).To(Equal(0))
+		})
+
+		It("updates the time zone and quiet hours when they are given", func() {
+			requestBody := []byte(`{
+				"global_unsubscribe": false,
+				"time_zone": "America/Chicago",
+				"quiet_hours": {"start": "22:00", "end": "07:00"},
+				"clients": {}
+			}`)
+
+			request, err := http.NewRequest("PATCH", "/user_preferences", bytes.NewBuffer(requestBody))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusNoContent))
+			Expect(reflect.ValueOf(updater.UpdateQuietHoursCall.Receives.Connection).Pointer()).To(Equal(reflect.ValueOf(transaction).Pointer()))
+			Expect(*updater.UpdateQuietHoursCall.Receives.TimeZone).To(Equal("America/Chicago"))
+			Expect(*updater.UpdateQuietHoursCall.Receives.QuietHours).To(Equal(services.QuietHours{
+				Start: "22:00",
+				End:   "07:00",
+			}))
+			Expect(updater.UpdateQuietHoursCall.Receives.UserID).To(Equal("correct-user"))
+			Expect(updater.UpdateQuietHoursCall.Receives.Actor).To(Equal(updater.UpdateCall.Receives.Actor))
+			Expect(transaction.CommitCall.WasCalled).To(BeTrue())
 		})
 
 		It("Returns a 204 status code when the Preference object does not error", func() {
@@ -222,6 +254,20 @@ var _ = Describe("UpdatePreferencesHandler", func() {
 				})
 			})
 
+			It("delegates quiet hours errors to the ErrorWriter", func() {
+				updater.UpdateQuietHoursCall.Returns.Error = errors.New("BOOM!")
+
+				request, err := http.NewRequest("PATCH", "/user_preferences", bytes.NewBuffer([]byte(`{"time_zone": "Europe/Paris", "clients": {}}`)))
+				Expect(err).NotTo(HaveOccurred())
+
+				handler.ServeHTTP(writer, request, context)
+
+				Expect(errorWriter.WriteCall.Receives.Error).To(Equal(errors.New("BOOM!")))
+
+				Expect(transaction.CommitCall.WasCalled).To(BeFalse())
+				Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+			})
+
 			It("delegates json validation errors to the ErrorWriter", func() {
 				requestBody, err := json.Marshal(map[string]interface{}{
 					"something": true,
@@ -8,7 +8,9 @@ import (
 	"github.com/cloudfoundry-incubator/notifications/v1/models"
 	"github.com/cloudfoundry-incubator/notifications/v1/services"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	v2models "github.com/cloudfoundry-incubator/notifications/v2/models"
 	"github.com/cloudfoundry-incubator/notifications/valiant"
+	"github.com/dgrijalva/jwt-go"
 	"github.com/ryanmoran/stack"
 )
 
@@ -44,9 +46,15 @@ func (h UpdateUserPreferencesHandler) ServeHTTP(w http.ResponseWriter, req *http
 		return
 	}
 
+	actor := v2models.Actor{
+		Type:   v2models.ActorClient,
+		ID:     clientIDFor(context),
+		Source: v2models.SourceAPI,
+	}
+
 	transaction := connection.Transaction()
 	transaction.Begin()
-	err = h.preferences.Update(transaction, preferences, builder.GlobalUnsubscribe, userGUID)
+	err = h.preferences.Update(transaction, preferences, builder.GlobalUnsubscribe, userGUID, actor)
 	if err != nil {
 		transaction.Rollback()
 
@@ -59,6 +67,15 @@ func (h UpdateUserPreferencesHandler) ServeHTTP(w http.ResponseWriter, req *http
 		return
 	}
 
+	if builder.TimeZone != nil || builder.QuietHours != nil {
+		err = h.preferences.UpdateQuietHours(transaction, builder.TimeZone, builder.QuietHours, userGUID, actor)
+		if err != nil {
+			transaction.Rollback()
+			h.errorWriter.Write(w, err)
+			return
+		}
+	}
+
 	err = transaction.Commit()
 	if err != nil {
 		h.errorWriter.Write(w, models.TransactionCommitError{err})
@@ -77,3 +94,13 @@ func writeJSON(w http.ResponseWriter, status int, object interface{}) {
 	w.WriteHeader(status)
 	w.Write(output)
 }
+
+func clientIDFor(context stack.Context) string {
+	token, ok := context.Get("token").(*jwt.Token)
+	if !ok {
+		return ""
+	}
+
+	clientID, _ := token.Claims["client_id"].(string)
+	return clientID
+}
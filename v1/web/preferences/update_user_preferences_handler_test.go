@@ -15,6 +15,7 @@ import (
 	"github.com/cloudfoundry-incubator/notifications/v1/services"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/preferences"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	v2models "github.com/cloudfoundry-incubator/notifications/v2/models"
 	"github.com/dgrijalva/jwt-go"
 	"github.com/ryanmoran/stack"
 
@@ -120,6 +121,11 @@ var _ = Describe("UpdateUserPreferencesHandler", func() {
 
 			Expect(updater.UpdateCall.Receives.GlobalUnsubscribe).To(BeTrue())
 			Expect(updater.UpdateCall.Receives.UserID).To(Equal(userGUID))
+			Expect(updater.UpdateCall.Receives.Actor).To(Equal(v2models.Actor{
+				Type:   v2models.ActorClient,
+				ID:     "mister-client",
+				Source: v2models.SourceAPI,
+			}))
 		})
 
 		It("Returns a 204 status code when the Preference object does not error", func() {
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/cloudfoundry-incubator/notifications/cache"
 	"github.com/cloudfoundry-incubator/notifications/cf"
 	"github.com/cloudfoundry-incubator/notifications/gobble"
 	"github.com/cloudfoundry-incubator/notifications/metrics"
@@ -20,10 +21,15 @@ import (
 	"github.com/cloudfoundry-incubator/notifications/v1/web/middleware"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/notifications"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/notify"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/preferencecenter"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/preferences"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/templates"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/unsubscribe"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	v2collections "github.com/cloudfoundry-incubator/notifications/v2/collections"
+	v2models "github.com/cloudfoundry-incubator/notifications/v2/models"
 	"github.com/gorilla/mux"
+	"github.com/pivotal-cf-experimental/warrant"
 	"github.com/pivotal-golang/lager"
 	"github.com/ryanmoran/stack"
 )
@@ -41,11 +47,17 @@ type Config struct {
 	DefaultUAAScopes     []string
 	VerifySSL            bool
 	CCHost               string
+	CCAPIVersion         int
 	DBLoggingEnabled     bool
 	Logger               lager.Logger
 	CORSOrigin           string
 	SQLDB                *sql.DB
 	QueueWaitMaxDuration int
+	EncryptionKey        []byte
+
+	UAAHost               string
+	PreferencesTemplateID string
+	LookupCache           cache.Cache
 }
 
 func NewRouter(mx muxer, config Config) http.Handler {
@@ -59,11 +71,19 @@ func NewRouter(mx muxer, config Config) http.Handler {
 	unsubscribesRepo := models.NewUnsubscribesRepo()
 	messagesRepo := models.NewMessagesRepo(guidGenerator.Generate)
 	templatesRepo := models.NewTemplatesRepo()
+	emailUnsubscribesRepo := v2models.NewEmailUnsubscribesRepository(clock)
+	digestPreferencesRepo := v2models.NewDigestPreferencesRepository()
+	quietHoursRepo := v2models.NewQuietHoursRepository()
+	preferenceChangesRepo := v2models.NewPreferenceChangesRepository(clock)
+	campaignTypesRepository := v2models.NewCampaignTypesRepository(guidGenerator.Generate)
+	sendersRepository := v2models.NewSendersRepository(guidGenerator.Generate)
+	unsubscribersRepository := v2models.NewUnsubscribersRepository(guidGenerator.Generate)
+	v2GlobalUnsubscribesRepository := v2models.NewGlobalUnsubscribesRepository(clock)
 
 	registrar := services.NewRegistrar(clientsRepo, kindsRepo)
 	notificationsFinder := services.NewNotificationsFinder(clientsRepo, kindsRepo)
-	preferencesFinder := services.NewPreferencesFinder(preferencesRepo, globalUnsubscribesRepo)
-	preferenceUpdater := services.NewPreferenceUpdater(globalUnsubscribesRepo, unsubscribesRepo, kindsRepo)
+	preferencesFinder := services.NewPreferencesFinder(preferencesRepo, globalUnsubscribesRepo, quietHoursRepo)
+	preferenceUpdater := services.NewPreferenceUpdater(globalUnsubscribesRepo, unsubscribesRepo, kindsRepo, digestPreferencesRepo, quietHoursRepo, preferenceChangesRepo)
 	notificationsUpdater := services.NewNotificationsUpdater(kindsRepo)
 	messageFinder := services.NewMessageFinder(messagesRepo)
 
@@ -81,20 +101,29 @@ func NewRouter(mx muxer, config Config) http.Handler {
 
 	v1enqueuer := services.NewEnqueuer(gobbleQueue, messagesRepo, gobble.Initializer{})
 
-	uaaClient := uaa.NewZonedUAAClient(config.UAAClientID, config.UAAClientSecret, config.VerifySSL, config.UAAPublicKey)
-	cloudController := cf.NewCloudController(config.CCHost, !config.VerifySSL)
+	uaaClient := uaa.NewCachedZonedUAAClient(uaa.NewZonedUAAClient(config.UAAClientID, config.UAAClientSecret, config.VerifySSL, config.UAAPublicKey), config.LookupCache)
+	cloudController := cf.NewCachedCloudController(cf.NewCloudControllerForVersion(config.CCHost, !config.VerifySSL, config.CCAPIVersion), config.LookupCache)
 	tokenLoader := uaa.NewTokenLoader(uaaClient)
 	spaceLoader := services.NewSpaceLoader(cloudController)
 	organizationLoader := services.NewOrganizationLoader(cloudController)
 	findsUserIDs := services.NewFindsUserIDs(cloudController, uaaClient)
 	allUsers := services.NewAllUsers(uaaClient)
 
+	warrantConfig := warrant.Config{
+		Host:          config.UAAHost,
+		SkipVerifySSL: !config.VerifySSL,
+	}
+	userFinder := uaa.NewUserFinder(config.UAAClientID, config.UAAClientSecret, warrant.NewUsersService(warrantConfig), warrant.NewClientsService(warrantConfig))
+	campaignTypePreferences := v2collections.NewUserPreferencesCollection(campaignTypesRepository, sendersRepository, unsubscribersRepository,
+		v2GlobalUnsubscribesRepository, digestPreferencesRepo, quietHoursRepo, preferenceChangesRepo, userFinder)
+
 	emailStrategy := services.NewEmailStrategy(v1enqueuer)
 	userStrategy := services.NewUserStrategy(v1enqueuer)
 	spaceStrategy := services.NewSpaceStrategy(tokenLoader, spaceLoader, organizationLoader, findsUserIDs, v1enqueuer)
 	organizationStrategy := services.NewOrganizationStrategy(tokenLoader, organizationLoader, findsUserIDs, v1enqueuer)
 	everyoneStrategy := services.NewEveryoneStrategy(tokenLoader, allUsers, v1enqueuer)
 	uaaScopeStrategy := services.NewUAAScopeStrategy(tokenLoader, findsUserIDs, v1enqueuer, config.DefaultUAAScopes)
+	uaaGroupStrategy := services.NewUAAGroupStrategy(tokenLoader, findsUserIDs, v1enqueuer)
 
 	errorWriter := webutil.NewErrorWriter()
 
@@ -176,6 +205,31 @@ func NewRouter(mx muxer, config Config) http.Handler {
 		TemplateAssigner:     templatesCollection,
 	}.Register(mx)
 
+	unsubscribe.Routes{
+		RequestCounter:    requestCounter,
+		RequestLogging:    requestLogging,
+		DatabaseAllocator: databaseAllocator,
+
+		Verifier:          util.NewSigner(config.EncryptionKey),
+		EmailUnsubscribes: emailUnsubscribesRepo,
+		PreferenceChanges: preferenceChangesRepo,
+	}.Register(mx)
+
+	preferencecenter.Routes{
+		RequestCounter:    requestCounter,
+		RequestLogging:    requestLogging,
+		DatabaseAllocator: databaseAllocator,
+
+		Verifier:                util.NewSigner(config.EncryptionKey),
+		Clock:                   clock,
+		PreferencesFinder:       preferencesFinder,
+		PreferenceUpdater:       preferenceUpdater,
+		CampaignTypePreferences: campaignTypePreferences,
+		TemplateFinder:          templateFinder,
+		TemplateID:              config.PreferencesTemplateID,
+		ErrorWriter:             errorWriter,
+	}.Register(mx)
+
 	notify.Routes{
 		RequestCounter:                  requestCounter,
 		RequestLogging:                  requestLogging,
@@ -190,6 +244,7 @@ func NewRouter(mx muxer, config Config) http.Handler {
 		OrganizationStrategy: organizationStrategy,
 		EveryoneStrategy:     everyoneStrategy,
 		UAAScopeStrategy:     uaaScopeStrategy,
+		UAAGroupStrategy:     uaaGroupStrategy,
 		EmailStrategy:        emailStrategy,
 	}.Register(mx)
 
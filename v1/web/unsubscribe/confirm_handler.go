@@ -0,0 +1,36 @@
+package unsubscribe
+
+import (
+	"net/http"
+
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/ryanmoran/stack"
+)
+
+type ConfirmHandler struct {
+	verifier tokenVerifier
+}
+
+func NewConfirmHandler(verifier tokenVerifier) ConfirmHandler {
+	return ConfirmHandler{
+		verifier: verifier,
+	}
+}
+
+func (h ConfirmHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	token := req.URL.Query().Get("token")
+
+	unsubscribe, err := common.ParseEmailUnsubscribeToken(h.verifier, token)
+	if err != nil {
+		writePage(w, http.StatusBadRequest, errorPage, "This unsubscribe link is invalid.")
+		return
+	}
+
+	writePage(w, http.StatusOK, confirmPage, struct {
+		Email string
+		Token string
+	}{
+		Email: unsubscribe.Email,
+		Token: token,
+	})
+}
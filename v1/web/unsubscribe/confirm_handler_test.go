@@ -0,0 +1,62 @@
+package unsubscribe_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/unsubscribe"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ConfirmHandler", func() {
+	var (
+		handler  unsubscribe.ConfirmHandler
+		verifier *mocks.Signer
+		writer   *httptest.ResponseRecorder
+		request  *http.Request
+	)
+
+	BeforeEach(func() {
+		verifier = mocks.NewSigner()
+		verifier.VerifyCall.Returns.Payload = []byte(`{
+			"email": "someone@example.com",
+			"client_id": "some-client-id",
+			"campaign_type_id": "some-campaign-type-id"
+		}`)
+
+		writer = httptest.NewRecorder()
+
+		var err error
+		request, err = http.NewRequest("GET", "/unsubscribe?token=some-token", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		handler = unsubscribe.NewConfirmHandler(verifier)
+	})
+
+	It("renders a page asking the recipient to confirm", func() {
+		handler.ServeHTTP(writer, request, stack.NewContext())
+
+		Expect(verifier.VerifyCall.Receives.Token).To(Equal("some-token"))
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.HeaderMap.Get("Content-Type")).To(Equal("text/html; charset=utf-8"))
+		Expect(writer.Body.String()).To(ContainSubstring("Stop sending these notifications to someone@example.com?"))
+		Expect(writer.Body.String()).To(ContainSubstring(`<form method="POST" action="/unsubscribe?token=some-token">`))
+	})
+
+	Context("when the token is invalid", func() {
+		It("returns a 400", func() {
+			verifier.VerifyCall.Returns.Error = errors.New("invalid signature")
+
+			handler.ServeHTTP(writer, request, stack.NewContext())
+
+			Expect(writer.Code).To(Equal(http.StatusBadRequest))
+			Expect(writer.Body.String()).To(ContainSubstring("This unsubscribe link is invalid."))
+		})
+	})
+})
@@ -0,0 +1,7 @@
+package unsubscribe
+
+import "github.com/cloudfoundry-incubator/notifications/v1/models"
+
+type DatabaseInterface interface {
+	models.DatabaseInterface
+}
@@ -0,0 +1,13 @@
+package unsubscribe_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestWebV1UnsubscribeSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "v1/web/unsubscribe")
+}
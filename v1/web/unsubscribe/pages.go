@@ -0,0 +1,42 @@
+package unsubscribe
+
+import (
+	"html/template"
+	"net/http"
+)
+
+var confirmPage = template.Must(template.New("confirm").Parse(`<!DOCTYPE html>
+<html>
+	<head><title>Unsubscribe</title></head>
+	<body>
+		<p>Stop sending these notifications to {{.Email}}?</p>
+		<form method="POST" action="/unsubscribe?token={{.Token}}">
+			<button type="submit">Unsubscribe</button>
+		</form>
+	</body>
+</html>
+`))
+
+var unsubscribedPage = template.Must(template.New("unsubscribed").Parse(`<!DOCTYPE html>
+<html>
+	<head><title>Unsubscribed</title></head>
+	<body>
+		<p>{{.Email}} has been unsubscribed from these notifications.</p>
+	</body>
+</html>
+`))
+
+var errorPage = template.Must(template.New("error").Parse(`<!DOCTYPE html>
+<html>
+	<head><title>Unsubscribe</title></head>
+	<body>
+		<p>{{.}}</p>
+	</body>
+</html>
+`))
+
+func writePage(w http.ResponseWriter, status int, page *template.Template, data interface{}) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	page.Execute(w, data)
+}
@@ -0,0 +1,41 @@
+package unsubscribe
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+	"github.com/ryanmoran/stack"
+)
+
+type muxer interface {
+	Handle(method, path string, handler stack.Handler, middleware ...stack.Middleware)
+}
+
+type tokenVerifier interface {
+	Verify(token string) ([]byte, error)
+}
+
+type emailUnsubscribesGetInserter interface {
+	Get(connection models.ConnectionInterface, email, clientID, campaignTypeID string) (bool, error)
+	Insert(connection models.ConnectionInterface, email, clientID, campaignTypeID string) error
+}
+
+type preferenceChangesRecorder interface {
+	Record(connection models.ConnectionInterface, actor models.Actor, change models.PreferenceChange) error
+}
+
+type Routes struct {
+	RequestCounter    stack.Middleware
+	RequestLogging    stack.Middleware
+	DatabaseAllocator stack.Middleware
+
+	Verifier          tokenVerifier
+	EmailUnsubscribes emailUnsubscribesGetInserter
+	PreferenceChanges preferenceChangesRecorder
+}
+
+// Register adds the pages linked from the unsubscribe footer and the
+// List-Unsubscribe header. They are authorized by the signed token alone, so
+// they sit on the default (v1) router for requests without a version header.
+func (r Routes) Register(m muxer) {
+	m.Handle("GET", "/unsubscribe", NewConfirmHandler(r.Verifier), r.RequestLogging, r.RequestCounter)
+	m.Handle("POST", "/unsubscribe", NewUnsubscribeHandler(r.Verifier, r.EmailUnsubscribes, r.PreferenceChanges), r.RequestLogging, r.RequestCounter, r.DatabaseAllocator)
+}
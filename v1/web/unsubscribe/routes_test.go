@@ -0,0 +1,50 @@
+package unsubscribe_test
+
+import (
+	"net/http"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/middleware"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/unsubscribe"
+	"github.com/cloudfoundry-incubator/notifications/web"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Routes", func() {
+	var muxer web.Muxer
+
+	BeforeEach(func() {
+		muxer = web.NewMuxer()
+		unsubscribe.Routes{
+			RequestCounter:    middleware.RequestCounter{},
+			RequestLogging:    middleware.RequestLogging{},
+			DatabaseAllocator: middleware.DatabaseAllocator{},
+
+			Verifier:          mocks.NewSigner(),
+			EmailUnsubscribes: mocks.NewEmailUnsubscribesRepository(),
+			PreferenceChanges: mocks.NewPreferenceChangesRepository(),
+		}.Register(muxer)
+	})
+
+	It("routes GET /unsubscribe", func() {
+		request, err := http.NewRequest("GET", "/unsubscribe?token=some-token", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		s := muxer.Match(request).(stack.Stack)
+		Expect(s.Handler).To(BeAssignableToTypeOf(unsubscribe.ConfirmHandler{}))
+		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{})
+	})
+
+	It("routes POST /unsubscribe", func() {
+		request, err := http.NewRequest("POST", "/unsubscribe?token=some-token", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		s := muxer.Match(request).(stack.Stack)
+		Expect(s.Handler).To(BeAssignableToTypeOf(unsubscribe.UnsubscribeHandler{}))
+		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.DatabaseAllocator{})
+	})
+})
@@ -0,0 +1,82 @@
+package unsubscribe
+
+import (
+	"net/http"
+
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+	"github.com/ryanmoran/stack"
+)
+
+type UnsubscribeHandler struct {
+	verifier          tokenVerifier
+	emailUnsubscribes emailUnsubscribesGetInserter
+	preferenceChanges preferenceChangesRecorder
+}
+
+func NewUnsubscribeHandler(verifier tokenVerifier, emailUnsubscribes emailUnsubscribesGetInserter, preferenceChanges preferenceChangesRecorder) UnsubscribeHandler {
+	return UnsubscribeHandler{
+		verifier:          verifier,
+		emailUnsubscribes: emailUnsubscribes,
+		preferenceChanges: preferenceChanges,
+	}
+}
+
+// ServeHTTP records the opt-out submitted by the confirm page, and also serves
+// mail clients performing a one-click unsubscribe (RFC 8058).
+func (h UnsubscribeHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	unsubscribe, err := common.ParseEmailUnsubscribeToken(h.verifier, req.URL.Query().Get("token"))
+	if err != nil {
+		writePage(w, http.StatusBadRequest, errorPage, "This unsubscribe link is invalid.")
+		return
+	}
+
+	database := context.Get("database").(DatabaseInterface)
+	transaction := database.Connection().Transaction()
+
+	err = transaction.Begin()
+	if err != nil {
+		writePage(w, http.StatusInternalServerError, errorPage, "Something went wrong. Please try again later.")
+		return
+	}
+
+	err = h.unsubscribe(transaction, unsubscribe)
+	if err != nil {
+		transaction.Rollback()
+		writePage(w, http.StatusInternalServerError, errorPage, "Something went wrong. Please try again later.")
+		return
+	}
+
+	err = transaction.Commit()
+	if err != nil {
+		writePage(w, http.StatusInternalServerError, errorPage, "Something went wrong. Please try again later.")
+		return
+	}
+
+	writePage(w, http.StatusOK, unsubscribedPage, unsubscribe)
+}
+
+func (h UnsubscribeHandler) unsubscribe(connection models.ConnectionInterface, unsubscribe common.EmailUnsubscribe) error {
+	unsubscribed, err := h.emailUnsubscribes.Get(connection, unsubscribe.Email, unsubscribe.ClientID, unsubscribe.CampaignTypeID)
+	if err != nil {
+		return err
+	}
+
+	err = h.emailUnsubscribes.Insert(connection, unsubscribe.Email, unsubscribe.ClientID, unsubscribe.CampaignTypeID)
+	if err != nil {
+		return err
+	}
+
+	return h.preferenceChanges.Record(connection, models.Actor{
+		Type:   models.ActorRecipient,
+		ID:     unsubscribe.Email,
+		Source: models.SourceLink,
+	}, models.PreferenceChange{
+		Email:      unsubscribe.Email,
+		Preference: models.PreferenceCampaignType,
+		ClientID:   unsubscribe.ClientID,
+		TargetID:   unsubscribe.CampaignTypeID,
+		OldValue:   models.SubscriptionValue(unsubscribed),
+		NewValue:   models.Unsubscribed,
+	})
+}
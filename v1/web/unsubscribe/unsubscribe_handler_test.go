@@ -0,0 +1,147 @@
+package unsubscribe_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/unsubscribe"
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("UnsubscribeHandler", func() {
+	var (
+		handler           unsubscribe.UnsubscribeHandler
+		verifier          *mocks.Signer
+		emailUnsubscribes *mocks.EmailUnsubscribesRepository
+		preferenceChanges *mocks.PreferenceChangesRepository
+		conn              *mocks.Connection
+		transaction       *mocks.Transaction
+		writer            *httptest.ResponseRecorder
+		request           *http.Request
+		context           stack.Context
+	)
+
+	BeforeEach(func() {
+		verifier = mocks.NewSigner()
+		verifier.VerifyCall.Returns.Payload = []byte(`{
+			"email": "someone@example.com",
+			"client_id": "some-client-id",
+			"campaign_type_id": "some-campaign-type-id"
+		}`)
+		emailUnsubscribes = mocks.NewEmailUnsubscribesRepository()
+		preferenceChanges = mocks.NewPreferenceChangesRepository()
+
+		transaction = mocks.NewTransaction()
+		conn = mocks.NewConnection()
+		conn.TransactionCall.Returns.Transaction = transaction
+		database := mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = conn
+
+		context = stack.NewContext()
+		context.Set("database", database)
+
+		writer = httptest.NewRecorder()
+
+		var err error
+		request, err = http.NewRequest("POST", "/unsubscribe?token=some-token", strings.NewReader("List-Unsubscribe=One-Click"))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler = unsubscribe.NewUnsubscribeHandler(verifier, emailUnsubscribes, preferenceChanges)
+	})
+
+	It("unsubscribes the email address from the campaign type", func() {
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(verifier.VerifyCall.Receives.Token).To(Equal("some-token"))
+
+		Expect(emailUnsubscribes.InsertCall.Receives.Connection).To(Equal(transaction))
+		Expect(emailUnsubscribes.InsertCall.Receives.Email).To(Equal("someone@example.com"))
+		Expect(emailUnsubscribes.InsertCall.Receives.ClientID).To(Equal("some-client-id"))
+		Expect(emailUnsubscribes.InsertCall.Receives.CampaignTypeID).To(Equal("some-campaign-type-id"))
+
+		Expect(transaction.BeginCall.WasCalled).To(BeTrue())
+		Expect(transaction.CommitCall.WasCalled).To(BeTrue())
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.String()).To(ContainSubstring("someone@example.com has been unsubscribed from these notifications."))
+	})
+
+	It("records the change made through the link", func() {
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(emailUnsubscribes.GetCall.Receives.Connection).To(Equal(transaction))
+		Expect(emailUnsubscribes.GetCall.Receives.Email).To(Equal("someone@example.com"))
+		Expect(emailUnsubscribes.GetCall.Receives.ClientID).To(Equal("some-client-id"))
+		Expect(emailUnsubscribes.GetCall.Receives.CampaignTypeID).To(Equal("some-campaign-type-id"))
+
+		Expect(preferenceChanges.RecordCall.Receives.Connection).To(Equal(transaction))
+		Expect(preferenceChanges.RecordCall.Receives.Actor).To(Equal(models.Actor{
+			Type:   models.ActorRecipient,
+			ID:     "someone@example.com",
+			Source: models.SourceLink,
+		}))
+		Expect(preferenceChanges.RecordCall.Receives.Changes).To(Equal([]models.PreferenceChange{
+			{
+				Email:      "someone@example.com",
+				Preference: models.PreferenceCampaignType,
+				ClientID:   "some-client-id",
+				TargetID:   "some-campaign-type-id",
+				OldValue:   models.Subscribed,
+				NewValue:   models.Unsubscribed,
+			},
+		}))
+	})
+
+	Context("failure cases", func() {
+		It("returns a 400 when the token is invalid", func() {
+			verifier.VerifyCall.Returns.Error = errors.New("invalid signature")
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusBadRequest))
+			Expect(emailUnsubscribes.InsertCall.Receives.Email).To(BeEmpty())
+		})
+
+		It("returns a 500 when the unsubscribe cannot be saved", func() {
+			emailUnsubscribes.InsertCall.Returns.Error = errors.New("some database error")
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusInternalServerError))
+		})
+
+		It("returns a 500 when the existing unsubscribe cannot be read", func() {
+			emailUnsubscribes.GetCall.Returns.Error = errors.New("some database error")
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusInternalServerError))
+			Expect(emailUnsubscribes.InsertCall.Receives.Email).To(BeEmpty())
+		})
+
+		It("returns a 500 and rolls back the unsubscribe when the change cannot be recorded", func() {
+			preferenceChanges.RecordCall.Returns.Error = errors.New("some database error")
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusInternalServerError))
+			Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+			Expect(transaction.CommitCall.WasCalled).To(BeFalse())
+		})
+
+		It("returns a 500 when the transaction cannot be committed", func() {
+			transaction.CommitCall.Returns.Error = errors.New("commit failed")
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusInternalServerError))
+		})
+	})
+})
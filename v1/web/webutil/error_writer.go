@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/cloudfoundry-incubator/notifications/cf"
+	"github.com/cloudfoundry-incubator/notifications/uaa"
 	"github.com/cloudfoundry-incubator/notifications/v1/collections"
 	"github.com/cloudfoundry-incubator/notifications/v1/models"
 	"github.com/cloudfoundry-incubator/notifications/v1/services"
@@ -22,7 +23,7 @@ func (writer ErrorWriter) Write(w http.ResponseWriter, err error) {
 		w.WriteHeader(422)
 	case services.CCDownError:
 		w.WriteHeader(http.StatusBadGateway)
-	case services.CCNotFoundError, models.NotFoundError, cf.NotFoundError:
+	case services.CCNotFoundError, models.NotFoundError, cf.NotFoundError, uaa.GroupNotFoundError:
 		w.WriteHeader(http.StatusNotFound)
 	case ParseError, SchemaError:
 		w.WriteHeader(http.StatusBadRequest)
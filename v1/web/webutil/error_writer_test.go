@@ -6,6 +6,7 @@ import (
 	"net/http/httptest"
 
 	"github.com/cloudfoundry-incubator/notifications/cf"
+	"github.com/cloudfoundry-incubator/notifications/uaa"
 	"github.com/cloudfoundry-incubator/notifications/v1/collections"
 	"github.com/cloudfoundry-incubator/notifications/v1/models"
 	"github.com/cloudfoundry-incubator/notifications/v1/services"
@@ -74,6 +75,14 @@ var _ = Describe("ErrorWriter", func() {
 		}`))
 	})
 
+	It("returns a 404 when the UAA group cannot be found", func() {
+		writer.Write(recorder, uaa.GroupNotFoundError{Name: "security-oncall"})
+		Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		Expect(recorder.Body).To(MatchJSON(`{
+			"errors": ["UAA group \"security-oncall\" could not be found"]
+		}`))
+	})
+
 	It("returns a 400 when the request cannot be parsed due to syntatically invalid JSON", func() {
 		writer.Write(recorder, webutil.ParseError{})
 		Expect(recorder.Code).To(Equal(400))
@@ -0,0 +1,175 @@
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+)
+
+const (
+	Version = 1
+
+	tarEntryName = "bundle.json"
+)
+
+type Bundle struct {
+	Version       int            `json:"version"`
+	ClientID      string         `json:"client_id"`
+	Templates     []Template     `json:"templates"`
+	Senders       []Sender       `json:"senders"`
+	V1Templates   []V1Template   `json:"v1_templates"`
+	V1Assignments []V1Assignment `json:"v1_assignments"`
+}
+
+type Template struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Subject  string `json:"subject"`
+	Text     string `json:"text"`
+	HTML     string `json:"html"`
+	Markdown string `json:"markdown"`
+	Metadata string `json:"metadata"`
+}
+
+type Sender struct {
+	ID            string         `json:"id"`
+	Name          string         `json:"name"`
+	CampaignTypes []CampaignType `json:"campaign_types"`
+}
+
+type CampaignType struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Critical    bool   `json:"critical"`
+	TemplateID  string `json:"template_id"`
+}
+
+type V1Template struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Subject  string `json:"subject"`
+	Text     string `json:"text"`
+	HTML     string `json:"html"`
+	Metadata string `json:"metadata"`
+}
+
+// V1Assignment records the template assigned to the client itself when
+// KindID is empty, or to one of its kinds otherwise.
+type V1Assignment struct {
+	KindID     string `json:"kind_id"`
+	TemplateID string `json:"template_id"`
+}
+
+type ValidationError struct {
+	Err error
+}
+
+func (e ValidationError) Error() string {
+	return e.Err.Error()
+}
+
+func (b Bundle) Validate() error {
+	if b.Version != Version {
+		return ValidationError{fmt.Errorf("unsupported bundle version %d", b.Version)}
+	}
+
+	templateIDs := map[string]bool{}
+	for _, template := range b.Templates {
+		if template.ID == "" || template.Name == "" {
+			return ValidationError{fmt.Errorf("templates must have an id and a name")}
+		}
+		templateIDs[template.ID] = true
+	}
+
+	for _, sender := range b.Senders {
+		if sender.Name == "" {
+			return ValidationError{fmt.Errorf("senders must have a name")}
+		}
+
+		for _, campaignType := range sender.CampaignTypes {
+			if campaignType.Name == "" {
+				return ValidationError{fmt.Errorf("campaign types must have a name")}
+			}
+
+			if campaignType.TemplateID != "" && campaignType.TemplateID != defaultTemplateID && !templateIDs[campaignType.TemplateID] {
+				return ValidationError{fmt.Errorf("campaign type %q references template %q which is not in the bundle", campaignType.Name, campaignType.TemplateID)}
+			}
+		}
+	}
+
+	v1TemplateIDs := map[string]bool{}
+	for _, template := range b.V1Templates {
+		if template.ID == "" || template.Name == "" {
+			return ValidationError{fmt.Errorf("v1 templates must have an id and a name")}
+		}
+		v1TemplateIDs[template.ID] = true
+	}
+
+	for _, assignment := range b.V1Assignments {
+		if assignment.TemplateID != defaultTemplateID && !v1TemplateIDs[assignment.TemplateID] {
+			return ValidationError{fmt.Errorf("v1 assignment references template %q which is not in the bundle", assignment.TemplateID)}
+		}
+	}
+
+	return nil
+}
+
+func (b Bundle) WriteTar(writer io.Writer) error {
+	content, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	archive := tar.NewWriter(writer)
+	err = archive.WriteHeader(&tar.Header{
+		Name:    tarEntryName,
+		Mode:    0644,
+		Size:    int64(len(content)),
+		ModTime: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = archive.Write(content)
+	if err != nil {
+		return err
+	}
+
+	return archive.Close()
+}
+
+func ReadTar(reader io.Reader) (Bundle, error) {
+	archive := tar.NewReader(reader)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			return Bundle{}, ValidationError{fmt.Errorf("archive does not contain %s", tarEntryName)}
+		}
+		if err != nil {
+			return Bundle{}, ValidationError{err}
+		}
+
+		if header.Name != tarEntryName {
+			continue
+		}
+
+		content, err := ioutil.ReadAll(archive)
+		if err != nil {
+			return Bundle{}, err
+		}
+
+		var b Bundle
+		err = json.NewDecoder(bytes.NewReader(content)).Decode(&b)
+		if err != nil {
+			return Bundle{}, ValidationError{err}
+		}
+
+		return b, nil
+	}
+}
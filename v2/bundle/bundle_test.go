@@ -0,0 +1,97 @@
+package bundle_test
+
+import (
+	"archive/tar"
+	"bytes"
+
+	"github.com/cloudfoundry-incubator/notifications/v2/bundle"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Bundle", func() {
+	var b bundle.Bundle
+
+	BeforeEach(func() {
+		b = bundle.Bundle{
+			Version:  bundle.Version,
+			ClientID: "some-client-id",
+			Templates: []bundle.Template{
+				{ID: "template-id", Name: "a template"},
+			},
+			Senders: []bundle.Sender{
+				{
+					ID:   "sender-id",
+					Name: "a sender",
+					CampaignTypes: []bundle.CampaignType{
+						{ID: "campaign-type-id", Name: "a campaign type", TemplateID: "template-id"},
+						{ID: "other-campaign-type-id", Name: "another campaign type", TemplateID: "default"},
+					},
+				},
+			},
+			V1Templates: []bundle.V1Template{
+				{ID: "v1-template-id", Name: "a v1 template"},
+			},
+			V1Assignments: []bundle.V1Assignment{
+				{TemplateID: "v1-template-id"},
+				{KindID: "some-kind", TemplateID: "default"},
+			},
+		}
+	})
+
+	Describe("Validate", func() {
+		It("accepts a well formed bundle", func() {
+			Expect(b.Validate()).To(Succeed())
+		})
+
+		It("rejects other versions", func() {
+			b.Version = 2
+
+			Expect(b.Validate()).To(BeAssignableToTypeOf(bundle.ValidationError{}))
+			Expect(b.Validate()).To(MatchError("unsupported bundle version 2"))
+		})
+
+		It("rejects campaign types referencing templates outside of the bundle", func() {
+			b.Senders[0].CampaignTypes[0].TemplateID = "missing-template-id"
+
+			Expect(b.Validate()).To(MatchError(`campaign type "a campaign type" references template "missing-template-id" which is not in the bundle`))
+		})
+
+		It("rejects v1 assignments referencing templates outside of the bundle", func() {
+			b.V1Assignments[0].TemplateID = "missing-template-id"
+
+			Expect(b.Validate()).To(MatchError(`v1 assignment references template "missing-template-id" which is not in the bundle`))
+		})
+
+		It("rejects unnamed records", func() {
+			b.Senders[0].Name = ""
+
+			Expect(b.Validate()).To(MatchError("senders must have a name"))
+		})
+	})
+
+	Describe("WriteTar and ReadTar", func() {
+		It("round trips the bundle through a tar archive", func() {
+			archive := bytes.NewBuffer([]byte{})
+			Expect(b.WriteTar(archive)).To(Succeed())
+
+			read, err := bundle.ReadTar(archive)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(read).To(Equal(b))
+		})
+
+		It("returns a validation error when the archive has no bundle in it", func() {
+			archive := bytes.NewBuffer([]byte{})
+			writer := tar.NewWriter(archive)
+			Expect(writer.WriteHeader(&tar.Header{Name: "other.txt", Mode: 0644, Size: 2})).To(Succeed())
+			_, err := writer.Write([]byte("hi"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(writer.Close()).To(Succeed())
+
+			_, err = bundle.ReadTar(archive)
+			Expect(err).To(BeAssignableToTypeOf(bundle.ValidationError{}))
+			Expect(err).To(MatchError("archive does not contain bundle.json"))
+		})
+	})
+})
@@ -0,0 +1,268 @@
+package bundle
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/db"
+	v1models "github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+)
+
+const defaultTemplateID = "default"
+
+type templatesCollection interface {
+	Set(conn collections.ConnectionInterface, template collections.Template) (collections.Template, error)
+	List(conn collections.ConnectionInterface, clientID string) ([]collections.Template, error)
+}
+
+type sendersCollection interface {
+	Set(conn collections.ConnectionInterface, sender collections.Sender) (collections.Sender, error)
+	List(conn collections.ConnectionInterface, clientID string) ([]collections.Sender, error)
+}
+
+type campaignTypesCollection interface {
+	Set(conn collections.ConnectionInterface, campaignType collections.CampaignType, clientID string) (collections.CampaignType, error)
+	List(conn collections.ConnectionInterface, senderID, clientID string) ([]collections.CampaignType, error)
+}
+
+type v1ClientsRepository interface {
+	Find(conn v1models.ConnectionInterface, clientID string) (v1models.Client, error)
+	FindAllByTemplateID(conn v1models.ConnectionInterface, templateID string) ([]v1models.Client, error)
+	Update(conn v1models.ConnectionInterface, client v1models.Client) (v1models.Client, error)
+}
+
+type v1KindsRepository interface {
+	Find(conn v1models.ConnectionInterface, kindID, clientID string) (v1models.Kind, error)
+	FindAll(conn v1models.ConnectionInterface) ([]v1models.Kind, error)
+	FindAllByTemplateID(conn v1models.ConnectionInterface, templateID string) ([]v1models.Kind, error)
+	Update(conn v1models.ConnectionInterface, kind v1models.Kind) (v1models.Kind, error)
+}
+
+type v1TemplatesRepository interface {
+	FindByID(conn v1models.ConnectionInterface, templateID string) (v1models.Template, error)
+	ListIDsAndNames(conn v1models.ConnectionInterface) ([]v1models.Template, error)
+	Create(conn v1models.ConnectionInterface, template v1models.Template) (v1models.Template, error)
+	Update(conn v1models.ConnectionInterface, templateID string, template v1models.Template) (v1models.Template, error)
+}
+
+type BundlerConfig struct {
+	V1Database db.DatabaseInterface
+
+	TemplatesCollection     templatesCollection
+	SendersCollection       sendersCollection
+	CampaignTypesCollection campaignTypesCollection
+
+	V1ClientsRepository   v1ClientsRepository
+	V1KindsRepository     v1KindsRepository
+	V1TemplatesRepository v1TemplatesRepository
+}
+
+// Bundler moves a client's templates, senders and campaign types, along with
+// its v1 template assignments, between deployments. The v1 records live in
+// tables that are not mapped on the v2 database, so they are read and written
+// through their own database.
+type Bundler struct {
+	v1Database db.DatabaseInterface
+
+	templates     templatesCollection
+	senders       sendersCollection
+	campaignTypes campaignTypesCollection
+
+	v1Clients   v1ClientsRepository
+	v1Kinds     v1KindsRepository
+	v1Templates v1TemplatesRepository
+}
+
+func NewBundler(config BundlerConfig) Bundler {
+	return Bundler{
+		v1Database:    config.V1Database,
+		templates:     config.TemplatesCollection,
+		senders:       config.SendersCollection,
+		campaignTypes: config.CampaignTypesCollection,
+		v1Clients:     config.V1ClientsRepository,
+		v1Kinds:       config.V1KindsRepository,
+		v1Templates:   config.V1TemplatesRepository,
+	}
+}
+
+func (b Bundler) Export(conn db.ConnectionInterface, clientID string) (Bundle, error) {
+	exported := Bundle{
+		Version:       Version,
+		ClientID:      clientID,
+		Templates:     []Template{},
+		Senders:       []Sender{},
+		V1Templates:   []V1Template{},
+		V1Assignments: []V1Assignment{},
+	}
+
+	templates, err := b.templates.List(conn, clientID)
+	if err != nil {
+		return Bundle{}, err
+	}
+
+	for _, template := range templates {
+		exported.Templates = append(exported.Templates, Template{
+			ID:       template.ID,
+			Name:     template.Name,
+			Subject:  template.Subject,
+			Text:     template.Text,
+			HTML:     template.HTML,
+			Markdown: template.Markdown,
+			Metadata: template.Metadata,
+		})
+	}
+
+	senders, err := b.senders.List(conn, clientID)
+	if err != nil {
+		return Bundle{}, err
+	}
+
+	for _, sender := range senders {
+		campaignTypes, err := b.campaignTypes.List(conn, sender.ID, clientID)
+		if err != nil {
+			return Bundle{}, err
+		}
+
+		exportedSender := Sender{
+			ID:            sender.ID,
+			Name:          sender.Name,
+			CampaignTypes: []CampaignType{},
+		}
+
+		for _, campaignType := range campaignTypes {
+			exportedSender.CampaignTypes = append(exportedSender.CampaignTypes, CampaignType{
+				ID:          campaignType.ID,
+				Name:        campaignType.Name,
+				Description: campaignType.Description,
+				Critical:    campaignType.Critical,
+				TemplateID:  campaignType.TemplateID,
+			})
+		}
+
+		exported.Senders = append(exported.Senders, exportedSender)
+	}
+
+	v1Conn := b.v1Database.Connection()
+
+	client, err := b.v1Clients.Find(v1Conn, clientID)
+	if err != nil {
+		if _, ok := err.(v1models.NotFoundError); ok {
+			return exported, nil
+		}
+
+		return Bundle{}, err
+	}
+
+	exported.V1Assignments = append(exported.V1Assignments, V1Assignment{
+		TemplateID: client.TemplateToUse(),
+	})
+
+	kinds, err := b.v1Kinds.FindAll(v1Conn)
+	if err != nil {
+		return Bundle{}, err
+	}
+
+	for _, kind := range kinds {
+		if kind.ClientID != clientID {
+			continue
+		}
+
+		exported.V1Assignments = append(exported.V1Assignments, V1Assignment{
+			KindID:     kind.ID,
+			TemplateID: kind.TemplateToUse(),
+		})
+	}
+
+	seen := map[string]bool{defaultTemplateID: true}
+	for _, assignment := range exported.V1Assignments {
+		if seen[assignment.TemplateID] {
+			continue
+		}
+		seen[assignment.TemplateID] = true
+
+		template, err := b.v1Templates.FindByID(v1Conn, assignment.TemplateID)
+		if err != nil {
+			return Bundle{}, err
+		}
+
+		exported.V1Templates = append(exported.V1Templates, V1Template{
+			ID:       template.ID,
+			Name:     template.Name,
+			Subject:  template.Subject,
+			Text:     template.Text,
+			HTML:     template.HTML,
+			Metadata: template.Metadata,
+		})
+	}
+
+	return exported, nil
+}
+
+// Import applies the bundle to the given client, matching existing records
+// by name and remapping the IDs that reference them. In dry-run mode nothing
+// is written and the returned diff describes what an import would do.
+func (b Bundler) Import(conn db.ConnectionInterface, clientID string, imported Bundle, dryRun bool) (Diff, error) {
+	err := imported.Validate()
+	if err != nil {
+		return Diff{}, err
+	}
+
+	run := &importRun{
+		Bundler:       b,
+		clientID:      clientID,
+		dryRun:        dryRun,
+		conn:          conn,
+		v1Conn:        b.v1Database.Connection(),
+		templateIDs:   map[string]string{defaultTemplateID: defaultTemplateID},
+		v1TemplateIDs: map[string]string{defaultTemplateID: defaultTemplateID},
+		diff: Diff{
+			DryRun:  dryRun,
+			Changes: []Change{},
+		},
+	}
+
+	if dryRun {
+		err = run.apply(imported)
+		if err != nil {
+			return Diff{}, err
+		}
+
+		return run.diff, nil
+	}
+
+	transaction := conn.Transaction()
+	v1Transaction := run.v1Conn.Transaction()
+	run.conn = transaction
+	run.v1Conn = v1Transaction
+
+	err = transaction.Begin()
+	if err != nil {
+		return Diff{}, err
+	}
+
+	err = v1Transaction.Begin()
+	if err != nil {
+		transaction.Rollback()
+		return Diff{}, err
+	}
+
+	err = run.apply(imported)
+	if err != nil {
+		transaction.Rollback()
+		v1Transaction.Rollback()
+		return Diff{}, err
+	}
+
+	// The v1 records are committed first so that a failure there can still
+	// roll back the v2 records and leave the deployment untouched.
+	err = v1Transaction.Commit()
+	if err != nil {
+		transaction.Rollback()
+		return Diff{}, err
+	}
+
+	err = transaction.Commit()
+	if err != nil {
+		return Diff{}, err
+	}
+
+	return run.diff, nil
+}
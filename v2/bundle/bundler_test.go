@@ -0,0 +1,411 @@
+package bundle_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	v1models "github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v2/bundle"
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Bundler", func() {
+	var (
+		bundler                 bundle.Bundler
+		conn                    *mocks.Connection
+		v1Conn                  *mocks.Connection
+		templatesCollection     *mocks.TemplatesCollection
+		sendersCollection       *mocks.SendersCollection
+		campaignTypesCollection *mocks.CampaignTypesCollection
+		clientsRepo             *mocks.ClientsRepository
+		kindsRepo               *mocks.KindsRepo
+		v1TemplatesRepo         *mocks.TemplatesRepo
+	)
+
+	BeforeEach(func() {
+		conn = mocks.NewConnection()
+		v1Conn = mocks.NewConnection()
+		v1Database := mocks.NewDatabase()
+		v1Database.ConnectionCall.Returns.Connection = v1Conn
+
+		templatesCollection = mocks.NewTemplatesCollection()
+		sendersCollection = mocks.NewSendersCollection()
+		campaignTypesCollection = mocks.NewCampaignTypesCollection()
+		clientsRepo = mocks.NewClientsRepository()
+		kindsRepo = mocks.NewKindsRepo()
+		v1TemplatesRepo = mocks.NewTemplatesRepo()
+
+		bundler = bundle.NewBundler(bundle.BundlerConfig{
+			V1Database:              v1Database,
+			TemplatesCollection:     templatesCollection,
+			SendersCollection:       sendersCollection,
+			CampaignTypesCollection: campaignTypesCollection,
+			V1ClientsRepository:     clientsRepo,
+			V1KindsRepository:       kindsRepo,
+			V1TemplatesRepository:   v1TemplatesRepo,
+		})
+	})
+
+	Describe("Export", func() {
+		BeforeEach(func() {
+			templatesCollection.ListCall.Returns.Templates = []collections.Template{
+				{
+					ID:       "template-id",
+					Name:     "a template",
+					Subject:  "{{.Subject}}",
+					Markdown: "*hi*",
+					ClientID: "some-client-id",
+				},
+			}
+			sendersCollection.ListCall.Returns.SenderList = []collections.Sender{
+				{ID: "sender-id", Name: "a sender", ClientID: "some-client-id"},
+			}
+			campaignTypesCollection.ListCall.Returns.CampaignTypeList = []collections.CampaignType{
+				{
+					ID:          "campaign-type-id",
+					Name:        "a campaign type",
+					Description: "about things",
+					Critical:    true,
+					TemplateID:  "template-id",
+					SenderID:    "sender-id",
+				},
+			}
+			clientsRepo.FindCall.Returns.Client = v1models.Client{
+				ID:         "some-client-id",
+				TemplateID: "v1-template-id",
+			}
+			kindsRepo.FindAllCall.Returns.Kinds = []v1models.Kind{
+				{ID: "some-kind", ClientID: "some-client-id", TemplateID: "v1-template-id"},
+				{ID: "other-kind", ClientID: "some-client-id"},
+				{ID: "some-kind", ClientID: "other-client-id", TemplateID: "other-template-id"},
+			}
+			v1TemplatesRepo.FindByIDCall.Returns.Template = v1models.Template{
+				ID:      "v1-template-id",
+				Name:    "a v1 template",
+				Subject: "v1 {{.Subject}}",
+				Text:    "v1 text",
+			}
+		})
+
+		It("exports the client's records", func() {
+			exported, err := bundler.Export(conn, "some-client-id")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(exported).To(Equal(bundle.Bundle{
+				Version:  bundle.Version,
+				ClientID: "some-client-id",
+				Templates: []bundle.Template{
+					{ID: "template-id", Name: "a template", Subject: "{{.Subject}}", Markdown: "*hi*"},
+				},
+				Senders: []bundle.Sender{
+					{
+						ID:   "sender-id",
+						Name: "a sender",
+						CampaignTypes: []bundle.CampaignType{
+							{
+								ID:          "campaign-type-id",
+								Name:        "a campaign type",
+								Description: "about things",
+								Critical:    true,
+								TemplateID:  "template-id",
+							},
+						},
+					},
+				},
+				V1Templates: []bundle.V1Template{
+					{ID: "v1-template-id", Name: "a v1 template", Subject: "v1 {{.Subject}}", Text: "v1 text"},
+				},
+				V1Assignments: []bundle.V1Assignment{
+					{TemplateID: "v1-template-id"},
+					{KindID: "some-kind", TemplateID: "v1-template-id"},
+					{KindID: "other-kind", TemplateID: "default"},
+				},
+			}))
+
+			Expect(templatesCollection.ListCall.Receives.Connection).To(Equal(conn))
+			Expect(templatesCollection.ListCall.Receives.ClientID).To(Equal("some-client-id"))
+			Expect(campaignTypesCollection.ListCall.Receives.SenderID).To(Equal("sender-id"))
+			Expect(clientsRepo.FindCall.Receives.Connection).To(Equal(v1Conn))
+			Expect(v1TemplatesRepo.FindByIDCall.Receives.TemplateID).To(Equal("v1-template-id"))
+		})
+
+		It("omits v1 assignments for clients that never registered with v1", func() {
+			clientsRepo.FindCall.Returns.Error = v1models.NotFoundError{errors.New("not found")}
+
+			exported, err := bundler.Export(conn, "some-client-id")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(exported.V1Templates).To(BeEmpty())
+			Expect(exported.V1Assignments).To(BeEmpty())
+		})
+
+		It("returns errors from the collections", func() {
+			sendersCollection.ListCall.Returns.Error = errors.New("failed to list senders")
+
+			_, err := bundler.Export(conn, "some-client-id")
+			Expect(err).To(MatchError("failed to list senders"))
+		})
+	})
+
+	Describe("Import", func() {
+		var (
+			imported      bundle.Bundle
+			transaction   *mocks.Transaction
+			v1Transaction *mocks.Transaction
+		)
+
+		BeforeEach(func() {
+			transaction = mocks.NewTransaction()
+			conn.TransactionCall.Returns.Transaction = transaction
+			v1Transaction = mocks.NewTransaction()
+			v1Conn.TransactionCall.Returns.Transaction = v1Transaction
+
+			imported = bundle.Bundle{
+				Version:  bundle.Version,
+				ClientID: "staging-client-id",
+				Templates: []bundle.Template{
+					{ID: "staging-template-id", Name: "a template", Text: "some text"},
+				},
+				Senders: []bundle.Sender{
+					{
+						ID:   "staging-sender-id",
+						Name: "a sender",
+						CampaignTypes: []bundle.CampaignType{
+							{ID: "staging-campaign-type-id", Name: "a campaign type", TemplateID: "staging-template-id"},
+						},
+					},
+				},
+				V1Templates: []bundle.V1Template{
+					{ID: "staging-v1-template-id", Name: "a v1 template", Text: "v1 text"},
+				},
+				V1Assignments: []bundle.V1Assignment{
+					{TemplateID: "staging-v1-template-id"},
+				},
+			}
+
+			clientsRepo.FindCall.Returns.Client = v1models.Client{
+				ID:         "some-client-id",
+				TemplateID: "default",
+			}
+		})
+
+		Context("when none of the records exist yet", func() {
+			BeforeEach(func() {
+				templatesCollection.SetCall.Returns.Template = collections.Template{ID: "new-template-id"}
+				sendersCollection.SetCall.Returns.Sender = collections.Sender{ID: "new-sender-id"}
+				campaignTypesCollection.SetCall.Returns.CampaignType = collections.CampaignType{ID: "new-campaign-type-id"}
+				v1TemplatesRepo.CreateCall.Returns.Template = v1models.Template{ID: "new-v1-template-id"}
+			})
+
+			It("creates them with remapped IDs inside transactions", func() {
+				diff, err := bundler.Import(conn, "some-client-id", imported, false)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(diff).To(Equal(bundle.Diff{
+					Changes: []bundle.Change{
+						{Resource: "template", Action: "create", Name: "a template", SourceID: "staging-template-id", ID: "new-template-id"},
+						{Resource: "sender", Action: "create", Name: "a sender", SourceID: "staging-sender-id", ID: "new-sender-id"},
+						{Resource: "campaign_type", Action: "create", Name: "a campaign type", SourceID: "staging-campaign-type-id", ID: "new-campaign-type-id"},
+						{Resource: "v1_template", Action: "create", Name: "a v1 template", SourceID: "staging-v1-template-id", ID: "new-v1-template-id"},
+						{Resource: "v1_assignment", Action: "update", Name: "some-client-id", SourceID: "staging-v1-template-id", ID: "new-v1-template-id"},
+					},
+				}))
+
+				Expect(templatesCollection.SetCall.Receives.Connection).To(Equal(transaction))
+				Expect(templatesCollection.SetCall.Receives.Template).To(Equal(collections.Template{
+					Name:     "a template",
+					Text:     "some text",
+					ClientID: "some-client-id",
+				}))
+
+				Expect(sendersCollection.SetCall.Receives.Sender).To(Equal(collections.Sender{
+					Name:     "a sender",
+					ClientID: "some-client-id",
+				}))
+
+				Expect(campaignTypesCollection.ListCall.Receives.SenderID).To(Equal("new-sender-id"))
+				Expect(campaignTypesCollection.SetCall.Receives.CampaignType).To(Equal(collections.CampaignType{
+					Name:       "a campaign type",
+					TemplateID: "new-template-id",
+					SenderID:   "new-sender-id",
+				}))
+
+				Expect(v1TemplatesRepo.CreateCall.Receives.Connection).To(Equal(v1Transaction))
+				Expect(v1TemplatesRepo.CreateCall.Receives.Template).To(Equal(v1models.Template{
+					Name: "a v1 template",
+					Text: "v1 text",
+				}))
+
+				Expect(clientsRepo.UpdateCall.Receives.Client).To(Equal(v1models.Client{
+					ID:         "some-client-id",
+					TemplateID: "new-v1-template-id",
+				}))
+
+				Expect(transaction.BeginCall.WasCalled).To(BeTrue())
+				Expect(transaction.CommitCall.WasCalled).To(BeTrue())
+				Expect(v1Transaction.BeginCall.WasCalled).To(BeTrue())
+				Expect(v1Transaction.CommitCall.WasCalled).To(BeTrue())
+			})
+
+			It("only reports what would change in dry-run mode", func() {
+				diff, err := bundler.Import(conn, "some-client-id", imported, true)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(diff).To(Equal(bundle.Diff{
+					DryRun: true,
+					Changes: []bundle.Change{
+						{Resource: "template", Action: "create", Name: "a template", SourceID: "staging-template-id"},
+						{Resource: "sender", Action: "create", Name: "a sender", SourceID: "staging-sender-id"},
+						{Resource: "campaign_type", Action: "create", Name: "a campaign type", SourceID: "staging-campaign-type-id"},
+						{Resource: "v1_template", Action: "create", Name: "a v1 template", SourceID: "staging-v1-template-id"},
+						{Resource: "v1_assignment", Action: "update", Name: "some-client-id", SourceID: "staging-v1-template-id"},
+					},
+				}))
+
+				Expect(templatesCollection.SetCall.Receives.Template).To(Equal(collections.Template{}))
+				Expect(sendersCollection.SetCall.Receives.Sender).To(Equal(collections.Sender{}))
+				Expect(campaignTypesCollection.SetCall.WasCalled).To(BeFalse())
+				Expect(v1TemplatesRepo.CreateCall.Receives.Template).To(Equal(v1models.Template{}))
+				Expect(clientsRepo.UpdateCall.Receives.Client).To(Equal(v1models.Client{}))
+				Expect(transaction.BeginCall.WasCalled).To(BeFalse())
+			})
+		})
+
+		Context("when records with the same names exist", func() {
+			BeforeEach(func() {
+				templatesCollection.ListCall.Returns.Templates = []collections.Template{
+					{ID: "prod-template-id", Name: "a template", Text: "old text", ClientID: "some-client-id"},
+				}
+				templatesCollection.SetCall.Returns.Template = collections.Template{ID: "prod-template-id"}
+				sendersCollection.ListCall.Returns.SenderList = []collections.Sender{
+					{ID: "prod-sender-id", Name: "a sender", ClientID: "some-client-id"},
+				}
+				campaignTypesCollection.ListCall.Returns.CampaignTypeList = []collections.CampaignType{
+					{ID: "prod-campaign-type-id", Name: "a campaign type", TemplateID: "prod-template-id", SenderID: "prod-sender-id"},
+				}
+				v1TemplatesRepo.ListIDsAndNamesCall.Returns.Templates = []v1models.Template{
+					{ID: "prod-v1-template-id", Name: "a v1 template"},
+				}
+				v1TemplatesRepo.FindByIDCall.Returns.Template = v1models.Template{
+					ID:   "prod-v1-template-id",
+					Name: "a v1 template",
+					Text: "v1 text",
+				}
+				clientsRepo.FindCall.Returns.Client.TemplateID = "prod-v1-template-id"
+				clientsRepo.FindAllByTemplateIDCall.Returns.Clients = []v1models.Client{
+					{ID: "some-client-id", TemplateID: "prod-v1-template-id"},
+				}
+			})
+
+			It("updates the records that differ and leaves the rest alone", func() {
+				diff, err := bundler.Import(conn, "some-client-id", imported, false)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(diff.Changes).To(Equal([]bundle.Change{
+					{Resource: "template", Action: "update", Name: "a template", SourceID: "staging-template-id", ID: "prod-template-id"},
+					{Resource: "sender", Action: "unchanged", Name: "a sender", SourceID: "staging-sender-id", ID: "prod-sender-id"},
+					{Resource: "campaign_type", Action: "unchanged", Name: "a campaign type", SourceID: "staging-campaign-type-id", ID: "prod-campaign-type-id"},
+					{Resource: "v1_template", Action: "unchanged", Name: "a v1 template", SourceID: "staging-v1-template-id", ID: "prod-v1-template-id"},
+					{Resource: "v1_assignment", Action: "unchanged", Name: "some-client-id", SourceID: "staging-v1-template-id", ID: "prod-v1-template-id"},
+				}))
+
+				Expect(templatesCollection.SetCall.Receives.Template).To(Equal(collections.Template{
+					ID:       "prod-template-id",
+					Name:     "a template",
+					Text:     "some text",
+					ClientID: "some-client-id",
+				}))
+				Expect(sendersCollection.SetCall.Receives.Sender).To(Equal(collections.Sender{}))
+				Expect(campaignTypesCollection.SetCall.WasCalled).To(BeFalse())
+				Expect(v1TemplatesRepo.UpdateCall.Receives.TemplateID).To(BeEmpty())
+				Expect(clientsRepo.UpdateCall.Receives.Client).To(Equal(v1models.Client{}))
+				Expect(clientsRepo.FindAllByTemplateIDCall.Receives.TemplateID).To(Equal("prod-v1-template-id"))
+				Expect(kindsRepo.FindAllByTemplateIDCall.Receives.TemplateID).To(Equal("prod-v1-template-id"))
+			})
+
+			It("refuses to overwrite a v1 template that another client uses", func() {
+				kindsRepo.FindAllByTemplateIDCall.Returns.Kinds = []v1models.Kind{
+					{ID: "other-kind", ClientID: "other-client-id", TemplateID: "prod-v1-template-id"},
+				}
+
+				_, err := bundler.Import(conn, "some-client-id", imported, false)
+				Expect(err).To(MatchError(`v1 template "a v1 template" belongs to another client`))
+				Expect(err).To(BeAssignableToTypeOf(collections.DuplicateRecordError{}))
+
+				Expect(v1TemplatesRepo.UpdateCall.Receives.TemplateID).To(BeEmpty())
+				Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+				Expect(v1Transaction.RollbackCall.WasCalled).To(BeTrue())
+			})
+
+			It("refuses to overwrite a v1 template that no client uses", func() {
+				clientsRepo.FindAllByTemplateIDCall.Returns.Clients = nil
+
+				_, err := bundler.Import(conn, "some-client-id", imported, true)
+				Expect(err).To(MatchError(`v1 template "a v1 template" belongs to another client`))
+			})
+		})
+
+		It("skips v1 assignments for kinds that do not exist in this deployment", func() {
+			imported.V1Assignments = []bundle.V1Assignment{
+				{KindID: "missing-kind", TemplateID: "default"},
+			}
+			kindsRepo.FindCall.Returns.Kinds = []v1models.Kind{{}}
+			kindsRepo.FindCall.Returns.Error = v1models.NotFoundError{errors.New("not found")}
+
+			diff, err := bundler.Import(conn, "some-client-id", imported, true)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(diff.Changes).To(ContainElement(bundle.Change{
+				Resource: "v1_assignment",
+				Action:   "skip",
+				Name:     "missing-kind",
+				SourceID: "default",
+				ID:       "default",
+			}))
+		})
+
+		Context("failure cases", func() {
+			It("returns validation errors without touching the database", func() {
+				imported.Version = 7
+
+				_, err := bundler.Import(conn, "some-client-id", imported, false)
+				Expect(err).To(MatchError("unsupported bundle version 7"))
+				Expect(transaction.BeginCall.WasCalled).To(BeFalse())
+			})
+
+			It("rolls back both transactions when a write fails", func() {
+				sendersCollection.SetCall.Returns.Error = errors.New("failed to save sender")
+
+				_, err := bundler.Import(conn, "some-client-id", imported, false)
+				Expect(err).To(MatchError("failed to save sender"))
+
+				Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+				Expect(v1Transaction.RollbackCall.WasCalled).To(BeTrue())
+				Expect(transaction.CommitCall.WasCalled).To(BeFalse())
+			})
+
+			It("returns the error when a transaction cannot begin", func() {
+				v1Transaction.BeginCall.Returns.Error = errors.New("failed to begin")
+
+				_, err := bundler.Import(conn, "some-client-id", imported, false)
+				Expect(err).To(MatchError("failed to begin"))
+
+				Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+				Expect(templatesCollection.SetCall.Receives.Template).To(Equal(collections.Template{}))
+			})
+
+			It("rolls back the v2 records when the v1 records fail to commit", func() {
+				v1Transaction.CommitCall.Returns.Error = errors.New("failed to commit")
+
+				_, err := bundler.Import(conn, "some-client-id", imported, false)
+				Expect(err).To(MatchError("failed to commit"))
+
+				Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+				Expect(transaction.CommitCall.WasCalled).To(BeFalse())
+			})
+		})
+	})
+})
@@ -0,0 +1,33 @@
+package bundle
+
+const (
+	ActionCreate    = "create"
+	ActionUpdate    = "update"
+	ActionUnchanged = "unchanged"
+	ActionSkip      = "skip"
+)
+
+const (
+	ResourceTemplate     = "template"
+	ResourceSender       = "sender"
+	ResourceCampaignType = "campaign_type"
+	ResourceV1Template   = "v1_template"
+	ResourceV1Assignment = "v1_assignment"
+)
+
+// Change describes what an import did, or would do in dry-run mode, to a
+// single resource in the bundle. SourceID is the ID the resource had in the
+// exporting deployment and ID is the one it maps to in this deployment; ID
+// is empty for resources that a dry run would create.
+type Change struct {
+	Resource string `json:"resource"`
+	Action   string `json:"action"`
+	Name     string `json:"name"`
+	SourceID string `json:"source_id"`
+	ID       string `json:"id"`
+}
+
+type Diff struct {
+	DryRun  bool     `json:"dry_run"`
+	Changes []Change `json:"changes"`
+}
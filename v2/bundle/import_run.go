@@ -0,0 +1,350 @@
+package bundle
+
+import (
+	"fmt"
+
+	"github.com/cloudfoundry-incubator/notifications/db"
+	v1models "github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+)
+
+type importRun struct {
+	Bundler
+
+	clientID string
+	dryRun   bool
+	conn     db.ConnectionInterface
+	v1Conn   db.ConnectionInterface
+
+	templateIDs   map[string]string
+	v1TemplateIDs map[string]string
+	diff          Diff
+}
+
+func (r *importRun) apply(imported Bundle) error {
+	steps := []func(Bundle) error{
+		r.importTemplates,
+		r.importSenders,
+		r.importV1Templates,
+		r.importV1Assignments,
+	}
+
+	for _, step := range steps {
+		err := step(imported)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *importRun) record(resource, action, name, sourceID, id string) {
+	r.diff.Changes = append(r.diff.Changes, Change{
+		Resource: resource,
+		Action:   action,
+		Name:     name,
+		SourceID: sourceID,
+		ID:       id,
+	})
+}
+
+func (r *importRun) importTemplates(imported Bundle) error {
+	existing, err := r.templates.List(r.conn, r.clientID)
+	if err != nil {
+		return err
+	}
+
+	byName := map[string]collections.Template{}
+	for _, template := range existing {
+		byName[template.Name] = template
+	}
+
+	for _, template := range imported.Templates {
+		incoming := collections.Template{
+			Name:     template.Name,
+			Subject:  template.Subject,
+			Text:     template.Text,
+			HTML:     template.HTML,
+			Markdown: template.Markdown,
+			Metadata: template.Metadata,
+			ClientID: r.clientID,
+		}
+
+		action := ActionCreate
+		if current, ok := byName[template.Name]; ok {
+			incoming.ID = current.ID
+			action = ActionUpdate
+			if current == incoming {
+				action = ActionUnchanged
+			}
+		}
+
+		if action != ActionUnchanged && !r.dryRun {
+			saved, err := r.templates.Set(r.conn, incoming)
+			if err != nil {
+				return err
+			}
+			incoming.ID = saved.ID
+		}
+
+		r.templateIDs[template.ID] = incoming.ID
+		r.record(ResourceTemplate, action, template.Name, template.ID, incoming.ID)
+	}
+
+	return nil
+}
+
+func (r *importRun) importSenders(imported Bundle) error {
+	existing, err := r.senders.List(r.conn, r.clientID)
+	if err != nil {
+		return err
+	}
+
+	byName := map[string]collections.Sender{}
+	for _, sender := range existing {
+		byName[sender.Name] = sender
+	}
+
+	for _, sender := range imported.Senders {
+		senderID := ""
+		action := ActionCreate
+		if current, ok := byName[sender.Name]; ok {
+			senderID = current.ID
+			action = ActionUnchanged
+		} else if !r.dryRun {
+			saved, err := r.senders.Set(r.conn, collections.Sender{
+				Name:     sender.Name,
+				ClientID: r.clientID,
+			})
+			if err != nil {
+				return err
+			}
+			senderID = saved.ID
+		}
+
+		r.record(ResourceSender, action, sender.Name, sender.ID, senderID)
+
+		err = r.importCampaignTypes(sender, senderID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *importRun) importCampaignTypes(sender Sender, senderID string) error {
+	byName := map[string]collections.CampaignType{}
+	if senderID != "" {
+		existing, err := r.campaignTypes.List(r.conn, senderID, r.clientID)
+		if err != nil {
+			return err
+		}
+
+		for _, campaignType := range existing {
+			byName[campaignType.Name] = campaignType
+		}
+	}
+
+	for _, campaignType := range sender.CampaignTypes {
+		incoming := collections.CampaignType{
+			Name:        campaignType.Name,
+			Description: campaignType.Description,
+			Critical:    campaignType.Critical,
+			TemplateID:  r.templateIDs[campaignType.TemplateID],
+			SenderID:    senderID,
+		}
+
+		action := ActionCreate
+		if current, ok := byName[campaignType.Name]; ok {
+			incoming.ID = current.ID
+			action = ActionUpdate
+			if current == incoming {
+				action = ActionUnchanged
+			}
+		}
+
+		if action != ActionUnchanged && !r.dryRun {
+			saved, err := r.campaignTypes.Set(r.conn, incoming, r.clientID)
+			if err != nil {
+				return err
+			}
+			incoming.ID = saved.ID
+		}
+
+		r.record(ResourceCampaignType, action, campaignType.Name, campaignType.ID, incoming.ID)
+	}
+
+	return nil
+}
+
+func (r *importRun) importV1Templates(imported Bundle) error {
+	existing, err := r.v1Templates.ListIDsAndNames(r.v1Conn)
+	if err != nil {
+		return err
+	}
+
+	byName := map[string]string{}
+	for _, template := range existing {
+		byName[template.Name] = template.ID
+	}
+
+	for _, template := range imported.V1Templates {
+		incoming := v1models.Template{
+			Name:     template.Name,
+			Subject:  template.Subject,
+			Text:     template.Text,
+			HTML:     template.HTML,
+			Metadata: template.Metadata,
+		}
+
+		action := ActionCreate
+		if id, ok := byName[template.Name]; ok {
+			owned, err := r.ownsV1Template(id)
+			if err != nil {
+				return err
+			}
+
+			if !owned {
+				return collections.DuplicateRecordError{Err: fmt.Errorf("v1 template %q belongs to another client", template.Name)}
+			}
+
+			current, err := r.v1Templates.FindByID(r.v1Conn, id)
+			if err != nil {
+				return err
+			}
+
+			incoming.ID = id
+			action = ActionUpdate
+			if current.Subject == incoming.Subject && current.Text == incoming.Text && current.HTML == incoming.HTML && current.Metadata == incoming.Metadata {
+				action = ActionUnchanged
+			}
+		}
+
+		if !r.dryRun {
+			switch action {
+			case ActionCreate:
+				saved, err := r.v1Templates.Create(r.v1Conn, incoming)
+				if err != nil {
+					return err
+				}
+				incoming.ID = saved.ID
+			case ActionUpdate:
+				_, err := r.v1Templates.Update(r.v1Conn, incoming.ID, incoming)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		r.v1TemplateIDs[template.ID] = incoming.ID
+		r.record(ResourceV1Template, action, template.Name, template.ID, incoming.ID)
+	}
+
+	return nil
+}
+
+// ownsV1Template reports whether the v1 template is used only by the client
+// and its kinds. v1 templates have no owner of their own and share one
+// namespace, so a template that is unassigned, global or assigned elsewhere
+// must not be overwritten by an import.
+func (r *importRun) ownsV1Template(templateID string) (bool, error) {
+	if templateID == defaultTemplateID {
+		return false, nil
+	}
+
+	clients, err := r.v1Clients.FindAllByTemplateID(r.v1Conn, templateID)
+	if err != nil {
+		return false, err
+	}
+
+	kinds, err := r.v1Kinds.FindAllByTemplateID(r.v1Conn, templateID)
+	if err != nil {
+		return false, err
+	}
+
+	if len(clients) == 0 && len(kinds) == 0 {
+		return false, nil
+	}
+
+	for _, client := range clients {
+		if client.ID != r.clientID {
+			return false, nil
+		}
+	}
+
+	for _, kind := range kinds {
+		if kind.ClientID != r.clientID {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func (r *importRun) importV1Assignments(imported Bundle) error {
+	for _, assignment := range imported.V1Assignments {
+		templateID := r.v1TemplateIDs[assignment.TemplateID]
+
+		var (
+			current string
+			update  func() error
+			name    = assignment.KindID
+		)
+
+		if assignment.KindID == "" {
+			name = r.clientID
+
+			client, err := r.v1Clients.Find(r.v1Conn, r.clientID)
+			if err != nil {
+				if _, ok := err.(v1models.NotFoundError); ok {
+					r.record(ResourceV1Assignment, ActionSkip, name, assignment.TemplateID, templateID)
+					continue
+				}
+
+				return err
+			}
+
+			current = client.TemplateToUse()
+			update = func() error {
+				client.TemplateID = templateID
+				_, err := r.v1Clients.Update(r.v1Conn, client)
+				return err
+			}
+		} else {
+			kind, err := r.v1Kinds.Find(r.v1Conn, assignment.KindID, r.clientID)
+			if err != nil {
+				if _, ok := err.(v1models.NotFoundError); ok {
+					r.record(ResourceV1Assignment, ActionSkip, name, assignment.TemplateID, templateID)
+					continue
+				}
+
+				return err
+			}
+
+			current = kind.TemplateToUse()
+			update = func() error {
+				kind.TemplateID = templateID
+				_, err := r.v1Kinds.Update(r.v1Conn, kind)
+				return err
+			}
+		}
+
+		action := ActionUpdate
+		if current == templateID {
+			action = ActionUnchanged
+		}
+
+		if action == ActionUpdate && !r.dryRun {
+			err := update()
+			if err != nil {
+				return err
+			}
+		}
+
+		r.record(ResourceV1Assignment, action, name, assignment.TemplateID, templateID)
+	}
+
+	return nil
+}
@@ -0,0 +1,13 @@
+package bundle_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestBundleSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "v2/bundle")
+}
@@ -0,0 +1,335 @@
+package collections
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+)
+
+var audienceMemberKeys = []string{"users", "spaces", "orgs", "emails"}
+
+type Audience struct {
+	ID        string
+	Name      string
+	SenderID  string
+	Version   int
+	Users     []string
+	Spaces    []string
+	Orgs      []string
+	Emails    []string
+	Exclude   map[string][]string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// AudienceUpdate holds the fields of an audience to change. Fields left nil
+// keep their stored value.
+type AudienceUpdate struct {
+	Name    *string
+	Users   *[]string
+	Spaces  *[]string
+	Orgs    *[]string
+	Emails  *[]string
+	Exclude *map[string][]string
+}
+
+type audiencesRepository interface {
+	Insert(conn models.ConnectionInterface, audience models.Audience) (insertedAudience models.Audience, err error)
+	Update(conn models.ConnectionInterface, audience models.Audience) (updatedAudience models.Audience, err error)
+	Get(conn models.ConnectionInterface, audienceID string) (retrievedAudience models.Audience, err error)
+	GetForUpdate(conn models.ConnectionInterface, audienceID string) (retrievedAudience models.Audience, err error)
+	List(conn models.ConnectionInterface, senderID string) (retrievedAudienceList []models.Audience, err error)
+	Delete(conn models.ConnectionInterface, audience models.Audience) error
+}
+
+type AudiencesCollection struct {
+	audiencesRepo audiencesRepository
+	sendersRepo   sendersGetter
+}
+
+func NewAudiencesCollection(audiencesRepo audiencesRepository, sendersRepo sendersGetter) AudiencesCollection {
+	return AudiencesCollection{
+		audiencesRepo: audiencesRepo,
+		sendersRepo:   sendersRepo,
+	}
+}
+
+// Set saves the audience as a new version. Earlier versions are kept so that
+// campaigns created against them are not affected by the change.
+func (c AudiencesCollection) Set(conn ConnectionInterface, audience Audience, clientID string) (Audience, error) {
+	sender, err := c.sendersRepo.Get(conn, audience.SenderID)
+	err = validateSender(clientID, audience.SenderID, sender, err)
+	if err != nil {
+		return Audience{}, err
+	}
+
+	err = validateAudienceDefinition(audience)
+	if err != nil {
+		return Audience{}, err
+	}
+
+	record := newModelFromAudience(audience)
+
+	transaction := conn.Transaction()
+	err = transaction.Begin()
+	if err != nil {
+		return Audience{}, PersistenceError{err}
+	}
+
+	var model models.Audience
+	if audience.ID == "" {
+		model, err = c.audiencesRepo.Insert(transaction, record)
+	} else {
+		model, err = c.audiencesRepo.Update(transaction, record)
+	}
+	if err != nil {
+		transaction.Rollback()
+		return Audience{}, saveError(err)
+	}
+
+	err = transaction.Commit()
+	if err != nil {
+		return Audience{}, PersistenceError{err}
+	}
+
+	return newAudienceFromModel(model)
+}
+
+// Update applies the given changes to the stored audience and saves the
+// result as a new version. The audience is read and written within one
+// transaction that holds a lock on it, so concurrent updates are applied one
+// after the other and neither is lost.
+func (c AudiencesCollection) Update(conn ConnectionInterface, audienceID, clientID string, update AudienceUpdate) (Audience, error) {
+	transaction := conn.Transaction()
+	err := transaction.Begin()
+	if err != nil {
+		return Audience{}, PersistenceError{err}
+	}
+
+	audience, err := c.getForUpdate(transaction, audienceID, clientID)
+	if err != nil {
+		transaction.Rollback()
+		return Audience{}, err
+	}
+
+	if update.Name != nil {
+		audience.Name = *update.Name
+	}
+
+	if update.Users != nil {
+		audience.Users = *update.Users
+	}
+
+	if update.Spaces != nil {
+		audience.Spaces = *update.Spaces
+	}
+
+	if update.Orgs != nil {
+		audience.Orgs = *update.Orgs
+	}
+
+	if update.Emails != nil {
+		audience.Emails = *update.Emails
+	}
+
+	if update.Exclude != nil {
+		audience.Exclude = *update.Exclude
+	}
+
+	if audience.Name == "" {
+		transaction.Rollback()
+		return Audience{}, ValidationError{errors.New(`Audience "name" field cannot be empty`)}
+	}
+
+	err = validateAudienceDefinition(audience)
+	if err != nil {
+		transaction.Rollback()
+		return Audience{}, err
+	}
+
+	model, err := c.audiencesRepo.Update(transaction, newModelFromAudience(audience))
+	if err != nil {
+		transaction.Rollback()
+		return Audience{}, saveError(err)
+	}
+
+	err = transaction.Commit()
+	if err != nil {
+		return Audience{}, PersistenceError{err}
+	}
+
+	return newAudienceFromModel(model)
+}
+
+func (c AudiencesCollection) getForUpdate(conn ConnectionInterface, audienceID, clientID string) (Audience, error) {
+	model, err := c.audiencesRepo.GetForUpdate(conn, audienceID)
+	if err != nil {
+		switch err.(type) {
+		case models.RecordNotFoundError:
+			return Audience{}, NotFoundError{err}
+		default:
+			return Audience{}, UnknownError{err}
+		}
+	}
+
+	err = c.checkOwner(conn, model, audienceID, clientID)
+	if err != nil {
+		return Audience{}, err
+	}
+
+	return newAudienceFromModel(model)
+}
+
+func (c AudiencesCollection) Get(conn ConnectionInterface, audienceID, clientID string) (Audience, error) {
+	model, err := c.audiencesRepo.Get(conn, audienceID)
+	if err != nil {
+		switch err.(type) {
+		case models.RecordNotFoundError:
+			return Audience{}, NotFoundError{err}
+		default:
+			return Audience{}, UnknownError{err}
+		}
+	}
+
+	err = c.checkOwner(conn, model, audienceID, clientID)
+	if err != nil {
+		return Audience{}, err
+	}
+
+	return newAudienceFromModel(model)
+}
+
+// checkOwner hides audiences whose sender belongs to another client.
+func (c AudiencesCollection) checkOwner(conn ConnectionInterface, model models.Audience, audienceID, clientID string) error {
+	sender, err := c.sendersRepo.Get(conn, model.SenderID)
+	if err != nil {
+		if _, ok := err.(models.RecordNotFoundError); !ok {
+			return UnknownError{err}
+		}
+	}
+
+	if err != nil || sender.ClientID != clientID {
+		return NotFoundError{fmt.Errorf("Audience with id %q could not be found", audienceID)}
+	}
+
+	return nil
+}
+
+func (c AudiencesCollection) List(conn ConnectionInterface, senderID, clientID string) ([]Audience, error) {
+	sender, err := c.sendersRepo.Get(conn, senderID)
+	err = validateSender(clientID, senderID, sender, err)
+	if err != nil {
+		return []Audience{}, err
+	}
+
+	modelList, err := c.audiencesRepo.List(conn, senderID)
+	if err != nil {
+		return []Audience{}, UnknownError{err}
+	}
+
+	audienceList := []Audience{}
+	for _, model := range modelList {
+		audience, err := newAudienceFromModel(model)
+		if err != nil {
+			return []Audience{}, err
+		}
+
+		audienceList = append(audienceList, audience)
+	}
+
+	return audienceList, nil
+}
+
+func (c AudiencesCollection) Delete(conn ConnectionInterface, audienceID, clientID string) error {
+	_, err := c.Get(conn, audienceID, clientID)
+	if err != nil {
+		return err
+	}
+
+	err = c.audiencesRepo.Delete(conn, models.Audience{ID: audienceID})
+	if err != nil {
+		return UnknownError{err}
+	}
+
+	return nil
+}
+
+func validateAudienceDefinition(audience Audience) error {
+	if len(audience.Users)+len(audience.Spaces)+len(audience.Orgs)+len(audience.Emails) == 0 {
+		return ValidationError{errors.New("An audience must include at least one of users, spaces, orgs or emails")}
+	}
+
+	for key := range audience.Exclude {
+		if !isAudienceMemberKey(key) {
+			return ValidationError{fmt.Errorf("The %q audience cannot be excluded from a saved audience", key)}
+		}
+	}
+
+	return nil
+}
+
+func isAudienceMemberKey(key string) bool {
+	for _, memberKey := range audienceMemberKeys {
+		if key == memberKey {
+			return true
+		}
+	}
+
+	return false
+}
+
+func saveError(err error) error {
+	switch err.(type) {
+	case models.DuplicateRecordError:
+		return DuplicateRecordError{err}
+	default:
+		return PersistenceError{err}
+	}
+}
+
+func newModelFromAudience(audience Audience) models.Audience {
+	definition, err := json.Marshal(models.AudienceDefinition{
+		Users:   audience.Users,
+		Spaces:  audience.Spaces,
+		Orgs:    audience.Orgs,
+		Emails:  audience.Emails,
+		Exclude: audience.Exclude,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	return models.Audience{
+		ID:         audience.ID,
+		Name:       audience.Name,
+		SenderID:   audience.SenderID,
+		Version:    audience.Version,
+		Definition: string(definition),
+		CreatedAt:  audience.CreatedAt,
+	}
+}
+
+func newAudienceFromModel(model models.Audience) (Audience, error) {
+	var definition models.AudienceDefinition
+	err := json.Unmarshal([]byte(model.Definition), &definition)
+	if err != nil {
+		return Audience{}, UnknownError{err}
+	}
+
+	return Audience{
+		ID:        model.ID,
+		Name:      model.Name,
+		SenderID:  model.SenderID,
+		Version:   model.Version,
+		Users:     definition.Users,
+		Spaces:    definition.Spaces,
+		Orgs:      definition.Orgs,
+		Emails:    definition.Emails,
+		Exclude:   definition.Exclude,
+		CreatedAt: model.CreatedAt,
+		UpdatedAt: model.UpdatedAt,
+	}, nil
+}
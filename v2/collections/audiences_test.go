@@ -0,0 +1,365 @@
+package collections_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AudiencesCollection", func() {
+	var (
+		audiencesCollection collections.AudiencesCollection
+		audiencesRepository *mocks.AudiencesRepository
+		sendersRepository   *mocks.SendersRepository
+		conn                *mocks.Connection
+		transaction         *mocks.Transaction
+		createdAt           time.Time
+	)
+
+	BeforeEach(func() {
+		audiencesRepository = mocks.NewAudiencesRepository()
+		sendersRepository = mocks.NewSendersRepository()
+		sendersRepository.GetCall.Returns.Sender = models.Sender{
+			ID:       "some-sender-id",
+			ClientID: "some-client-id",
+		}
+
+		transaction = mocks.NewTransaction()
+		conn = mocks.NewConnection()
+		conn.TransactionCall.Returns.Transaction = transaction
+
+		createdAt = time.Now().UTC().Truncate(time.Second)
+
+		audiencesCollection = collections.NewAudiencesCollection(audiencesRepository, sendersRepository)
+	})
+
+	Describe("Set", func() {
+		It("inserts an audience without an id inside a transaction", func() {
+			audiencesRepository.InsertCall.Returns.Audience = models.Audience{
+				ID:         "some-audience-id",
+				Name:       "security-oncall",
+				SenderID:   "some-sender-id",
+				Version:    1,
+				Definition: `{"users":["some-user-guid"],"emails":["oncall@example.com"],"exclude":{"users":["other-user-guid"]}}`,
+				CreatedAt:  createdAt,
+				UpdatedAt:  createdAt,
+			}
+
+			audience, err := audiencesCollection.Set(conn, collections.Audience{
+				Name:     "security-oncall",
+				SenderID: "some-sender-id",
+				Users:    []string{"some-user-guid"},
+				Emails:   []string{"oncall@example.com"},
+				Exclude:  map[string][]string{"users": {"other-user-guid"}},
+			}, "some-client-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(audience).To(Equal(collections.Audience{
+				ID:        "some-audience-id",
+				Name:      "security-oncall",
+				SenderID:  "some-sender-id",
+				Version:   1,
+				Users:     []string{"some-user-guid"},
+				Emails:    []string{"oncall@example.com"},
+				Exclude:   map[string][]string{"users": {"other-user-guid"}},
+				CreatedAt: createdAt,
+				UpdatedAt: createdAt,
+			}))
+
+			Expect(sendersRepository.GetCall.Receives.SenderID).To(Equal("some-sender-id"))
+
+			Expect(audiencesRepository.InsertCall.Receives.Connection).To(Equal(transaction))
+			Expect(audiencesRepository.InsertCall.Receives.Audience).To(Equal(models.Audience{
+				Name:       "security-oncall",
+				SenderID:   "some-sender-id",
+				Definition: `{"users":["some-user-guid"],"emails":["oncall@example.com"],"exclude":{"users":["other-user-guid"]}}`,
+			}))
+
+			Expect(transaction.BeginCall.WasCalled).To(BeTrue())
+			Expect(transaction.CommitCall.WasCalled).To(BeTrue())
+		})
+
+		It("updates an audience with an id", func() {
+			audiencesRepository.UpdateCall.Returns.Audience = models.Audience{
+				ID:         "some-audience-id",
+				Name:       "security-oncall",
+				SenderID:   "some-sender-id",
+				Version:    3,
+				Definition: `{"spaces":["some-space-guid"]}`,
+				CreatedAt:  createdAt,
+				UpdatedAt:  createdAt,
+			}
+
+			audience, err := audiencesCollection.Set(conn, collections.Audience{
+				ID:        "some-audience-id",
+				Name:      "security-oncall",
+				SenderID:  "some-sender-id",
+				Version:   2,
+				Spaces:    []string{"some-space-guid"},
+				CreatedAt: createdAt,
+			}, "some-client-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(audience.Version).To(Equal(3))
+
+			Expect(audiencesRepository.UpdateCall.Receives.Connection).To(Equal(transaction))
+			Expect(audiencesRepository.UpdateCall.Receives.Audience).To(Equal(models.Audience{
+				ID:         "some-audience-id",
+				Name:       "security-oncall",
+				SenderID:   "some-sender-id",
+				Version:    2,
+				Definition: `{"spaces":["some-space-guid"]}`,
+				CreatedAt:  createdAt,
+			}))
+		})
+
+		Context("failure cases", func() {
+			It("returns a not found error when the sender belongs to another client", func() {
+				_, err := audiencesCollection.Set(conn, collections.Audience{
+					Name:     "security-oncall",
+					SenderID: "some-sender-id",
+					Users:    []string{"some-user-guid"},
+				}, "other-client-id")
+				Expect(err).To(MatchError(collections.NotFoundError{errors.New(`Sender with id "some-sender-id" could not be found`)}))
+			})
+
+			It("returns a validation error when the audience has no members", func() {
+				_, err := audiencesCollection.Set(conn, collections.Audience{
+					Name:     "security-oncall",
+					SenderID: "some-sender-id",
+				}, "some-client-id")
+				Expect(err).To(MatchError(collections.ValidationError{errors.New("An audience must include at least one of users, spaces, orgs or emails")}))
+			})
+
+			It("returns a validation error when an exclusion is not a member audience", func() {
+				_, err := audiencesCollection.Set(conn, collections.Audience{
+					Name:     "security-oncall",
+					SenderID: "some-sender-id",
+					Users:    []string{"some-user-guid"},
+					Exclude:  map[string][]string{"everyone": {}},
+				}, "some-client-id")
+				Expect(err).To(MatchError(collections.ValidationError{errors.New(`The "everyone" audience cannot be excluded from a saved audience`)}))
+			})
+
+			It("returns a duplicate record error and rolls back when the name is taken", func() {
+				audiencesRepository.InsertCall.Returns.Error = models.DuplicateRecordError{errors.New("duplicate")}
+
+				_, err := audiencesCollection.Set(conn, collections.Audience{
+					Name:     "security-oncall",
+					SenderID: "some-sender-id",
+					Users:    []string{"some-user-guid"},
+				}, "some-client-id")
+				Expect(err).To(MatchError(collections.DuplicateRecordError{models.DuplicateRecordError{errors.New("duplicate")}}))
+				Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+				Expect(transaction.CommitCall.WasCalled).To(BeFalse())
+			})
+
+			It("returns a persistence error when the commit fails", func() {
+				transaction.CommitCall.Returns.Error = errors.New("commit failed")
+
+				_, err := audiencesCollection.Set(conn, collections.Audience{
+					Name:     "security-oncall",
+					SenderID: "some-sender-id",
+					Users:    []string{"some-user-guid"},
+				}, "some-client-id")
+				Expect(err).To(MatchError(collections.PersistenceError{errors.New("commit failed")}))
+			})
+		})
+	})
+
+	Describe("Update", func() {
+		BeforeEach(func() {
+			audiencesRepository.GetForUpdateCall.Returns.Audience = models.Audience{
+				ID:         "some-audience-id",
+				Name:       "security-oncall",
+				SenderID:   "some-sender-id",
+				Version:    2,
+				Definition: `{"users":["some-user-guid"],"emails":["oncall@example.com"]}`,
+				CreatedAt:  createdAt,
+				UpdatedAt:  createdAt,
+			}
+			audiencesRepository.UpdateCall.Returns.Audience = models.Audience{
+				ID:         "some-audience-id",
+				Name:       "security-oncall",
+				SenderID:   "some-sender-id",
+				Version:    3,
+				Definition: `{"users":["other-user-guid"],"emails":["oncall@example.com"]}`,
+				CreatedAt:  createdAt,
+				UpdatedAt:  createdAt,
+			}
+		})
+
+		It("applies the changes to the locked audience inside a transaction", func() {
+			users := []string{"other-user-guid"}
+			audience, err := audiencesCollection.Update(conn, "some-audience-id", "some-client-id", collections.AudienceUpdate{
+				Users: &users,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(audience.Version).To(Equal(3))
+			Expect(audience.Users).To(Equal([]string{"other-user-guid"}))
+
+			Expect(audiencesRepository.GetForUpdateCall.Receives.Connection).To(Equal(transaction))
+			Expect(audiencesRepository.GetForUpdateCall.Receives.AudienceID).To(Equal("some-audience-id"))
+			Expect(sendersRepository.GetCall.Receives.SenderID).To(Equal("some-sender-id"))
+
+			Expect(audiencesRepository.UpdateCall.Receives.Connection).To(Equal(transaction))
+			Expect(audiencesRepository.UpdateCall.Receives.Audience).To(Equal(models.Audience{
+				ID:         "some-audience-id",
+				Name:       "security-oncall",
+				SenderID:   "some-sender-id",
+				Version:    2,
+				Definition: `{"users":["other-user-guid"],"emails":["oncall@example.com"]}`,
+				CreatedAt:  createdAt,
+			}))
+
+			Expect(transaction.BeginCall.WasCalled).To(BeTrue())
+			Expect(transaction.CommitCall.WasCalled).To(BeTrue())
+		})
+
+		Context("failure cases", func() {
+			It("returns a not found error when the audience does not exist", func() {
+				audiencesRepository.GetForUpdateCall.Returns.Error = models.RecordNotFoundError{errors.New("not found")}
+
+				_, err := audiencesCollection.Update(conn, "some-audience-id", "some-client-id", collections.AudienceUpdate{})
+				Expect(err).To(MatchError(collections.NotFoundError{models.RecordNotFoundError{errors.New("not found")}}))
+				Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+			})
+
+			It("returns a not found error when the sender belongs to another client", func() {
+				_, err := audiencesCollection.Update(conn, "some-audience-id", "other-client-id", collections.AudienceUpdate{})
+				Expect(err).To(MatchError(collections.NotFoundError{errors.New(`Audience with id "some-audience-id" could not be found`)}))
+				Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+				Expect(audiencesRepository.UpdateCall.Receives.Audience).To(Equal(models.Audience{}))
+			})
+
+			It("returns a validation error when the name is cleared", func() {
+				name := ""
+				_, err := audiencesCollection.Update(conn, "some-audience-id", "some-client-id", collections.AudienceUpdate{
+					Name: &name,
+				})
+				Expect(err).To(MatchError(collections.ValidationError{errors.New(`Audience "name" field cannot be empty`)}))
+				Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+			})
+
+			It("returns a validation error when the audience is left without members", func() {
+				empty := []string{}
+				_, err := audiencesCollection.Update(conn, "some-audience-id", "some-client-id", collections.AudienceUpdate{
+					Users:  &empty,
+					Emails: &empty,
+				})
+				Expect(err).To(MatchError(collections.ValidationError{errors.New("An audience must include at least one of users, spaces, orgs or emails")}))
+			})
+
+			It("returns a duplicate record error and rolls back when the name is taken", func() {
+				audiencesRepository.UpdateCall.Returns.Error = models.DuplicateRecordError{errors.New("duplicate")}
+
+				name := "platform-oncall"
+				_, err := audiencesCollection.Update(conn, "some-audience-id", "some-client-id", collections.AudienceUpdate{
+					Name: &name,
+				})
+				Expect(err).To(MatchError(collections.DuplicateRecordError{models.DuplicateRecordError{errors.New("duplicate")}}))
+				Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+				Expect(transaction.CommitCall.WasCalled).To(BeFalse())
+			})
+		})
+	})
+
+	Describe("Get", func() {
+		BeforeEach(func() {
+			audiencesRepository.GetCall.Returns.Audience = models.Audience{
+				ID:         "some-audience-id",
+				Name:       "security-oncall",
+				SenderID:   "some-sender-id",
+				Version:    1,
+				Definition: `{"orgs":["some-org-guid"]}`,
+			}
+		})
+
+		It("returns the audience", func() {
+			audience, err := audiencesCollection.Get(conn, "some-audience-id", "some-client-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(audience).To(Equal(collections.Audience{
+				ID:       "some-audience-id",
+				Name:     "security-oncall",
+				SenderID: "some-sender-id",
+				Version:  1,
+				Orgs:     []string{"some-org-guid"},
+			}))
+
+			Expect(audiencesRepository.GetCall.Receives.AudienceID).To(Equal("some-audience-id"))
+		})
+
+		It("returns a not found error when the audience belongs to another client", func() {
+			_, err := audiencesCollection.Get(conn, "some-audience-id", "other-client-id")
+			Expect(err).To(MatchError(collections.NotFoundError{errors.New(`Audience with id "some-audience-id" could not be found`)}))
+		})
+
+		It("returns a not found error when the audience does not exist", func() {
+			audiencesRepository.GetCall.Returns.Error = models.RecordNotFoundError{errors.New("not found")}
+
+			_, err := audiencesCollection.Get(conn, "some-audience-id", "some-client-id")
+			Expect(err).To(MatchError(collections.NotFoundError{models.RecordNotFoundError{errors.New("not found")}}))
+		})
+	})
+
+	Describe("List", func() {
+		It("returns the audiences belonging to the sender", func() {
+			audiencesRepository.ListCall.Returns.Audiences = []models.Audience{
+				{
+					ID:         "some-audience-id",
+					Name:       "security-oncall",
+					SenderID:   "some-sender-id",
+					Version:    1,
+					Definition: `{"users":["some-user-guid"]}`,
+				},
+			}
+
+			audiences, err := audiencesCollection.List(conn, "some-sender-id", "some-client-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(audiences).To(Equal([]collections.Audience{
+				{
+					ID:       "some-audience-id",
+					Name:     "security-oncall",
+					SenderID: "some-sender-id",
+					Version:  1,
+					Users:    []string{"some-user-guid"},
+				},
+			}))
+
+			Expect(audiencesRepository.ListCall.Receives.SenderID).To(Equal("some-sender-id"))
+		})
+
+		It("returns a not found error when the sender belongs to another client", func() {
+			_, err := audiencesCollection.List(conn, "some-sender-id", "other-client-id")
+			Expect(err).To(MatchError(collections.NotFoundError{errors.New(`Sender with id "some-sender-id" could not be found`)}))
+		})
+	})
+
+	Describe("Delete", func() {
+		BeforeEach(func() {
+			audiencesRepository.GetCall.Returns.Audience = models.Audience{
+				ID:         "some-audience-id",
+				SenderID:   "some-sender-id",
+				Definition: `{"users":["some-user-guid"]}`,
+			}
+		})
+
+		It("deletes the audience", func() {
+			err := audiencesCollection.Delete(conn, "some-audience-id", "some-client-id")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(audiencesRepository.DeleteCall.Receives.Audience).To(Equal(models.Audience{ID: "some-audience-id"}))
+		})
+
+		It("does not delete an audience belonging to another client", func() {
+			err := audiencesCollection.Delete(conn, "some-audience-id", "other-client-id")
+			Expect(err).To(BeAssignableToTypeOf(collections.NotFoundError{}))
+
+			Expect(audiencesRepository.DeleteCall.Receives.Audience).To(Equal(models.Audience{}))
+		})
+	})
+})
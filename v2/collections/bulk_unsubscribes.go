@@ -0,0 +1,274 @@
+package collections
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+)
+
+type bulkUnsubscribersRepository interface {
+	Get(conn models.ConnectionInterface, userGUID, campaignTypeID string) (models.Unsubscriber, error)
+	Insert(conn models.ConnectionInterface, unsubscriber models.Unsubscriber) (models.Unsubscriber, error)
+}
+
+type globalUnsubscribesInserter interface {
+	Insert(conn models.ConnectionInterface, userGUID string) error
+	Get(conn models.ConnectionInterface, userGUID string) (bool, error)
+}
+
+type kindUnsubscribesRepository interface {
+	Insert(conn models.ConnectionInterface, unsubscriber models.KindUnsubscriber) error
+	Get(conn models.ConnectionInterface, unsubscriber models.KindUnsubscriber) (bool, error)
+	KindCritical(conn models.ConnectionInterface, clientID, kindID string) (bool, error)
+}
+
+type bulkUnsubscribesLister interface {
+	List(conn models.ConnectionInterface, offset, limit int) ([]models.BulkUnsubscriber, error)
+}
+
+// BulkUnsubscribe is a single opt-out in an import or export. Exactly one of
+// CampaignTypeID, the ClientID and KindID pair, or Global is set. Row is the
+// line of the import it was read from, and is used to report its errors.
+type BulkUnsubscribe struct {
+	UserGUID       string
+	CampaignTypeID string
+	ClientID       string
+	KindID         string
+	Global         bool
+	Row            int
+}
+
+type BulkUnsubscribeRowError struct {
+	Row   int
+	Error string
+}
+
+type BulkUnsubscribeResult struct {
+	Imported int
+	Errors   []BulkUnsubscribeRowError
+}
+
+type BulkUnsubscribesCollection struct {
+	unsubscribersRepository bulkUnsubscribersRepository
+	globalUnsubscribes      globalUnsubscribesInserter
+	kindUnsubscribes        kindUnsubscribesRepository
+	bulkUnsubscribes        bulkUnsubscribesLister
+	campaignTypesRepository campaignTypesGetter
+	preferenceChanges       preferenceChangesRecorder
+}
+
+func NewBulkUnsubscribesCollection(unsubscribersRepository bulkUnsubscribersRepository, globalUnsubscribes globalUnsubscribesInserter,
+	kindUnsubscribes kindUnsubscribesRepository, bulkUnsubscribes bulkUnsubscribesLister, campaignTypesRepository campaignTypesGetter,
+	preferenceChanges preferenceChangesRecorder) BulkUnsubscribesCollection {
+
+	return BulkUnsubscribesCollection{
+		unsubscribersRepository: unsubscribersRepository,
+		globalUnsubscribes:      globalUnsubscribes,
+		kindUnsubscribes:        kindUnsubscribes,
+		bulkUnsubscribes:        bulkUnsubscribes,
+		campaignTypesRepository: campaignTypesRepository,
+		preferenceChanges:       preferenceChanges,
+	}
+}
+
+// Import records every valid row and reports the rest by their Row, or by
+// their 1-based position when no Row is set. Users are not looked up in UAA, so that large imports do not need a
+// round trip per row. Any persistence failure rolls back the whole import.
+// Each new opt-out is recorded in the audit trail as made by the actor.
+func (c BulkUnsubscribesCollection) Import(conn ConnectionInterface, unsubscribes []BulkUnsubscribe, actor models.Actor) (BulkUnsubscribeResult, error) {
+	result := BulkUnsubscribeResult{
+		Errors: []BulkUnsubscribeRowError{},
+	}
+
+	transaction := conn.Transaction()
+	err := transaction.Begin()
+	if err != nil {
+		return BulkUnsubscribeResult{}, PersistenceError{err}
+	}
+
+	critical := map[string]bool{}
+	for i, unsubscribe := range unsubscribes {
+		err = c.importRow(transaction, unsubscribe, critical, actor)
+		switch err.(type) {
+		case nil:
+			result.Imported++
+		case PersistenceError:
+			transaction.Rollback()
+			return BulkUnsubscribeResult{}, err
+		default:
+			row := unsubscribe.Row
+			if row == 0 {
+				row = i + 1
+			}
+
+			result.Errors = append(result.Errors, BulkUnsubscribeRowError{
+				Row:   row,
+				Error: err.Error(),
+			})
+		}
+	}
+
+	err = transaction.Commit()
+	if err != nil {
+		return BulkUnsubscribeResult{}, PersistenceError{err}
+	}
+
+	return result, nil
+}
+
+func (c BulkUnsubscribesCollection) importRow(conn models.ConnectionInterface, unsubscribe BulkUnsubscribe, critical map[string]bool, actor models.Actor) error {
+	if unsubscribe.UserGUID == "" {
+		return ValidationError{errors.New("missing user_guid")}
+	}
+
+	targets := 0
+	if unsubscribe.Global {
+		targets++
+	}
+	if unsubscribe.CampaignTypeID != "" {
+		targets++
+	}
+	if unsubscribe.ClientID != "" || unsubscribe.KindID != "" {
+		targets++
+	}
+
+	if targets != 1 {
+		return ValidationError{errors.New("exactly one of campaign_type_id, client_id and kind_id, or global must be given")}
+	}
+
+	change := models.PreferenceChange{
+		UserGUID: unsubscribe.UserGUID,
+		OldValue: models.Subscribed,
+		NewValue: models.Unsubscribed,
+	}
+
+	switch {
+	case unsubscribe.Global:
+		unsubscribed, err := c.globalUnsubscribes.Get(conn, unsubscribe.UserGUID)
+		if err != nil {
+			return PersistenceError{err}
+		}
+
+		err = c.globalUnsubscribes.Insert(conn, unsubscribe.UserGUID)
+		if err != nil {
+			return PersistenceError{err}
+		}
+
+		change.Preference = models.PreferenceGlobal
+		change.OldValue = models.SubscriptionValue(unsubscribed)
+
+	case unsubscribe.CampaignTypeID != "":
+		isCritical, ok := critical[unsubscribe.CampaignTypeID]
+		if !ok {
+			campaignType, err := c.campaignTypesRepository.Get(conn, unsubscribe.CampaignTypeID)
+			if err != nil {
+				if _, ok := err.(models.RecordNotFoundError); ok {
+					return NotFoundError{fmt.Errorf("Campaign type %q not found", unsubscribe.CampaignTypeID)}
+				}
+				return PersistenceError{err}
+			}
+
+			isCritical = campaignType.Critical
+			critical[unsubscribe.CampaignTypeID] = isCritical
+		}
+
+		if isCritical {
+			return PermissionsError{fmt.Errorf("Campaign type %q cannot be unsubscribed from", unsubscribe.CampaignTypeID)}
+		}
+
+		_, err := c.unsubscribersRepository.Get(conn, unsubscribe.UserGUID, unsubscribe.CampaignTypeID)
+		switch err.(type) {
+		case nil:
+			return nil
+		case models.RecordNotFoundError:
+		default:
+			return PersistenceError{err}
+		}
+
+		_, err = c.unsubscribersRepository.Insert(conn, models.Unsubscriber{
+			CampaignTypeID: unsubscribe.CampaignTypeID,
+			UserGUID:       unsubscribe.UserGUID,
+		})
+		if err != nil {
+			return PersistenceError{err}
+		}
+
+		change.Preference = models.PreferenceCampaignType
+		change.TargetID = unsubscribe.CampaignTypeID
+
+	default:
+		if unsubscribe.ClientID == "" || unsubscribe.KindID == "" {
+			return ValidationError{errors.New("kind unsubscribes need both a client_id and a kind_id")}
+		}
+
+		key := unsubscribe.ClientID + "/" + unsubscribe.KindID
+		isCritical, ok := critical[key]
+		if !ok {
+			var err error
+			isCritical, err = c.kindUnsubscribes.KindCritical(conn, unsubscribe.ClientID, unsubscribe.KindID)
+			if err != nil {
+				if e, ok := err.(models.RecordNotFoundError); ok {
+					return NotFoundError{e}
+				}
+				return PersistenceError{err}
+			}
+
+			critical[key] = isCritical
+		}
+
+		if isCritical {
+			return PermissionsError{fmt.Errorf("Kind %q of client %q cannot be unsubscribed from", unsubscribe.KindID, unsubscribe.ClientID)}
+		}
+
+		kindUnsubscriber := models.KindUnsubscriber{
+			UserGUID: unsubscribe.UserGUID,
+			ClientID: unsubscribe.ClientID,
+			KindID:   unsubscribe.KindID,
+		}
+
+		unsubscribed, err := c.kindUnsubscribes.Get(conn, kindUnsubscriber)
+		if err != nil {
+			return PersistenceError{err}
+		}
+
+		err = c.kindUnsubscribes.Insert(conn, kindUnsubscriber)
+		if err != nil {
+			return PersistenceError{err}
+		}
+
+		change.Preference = models.PreferenceKind
+		change.ClientID = unsubscribe.ClientID
+		change.TargetID = unsubscribe.KindID
+		change.OldValue = models.SubscriptionValue(unsubscribed)
+	}
+
+	err := c.preferenceChanges.Record(conn, actor, change)
+	if err != nil {
+		return PersistenceError{err}
+	}
+
+	return nil
+}
+
+// Export lists a page of the global, v1 kind and v2 campaign type
+// unsubscribes, in that order.
+func (c BulkUnsubscribesCollection) Export(conn ConnectionInterface, offset, limit int) ([]BulkUnsubscribe, error) {
+	unsubscribers, err := c.bulkUnsubscribes.List(conn, offset, limit)
+	if err != nil {
+		return nil, PersistenceError{err}
+	}
+
+	unsubscribes := []BulkUnsubscribe{}
+	for _, unsubscriber := range unsubscribers {
+		unsubscribes = append(unsubscribes, BulkUnsubscribe{
+			UserGUID:       unsubscriber.UserGUID,
+			CampaignTypeID: unsubscriber.CampaignTypeID,
+			ClientID:       unsubscriber.ClientID,
+			KindID:         unsubscriber.KindID,
+			Global:         unsubscriber.Global,
+		})
+	}
+
+	return unsubscribes, nil
+}
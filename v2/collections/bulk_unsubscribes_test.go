@@ -0,0 +1,295 @@
+package collections_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BulkUnsubscribesCollection", func() {
+	var (
+		unsubscribersRepository *mocks.UnsubscribersRepository
+		globalUnsubscribes      *mocks.GlobalUnsubscribesRepository
+		kindUnsubscribes        *mocks.KindUnsubscribesRepository
+		bulkUnsubscribes        *mocks.BulkUnsubscribesRepository
+		campaignTypesRepository *mocks.CampaignTypesRepository
+		preferenceChanges       *mocks.PreferenceChangesRepository
+		actor                   models.Actor
+		connection              *mocks.Connection
+		transaction             *mocks.Transaction
+		collection              collections.BulkUnsubscribesCollection
+	)
+
+	BeforeEach(func() {
+		unsubscribersRepository = mocks.NewUnsubscribersRepository()
+		unsubscribersRepository.GetCall.Returns.Error = models.RecordNotFoundError{errors.New("not found")}
+
+		globalUnsubscribes = mocks.NewGlobalUnsubscribesRepository()
+		kindUnsubscribes = mocks.NewKindUnsubscribesRepository()
+		bulkUnsubscribes = mocks.NewBulkUnsubscribesRepository()
+
+		campaignTypesRepository = mocks.NewCampaignTypesRepository()
+		campaignTypesRepository.GetCall.Returns.CampaignType = models.CampaignType{
+			ID: "some-campaign-type-id",
+		}
+
+		preferenceChanges = mocks.NewPreferenceChangesRepository()
+		actor = models.Actor{
+			Type:   models.ActorClient,
+			ID:     "some-client-id",
+			Source: models.SourceImport,
+		}
+
+		transaction = mocks.NewTransaction()
+		connection = mocks.NewConnection()
+		connection.TransactionCall.Returns.Transaction = transaction
+
+		collection = collections.NewBulkUnsubscribesCollection(unsubscribersRepository, globalUnsubscribes, kindUnsubscribes, bulkUnsubscribes, campaignTypesRepository, preferenceChanges)
+	})
+
+	Describe("Import", func() {
+		It("imports global, kind and campaign type unsubscribes", func() {
+			result, err := collection.Import(connection, []collections.BulkUnsubscribe{
+				{UserGUID: "global-user-guid", Global: true},
+				{UserGUID: "kind-user-guid", ClientID: "some-client-id", KindID: "some-kind-id"},
+				{UserGUID: "campaign-type-user-guid", CampaignTypeID: "some-campaign-type-id"},
+			}, actor)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(collections.BulkUnsubscribeResult{
+				Imported: 3,
+				Errors:   []collections.BulkUnsubscribeRowError{},
+			}))
+
+			Expect(globalUnsubscribes.InsertCall.Receives.Connection).To(Equal(transaction))
+			Expect(globalUnsubscribes.InsertCall.Receives.UserGUID).To(Equal("global-user-guid"))
+
+			Expect(kindUnsubscribes.KindCriticalCall.Receives.ClientID).To(Equal("some-client-id"))
+			Expect(kindUnsubscribes.KindCriticalCall.Receives.KindID).To(Equal("some-kind-id"))
+			Expect(kindUnsubscribes.InsertCall.Receives.Connection).To(Equal(transaction))
+			Expect(kindUnsubscribes.InsertCall.Receives.Unsubscriber).To(Equal(models.KindUnsubscriber{
+				UserGUID: "kind-user-guid",
+				ClientID: "some-client-id",
+				KindID:   "some-kind-id",
+			}))
+
+			Expect(campaignTypesRepository.GetCall.Receives.CampaignTypeID).To(Equal("some-campaign-type-id"))
+			Expect(unsubscribersRepository.InsertCall.Receives.Connection).To(Equal(transaction))
+			Expect(unsubscribersRepository.InsertCall.Receives.Unsubscriber).To(Equal(models.Unsubscriber{
+				CampaignTypeID: "some-campaign-type-id",
+				UserGUID:       "campaign-type-user-guid",
+			}))
+
+			Expect(transaction.BeginCall.WasCalled).To(BeTrue())
+			Expect(transaction.CommitCall.WasCalled).To(BeTrue())
+		})
+
+		It("records each imported unsubscribe", func() {
+			globalUnsubscribes.GetCall.Returns.Unsubscribed = true
+
+			_, err := collection.Import(connection, []collections.BulkUnsubscribe{
+				{UserGUID: "global-user-guid", Global: true},
+				{UserGUID: "kind-user-guid", ClientID: "some-client-id", KindID: "some-kind-id"},
+				{UserGUID: "campaign-type-user-guid", CampaignTypeID: "some-campaign-type-id"},
+			}, actor)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(globalUnsubscribes.GetCall.Receives.UserGUID).To(Equal("global-user-guid"))
+			Expect(kindUnsubscribes.GetCall.Receives.Unsubscriber).To(Equal(models.KindUnsubscriber{
+				UserGUID: "kind-user-guid",
+				ClientID: "some-client-id",
+				KindID:   "some-kind-id",
+			}))
+
+			Expect(preferenceChanges.RecordCall.Receives.Connection).To(Equal(transaction))
+			Expect(preferenceChanges.RecordCall.Receives.Actor).To(Equal(actor))
+			Expect(preferenceChanges.RecordCall.Receives.Changes).To(Equal([]models.PreferenceChange{
+				{
+					UserGUID:   "global-user-guid",
+					Preference: models.PreferenceGlobal,
+					OldValue:   models.Unsubscribed,
+					NewValue:   models.Unsubscribed,
+				},
+				{
+					UserGUID:   "kind-user-guid",
+					Preference: models.PreferenceKind,
+					ClientID:   "some-client-id",
+					TargetID:   "some-kind-id",
+					OldValue:   models.Subscribed,
+					NewValue:   models.Unsubscribed,
+				},
+				{
+					UserGUID:   "campaign-type-user-guid",
+					Preference: models.PreferenceCampaignType,
+					TargetID:   "some-campaign-type-id",
+					OldValue:   models.Subscribed,
+					NewValue:   models.Unsubscribed,
+				},
+			}))
+		})
+
+		It("skips campaign type unsubscribes that already exist", func() {
+			unsubscribersRepository.GetCall.Returns.Error = nil
+
+			result, err := collection.Import(connection, []collections.BulkUnsubscribe{
+				{UserGUID: "some-user-guid", CampaignTypeID: "some-campaign-type-id"},
+			}, actor)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Imported).To(Equal(1))
+			Expect(unsubscribersRepository.InsertCall.CallCount).To(Equal(0))
+			Expect(preferenceChanges.RecordCall.CallCount).To(Equal(0))
+		})
+
+		It("looks up each campaign type once", func() {
+			_, err := collection.Import(connection, []collections.BulkUnsubscribe{
+				{UserGUID: "some-user-guid", CampaignTypeID: "some-campaign-type-id"},
+				{UserGUID: "other-user-guid", CampaignTypeID: "some-campaign-type-id"},
+			}, actor)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(campaignTypesRepository.GetCall.CallCount).To(Equal(1))
+			Expect(unsubscribersRepository.InsertCall.CallCount).To(Equal(2))
+		})
+
+		It("reports the rows that cannot be imported", func() {
+			result, err := collection.Import(connection, []collections.BulkUnsubscribe{
+				{CampaignTypeID: "some-campaign-type-id"},
+				{UserGUID: "some-user-guid"},
+				{UserGUID: "some-user-guid", Global: true, CampaignTypeID: "some-campaign-type-id"},
+				{UserGUID: "some-user-guid", ClientID: "some-client-id"},
+				{UserGUID: "some-user-guid", Global: true},
+			}, actor)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(collections.BulkUnsubscribeResult{
+				Imported: 1,
+				Errors: []collections.BulkUnsubscribeRowError{
+					{Row: 1, Error: "missing user_guid"},
+					{Row: 2, Error: "exactly one of campaign_type_id, client_id and kind_id, or global must be given"},
+					{Row: 3, Error: "exactly one of campaign_type_id, client_id and kind_id, or global must be given"},
+					{Row: 4, Error: "kind unsubscribes need both a client_id and a kind_id"},
+				},
+			}))
+			Expect(transaction.CommitCall.WasCalled).To(BeTrue())
+		})
+
+		It("reports unknown campaign types", func() {
+			campaignTypesRepository.GetCall.Returns.Error = models.RecordNotFoundError{errors.New("not found")}
+
+			result, err := collection.Import(connection, []collections.BulkUnsubscribe{
+				{UserGUID: "some-user-guid", CampaignTypeID: "missing-campaign-type-id"},
+			}, actor)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Errors).To(Equal([]collections.BulkUnsubscribeRowError{
+				{Row: 1, Error: `Campaign type "missing-campaign-type-id" not found`},
+			}))
+		})
+
+		It("reports critical campaign types", func() {
+			campaignTypesRepository.GetCall.Returns.CampaignType.Critical = true
+
+			result, err := collection.Import(connection, []collections.BulkUnsubscribe{
+				{UserGUID: "some-user-guid", CampaignTypeID: "some-campaign-type-id"},
+			}, actor)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Errors).To(Equal([]collections.BulkUnsubscribeRowError{
+				{Row: 1, Error: `Campaign type "some-campaign-type-id" cannot be unsubscribed from`},
+			}))
+			Expect(unsubscribersRepository.InsertCall.CallCount).To(Equal(0))
+		})
+
+		It("reports unknown and critical kinds", func() {
+			kindUnsubscribes.KindCriticalCall.Returns.Critical = true
+
+			result, err := collection.Import(connection, []collections.BulkUnsubscribe{
+				{UserGUID: "some-user-guid", ClientID: "some-client-id", KindID: "some-kind-id"},
+			}, actor)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Errors).To(Equal([]collections.BulkUnsubscribeRowError{
+				{Row: 1, Error: `Kind "some-kind-id" of client "some-client-id" cannot be unsubscribed from`},
+			}))
+
+			kindUnsubscribes.KindCriticalCall.Returns.Error = models.RecordNotFoundError{errors.New("kind not found")}
+
+			result, err = collection.Import(connection, []collections.BulkUnsubscribe{
+				{UserGUID: "some-user-guid", ClientID: "other-client-id", KindID: "some-kind-id"},
+			}, actor)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Errors).To(Equal([]collections.BulkUnsubscribeRowError{
+				{Row: 1, Error: "kind not found"},
+			}))
+			Expect(kindUnsubscribes.InsertCall.CallCount).To(Equal(0))
+		})
+
+		Context("failure cases", func() {
+			It("returns a persistence error and rolls back when an unsubscribe cannot be saved", func() {
+				kindUnsubscribes.InsertCall.Returns.Error = errors.New("db is down")
+
+				_, err := collection.Import(connection, []collections.BulkUnsubscribe{
+					{UserGUID: "some-user-guid", ClientID: "some-client-id", KindID: "some-kind-id"},
+				}, actor)
+				Expect(err).To(MatchError(collections.PersistenceError{errors.New("db is down")}))
+				Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+				Expect(transaction.CommitCall.WasCalled).To(BeFalse())
+			})
+
+			It("returns a persistence error when the campaign type lookup fails", func() {
+				campaignTypesRepository.GetCall.Returns.Error = errors.New("db is down")
+
+				_, err := collection.Import(connection, []collections.BulkUnsubscribe{
+					{UserGUID: "some-user-guid", CampaignTypeID: "some-campaign-type-id"},
+				}, actor)
+				Expect(err).To(MatchError(collections.PersistenceError{errors.New("db is down")}))
+				Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+			})
+
+			It("returns a persistence error and rolls back when a change cannot be recorded", func() {
+				preferenceChanges.RecordCall.Returns.Error = errors.New("db is down")
+
+				_, err := collection.Import(connection, []collections.BulkUnsubscribe{
+					{UserGUID: "some-user-guid", Global: true},
+				}, actor)
+				Expect(err).To(MatchError(collections.PersistenceError{errors.New("db is down")}))
+				Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+			})
+
+			It("returns a persistence error when the transaction cannot be committed", func() {
+				transaction.CommitCall.Returns.Error = errors.New("commit failed")
+
+				_, err := collection.Import(connection, []collections.BulkUnsubscribe{}, actor)
+				Expect(err).To(MatchError(collections.PersistenceError{errors.New("commit failed")}))
+			})
+		})
+	})
+
+	Describe("Export", func() {
+		It("lists a page of unsubscribes", func() {
+			bulkUnsubscribes.ListCall.Returns.Unsubscribers = []models.BulkUnsubscriber{
+				{UserGUID: "global-user-guid", Global: true},
+				{UserGUID: "kind-user-guid", ClientID: "some-client-id", KindID: "some-kind-id"},
+				{UserGUID: "campaign-type-user-guid", CampaignTypeID: "some-campaign-type-id"},
+			}
+
+			unsubscribes, err := collection.Export(connection, 20, 10)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(unsubscribes).To(Equal([]collections.BulkUnsubscribe{
+				{UserGUID: "global-user-guid", Global: true},
+				{UserGUID: "kind-user-guid", ClientID: "some-client-id", KindID: "some-kind-id"},
+				{UserGUID: "campaign-type-user-guid", CampaignTypeID: "some-campaign-type-id"},
+			}))
+
+			Expect(bulkUnsubscribes.ListCall.Receives.Connection).To(Equal(connection))
+			Expect(bulkUnsubscribes.ListCall.Receives.Offset).To(Equal(20))
+			Expect(bulkUnsubscribes.ListCall.Receives.Limit).To(Equal(10))
+		})
+
+		It("returns a persistence error when the unsubscribes cannot be read", func() {
+			bulkUnsubscribes.ListCall.Returns.Error = errors.New("db is down")
+
+			_, err := collection.Export(connection, 0, 10)
+			Expect(err).To(MatchError(collections.PersistenceError{errors.New("db is down")}))
+		})
+	})
+})
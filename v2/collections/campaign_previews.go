@@ -0,0 +1,167 @@
+package collections
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/cloudfoundry-incubator/notifications/uaa"
+	"github.com/cloudfoundry-incubator/notifications/v2/horde"
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+	"github.com/pivotal-golang/lager"
+)
+
+const (
+	previewSampleSize = 10
+
+	// previewUnsubscribeChecks bounds the unsubscribe lookups made for one
+	// preview. Larger audiences are checked at evenly spaced recipients and
+	// the number of unsubscribed recipients is estimated from them.
+	previewUnsubscribeChecks = 500
+)
+
+type unsubscribeFilter interface {
+	Unsubscribed(conn models.ConnectionInterface, userGUID, email, clientID, campaignTypeID string) (bool, error)
+}
+
+type PreviewRecipient struct {
+	GUID  string
+	Email string
+}
+
+type CampaignPreview struct {
+	TotalRecipients        int
+	Audiences              map[string]int
+	ExcludedRecipients     int
+	UnsubscribedRecipients int
+	UnsubscribesEstimated  bool
+	Sample                 []PreviewRecipient
+}
+
+type CampaignPreviewsCollection struct {
+	generators        horde.Generators
+	sendersRepo       sendersGetter
+	campaignTypesRepo campaignTypesGetter
+	unsubscribes      unsubscribeFilter
+}
+
+func NewCampaignPreviewsCollection(generators horde.Generators, sendersRepo sendersGetter, campaignTypesRepo campaignTypesGetter, unsubscribes unsubscribeFilter) CampaignPreviewsCollection {
+	return CampaignPreviewsCollection{
+		generators:        generators,
+		sendersRepo:       sendersRepo,
+		campaignTypesRepo: campaignTypesRepo,
+		unsubscribes:      unsubscribes,
+	}
+}
+
+// Preview resolves the campaign audiences with the generators and filter used
+// by the campaign job processor and applies the unsubscribe filtering done at
+// delivery time, without persisting or enqueueing anything.
+func (c CampaignPreviewsCollection) Preview(conn ConnectionInterface, campaign Campaign, clientID string, logger lager.Logger) (CampaignPreview, error) {
+	sender, err := c.sendersRepo.Get(conn, campaign.SenderID)
+	if err != nil {
+		switch err.(type) {
+		case models.RecordNotFoundError:
+			return CampaignPreview{}, NotFoundError{err}
+		default:
+			return CampaignPreview{}, UnknownError{err}
+		}
+	}
+
+	if sender.ClientID != clientID {
+		return CampaignPreview{}, NotFoundError{fmt.Errorf("Sender with id %q could not be found", campaign.SenderID)}
+	}
+
+	_, err = c.campaignTypesRepo.Get(conn, campaign.CampaignTypeID)
+	if err != nil {
+		switch err.(type) {
+		case models.RecordNotFoundError:
+			return CampaignPreview{}, NotFoundError{err}
+		default:
+			return CampaignPreview{}, PersistenceError{err}
+		}
+	}
+
+	preview := CampaignPreview{
+		Audiences: map[string]int{},
+		Sample:    []PreviewRecipient{},
+	}
+
+	generators := c.generators.ForSender(campaign.SenderID)
+
+	recipients := map[string]horde.Recipient{}
+	for audience, members := range campaign.SendTo {
+		users, err := generators.Recipients(map[string][]string{audience: members}, logger)
+		if err != nil {
+			return CampaignPreview{}, generatorError(err)
+		}
+
+		preview.Audiences[audience] = len(users)
+		for userKey, user := range users {
+			recipients[userKey] = user
+		}
+	}
+
+	filter, err := generators.Filter(campaign.Intersect, campaign.Exclude, logger)
+	if err != nil {
+		return CampaignPreview{}, generatorError(err)
+	}
+
+	var keys []string
+	for userKey := range recipients {
+		if filter.Excludes(userKey) {
+			preview.ExcludedRecipients++
+			continue
+		}
+
+		keys = append(keys, userKey)
+	}
+	sort.Strings(keys)
+
+	step := 1
+	if len(keys) > previewUnsubscribeChecks {
+		step = (len(keys) + previewUnsubscribeChecks - 1) / previewUnsubscribeChecks
+	}
+
+	var checked, unsubscribed int
+	for i := 0; i < len(keys); i += step {
+		user := recipients[keys[i]].User
+
+		isUnsubscribed, err := c.unsubscribes.Unsubscribed(conn, user.GUID, user.Email, clientID, campaign.CampaignTypeID)
+		if err != nil {
+			return CampaignPreview{}, PersistenceError{err}
+		}
+
+		checked++
+		if isUnsubscribed {
+			unsubscribed++
+			continue
+		}
+
+		if len(preview.Sample) < previewSampleSize {
+			preview.Sample = append(preview.Sample, PreviewRecipient{
+				GUID:  user.GUID,
+				Email: user.Email,
+			})
+		}
+	}
+
+	preview.UnsubscribedRecipients = unsubscribed
+	if step > 1 {
+		preview.UnsubscribedRecipients = (unsubscribed*len(keys) + checked/2) / checked
+		preview.UnsubscribesEstimated = true
+	}
+	preview.TotalRecipients = len(keys) - preview.UnsubscribedRecipients
+
+	return preview, nil
+}
+
+func generatorError(err error) error {
+	switch e := err.(type) {
+	case horde.UnknownAudienceError:
+		return ValidationError{fmt.Errorf("The %q audience is not valid", e.Audience)}
+	case uaa.GroupNotFoundError, models.RecordNotFoundError:
+		return NotFoundError{err}
+	default:
+		return UnknownError{err}
+	}
+}
@@ -0,0 +1,287 @@
+package collections_test
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/uaa"
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/cloudfoundry-incubator/notifications/v2/horde"
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+	"github.com/pivotal-golang/lager"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CampaignPreviewsCollection", func() {
+	var (
+		conn                   *mocks.Connection
+		logger                 lager.Logger
+		collection             collections.CampaignPreviewsCollection
+		orgs                   *mocks.Audiences
+		users                  *mocks.Audiences
+		emails                 *mocks.Audiences
+		sendersRepo            *mocks.SendersRepository
+		campaignTypesRepo      *mocks.CampaignTypesRepository
+		unsubscribersRepo      *mocks.UnsubscribersRepository
+		globalUnsubscribesRepo *mocks.GlobalUnsubscribesRepository
+		emailUnsubscribesRepo  *mocks.EmailUnsubscribesRepository
+		campaign               collections.Campaign
+	)
+
+	BeforeEach(func() {
+		conn = mocks.NewConnection()
+		logger = lager.NewLogger("notifications")
+
+		orgs = mocks.NewAudiences()
+		orgs.GenerateAudiencesCall.Returns.Audiences = []horde.Audience{
+			{
+				Users: []horde.User{
+					{GUID: "user-3"},
+					{GUID: "user-1"},
+					{GUID: "user-2"},
+				},
+				Endorsement: "You are in the org.",
+			},
+		}
+
+		users = mocks.NewAudiences()
+		users.GenerateAudiencesCall.Returns.Audiences = []horde.Audience{
+			{Users: []horde.User{{GUID: "user-2"}}},
+		}
+
+		emails = mocks.NewAudiences()
+		emails.GenerateAudiencesCall.Returns.Audiences = []horde.Audience{
+			{Users: []horde.User{{Email: "someone@example.com"}}},
+		}
+
+		sendersRepo = mocks.NewSendersRepository()
+		sendersRepo.GetCall.Returns.Sender = models.Sender{
+			ID:       "some-sender-id",
+			ClientID: "some-client-id",
+		}
+
+		campaignTypesRepo = mocks.NewCampaignTypesRepository()
+		campaignTypesRepo.GetCall.Returns.CampaignType = models.CampaignType{
+			ID: "some-campaign-type-id",
+		}
+
+		unsubscribersRepo = mocks.NewUnsubscribersRepository()
+		unsubscribersRepo.GetCall.Returns.Error = models.RecordNotFoundError{errors.New("not found")}
+		globalUnsubscribesRepo = mocks.NewGlobalUnsubscribesRepository()
+		emailUnsubscribesRepo = mocks.NewEmailUnsubscribesRepository()
+
+		collection = collections.NewCampaignPreviewsCollection(horde.Generators{
+			"orgs":   orgs,
+			"users":  users,
+			"emails": emails,
+		}, sendersRepo, campaignTypesRepo, collections.NewUnsubscribeFilter(unsubscribersRepo, globalUnsubscribesRepo, emailUnsubscribesRepo, campaignTypesRepo))
+
+		campaign = collections.Campaign{
+			SendTo: map[string][]string{
+				"orgs":   {"some-org-guid"},
+				"emails": {"someone@example.com"},
+			},
+			CampaignTypeID: "some-campaign-type-id",
+			SenderID:       "some-sender-id",
+		}
+	})
+
+	It("counts and samples the recipients of the campaign", func() {
+		preview, err := collection.Preview(conn, campaign, "some-client-id", logger)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(preview).To(Equal(collections.CampaignPreview{
+			TotalRecipients: 4,
+			Audiences: map[string]int{
+				"orgs":   3,
+				"emails": 1,
+			},
+			Sample: []collections.PreviewRecipient{
+				{Email: "someone@example.com"},
+				{GUID: "user-1"},
+				{GUID: "user-2"},
+				{GUID: "user-3"},
+			},
+		}))
+
+		Expect(orgs.GenerateAudiencesCall.Receives.Inputs).To(Equal([]string{"some-org-guid"}))
+		Expect(orgs.GenerateAudiencesCall.Receives.Logger).To(Equal(logger))
+		Expect(emails.GenerateAudiencesCall.Receives.Inputs).To(Equal([]string{"someone@example.com"}))
+
+		Expect(sendersRepo.GetCall.Receives.SenderID).To(Equal("some-sender-id"))
+		Expect(campaignTypesRepo.GetCall.Receives.CampaignTypeID).To(Equal("some-campaign-type-id"))
+		Expect(emailUnsubscribesRepo.GetCall.Receives.Email).To(Equal("someone@example.com"))
+		Expect(emailUnsubscribesRepo.GetCall.Receives.ClientID).To(Equal("some-client-id"))
+		Expect(emailUnsubscribesRepo.GetCall.Receives.CampaignTypeID).To(Equal("some-campaign-type-id"))
+	})
+
+	It("counts recipients removed by exclude and intersect blocks", func() {
+		campaign.SendTo = map[string][]string{"orgs": {"some-org-guid"}}
+		campaign.Exclude = map[string][]string{"users": {"user-2"}}
+
+		preview, err := collection.Preview(conn, campaign, "some-client-id", logger)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(preview.TotalRecipients).To(Equal(2))
+		Expect(preview.ExcludedRecipients).To(Equal(1))
+		Expect(preview.Sample).To(Equal([]collections.PreviewRecipient{{GUID: "user-1"}, {GUID: "user-3"}}))
+
+		campaign.Exclude = nil
+		campaign.Intersect = map[string][]string{"users": {"user-2"}}
+
+		preview, err = collection.Preview(conn, campaign, "some-client-id", logger)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(preview.TotalRecipients).To(Equal(1))
+		Expect(preview.ExcludedRecipients).To(Equal(2))
+		Expect(preview.Sample).To(Equal([]collections.PreviewRecipient{{GUID: "user-2"}}))
+	})
+
+	It("only samples the first ten recipients", func() {
+		var audienceUsers []horde.User
+		for _, guid := range []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k", "l"} {
+			audienceUsers = append(audienceUsers, horde.User{GUID: guid})
+		}
+		orgs.GenerateAudiencesCall.Returns.Audiences = []horde.Audience{{Users: audienceUsers}}
+		campaign.SendTo = map[string][]string{"orgs": {"some-org-guid"}}
+
+		preview, err := collection.Preview(conn, campaign, "some-client-id", logger)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(preview.TotalRecipients).To(Equal(12))
+		Expect(preview.Sample).To(HaveLen(10))
+		Expect(preview.Sample[9]).To(Equal(collections.PreviewRecipient{GUID: "j"}))
+	})
+
+	It("estimates unsubscribes from evenly spaced recipients of large audiences", func() {
+		var audienceUsers []horde.User
+		for i := 0; i < 1200; i++ {
+			audienceUsers = append(audienceUsers, horde.User{GUID: fmt.Sprintf("user-%04d", i)})
+		}
+		orgs.GenerateAudiencesCall.Returns.Audiences = []horde.Audience{{Users: audienceUsers}}
+		campaign.SendTo = map[string][]string{"orgs": {"some-org-guid"}}
+
+		preview, err := collection.Preview(conn, campaign, "some-client-id", logger)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(preview.TotalRecipients).To(Equal(1200))
+		Expect(preview.UnsubscribedRecipients).To(Equal(0))
+		Expect(preview.UnsubscribesEstimated).To(BeTrue())
+		Expect(preview.Sample).To(HaveLen(10))
+		Expect(preview.Sample[1]).To(Equal(collections.PreviewRecipient{GUID: "user-0003"}))
+		Expect(globalUnsubscribesRepo.GetCall.CallCount).To(Equal(400))
+
+		globalUnsubscribesRepo.GetCall.Returns.Unsubscribed = true
+
+		preview, err = collection.Preview(conn, campaign, "some-client-id", logger)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(preview.TotalRecipients).To(Equal(0))
+		Expect(preview.UnsubscribedRecipients).To(Equal(1200))
+	})
+
+	Context("when recipients have unsubscribed", func() {
+		BeforeEach(func() {
+			campaign.SendTo = map[string][]string{"users": {"user-2"}}
+		})
+
+		It("skips recipients unsubscribed from the campaign type", func() {
+			unsubscribersRepo.GetCall.Returns.Unsubscriber = models.Unsubscriber{ID: "some-unsubscriber-id"}
+			unsubscribersRepo.GetCall.Returns.Error = nil
+
+			preview, err := collection.Preview(conn, campaign, "some-client-id", logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(preview.TotalRecipients).To(Equal(0))
+			Expect(preview.UnsubscribedRecipients).To(Equal(1))
+			Expect(preview.Sample).To(BeEmpty())
+
+			Expect(unsubscribersRepo.GetCall.Receives.UserGUID).To(Equal("user-2"))
+			Expect(unsubscribersRepo.GetCall.Receives.CampaignTypeID).To(Equal("some-campaign-type-id"))
+		})
+
+		It("skips recipients that have globally unsubscribed", func() {
+			globalUnsubscribesRepo.GetCall.Returns.Unsubscribed = true
+
+			preview, err := collection.Preview(conn, campaign, "some-client-id", logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(preview.TotalRecipients).To(Equal(0))
+			Expect(preview.UnsubscribedRecipients).To(Equal(1))
+			Expect(globalUnsubscribesRepo.GetCall.Receives.UserGUID).To(Equal("user-2"))
+		})
+
+		It("skips email recipients that have unsubscribed", func() {
+			emailUnsubscribesRepo.GetCall.Returns.Unsubscribed = true
+			campaign.SendTo = map[string][]string{"emails": {"someone@example.com"}}
+
+			preview, err := collection.Preview(conn, campaign, "some-client-id", logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(preview.TotalRecipients).To(Equal(0))
+			Expect(preview.UnsubscribedRecipients).To(Equal(1))
+		})
+
+		It("still counts global and email unsubscribers for critical campaign types", func() {
+			campaignTypesRepo.GetCall.Returns.CampaignType.Critical = true
+			globalUnsubscribesRepo.GetCall.Returns.Unsubscribed = true
+
+			preview, err := collection.Preview(conn, campaign, "some-client-id", logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(preview.TotalRecipients).To(Equal(1))
+			Expect(preview.UnsubscribedRecipients).To(Equal(0))
+		})
+	})
+
+	Context("failure cases", func() {
+		It("returns a not found error when the sender does not exist", func() {
+			sendersRepo.GetCall.Returns.Error = models.RecordNotFoundError{errors.New("sender not found")}
+
+			_, err := collection.Preview(conn, campaign, "some-client-id", logger)
+			Expect(err).To(MatchError(collections.NotFoundError{models.RecordNotFoundError{errors.New("sender not found")}}))
+		})
+
+		It("returns a not found error when the sender belongs to another client", func() {
+			_, err := collection.Preview(conn, campaign, "other-client-id", logger)
+			Expect(err).To(MatchError(collections.NotFoundError{errors.New(`Sender with id "some-sender-id" could not be found`)}))
+		})
+
+		It("returns a not found error when the campaign type does not exist", func() {
+			campaignTypesRepo.GetCall.Returns.Error = models.RecordNotFoundError{errors.New("campaign type not found")}
+
+			_, err := collection.Preview(conn, campaign, "some-client-id", logger)
+			Expect(err).To(MatchError(collections.NotFoundError{models.RecordNotFoundError{errors.New("campaign type not found")}}))
+		})
+
+		It("returns a validation error when the audience has no generator", func() {
+			campaign.SendTo = map[string][]string{"bananas": {"banana-1"}}
+
+			_, err := collection.Preview(conn, campaign, "some-client-id", logger)
+			Expect(err).To(MatchError(collections.ValidationError{errors.New(`The "bananas" audience is not valid`)}))
+		})
+
+		It("returns an unknown error when an audience cannot be generated", func() {
+			orgs.GenerateAudiencesCall.Returns.Error = errors.New("cc is down")
+
+			_, err := collection.Preview(conn, campaign, "some-client-id", logger)
+			Expect(err).To(MatchError(collections.UnknownError{errors.New("cc is down")}))
+		})
+
+		It("returns a not found error when a UAA group cannot be found", func() {
+			orgs.GenerateAudiencesCall.Returns.Error = uaa.GroupNotFoundError{Name: "security-oncall"}
+
+			_, err := collection.Preview(conn, campaign, "some-client-id", logger)
+			Expect(err).To(MatchError(collections.NotFoundError{uaa.GroupNotFoundError{Name: "security-oncall"}}))
+		})
+
+		It("returns a not found error when a saved audience cannot be found", func() {
+			orgs.GenerateAudiencesCall.Returns.Error = models.RecordNotFoundError{errors.New("Audience \"some-audience-id\" could not be found")}
+
+			_, err := collection.Preview(conn, campaign, "some-client-id", logger)
+			Expect(err).To(MatchError(collections.NotFoundError{models.RecordNotFoundError{errors.New("Audience \"some-audience-id\" could not be found")}}))
+		})
+
+		It("returns a persistence error when unsubscribes cannot be read", func() {
+			globalUnsubscribesRepo.GetCall.Returns.Error = errors.New("db is down")
+			campaign.SendTo = map[string][]string{"users": {"user-2"}}
+
+			_, err := collection.Preview(conn, campaign, "some-client-id", logger)
+			Expect(err).To(MatchError(collections.PersistenceError{errors.New("db is down")}))
+		})
+	})
+})
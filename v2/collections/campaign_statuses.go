@@ -25,6 +25,16 @@ type messageCountGetter interface {
 	MostRecentlyUpdatedByCampaignID(conn models.ConnectionInterface, campaignID string) (models.Message, error)
 }
 
+type messageTimingsGetter interface {
+	PercentilesByCampaignID(conn models.ConnectionInterface, campaignID string) (models.MessageTimingPercentiles, error)
+}
+
+// DurationPercentiles are in seconds.
+type DurationPercentiles struct {
+	P50 float64
+	P95 float64
+}
+
 type CampaignStatus struct {
 	CampaignID            string
 	Status                string
@@ -34,21 +44,27 @@ type CampaignStatus struct {
 	RetryMessages         int
 	FailedMessages        int
 	UndeliverableMessages int
+	ExcludedRecipients    int
 	StartTime             time.Time
 	CompletedTime         *time.Time
+	QueueWait             *DurationPercentiles
+	SendDuration          *DurationPercentiles
+	FanoutDuration        *float64
 }
 
 type CampaignStatusesCollection struct {
 	campaignsRepository campaignGetter
 	sendersRepository   senderGetter
 	messages            messageCountGetter
+	messageTimings      messageTimingsGetter
 }
 
-func NewCampaignStatusesCollection(campaignsRepository campaignGetter, sendersRepository senderGetter, messages messageCountGetter) CampaignStatusesCollection {
+func NewCampaignStatusesCollection(campaignsRepository campaignGetter, sendersRepository senderGetter, messages messageCountGetter, messageTimings messageTimingsGetter) CampaignStatusesCollection {
 	return CampaignStatusesCollection{
 		campaignsRepository: campaignsRepository,
 		sendersRepository:   sendersRepository,
 		messages:            messages,
+		messageTimings:      messageTimings,
 	}
 }
 
@@ -96,6 +112,23 @@ func (csc CampaignStatusesCollection) Get(conn ConnectionInterface, campaignID,
 		completedTime = &mostRecentlyUpdatedMessage.UpdatedAt
 	}
 
+	timings, err := csc.messageTimings.PercentilesByCampaignID(conn, campaign.ID)
+	if err != nil {
+		return CampaignStatus{}, UnknownError{err}
+	}
+
+	var queueWait, sendDuration *DurationPercentiles
+	if timings.Count > 0 {
+		queueWait = &DurationPercentiles{P50: timings.QueueWait.P50, P95: timings.QueueWait.P95}
+		sendDuration = &DurationPercentiles{P50: timings.SendDuration.P50, P95: timings.SendDuration.P95}
+	}
+
+	var fanoutDuration *float64
+	if campaign.FanoutStartedAt.Valid && campaign.FanoutCompletedAt.Valid {
+		seconds := campaign.FanoutCompletedAt.Time.Sub(campaign.FanoutStartedAt.Time).Seconds()
+		fanoutDuration = &seconds
+	}
+
 	return CampaignStatus{
 		CampaignID:            campaign.ID,
 		Status:                status,
@@ -105,8 +138,12 @@ func (csc CampaignStatusesCollection) Get(conn ConnectionInterface, campaignID,
 		RetryMessages:         counts.Retry,
 		QueuedMessages:        counts.Queued,
 		UndeliverableMessages: counts.Undeliverable,
+		ExcludedRecipients:    campaign.ExcludedCount,
 		StartTime:             campaign.StartTime,
 		CompletedTime:         completedTime,
+		QueueWait:             queueWait,
+		SendDuration:          sendDuration,
+		FanoutDuration:        fanoutDuration,
 	}, nil
 }
 
@@ -7,6 +7,7 @@ import (
 	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
 	"github.com/cloudfoundry-incubator/notifications/v2/collections"
 	"github.com/cloudfoundry-incubator/notifications/v2/models"
+	"github.com/go-sql-driver/mysql"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -17,6 +18,7 @@ var _ = Describe("CampaignStatusesCollection", func() {
 		campaignsRepository        *mocks.CampaignsRepository
 		sendersRepository          *mocks.SendersRepository
 		messagesRepository         *mocks.MessagesRepository
+		messageTimingsRepository   *mocks.MessageTimingsRepository
 		conn                       *mocks.Connection
 		campaignStatusesCollection collections.CampaignStatusesCollection
 	)
@@ -25,9 +27,10 @@ var _ = Describe("CampaignStatusesCollection", func() {
 		campaignsRepository = mocks.NewCampaignsRepository()
 		sendersRepository = mocks.NewSendersRepository()
 		messagesRepository = mocks.NewMessagesRepository()
+		messageTimingsRepository = mocks.NewMessageTimingsRepository()
 		conn = mocks.NewConnection()
 
-		campaignStatusesCollection = collections.NewCampaignStatusesCollection(campaignsRepository, sendersRepository, messagesRepository)
+		campaignStatusesCollection = collections.NewCampaignStatusesCollection(campaignsRepository, sendersRepository, messagesRepository, messageTimingsRepository)
 	})
 
 	Context("when a valid campaign is queried", func() {
@@ -99,6 +102,43 @@ var _ = Describe("CampaignStatusesCollection", func() {
 			Expect(sendersRepository.GetCall.Receives.SenderID).To(Equal("sender-id"))
 		})
 
+		It("reports how many recipients were excluded from the campaign", func() {
+			campaignsRepository.GetCall.Returns.Campaign.ExcludedCount = 3
+
+			campaignStatus, err := campaignStatusesCollection.Get(conn, "campaign-id", "client-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(campaignStatus.ExcludedRecipients).To(Equal(3))
+		})
+
+		It("reports the delivery timings of the campaign", func() {
+			messageTimingsRepository.PercentilesByCampaignIDCall.Returns.Percentiles = models.MessageTimingPercentiles{
+				Count:        20,
+				QueueWait:    models.Percentiles{P50: 1.5, P95: 4},
+				SendDuration: models.Percentiles{P50: 0.25, P95: 0.75},
+			}
+			campaignsRepository.GetCall.Returns.Campaign.FanoutStartedAt = mysql.NullTime{Time: startTime, Valid: true}
+			campaignsRepository.GetCall.Returns.Campaign.FanoutCompletedAt = mysql.NullTime{Time: startTime.Add(90 * time.Second), Valid: true}
+
+			campaignStatus, err := campaignStatusesCollection.Get(conn, "campaign-id", "client-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(campaignStatus.QueueWait).To(Equal(&collections.DurationPercentiles{P50: 1.5, P95: 4}))
+			Expect(campaignStatus.SendDuration).To(Equal(&collections.DurationPercentiles{P50: 0.25, P95: 0.75}))
+			Expect(*campaignStatus.FanoutDuration).To(Equal(90.0))
+
+			Expect(messageTimingsRepository.PercentilesByCampaignIDCall.Receives.Connection).To(Equal(conn))
+			Expect(messageTimingsRepository.PercentilesByCampaignIDCall.Receives.CampaignID).To(Equal("campaign-id"))
+		})
+
+		It("does not report timings that have not been recorded yet", func() {
+			campaignsRepository.GetCall.Returns.Campaign.FanoutStartedAt = mysql.NullTime{Time: startTime, Valid: true}
+
+			campaignStatus, err := campaignStatusesCollection.Get(conn, "campaign-id", "client-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(campaignStatus.QueueWait).To(BeNil())
+			Expect(campaignStatus.SendDuration).To(BeNil())
+			Expect(campaignStatus.FanoutDuration).To(BeNil())
+		})
+
 		Context("when the campaign is not yet completed", func() {
 			It("returns a transient status", func() {
 				messagesRepository.CountByStatusCall.Returns.MessageCounts = models.MessageCounts{
@@ -213,6 +253,13 @@ var _ = Describe("CampaignStatusesCollection", func() {
 				_, err := campaignStatusesCollection.Get(conn, "campaign-id", "client-id")
 				Expect(err).To(MatchError(collections.UnknownError{errors.New("db went away")}))
 			})
+
+			It("returns an error when the message timings cannot be loaded", func() {
+				messageTimingsRepository.PercentilesByCampaignIDCall.Returns.Error = errors.New("db went away")
+
+				_, err := campaignStatusesCollection.Get(conn, "campaign-id", "client-id")
+				Expect(err).To(MatchError(collections.UnknownError{errors.New("db went away")}))
+			})
 		})
 	})
 })
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/cloudfoundry-incubator/notifications/uaa"
 	"github.com/cloudfoundry-incubator/notifications/v2/models"
 )
 
@@ -27,22 +28,36 @@ type templatesGetter interface {
 	Get(conn models.ConnectionInterface, templateID string) (models.Template, error)
 }
 
+type audiencesGetter interface {
+	Get(conn models.ConnectionInterface, audienceID string) (models.Audience, error)
+}
+
 type sendersGetter interface {
 	Get(conn models.ConnectionInterface, senderID string) (models.Sender, error)
 }
 
+type groupMembersFinder interface {
+	Members(groupName string) ([]string, error)
+}
+
 type Campaign struct {
 	ID             string
 	SendTo         map[string][]string
+	Exclude        map[string][]string
+	Intersect      map[string][]string
 	CampaignTypeID string
 	Text           string
 	HTML           string
+	Markdown       string
 	Subject        string
 	TemplateID     string
 	ReplyTo        string
 	SenderID       string
 	ClientID       string
 	StartTime      time.Time
+	BusinessHours  bool
+
+	RequestReceived time.Time
 }
 
 type CampaignsCollection struct {
@@ -51,29 +66,29 @@ type CampaignsCollection struct {
 	campaignTypesRepo campaignTypesGetter
 	templatesRepo     templatesGetter
 	sendersRepo       sendersGetter
+	audiencesRepo     audiencesGetter
+	groups            groupMembersFinder
+	defaultScopes     []string
 }
 
-func NewCampaignsCollection(enqueuer campaignEnqueuer, campaignsRepo campaignsPersister, campaignTypesRepo campaignTypesGetter, templatesRepo templatesGetter, sendersRepo sendersGetter) CampaignsCollection {
+func NewCampaignsCollection(enqueuer campaignEnqueuer, campaignsRepo campaignsPersister, campaignTypesRepo campaignTypesGetter, templatesRepo templatesGetter, sendersRepo sendersGetter, audiencesRepo audiencesGetter, groups groupMembersFinder, defaultScopes []string) CampaignsCollection {
 	return CampaignsCollection{
 		enqueuer:          enqueuer,
 		campaignsRepo:     campaignsRepo,
 		campaignTypesRepo: campaignTypesRepo,
 		templatesRepo:     templatesRepo,
 		sendersRepo:       sendersRepo,
+		audiencesRepo:     audiencesRepo,
+		groups:            groups,
+		defaultScopes:     defaultScopes,
 	}
 }
 
-func (c CampaignsCollection) Create(conn ConnectionInterface, campaign Campaign, clientID string, canSendCritical bool) (Campaign, error) {
-	for audience, audienceMembers := range campaign.SendTo {
-		for _, audienceMember := range audienceMembers {
-			exists, err := c.checkForExistence(audience, audienceMember)
-			if err != nil {
-				return Campaign{}, UnknownError{err}
-			}
-
-			if !exists {
-				return Campaign{}, NotFoundError{fmt.Errorf("The %s %q cannot be found", strings.TrimSuffix(audience, "s"), audienceMember)}
-			}
+func (c CampaignsCollection) Validate(conn ConnectionInterface, campaign Campaign, clientID string, canSendCritical bool) (Campaign, error) {
+	for _, audiences := range []map[string][]string{campaign.SendTo, campaign.Exclude, campaign.Intersect} {
+		err := c.checkAudiences(audiences)
+		if err != nil {
+			return Campaign{}, err
 		}
 	}
 
@@ -91,6 +106,21 @@ func (c CampaignsCollection) Create(conn ConnectionInterface, campaign Campaign,
 		return Campaign{}, NotFoundError{fmt.Errorf("Sender with id %q could not be found", campaign.SenderID)}
 	}
 
+	campaign.SendTo, err = c.pinSavedAudiences(conn, campaign.SendTo, campaign.SenderID)
+	if err != nil {
+		return Campaign{}, err
+	}
+
+	campaign.Exclude, err = c.pinSavedAudiences(conn, campaign.Exclude, campaign.SenderID)
+	if err != nil {
+		return Campaign{}, err
+	}
+
+	campaign.Intersect, err = c.pinSavedAudiences(conn, campaign.Intersect, campaign.SenderID)
+	if err != nil {
+		return Campaign{}, err
+	}
+
 	campaignType, err := c.campaignTypesRepo.Get(conn, campaign.CampaignTypeID)
 	if err != nil {
 		switch err.(type) {
@@ -123,21 +153,44 @@ func (c CampaignsCollection) Create(conn ConnectionInterface, campaign Campaign,
 		}
 	}
 
+	return campaign, nil
+}
+
+func (c CampaignsCollection) Create(conn ConnectionInterface, campaign Campaign, clientID string, canSendCritical bool) (Campaign, error) {
+	campaign, err := c.Validate(conn, campaign, clientID, canSendCritical)
+	if err != nil {
+		return Campaign{}, err
+	}
+
 	sendTo, err := json.Marshal(campaign.SendTo)
 	if err != nil {
 		panic(err)
 	}
 
+	exclude, err := marshalAudiences(campaign.Exclude)
+	if err != nil {
+		panic(err)
+	}
+
+	intersect, err := marshalAudiences(campaign.Intersect)
+	if err != nil {
+		panic(err)
+	}
+
 	campaignModel, err := c.campaignsRepo.Insert(conn, models.Campaign{
 		SendTo:         string(sendTo),
 		CampaignTypeID: campaign.CampaignTypeID,
 		Text:           campaign.Text,
 		HTML:           campaign.HTML,
+		Markdown:       campaign.Markdown,
 		Subject:        campaign.Subject,
 		TemplateID:     campaign.TemplateID,
 		ReplyTo:        campaign.ReplyTo,
 		SenderID:       campaign.SenderID,
 		StartTime:      campaign.StartTime,
+		BusinessHours:  campaign.BusinessHours,
+		Exclude:        exclude,
+		Intersect:      intersect,
 	})
 	if err != nil {
 		return Campaign{}, PersistenceError{err}
@@ -154,6 +207,27 @@ func (c CampaignsCollection) Create(conn ConnectionInterface, campaign Campaign,
 	return campaign, nil
 }
 
+func (c CampaignsCollection) checkAudiences(audiences map[string][]string) error {
+	for audience, audienceMembers := range audiences {
+		for _, audienceMember := range audienceMembers {
+			if audience == "uaa_scopes" && c.isDefaultScope(audienceMember) {
+				return ValidationError{fmt.Errorf("The %q scope is a default scope and cannot be sent to", audienceMember)}
+			}
+
+			exists, err := c.checkForExistence(audience, audienceMember)
+			if err != nil {
+				return UnknownError{err}
+			}
+
+			if !exists {
+				return NotFoundError{fmt.Errorf("The %s %q cannot be found", strings.TrimSuffix(audience, "s"), audienceMember)}
+			}
+		}
+	}
+
+	return nil
+}
+
 func (c CampaignsCollection) checkForExistence(audience, guid string) (bool, error) {
 	switch audience {
 	case "users":
@@ -164,11 +238,77 @@ func (c CampaignsCollection) checkForExistence(audience, guid string) (bool, err
 		return true, nil
 	case "emails":
 		return true, nil
+	case "audiences":
+		return true, nil
+	case "groups":
+		_, err := c.groups.Members(guid)
+		if err != nil {
+			if _, ok := err.(uaa.GroupNotFoundError); ok {
+				return false, nil
+			}
+
+			return false, err
+		}
+
+		return true, nil
+	case "uaa_scopes", "everyone", "org_managers", "org_auditors", "billing_managers":
+		return true, nil
+	case "space_managers", "space_developers", "space_auditors":
+		return true, nil
 	default:
 		return false, fmt.Errorf("The %q audience is not valid", audience)
 	}
 }
 
+// pinSavedAudiences replaces each saved audience ID with a reference to its
+// current version, so that later edits to the audience do not change who the
+// campaign is sent to. Inputs that are already pinned are kept as they are.
+func (c CampaignsCollection) pinSavedAudiences(conn ConnectionInterface, audiences map[string][]string, senderID string) (map[string][]string, error) {
+	references, ok := audiences["audiences"]
+	if !ok {
+		return audiences, nil
+	}
+
+	pinned := map[string][]string{}
+	for audience, audienceMembers := range audiences {
+		pinned[audience] = audienceMembers
+	}
+
+	pinned["audiences"] = []string{}
+	for _, reference := range references {
+		audienceID, version := models.ParseAudienceReference(reference)
+
+		audience, err := c.audiencesRepo.Get(conn, audienceID)
+		if err != nil {
+			if _, ok := err.(models.RecordNotFoundError); !ok {
+				return nil, PersistenceError{err}
+			}
+		}
+
+		if err != nil || audience.SenderID != senderID || version > audience.Version {
+			return nil, NotFoundError{fmt.Errorf("The audience %q cannot be found", reference)}
+		}
+
+		if version == 0 {
+			version = audience.Version
+		}
+
+		pinned["audiences"] = append(pinned["audiences"], models.AudienceReference(audienceID, version))
+	}
+
+	return pinned, nil
+}
+
+func (c CampaignsCollection) isDefaultScope(scope string) bool {
+	for _, defaultScope := range c.defaultScopes {
+		if scope == defaultScope {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (c CampaignsCollection) Get(connection ConnectionInterface, campaignID, clientID string) (Campaign, error) {
 	campaign, err := c.campaignsRepo.Get(connection, campaignID)
 	if err != nil {
@@ -200,15 +340,48 @@ func (c CampaignsCollection) Get(connection ConnectionInterface, campaignID, cli
 		panic(err)
 	}
 
+	exclude, err := unmarshalAudiences(campaign.Exclude)
+	if err != nil {
+		panic(err)
+	}
+
+	intersect, err := unmarshalAudiences(campaign.Intersect)
+	if err != nil {
+		panic(err)
+	}
+
 	return Campaign{
 		ID:             campaignID,
 		SendTo:         sendTo,
+		Exclude:        exclude,
+		Intersect:      intersect,
 		CampaignTypeID: campaign.CampaignTypeID,
 		Text:           campaign.Text,
 		HTML:           campaign.HTML,
+		Markdown:       campaign.Markdown,
 		Subject:        campaign.Subject,
 		TemplateID:     campaign.TemplateID,
 		ReplyTo:        campaign.ReplyTo,
 		SenderID:       campaign.SenderID,
+		BusinessHours:  campaign.BusinessHours,
 	}, nil
 }
+
+func marshalAudiences(audiences map[string][]string) (string, error) {
+	if len(audiences) == 0 {
+		return "", nil
+	}
+
+	output, err := json.Marshal(audiences)
+	return string(output), err
+}
+
+func unmarshalAudiences(input string) (map[string][]string, error) {
+	if input == "" {
+		return nil, nil
+	}
+
+	var audiences map[string][]string
+	err := json.Unmarshal([]byte(input), &audiences)
+	return audiences, err
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/uaa"
 	"github.com/cloudfoundry-incubator/notifications/v2/collections"
 	"github.com/cloudfoundry-incubator/notifications/v2/models"
 
@@ -22,6 +23,8 @@ var _ = Describe("CampaignsCollection", func() {
 		campaignTypesRepo *mocks.CampaignTypesRepository
 		templatesRepo     *mocks.TemplatesRepository
 		sendersRepo       *mocks.SendersRepository
+		audiencesRepo     *mocks.AudiencesRepository
+		groups            *mocks.GroupMembersFinder
 	)
 
 	BeforeEach(func() {
@@ -31,12 +34,14 @@ var _ = Describe("CampaignsCollection", func() {
 		campaignTypesRepo = mocks.NewCampaignTypesRepository()
 		templatesRepo = mocks.NewTemplatesRepository()
 		sendersRepo = mocks.NewSendersRepository()
+		audiencesRepo = mocks.NewAudiencesRepository()
+		groups = mocks.NewGroupMembersFinder()
 
 		var err error
 		startTime, err = time.Parse(time.RFC3339, "2015-09-01T12:34:56-07:00")
 		Expect(err).NotTo(HaveOccurred())
 
-		collection = collections.NewCampaignsCollection(enqueuer, campaignsRepo, campaignTypesRepo, templatesRepo, sendersRepo)
+		collection = collections.NewCampaignsCollection(enqueuer, campaignsRepo, campaignTypesRepo, templatesRepo, sendersRepo, audiencesRepo, groups, []string{"openid", "cloud_controller.read"})
 	})
 
 	Describe("Create", func() {
@@ -66,6 +71,178 @@ var _ = Describe("CampaignsCollection", func() {
 			})
 		})
 
+		Context("when the campaign excludes or intersects audiences", func() {
+			var campaign collections.Campaign
+
+			BeforeEach(func() {
+				campaign = collections.Campaign{
+					SendTo:         map[string][]string{"orgs": {"some-org-guid"}},
+					Exclude:        map[string][]string{"users": {"some-ops-guid"}},
+					Intersect:      map[string][]string{"spaces": {"some-space-guid"}},
+					CampaignTypeID: "some-id",
+					Text:           "some-test",
+					Subject:        "some-subject",
+					TemplateID:     "whoa-a-template-id",
+					SenderID:       "some-sender-id",
+				}
+			})
+
+			It("stores the filters and enqueues them with the campaign", func() {
+				_, err := collection.Create(conn, campaign, "some-client-id", false)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(campaignsRepo.InsertCall.Receives.Campaign.SendTo).To(MatchJSON(`{"orgs": ["some-org-guid"]}`))
+				Expect(campaignsRepo.InsertCall.Receives.Campaign.Exclude).To(MatchJSON(`{"users": ["some-ops-guid"]}`))
+				Expect(campaignsRepo.InsertCall.Receives.Campaign.Intersect).To(MatchJSON(`{"spaces": ["some-space-guid"]}`))
+
+				Expect(enqueuer.EnqueueCall.Receives.Campaign.Exclude).To(Equal(map[string][]string{"users": {"some-ops-guid"}}))
+				Expect(enqueuer.EnqueueCall.Receives.Campaign.Intersect).To(Equal(map[string][]string{"spaces": {"some-space-guid"}}))
+			})
+
+			It("validates the filtered audiences", func() {
+				campaign.Exclude = map[string][]string{"not a thing": {"some-thing-guid"}}
+
+				_, err := collection.Create(conn, campaign, "some-client-id", false)
+				Expect(err).To(MatchError(collections.UnknownError{errors.New("The \"not a thing\" audience is not valid")}))
+			})
+		})
+
+		Context("when the audience is a uaa scope, uaa group, everyone or an organization or space role", func() {
+			It("accepts the audience", func() {
+				for _, sendTo := range []map[string][]string{
+					{"uaa_scopes": {"great.scope"}},
+					{"groups": {"security-oncall"}},
+					{"everyone": {}},
+					{"org_managers": {"some-org-guid"}},
+					{"org_auditors": {"some-org-guid"}},
+					{"billing_managers": {"some-org-guid"}},
+					{"space_managers": {"some-space-guid"}},
+					{"space_developers": {"some-space-guid"}},
+					{"space_auditors": {"some-space-guid"}},
+				} {
+					_, err := collection.Create(conn, collections.Campaign{
+						SendTo:         sendTo,
+						CampaignTypeID: "some-id",
+						Text:           "some-test",
+						Subject:        "some-subject",
+						TemplateID:     "whoa-a-template-id",
+						SenderID:       "some-sender-id",
+					}, "some-client-id", false)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(enqueuer.EnqueueCall.Receives.Campaign.SendTo).To(Equal(sendTo))
+				}
+			})
+
+			Context("when the uaa scope is a default scope", func() {
+				It("returns a validation error", func() {
+					_, err := collection.Create(conn, collections.Campaign{
+						SendTo:         map[string][]string{"uaa_scopes": {"great.scope", "openid"}},
+						CampaignTypeID: "some-id",
+						Text:           "some-test",
+						Subject:        "some-subject",
+						SenderID:       "some-sender-id",
+					}, "some-client-id", false)
+					Expect(err).To(MatchError(collections.ValidationError{errors.New("The \"openid\" scope is a default scope and cannot be sent to")}))
+					Expect(enqueuer.EnqueueCall.Receives.Campaign).To(Equal(collections.Campaign{}))
+				})
+			})
+
+			Context("when the uaa group cannot be found", func() {
+				It("returns a not found error", func() {
+					groups.MembersCall.Returns.Error = uaa.GroupNotFoundError{Name: "no-such-group"}
+
+					_, err := collection.Create(conn, collections.Campaign{
+						SendTo:         map[string][]string{"groups": {"no-such-group"}},
+						CampaignTypeID: "some-id",
+						Text:           "some-test",
+						Subject:        "some-subject",
+						SenderID:       "some-sender-id",
+					}, "some-client-id", false)
+					Expect(err).To(MatchError(collections.NotFoundError{errors.New(`The group "no-such-group" cannot be found`)}))
+					Expect(groups.MembersCall.Receives.GroupName).To(Equal("no-such-group"))
+					Expect(enqueuer.EnqueueCall.Receives.Campaign).To(Equal(collections.Campaign{}))
+				})
+			})
+
+			Context("when the uaa group lookup fails", func() {
+				It("returns an unknown error", func() {
+					groups.MembersCall.Returns.Error = errors.New("uaa is down")
+
+					_, err := collection.Create(conn, collections.Campaign{
+						SendTo:         map[string][]string{"groups": {"security-oncall"}},
+						CampaignTypeID: "some-id",
+						Text:           "some-test",
+						Subject:        "some-subject",
+						SenderID:       "some-sender-id",
+					}, "some-client-id", false)
+					Expect(err).To(MatchError(collections.UnknownError{errors.New("uaa is down")}))
+				})
+			})
+		})
+
+		Context("when the audience is a saved audience", func() {
+			BeforeEach(func() {
+				audiencesRepo.GetCall.Returns.Audience = models.Audience{
+					ID:       "some-audience-id",
+					SenderID: "some-sender-id",
+					Version:  3,
+				}
+			})
+
+			It("pins the campaign to the current version of the audience", func() {
+				_, err := collection.Create(conn, collections.Campaign{
+					SendTo:         map[string][]string{"audiences": {"some-audience-id"}},
+					Exclude:        map[string][]string{"audiences": {"some-audience-id@2"}},
+					CampaignTypeID: "some-id",
+					Text:           "some-test",
+					Subject:        "some-subject",
+					SenderID:       "some-sender-id",
+				}, "some-client-id", false)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(audiencesRepo.GetCall.Receives.AudienceID).To(Equal("some-audience-id"))
+				Expect(enqueuer.EnqueueCall.Receives.Campaign.SendTo).To(Equal(map[string][]string{"audiences": {"some-audience-id@3"}}))
+				Expect(enqueuer.EnqueueCall.Receives.Campaign.Exclude).To(Equal(map[string][]string{"audiences": {"some-audience-id@2"}}))
+			})
+
+			It("returns a not found error when the audience belongs to another sender", func() {
+				audiencesRepo.GetCall.Returns.Audience.SenderID = "other-sender-id"
+
+				_, err := collection.Create(conn, collections.Campaign{
+					SendTo:         map[string][]string{"audiences": {"some-audience-id"}},
+					CampaignTypeID: "some-id",
+					Text:           "some-test",
+					Subject:        "some-subject",
+					SenderID:       "some-sender-id",
+				}, "some-client-id", false)
+				Expect(err).To(MatchError(collections.NotFoundError{errors.New(`The audience "some-audience-id" cannot be found`)}))
+			})
+
+			It("returns a not found error when the pinned version does not exist", func() {
+				_, err := collection.Create(conn, collections.Campaign{
+					SendTo:         map[string][]string{"audiences": {"some-audience-id@4"}},
+					CampaignTypeID: "some-id",
+					Text:           "some-test",
+					Subject:        "some-subject",
+					SenderID:       "some-sender-id",
+				}, "some-client-id", false)
+				Expect(err).To(MatchError(collections.NotFoundError{errors.New(`The audience "some-audience-id@4" cannot be found`)}))
+			})
+
+			It("returns a not found error when the audience does not exist", func() {
+				audiencesRepo.GetCall.Returns.Error = models.RecordNotFoundError{errors.New("not found")}
+
+				_, err := collection.Create(conn, collections.Campaign{
+					SendTo:         map[string][]string{"audiences": {"some-audience-id"}},
+					CampaignTypeID: "some-id",
+					Text:           "some-test",
+					Subject:        "some-subject",
+					SenderID:       "some-sender-id",
+				}, "some-client-id", false)
+				Expect(err).To(MatchError(collections.NotFoundError{errors.New(`The audience "some-audience-id" cannot be found`)}))
+			})
+		})
+
 		Context("when the audience is an email", func() {
 			Context("enqueuing a campaignJob", func() {
 				BeforeEach(func() {
@@ -128,6 +305,23 @@ var _ = Describe("CampaignsCollection", func() {
 					Expect(enqueuedCampaign.ID).To(Equal("a-new-id"))
 					Expect(err).NotTo(HaveOccurred())
 				})
+
+				It("saves whether the campaign waits for local business hours", func() {
+					campaign := collections.Campaign{
+						SendTo:         map[string][]string{"emails": {"test1@example.com"}},
+						CampaignTypeID: "some-id",
+						Text:           "some-test",
+						Subject:        "some-subject",
+						SenderID:       "some-sender-id",
+						BusinessHours:  true,
+					}
+
+					enqueuedCampaign, err := collection.Create(conn, campaign, "some-client-id", false)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(campaignsRepo.InsertCall.Receives.Campaign.BusinessHours).To(BeTrue())
+					Expect(enqueuedCampaign.BusinessHours).To(BeTrue())
+				})
 			})
 		})
 
@@ -599,6 +793,44 @@ var _ = Describe("CampaignsCollection", func() {
 		})
 	})
 
+	Describe("Validate", func() {
+		BeforeEach(func() {
+			sendersRepo.GetCall.Returns.Sender = models.Sender{
+				ID:       "some-sender-id",
+				ClientID: "some-client-id",
+			}
+			campaignTypesRepo.GetCall.Returns.CampaignType = models.CampaignType{
+				ID:         "some-campaign-type-id",
+				TemplateID: "campaign-type-template-id",
+			}
+		})
+
+		It("validates the campaign without persisting or enqueueing it", func() {
+			campaign, err := collection.Validate(conn, collections.Campaign{
+				SendTo:         map[string][]string{"users": {"some-user-guid"}},
+				CampaignTypeID: "some-campaign-type-id",
+				Text:           "some-text",
+				Subject:        "some-subject",
+				SenderID:       "some-sender-id",
+			}, "some-client-id", false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(campaign.TemplateID).To(Equal("campaign-type-template-id"))
+
+			Expect(templatesRepo.GetCall.Receives.TemplateID).To(Equal("campaign-type-template-id"))
+			Expect(campaignsRepo.InsertCall.Receives.Campaign).To(Equal(models.Campaign{}))
+			Expect(enqueuer.EnqueueCall.Receives.Campaign).To(Equal(collections.Campaign{}))
+		})
+
+		It("returns the same errors as Create", func() {
+			_, err := collection.Validate(conn, collections.Campaign{
+				SendTo:         map[string][]string{"users": {"some-user-guid"}},
+				CampaignTypeID: "some-campaign-type-id",
+				SenderID:       "some-sender-id",
+			}, "other-client-id", false)
+			Expect(err).To(MatchError(collections.NotFoundError{errors.New(`Sender with id "some-sender-id" could not be found`)}))
+		})
+	})
+
 	Describe("Get", func() {
 		BeforeEach(func() {
 			campaignsRepo.GetCall.Returns.Campaign = models.Campaign{
@@ -625,6 +857,18 @@ var _ = Describe("CampaignsCollection", func() {
 			Expect(err).NotTo(HaveOccurred())
 			Expect(campaign.ID).To(Equal("my-campaign-id"))
 			Expect(campaign.Text).To(Equal("some-text"))
+			Expect(campaign.Exclude).To(BeNil())
+			Expect(campaign.Intersect).To(BeNil())
+		})
+
+		It("returns the audience exclusions and intersections", func() {
+			campaignsRepo.GetCall.Returns.Campaign.Exclude = `{"users": ["some-ops-guid"]}`
+			campaignsRepo.GetCall.Returns.Campaign.Intersect = `{"spaces": ["some-space-guid"]}`
+
+			campaign, err := collection.Get(conn, "my-campaign-id", "some-client-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(campaign.Exclude).To(Equal(map[string][]string{"users": {"some-ops-guid"}}))
+			Expect(campaign.Intersect).To(Equal(map[string][]string{"spaces": {"some-space-guid"}}))
 		})
 
 		Context("failure cases", func() {
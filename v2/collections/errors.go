@@ -39,3 +39,11 @@ type PermissionsError struct {
 func (e PermissionsError) Error() string {
 	return e.Err.Error()
 }
+
+type ValidationError struct {
+	Err error
+}
+
+func (e ValidationError) Error() string {
+	return e.Err.Error()
+}
@@ -0,0 +1,84 @@
+package collections
+
+import "github.com/cloudfoundry-incubator/notifications/v2/models"
+
+type globalUnsubscribesSetterDeleter interface {
+	Insert(conn models.ConnectionInterface, userGUID string) error
+	Get(conn models.ConnectionInterface, userGUID string) (bool, error)
+	Delete(conn models.ConnectionInterface, userGUID string) error
+}
+
+type GlobalUnsubscribersCollection struct {
+	globalUnsubscribesRepository globalUnsubscribesSetterDeleter
+	userFinder                   existenceChecker
+	preferenceChanges            preferenceChangesRecorder
+}
+
+func NewGlobalUnsubscribersCollection(globalUnsubscribesRepository globalUnsubscribesSetterDeleter, userFinder existenceChecker,
+	preferenceChanges preferenceChangesRecorder) GlobalUnsubscribersCollection {
+
+	return GlobalUnsubscribersCollection{
+		globalUnsubscribesRepository: globalUnsubscribesRepository,
+		userFinder:                   userFinder,
+		preferenceChanges:            preferenceChanges,
+	}
+}
+
+func (c GlobalUnsubscribersCollection) Set(connection ConnectionInterface, userGUID string, actor models.Actor) error {
+	return c.set(connection, userGUID, true, actor)
+}
+
+func (c GlobalUnsubscribersCollection) Delete(connection ConnectionInterface, userGUID string, actor models.Actor) error {
+	return c.set(connection, userGUID, false, actor)
+}
+
+func (c GlobalUnsubscribersCollection) set(connection ConnectionInterface, userGUID string, unsubscribe bool, actor models.Actor) error {
+	err := checkUserExists(c.userFinder, userGUID)
+	if err != nil {
+		return err
+	}
+
+	transaction := connection.Transaction()
+	err = transaction.Begin()
+	if err != nil {
+		return PersistenceError{err}
+	}
+
+	err = setGlobalUnsubscribe(transaction, c.globalUnsubscribesRepository, c.preferenceChanges, userGUID, unsubscribe, actor)
+	if err != nil {
+		transaction.Rollback()
+		return PersistenceError{err}
+	}
+
+	err = transaction.Commit()
+	if err != nil {
+		return PersistenceError{err}
+	}
+
+	return nil
+}
+
+func setGlobalUnsubscribe(connection models.ConnectionInterface, globalUnsubscribes globalUnsubscribesSetterDeleter,
+	preferenceChanges preferenceChangesRecorder, userGUID string, unsubscribe bool, actor models.Actor) error {
+
+	unsubscribed, err := globalUnsubscribes.Get(connection, userGUID)
+	if err != nil {
+		return err
+	}
+
+	if unsubscribe {
+		err = globalUnsubscribes.Insert(connection, userGUID)
+	} else {
+		err = globalUnsubscribes.Delete(connection, userGUID)
+	}
+	if err != nil {
+		return err
+	}
+
+	return preferenceChanges.Record(connection, actor, models.PreferenceChange{
+		UserGUID:   userGUID,
+		Preference: models.PreferenceGlobal,
+		OldValue:   models.SubscriptionValue(unsubscribed),
+		NewValue:   models.SubscriptionValue(unsubscribe),
+	})
+}
@@ -0,0 +1,173 @@
+package collections_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GlobalUnsubscribersCollection", func() {
+	var (
+		globalUnsubscribesRepository *mocks.GlobalUnsubscribesRepository
+		userFinder                   *mocks.UserFinder
+		preferenceChanges            *mocks.PreferenceChangesRepository
+		actor                        models.Actor
+		connection                   *mocks.Connection
+		transaction                  *mocks.Transaction
+		collection                   collections.GlobalUnsubscribersCollection
+	)
+
+	BeforeEach(func() {
+		globalUnsubscribesRepository = mocks.NewGlobalUnsubscribesRepository()
+		userFinder = mocks.NewUserFinder()
+		userFinder.ExistsCall.Returns.Exists = true
+		preferenceChanges = mocks.NewPreferenceChangesRepository()
+		actor = models.Actor{
+			Type:   models.ActorUser,
+			ID:     "some-user-guid",
+			Source: models.SourceAPI,
+		}
+		transaction = mocks.NewTransaction()
+		connection = mocks.NewConnection()
+		connection.TransactionCall.Returns.Transaction = transaction
+
+		collection = collections.NewGlobalUnsubscribersCollection(globalUnsubscribesRepository, userFinder, preferenceChanges)
+	})
+
+	Describe("Set", func() {
+		It("globally unsubscribes the user", func() {
+			err := collection.Set(connection, "some-user-guid", actor)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(userFinder.ExistsCall.Receives.GUID).To(Equal("some-user-guid"))
+			Expect(transaction.BeginCall.WasCalled).To(BeTrue())
+			Expect(transaction.CommitCall.WasCalled).To(BeTrue())
+			Expect(globalUnsubscribesRepository.InsertCall.Receives.Connection).To(Equal(transaction))
+			Expect(globalUnsubscribesRepository.InsertCall.Receives.UserGUID).To(Equal("some-user-guid"))
+		})
+
+		It("records the change", func() {
+			err := collection.Set(connection, "some-user-guid", actor)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(globalUnsubscribesRepository.GetCall.Receives.UserGUID).To(Equal("some-user-guid"))
+			Expect(preferenceChanges.RecordCall.Receives.Connection).To(Equal(transaction))
+			Expect(preferenceChanges.RecordCall.Receives.Actor).To(Equal(actor))
+			Expect(preferenceChanges.RecordCall.Receives.Changes).To(Equal([]models.PreferenceChange{
+				{
+					UserGUID:   "some-user-guid",
+					Preference: models.PreferenceGlobal,
+					OldValue:   models.Subscribed,
+					NewValue:   models.Unsubscribed,
+				},
+			}))
+		})
+
+		Context("failure cases", func() {
+			It("returns a not found error when the user does not exist", func() {
+				userFinder.ExistsCall.Returns.Exists = false
+
+				err := collection.Set(connection, "some-user-guid", actor)
+				Expect(err).To(MatchError(collections.NotFoundError{errors.New(`User "some-user-guid" not found`)}))
+				Expect(globalUnsubscribesRepository.InsertCall.Receives.UserGUID).To(BeEmpty())
+			})
+
+			It("returns an unknown error when the user lookup fails", func() {
+				userFinder.ExistsCall.Returns.Error = errors.New("uaa is down")
+
+				err := collection.Set(connection, "some-user-guid", actor)
+				Expect(err).To(MatchError(collections.UnknownError{errors.New("uaa is down")}))
+			})
+
+			It("returns a persistence error when the repository fails", func() {
+				globalUnsubscribesRepository.InsertCall.Returns.Error = errors.New("db is down")
+
+				err := collection.Set(connection, "some-user-guid", actor)
+				Expect(err).To(MatchError(collections.PersistenceError{errors.New("db is down")}))
+				Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+				Expect(transaction.CommitCall.WasCalled).To(BeFalse())
+			})
+
+			It("rolls back the unsubscribe when the change cannot be recorded", func() {
+				preferenceChanges.RecordCall.Returns.Error = errors.New("db is down")
+
+				err := collection.Set(connection, "some-user-guid", actor)
+				Expect(err).To(MatchError(collections.PersistenceError{errors.New("db is down")}))
+				Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+				Expect(transaction.CommitCall.WasCalled).To(BeFalse())
+			})
+
+			It("returns a persistence error when the transaction cannot be started", func() {
+				transaction.BeginCall.Returns.Error = errors.New("begin failed")
+
+				err := collection.Set(connection, "some-user-guid", actor)
+				Expect(err).To(MatchError(collections.PersistenceError{errors.New("begin failed")}))
+				Expect(globalUnsubscribesRepository.InsertCall.Receives.UserGUID).To(BeEmpty())
+			})
+
+			It("returns a persistence error when the transaction cannot be committed", func() {
+				transaction.CommitCall.Returns.Error = errors.New("commit failed")
+
+				err := collection.Set(connection, "some-user-guid", actor)
+				Expect(err).To(MatchError(collections.PersistenceError{errors.New("commit failed")}))
+			})
+		})
+	})
+
+	Describe("Delete", func() {
+		It("globally resubscribes the user", func() {
+			err := collection.Delete(connection, "some-user-guid", actor)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(transaction.CommitCall.WasCalled).To(BeTrue())
+			Expect(globalUnsubscribesRepository.DeleteCall.Receives.Connection).To(Equal(transaction))
+			Expect(globalUnsubscribesRepository.DeleteCall.Receives.UserGUID).To(Equal("some-user-guid"))
+		})
+
+		It("records the change", func() {
+			globalUnsubscribesRepository.GetCall.Returns.Unsubscribed = true
+
+			err := collection.Delete(connection, "some-user-guid", actor)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(preferenceChanges.RecordCall.Receives.Changes).To(Equal([]models.PreferenceChange{
+				{
+					UserGUID:   "some-user-guid",
+					Preference: models.PreferenceGlobal,
+					OldValue:   models.Unsubscribed,
+					NewValue:   models.Subscribed,
+				},
+			}))
+		})
+
+		Context("failure cases", func() {
+			It("returns a not found error when the user does not exist", func() {
+				userFinder.ExistsCall.Returns.Exists = false
+
+				err := collection.Delete(connection, "some-user-guid", actor)
+				Expect(err).To(MatchError(collections.NotFoundError{errors.New(`User "some-user-guid" not found`)}))
+			})
+
+			It("returns a persistence error when the repository fails", func() {
+				globalUnsubscribesRepository.DeleteCall.Returns.Error = errors.New("db is down")
+
+				err := collection.Delete(connection, "some-user-guid", actor)
+				Expect(err).To(MatchError(collections.PersistenceError{errors.New("db is down")}))
+			})
+
+			It("returns a persistence error when the change cannot be recorded", func() {
+				preferenceChanges.RecordCall.Returns.Error = errors.New("db is down")
+
+				err := collection.Delete(connection, "some-user-guid", actor)
+				Expect(err).To(MatchError(collections.PersistenceError{errors.New("db is down")}))
+				Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+				Expect(transaction.CommitCall.WasCalled).To(BeFalse())
+			})
+		})
+	})
+})
@@ -0,0 +1,130 @@
+package collections
+
+import (
+	"fmt"
+
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+)
+
+type Partial struct {
+	ID       string
+	Name     string
+	HTML     string
+	Text     string
+	ClientID string
+}
+
+type partialsRepository interface {
+	Insert(conn models.ConnectionInterface, partial models.Partial) (insertedPartial models.Partial, err error)
+	Update(conn models.ConnectionInterface, partial models.Partial) (updatedPartial models.Partial, err error)
+	Get(conn models.ConnectionInterface, partialID string) (retrievedPartial models.Partial, err error)
+	List(conn models.ConnectionInterface, clientID string) (retrievedPartialList []models.Partial, err error)
+	Delete(conn models.ConnectionInterface, partial models.Partial) error
+}
+
+type PartialsCollection struct {
+	repo partialsRepository
+}
+
+func NewPartialsCollection(repo partialsRepository) PartialsCollection {
+	return PartialsCollection{
+		repo: repo,
+	}
+}
+
+func (c PartialsCollection) Set(conn ConnectionInterface, partial Partial) (Partial, error) {
+	var (
+		model models.Partial
+		err   error
+	)
+
+	record := models.Partial{
+		ID:       partial.ID,
+		Name:     partial.Name,
+		HTML:     partial.HTML,
+		Text:     partial.Text,
+		ClientID: partial.ClientID,
+	}
+
+	if partial.ID == "" {
+		model, err = c.repo.Insert(conn, record)
+	} else {
+		model, err = c.repo.Update(conn, record)
+	}
+	if err != nil {
+		switch err.(type) {
+		case models.DuplicateRecordError:
+			return Partial{}, DuplicateRecordError{err}
+		default:
+			return Partial{}, PersistenceError{err}
+		}
+	}
+
+	return newPartialFromModel(model), nil
+}
+
+func (c PartialsCollection) Get(conn ConnectionInterface, partialID, clientID string) (Partial, error) {
+	model, err := c.repo.Get(conn, partialID)
+	if err != nil {
+		switch err.(type) {
+		case models.RecordNotFoundError:
+			return Partial{}, NotFoundError{err}
+		default:
+			return Partial{}, UnknownError{err}
+		}
+	}
+
+	if model.ClientID != clientID {
+		return Partial{}, NotFoundError{fmt.Errorf("Partial with id %q could not be found", partialID)}
+	}
+
+	return newPartialFromModel(model), nil
+}
+
+func (c PartialsCollection) List(conn ConnectionInterface, clientID string) ([]Partial, error) {
+	partialList := []Partial{}
+
+	models, err := c.repo.List(conn, clientID)
+	if err != nil {
+		return partialList, UnknownError{err}
+	}
+
+	for _, model := range models {
+		partialList = append(partialList, newPartialFromModel(model))
+	}
+
+	return partialList, nil
+}
+
+func (c PartialsCollection) Delete(conn ConnectionInterface, partialID, clientID string) error {
+	model, err := c.repo.Get(conn, partialID)
+	if err != nil {
+		switch err.(type) {
+		case models.RecordNotFoundError:
+			return NotFoundError{err}
+		default:
+			return UnknownError{err}
+		}
+	}
+
+	if model.ClientID != clientID {
+		return NotFoundError{fmt.Errorf("Partial with id %q could not be found", partialID)}
+	}
+
+	err = c.repo.Delete(conn, model)
+	if err != nil {
+		return UnknownError{err}
+	}
+
+	return nil
+}
+
+func newPartialFromModel(model models.Partial) Partial {
+	return Partial{
+		ID:       model.ID,
+		Name:     model.Name,
+		HTML:     model.HTML,
+		Text:     model.Text,
+		ClientID: model.ClientID,
+	}
+}
@@ -0,0 +1,213 @@
+package collections_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PartialsCollection", func() {
+	var (
+		partialsCollection collections.PartialsCollection
+		partialsRepository *mocks.PartialsRepository
+		conn               *mocks.Connection
+	)
+
+	BeforeEach(func() {
+		partialsRepository = mocks.NewPartialsRepository()
+		partialsCollection = collections.NewPartialsCollection(partialsRepository)
+		conn = mocks.NewConnection()
+	})
+
+	Describe("Set", func() {
+		It("inserts a partial without an id", func() {
+			partialsRepository.InsertCall.Returns.Partial = models.Partial{
+				ID:       "some-partial-id",
+				Name:     "footer",
+				HTML:     "<footer></footer>",
+				Text:     "--",
+				ClientID: "some-client-id",
+			}
+
+			partial, err := partialsCollection.Set(conn, collections.Partial{
+				Name:     "footer",
+				HTML:     "<footer></footer>",
+				Text:     "--",
+				ClientID: "some-client-id",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(partial).To(Equal(collections.Partial{
+				ID:       "some-partial-id",
+				Name:     "footer",
+				HTML:     "<footer></footer>",
+				Text:     "--",
+				ClientID: "some-client-id",
+			}))
+
+			Expect(partialsRepository.InsertCall.Receives.Connection).To(Equal(conn))
+			Expect(partialsRepository.InsertCall.Receives.Partial).To(Equal(models.Partial{
+				Name:     "footer",
+				HTML:     "<footer></footer>",
+				Text:     "--",
+				ClientID: "some-client-id",
+			}))
+		})
+
+		It("updates a partial with an id", func() {
+			partialsRepository.UpdateCall.Returns.Partial = models.Partial{
+				ID:       "some-partial-id",
+				Name:     "footer",
+				Text:     "updated",
+				ClientID: "some-client-id",
+			}
+
+			partial, err := partialsCollection.Set(conn, collections.Partial{
+				ID:       "some-partial-id",
+				Name:     "footer",
+				Text:     "updated",
+				ClientID: "some-client-id",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(partial.Text).To(Equal("updated"))
+
+			Expect(partialsRepository.UpdateCall.Receives.Partial).To(Equal(models.Partial{
+				ID:       "some-partial-id",
+				Name:     "footer",
+				Text:     "updated",
+				ClientID: "some-client-id",
+			}))
+		})
+
+		Context("failure cases", func() {
+			It("returns a duplicate record error when the name is taken", func() {
+				partialsRepository.InsertCall.Returns.Error = models.DuplicateRecordError{errors.New("duplicate")}
+
+				_, err := partialsCollection.Set(conn, collections.Partial{Name: "footer"})
+				Expect(err).To(MatchError(collections.DuplicateRecordError{models.DuplicateRecordError{errors.New("duplicate")}}))
+			})
+
+			It("returns a persistence error when the repository fails", func() {
+				partialsRepository.UpdateCall.Returns.Error = errors.New("failed")
+
+				_, err := partialsCollection.Set(conn, collections.Partial{ID: "some-partial-id"})
+				Expect(err).To(MatchError(collections.PersistenceError{errors.New("failed")}))
+			})
+		})
+	})
+
+	Describe("Get", func() {
+		It("returns the partial", func() {
+			partialsRepository.GetCall.Returns.Partial = models.Partial{
+				ID:       "some-partial-id",
+				Name:     "footer",
+				ClientID: "some-client-id",
+			}
+
+			partial, err := partialsCollection.Get(conn, "some-partial-id", "some-client-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(partial).To(Equal(collections.Partial{
+				ID:       "some-partial-id",
+				Name:     "footer",
+				ClientID: "some-client-id",
+			}))
+
+			Expect(partialsRepository.GetCall.Receives.Connection).To(Equal(conn))
+			Expect(partialsRepository.GetCall.Receives.PartialID).To(Equal("some-partial-id"))
+		})
+
+		Context("failure cases", func() {
+			It("returns a not found error when the partial belongs to another client", func() {
+				partialsRepository.GetCall.Returns.Partial = models.Partial{
+					ID:       "some-partial-id",
+					ClientID: "other-client-id",
+				}
+
+				_, err := partialsCollection.Get(conn, "some-partial-id", "some-client-id")
+				Expect(err).To(MatchError(collections.NotFoundError{errors.New(`Partial with id "some-partial-id" could not be found`)}))
+			})
+
+			It("returns a not found error when the partial does not exist", func() {
+				partialsRepository.GetCall.Returns.Error = models.RecordNotFoundError{errors.New("not found")}
+
+				_, err := partialsCollection.Get(conn, "some-partial-id", "some-client-id")
+				Expect(err).To(MatchError(collections.NotFoundError{models.RecordNotFoundError{errors.New("not found")}}))
+			})
+
+			It("returns an unknown error when the repository fails", func() {
+				partialsRepository.GetCall.Returns.Error = errors.New("failed")
+
+				_, err := partialsCollection.Get(conn, "some-partial-id", "some-client-id")
+				Expect(err).To(MatchError(collections.UnknownError{errors.New("failed")}))
+			})
+		})
+	})
+
+	Describe("List", func() {
+		It("returns the partials belonging to the client", func() {
+			partialsRepository.ListCall.Returns.Partials = []models.Partial{
+				{ID: "footer-id", Name: "footer", ClientID: "some-client-id"},
+				{ID: "header-id", Name: "header", ClientID: "some-client-id"},
+			}
+
+			partials, err := partialsCollection.List(conn, "some-client-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(partials).To(Equal([]collections.Partial{
+				{ID: "footer-id", Name: "footer", ClientID: "some-client-id"},
+				{ID: "header-id", Name: "header", ClientID: "some-client-id"},
+			}))
+
+			Expect(partialsRepository.ListCall.Receives.ClientID).To(Equal("some-client-id"))
+		})
+
+		It("returns an unknown error when the repository fails", func() {
+			partialsRepository.ListCall.Returns.Error = errors.New("failed")
+
+			_, err := partialsCollection.List(conn, "some-client-id")
+			Expect(err).To(MatchError(collections.UnknownError{errors.New("failed")}))
+		})
+	})
+
+	Describe("Delete", func() {
+		It("deletes the partial", func() {
+			partialsRepository.GetCall.Returns.Partial = models.Partial{
+				ID:       "some-partial-id",
+				ClientID: "some-client-id",
+			}
+
+			err := partialsCollection.Delete(conn, "some-partial-id", "some-client-id")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(partialsRepository.DeleteCall.Receives.Connection).To(Equal(conn))
+			Expect(partialsRepository.DeleteCall.Receives.Partial).To(Equal(models.Partial{
+				ID:       "some-partial-id",
+				ClientID: "some-client-id",
+			}))
+		})
+
+		It("returns a not found error when the partial belongs to another client", func() {
+			partialsRepository.GetCall.Returns.Partial = models.Partial{
+				ID:       "some-partial-id",
+				ClientID: "other-client-id",
+			}
+
+			err := partialsCollection.Delete(conn, "some-partial-id", "some-client-id")
+			Expect(err).To(MatchError(collections.NotFoundError{errors.New(`Partial with id "some-partial-id" could not be found`)}))
+		})
+
+		It("returns an unknown error when the delete fails", func() {
+			partialsRepository.GetCall.Returns.Partial = models.Partial{
+				ID:       "some-partial-id",
+				ClientID: "some-client-id",
+			}
+			partialsRepository.DeleteCall.Returns.Error = errors.New("failed")
+
+			err := partialsCollection.Delete(conn, "some-partial-id", "some-client-id")
+			Expect(err).To(MatchError(collections.UnknownError{errors.New("failed")}))
+		})
+	})
+})
@@ -0,0 +1,64 @@
+package collections
+
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+)
+
+type PreferenceChange struct {
+	UserGUID   string
+	Email      string
+	ActorType  string
+	ActorID    string
+	Source     string
+	Preference string
+	ClientID   string
+	TargetID   string
+	OldValue   string
+	NewValue   string
+	CreatedAt  time.Time
+}
+
+type preferenceChangesLister interface {
+	List(conn models.ConnectionInterface, userGUID string, offset, limit int) ([]models.PreferenceChange, error)
+}
+
+type PreferenceChangesCollection struct {
+	repo preferenceChangesLister
+}
+
+func NewPreferenceChangesCollection(repo preferenceChangesLister) PreferenceChangesCollection {
+	return PreferenceChangesCollection{
+		repo: repo,
+	}
+}
+
+// List returns a page of the audit trail for a user, oldest first, or for
+// everyone when the user GUID is empty.
+func (c PreferenceChangesCollection) List(conn ConnectionInterface, userGUID string, offset, limit int) ([]PreferenceChange, error) {
+	changes := []PreferenceChange{}
+
+	records, err := c.repo.List(conn, userGUID, offset, limit)
+	if err != nil {
+		return changes, PersistenceError{err}
+	}
+
+	for _, record := range records {
+		changes = append(changes, PreferenceChange{
+			UserGUID:   record.UserGUID,
+			Email:      record.Email,
+			ActorType:  record.ActorType,
+			ActorID:    record.ActorID,
+			Source:     record.Source,
+			Preference: record.Preference,
+			ClientID:   record.ClientID,
+			TargetID:   record.TargetID,
+			OldValue:   record.OldValue,
+			NewValue:   record.NewValue,
+			CreatedAt:  record.CreatedAt,
+		})
+	}
+
+	return changes, nil
+}
@@ -0,0 +1,77 @@
+package collections_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PreferenceChangesCollection", func() {
+	var (
+		repo       *mocks.PreferenceChangesRepository
+		connection *mocks.Connection
+		collection collections.PreferenceChangesCollection
+		createdAt  time.Time
+	)
+
+	BeforeEach(func() {
+		createdAt = time.Date(2015, time.October, 1, 12, 0, 0, 0, time.UTC)
+
+		repo = mocks.NewPreferenceChangesRepository()
+		repo.ListCall.Returns.Changes = []models.PreferenceChange{
+			{
+				ID:         1,
+				UserGUID:   "some-user-guid",
+				ActorType:  models.ActorClient,
+				ActorID:    "some-client-id",
+				Source:     models.SourceAPI,
+				Preference: models.PreferenceCampaignType,
+				TargetID:   "some-campaign-type-id",
+				OldValue:   models.Subscribed,
+				NewValue:   models.Unsubscribed,
+				CreatedAt:  createdAt,
+			},
+		}
+		connection = mocks.NewConnection()
+
+		collection = collections.NewPreferenceChangesCollection(repo)
+	})
+
+	Describe("List", func() {
+		It("returns the changes made to the user's preferences", func() {
+			changes, err := collection.List(connection, "some-user-guid", 20, 10)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(changes).To(Equal([]collections.PreferenceChange{
+				{
+					UserGUID:   "some-user-guid",
+					ActorType:  models.ActorClient,
+					ActorID:    "some-client-id",
+					Source:     models.SourceAPI,
+					Preference: models.PreferenceCampaignType,
+					TargetID:   "some-campaign-type-id",
+					OldValue:   models.Subscribed,
+					NewValue:   models.Unsubscribed,
+					CreatedAt:  createdAt,
+				},
+			}))
+
+			Expect(repo.ListCall.Receives.Connection).To(Equal(connection))
+			Expect(repo.ListCall.Receives.UserGUID).To(Equal("some-user-guid"))
+			Expect(repo.ListCall.Receives.Offset).To(Equal(20))
+			Expect(repo.ListCall.Receives.Limit).To(Equal(10))
+		})
+
+		It("returns a persistence error when the changes cannot be read", func() {
+			repo.ListCall.Returns.Error = errors.New("db is down")
+
+			_, err := collection.List(connection, "some-user-guid", 20, 10)
+			Expect(err).To(MatchError(collections.PersistenceError{errors.New("db is down")}))
+		})
+	})
+})
@@ -0,0 +1,150 @@
+package collections
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+)
+
+type campaignTypesByTemplateLister interface {
+	ListByTemplateID(conn models.ConnectionInterface, templateID, clientID string) ([]models.CampaignType, error)
+}
+
+type campaignsByTemplateLister interface {
+	ListByTemplateID(conn models.ConnectionInterface, templateID, clientID string, since time.Time) ([]models.Campaign, error)
+}
+
+type messageCountsByTemplateGetter interface {
+	CountByTemplateIDSince(conn models.ConnectionInterface, templateID, clientID string, since time.Time) (models.MessageCounts, error)
+}
+
+type clock interface {
+	Now() time.Time
+}
+
+type TemplateCampaign struct {
+	ID             string
+	CampaignTypeID string
+	Status         string
+	StartTime      time.Time
+}
+
+type TemplateSendCounts struct {
+	Days          int
+	Total         int
+	Delivered     int
+	Queued        int
+	Retry         int
+	Failed        int
+	Undeliverable int
+}
+
+type TemplateAssociations struct {
+	CampaignTypes []CampaignType
+	Campaigns     []TemplateCampaign
+	SendCounts    TemplateSendCounts
+}
+
+// InUse reports whether deleting the template would leave a campaign type
+// or an unfinished campaign pointing at a missing template.
+func (a TemplateAssociations) InUse() bool {
+	if len(a.CampaignTypes) > 0 {
+		return true
+	}
+
+	for _, campaign := range a.Campaigns {
+		if campaign.Status != CampaignStatusCompleted {
+			return true
+		}
+	}
+
+	return false
+}
+
+type TemplateAssociationsCollection struct {
+	templatesRepository     templatesGetter
+	campaignTypesRepository campaignTypesByTemplateLister
+	campaignsRepository     campaignsByTemplateLister
+	messagesRepository      messageCountsByTemplateGetter
+	clock                   clock
+}
+
+func NewTemplateAssociationsCollection(templatesRepository templatesGetter, campaignTypesRepository campaignTypesByTemplateLister, campaignsRepository campaignsByTemplateLister, messagesRepository messageCountsByTemplateGetter, clock clock) TemplateAssociationsCollection {
+	return TemplateAssociationsCollection{
+		templatesRepository:     templatesRepository,
+		campaignTypesRepository: campaignTypesRepository,
+		campaignsRepository:     campaignsRepository,
+		messagesRepository:      messagesRepository,
+		clock:                   clock,
+	}
+}
+
+func (c TemplateAssociationsCollection) List(conn ConnectionInterface, templateID, clientID string, days int) (TemplateAssociations, error) {
+	template, err := c.templatesRepository.Get(conn, templateID)
+	if err != nil {
+		switch err.(type) {
+		case models.RecordNotFoundError:
+			return TemplateAssociations{}, NotFoundError{err}
+		default:
+			return TemplateAssociations{}, PersistenceError{err}
+		}
+	}
+
+	if template.ClientID != clientID && templateID != models.DefaultTemplate.ID {
+		return TemplateAssociations{}, NotFoundError{fmt.Errorf("Template with id %q could not be found", templateID)}
+	}
+
+	since := c.clock.Now().Add(-time.Duration(days) * 24 * time.Hour)
+
+	campaignTypes, err := c.campaignTypesRepository.ListByTemplateID(conn, templateID, clientID)
+	if err != nil {
+		return TemplateAssociations{}, PersistenceError{err}
+	}
+
+	campaigns, err := c.campaignsRepository.ListByTemplateID(conn, templateID, clientID, since)
+	if err != nil {
+		return TemplateAssociations{}, PersistenceError{err}
+	}
+
+	counts, err := c.messagesRepository.CountByTemplateIDSince(conn, templateID, clientID, since)
+	if err != nil {
+		return TemplateAssociations{}, PersistenceError{err}
+	}
+
+	associations := TemplateAssociations{
+		CampaignTypes: []CampaignType{},
+		Campaigns:     []TemplateCampaign{},
+		SendCounts: TemplateSendCounts{
+			Days:          days,
+			Total:         counts.Total,
+			Delivered:     counts.Delivered,
+			Queued:        counts.Queued,
+			Retry:         counts.Retry,
+			Failed:        counts.Failed,
+			Undeliverable: counts.Undeliverable,
+		},
+	}
+
+	for _, campaignType := range campaignTypes {
+		associations.CampaignTypes = append(associations.CampaignTypes, CampaignType{
+			ID:          campaignType.ID,
+			Name:        campaignType.Name,
+			Description: campaignType.Description,
+			Critical:    campaignType.Critical,
+			TemplateID:  campaignType.TemplateID,
+			SenderID:    campaignType.SenderID,
+		})
+	}
+
+	for _, campaign := range campaigns {
+		associations.Campaigns = append(associations.Campaigns, TemplateCampaign{
+			ID:             campaign.ID,
+			CampaignTypeID: campaign.CampaignTypeID,
+			Status:         campaign.Status,
+			StartTime:      campaign.StartTime,
+		})
+	}
+
+	return associations, nil
+}
@@ -0,0 +1,138 @@
+package collections_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TemplateAssociationsCollection", func() {
+	var (
+		templatesRepository     *mocks.TemplatesRepository
+		campaignTypesRepository *mocks.CampaignTypesRepository
+		campaignsRepository     *mocks.CampaignsRepository
+		messagesRepository      *mocks.MessagesRepository
+		clock                   *mocks.Clock
+		conn                    *mocks.Connection
+		now                     time.Time
+		collection              collections.TemplateAssociationsCollection
+	)
+
+	BeforeEach(func() {
+		templatesRepository = mocks.NewTemplatesRepository()
+		templatesRepository.GetCall.Returns.Template = models.Template{
+			ID:       "some-template-id",
+			ClientID: "some-client-id",
+		}
+		campaignTypesRepository = mocks.NewCampaignTypesRepository()
+		campaignsRepository = mocks.NewCampaignsRepository()
+		messagesRepository = mocks.NewMessagesRepository()
+		conn = mocks.NewConnection()
+
+		now = time.Now().UTC().Truncate(time.Second)
+		clock = mocks.NewClock()
+		clock.NowCall.Returns.Time = now
+
+		collection = collections.NewTemplateAssociationsCollection(templatesRepository, campaignTypesRepository, campaignsRepository, messagesRepository, clock)
+	})
+
+	Describe("List", func() {
+		It("returns the campaign types, campaigns and send counts for the template", func() {
+			campaignTypesRepository.ListByTemplateIDCall.Returns.CampaignTypeList = []models.CampaignType{
+				{ID: "campaign-type-id", Name: "some campaign type", TemplateID: "some-template-id", SenderID: "sender-id"},
+			}
+			campaignsRepository.ListByTemplateIDCall.Returns.Campaigns = []models.Campaign{
+				{ID: "campaign-id", CampaignTypeID: "campaign-type-id", Status: "completed", StartTime: now},
+			}
+			messagesRepository.CountByTemplateIDSinceCall.Returns.MessageCounts = models.MessageCounts{
+				Total:     3,
+				Delivered: 2,
+				Failed:    1,
+			}
+
+			associations, err := collection.List(conn, "some-template-id", "some-client-id", 7)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(associations).To(Equal(collections.TemplateAssociations{
+				CampaignTypes: []collections.CampaignType{
+					{ID: "campaign-type-id", Name: "some campaign type", TemplateID: "some-template-id", SenderID: "sender-id"},
+				},
+				Campaigns: []collections.TemplateCampaign{
+					{ID: "campaign-id", CampaignTypeID: "campaign-type-id", Status: "completed", StartTime: now},
+				},
+				SendCounts: collections.TemplateSendCounts{
+					Days:      7,
+					Total:     3,
+					Delivered: 2,
+					Failed:    1,
+				},
+			}))
+
+			Expect(templatesRepository.GetCall.Receives.TemplateID).To(Equal("some-template-id"))
+			Expect(campaignTypesRepository.ListByTemplateIDCall.Receives.Connection).To(Equal(conn))
+			Expect(campaignTypesRepository.ListByTemplateIDCall.Receives.TemplateID).To(Equal("some-template-id"))
+			Expect(campaignTypesRepository.ListByTemplateIDCall.Receives.ClientID).To(Equal("some-client-id"))
+			Expect(campaignsRepository.ListByTemplateIDCall.Receives.Since).To(Equal(now.Add(-7 * 24 * time.Hour)))
+			Expect(messagesRepository.CountByTemplateIDSinceCall.Receives.ClientID).To(Equal("some-client-id"))
+			Expect(messagesRepository.CountByTemplateIDSinceCall.Receives.Since).To(Equal(now.Add(-7 * 24 * time.Hour)))
+		})
+
+		It("lists the client's own usage of the default template", func() {
+			templatesRepository.GetCall.Returns.Template = models.DefaultTemplate
+
+			_, err := collection.List(conn, "default", "some-client-id", 7)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(campaignTypesRepository.ListByTemplateIDCall.Receives.ClientID).To(Equal("some-client-id"))
+		})
+
+		Context("failure cases", func() {
+			It("returns a not found error when the template does not exist", func() {
+				templatesRepository.GetCall.Returns.Error = models.RecordNotFoundError{errors.New("not found")}
+
+				_, err := collection.List(conn, "some-template-id", "some-client-id", 7)
+				Expect(err).To(MatchError(collections.NotFoundError{models.RecordNotFoundError{errors.New("not found")}}))
+			})
+
+			It("returns a not found error when the template belongs to another client", func() {
+				_, err := collection.List(conn, "some-template-id", "other-client-id", 7)
+				Expect(err).To(MatchError(collections.NotFoundError{errors.New(`Template with id "some-template-id" could not be found`)}))
+			})
+
+			It("returns a persistence error when the repositories fail", func() {
+				campaignsRepository.ListByTemplateIDCall.Returns.Error = errors.New("db is down")
+
+				_, err := collection.List(conn, "some-template-id", "some-client-id", 7)
+				Expect(err).To(MatchError(collections.PersistenceError{errors.New("db is down")}))
+			})
+		})
+	})
+
+	Describe("InUse", func() {
+		It("is true when a campaign type references the template", func() {
+			associations := collections.TemplateAssociations{
+				CampaignTypes: []collections.CampaignType{{ID: "campaign-type-id"}},
+			}
+			Expect(associations.InUse()).To(BeTrue())
+		})
+
+		It("is true when an unfinished campaign references the template", func() {
+			associations := collections.TemplateAssociations{
+				Campaigns: []collections.TemplateCampaign{{ID: "campaign-id", Status: "sending"}},
+			}
+			Expect(associations.InUse()).To(BeTrue())
+		})
+
+		It("is false when only completed campaigns reference the template", func() {
+			associations := collections.TemplateAssociations{
+				Campaigns: []collections.TemplateCampaign{{ID: "campaign-id", Status: "completed"}},
+			}
+			Expect(associations.InUse()).To(BeFalse())
+		})
+	})
+})
@@ -10,6 +10,7 @@ type Template struct {
 	ID       string
 	Name     string
 	HTML     string
+	Markdown string
 	Text     string
 	Subject  string
 	Metadata string
@@ -40,6 +41,7 @@ func (c TemplatesCollection) Set(conn ConnectionInterface, template Template) (T
 			ID:       template.ID,
 			Name:     template.Name,
 			HTML:     template.HTML,
+			Markdown: template.Markdown,
 			Text:     template.Text,
 			Subject:  template.Subject,
 			Metadata: template.Metadata,
@@ -58,6 +60,7 @@ func (c TemplatesCollection) Set(conn ConnectionInterface, template Template) (T
 			ID:       model.ID,
 			Name:     model.Name,
 			HTML:     model.HTML,
+			Markdown: model.Markdown,
 			Text:     model.Text,
 			Subject:  model.Subject,
 			Metadata: model.Metadata,
@@ -87,6 +90,7 @@ func (c TemplatesCollection) Get(conn ConnectionInterface, templateID, clientID
 		ID:       template.ID,
 		Name:     template.Name,
 		HTML:     template.HTML,
+		Markdown: template.Markdown,
 		Text:     template.Text,
 		Subject:  template.Subject,
 		Metadata: template.Metadata,
@@ -121,6 +125,7 @@ func (c TemplatesCollection) List(conn ConnectionInterface, clientID string) ([]
 			ID:       template.ID,
 			Name:     template.Name,
 			HTML:     template.HTML,
+			Markdown: template.Markdown,
 			Text:     template.Text,
 			Subject:  template.Subject,
 			Metadata: template.Metadata,
@@ -136,6 +141,7 @@ func (c TemplatesCollection) updateExistingRecord(conn ConnectionInterface, temp
 		ID:       template.ID,
 		Name:     template.Name,
 		HTML:     template.HTML,
+		Markdown: template.Markdown,
 		Text:     template.Text,
 		Subject:  template.Subject,
 		Metadata: template.Metadata,
@@ -149,6 +155,7 @@ func (c TemplatesCollection) updateExistingRecord(conn ConnectionInterface, temp
 		ID:       model.ID,
 		Name:     model.Name,
 		HTML:     model.HTML,
+		Markdown: model.Markdown,
 		Text:     model.Text,
 		Subject:  model.Subject,
 		Metadata: model.Metadata,
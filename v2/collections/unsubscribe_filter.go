@@ -0,0 +1,75 @@
+package collections
+
+import "github.com/cloudfoundry-incubator/notifications/v2/models"
+
+type unsubscribersGetter interface {
+	Get(conn models.ConnectionInterface, userGUID, campaignTypeID string) (models.Unsubscriber, error)
+}
+
+type globalUnsubscribesGetter interface {
+	Get(conn models.ConnectionInterface, userGUID string) (bool, error)
+}
+
+type emailUnsubscribesGetter interface {
+	Get(conn models.ConnectionInterface, email, clientID, campaignTypeID string) (bool, error)
+}
+
+// UnsubscribeFilter decides whether a recipient has opted out of a campaign
+// type. It is shared by campaign previews and the delivery worker so that a
+// preview skips exactly the recipients a real send would.
+type UnsubscribeFilter struct {
+	unsubscribersRepo      unsubscribersGetter
+	globalUnsubscribesRepo globalUnsubscribesGetter
+	emailUnsubscribesRepo  emailUnsubscribesGetter
+	campaignTypesRepo      campaignTypesGetter
+}
+
+func NewUnsubscribeFilter(unsubscribersRepo unsubscribersGetter, globalUnsubscribesRepo globalUnsubscribesGetter, emailUnsubscribesRepo emailUnsubscribesGetter, campaignTypesRepo campaignTypesGetter) UnsubscribeFilter {
+	return UnsubscribeFilter{
+		unsubscribersRepo:      unsubscribersRepo,
+		globalUnsubscribesRepo: globalUnsubscribesRepo,
+		emailUnsubscribesRepo:  emailUnsubscribesRepo,
+		campaignTypesRepo:      campaignTypesRepo,
+	}
+}
+
+// Unsubscribed reports whether the recipient, addressed by user GUID or else
+// by email, should be skipped. Unsubscribing from the campaign type applies
+// to critical campaign types too; a global or email unsubscribe does not.
+func (f UnsubscribeFilter) Unsubscribed(conn models.ConnectionInterface, userGUID, email, clientID, campaignTypeID string) (bool, error) {
+	if userGUID == "" {
+		unsubscribed, err := f.emailUnsubscribesRepo.Get(conn, email, clientID, campaignTypeID)
+		if err != nil || !unsubscribed {
+			return false, err
+		}
+
+		return f.nonCritical(conn, campaignTypeID)
+	}
+
+	unsubscriber, err := f.unsubscribersRepo.Get(conn, userGUID, campaignTypeID)
+	if err != nil {
+		if _, ok := err.(models.RecordNotFoundError); !ok {
+			return false, err
+		}
+	}
+
+	if unsubscriber.ID != "" {
+		return true, nil
+	}
+
+	unsubscribed, err := f.globalUnsubscribesRepo.Get(conn, userGUID)
+	if err != nil || !unsubscribed {
+		return false, err
+	}
+
+	return f.nonCritical(conn, campaignTypeID)
+}
+
+func (f UnsubscribeFilter) nonCritical(conn models.ConnectionInterface, campaignTypeID string) (bool, error) {
+	campaignType, err := f.campaignTypesRepo.Get(conn, campaignTypeID)
+	if err != nil {
+		return false, err
+	}
+
+	return !campaignType.Critical, nil
+}
@@ -0,0 +1,104 @@
+package collections_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("UnsubscribeFilter", func() {
+	var (
+		conn                   *mocks.Connection
+		unsubscribersRepo      *mocks.UnsubscribersRepository
+		globalUnsubscribesRepo *mocks.GlobalUnsubscribesRepository
+		emailUnsubscribesRepo  *mocks.EmailUnsubscribesRepository
+		campaignTypesRepo      *mocks.CampaignTypesRepository
+		filter                 collections.UnsubscribeFilter
+	)
+
+	BeforeEach(func() {
+		conn = mocks.NewConnection()
+		unsubscribersRepo = mocks.NewUnsubscribersRepository()
+		unsubscribersRepo.GetCall.Returns.Error = models.RecordNotFoundError{errors.New("not found")}
+		globalUnsubscribesRepo = mocks.NewGlobalUnsubscribesRepository()
+		emailUnsubscribesRepo = mocks.NewEmailUnsubscribesRepository()
+		campaignTypesRepo = mocks.NewCampaignTypesRepository()
+
+		filter = collections.NewUnsubscribeFilter(unsubscribersRepo, globalUnsubscribesRepo, emailUnsubscribesRepo, campaignTypesRepo)
+	})
+
+	It("does not skip recipients that have not unsubscribed", func() {
+		unsubscribed, err := filter.Unsubscribed(conn, "some-user-guid", "", "some-client-id", "some-campaign-type-id")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(unsubscribed).To(BeFalse())
+
+		Expect(unsubscribersRepo.GetCall.Receives.Connection).To(Equal(conn))
+		Expect(unsubscribersRepo.GetCall.Receives.UserGUID).To(Equal("some-user-guid"))
+		Expect(unsubscribersRepo.GetCall.Receives.CampaignTypeID).To(Equal("some-campaign-type-id"))
+		Expect(globalUnsubscribesRepo.GetCall.Receives.UserGUID).To(Equal("some-user-guid"))
+	})
+
+	It("skips users unsubscribed from the campaign type, even when it is critical", func() {
+		unsubscribersRepo.GetCall.Returns.Unsubscriber = models.Unsubscriber{ID: "some-unsubscriber-id"}
+		unsubscribersRepo.GetCall.Returns.Error = nil
+		campaignTypesRepo.GetCall.Returns.CampaignType.Critical = true
+
+		unsubscribed, err := filter.Unsubscribed(conn, "some-user-guid", "", "some-client-id", "some-campaign-type-id")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(unsubscribed).To(BeTrue())
+	})
+
+	It("skips globally unsubscribed users unless the campaign type is critical", func() {
+		globalUnsubscribesRepo.GetCall.Returns.Unsubscribed = true
+
+		unsubscribed, err := filter.Unsubscribed(conn, "some-user-guid", "", "some-client-id", "some-campaign-type-id")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(unsubscribed).To(BeTrue())
+		Expect(campaignTypesRepo.GetCall.Receives.CampaignTypeID).To(Equal("some-campaign-type-id"))
+
+		campaignTypesRepo.GetCall.Returns.CampaignType.Critical = true
+
+		unsubscribed, err = filter.Unsubscribed(conn, "some-user-guid", "", "some-client-id", "some-campaign-type-id")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(unsubscribed).To(BeFalse())
+	})
+
+	It("skips unsubscribed email recipients unless the campaign type is critical", func() {
+		emailUnsubscribesRepo.GetCall.Returns.Unsubscribed = true
+
+		unsubscribed, err := filter.Unsubscribed(conn, "", "someone@example.com", "some-client-id", "some-campaign-type-id")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(unsubscribed).To(BeTrue())
+		Expect(emailUnsubscribesRepo.GetCall.Receives.Email).To(Equal("someone@example.com"))
+		Expect(emailUnsubscribesRepo.GetCall.Receives.ClientID).To(Equal("some-client-id"))
+		Expect(emailUnsubscribesRepo.GetCall.Receives.CampaignTypeID).To(Equal("some-campaign-type-id"))
+
+		campaignTypesRepo.GetCall.Returns.CampaignType.Critical = true
+
+		unsubscribed, err = filter.Unsubscribed(conn, "", "someone@example.com", "some-client-id", "some-campaign-type-id")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(unsubscribed).To(BeFalse())
+	})
+
+	Context("failure cases", func() {
+		It("returns unsubscriber lookup errors", func() {
+			unsubscribersRepo.GetCall.Returns.Error = errors.New("db is down")
+
+			_, err := filter.Unsubscribed(conn, "some-user-guid", "", "some-client-id", "some-campaign-type-id")
+			Expect(err).To(MatchError(errors.New("db is down")))
+		})
+
+		It("returns campaign type lookup errors", func() {
+			globalUnsubscribesRepo.GetCall.Returns.Unsubscribed = true
+			campaignTypesRepo.GetCall.Returns.Error = errors.New("db is down")
+
+			_, err := filter.Unsubscribed(conn, "some-user-guid", "", "some-client-id", "some-campaign-type-id")
+			Expect(err).To(MatchError(errors.New("db is down")))
+		})
+	})
+})
@@ -17,27 +17,34 @@ type Unsubscriber struct {
 }
 
 type unsubscribersSetterDeleter interface {
+	Get(connection models.ConnectionInterface, userGUID, campaignTypeID string) (models.Unsubscriber, error)
 	Insert(connection models.ConnectionInterface, unsubscriber models.Unsubscriber) (models.Unsubscriber, error)
 	Delete(connection models.ConnectionInterface, unsubscriber models.Unsubscriber) error
 }
 
+type preferenceChangesRecorder interface {
+	Record(connection models.ConnectionInterface, actor models.Actor, change models.PreferenceChange) error
+}
+
 type UnsubscribersCollection struct {
 	unsubscribersRepository unsubscribersSetterDeleter
 	userFinder              existenceChecker
 	campaignTypesRepository campaignTypesGetter
+	preferenceChanges       preferenceChangesRecorder
 }
 
 func NewUnsubscribersCollection(unsubscribersRepository unsubscribersSetterDeleter,
-	campaignTypesRepository campaignTypesGetter, userFinder existenceChecker) UnsubscribersCollection {
+	campaignTypesRepository campaignTypesGetter, userFinder existenceChecker, preferenceChanges preferenceChangesRecorder) UnsubscribersCollection {
 
 	return UnsubscribersCollection{
 		unsubscribersRepository: unsubscribersRepository,
 		userFinder:              userFinder,
 		campaignTypesRepository: campaignTypesRepository,
+		preferenceChanges:       preferenceChanges,
 	}
 }
 
-func (c UnsubscribersCollection) Set(connection ConnectionInterface, unsubscriber Unsubscriber) (Unsubscriber, error) {
+func (c UnsubscribersCollection) Set(connection ConnectionInterface, unsubscriber Unsubscriber, actor models.Actor) (Unsubscriber, error) {
 	campaignType, err := c.campaignTypesRepository.Get(connection, unsubscriber.CampaignTypeID)
 	if err != nil {
 		if e, ok := err.(models.RecordNotFoundError); ok {
@@ -59,10 +66,34 @@ func (c UnsubscribersCollection) Set(connection ConnectionInterface, unsubscribe
 		return Unsubscriber{}, NotFoundError{fmt.Errorf("User %q not found", unsubscriber.UserGUID)}
 	}
 
-	unsub, err := c.unsubscribersRepository.Insert(connection, models.Unsubscriber{
+	transaction := connection.Transaction()
+	err = transaction.Begin()
+	if err != nil {
+		return Unsubscriber{}, err
+	}
+
+	unsubscribed, err := c.isUnsubscribed(transaction, unsubscriber)
+	if err != nil {
+		transaction.Rollback()
+		return Unsubscriber{}, err
+	}
+
+	unsub, err := c.unsubscribersRepository.Insert(transaction, models.Unsubscriber{
 		CampaignTypeID: unsubscriber.CampaignTypeID,
 		UserGUID:       unsubscriber.UserGUID,
 	})
+	if err != nil {
+		transaction.Rollback()
+		return Unsubscriber{}, err
+	}
+
+	err = c.recordChange(transaction, unsubscriber, actor, unsubscribed, true)
+	if err != nil {
+		transaction.Rollback()
+		return Unsubscriber{}, err
+	}
+
+	err = transaction.Commit()
 	if err != nil {
 		return Unsubscriber{}, err
 	}
@@ -71,7 +102,7 @@ func (c UnsubscribersCollection) Set(connection ConnectionInterface, unsubscribe
 	return unsubscriber, nil
 }
 
-func (c UnsubscribersCollection) Delete(connection ConnectionInterface, unsubscriber Unsubscriber) error {
+func (c UnsubscribersCollection) Delete(connection ConnectionInterface, unsubscriber Unsubscriber, actor models.Actor) error {
 	_, err := c.campaignTypesRepository.Get(connection, unsubscriber.CampaignTypeID)
 	if err != nil {
 		if e, ok := err.(models.RecordNotFoundError); ok {
@@ -89,10 +120,54 @@ func (c UnsubscribersCollection) Delete(connection ConnectionInterface, unsubscr
 		return NotFoundError{fmt.Errorf("User %q not found", unsubscriber.UserGUID)}
 	}
 
-	err = c.unsubscribersRepository.Delete(connection, models.Unsubscriber{
+	transaction := connection.Transaction()
+	err = transaction.Begin()
+	if err != nil {
+		return err
+	}
+
+	unsubscribed, err := c.isUnsubscribed(transaction, unsubscriber)
+	if err != nil {
+		transaction.Rollback()
+		return err
+	}
+
+	err = c.unsubscribersRepository.Delete(transaction, models.Unsubscriber{
 		CampaignTypeID: unsubscriber.CampaignTypeID,
 		UserGUID:       unsubscriber.UserGUID,
 	})
+	if err != nil {
+		transaction.Rollback()
+		return err
+	}
 
-	return err
+	err = c.recordChange(transaction, unsubscriber, actor, unsubscribed, false)
+	if err != nil {
+		transaction.Rollback()
+		return err
+	}
+
+	return transaction.Commit()
+}
+
+func (c UnsubscribersCollection) isUnsubscribed(connection models.ConnectionInterface, unsubscriber Unsubscriber) (bool, error) {
+	_, err := c.unsubscribersRepository.Get(connection, unsubscriber.UserGUID, unsubscriber.CampaignTypeID)
+	switch err.(type) {
+	case nil:
+		return true, nil
+	case models.RecordNotFoundError:
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+func (c UnsubscribersCollection) recordChange(connection models.ConnectionInterface, unsubscriber Unsubscriber, actor models.Actor, wasUnsubscribed, unsubscribed bool) error {
+	return c.preferenceChanges.Record(connection, actor, models.PreferenceChange{
+		UserGUID:   unsubscriber.UserGUID,
+		Preference: models.PreferenceCampaignType,
+		TargetID:   unsubscriber.CampaignTypeID,
+		OldValue:   models.SubscriptionValue(wasUnsubscribed),
+		NewValue:   models.SubscriptionValue(unsubscribed),
+	})
 }
@@ -15,17 +15,28 @@ var _ = Describe("UnsubscribersCollection", func() {
 	var (
 		unsubscribersRepository *mocks.UnsubscribersRepository
 		connection              *mocks.Connection
+		transaction             *mocks.Transaction
 		unsubscribersCollection collections.UnsubscribersCollection
 		userFinder              *mocks.UserFinder
 		campaignTypesRepository *mocks.CampaignTypesRepository
+		preferenceChanges       *mocks.PreferenceChangesRepository
+		actor                   models.Actor
 	)
 
 	BeforeEach(func() {
 		unsubscribersRepository = mocks.NewUnsubscribersRepository()
+		transaction = mocks.NewTransaction()
 		connection = mocks.NewConnection()
+		connection.TransactionCall.Returns.Transaction = transaction
 		userFinder = mocks.NewUserFinder()
 		campaignTypesRepository = mocks.NewCampaignTypesRepository()
-		unsubscribersCollection = collections.NewUnsubscribersCollection(unsubscribersRepository, campaignTypesRepository, userFinder)
+		preferenceChanges = mocks.NewPreferenceChangesRepository()
+		actor = models.Actor{
+			Type:   models.ActorClient,
+			ID:     "some-client-id",
+			Source: models.SourceAPI,
+		}
+		unsubscribersCollection = collections.NewUnsubscribersCollection(unsubscribersRepository, campaignTypesRepository, userFinder, preferenceChanges)
 	})
 
 	Describe("Set", func() {
@@ -43,11 +54,13 @@ var _ = Describe("UnsubscribersCollection", func() {
 				unsubscriber, err := unsubscribersCollection.Set(connection, collections.Unsubscriber{
 					CampaignTypeID: "some-campaign-type-id",
 					UserGUID:       "some-user-guid",
-				})
+				}, actor)
 				Expect(err).NotTo(HaveOccurred())
 
 				Expect(userFinder.ExistsCall.Receives.GUID).To(Equal("some-user-guid"))
-				Expect(unsubscribersRepository.InsertCall.Receives.Connection).To(Equal(connection))
+				Expect(transaction.BeginCall.WasCalled).To(BeTrue())
+				Expect(transaction.CommitCall.WasCalled).To(BeTrue())
+				Expect(unsubscribersRepository.InsertCall.Receives.Connection).To(Equal(transaction))
 				Expect(unsubscribersRepository.InsertCall.Receives.Unsubscriber).To(Equal(models.Unsubscriber{
 					CampaignTypeID: "some-campaign-type-id",
 					UserGUID:       "some-user-guid",
@@ -59,6 +72,30 @@ var _ = Describe("UnsubscribersCollection", func() {
 					UserGUID:       "some-user-guid",
 				}))
 			})
+
+			It("records the change", func() {
+				unsubscribersRepository.GetCall.Returns.Error = models.RecordNotFoundError{errors.New("not found")}
+
+				_, err := unsubscribersCollection.Set(connection, collections.Unsubscriber{
+					CampaignTypeID: "some-campaign-type-id",
+					UserGUID:       "some-user-guid",
+				}, actor)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(unsubscribersRepository.GetCall.Receives.UserGUID).To(Equal("some-user-guid"))
+				Expect(unsubscribersRepository.GetCall.Receives.CampaignTypeID).To(Equal("some-campaign-type-id"))
+				Expect(preferenceChanges.RecordCall.Receives.Connection).To(Equal(transaction))
+				Expect(preferenceChanges.RecordCall.Receives.Actor).To(Equal(actor))
+				Expect(preferenceChanges.RecordCall.Receives.Changes).To(Equal([]models.PreferenceChange{
+					{
+						UserGUID:   "some-user-guid",
+						Preference: models.PreferenceCampaignType,
+						TargetID:   "some-campaign-type-id",
+						OldValue:   models.Subscribed,
+						NewValue:   models.Unsubscribed,
+					},
+				}))
+			})
 		})
 
 		Context("when an error occurs", func() {
@@ -68,7 +105,7 @@ var _ = Describe("UnsubscribersCollection", func() {
 					_, err := unsubscribersCollection.Set(connection, collections.Unsubscriber{
 						CampaignTypeID: "some-campaign-type-id",
 						UserGUID:       "some-weird-user",
-					})
+					}, actor)
 					Expect(err).To(MatchError(collections.NotFoundError{errors.New(`User "some-weird-user" not found`)}))
 				})
 			})
@@ -79,7 +116,7 @@ var _ = Describe("UnsubscribersCollection", func() {
 					_, err := unsubscribersCollection.Set(connection, collections.Unsubscriber{
 						CampaignTypeID: "some-campaign-type-id",
 						UserGUID:       "some-weird-user",
-					})
+					}, actor)
 					Expect(err).To(MatchError("some error"))
 				})
 			})
@@ -90,7 +127,7 @@ var _ = Describe("UnsubscribersCollection", func() {
 					_, err := unsubscribersCollection.Set(connection, collections.Unsubscriber{
 						CampaignTypeID: "non-existent-campaign-type-id",
 						UserGUID:       "some-user",
-					})
+					}, actor)
 					Expect(err).To(MatchError(collections.NotFoundError{models.RecordNotFoundError{errors.New("some-record-not-found-error")}}))
 				})
 			})
@@ -101,7 +138,7 @@ var _ = Describe("UnsubscribersCollection", func() {
 					_, err := unsubscribersCollection.Set(connection, collections.Unsubscriber{
 						CampaignTypeID: "some-critical-campaign-type",
 						UserGUID:       "some-user",
-					})
+					}, actor)
 					Expect(err).To(MatchError(collections.PermissionsError{errors.New("Campaign type \"some-critical-campaign-type\" cannot be unsubscribed from")}))
 				})
 			})
@@ -112,7 +149,7 @@ var _ = Describe("UnsubscribersCollection", func() {
 					_, err := unsubscribersCollection.Set(connection, collections.Unsubscriber{
 						CampaignTypeID: "non-existent-campaign-type-id",
 						UserGUID:       "some-user",
-					})
+					}, actor)
 					Expect(err).To(MatchError("some-error"))
 				})
 			})
@@ -123,11 +160,37 @@ var _ = Describe("UnsubscribersCollection", func() {
 					_, err := unsubscribersCollection.Set(connection, collections.Unsubscriber{
 						CampaignTypeID: "some-campaign-type-id",
 						UserGUID:       "some-user-guid",
-					})
+					}, actor)
 
 					Expect(err).To(MatchError("some-other-error"))
 				})
 			})
+
+			Describe("when the change cannot be recorded", func() {
+				It("returns the error", func() {
+					preferenceChanges.RecordCall.Returns.Error = errors.New("some-record-error")
+					_, err := unsubscribersCollection.Set(connection, collections.Unsubscriber{
+						CampaignTypeID: "some-campaign-type-id",
+						UserGUID:       "some-user-guid",
+					}, actor)
+
+					Expect(err).To(MatchError("some-record-error"))
+					Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+					Expect(transaction.CommitCall.WasCalled).To(BeFalse())
+				})
+			})
+
+			Describe("when the transaction cannot be committed", func() {
+				It("returns the error", func() {
+					transaction.CommitCall.Returns.Error = errors.New("commit failed")
+					_, err := unsubscribersCollection.Set(connection, collections.Unsubscriber{
+						CampaignTypeID: "some-campaign-type-id",
+						UserGUID:       "some-user-guid",
+					}, actor)
+
+					Expect(err).To(MatchError("commit failed"))
+				})
+			})
 		})
 	})
 
@@ -141,15 +204,35 @@ var _ = Describe("UnsubscribersCollection", func() {
 				err := unsubscribersCollection.Delete(connection, collections.Unsubscriber{
 					CampaignTypeID: "some-campaign-type-id",
 					UserGUID:       "some-user-guid",
-				})
+				}, actor)
 				Expect(err).NotTo(HaveOccurred())
 
-				Expect(unsubscribersRepository.DeleteCall.Receives.Connection).To(Equal(connection))
+				Expect(transaction.BeginCall.WasCalled).To(BeTrue())
+				Expect(transaction.CommitCall.WasCalled).To(BeTrue())
+				Expect(unsubscribersRepository.DeleteCall.Receives.Connection).To(Equal(transaction))
 				Expect(unsubscribersRepository.DeleteCall.Receives.Unsubscriber).To(Equal(models.Unsubscriber{
 					CampaignTypeID: "some-campaign-type-id",
 					UserGUID:       "some-user-guid",
 				}))
 			})
+
+			It("records the change", func() {
+				err := unsubscribersCollection.Delete(connection, collections.Unsubscriber{
+					CampaignTypeID: "some-campaign-type-id",
+					UserGUID:       "some-user-guid",
+				}, actor)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(preferenceChanges.RecordCall.Receives.Changes).To(Equal([]models.PreferenceChange{
+					{
+						UserGUID:   "some-user-guid",
+						Preference: models.PreferenceCampaignType,
+						TargetID:   "some-campaign-type-id",
+						OldValue:   models.Unsubscribed,
+						NewValue:   models.Subscribed,
+					},
+				}))
+			})
 		})
 
 		Context("failure cases", func() {
@@ -159,7 +242,7 @@ var _ = Describe("UnsubscribersCollection", func() {
 					err := unsubscribersCollection.Delete(connection, collections.Unsubscriber{
 						CampaignTypeID: "non-existent-campaign-type-id",
 						UserGUID:       "some-user",
-					})
+					}, actor)
 					Expect(err).To(MatchError(collections.NotFoundError{models.RecordNotFoundError{errors.New("not found")}}))
 				})
 			})
@@ -170,7 +253,7 @@ var _ = Describe("UnsubscribersCollection", func() {
 					err := unsubscribersCollection.Delete(connection, collections.Unsubscriber{
 						CampaignTypeID: "non-existent-campaign-type-id",
 						UserGUID:       "some-user",
-					})
+					}, actor)
 					Expect(err).To(MatchError("some-error"))
 				})
 			})
@@ -181,7 +264,7 @@ var _ = Describe("UnsubscribersCollection", func() {
 					err := unsubscribersCollection.Delete(connection, collections.Unsubscriber{
 						CampaignTypeID: "some-campaign-type-id",
 						UserGUID:       "some-weird-user",
-					})
+					}, actor)
 					Expect(err).To(MatchError(collections.NotFoundError{errors.New(`User "some-weird-user" not found`)}))
 				})
 			})
@@ -192,10 +275,24 @@ var _ = Describe("UnsubscribersCollection", func() {
 					err := unsubscribersCollection.Delete(connection, collections.Unsubscriber{
 						CampaignTypeID: "some-campaign-type-id",
 						UserGUID:       "some-weird-user",
-					})
+					}, actor)
 					Expect(err).To(MatchError("user not found"))
 				})
 			})
+
+			Context("when the change cannot be recorded", func() {
+				It("rolls back the delete", func() {
+					preferenceChanges.RecordCall.Returns.Error = errors.New("some-record-error")
+					err := unsubscribersCollection.Delete(connection, collections.Unsubscriber{
+						CampaignTypeID: "some-campaign-type-id",
+						UserGUID:       "some-user-guid",
+					}, actor)
+
+					Expect(err).To(MatchError("some-record-error"))
+					Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+					Expect(transaction.CommitCall.WasCalled).To(BeFalse())
+				})
+			})
 		})
 	})
 })
@@ -0,0 +1,392 @@
+package collections
+
+import (
+	"fmt"
+
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+)
+
+type unsubscribableCampaignTypesLister interface {
+	ListNonCritical(conn models.ConnectionInterface) ([]models.CampaignType, error)
+	Get(conn models.ConnectionInterface, campaignTypeID string) (models.CampaignType, error)
+}
+
+type unsubscribersListerSetterDeleter interface {
+	ListByUserGUID(conn models.ConnectionInterface, userGUID string) ([]models.Unsubscriber, error)
+	Insert(conn models.ConnectionInterface, unsubscriber models.Unsubscriber) (models.Unsubscriber, error)
+	Delete(conn models.ConnectionInterface, unsubscriber models.Unsubscriber) error
+}
+
+type digestPreferencesListerSetter interface {
+	Get(conn models.ConnectionInterface, userGUID, clientID, campaignTypeID string) (string, error)
+	ListByUserGUID(conn models.ConnectionInterface, userGUID string) ([]models.DigestPreference, error)
+	Set(conn models.ConnectionInterface, preference models.DigestPreference) error
+}
+
+type quietHoursGetterSetter interface {
+	Get(conn models.ConnectionInterface, userGUID string) (models.QuietHours, error)
+	Set(conn models.ConnectionInterface, quietHours models.QuietHours) error
+}
+
+type CampaignTypePreference struct {
+	ID          string
+	Name        string
+	Description string
+	Subscribed  bool
+	Digest      string
+}
+
+type QuietHours struct {
+	Start string
+	End   string
+}
+
+func ValidDigestFrequency(frequency string) bool {
+	return models.ValidDigestFrequency(frequency)
+}
+
+func ValidTimeZone(timeZone string) bool {
+	return models.ValidTimeZone(timeZone)
+}
+
+func ValidClockTime(clockTime string) bool {
+	return models.ValidClockTime(clockTime)
+}
+
+type SenderPreferences struct {
+	ID            string
+	Name          string
+	CampaignTypes []CampaignTypePreference
+}
+
+type UserPreferences struct {
+	UserGUID          string
+	GlobalUnsubscribe bool
+	TimeZone          string
+	QuietHours        QuietHours
+	Senders           []SenderPreferences
+}
+
+type UserPreferencesCollection struct {
+	campaignTypesRepository unsubscribableCampaignTypesLister
+	sendersRepository       sendersGetter
+	unsubscribersRepository unsubscribersListerSetterDeleter
+	globalUnsubscribes      globalUnsubscribesSetterDeleter
+	digestPreferences       digestPreferencesListerSetter
+	quietHours              quietHoursGetterSetter
+	preferenceChanges       preferenceChangesRecorder
+	userFinder              existenceChecker
+}
+
+func NewUserPreferencesCollection(campaignTypesRepository unsubscribableCampaignTypesLister, sendersRepository sendersGetter,
+	unsubscribersRepository unsubscribersListerSetterDeleter, globalUnsubscribes globalUnsubscribesSetterDeleter,
+	digestPreferences digestPreferencesListerSetter, quietHours quietHoursGetterSetter, preferenceChanges preferenceChangesRecorder,
+	userFinder existenceChecker) UserPreferencesCollection {
+
+	return UserPreferencesCollection{
+		campaignTypesRepository: campaignTypesRepository,
+		sendersRepository:       sendersRepository,
+		unsubscribersRepository: unsubscribersRepository,
+		globalUnsubscribes:      globalUnsubscribes,
+		digestPreferences:       digestPreferences,
+		quietHours:              quietHours,
+		preferenceChanges:       preferenceChanges,
+		userFinder:              userFinder,
+	}
+}
+
+func (c UserPreferencesCollection) List(conn ConnectionInterface, userGUID string) (UserPreferences, error) {
+	err := checkUserExists(c.userFinder, userGUID)
+	if err != nil {
+		return UserPreferences{}, err
+	}
+
+	unsubscribed, err := c.unsubscribedCampaignTypeIDs(conn, userGUID)
+	if err != nil {
+		return UserPreferences{}, err
+	}
+
+	globallyUnsubscribed, err := c.globalUnsubscribes.Get(conn, userGUID)
+	if err != nil {
+		return UserPreferences{}, PersistenceError{err}
+	}
+
+	digestPreferences, err := c.digestPreferences.ListByUserGUID(conn, userGUID)
+	if err != nil {
+		return UserPreferences{}, PersistenceError{err}
+	}
+
+	digests := map[string]string{}
+	for _, digestPreference := range digestPreferences {
+		digests[digestPreference.CampaignTypeID] = digestPreference.Frequency
+	}
+
+	quietHours, err := c.quietHours.Get(conn, userGUID)
+	if err != nil {
+		return UserPreferences{}, PersistenceError{err}
+	}
+
+	campaignTypes, err := c.campaignTypesRepository.ListNonCritical(conn)
+	if err != nil {
+		return UserPreferences{}, PersistenceError{err}
+	}
+
+	preferences := UserPreferences{
+		UserGUID:          userGUID,
+		GlobalUnsubscribe: globallyUnsubscribed,
+		TimeZone:          quietHours.TimeZone,
+		QuietHours: QuietHours{
+			Start: quietHours.Start,
+			End:   quietHours.End,
+		},
+		Senders: []SenderPreferences{},
+	}
+
+	senderIndexes := map[string]int{}
+	for _, campaignType := range campaignTypes {
+		index, ok := senderIndexes[campaignType.SenderID]
+		if !ok {
+			sender, err := c.sendersRepository.Get(conn, campaignType.SenderID)
+			if err != nil {
+				return UserPreferences{}, PersistenceError{err}
+			}
+
+			index = len(preferences.Senders)
+			senderIndexes[campaignType.SenderID] = index
+			preferences.Senders = append(preferences.Senders, SenderPreferences{
+				ID:            sender.ID,
+				Name:          sender.Name,
+				CampaignTypes: []CampaignTypePreference{},
+			})
+		}
+
+		digest, ok := digests[campaignType.ID]
+		if !ok {
+			digest = models.DigestImmediate
+		}
+
+		preferences.Senders[index].CampaignTypes = append(preferences.Senders[index].CampaignTypes, CampaignTypePreference{
+			ID:          campaignType.ID,
+			Name:        campaignType.Name,
+			Description: campaignType.Description,
+			Subscribed:  !unsubscribed[campaignType.ID],
+			Digest:      digest,
+		})
+	}
+
+	return preferences, nil
+}
+
+func (c UserPreferencesCollection) Update(conn ConnectionInterface, userGUID string, campaignTypePreferences []CampaignTypePreference,
+	globalUnsubscribe *bool, timeZone *string, quietHours *QuietHours, actor models.Actor) (UserPreferences, error) {
+
+	err := checkUserExists(c.userFinder, userGUID)
+	if err != nil {
+		return UserPreferences{}, err
+	}
+
+	unsubscribed, err := c.unsubscribedCampaignTypeIDs(conn, userGUID)
+	if err != nil {
+		return UserPreferences{}, err
+	}
+
+	transaction := conn.Transaction()
+	err = transaction.Begin()
+	if err != nil {
+		return UserPreferences{}, PersistenceError{err}
+	}
+
+	err = c.updateCampaignTypes(transaction, userGUID, campaignTypePreferences, unsubscribed, actor)
+	if err != nil {
+		transaction.Rollback()
+		return UserPreferences{}, err
+	}
+
+	if globalUnsubscribe != nil {
+		err = setGlobalUnsubscribe(transaction, c.globalUnsubscribes, c.preferenceChanges, userGUID, *globalUnsubscribe, actor)
+		if err != nil {
+			transaction.Rollback()
+			return UserPreferences{}, PersistenceError{err}
+		}
+	}
+
+	if timeZone != nil || quietHours != nil {
+		err = c.updateQuietHours(transaction, userGUID, timeZone, quietHours, actor)
+		if err != nil {
+			transaction.Rollback()
+			return UserPreferences{}, PersistenceError{err}
+		}
+	}
+
+	err = transaction.Commit()
+	if err != nil {
+		return UserPreferences{}, PersistenceError{err}
+	}
+
+	return c.List(conn, userGUID)
+}
+
+// UpdateCampaignTypes writes the campaign type preferences on conn without
+// beginning or committing a transaction of its own, so that callers can save
+// them together with other changes. It does not check that the user exists.
+func (c UserPreferencesCollection) UpdateCampaignTypes(conn ConnectionInterface, userGUID string, campaignTypePreferences []CampaignTypePreference, actor models.Actor) error {
+	unsubscribed, err := c.unsubscribedCampaignTypeIDs(conn, userGUID)
+	if err != nil {
+		return err
+	}
+
+	return c.updateCampaignTypes(conn, userGUID, campaignTypePreferences, unsubscribed, actor)
+}
+
+func (c UserPreferencesCollection) updateCampaignTypes(conn models.ConnectionInterface, userGUID string, campaignTypePreferences []CampaignTypePreference,
+	unsubscribed map[string]bool, actor models.Actor) error {
+
+	for _, preference := range campaignTypePreferences {
+		err := c.updatePreference(conn, userGUID, preference, unsubscribed[preference.ID], actor)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c UserPreferencesCollection) updatePreference(conn models.ConnectionInterface, userGUID string, preference CampaignTypePreference, unsubscribed bool, actor models.Actor) error {
+	campaignType, err := c.campaignTypesRepository.Get(conn, preference.ID)
+	if err != nil {
+		switch err.(type) {
+		case models.RecordNotFoundError:
+			return NotFoundError{err}
+		default:
+			return PersistenceError{err}
+		}
+	}
+
+	if campaignType.Critical {
+		return PermissionsError{fmt.Errorf("Campaign type %q cannot be unsubscribed from", preference.ID)}
+	}
+
+	unsubscriber := models.Unsubscriber{
+		CampaignTypeID: preference.ID,
+		UserGUID:       userGUID,
+	}
+
+	switch {
+	case preference.Subscribed && unsubscribed:
+		err = c.unsubscribersRepository.Delete(conn, unsubscriber)
+	case !preference.Subscribed && !unsubscribed:
+		_, err = c.unsubscribersRepository.Insert(conn, unsubscriber)
+	}
+	if err != nil {
+		return PersistenceError{err}
+	}
+
+	err = c.preferenceChanges.Record(conn, actor, models.PreferenceChange{
+		UserGUID:   userGUID,
+		Preference: models.PreferenceCampaignType,
+		TargetID:   preference.ID,
+		OldValue:   models.SubscriptionValue(unsubscribed),
+		NewValue:   models.SubscriptionValue(!preference.Subscribed),
+	})
+	if err != nil {
+		return PersistenceError{err}
+	}
+
+	if preference.Digest != "" {
+		sender, err := c.sendersRepository.Get(conn, campaignType.SenderID)
+		if err != nil {
+			return PersistenceError{err}
+		}
+
+		frequency, err := c.digestPreferences.Get(conn, userGUID, sender.ClientID, preference.ID)
+		if err != nil {
+			return PersistenceError{err}
+		}
+
+		err = c.digestPreferences.Set(conn, models.DigestPreference{
+			UserGUID:       userGUID,
+			ClientID:       sender.ClientID,
+			CampaignTypeID: preference.ID,
+			Frequency:      preference.Digest,
+		})
+		if err != nil {
+			return PersistenceError{err}
+		}
+
+		err = c.preferenceChanges.Record(conn, actor, models.PreferenceChange{
+			UserGUID:   userGUID,
+			Preference: models.PreferenceCampaignTypeDigest,
+			ClientID:   sender.ClientID,
+			TargetID:   preference.ID,
+			OldValue:   frequency,
+			NewValue:   preference.Digest,
+		})
+		if err != nil {
+			return PersistenceError{err}
+		}
+	}
+
+	return nil
+}
+
+func (c UserPreferencesCollection) updateQuietHours(conn models.ConnectionInterface, userGUID string, timeZone *string, quietHours *QuietHours, actor models.Actor) error {
+	existing, err := c.quietHours.Get(conn, userGUID)
+	if err != nil {
+		return err
+	}
+
+	previous := existing
+	previous.UserGUID = userGUID
+
+	if timeZone != nil {
+		existing.TimeZone = *timeZone
+	}
+
+	if quietHours != nil {
+		existing.Start = quietHours.Start
+		existing.End = quietHours.End
+	}
+
+	existing.UserGUID = userGUID
+
+	err = c.quietHours.Set(conn, existing)
+	if err != nil {
+		return err
+	}
+
+	for _, change := range models.QuietHoursChanges(userGUID, previous, existing) {
+		err = c.preferenceChanges.Record(conn, actor, change)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func checkUserExists(userFinder existenceChecker, userGUID string) error {
+	exists, err := userFinder.Exists(userGUID)
+	if err != nil {
+		return UnknownError{err}
+	}
+
+	if !exists {
+		return NotFoundError{fmt.Errorf("User %q not found", userGUID)}
+	}
+
+	return nil
+}
+
+func (c UserPreferencesCollection) unsubscribedCampaignTypeIDs(conn ConnectionInterface, userGUID string) (map[string]bool, error) {
+	unsubscribers, err := c.unsubscribersRepository.ListByUserGUID(conn, userGUID)
+	if err != nil {
+		return nil, PersistenceError{err}
+	}
+
+	unsubscribed := map[string]bool{}
+	for _, unsubscriber := range unsubscribers {
+		unsubscribed[unsubscriber.CampaignTypeID] = true
+	}
+
+	return unsubscribed, nil
+}
@@ -0,0 +1,498 @@
+package collections_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("UserPreferencesCollection", func() {
+	var (
+		campaignTypesRepository *mocks.CampaignTypesRepository
+		sendersRepository       *mocks.SendersRepository
+		unsubscribersRepository *mocks.UnsubscribersRepository
+		globalUnsubscribes      *mocks.GlobalUnsubscribesRepository
+		digestPreferences       *mocks.DigestPreferencesRepository
+		quietHours              *mocks.QuietHoursRepository
+		userFinder              *mocks.UserFinder
+		preferenceChanges       *mocks.PreferenceChangesRepository
+		actor                   models.Actor
+		connection              *mocks.Connection
+		transaction             *mocks.Transaction
+		collection              collections.UserPreferencesCollection
+	)
+
+	BeforeEach(func() {
+		campaignTypesRepository = mocks.NewCampaignTypesRepository()
+		campaignTypesRepository.ListNonCriticalCall.Returns.CampaignTypeList = []models.CampaignType{
+			{ID: "first-campaign-type-id", Name: "first", Description: "first campaign type", SenderID: "some-sender-id"},
+			{ID: "second-campaign-type-id", Name: "second", Description: "second campaign type", SenderID: "some-sender-id"},
+		}
+
+		sendersRepository = mocks.NewSendersRepository()
+		sendersRepository.GetCall.Returns.Sender = models.Sender{
+			ID:       "some-sender-id",
+			Name:     "some-sender",
+			ClientID: "some-client-id",
+		}
+
+		unsubscribersRepository = mocks.NewUnsubscribersRepository()
+		unsubscribersRepository.ListByUserGUIDCall.Returns.Unsubscribers = []models.Unsubscriber{
+			{ID: "some-unsubscriber-id", CampaignTypeID: "second-campaign-type-id", UserGUID: "some-user-guid"},
+		}
+
+		globalUnsubscribes = mocks.NewGlobalUnsubscribesRepository()
+
+		digestPreferences = mocks.NewDigestPreferencesRepository()
+		digestPreferences.ListByUserGUIDCall.Returns.Preferences = []models.DigestPreference{
+			{UserGUID: "some-user-guid", ClientID: "some-client-id", CampaignTypeID: "first-campaign-type-id", Frequency: models.DigestDaily},
+		}
+
+		quietHours = mocks.NewQuietHoursRepository()
+		quietHours.GetCall.Returns.QuietHours = models.QuietHours{
+			UserGUID: "some-user-guid",
+			TimeZone: "America/Chicago",
+			Start:    "22:00",
+			End:      "07:00",
+		}
+
+		userFinder = mocks.NewUserFinder()
+		userFinder.ExistsCall.Returns.Exists = true
+
+		preferenceChanges = mocks.NewPreferenceChangesRepository()
+		actor = models.Actor{
+			Type:   models.ActorUser,
+			ID:     "some-user-guid",
+			Source: models.SourceAPI,
+		}
+
+		transaction = mocks.NewTransaction()
+		connection = mocks.NewConnection()
+		connection.TransactionCall.Returns.Transaction = transaction
+
+		collection = collections.NewUserPreferencesCollection(campaignTypesRepository, sendersRepository, unsubscribersRepository, globalUnsubscribes, digestPreferences, quietHours, preferenceChanges, userFinder)
+	})
+
+	Describe("List", func() {
+		It("returns every unsubscribable campaign type grouped by sender", func() {
+			preferences, err := collection.List(connection, "some-user-guid")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(preferences).To(Equal(collections.UserPreferences{
+				UserGUID: "some-user-guid",
+				TimeZone: "America/Chicago",
+				QuietHours: collections.QuietHours{
+					Start: "22:00",
+					End:   "07:00",
+				},
+				Senders: []collections.SenderPreferences{
+					{
+						ID:   "some-sender-id",
+						Name: "some-sender",
+						CampaignTypes: []collections.CampaignTypePreference{
+							{ID: "first-campaign-type-id", Name: "first", Description: "first campaign type", Subscribed: true, Digest: "daily"},
+							{ID: "second-campaign-type-id", Name: "second", Description: "second campaign type", Subscribed: false, Digest: "immediate"},
+						},
+					},
+				},
+			}))
+
+			Expect(userFinder.ExistsCall.Receives.GUID).To(Equal("some-user-guid"))
+			Expect(unsubscribersRepository.ListByUserGUIDCall.Receives.UserGUID).To(Equal("some-user-guid"))
+			Expect(globalUnsubscribes.GetCall.Receives.UserGUID).To(Equal("some-user-guid"))
+			Expect(digestPreferences.ListByUserGUIDCall.Receives.UserGUID).To(Equal("some-user-guid"))
+			Expect(quietHours.GetCall.Receives.UserGUID).To(Equal("some-user-guid"))
+			Expect(campaignTypesRepository.ListNonCriticalCall.Receives.Connection).To(Equal(connection))
+			Expect(sendersRepository.GetCall.Receives.SenderID).To(Equal("some-sender-id"))
+		})
+
+		It("reports whether the user is globally unsubscribed", func() {
+			globalUnsubscribes.GetCall.Returns.Unsubscribed = true
+
+			preferences, err := collection.List(connection, "some-user-guid")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(preferences.GlobalUnsubscribe).To(BeTrue())
+		})
+
+		Context("failure cases", func() {
+			It("returns a not found error when the user does not exist", func() {
+				userFinder.ExistsCall.Returns.Exists = false
+
+				_, err := collection.List(connection, "some-user-guid")
+				Expect(err).To(MatchError(collections.NotFoundError{errors.New(`User "some-user-guid" not found`)}))
+			})
+
+			It("returns an unknown error when the user lookup fails", func() {
+				userFinder.ExistsCall.Returns.Error = errors.New("uaa is down")
+
+				_, err := collection.List(connection, "some-user-guid")
+				Expect(err).To(MatchError(collections.UnknownError{errors.New("uaa is down")}))
+			})
+
+			It("returns a persistence error when the campaign types cannot be listed", func() {
+				campaignTypesRepository.ListNonCriticalCall.Returns.Error = errors.New("db is down")
+
+				_, err := collection.List(connection, "some-user-guid")
+				Expect(err).To(MatchError(collections.PersistenceError{errors.New("db is down")}))
+			})
+
+			It("returns a persistence error when the digest preferences cannot be listed", func() {
+				digestPreferences.ListByUserGUIDCall.Returns.Error = errors.New("db is down")
+
+				_, err := collection.List(connection, "some-user-guid")
+				Expect(err).To(MatchError(collections.PersistenceError{errors.New("db is down")}))
+			})
+
+			It("returns a persistence error when the quiet hours cannot be retrieved", func() {
+				quietHours.GetCall.Returns.Error = errors.New("db is down")
+
+				_, err := collection.List(connection, "some-user-guid")
+				Expect(err).To(MatchError(collections.PersistenceError{errors.New("db is down")}))
+			})
+		})
+	})
+
+	Describe("Update", func() {
+		BeforeEach(func() {
+			campaignTypesRepository.GetCall.Returns.CampaignType = models.CampaignType{
+				ID:       "first-campaign-type-id",
+				SenderID: "some-sender-id",
+			}
+		})
+
+		It("unsubscribes the user from campaign types they were subscribed to", func() {
+			_, err := collection.Update(connection, "some-user-guid", []collections.CampaignTypePreference{
+				{ID: "first-campaign-type-id", Subscribed: false},
+			}, nil, nil, nil, actor)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(transaction.BeginCall.WasCalled).To(BeTrue())
+			Expect(transaction.CommitCall.WasCalled).To(BeTrue())
+			Expect(campaignTypesRepository.GetCall.Receives.CampaignTypeID).To(Equal("first-campaign-type-id"))
+			Expect(unsubscribersRepository.InsertCall.Receives.Connection).To(Equal(transaction))
+			Expect(unsubscribersRepository.InsertCall.Receives.Unsubscriber).To(Equal(models.Unsubscriber{
+				CampaignTypeID: "first-campaign-type-id",
+				UserGUID:       "some-user-guid",
+			}))
+		})
+
+		It("resubscribes the user to campaign types they had unsubscribed from", func() {
+			_, err := collection.Update(connection, "some-user-guid", []collections.CampaignTypePreference{
+				{ID: "second-campaign-type-id", Subscribed: true},
+			}, nil, nil, nil, actor)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(unsubscribersRepository.DeleteCall.Receives.Unsubscriber).To(Equal(models.Unsubscriber{
+				CampaignTypeID: "second-campaign-type-id",
+				UserGUID:       "some-user-guid",
+			}))
+			Expect(unsubscribersRepository.InsertCall.Receives.Unsubscriber).To(Equal(models.Unsubscriber{}))
+		})
+
+		It("leaves unchanged preferences alone", func() {
+			_, err := collection.Update(connection, "some-user-guid", []collections.CampaignTypePreference{
+				{ID: "first-campaign-type-id", Subscribed: true},
+				{ID: "second-campaign-type-id", Subscribed: false},
+			}, nil, nil, nil, actor)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(unsubscribersRepository.InsertCall.Receives.Unsubscriber).To(Equal(models.Unsubscriber{}))
+			Expect(unsubscribersRepository.DeleteCall.Receives.Unsubscriber).To(Equal(models.Unsubscriber{}))
+		})
+
+		It("records each campaign type and global change", func() {
+			globalUnsubscribe := true
+			_, err := collection.Update(connection, "some-user-guid", []collections.CampaignTypePreference{
+				{ID: "first-campaign-type-id", Subscribed: false},
+				{ID: "second-campaign-type-id", Subscribed: true},
+			}, &globalUnsubscribe, nil, nil, actor)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(preferenceChanges.RecordCall.Receives.Connection).To(Equal(transaction))
+			Expect(preferenceChanges.RecordCall.Receives.Actor).To(Equal(actor))
+			Expect(preferenceChanges.RecordCall.Receives.Changes).To(Equal([]models.PreferenceChange{
+				{
+					UserGUID:   "some-user-guid",
+					Preference: models.PreferenceCampaignType,
+					TargetID:   "first-campaign-type-id",
+					OldValue:   models.Subscribed,
+					NewValue:   models.Unsubscribed,
+				},
+				{
+					UserGUID:   "some-user-guid",
+					Preference: models.PreferenceCampaignType,
+					TargetID:   "second-campaign-type-id",
+					OldValue:   models.Unsubscribed,
+					NewValue:   models.Subscribed,
+				},
+				{
+					UserGUID:   "some-user-guid",
+					Preference: models.PreferenceGlobal,
+					OldValue:   models.Subscribed,
+					NewValue:   models.Unsubscribed,
+				},
+			}))
+		})
+
+		It("sets the digest frequency for the campaign type", func() {
+			_, err := collection.Update(connection, "some-user-guid", []collections.CampaignTypePreference{
+				{ID: "first-campaign-type-id", Subscribed: true, Digest: models.DigestHourly},
+			}, nil, nil, nil, actor)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(digestPreferences.SetCall.Receives.Connection).To(Equal(transaction))
+			Expect(digestPreferences.SetCall.Receives.Preferences).To(Equal([]models.DigestPreference{
+				{
+					UserGUID:       "some-user-guid",
+					ClientID:       "some-client-id",
+					CampaignTypeID: "first-campaign-type-id",
+					Frequency:      "hourly",
+				},
+			}))
+		})
+
+		It("records the digest frequency change", func() {
+			digestPreferences.GetCall.Returns.Frequency = models.DigestDaily
+
+			_, err := collection.Update(connection, "some-user-guid", []collections.CampaignTypePreference{
+				{ID: "first-campaign-type-id", Subscribed: true, Digest: models.DigestHourly},
+			}, nil, nil, nil, actor)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(digestPreferences.GetCall.Receives.UserGUID).To(Equal("some-user-guid"))
+			Expect(digestPreferences.GetCall.Receives.ClientID).To(Equal("some-client-id"))
+			Expect(digestPreferences.GetCall.Receives.CampaignTypeID).To(Equal("first-campaign-type-id"))
+			Expect(preferenceChanges.RecordCall.Receives.Changes).To(ContainElement(models.PreferenceChange{
+				UserGUID:   "some-user-guid",
+				Preference: models.PreferenceCampaignTypeDigest,
+				ClientID:   "some-client-id",
+				TargetID:   "first-campaign-type-id",
+				OldValue:   models.DigestDaily,
+				NewValue:   models.DigestHourly,
+			}))
+		})
+
+		It("leaves the digest frequency alone when it is not given", func() {
+			_, err := collection.Update(connection, "some-user-guid", []collections.CampaignTypePreference{
+				{ID: "first-campaign-type-id", Subscribed: true},
+			}, nil, nil, nil, actor)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(digestPreferences.SetCall.CallCount).To(Equal(0))
+		})
+
+		It("globally unsubscribes the user", func() {
+			globalUnsubscribe := true
+			_, err := collection.Update(connection, "some-user-guid", []collections.CampaignTypePreference{}, &globalUnsubscribe, nil, nil, actor)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(globalUnsubscribes.InsertCall.Receives.Connection).To(Equal(transaction))
+			Expect(globalUnsubscribes.InsertCall.Receives.UserGUID).To(Equal("some-user-guid"))
+			Expect(globalUnsubscribes.DeleteCall.Receives.UserGUID).To(BeEmpty())
+		})
+
+		It("globally resubscribes the user", func() {
+			globalUnsubscribe := false
+			_, err := collection.Update(connection, "some-user-guid", []collections.CampaignTypePreference{}, &globalUnsubscribe, nil, nil, actor)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(globalUnsubscribes.DeleteCall.Receives.Connection).To(Equal(transaction))
+			Expect(globalUnsubscribes.DeleteCall.Receives.UserGUID).To(Equal("some-user-guid"))
+			Expect(globalUnsubscribes.InsertCall.Receives.UserGUID).To(BeEmpty())
+		})
+
+		It("leaves the global unsubscribe alone when it is not given", func() {
+			_, err := collection.Update(connection, "some-user-guid", []collections.CampaignTypePreference{}, nil, nil, nil, actor)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(globalUnsubscribes.InsertCall.Receives.UserGUID).To(BeEmpty())
+			Expect(globalUnsubscribes.DeleteCall.Receives.UserGUID).To(BeEmpty())
+		})
+
+		It("sets the time zone, keeping the existing quiet hours", func() {
+			timeZone := "Europe/Paris"
+			_, err := collection.Update(connection, "some-user-guid", []collections.CampaignTypePreference{}, nil, &timeZone, nil, actor)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(quietHours.SetCall.Receives.Connection).To(Equal(transaction))
+			Expect(quietHours.SetCall.Receives.QuietHours).To(Equal(models.QuietHours{
+				UserGUID: "some-user-guid",
+				TimeZone: "Europe/Paris",
+				Start:    "22:00",
+				End:      "07:00",
+			}))
+		})
+
+		It("sets the quiet hours, keeping the existing time zone", func() {
+			_, err := collection.Update(connection, "some-user-guid", []collections.CampaignTypePreference{}, nil, nil, &collections.QuietHours{
+				Start: "23:30",
+				End:   "06:00",
+			}, actor)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(quietHours.SetCall.Receives.QuietHours).To(Equal(models.QuietHours{
+				UserGUID: "some-user-guid",
+				TimeZone: "America/Chicago",
+				Start:    "23:30",
+				End:      "06:00",
+			}))
+		})
+
+		It("records the time zone and quiet hours changes", func() {
+			timeZone := "Europe/Paris"
+			_, err := collection.Update(connection, "some-user-guid", []collections.CampaignTypePreference{}, nil, &timeZone, &collections.QuietHours{
+				Start: "23:30",
+				End:   "06:00",
+			}, actor)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(preferenceChanges.RecordCall.Receives.Connection).To(Equal(transaction))
+			Expect(preferenceChanges.RecordCall.Receives.Changes).To(Equal([]models.PreferenceChange{
+				{
+					UserGUID:   "some-user-guid",
+					Preference: models.PreferenceTimeZone,
+					OldValue:   "America/Chicago",
+					NewValue:   "Europe/Paris",
+				},
+				{
+					UserGUID:   "some-user-guid",
+					Preference: models.PreferenceQuietHours,
+					OldValue:   "22:00-07:00",
+					NewValue:   "23:30-06:00",
+				},
+			}))
+		})
+
+		It("leaves the quiet hours alone when neither they nor the time zone are given", func() {
+			_, err := collection.Update(connection, "some-user-guid", []collections.CampaignTypePreference{}, nil, nil, nil, actor)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(quietHours.SetCall.CallCount).To(Equal(0))
+		})
+
+		It("returns the updated preferences", func() {
+			preferences, err := collection.Update(connection, "some-user-guid", []collections.CampaignTypePreference{}, nil, nil, nil, actor)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(preferences.UserGUID).To(Equal("some-user-guid"))
+			Expect(preferences.Senders).To(HaveLen(1))
+		})
+
+		Context("failure cases", func() {
+			It("returns a not found error when the campaign type does not exist", func() {
+				campaignTypesRepository.GetCall.Returns.Error = models.RecordNotFoundError{errors.New("not found")}
+
+				_, err := collection.Update(connection, "some-user-guid", []collections.CampaignTypePreference{
+					{ID: "missing-campaign-type-id", Subscribed: false},
+				}, nil, nil, nil, actor)
+				Expect(err).To(MatchError(collections.NotFoundError{models.RecordNotFoundError{errors.New("not found")}}))
+				Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+				Expect(transaction.CommitCall.WasCalled).To(BeFalse())
+			})
+
+			It("returns a permissions error when the campaign type is critical", func() {
+				campaignTypesRepository.GetCall.Returns.CampaignType.Critical = true
+
+				_, err := collection.Update(connection, "some-user-guid", []collections.CampaignTypePreference{
+					{ID: "first-campaign-type-id", Subscribed: false},
+				}, nil, nil, nil, actor)
+				Expect(err).To(MatchError(collections.PermissionsError{errors.New(`Campaign type "first-campaign-type-id" cannot be unsubscribed from`)}))
+				Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+			})
+
+			It("returns a persistence error when the unsubscribe cannot be saved", func() {
+				unsubscribersRepository.InsertCall.Returns.Error = errors.New("db is down")
+
+				_, err := collection.Update(connection, "some-user-guid", []collections.CampaignTypePreference{
+					{ID: "first-campaign-type-id", Subscribed: false},
+				}, nil, nil, nil, actor)
+				Expect(err).To(MatchError(collections.PersistenceError{errors.New("db is down")}))
+				Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+			})
+
+			It("returns a persistence error when the change cannot be recorded", func() {
+				preferenceChanges.RecordCall.Returns.Error = errors.New("db is down")
+
+				_, err := collection.Update(connection, "some-user-guid", []collections.CampaignTypePreference{
+					{ID: "first-campaign-type-id", Subscribed: false},
+				}, nil, nil, nil, actor)
+				Expect(err).To(MatchError(collections.PersistenceError{errors.New("db is down")}))
+				Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+			})
+
+			It("returns a persistence error when the digest frequency cannot be saved", func() {
+				digestPreferences.SetCall.Returns.Error = errors.New("db is down")
+
+				_, err := collection.Update(connection, "some-user-guid", []collections.CampaignTypePreference{
+					{ID: "first-campaign-type-id", Subscribed: true, Digest: models.DigestDaily},
+				}, nil, nil, nil, actor)
+				Expect(err).To(MatchError(collections.PersistenceError{errors.New("db is down")}))
+				Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+			})
+
+			It("returns a persistence error when the quiet hours cannot be saved", func() {
+				quietHours.SetCall.Returns.Error = errors.New("db is down")
+
+				timeZone := "Europe/Paris"
+				_, err := collection.Update(connection, "some-user-guid", []collections.CampaignTypePreference{}, nil, &timeZone, nil, actor)
+				Expect(err).To(MatchError(collections.PersistenceError{errors.New("db is down")}))
+				Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+			})
+
+			It("returns a persistence error when the transaction cannot be committed", func() {
+				transaction.CommitCall.Returns.Error = errors.New("commit failed")
+
+				_, err := collection.Update(connection, "some-user-guid", []collections.CampaignTypePreference{}, nil, nil, nil, actor)
+				Expect(err).To(MatchError(collections.PersistenceError{errors.New("commit failed")}))
+			})
+
+			It("returns a not found error when the user does not exist", func() {
+				userFinder.ExistsCall.Returns.Exists = false
+
+				_, err := collection.Update(connection, "some-user-guid", []collections.CampaignTypePreference{}, nil, nil, nil, actor)
+				Expect(err).To(MatchError(collections.NotFoundError{errors.New(`User "some-user-guid" not found`)}))
+				Expect(transaction.BeginCall.WasCalled).To(BeFalse())
+			})
+		})
+	})
+
+	Describe("UpdateCampaignTypes", func() {
+		BeforeEach(func() {
+			campaignTypesRepository.GetCall.Returns.CampaignType = models.CampaignType{
+				ID:       "first-campaign-type-id",
+				SenderID: "some-sender-id",
+			}
+		})
+
+		It("writes the preferences on the given connection without a transaction of its own", func() {
+			err := collection.UpdateCampaignTypes(connection, "some-user-guid", []collections.CampaignTypePreference{
+				{ID: "first-campaign-type-id", Subscribed: false},
+			}, actor)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(transaction.BeginCall.WasCalled).To(BeFalse())
+			Expect(transaction.CommitCall.WasCalled).To(BeFalse())
+			Expect(userFinder.ExistsCall.Receives.GUID).To(BeEmpty())
+
+			Expect(unsubscribersRepository.InsertCall.Receives.Connection).To(Equal(connection))
+			Expect(unsubscribersRepository.InsertCall.Receives.Unsubscriber).To(Equal(models.Unsubscriber{
+				CampaignTypeID: "first-campaign-type-id",
+				UserGUID:       "some-user-guid",
+			}))
+			Expect(preferenceChanges.RecordCall.Receives.Connection).To(Equal(connection))
+		})
+
+		It("returns the error when a preference cannot be saved", func() {
+			campaignTypesRepository.GetCall.Returns.CampaignType.Critical = true
+
+			err := collection.UpdateCampaignTypes(connection, "some-user-guid", []collections.CampaignTypePreference{
+				{ID: "first-campaign-type-id", Subscribed: false},
+			}, actor)
+			Expect(err).To(MatchError(collections.PermissionsError{errors.New(`Campaign type "first-campaign-type-id" cannot be unsubscribed from`)}))
+		})
+	})
+})
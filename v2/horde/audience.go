@@ -1,5 +1,7 @@
 package horde
 
+import "strings"
+
 type Audience struct {
 	Users       []User
 	Endorsement string
@@ -9,3 +11,12 @@ type User struct {
 	Email string
 	GUID  string
 }
+
+// endorsementEscaper quotes template delimiters in user-chosen names. The
+// endorsement is compiled as a template when the message is packaged, so a
+// name containing "{{" would otherwise fail to parse or run as an action.
+var endorsementEscaper = strings.NewReplacer("{{", `{{"{{"}}`)
+
+func escapeEndorsementName(name string) string {
+	return endorsementEscaper.Replace(name)
+}
@@ -0,0 +1,43 @@
+package horde
+
+import "github.com/pivotal-golang/lager"
+
+type allUsers interface {
+	AllUserGUIDs(token string) (userGUIDs []string, err error)
+}
+
+type Everyone struct {
+	allUsers    allUsers
+	tokenLoader tokenLoader
+	uaaHost     string
+}
+
+func NewEveryone(allUsers allUsers, tokenLoader tokenLoader, uaaHost string) Everyone {
+	return Everyone{
+		allUsers:    allUsers,
+		tokenLoader: tokenLoader,
+		uaaHost:     uaaHost,
+	}
+}
+
+func (e Everyone) GenerateAudiences(inputs []string, logger lager.Logger) ([]Audience, error) {
+	token, err := e.tokenLoader.Load(e.uaaHost)
+	if err != nil {
+		return nil, err
+	}
+
+	userGUIDs, err := e.allUsers.AllUserGUIDs(token)
+	if err != nil {
+		return nil, err
+	}
+
+	var users []User
+	for _, userGUID := range userGUIDs {
+		users = append(users, User{GUID: userGUID})
+	}
+
+	return []Audience{{
+		Users:       users,
+		Endorsement: "This message was sent to everyone.",
+	}}, nil
+}
@@ -0,0 +1,70 @@
+package horde_test
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v2/horde"
+	"github.com/pivotal-golang/lager"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("everyone audience", func() {
+	var (
+		allUsers    *mocks.AllUsers
+		tokenLoader *mocks.TokenLoader
+		everyone    horde.Everyone
+		logger      lager.Logger
+	)
+
+	BeforeEach(func() {
+		allUsers = mocks.NewAllUsers()
+		allUsers.AllUserGUIDsCall.Returns.GUIDs = []string{"some-user-guid", "some-other-user-guid"}
+
+		tokenLoader = mocks.NewTokenLoader()
+		tokenLoader.LoadCall.Returns.Token = "token"
+
+		everyone = horde.NewEveryone(allUsers, tokenLoader, "https://uaa.example.com")
+
+		logger = lager.NewLogger("notifications-whatever")
+		logger.RegisterSink(lager.NewWriterSink(bytes.NewBuffer([]byte{}), lager.DEBUG))
+	})
+
+	Describe("GenerateAudiences", func() {
+		It("looks up every user and wraps them in User objects", func() {
+			audiences, err := everyone.GenerateAudiences([]string{}, logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(audiences).To(Equal([]horde.Audience{
+				{
+					Users: []horde.User{
+						{GUID: "some-user-guid"},
+						{GUID: "some-other-user-guid"},
+					},
+					Endorsement: "This message was sent to everyone.",
+				},
+			}))
+
+			Expect(tokenLoader.LoadCall.Receives.UAAHost).To(Equal("https://uaa.example.com"))
+			Expect(allUsers.AllUserGUIDsCall.Receives.Token).To(Equal("token"))
+		})
+
+		Context("when an error occurs", func() {
+			It("returns the token loader error", func() {
+				tokenLoader.LoadCall.Returns.Error = errors.New("some token error")
+
+				_, err := everyone.GenerateAudiences([]string{}, logger)
+				Expect(err).To(MatchError(errors.New("some token error")))
+			})
+
+			It("returns the user lookup error", func() {
+				allUsers.AllUserGUIDsCall.Returns.Error = errors.New("some user error")
+
+				_, err := everyone.GenerateAudiences([]string{}, logger)
+				Expect(err).To(MatchError(errors.New("some user error")))
+			})
+		})
+	})
+})
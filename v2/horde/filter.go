@@ -0,0 +1,56 @@
+package horde
+
+import "github.com/pivotal-golang/lager"
+
+// Filter applies the exclude and intersect blocks of a campaign to its
+// recipients, matching them by Key.
+type Filter struct {
+	intersection map[string]bool
+	exclusions   map[string]bool
+}
+
+// NewFilter builds a filter from the keys of recipients already expanded
+// from the blocks. Without an intersect block, intersection is ignored.
+func NewFilter(intersect bool, intersection, exclusions []string) Filter {
+	filter := Filter{
+		exclusions: map[string]bool{},
+	}
+
+	if intersect {
+		filter.intersection = map[string]bool{}
+		for _, key := range intersection {
+			filter.intersection[key] = true
+		}
+	}
+
+	for _, key := range exclusions {
+		filter.exclusions[key] = true
+	}
+
+	return filter
+}
+
+// Filter expands the intersect and exclude blocks of a campaign.
+func (g Generators) Filter(intersect, exclude map[string][]string, logger lager.Logger) (Filter, error) {
+	intersection, err := g.Keys(intersect, logger)
+	if err != nil {
+		return Filter{}, err
+	}
+
+	exclusions, err := g.Keys(exclude, logger)
+	if err != nil {
+		return Filter{}, err
+	}
+
+	return NewFilter(len(intersect) > 0, intersection, exclusions), nil
+}
+
+// Excludes reports whether the recipient is left out of the campaign, either
+// because it is excluded or because it is missing from the intersection.
+func (f Filter) Excludes(key string) bool {
+	if f.intersection != nil && !f.intersection[key] {
+		return true
+	}
+
+	return f.exclusions[key]
+}
@@ -0,0 +1,154 @@
+package horde
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+	"github.com/pivotal-golang/lager"
+)
+
+type Generator interface {
+	GenerateAudiences(inputs []string, logger lager.Logger) ([]Audience, error)
+}
+
+type UnknownAudienceError struct {
+	Audience string
+}
+
+func (e UnknownAudienceError) Error() string {
+	return fmt.Sprintf("generator for %q audience could not be found", e.Audience)
+}
+
+type generatorsUserFinder interface {
+	userFinder
+	groupUserFinder
+	scopeUserFinder
+}
+
+type GeneratorsConfig struct {
+	UserFinder       generatorsUserFinder
+	OrgFinder        orgFinder
+	SpaceFinder      spaceFinder
+	AllUsers         allUsers
+	TokenLoader      tokenLoader
+	Database         models.DatabaseInterface
+	SavedAudiences   savedAudiencesRepository
+	UAAHost          string
+	DefaultUAAScopes []string
+}
+
+// Generators maps each send_to key to the generator that expands it. The API
+// and the campaign job processor both build theirs with NewGenerators so that
+// a preview resolves exactly the audiences a real send would.
+type Generators map[string]Generator
+
+func NewGenerators(config GeneratorsConfig) Generators {
+	generators := Generators{
+		"users":            NewUsers(),
+		"emails":           NewEmails(),
+		"spaces":           NewSpaces(config.UserFinder, config.OrgFinder, config.SpaceFinder, config.TokenLoader, config.UAAHost),
+		"orgs":             NewOrganizations(config.UserFinder, config.OrgFinder, config.TokenLoader, config.UAAHost),
+		"uaa_scopes":       NewUAAScopes(config.UserFinder, config.TokenLoader, config.UAAHost, config.DefaultUAAScopes),
+		"groups":           NewUAAGroups(config.UserFinder, config.TokenLoader, config.UAAHost),
+		"everyone":         NewEveryone(config.AllUsers, config.TokenLoader, config.UAAHost),
+		"org_managers":     NewOrganizationRole(config.UserFinder, config.OrgFinder, config.TokenLoader, config.UAAHost, "OrgManager"),
+		"org_auditors":     NewOrganizationRole(config.UserFinder, config.OrgFinder, config.TokenLoader, config.UAAHost, "OrgAuditor"),
+		"billing_managers": NewOrganizationRole(config.UserFinder, config.OrgFinder, config.TokenLoader, config.UAAHost, "BillingManager"),
+		"space_managers":   NewSpaceRole(config.UserFinder, config.OrgFinder, config.SpaceFinder, config.TokenLoader, config.UAAHost, "SpaceManager"),
+		"space_developers": NewSpaceRole(config.UserFinder, config.OrgFinder, config.SpaceFinder, config.TokenLoader, config.UAAHost, "SpaceDeveloper"),
+		"space_auditors":   NewSpaceRole(config.UserFinder, config.OrgFinder, config.SpaceFinder, config.TokenLoader, config.UAAHost, "SpaceAuditor"),
+	}
+
+	generators["audiences"] = NewSavedAudiences(config.Database, config.SavedAudiences,
+		generators["users"], generators["spaces"], generators["orgs"], generators["emails"])
+
+	return generators
+}
+
+// ForSender returns a copy of the generators whose saved audiences generator
+// only expands the audiences saved by the given sender. Callers expanding a
+// campaign must use it so that one sender cannot address another's audiences.
+func (g Generators) ForSender(senderID string) Generators {
+	scoped := Generators{}
+	for audience, generator := range g {
+		scoped[audience] = generator
+	}
+
+	if saved, ok := g["audiences"].(SavedAudiences); ok {
+		scoped["audiences"] = saved.ForSender(senderID)
+	}
+
+	return scoped
+}
+
+func (g Generators) Find(audience string) (Generator, error) {
+	generator, ok := g[audience]
+	if !ok {
+		return nil, UnknownAudienceError{Audience: audience}
+	}
+
+	return generator, nil
+}
+
+// Recipient is a user found in an audience, along with the endorsement that
+// tells them why they were sent the message.
+type Recipient struct {
+	User        User
+	Endorsement string
+}
+
+// Recipients expands every audience in sendTo into its distinct recipients,
+// keyed by Key. A recipient found in more than one audience keeps the
+// endorsement of the last one expanded.
+func (g Generators) Recipients(sendTo map[string][]string, logger lager.Logger) (map[string]Recipient, error) {
+	recipients := map[string]Recipient{}
+	for audience, inputs := range sendTo {
+		generator, err := g.Find(audience)
+		if err != nil {
+			return nil, err
+		}
+
+		audiences, err := generator.GenerateAudiences(inputs, logger)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, aud := range audiences {
+			for _, user := range aud.Users {
+				recipients[Key(user)] = Recipient{
+					User:        user,
+					Endorsement: aud.Endorsement,
+				}
+			}
+		}
+	}
+
+	return recipients, nil
+}
+
+// Keys expands every audience in sendTo and returns the sorted keys of its
+// distinct recipients.
+func (g Generators) Keys(sendTo map[string][]string, logger lager.Logger) ([]string, error) {
+	recipients, err := g.Recipients(sendTo, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for key := range recipients {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys, nil
+}
+
+// Key identifies a recipient across audiences: by user GUID, or by address
+// for recipients of the emails audience.
+func Key(user User) string {
+	if user.GUID != "" {
+		return user.GUID
+	}
+	return user.Email
+}
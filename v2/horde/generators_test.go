@@ -0,0 +1,163 @@
+package horde_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v2/horde"
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+	"github.com/pivotal-golang/lager"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Generators", func() {
+	var (
+		users      *mocks.Audiences
+		orgs       *mocks.Audiences
+		emails     *mocks.Audiences
+		generators horde.Generators
+		logger     lager.Logger
+	)
+
+	BeforeEach(func() {
+		users = mocks.NewAudiences()
+		users.GenerateAudiencesCall.Returns.Audiences = []horde.Audience{
+			{Users: []horde.User{{GUID: "user-1"}}, Endorsement: "You are a user."},
+		}
+
+		orgs = mocks.NewAudiences()
+		orgs.GenerateAudiencesCall.Returns.Audiences = []horde.Audience{
+			{Users: []horde.User{{GUID: "user-2"}, {GUID: "user-3"}}, Endorsement: "You are in the org."},
+		}
+
+		emails = mocks.NewAudiences()
+		emails.GenerateAudiencesCall.Returns.Audiences = []horde.Audience{
+			{Users: []horde.User{{Email: "someone@example.com"}}},
+		}
+
+		generators = horde.Generators{
+			"users":  users,
+			"orgs":   orgs,
+			"emails": emails,
+		}
+
+		logger = lager.NewLogger("notifications")
+	})
+
+	Describe("NewGenerators", func() {
+		It("builds a generator for every audience", func() {
+			generators := horde.NewGenerators(horde.GeneratorsConfig{})
+
+			var audiences []string
+			for audience := range generators {
+				audiences = append(audiences, audience)
+			}
+
+			Expect(audiences).To(ConsistOf("users", "emails", "spaces", "orgs", "uaa_scopes", "groups", "everyone",
+				"org_managers", "org_auditors", "billing_managers", "space_managers", "space_developers",
+				"space_auditors", "audiences"))
+		})
+	})
+
+	Describe("ForSender", func() {
+		var config horde.GeneratorsConfig
+
+		BeforeEach(func() {
+			database := mocks.NewDatabase()
+			database.ConnectionCall.Returns.Connection = mocks.NewConnection()
+
+			repository := mocks.NewAudiencesRepository()
+			repository.GetVersionCall.Returns.AudienceVersion = models.AudienceVersion{
+				AudienceID: "some-audience-id",
+				Version:    1,
+				Name:       "security-oncall",
+				SenderID:   "some-sender-id",
+				Definition: `{"emails":["oncall@example.com"]}`,
+			}
+
+			config = horde.GeneratorsConfig{
+				Database:       database,
+				SavedAudiences: repository,
+			}
+		})
+
+		It("expands the saved audiences of the sender only", func() {
+			generators := horde.NewGenerators(config)
+			sendTo := map[string][]string{"audiences": {"some-audience-id@1"}}
+
+			recipients, err := generators.ForSender("some-sender-id").Recipients(sendTo, logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recipients).To(HaveKey("oncall@example.com"))
+
+			_, err = generators.ForSender("other-sender-id").Recipients(sendTo, logger)
+			Expect(err).To(BeAssignableToTypeOf(models.RecordNotFoundError{}))
+
+			_, err = generators.Recipients(sendTo, logger)
+			Expect(err).To(BeAssignableToTypeOf(models.RecordNotFoundError{}))
+		})
+	})
+
+	Describe("Recipients", func() {
+		It("expands the audiences into recipients keyed by guid or email", func() {
+			recipients, err := generators.Recipients(map[string][]string{
+				"orgs":   {"some-org-guid"},
+				"emails": {"someone@example.com"},
+			}, logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recipients).To(Equal(map[string]horde.Recipient{
+				"user-2":              {User: horde.User{GUID: "user-2"}, Endorsement: "You are in the org."},
+				"user-3":              {User: horde.User{GUID: "user-3"}, Endorsement: "You are in the org."},
+				"someone@example.com": {User: horde.User{Email: "someone@example.com"}},
+			}))
+
+			Expect(orgs.GenerateAudiencesCall.Receives.Inputs).To(Equal([]string{"some-org-guid"}))
+			Expect(orgs.GenerateAudiencesCall.Receives.Logger).To(Equal(logger))
+		})
+
+		It("returns an unknown audience error when there is no generator", func() {
+			_, err := generators.Recipients(map[string][]string{"bananas": {"banana-1"}}, logger)
+			Expect(err).To(MatchError(horde.UnknownAudienceError{Audience: "bananas"}))
+		})
+
+		It("returns the error of a failing generator", func() {
+			orgs.GenerateAudiencesCall.Returns.Error = errors.New("cc is down")
+
+			_, err := generators.Recipients(map[string][]string{"orgs": {"some-org-guid"}}, logger)
+			Expect(err).To(MatchError(errors.New("cc is down")))
+		})
+	})
+
+	Describe("Filter", func() {
+		It("excludes nobody without exclude or intersect blocks", func() {
+			filter, err := generators.Filter(nil, nil, logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(filter.Excludes("user-1")).To(BeFalse())
+		})
+
+		It("excludes the recipients of the exclude block", func() {
+			filter, err := generators.Filter(nil, map[string][]string{"users": {"user-1"}}, logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(filter.Excludes("user-1")).To(BeTrue())
+			Expect(filter.Excludes("user-2")).To(BeFalse())
+		})
+
+		It("excludes the recipients missing from the intersect block", func() {
+			users.GenerateAudiencesCall.Returns.Audiences = []horde.Audience{{Users: []horde.User{{GUID: "user-2"}}}}
+
+			filter, err := generators.Filter(map[string][]string{"orgs": {"some-org-guid"}}, map[string][]string{"users": {"user-2"}}, logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(filter.Excludes("user-1")).To(BeTrue())
+			Expect(filter.Excludes("user-2")).To(BeTrue())
+			Expect(filter.Excludes("user-3")).To(BeFalse())
+		})
+
+		It("returns the error of a failing generator", func() {
+			users.GenerateAudiencesCall.Returns.Error = errors.New("uaa is down")
+
+			_, err := generators.Filter(nil, map[string][]string{"users": {"user-1"}}, logger)
+			Expect(err).To(MatchError(errors.New("uaa is down")))
+		})
+	})
+})
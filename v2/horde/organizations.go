@@ -9,7 +9,7 @@ import (
 
 type userFinder interface {
 	UserIDsBelongingToOrganization(orgGUID, role, token string) (userGUIDs []string, err error)
-	UserIDsBelongingToSpace(spaceGUID, token string) (userGUIDs []string, err error)
+	UserIDsBelongingToSpace(spaceGUID, role, token string) (userGUIDs []string, err error)
 }
 
 type orgFinder interface {
@@ -25,6 +25,7 @@ type Organizations struct {
 	orgFinder   orgFinder
 	tokenLoader tokenLoader
 	uaaHost     string
+	role        string
 }
 
 func NewOrganizations(userFinder userFinder, orgFinder orgFinder, tokenLoader tokenLoader, uaaHost string) Organizations {
@@ -36,6 +37,13 @@ func NewOrganizations(userFinder userFinder, orgFinder orgFinder, tokenLoader to
 	}
 }
 
+func NewOrganizationRole(userFinder userFinder, orgFinder orgFinder, tokenLoader tokenLoader, uaaHost, role string) Organizations {
+	organizations := NewOrganizations(userFinder, orgFinder, tokenLoader, uaaHost)
+	organizations.role = role
+
+	return organizations
+}
+
 func (o Organizations) GenerateAudiences(orgGUIDs []string, logger lager.Logger) ([]Audience, error) {
 	var audiences []Audience
 
@@ -61,7 +69,7 @@ func (o Organizations) GenerateAudiences(orgGUIDs []string, logger lager.Logger)
 			return audiences, err
 		}
 
-		userGUIDs, err := o.userFinder.UserIDsBelongingToOrganization(orgGUID, "", token)
+		userGUIDs, err := o.userFinder.UserIDsBelongingToOrganization(orgGUID, o.role, token)
 		if err != nil {
 			return audiences, err
 		}
@@ -72,9 +80,17 @@ func (o Organizations) GenerateAudiences(orgGUIDs []string, logger lager.Logger)
 
 		audiences = append(audiences, Audience{
 			Users:       users,
-			Endorsement: fmt.Sprintf("You received this message because you belong to the %s organization.", org.Name),
+			Endorsement: o.endorsement(org),
 		})
 	}
 
 	return audiences, nil
 }
+
+func (o Organizations) endorsement(org cf.CloudControllerOrganization) string {
+	if o.role != "" {
+		return fmt.Sprintf("You received this message because you are an %s in the %s organization.", o.role, org.Name)
+	}
+
+	return fmt.Sprintf("You received this message because you belong to the %s organization.", org.Name)
+}
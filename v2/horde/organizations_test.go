@@ -65,6 +65,25 @@ var _ = Describe("organizations audience", func() {
 			Expect(orgFinder.LoadCall.Receives.Token).To(Equal("token"))
 		})
 
+		Context("when the audience is qualified by an organization role", func() {
+			It("looks up the users with that role and endorses them accordingly", func() {
+				organizations = horde.NewOrganizationRole(userFinder, orgFinder, tokenLoader, "https://uaa.example.com", "OrgManager")
+
+				audiences, err := organizations.GenerateAudiences([]string{"some-silly-org-guid"}, logger)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(audiences).To(Equal([]horde.Audience{
+					{
+						Users:       []horde.User{{GUID: "some-random-guid"}},
+						Endorsement: "You received this message because you are an OrgManager in the SOME-SILLY organization.",
+					},
+				}))
+
+				Expect(userFinder.UserIDsBelongingToOrganizationCall.Receives.OrgGUID).To(Equal("some-silly-org-guid"))
+				Expect(userFinder.UserIDsBelongingToOrganizationCall.Receives.Role).To(Equal("OrgManager"))
+				Expect(userFinder.UserIDsBelongingToOrganizationCall.Receives.Token).To(Equal("token"))
+			})
+		})
+
 		Context("when we count 100 OrgGUIDs", func() {
 			It("logs the count to the logger", func() {
 				allOrgs := make([]string, 101)
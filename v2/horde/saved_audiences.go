@@ -0,0 +1,171 @@
+package horde
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+	"github.com/pivotal-golang/lager"
+)
+
+type savedAudiencesRepository interface {
+	Get(conn models.ConnectionInterface, audienceID string) (models.Audience, error)
+	GetVersion(conn models.ConnectionInterface, audienceID string, version int) (models.AudienceVersion, error)
+}
+
+type SavedAudiences struct {
+	senderID   string
+	database   models.DatabaseInterface
+	repository savedAudiencesRepository
+	users      Generator
+	spaces     Generator
+	orgs       Generator
+	emails     Generator
+}
+
+func NewSavedAudiences(database models.DatabaseInterface, repository savedAudiencesRepository, users, spaces, orgs, emails Generator) SavedAudiences {
+	return SavedAudiences{
+		database:   database,
+		repository: repository,
+		users:      users,
+		spaces:     spaces,
+		orgs:       orgs,
+		emails:     emails,
+	}
+}
+
+// ForSender returns a copy of the generator that only expands the audiences
+// saved by the given sender. A generator that has not been given a sender
+// expands none.
+func (s SavedAudiences) ForSender(senderID string) SavedAudiences {
+	s.senderID = senderID
+	return s
+}
+
+// GenerateAudiences expands each saved audience into its members, less its
+// exclusions. Inputs pinned to a version (see models.AudienceReference) are
+// expanded as they were saved at that version; bare IDs use the latest one.
+func (s SavedAudiences) GenerateAudiences(references []string, logger lager.Logger) ([]Audience, error) {
+	var audiences []Audience
+
+	conn := s.database.Connection()
+	for _, reference := range references {
+		name, definition, err := s.load(conn, reference)
+		if err != nil {
+			return audiences, err
+		}
+
+		members, err := s.expand(map[string][]string{
+			"users":  definition.Users,
+			"spaces": definition.Spaces,
+			"orgs":   definition.Orgs,
+			"emails": definition.Emails,
+		}, logger)
+		if err != nil {
+			return audiences, err
+		}
+
+		excluded, err := s.expand(definition.Exclude, logger)
+		if err != nil {
+			return audiences, err
+		}
+
+		excludedKeys := map[string]bool{}
+		for _, user := range excluded {
+			excludedKeys[Key(user)] = true
+		}
+
+		var users []User
+		for _, user := range members {
+			if !excludedKeys[Key(user)] {
+				users = append(users, user)
+			}
+		}
+
+		logger.Info("saved-audience-expanded", lager.Data{
+			"audience": reference,
+			"users":    len(users),
+			"excluded": len(members) - len(users),
+		})
+
+		audiences = append(audiences, Audience{
+			Users:       users,
+			Endorsement: fmt.Sprintf("You received this message because you are a member of the %s audience.", escapeEndorsementName(name)),
+		})
+	}
+
+	return audiences, nil
+}
+
+func (s SavedAudiences) load(conn models.ConnectionInterface, reference string) (string, models.AudienceDefinition, error) {
+	var (
+		name       string
+		senderID   string
+		definition string
+	)
+
+	audienceID, version := models.ParseAudienceReference(reference)
+	if version == 0 {
+		audience, err := s.repository.Get(conn, audienceID)
+		if err != nil {
+			return "", models.AudienceDefinition{}, err
+		}
+		name, senderID, definition = audience.Name, audience.SenderID, audience.Definition
+	} else {
+		audienceVersion, err := s.repository.GetVersion(conn, audienceID, version)
+		if err != nil {
+			return "", models.AudienceDefinition{}, err
+		}
+		name, senderID, definition = audienceVersion.Name, audienceVersion.SenderID, audienceVersion.Definition
+	}
+
+	if s.senderID == "" || senderID != s.senderID {
+		return "", models.AudienceDefinition{}, models.RecordNotFoundError{fmt.Errorf("Audience %q could not be found", reference)}
+	}
+
+	var audienceDefinition models.AudienceDefinition
+	err := json.Unmarshal([]byte(definition), &audienceDefinition)
+	if err != nil {
+		return "", models.AudienceDefinition{}, err
+	}
+
+	return name, audienceDefinition, nil
+}
+
+func (s SavedAudiences) expand(inputs map[string][]string, logger lager.Logger) ([]User, error) {
+	var users []User
+	seen := map[string]bool{}
+
+	generators := []struct {
+		key       string
+		generator Generator
+	}{
+		{"users", s.users},
+		{"spaces", s.spaces},
+		{"orgs", s.orgs},
+		{"emails", s.emails},
+	}
+
+	for _, g := range generators {
+		if len(inputs[g.key]) == 0 {
+			continue
+		}
+
+		audiences, err := g.generator.GenerateAudiences(inputs[g.key], logger)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, audience := range audiences {
+			for _, user := range audience.Users {
+				if seen[Key(user)] {
+					continue
+				}
+				seen[Key(user)] = true
+				users = append(users, user)
+			}
+		}
+	}
+
+	return users, nil
+}
@@ -0,0 +1,164 @@
+package horde_test
+
+import (
+	"bytes"
+	"errors"
+	"text/template"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v2/horde"
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+	"github.com/pivotal-golang/lager"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("saved audiences audience", func() {
+	var (
+		database   *mocks.Database
+		conn       *mocks.Connection
+		repository *mocks.AudiencesRepository
+		users      *mocks.Audiences
+		spaces     *mocks.Audiences
+		orgs       *mocks.Audiences
+		emails     *mocks.Audiences
+		saved      horde.SavedAudiences
+		logger     lager.Logger
+	)
+
+	BeforeEach(func() {
+		conn = mocks.NewConnection()
+		database = mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = conn
+
+		repository = mocks.NewAudiencesRepository()
+		repository.GetVersionCall.Returns.AudienceVersion = models.AudienceVersion{
+			AudienceID: "some-audience-id",
+			Version:    2,
+			Name:       "security-oncall",
+			SenderID:   "some-sender-id",
+			Definition: `{"users":["some-user-guid"],"spaces":["some-space-guid"],"exclude":{"orgs":["some-org-guid"]}}`,
+		}
+
+		users = mocks.NewAudiences()
+		users.GenerateAudiencesCall.Returns.Audiences = []horde.Audience{
+			{Users: []horde.User{{GUID: "some-user-guid"}, {GUID: "shared-user-guid"}}},
+		}
+
+		spaces = mocks.NewAudiences()
+		spaces.GenerateAudiencesCall.Returns.Audiences = []horde.Audience{
+			{Users: []horde.User{{GUID: "shared-user-guid"}, {GUID: "excluded-user-guid"}}},
+		}
+
+		orgs = mocks.NewAudiences()
+		orgs.GenerateAudiencesCall.Returns.Audiences = []horde.Audience{
+			{Users: []horde.User{{GUID: "excluded-user-guid"}}},
+		}
+
+		emails = mocks.NewAudiences()
+
+		saved = horde.NewSavedAudiences(database, repository, users, spaces, orgs, emails).ForSender("some-sender-id")
+
+		logger = lager.NewLogger("notifications-whatever")
+		logger.RegisterSink(lager.NewWriterSink(bytes.NewBuffer([]byte{}), lager.DEBUG))
+	})
+
+	Describe("GenerateAudiences", func() {
+		It("expands the pinned version of the audience less its exclusions", func() {
+			audiences, err := saved.GenerateAudiences([]string{"some-audience-id@2"}, logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(audiences).To(Equal([]horde.Audience{
+				{
+					Users: []horde.User{
+						{GUID: "some-user-guid"},
+						{GUID: "shared-user-guid"},
+					},
+					Endorsement: "You received this message because you are a member of the security-oncall audience.",
+				},
+			}))
+
+			Expect(repository.GetVersionCall.Receives.Connection).To(Equal(conn))
+			Expect(repository.GetVersionCall.Receives.AudienceID).To(Equal("some-audience-id"))
+			Expect(repository.GetVersionCall.Receives.Version).To(Equal(2))
+
+			Expect(users.GenerateAudiencesCall.Receives.Inputs).To(Equal([]string{"some-user-guid"}))
+			Expect(spaces.GenerateAudiencesCall.Receives.Inputs).To(Equal([]string{"some-space-guid"}))
+			Expect(orgs.GenerateAudiencesCall.Receives.Inputs).To(Equal([]string{"some-org-guid"}))
+			Expect(emails.GenerateAudiencesCall.CallCount).To(Equal(0))
+		})
+
+		It("expands the latest version when the audience is not pinned", func() {
+			repository.GetCall.Returns.Audience = models.Audience{
+				ID:         "some-audience-id",
+				Name:       "platform-oncall",
+				SenderID:   "some-sender-id",
+				Version:    5,
+				Definition: `{"emails":["oncall@example.com"]}`,
+			}
+			emails.GenerateAudiencesCall.Returns.Audiences = []horde.Audience{
+				{Users: []horde.User{{Email: "oncall@example.com"}}},
+			}
+
+			audiences, err := saved.GenerateAudiences([]string{"some-audience-id"}, logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(audiences).To(Equal([]horde.Audience{
+				{
+					Users:       []horde.User{{Email: "oncall@example.com"}},
+					Endorsement: "You received this message because you are a member of the platform-oncall audience.",
+				},
+			}))
+
+			Expect(repository.GetCall.Receives.AudienceID).To(Equal("some-audience-id"))
+		})
+
+		It("quotes template delimiters in the audience name so the endorsement renders it verbatim", func() {
+			repository.GetVersionCall.Returns.AudienceVersion.Name = "{{oncall"
+
+			audiences, err := saved.GenerateAudiences([]string{"some-audience-id@2"}, logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(audiences).To(HaveLen(1))
+
+			endorsement, err := template.New("endorsement").Parse(audiences[0].Endorsement)
+			Expect(err).NotTo(HaveOccurred())
+
+			output := bytes.NewBuffer([]byte{})
+			err = endorsement.Execute(output, struct{}{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(output.String()).To(Equal("You received this message because you are a member of the {{oncall audience."))
+		})
+
+		Context("when the audience belongs to another sender", func() {
+			It("returns a not found error without expanding it", func() {
+				repository.GetVersionCall.Returns.AudienceVersion.SenderID = "other-sender-id"
+
+				_, err := saved.GenerateAudiences([]string{"some-audience-id@2"}, logger)
+				Expect(err).To(MatchError(models.RecordNotFoundError{errors.New(`Audience "some-audience-id@2" could not be found`)}))
+				Expect(users.GenerateAudiencesCall.CallCount).To(Equal(0))
+			})
+
+			It("returns a not found error when no sender is given", func() {
+				saved = horde.NewSavedAudiences(database, repository, users, spaces, orgs, emails)
+
+				_, err := saved.GenerateAudiences([]string{"some-audience-id@2"}, logger)
+				Expect(err).To(MatchError(models.RecordNotFoundError{errors.New(`Audience "some-audience-id@2" could not be found`)}))
+			})
+		})
+
+		Context("when an error occurs", func() {
+			It("returns the error when the audience cannot be loaded", func() {
+				repository.GetVersionCall.Returns.Error = errors.New("some database error")
+
+				_, err := saved.GenerateAudiences([]string{"some-audience-id@2"}, logger)
+				Expect(err).To(MatchError(errors.New("some database error")))
+			})
+
+			It("returns the error when a member audience cannot be generated", func() {
+				spaces.GenerateAudiencesCall.Returns.Error = errors.New("some cc error")
+
+				_, err := saved.GenerateAudiences([]string{"some-audience-id@2"}, logger)
+				Expect(err).To(MatchError(errors.New("some cc error")))
+			})
+		})
+	})
+})
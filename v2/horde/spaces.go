@@ -17,6 +17,7 @@ type Spaces struct {
 	spaceFinder spaceFinder
 	tokenLoader tokenLoader
 	uaaHost     string
+	role        string
 }
 
 func NewSpaces(userFinder userFinder, orgFinder orgFinder, spaceFinder spaceFinder, tokenLoader tokenLoader, uaaHost string) Spaces {
@@ -29,6 +30,13 @@ func NewSpaces(userFinder userFinder, orgFinder orgFinder, spaceFinder spaceFind
 	}
 }
 
+func NewSpaceRole(userFinder userFinder, orgFinder orgFinder, spaceFinder spaceFinder, tokenLoader tokenLoader, uaaHost, role string) Spaces {
+	spaces := NewSpaces(userFinder, orgFinder, spaceFinder, tokenLoader, uaaHost)
+	spaces.role = role
+
+	return spaces
+}
+
 func (s Spaces) GenerateAudiences(spaceGUIDs []string, logger lager.Logger) ([]Audience, error) {
 	var audiences []Audience
 
@@ -62,7 +70,7 @@ func (s Spaces) GenerateAudiences(spaceGUIDs []string, logger lager.Logger) ([]A
 			return audiences, err
 		}
 
-		userGUIDs, err := s.userFinder.UserIDsBelongingToSpace(space.GUID, token)
+		userGUIDs, err := s.userFinder.UserIDsBelongingToSpace(space.GUID, s.role, token)
 		if err != nil {
 			return audiences, err
 		}
@@ -73,9 +81,17 @@ func (s Spaces) GenerateAudiences(spaceGUIDs []string, logger lager.Logger) ([]A
 
 		audiences = append(audiences, Audience{
 			Users:       users,
-			Endorsement: fmt.Sprintf("You received this message because you belong to the %q space in the %q organization.", space.Name, org.Name),
+			Endorsement: s.endorsement(space, org),
 		})
 	}
 
 	return audiences, nil
 }
+
+func (s Spaces) endorsement(space cf.CloudControllerSpace, org cf.CloudControllerOrganization) string {
+	if s.role != "" {
+		return fmt.Sprintf("You received this message because you are a %s in the %q space in the %q organization.", s.role, space.Name, org.Name)
+	}
+
+	return fmt.Sprintf("You received this message because you belong to the %q space in the %q organization.", space.Name, org.Name)
+}
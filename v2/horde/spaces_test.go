@@ -68,6 +68,7 @@ var _ = Describe("spaces audience", func() {
 			Expect(tokenLoader.LoadCall.Receives.UAAHost).To(Equal("https://uaa.example.com"))
 
 			Expect(userFinder.UserIDsBelongingToSpaceCall.Receives.SpaceGUID).To(Equal("some-silly-space"))
+			Expect(userFinder.UserIDsBelongingToSpaceCall.Receives.Role).To(Equal(""))
 			Expect(userFinder.UserIDsBelongingToSpaceCall.Receives.Token).To(Equal("token"))
 
 			Expect(spaceFinder.LoadCall.Receives.SpaceGUID).To(Equal("some-silly-space"))
@@ -77,6 +78,25 @@ var _ = Describe("spaces audience", func() {
 			Expect(orgFinder.LoadCall.Receives.Token).To(Equal("token"))
 		})
 
+		Context("when the audience is qualified by a space role", func() {
+			It("looks up the users with that role and endorses them accordingly", func() {
+				spaces = horde.NewSpaceRole(userFinder, orgFinder, spaceFinder, tokenLoader, "https://uaa.example.com", "SpaceDeveloper")
+
+				audiences, err := spaces.GenerateAudiences([]string{"some-silly-space"}, logger)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(audiences).To(Equal([]horde.Audience{
+					{
+						Users:       []horde.User{{GUID: "some-random-guid"}},
+						Endorsement: `You received this message because you are a SpaceDeveloper in the "SILLY-SPACE" space in the "SOME-SILLY" organization.`,
+					},
+				}))
+
+				Expect(userFinder.UserIDsBelongingToSpaceCall.Receives.SpaceGUID).To(Equal("some-silly-space"))
+				Expect(userFinder.UserIDsBelongingToSpaceCall.Receives.Role).To(Equal("SpaceDeveloper"))
+				Expect(userFinder.UserIDsBelongingToSpaceCall.Receives.Token).To(Equal("token"))
+			})
+		})
+
 		Context("when we count 100 SpaceGUIDs", func() {
 			It("logs the count to the logger", func() {
 				allSpaces := make([]string, 101)
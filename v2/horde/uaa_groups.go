@@ -0,0 +1,64 @@
+package horde
+
+import (
+	"fmt"
+
+	"github.com/pivotal-golang/lager"
+)
+
+type groupUserFinder interface {
+	UserIDsBelongingToGroup(token, groupName string) (userGUIDs []string, err error)
+}
+
+type UAAGroups struct {
+	userFinder  groupUserFinder
+	tokenLoader tokenLoader
+	uaaHost     string
+}
+
+func NewUAAGroups(userFinder groupUserFinder, tokenLoader tokenLoader, uaaHost string) UAAGroups {
+	return UAAGroups{
+		userFinder:  userFinder,
+		tokenLoader: tokenLoader,
+		uaaHost:     uaaHost,
+	}
+}
+
+// Members returns the GUIDs of the users in the named group. The finder
+// returns a uaa.GroupNotFoundError when there is no such group.
+func (g UAAGroups) Members(groupName string) ([]string, error) {
+	token, err := g.tokenLoader.Load(g.uaaHost)
+	if err != nil {
+		return nil, err
+	}
+
+	return g.userFinder.UserIDsBelongingToGroup(token, groupName)
+}
+
+func (g UAAGroups) GenerateAudiences(groupNames []string, logger lager.Logger) ([]Audience, error) {
+	var audiences []Audience
+
+	token, err := g.tokenLoader.Load(g.uaaHost)
+	if err != nil {
+		return audiences, err
+	}
+
+	for _, groupName := range groupNames {
+		userGUIDs, err := g.userFinder.UserIDsBelongingToGroup(token, groupName)
+		if err != nil {
+			return audiences, err
+		}
+
+		var users []User
+		for _, userGUID := range userGUIDs {
+			users = append(users, User{GUID: userGUID})
+		}
+
+		audiences = append(audiences, Audience{
+			Users:       users,
+			Endorsement: fmt.Sprintf("You received this message because you are a member of the %s group.", escapeEndorsementName(groupName)),
+		})
+	}
+
+	return audiences, nil
+}
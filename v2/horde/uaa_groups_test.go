@@ -0,0 +1,112 @@
+package horde_test
+
+import (
+	"bytes"
+	"errors"
+	"text/template"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v2/horde"
+	"github.com/pivotal-golang/lager"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("uaa groups audience", func() {
+	var (
+		userFinder  *mocks.FindsUserIDs
+		tokenLoader *mocks.TokenLoader
+		groups      horde.UAAGroups
+		logger      lager.Logger
+	)
+
+	BeforeEach(func() {
+		userFinder = mocks.NewFindsUserIDs()
+		userFinder.UserIDsBelongingToGroupCall.Returns.UserIDs = []string{"some-user-guid", "some-other-user-guid"}
+
+		tokenLoader = mocks.NewTokenLoader()
+		tokenLoader.LoadCall.Returns.Token = "token"
+
+		groups = horde.NewUAAGroups(userFinder, tokenLoader, "https://uaa.example.com")
+
+		logger = lager.NewLogger("notifications-whatever")
+		logger.RegisterSink(lager.NewWriterSink(bytes.NewBuffer([]byte{}), lager.DEBUG))
+	})
+
+	Describe("Members", func() {
+		It("returns the GUIDs of the members of the group", func() {
+			userGUIDs, err := groups.Members("security-oncall")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(userGUIDs).To(Equal([]string{"some-user-guid", "some-other-user-guid"}))
+
+			Expect(tokenLoader.LoadCall.Receives.UAAHost).To(Equal("https://uaa.example.com"))
+			Expect(userFinder.UserIDsBelongingToGroupCall.Receives.Token).To(Equal("token"))
+			Expect(userFinder.UserIDsBelongingToGroupCall.Receives.GroupName).To(Equal("security-oncall"))
+		})
+
+		Context("when the token loader encounters an error", func() {
+			It("returns the error", func() {
+				tokenLoader.LoadCall.Returns.Error = errors.New("some token error")
+
+				_, err := groups.Members("security-oncall")
+				Expect(err).To(MatchError(errors.New("some token error")))
+			})
+		})
+	})
+
+	Describe("GenerateAudiences", func() {
+		It("looks up the members of the group and wraps them in User objects", func() {
+			audiences, err := groups.GenerateAudiences([]string{"security-oncall"}, logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(audiences).To(Equal([]horde.Audience{
+				{
+					Users: []horde.User{
+						{GUID: "some-user-guid"},
+						{GUID: "some-other-user-guid"},
+					},
+					Endorsement: "You received this message because you are a member of the security-oncall group.",
+				},
+			}))
+
+			Expect(tokenLoader.LoadCall.Receives.UAAHost).To(Equal("https://uaa.example.com"))
+
+			Expect(userFinder.UserIDsBelongingToGroupCall.Receives.Token).To(Equal("token"))
+			Expect(userFinder.UserIDsBelongingToGroupCall.Receives.GroupName).To(Equal("security-oncall"))
+		})
+
+		It("quotes template delimiters in the group name so the endorsement renders it verbatim", func() {
+			audiences, err := groups.GenerateAudiences([]string{"{{.Oncall}}"}, logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(audiences).To(HaveLen(1))
+
+			endorsement, err := template.New("endorsement").Parse(audiences[0].Endorsement)
+			Expect(err).NotTo(HaveOccurred())
+
+			output := bytes.NewBuffer([]byte{})
+			err = endorsement.Execute(output, struct{}{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(output.String()).To(Equal("You received this message because you are a member of the {{.Oncall}} group."))
+		})
+
+		Context("when an error occurs", func() {
+			Context("when the token loader encounters an error", func() {
+				It("returns the error", func() {
+					tokenLoader.LoadCall.Returns.Error = errors.New("some token error")
+
+					_, err := groups.GenerateAudiences([]string{"security-oncall"}, logger)
+					Expect(err).To(MatchError(errors.New("some token error")))
+				})
+			})
+
+			Context("when the user finder encounters an error", func() {
+				It("returns the error", func() {
+					userFinder.UserIDsBelongingToGroupCall.Returns.Error = errors.New("some user finding error")
+
+					_, err := groups.GenerateAudiences([]string{"security-oncall"}, logger)
+					Expect(err).To(MatchError(errors.New("some user finding error")))
+				})
+			})
+		})
+	})
+})
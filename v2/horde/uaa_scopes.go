@@ -0,0 +1,72 @@
+package horde
+
+import (
+	"fmt"
+
+	"github.com/pivotal-golang/lager"
+)
+
+type scopeUserFinder interface {
+	UserIDsBelongingToScope(token, scope string) (userGUIDs []string, err error)
+}
+
+type UAAScopes struct {
+	userFinder    scopeUserFinder
+	tokenLoader   tokenLoader
+	uaaHost       string
+	defaultScopes []string
+}
+
+func NewUAAScopes(userFinder scopeUserFinder, tokenLoader tokenLoader, uaaHost string, defaultScopes []string) UAAScopes {
+	return UAAScopes{
+		userFinder:    userFinder,
+		tokenLoader:   tokenLoader,
+		uaaHost:       uaaHost,
+		defaultScopes: defaultScopes,
+	}
+}
+
+func (s UAAScopes) GenerateAudiences(scopes []string, logger lager.Logger) ([]Audience, error) {
+	var audiences []Audience
+
+	token, err := s.tokenLoader.Load(s.uaaHost)
+	if err != nil {
+		return audiences, err
+	}
+
+	for _, scope := range scopes {
+		if s.isDefaultScope(scope) {
+			logger.Info("skipping-default-scope", lager.Data{
+				"scope": scope,
+			})
+			continue
+		}
+
+		userGUIDs, err := s.userFinder.UserIDsBelongingToScope(token, scope)
+		if err != nil {
+			return audiences, err
+		}
+
+		var users []User
+		for _, userGUID := range userGUIDs {
+			users = append(users, User{GUID: userGUID})
+		}
+
+		audiences = append(audiences, Audience{
+			Users:       users,
+			Endorsement: fmt.Sprintf("You received this message because you have the %s scope.", scope),
+		})
+	}
+
+	return audiences, nil
+}
+
+func (s UAAScopes) isDefaultScope(scope string) bool {
+	for _, defaultScope := range s.defaultScopes {
+		if scope == defaultScope {
+			return true
+		}
+	}
+
+	return false
+}
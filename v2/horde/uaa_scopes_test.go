@@ -0,0 +1,88 @@
+package horde_test
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v2/horde"
+	"github.com/pivotal-golang/lager"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("uaa scopes audience", func() {
+	var (
+		userFinder  *mocks.FindsUserIDs
+		tokenLoader *mocks.TokenLoader
+		scopes      horde.UAAScopes
+		logger      lager.Logger
+		logStream   *bytes.Buffer
+	)
+
+	BeforeEach(func() {
+		userFinder = mocks.NewFindsUserIDs()
+		userFinder.UserIDsBelongingToScopeCall.Returns.UserIDs = []string{"some-user-guid", "some-other-user-guid"}
+
+		tokenLoader = mocks.NewTokenLoader()
+		tokenLoader.LoadCall.Returns.Token = "token"
+
+		scopes = horde.NewUAAScopes(userFinder, tokenLoader, "https://uaa.example.com", []string{"cloud_controller.read", "openid"})
+
+		logStream = bytes.NewBuffer([]byte{})
+		logger = lager.NewLogger("notifications-whatever")
+		logger.RegisterSink(lager.NewWriterSink(logStream, lager.DEBUG))
+	})
+
+	Describe("GenerateAudiences", func() {
+		It("looks up the users with the scope and wraps them in User objects", func() {
+			audiences, err := scopes.GenerateAudiences([]string{"great.scope"}, logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(audiences).To(Equal([]horde.Audience{
+				{
+					Users: []horde.User{
+						{GUID: "some-user-guid"},
+						{GUID: "some-other-user-guid"},
+					},
+					Endorsement: "You received this message because you have the great.scope scope.",
+				},
+			}))
+
+			Expect(tokenLoader.LoadCall.Receives.UAAHost).To(Equal("https://uaa.example.com"))
+
+			Expect(userFinder.UserIDsBelongingToScopeCall.Receives.Token).To(Equal("token"))
+			Expect(userFinder.UserIDsBelongingToScopeCall.Receives.Scope).To(Equal("great.scope"))
+		})
+
+		Context("when the scope is a default scope", func() {
+			It("skips the scope", func() {
+				audiences, err := scopes.GenerateAudiences([]string{"openid"}, logger)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(audiences).To(BeEmpty())
+
+				Expect(userFinder.UserIDsBelongingToScopeCall.Receives.Scope).To(BeEmpty())
+			})
+		})
+
+		Context("when an error occurs", func() {
+			Context("when the token loader encounters an error", func() {
+				It("returns the error", func() {
+					tokenLoader.LoadCall.Returns.Error = errors.New("some token error")
+
+					_, err := scopes.GenerateAudiences([]string{"great.scope"}, logger)
+					Expect(err).To(MatchError(errors.New("some token error")))
+				})
+			})
+
+			Context("when the user finder encounters an error", func() {
+				It("returns the error", func() {
+					userFinder.UserIDsBelongingToScopeCall.Returns.Error = errors.New("some user finding error")
+
+					_, err := scopes.GenerateAudiences([]string{"great.scope"}, logger)
+					Expect(err).To(MatchError(errors.New("some user finding error")))
+				})
+			})
+		})
+	})
+})
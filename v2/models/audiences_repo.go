@@ -0,0 +1,193 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AudienceDefinition is the JSON document stored with each audience version.
+type AudienceDefinition struct {
+	Users   []string            `json:"users,omitempty"`
+	Spaces  []string            `json:"spaces,omitempty"`
+	Orgs    []string            `json:"orgs,omitempty"`
+	Emails  []string            `json:"emails,omitempty"`
+	Exclude map[string][]string `json:"exclude,omitempty"`
+}
+
+type Audience struct {
+	ID         string    `db:"id"`
+	Name       string    `db:"name"`
+	SenderID   string    `db:"sender_id"`
+	Version    int       `db:"version"`
+	Definition string    `db:"definition"`
+	CreatedAt  time.Time `db:"created_at"`
+	UpdatedAt  time.Time `db:"updated_at"`
+}
+
+// AudienceVersion is an immutable copy of an audience as it was saved. Versions
+// outlive the audience itself so that campaigns pinned to one keep resolving.
+type AudienceVersion struct {
+	AudienceID string    `db:"audience_id"`
+	Version    int       `db:"version"`
+	Name       string    `db:"name"`
+	SenderID   string    `db:"sender_id"`
+	Definition string    `db:"definition"`
+	CreatedAt  time.Time `db:"created_at"`
+}
+
+// AudienceReference formats a campaign audience input that is pinned to a
+// version of a saved audience.
+func AudienceReference(audienceID string, version int) string {
+	return fmt.Sprintf("%s@%d", audienceID, version)
+}
+
+// ParseAudienceReference splits a campaign audience input into the audience ID
+// and the pinned version. The version is 0 when the input is not pinned.
+func ParseAudienceReference(reference string) (string, int) {
+	parts := strings.SplitN(reference, "@", 2)
+	if len(parts) != 2 {
+		return reference, 0
+	}
+
+	version, err := strconv.Atoi(parts[1])
+	if err != nil || version < 1 {
+		return reference, 0
+	}
+
+	return parts[0], version
+}
+
+type AudiencesRepository struct {
+	generateGUID guidGeneratorFunc
+	clock        clock
+}
+
+func NewAudiencesRepository(guidGenerator guidGeneratorFunc, clock clock) AudiencesRepository {
+	return AudiencesRepository{
+		generateGUID: guidGenerator,
+		clock:        clock,
+	}
+}
+
+func (r AudiencesRepository) Insert(conn ConnectionInterface, audience Audience) (Audience, error) {
+	var err error
+	audience.ID, err = r.generateGUID()
+	if err != nil {
+		return Audience{}, err
+	}
+
+	audience.Version = 1
+	audience.CreatedAt = r.clock.Now().UTC().Truncate(time.Second)
+	audience.UpdatedAt = audience.CreatedAt
+
+	err = conn.Insert(&audience)
+	if err != nil {
+		if strings.Contains(err.Error(), "Duplicate entry") {
+			return Audience{}, DuplicateRecordError{fmt.Errorf("Audience with name %q already exists", audience.Name)}
+		}
+
+		return Audience{}, err
+	}
+
+	err = r.insertVersion(conn, audience)
+	if err != nil {
+		return Audience{}, err
+	}
+
+	return audience, nil
+}
+
+// Update saves the audience as the version after the one currently stored. It
+// locks the stored row to read that version, so it must be called within a
+// transaction for concurrent updates to be given distinct versions.
+func (r AudiencesRepository) Update(conn ConnectionInterface, audience Audience) (Audience, error) {
+	current, err := r.GetForUpdate(conn, audience.ID)
+	if err != nil {
+		return Audience{}, err
+	}
+
+	audience.Version = current.Version + 1
+	audience.CreatedAt = current.CreatedAt
+	audience.UpdatedAt = r.clock.Now().UTC().Truncate(time.Second)
+
+	_, err = conn.Update(&audience)
+	if err != nil {
+		if strings.Contains(err.Error(), "Duplicate entry") {
+			err = DuplicateRecordError{fmt.Errorf("Audience with name %q already exists", audience.Name)}
+		}
+		return Audience{}, err
+	}
+
+	err = r.insertVersion(conn, audience)
+	if err != nil {
+		return Audience{}, err
+	}
+
+	return audience, nil
+}
+
+func (r AudiencesRepository) Get(conn ConnectionInterface, audienceID string) (Audience, error) {
+	audience := Audience{}
+	err := conn.SelectOne(&audience, "SELECT * FROM `audiences` WHERE `id` = ?", audienceID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = RecordNotFoundError{fmt.Errorf("Audience with id %q could not be found", audienceID)}
+		}
+		return Audience{}, err
+	}
+
+	return audience, nil
+}
+
+// GetForUpdate reads the audience and locks its row until the end of the
+// transaction that conn belongs to.
+func (r AudiencesRepository) GetForUpdate(conn ConnectionInterface, audienceID string) (Audience, error) {
+	audience := Audience{}
+	err := conn.SelectOne(&audience, "SELECT * FROM `audiences` WHERE `id` = ? FOR UPDATE", audienceID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = RecordNotFoundError{fmt.Errorf("Audience with id %q could not be found", audienceID)}
+		}
+		return Audience{}, err
+	}
+
+	return audience, nil
+}
+
+func (r AudiencesRepository) GetVersion(conn ConnectionInterface, audienceID string, version int) (AudienceVersion, error) {
+	audienceVersion := AudienceVersion{}
+	err := conn.SelectOne(&audienceVersion, "SELECT * FROM `audience_versions` WHERE `audience_id` = ? AND `version` = ?", audienceID, version)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = RecordNotFoundError{fmt.Errorf("Audience with id %q and version %d could not be found", audienceID, version)}
+		}
+		return AudienceVersion{}, err
+	}
+
+	return audienceVersion, nil
+}
+
+func (r AudiencesRepository) List(conn ConnectionInterface, senderID string) ([]Audience, error) {
+	audiences := []Audience{}
+	_, err := conn.Select(&audiences, "SELECT * FROM `audiences` WHERE `sender_id` = ? ORDER BY `name`", senderID)
+	return audiences, err
+}
+
+func (r AudiencesRepository) Delete(conn ConnectionInterface, audience Audience) error {
+	_, err := conn.Delete(&audience)
+	return err
+}
+
+func (r AudiencesRepository) insertVersion(conn ConnectionInterface, audience Audience) error {
+	return conn.Insert(&AudienceVersion{
+		AudienceID: audience.ID,
+		Version:    audience.Version,
+		Name:       audience.Name,
+		SenderID:   audience.SenderID,
+		Definition: audience.Definition,
+		CreatedAt:  audience.UpdatedAt,
+	})
+}
@@ -0,0 +1,242 @@
+package models_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AudiencesRepository", func() {
+	var (
+		repo          models.AudiencesRepository
+		conn          db.ConnectionInterface
+		guidGenerator *mocks.IDGenerator
+		clock         *mocks.Clock
+		now           time.Time
+	)
+
+	BeforeEach(func() {
+		database := db.NewDatabase(sqlDB, db.Config{})
+		helpers.TruncateTables(database)
+
+		guidGenerator = mocks.NewIDGenerator()
+		guidGenerator.GenerateCall.Returns.IDs = []string{"first-random-guid", "second-random-guid"}
+
+		now = time.Now().UTC().Truncate(time.Second)
+		clock = mocks.NewClock()
+		clock.NowCall.Returns.Time = now
+
+		repo = models.NewAudiencesRepository(guidGenerator.Generate, clock)
+		conn = database.Connection()
+	})
+
+	Describe("Insert", func() {
+		It("inserts the record and its first version into the database", func() {
+			audience, err := repo.Insert(conn, models.Audience{
+				Name:       "security-oncall",
+				SenderID:   "some-sender-id",
+				Definition: `{"users":["some-user-guid"]}`,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(audience).To(Equal(models.Audience{
+				ID:         "first-random-guid",
+				Name:       "security-oncall",
+				SenderID:   "some-sender-id",
+				Version:    1,
+				Definition: `{"users":["some-user-guid"]}`,
+				CreatedAt:  now,
+				UpdatedAt:  now,
+			}))
+
+			version, err := repo.GetVersion(conn, "first-random-guid", 1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(version).To(Equal(models.AudienceVersion{
+				AudienceID: "first-random-guid",
+				Version:    1,
+				Name:       "security-oncall",
+				SenderID:   "some-sender-id",
+				Definition: `{"users":["some-user-guid"]}`,
+				CreatedAt:  now,
+			}))
+		})
+
+		Context("failure cases", func() {
+			It("returns a duplicate record error when the name and sender_id are taken", func() {
+				audience := models.Audience{
+					Name:     "security-oncall",
+					SenderID: "some-sender-id",
+				}
+
+				_, err := repo.Insert(conn, audience)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = repo.Insert(conn, audience)
+				Expect(err).To(MatchError(models.DuplicateRecordError{errors.New("Audience with name \"security-oncall\" already exists")}))
+			})
+
+			It("returns an error when the guid generator blows up", func() {
+				guidGenerator.GenerateCall.Returns.Error = errors.New("failed to generate")
+
+				_, err := repo.Insert(conn, models.Audience{})
+				Expect(err).To(MatchError(errors.New("failed to generate")))
+			})
+		})
+	})
+
+	Describe("Update", func() {
+		It("updates the record and keeps the previous version", func() {
+			audience, err := repo.Insert(conn, models.Audience{
+				Name:       "security-oncall",
+				SenderID:   "some-sender-id",
+				Definition: `{"users":["old-user-guid"]}`,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			audience.Definition = `{"users":["new-user-guid"]}`
+			audience, err = repo.Update(conn, audience)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(audience.Version).To(Equal(2))
+
+			audience, err = repo.Get(conn, audience.ID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(audience.Version).To(Equal(2))
+			Expect(audience.Definition).To(Equal(`{"users":["new-user-guid"]}`))
+
+			version, err := repo.GetVersion(conn, audience.ID, 1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(version.Definition).To(Equal(`{"users":["old-user-guid"]}`))
+
+			version, err = repo.GetVersion(conn, audience.ID, 2)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(version.Definition).To(Equal(`{"users":["new-user-guid"]}`))
+		})
+
+		It("saves the version after the stored one, whatever version it is given", func() {
+			audience, err := repo.Insert(conn, models.Audience{
+				Name:     "security-oncall",
+				SenderID: "some-sender-id",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = repo.Update(conn, audience)
+			Expect(err).NotTo(HaveOccurred())
+
+			audience, err = repo.Update(conn, audience)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(audience.Version).To(Equal(3))
+
+			_, err = repo.GetVersion(conn, audience.ID, 3)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("returns a not found error when the audience does not exist", func() {
+			_, err := repo.Update(conn, models.Audience{ID: "missing-audience-id"})
+			Expect(err).To(MatchError(models.RecordNotFoundError{errors.New("Audience with id \"missing-audience-id\" could not be found")}))
+		})
+
+		It("returns a duplicate record error when the name is taken", func() {
+			_, err := repo.Insert(conn, models.Audience{
+				Name:     "security-oncall",
+				SenderID: "some-sender-id",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			audience, err := repo.Insert(conn, models.Audience{
+				Name:     "platform-oncall",
+				SenderID: "some-sender-id",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			audience.Name = "security-oncall"
+			_, err = repo.Update(conn, audience)
+			Expect(err).To(MatchError(models.DuplicateRecordError{errors.New("Audience with name \"security-oncall\" already exists")}))
+		})
+	})
+
+	Describe("Get", func() {
+		It("returns a not found error when the audience does not exist", func() {
+			_, err := repo.Get(conn, "missing-audience-id")
+			Expect(err).To(MatchError(models.RecordNotFoundError{errors.New("Audience with id \"missing-audience-id\" could not be found")}))
+		})
+	})
+
+	Describe("GetForUpdate", func() {
+		It("returns the audience", func() {
+			audience, err := repo.Insert(conn, models.Audience{
+				Name:     "security-oncall",
+				SenderID: "some-sender-id",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			locked, err := repo.GetForUpdate(conn, audience.ID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(locked).To(Equal(audience))
+		})
+
+		It("returns a not found error when the audience does not exist", func() {
+			_, err := repo.GetForUpdate(conn, "missing-audience-id")
+			Expect(err).To(MatchError(models.RecordNotFoundError{errors.New("Audience with id \"missing-audience-id\" could not be found")}))
+		})
+	})
+
+	Describe("GetVersion", func() {
+		It("returns a not found error when the version does not exist", func() {
+			_, err := repo.GetVersion(conn, "missing-audience-id", 3)
+			Expect(err).To(MatchError(models.RecordNotFoundError{errors.New("Audience with id \"missing-audience-id\" and version 3 could not be found")}))
+		})
+	})
+
+	Describe("List", func() {
+		It("returns the audiences belonging to the sender ordered by name", func() {
+			guidGenerator.GenerateCall.Returns.IDs = []string{"first-random-guid", "second-random-guid", "third-random-guid"}
+
+			security, err := repo.Insert(conn, models.Audience{
+				Name:     "security-oncall",
+				SenderID: "some-sender-id",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = repo.Insert(conn, models.Audience{
+				Name:     "security-oncall",
+				SenderID: "other-sender-id",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			platform, err := repo.Insert(conn, models.Audience{
+				Name:     "platform-oncall",
+				SenderID: "some-sender-id",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			audiences, err := repo.List(conn, "some-sender-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(audiences).To(Equal([]models.Audience{platform, security}))
+		})
+	})
+
+	Describe("Delete", func() {
+		It("deletes the audience but keeps its versions", func() {
+			audience, err := repo.Insert(conn, models.Audience{
+				Name:     "security-oncall",
+				SenderID: "some-sender-id",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = repo.Delete(conn, audience)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = repo.Get(conn, audience.ID)
+			Expect(err).To(BeAssignableToTypeOf(models.RecordNotFoundError{}))
+
+			_, err = repo.GetVersion(conn, audience.ID, 1)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,29 @@
+package models
+
+// BulkUnsubscriber is one opt-out from any of the global, v1 kind and v2
+// campaign type unsubscribe tables, as read for an export.
+type BulkUnsubscriber struct {
+	UserGUID       string `db:"user_guid"`
+	CampaignTypeID string `db:"campaign_type_id"`
+	ClientID       string `db:"client_id"`
+	KindID         string `db:"kind_id"`
+	Global         bool   `db:"global"`
+}
+
+type BulkUnsubscribesRepository struct{}
+
+func NewBulkUnsubscribesRepository() BulkUnsubscribesRepository {
+	return BulkUnsubscribesRepository{}
+}
+
+// List returns a page of every global, v1 kind and v2 campaign type
+// unsubscribe, in that order, so that an export can be read in pieces.
+func (r BulkUnsubscribesRepository) List(connection ConnectionInterface, offset, limit int) ([]BulkUnsubscriber, error) {
+	unsubscribers := []BulkUnsubscriber{}
+	_, err := connection.Select(&unsubscribers, "SELECT `user_guid`, `campaign_type_id`, `client_id`, `kind_id`, `global` FROM ("+
+		"SELECT 0 AS `source`, `user_id` AS `user_guid`, '' AS `campaign_type_id`, '' AS `client_id`, '' AS `kind_id`, TRUE AS `global` FROM `global_unsubscribes` "+
+		"UNION ALL SELECT 1, `user_id`, '', `client_id`, `kind_id`, FALSE FROM `unsubscribes` "+
+		"UNION ALL SELECT 2, `user_guid`, `campaign_type_id`, '', '', FALSE FROM `unsubscribers`"+
+		") AS `bulk_unsubscribes` ORDER BY `source`, `user_guid`, `campaign_type_id`, `client_id`, `kind_id` LIMIT ? OFFSET ?", limit, offset)
+	return unsubscribers, err
+}
@@ -0,0 +1,74 @@
+package models_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BulkUnsubscribesRepository", func() {
+	var (
+		repo models.BulkUnsubscribesRepository
+		conn db.ConnectionInterface
+	)
+
+	BeforeEach(func() {
+		database := db.NewDatabase(sqlDB, db.Config{})
+		helpers.TruncateTables(database)
+		conn = database.Connection()
+
+		clock := mocks.NewClock()
+		clock.NowCall.Returns.Time = time.Now()
+
+		Expect(models.NewGlobalUnsubscribesRepository(clock).Insert(conn, "global-user-guid")).To(Succeed())
+		Expect(models.NewKindUnsubscribesRepository(clock).Insert(conn, models.KindUnsubscriber{
+			UserGUID: "kind-user-guid",
+			ClientID: "some-client-id",
+			KindID:   "some-kind-id",
+		})).To(Succeed())
+
+		unsubscribersRepo := models.NewUnsubscribersRepository(func() (string, error) { return "some-id", nil })
+		_, err := unsubscribersRepo.Insert(conn, models.Unsubscriber{
+			UserGUID:       "campaign-type-user-guid",
+			CampaignTypeID: "some-campaign-type-id",
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		repo = models.NewBulkUnsubscribesRepository()
+	})
+
+	Describe("List", func() {
+		It("returns a page of every kind of unsubscribe", func() {
+			unsubscribers, err := repo.List(conn, 0, 10)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(unsubscribers).To(Equal([]models.BulkUnsubscriber{
+				{UserGUID: "global-user-guid", Global: true},
+				{UserGUID: "kind-user-guid", ClientID: "some-client-id", KindID: "some-kind-id"},
+				{UserGUID: "campaign-type-user-guid", CampaignTypeID: "some-campaign-type-id"},
+			}))
+
+			unsubscribers, err = repo.List(conn, 1, 1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(unsubscribers).To(Equal([]models.BulkUnsubscriber{
+				{UserGUID: "kind-user-guid", ClientID: "some-client-id", KindID: "some-kind-id"},
+			}))
+		})
+
+		Context("when an error occurs", func() {
+			It("returns the error", func() {
+				connection := mocks.NewConnection()
+				connection.SelectCall.Returns.Error = errors.New("some connection error")
+
+				_, err := repo.List(connection, 0, 10)
+				Expect(err).To(MatchError(errors.New("some connection error")))
+			})
+		})
+	})
+})
@@ -0,0 +1,89 @@
+package models
+
+import "strings"
+
+const (
+	CampaignFilterIntersect = "intersect"
+	CampaignFilterExclude   = "exclude"
+)
+
+// CampaignFilter records that a recipient belongs to the intersect or exclude
+// block of a campaign. Recipient is the user GUID, or the email address for
+// recipients that only have one.
+type CampaignFilter struct {
+	CampaignID string `db:"campaign_id"`
+	Kind       string `db:"kind"`
+	Recipient  string `db:"recipient"`
+}
+
+// CampaignExclusion records that a recipient was left out of a campaign by
+// its intersect or exclude block.
+type CampaignExclusion struct {
+	CampaignID string `db:"campaign_id"`
+	Recipient  string `db:"recipient"`
+}
+
+type CampaignFiltersRepository struct{}
+
+func NewCampaignFiltersRepository() CampaignFiltersRepository {
+	return CampaignFiltersRepository{}
+}
+
+// Insert adds the recipients to the intersect or exclude block of the
+// campaign. Recipients that are already stored are left alone, so a retried
+// fan-out can store the blocks again.
+func (r CampaignFiltersRepository) Insert(connection ConnectionInterface, campaignID, kind string, recipients []string) error {
+	if len(recipients) == 0 {
+		return nil
+	}
+
+	var args []interface{}
+	for _, recipient := range recipients {
+		args = append(args, campaignID, kind, recipient)
+	}
+
+	values := strings.TrimSuffix(strings.Repeat("(?, ?, ?), ", len(recipients)), ", ")
+	_, err := connection.Exec("INSERT INTO `campaign_filters` (`campaign_id`, `kind`, `recipient`) VALUES "+values+" ON DUPLICATE KEY UPDATE `recipient` = `recipient`", args...)
+
+	return err
+}
+
+// List returns the intersect and exclude block entries stored for the given
+// recipients of the campaign.
+func (r CampaignFiltersRepository) List(connection ConnectionInterface, campaignID string, recipients []string) ([]CampaignFilter, error) {
+	filters := []CampaignFilter{}
+	if len(recipients) == 0 {
+		return filters, nil
+	}
+
+	args := []interface{}{campaignID}
+	for _, recipient := range recipients {
+		args = append(args, recipient)
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(recipients)), ", ")
+	_, err := connection.Select(&filters, "SELECT * FROM `campaign_filters` WHERE `campaign_id` = ? AND `recipient` IN ("+placeholders+")", args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return filters, nil
+}
+
+// RecordExcluded remembers the recipients left out of the campaign. Each
+// recipient is only recorded once, however often the fan-out is retried.
+func (r CampaignFiltersRepository) RecordExcluded(connection ConnectionInterface, campaignID string, recipients []string) error {
+	if len(recipients) == 0 {
+		return nil
+	}
+
+	var args []interface{}
+	for _, recipient := range recipients {
+		args = append(args, campaignID, recipient)
+	}
+
+	values := strings.TrimSuffix(strings.Repeat("(?, ?), ", len(recipients)), ", ")
+	_, err := connection.Exec("INSERT INTO `campaign_exclusions` (`campaign_id`, `recipient`) VALUES "+values+" ON DUPLICATE KEY UPDATE `recipient` = `recipient`", args...)
+
+	return err
+}
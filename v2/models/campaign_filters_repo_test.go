@@ -0,0 +1,92 @@
+package models_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CampaignFiltersRepository", func() {
+	var (
+		repo models.CampaignFiltersRepository
+		conn db.ConnectionInterface
+	)
+
+	BeforeEach(func() {
+		database := db.NewDatabase(sqlDB, db.Config{})
+		helpers.TruncateTables(database)
+		conn = database.Connection()
+
+		repo = models.NewCampaignFiltersRepository()
+	})
+
+	Describe("Insert and List", func() {
+		It("returns the stored entries for the given recipients", func() {
+			Expect(repo.Insert(conn, "some-campaign-id", models.CampaignFilterIntersect, []string{"user-1", "user-2"})).To(Succeed())
+			Expect(repo.Insert(conn, "some-campaign-id", models.CampaignFilterExclude, []string{"user-2", "someone@example.com"})).To(Succeed())
+			Expect(repo.Insert(conn, "other-campaign-id", models.CampaignFilterExclude, []string{"user-1"})).To(Succeed())
+
+			filters, err := repo.List(conn, "some-campaign-id", []string{"user-1", "user-2", "user-3"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(filters).To(ConsistOf([]models.CampaignFilter{
+				{CampaignID: "some-campaign-id", Kind: "intersect", Recipient: "user-1"},
+				{CampaignID: "some-campaign-id", Kind: "intersect", Recipient: "user-2"},
+				{CampaignID: "some-campaign-id", Kind: "exclude", Recipient: "user-2"},
+			}))
+		})
+
+		It("allows the same entries to be stored again", func() {
+			Expect(repo.Insert(conn, "some-campaign-id", models.CampaignFilterExclude, []string{"user-1"})).To(Succeed())
+			Expect(repo.Insert(conn, "some-campaign-id", models.CampaignFilterExclude, []string{"user-1"})).To(Succeed())
+
+			filters, err := repo.List(conn, "some-campaign-id", []string{"user-1"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(filters).To(HaveLen(1))
+		})
+
+		It("does nothing when no recipients are given", func() {
+			Expect(repo.Insert(conn, "some-campaign-id", models.CampaignFilterExclude, nil)).To(Succeed())
+
+			filters, err := repo.List(conn, "some-campaign-id", nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(filters).To(BeEmpty())
+		})
+
+		Context("failure cases", func() {
+			It("returns the error when the database blows up", func() {
+				fakeConnection := mocks.NewConnection()
+				fakeConnection.ExecCall.Returns.Error = errors.New("something bad happened")
+				fakeConnection.SelectCall.Returns.Error = errors.New("something else happened")
+
+				err := repo.Insert(fakeConnection, "some-campaign-id", models.CampaignFilterExclude, []string{"user-1"})
+				Expect(err).To(MatchError(errors.New("something bad happened")))
+
+				_, err = repo.List(fakeConnection, "some-campaign-id", []string{"user-1"})
+				Expect(err).To(MatchError(errors.New("something else happened")))
+			})
+		})
+	})
+
+	Describe("RecordExcluded", func() {
+		It("returns the error when the database blows up", func() {
+			fakeConnection := mocks.NewConnection()
+			fakeConnection.ExecCall.Returns.Error = errors.New("something bad happened")
+
+			err := repo.RecordExcluded(fakeConnection, "some-campaign-id", []string{"user-1"})
+			Expect(err).To(MatchError(errors.New("something bad happened")))
+		})
+
+		It("does nothing when no recipients are given", func() {
+			fakeConnection := mocks.NewConnection()
+			fakeConnection.ExecCall.Returns.Error = errors.New("something bad happened")
+
+			Expect(repo.RecordExcluded(fakeConnection, "some-campaign-id", nil)).To(Succeed())
+		})
+	})
+})
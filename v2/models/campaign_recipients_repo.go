@@ -0,0 +1,44 @@
+package models
+
+import "strings"
+
+// CampaignRecipient records that a message has been queued for a recipient of
+// a campaign. Recipient is the user GUID, or the email address for recipients
+// that only have one.
+type CampaignRecipient struct {
+	CampaignID string `db:"campaign_id"`
+	Recipient  string `db:"recipient"`
+	MessageID  string `db:"message_id"`
+}
+
+type CampaignRecipientsRepository struct{}
+
+func NewCampaignRecipientsRepository() CampaignRecipientsRepository {
+	return CampaignRecipientsRepository{}
+}
+
+func (r CampaignRecipientsRepository) Insert(connection ConnectionInterface, recipient CampaignRecipient) error {
+	return connection.Insert(&recipient)
+}
+
+// ListExisting returns the subset of recipients that already have a message
+// queued for the campaign.
+func (r CampaignRecipientsRepository) ListExisting(connection ConnectionInterface, campaignID string, recipients []string) ([]string, error) {
+	existing := []string{}
+	if len(recipients) == 0 {
+		return existing, nil
+	}
+
+	args := []interface{}{campaignID}
+	for _, recipient := range recipients {
+		args = append(args, recipient)
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(recipients)), ", ")
+	_, err := connection.Select(&existing, "SELECT `recipient` FROM `campaign_recipients` WHERE `campaign_id` = ? AND `recipient` IN ("+placeholders+")", args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return existing, nil
+}
@@ -0,0 +1,67 @@
+package models_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CampaignRecipientsRepository", func() {
+	var (
+		repo models.CampaignRecipientsRepository
+		conn db.ConnectionInterface
+	)
+
+	BeforeEach(func() {
+		database := db.NewDatabase(sqlDB, db.Config{})
+		helpers.TruncateTables(database)
+		conn = database.Connection()
+
+		repo = models.NewCampaignRecipientsRepository()
+	})
+
+	Describe("ListExisting", func() {
+		BeforeEach(func() {
+			Expect(repo.Insert(conn, models.CampaignRecipient{CampaignID: "some-campaign-id", Recipient: "user-1", MessageID: "message-1"})).To(Succeed())
+			Expect(repo.Insert(conn, models.CampaignRecipient{CampaignID: "some-campaign-id", Recipient: "someone@example.com", MessageID: "message-2"})).To(Succeed())
+			Expect(repo.Insert(conn, models.CampaignRecipient{CampaignID: "other-campaign-id", Recipient: "user-2", MessageID: "message-3"})).To(Succeed())
+		})
+
+		It("returns the recipients that already have a message for the campaign", func() {
+			existing, err := repo.ListExisting(conn, "some-campaign-id", []string{"user-1", "user-2", "someone@example.com"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(existing).To(ConsistOf([]string{"user-1", "someone@example.com"}))
+		})
+
+		It("returns an empty list when no recipients are given", func() {
+			existing, err := repo.ListExisting(conn, "some-campaign-id", nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(existing).To(BeEmpty())
+		})
+
+		Context("failure cases", func() {
+			It("returns the error when the database blows up", func() {
+				fakeConnection := mocks.NewConnection()
+				fakeConnection.SelectCall.Returns.Error = errors.New("something bad happened")
+
+				_, err := repo.ListExisting(fakeConnection, "some-campaign-id", []string{"user-1"})
+				Expect(err).To(MatchError(errors.New("something bad happened")))
+			})
+		})
+	})
+
+	Describe("Insert", func() {
+		It("does not allow a recipient to be recorded twice for a campaign", func() {
+			Expect(repo.Insert(conn, models.CampaignRecipient{CampaignID: "some-campaign-id", Recipient: "user-1", MessageID: "message-1"})).To(Succeed())
+
+			err := repo.Insert(conn, models.CampaignRecipient{CampaignID: "some-campaign-id", Recipient: "user-1", MessageID: "message-2"})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -64,6 +64,18 @@ func (r CampaignTypesRepository) List(connection ConnectionInterface, senderID s
 	return campaignTypeList, err
 }
 
+func (r CampaignTypesRepository) ListByTemplateID(connection ConnectionInterface, templateID, clientID string) ([]CampaignType, error) {
+	campaignTypeList := []CampaignType{}
+	_, err := connection.Select(&campaignTypeList, "SELECT `campaign_types`.* FROM `campaign_types` INNER JOIN `senders` ON `campaign_types`.`sender_id` = `senders`.`id` WHERE `campaign_types`.`template_id` = ? AND `senders`.`client_id` = ? ORDER BY `campaign_types`.`name`", templateID, clientID)
+	return campaignTypeList, err
+}
+
+func (r CampaignTypesRepository) ListNonCritical(connection ConnectionInterface) ([]CampaignType, error) {
+	campaignTypeList := []CampaignType{}
+	_, err := connection.Select(&campaignTypeList, "SELECT * FROM `campaign_types` WHERE `critical` = false ORDER BY `sender_id`, `name`")
+	return campaignTypeList, err
+}
+
 func (r CampaignTypesRepository) Get(connection ConnectionInterface, campaignTypeID string) (CampaignType, error) {
 	campaignType := CampaignType{}
 	err := connection.SelectOne(&campaignType, "SELECT * FROM `campaign_types` WHERE `id` = ?", campaignTypeID)
@@ -153,6 +153,95 @@ var _ = Describe("CampaignTypesRepo", func() {
 		})
 	})
 
+	Describe("ListNonCritical", func() {
+		It("fetches the campaign types that can be unsubscribed from", func() {
+			_, err := repo.Insert(conn, models.CampaignType{
+				ID:       "critical-campaign-type-id",
+				Name:     "critical-campaign-type",
+				Critical: true,
+				SenderID: "some-sender-id",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = repo.Insert(conn, models.CampaignType{
+				ID:       "second-campaign-type-id",
+				Name:     "second-campaign-type",
+				SenderID: "some-sender-id",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = repo.Insert(conn, models.CampaignType{
+				ID:       "first-campaign-type-id",
+				Name:     "first-campaign-type",
+				SenderID: "some-sender-id",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			campaignTypeList, err := repo.ListNonCritical(conn)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(campaignTypeList).To(HaveLen(2))
+			Expect(campaignTypeList[0].ID).To(Equal("first-campaign-type-id"))
+			Expect(campaignTypeList[1].ID).To(Equal("second-campaign-type-id"))
+		})
+
+		Context("failure cases", func() {
+			It("returns errors", func() {
+				conn := mocks.NewConnection()
+				conn.SelectCall.Returns.Error = errors.New("BOOM!")
+				_, err := repo.ListNonCritical(conn)
+				Expect(err).To(MatchError("BOOM!"))
+			})
+		})
+	})
+
+	Describe("ListByTemplateID", func() {
+		It("fetches the campaign types of the client that use the template", func() {
+			senderGUIDs := mocks.NewIDGenerator()
+			senderGUIDs.GenerateCall.Returns.IDs = []string{"some-sender-id", "other-sender-id"}
+
+			sendersRepo := models.NewSendersRepository(senderGUIDs.Generate)
+			sender, err := sendersRepo.Insert(conn, models.Sender{
+				Name:     "some-sender",
+				ClientID: "some-client-id",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			otherSender, err := sendersRepo.Insert(conn, models.Sender{
+				Name:     "other-sender",
+				ClientID: "other-client-id",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			campaignType, err := repo.Insert(conn, models.CampaignType{
+				Name:       "campaign-type-one",
+				TemplateID: "some-template-id",
+				SenderID:   sender.ID,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = repo.Insert(conn, models.CampaignType{
+				Name:       "campaign-type-two",
+				TemplateID: "some-template-id",
+				SenderID:   otherSender.ID,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			campaignTypes, err := repo.ListByTemplateID(conn, "some-template-id", "some-client-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(campaignTypes).To(HaveLen(1))
+			Expect(campaignTypes[0].ID).To(Equal(campaignType.ID))
+		})
+
+		Context("failure cases", func() {
+			It("returns errors", func() {
+				conn := mocks.NewConnection()
+				conn.SelectCall.Returns.Error = errors.New("BOOM!")
+				_, err := repo.ListByTemplateID(conn, "some-template-id", "some-client-id")
+				Expect(err).To(MatchError("BOOM!"))
+			})
+		})
+	})
+
 	Describe("Get", func() {
 		It("fetches a record from the database", func() {
 			campaignType, err := repo.Insert(conn, models.CampaignType{
@@ -14,6 +14,7 @@ type Campaign struct {
 	CampaignTypeID string         `db:"campaign_type_id"`
 	Text           string         `db:"text"`
 	HTML           string         `db:"html"`
+	Markdown       string         `db:"markdown"`
 	Subject        string         `db:"subject"`
 	TemplateID     string         `db:"template_id"`
 	ReplyTo        string         `db:"reply_to"`
@@ -25,6 +26,14 @@ type Campaign struct {
 	FailedMessages int            `db:"failed_messages"`
 	StartTime      time.Time      `db:"start_time"`
 	CompletedTime  mysql.NullTime `db:"completed_time"`
+	BusinessHours  bool           `db:"local_business_hours"`
+	Exclude        string         `db:"exclude"`
+	Intersect      string         `db:"intersect"`
+	ExcludedCount  int            `db:"excluded_count"`
+	FanoutProgress int            `db:"fanout_progress"`
+
+	FanoutStartedAt   mysql.NullTime `db:"fanout_started_at"`
+	FanoutCompletedAt mysql.NullTime `db:"fanout_completed_at"`
 }
 
 type CampaignsRepository struct {
@@ -73,6 +82,34 @@ func (r CampaignsRepository) Get(conn ConnectionInterface, campaignID string) (C
 	return campaign, nil
 }
 
+// UpdateExcludedCount sets the excluded count of the campaign to the number
+// of distinct recipients recorded as excluded from it.
+func (r CampaignsRepository) UpdateExcludedCount(conn ConnectionInterface, campaignID string) error {
+	_, err := conn.Exec("UPDATE `campaigns` SET `excluded_count` = (SELECT COUNT(*) FROM `campaign_exclusions` WHERE `campaign_id` = ?) WHERE `id` = ?", campaignID, campaignID)
+
+	return err
+}
+
+func (r CampaignsRepository) SetFanoutProgress(conn ConnectionInterface, campaignID string, progress int) error {
+	_, err := conn.Exec("UPDATE `campaigns` SET `fanout_progress` = ? WHERE `id` = ?", progress, campaignID)
+
+	return err
+}
+
+// StartFanout records when the fan-out first started. Retried jobs keep the
+// original start time.
+func (r CampaignsRepository) StartFanout(conn ConnectionInterface, campaignID string, startedAt time.Time) error {
+	_, err := conn.Exec("UPDATE `campaigns` SET `fanout_started_at` = COALESCE(`fanout_started_at`, ?) WHERE `id` = ?", startedAt.UTC(), campaignID)
+
+	return err
+}
+
+func (r CampaignsRepository) CompleteFanout(conn ConnectionInterface, campaignID string, completedAt time.Time) error {
+	_, err := conn.Exec("UPDATE `campaigns` SET `fanout_completed_at` = ? WHERE `id` = ?", completedAt.UTC(), campaignID)
+
+	return err
+}
+
 func (r CampaignsRepository) ListSendingCampaigns(conn ConnectionInterface) ([]Campaign, error) {
 	campaignList := []Campaign{}
 
@@ -80,3 +117,11 @@ func (r CampaignsRepository) ListSendingCampaigns(conn ConnectionInterface) ([]C
 
 	return campaignList, err
 }
+
+func (r CampaignsRepository) ListByTemplateID(conn ConnectionInterface, templateID, clientID string, since time.Time) ([]Campaign, error) {
+	campaignList := []Campaign{}
+
+	_, err := conn.Select(&campaignList, "SELECT `campaigns`.* FROM `campaigns` INNER JOIN `senders` ON `campaigns`.`sender_id` = `senders`.`id` WHERE `campaigns`.`template_id` = ? AND `senders`.`client_id` = ? AND (`campaigns`.`status` != \"completed\" OR `campaigns`.`start_time` >= ?) ORDER BY `campaigns`.`start_time` DESC", templateID, clientID, since)
+
+	return campaignList, err
+}
@@ -121,6 +121,122 @@ var _ = Describe("CampaignsRepository", func() {
 		})
 	})
 
+	Describe("UpdateExcludedCount", func() {
+		It("counts the distinct recipients excluded from the campaign", func() {
+			campaign, err := repo.Insert(connection, models.Campaign{
+				StartTime: time.Now().UTC().Truncate(time.Second),
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			filtersRepo := models.NewCampaignFiltersRepository()
+			Expect(filtersRepo.RecordExcluded(connection, campaign.ID, []string{"user-1", "user-2"})).To(Succeed())
+			Expect(filtersRepo.RecordExcluded(connection, campaign.ID, []string{"user-2", "someone@example.com"})).To(Succeed())
+			Expect(filtersRepo.RecordExcluded(connection, "other-campaign-id", []string{"user-3"})).To(Succeed())
+
+			err = repo.UpdateExcludedCount(connection, campaign.ID)
+			Expect(err).NotTo(HaveOccurred())
+
+			retrievedCampaign, err := repo.Get(connection, campaign.ID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(retrievedCampaign.ExcludedCount).To(Equal(3))
+		})
+
+		Context("failure cases", func() {
+			It("returns the error when the database blows up", func() {
+				fakeConnection := mocks.NewConnection()
+				fakeConnection.ExecCall.Returns.Error = errors.New("something bad happened")
+
+				err := repo.UpdateExcludedCount(fakeConnection, "some-campaign-id")
+				Expect(err).To(MatchError(errors.New("something bad happened")))
+			})
+		})
+	})
+
+	Describe("SetFanoutProgress", func() {
+		It("records how many audience members have been fanned out", func() {
+			campaign, err := repo.Insert(connection, models.Campaign{
+				StartTime: time.Now().UTC().Truncate(time.Second),
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = repo.SetFanoutProgress(connection, campaign.ID, 3)
+			Expect(err).NotTo(HaveOccurred())
+
+			retrievedCampaign, err := repo.Get(connection, campaign.ID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(retrievedCampaign.FanoutProgress).To(Equal(3))
+		})
+
+		Context("failure cases", func() {
+			It("returns the error when the database blows up", func() {
+				fakeConnection := mocks.NewConnection()
+				fakeConnection.ExecCall.Returns.Error = errors.New("something bad happened")
+
+				err := repo.SetFanoutProgress(fakeConnection, "some-campaign-id", 3)
+				Expect(err).To(MatchError(errors.New("something bad happened")))
+			})
+		})
+	})
+
+	Describe("StartFanout", func() {
+		It("records when the fan-out first started", func() {
+			campaign, err := repo.Insert(connection, models.Campaign{
+				StartTime: time.Now().UTC().Truncate(time.Second),
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			startedAt := time.Now().UTC().Truncate(time.Second)
+			err = repo.StartFanout(connection, campaign.ID, startedAt)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = repo.StartFanout(connection, campaign.ID, startedAt.Add(time.Minute))
+			Expect(err).NotTo(HaveOccurred())
+
+			retrievedCampaign, err := repo.Get(connection, campaign.ID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(retrievedCampaign.FanoutStartedAt.Valid).To(BeTrue())
+			Expect(retrievedCampaign.FanoutStartedAt.Time).To(BeTemporally("==", startedAt))
+		})
+
+		Context("failure cases", func() {
+			It("returns the error when the database blows up", func() {
+				fakeConnection := mocks.NewConnection()
+				fakeConnection.ExecCall.Returns.Error = errors.New("something bad happened")
+
+				err := repo.StartFanout(fakeConnection, "some-campaign-id", time.Now())
+				Expect(err).To(MatchError(errors.New("something bad happened")))
+			})
+		})
+	})
+
+	Describe("CompleteFanout", func() {
+		It("records when the fan-out completed", func() {
+			campaign, err := repo.Insert(connection, models.Campaign{
+				StartTime: time.Now().UTC().Truncate(time.Second),
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			completedAt := time.Now().UTC().Truncate(time.Second)
+			err = repo.CompleteFanout(connection, campaign.ID, completedAt)
+			Expect(err).NotTo(HaveOccurred())
+
+			retrievedCampaign, err := repo.Get(connection, campaign.ID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(retrievedCampaign.FanoutCompletedAt.Valid).To(BeTrue())
+			Expect(retrievedCampaign.FanoutCompletedAt.Time).To(BeTemporally("==", completedAt))
+		})
+
+		Context("failure cases", func() {
+			It("returns the error when the database blows up", func() {
+				fakeConnection := mocks.NewConnection()
+				fakeConnection.ExecCall.Returns.Error = errors.New("something bad happened")
+
+				err := repo.CompleteFanout(fakeConnection, "some-campaign-id", time.Now())
+				Expect(err).To(MatchError(errors.New("something bad happened")))
+			})
+		})
+	})
+
 	Describe("ListSendingCampaigns", func() {
 		var campaign models.Campaign
 
@@ -156,4 +272,78 @@ var _ = Describe("CampaignsRepository", func() {
 			})
 		})
 	})
+
+	Describe("ListByTemplateID", func() {
+		var sendingCampaign, recentCampaign models.Campaign
+
+		BeforeEach(func() {
+			now := time.Now().UTC().Truncate(time.Second)
+			guidGenerator.GenerateCall.Returns.IDs = []string{"sending-campaign", "recent-campaign", "old-campaign", "other-campaign", "some-sender-id"}
+
+			sendersRepo := models.NewSendersRepository(guidGenerator.Generate)
+
+			var err error
+			sendingCampaign, err = repo.Insert(connection, models.Campaign{
+				TemplateID: "some-template-id",
+				SenderID:   "some-sender-id",
+				Status:     "sending",
+				StartTime:  now.Add(-90 * 24 * time.Hour),
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			recentCampaign, err = repo.Insert(connection, models.Campaign{
+				TemplateID: "some-template-id",
+				SenderID:   "some-sender-id",
+				Status:     "completed",
+				StartTime:  now.Add(-24 * time.Hour),
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = repo.Insert(connection, models.Campaign{
+				TemplateID: "some-template-id",
+				SenderID:   "some-sender-id",
+				Status:     "completed",
+				StartTime:  now.Add(-90 * 24 * time.Hour),
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = repo.Insert(connection, models.Campaign{
+				TemplateID: "other-template-id",
+				SenderID:   "some-sender-id",
+				Status:     "sending",
+				StartTime:  now,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = sendersRepo.Insert(connection, models.Sender{
+				Name:     "some-sender",
+				ClientID: "some-client-id",
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("returns unfinished and recently started campaigns using the template", func() {
+			campaigns, err := repo.ListByTemplateID(connection, "some-template-id", "some-client-id", time.Now().Add(-30*24*time.Hour))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(campaigns).To(HaveLen(2))
+			Expect(campaigns[0].ID).To(Equal(recentCampaign.ID))
+			Expect(campaigns[1].ID).To(Equal(sendingCampaign.ID))
+		})
+
+		It("does not return campaigns belonging to other clients", func() {
+			campaigns, err := repo.ListByTemplateID(connection, "some-template-id", "other-client-id", time.Now().Add(-30*24*time.Hour))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(campaigns).To(BeEmpty())
+		})
+
+		Context("failure cases", func() {
+			It("returns an unknown error the database takes a dump", func() {
+				fakeConnection := mocks.NewConnection()
+				fakeConnection.SelectCall.Returns.Error = errors.New("something bad happened")
+
+				_, err := repo.ListByTemplateID(fakeConnection, "some-template-id", "some-client-id", time.Now())
+				Expect(err).To(MatchError(errors.New("something bad happened")))
+			})
+		})
+	})
 })
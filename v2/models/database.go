@@ -33,5 +33,18 @@ func Setup(database *db.DB) {
 	database.TableMap().AddTableWithName(Template{}, "v2_templates").SetKeys(false, "ID").SetUniqueTogether("name", "client_id")
 	database.TableMap().AddTableWithName(Campaign{}, "campaigns").SetKeys(false, "ID")
 	database.TableMap().AddTableWithName(Message{}, "messages").SetKeys(false, "ID")
+	database.TableMap().AddTableWithName(MessageTiming{}, "message_timings").SetKeys(false, "MessageID")
 	database.TableMap().AddTableWithName(Unsubscriber{}, "unsubscribers").SetKeys(false, "ID").SetUniqueTogether("campaign_type_id", "user_guid")
+	database.TableMap().AddTableWithName(Partial{}, "partials").SetKeys(false, "ID").SetUniqueTogether("name", "client_id")
+	database.TableMap().AddTableWithName(EmailUnsubscriber{}, "email_unsubscribes").SetKeys(true, "Primary").SetUniqueTogether("email", "client_id", "campaign_type_id")
+	database.TableMap().AddTableWithName(DigestPreference{}, "digest_preferences").SetKeys(false, "UserGUID", "ClientID", "CampaignTypeID")
+	database.TableMap().AddTableWithName(Digest{}, "digests").SetKeys(false, "ID")
+	database.TableMap().AddTableWithName(QuietHours{}, "quiet_hours").SetKeys(false, "UserGUID")
+	database.TableMap().AddTableWithName(PreferenceChange{}, "preference_changes").SetKeys(true, "ID")
+	database.TableMap().AddTableWithName(CampaignRecipient{}, "campaign_recipients").SetKeys(false, "CampaignID", "Recipient")
+	database.TableMap().AddTableWithName(CampaignFilter{}, "campaign_filters").SetKeys(false, "CampaignID", "Recipient", "Kind")
+	database.TableMap().AddTableWithName(CampaignExclusion{}, "campaign_exclusions").SetKeys(false, "CampaignID", "Recipient")
+	database.TableMap().AddTableWithName(Audience{}, "audiences").SetKeys(false, "ID").SetUniqueTogether("name", "sender_id")
+	database.TableMap().AddTableWithName(AudienceVersion{}, "audience_versions").SetKeys(false, "AudienceID", "Version")
+	database.TableMap().AddTableWithName(Lease{}, "leases").SetKeys(false, "Name")
 }
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"database/sql"
+)
+
+const (
+	DigestImmediate = "immediate"
+	DigestHourly    = "hourly"
+	DigestDaily     = "daily"
+)
+
+func ValidDigestFrequency(frequency string) bool {
+	switch frequency {
+	case DigestImmediate, DigestHourly, DigestDaily:
+		return true
+	default:
+		return false
+	}
+}
+
+// DigestPreference records how often a user wants to receive a kind or
+// campaign type. For v1 notifications the campaign type ID is the kind ID.
+// Users without a preference receive notifications immediately.
+type DigestPreference struct {
+	UserGUID       string `db:"user_guid"`
+	ClientID       string `db:"client_id"`
+	CampaignTypeID string `db:"campaign_type_id"`
+	Frequency      string `db:"frequency"`
+}
+
+type DigestPreferencesRepository struct{}
+
+func NewDigestPreferencesRepository() DigestPreferencesRepository {
+	return DigestPreferencesRepository{}
+}
+
+func (r DigestPreferencesRepository) Set(connection ConnectionInterface, preference DigestPreference) error {
+	if preference.Frequency == DigestImmediate {
+		_, err := connection.Exec("DELETE FROM `digest_preferences` WHERE `user_guid` = ? AND `client_id` = ? AND `campaign_type_id` = ?",
+			preference.UserGUID, preference.ClientID, preference.CampaignTypeID)
+		return err
+	}
+
+	_, err := connection.Exec("INSERT INTO `digest_preferences` (`user_guid`, `client_id`, `campaign_type_id`, `frequency`) VALUES (?, ?, ?, ?) ON DUPLICATE KEY UPDATE `frequency` = VALUES(`frequency`)",
+		preference.UserGUID, preference.ClientID, preference.CampaignTypeID, preference.Frequency)
+	return err
+}
+
+func (r DigestPreferencesRepository) Get(connection ConnectionInterface, userGUID, clientID, campaignTypeID string) (string, error) {
+	preference := DigestPreference{}
+	err := connection.SelectOne(&preference, "SELECT * FROM `digest_preferences` WHERE `user_guid` = ? AND `client_id` = ? AND `campaign_type_id` = ?", userGUID, clientID, campaignTypeID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return DigestImmediate, nil
+		}
+		return "", err
+	}
+
+	return preference.Frequency, nil
+}
+
+func (r DigestPreferencesRepository) ListByUserGUID(connection ConnectionInterface, userGUID string) ([]DigestPreference, error) {
+	preferences := []DigestPreference{}
+	_, err := connection.Select(&preferences, "SELECT * FROM `digest_preferences` WHERE `user_guid` = ?", userGUID)
+	return preferences, err
+}
@@ -0,0 +1,116 @@
+package models_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DigestPreferencesRepository", func() {
+	var (
+		repo models.DigestPreferencesRepository
+		conn db.ConnectionInterface
+	)
+
+	BeforeEach(func() {
+		database := db.NewDatabase(sqlDB, db.Config{})
+		helpers.TruncateTables(database)
+		conn = database.Connection()
+
+		repo = models.NewDigestPreferencesRepository()
+	})
+
+	Describe("Set", func() {
+		It("stores the frequency for the user and campaign type", func() {
+			err := repo.Set(conn, models.DigestPreference{
+				UserGUID:       "some-user-guid",
+				ClientID:       "some-client-id",
+				CampaignTypeID: "some-campaign-type-id",
+				Frequency:      models.DigestHourly,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			frequency, err := repo.Get(conn, "some-user-guid", "some-client-id", "some-campaign-type-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(frequency).To(Equal(models.DigestHourly))
+		})
+
+		It("replaces an existing frequency", func() {
+			preference := models.DigestPreference{
+				UserGUID:       "some-user-guid",
+				ClientID:       "some-client-id",
+				CampaignTypeID: "some-campaign-type-id",
+				Frequency:      models.DigestHourly,
+			}
+			Expect(repo.Set(conn, preference)).To(Succeed())
+
+			preference.Frequency = models.DigestDaily
+			Expect(repo.Set(conn, preference)).To(Succeed())
+
+			preferences, err := repo.ListByUserGUID(conn, "some-user-guid")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(preferences).To(Equal([]models.DigestPreference{preference}))
+		})
+
+		It("removes the preference when set back to immediate", func() {
+			preference := models.DigestPreference{
+				UserGUID:       "some-user-guid",
+				ClientID:       "some-client-id",
+				CampaignTypeID: "some-campaign-type-id",
+				Frequency:      models.DigestDaily,
+			}
+			Expect(repo.Set(conn, preference)).To(Succeed())
+
+			preference.Frequency = models.DigestImmediate
+			Expect(repo.Set(conn, preference)).To(Succeed())
+
+			preferences, err := repo.ListByUserGUID(conn, "some-user-guid")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(preferences).To(BeEmpty())
+		})
+
+		Context("when an error occurs", func() {
+			It("returns the error", func() {
+				connection := mocks.NewConnection()
+				connection.ExecCall.Returns.Error = errors.New("some connection error")
+
+				err := repo.Set(connection, models.DigestPreference{Frequency: models.DigestDaily})
+				Expect(err).To(MatchError(errors.New("some connection error")))
+			})
+		})
+	})
+
+	Describe("Get", func() {
+		It("defaults to immediate", func() {
+			frequency, err := repo.Get(conn, "some-user-guid", "some-client-id", "some-campaign-type-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(frequency).To(Equal(models.DigestImmediate))
+		})
+
+		Context("when an error occurs", func() {
+			It("returns the error", func() {
+				connection := mocks.NewConnection()
+				connection.SelectOneCall.Returns.Error = errors.New("some connection error")
+
+				_, err := repo.Get(connection, "some-user-guid", "some-client-id", "some-campaign-type-id")
+				Expect(err).To(MatchError(errors.New("some connection error")))
+			})
+		})
+	})
+
+	Describe("ValidDigestFrequency", func() {
+		It("accepts only the known frequencies", func() {
+			Expect(models.ValidDigestFrequency("immediate")).To(BeTrue())
+			Expect(models.ValidDigestFrequency("hourly")).To(BeTrue())
+			Expect(models.ValidDigestFrequency("daily")).To(BeTrue())
+			Expect(models.ValidDigestFrequency("weekly")).To(BeFalse())
+			Expect(models.ValidDigestFrequency("")).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+// Digest is a notification held back from a user who asked for it to be
+// batched into an hourly or daily summary.
+type Digest struct {
+	ID             string    `db:"id"`
+	MessageID      string    `db:"message_id"`
+	CampaignID     string    `db:"campaign_id"`
+	UserGUID       string    `db:"user_guid"`
+	Email          string    `db:"email"`
+	ClientID       string    `db:"client_id"`
+	CampaignTypeID string    `db:"campaign_type_id"`
+	Frequency      string    `db:"frequency"`
+	Subject        string    `db:"subject"`
+	Text           string    `db:"text"`
+	HTML           string    `db:"html"`
+	CreatedAt      time.Time `db:"created_at"`
+}
+
+type DigestsRepository struct {
+	clock        clock
+	generateGUID guidGeneratorFunc
+}
+
+func NewDigestsRepository(clock clock, guidGenerator guidGeneratorFunc) DigestsRepository {
+	return DigestsRepository{
+		clock:        clock,
+		generateGUID: guidGenerator,
+	}
+}
+
+func (r DigestsRepository) Insert(connection ConnectionInterface, digest Digest) (Digest, error) {
+	var err error
+	digest.ID, err = r.generateGUID()
+	if err != nil {
+		return Digest{}, err
+	}
+
+	digest.CreatedAt = r.clock.Now().UTC().Truncate(time.Second)
+
+	err = connection.Insert(&digest)
+	if err != nil {
+		return Digest{}, err
+	}
+
+	return digest, nil
+}
+
+// ListDue returns every held notification for the users whose oldest held
+// notification of the given frequency was created at or before the threshold,
+// ordered by user and then by age.
+func (r DigestsRepository) ListDue(connection ConnectionInterface, frequency string, threshold time.Time) ([]Digest, error) {
+	digests := []Digest{}
+	_, err := connection.Select(&digests, "SELECT * FROM `digests` WHERE `frequency` = ? AND `user_guid` IN (SELECT `user_guid` FROM `digests` WHERE `frequency` = ? AND `created_at` <= ?) ORDER BY `user_guid`, `created_at`, `id`",
+		frequency, frequency, threshold.UTC())
+	return digests, err
+}
+
+func (r DigestsRepository) Delete(connection ConnectionInterface, digest Digest) error {
+	_, err := connection.Delete(&digest)
+	return err
+}
@@ -0,0 +1,108 @@
+package models_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DigestsRepository", func() {
+	var (
+		repo          models.DigestsRepository
+		conn          db.ConnectionInterface
+		clock         *mocks.Clock
+		guidGenerator *mocks.IDGenerator
+		now           time.Time
+	)
+
+	BeforeEach(func() {
+		database := db.NewDatabase(sqlDB, db.Config{})
+		helpers.TruncateTables(database)
+		conn = database.Connection()
+
+		now = time.Now().UTC().Truncate(time.Second)
+		clock = mocks.NewClock()
+		clock.NowCall.Returns.Time = now
+
+		guidGenerator = mocks.NewIDGenerator()
+		guidGenerator.GenerateCall.Returns.IDs = []string{"first-digest-id", "second-digest-id", "third-digest-id"}
+
+		repo = models.NewDigestsRepository(clock, guidGenerator.Generate)
+	})
+
+	Describe("Insert", func() {
+		It("returns the inserted record", func() {
+			digest, err := repo.Insert(conn, models.Digest{
+				MessageID:      "some-message-id",
+				UserGUID:       "some-user-guid",
+				Email:          "user@example.com",
+				ClientID:       "some-client-id",
+				CampaignTypeID: "some-campaign-type-id",
+				Frequency:      models.DigestHourly,
+				Subject:        "some-subject",
+				Text:           "some-text",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(digest.ID).To(Equal("first-digest-id"))
+			Expect(digest.CreatedAt).To(Equal(now))
+		})
+
+		Context("when the guid generator errors", func() {
+			It("returns the error", func() {
+				guidGenerator.GenerateCall.Returns.Error = errors.New("some-guid-error")
+
+				_, err := repo.Insert(conn, models.Digest{})
+				Expect(err).To(MatchError(errors.New("some-guid-error")))
+			})
+		})
+	})
+
+	Describe("ListDue", func() {
+		BeforeEach(func() {
+			clock.NowCall.Returns.Time = now.Add(-2 * time.Hour)
+			_, err := repo.Insert(conn, models.Digest{MessageID: "old-message", UserGUID: "user-1", Frequency: models.DigestHourly})
+			Expect(err).NotTo(HaveOccurred())
+
+			clock.NowCall.Returns.Time = now
+			_, err = repo.Insert(conn, models.Digest{MessageID: "new-message", UserGUID: "user-1", Frequency: models.DigestHourly})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = repo.Insert(conn, models.Digest{MessageID: "other-user-message", UserGUID: "user-2", Frequency: models.DigestHourly})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("returns every held notification for users with one older than the threshold", func() {
+			digests, err := repo.ListDue(conn, models.DigestHourly, now.Add(-1*time.Hour))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(digests).To(HaveLen(2))
+			Expect(digests[0].MessageID).To(Equal("old-message"))
+			Expect(digests[1].MessageID).To(Equal("new-message"))
+		})
+
+		It("ignores other frequencies", func() {
+			digests, err := repo.ListDue(conn, models.DigestDaily, now)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(digests).To(BeEmpty())
+		})
+	})
+
+	Describe("Delete", func() {
+		It("removes the held notification", func() {
+			digest, err := repo.Insert(conn, models.Digest{MessageID: "some-message", UserGUID: "user-1", Frequency: models.DigestDaily})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(repo.Delete(conn, digest)).To(Succeed())
+
+			digests, err := repo.ListDue(conn, models.DigestDaily, now)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(digests).To(BeEmpty())
+		})
+	})
+})
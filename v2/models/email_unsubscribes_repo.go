@@ -0,0 +1,52 @@
+package models
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// EmailUnsubscriber rows record opt-outs for recipients that were addressed
+// by email rather than by UAA user. For v1 notifications the campaign type ID
+// is the kind ID.
+type EmailUnsubscriber struct {
+	Primary        int       `db:"primary"`
+	Email          string    `db:"email"`
+	ClientID       string    `db:"client_id"`
+	CampaignTypeID string    `db:"campaign_type_id"`
+	CreatedAt      time.Time `db:"created_at"`
+}
+
+type EmailUnsubscribesRepository struct {
+	clock clock
+}
+
+func NewEmailUnsubscribesRepository(clock clock) EmailUnsubscribesRepository {
+	return EmailUnsubscribesRepository{
+		clock: clock,
+	}
+}
+
+func (r EmailUnsubscribesRepository) Insert(connection ConnectionInterface, email, clientID, campaignTypeID string) error {
+	_, err := connection.Exec("INSERT INTO `email_unsubscribes` (`email`, `client_id`, `campaign_type_id`, `created_at`) VALUES (?, ?, ?, ?) ON DUPLICATE KEY UPDATE `email` = `email`",
+		normalizeEmail(email), clientID, campaignTypeID, r.clock.Now().UTC().Truncate(time.Second))
+	return err
+}
+
+func (r EmailUnsubscribesRepository) Get(connection ConnectionInterface, email, clientID, campaignTypeID string) (bool, error) {
+	emailUnsubscriber := EmailUnsubscriber{}
+	err := connection.SelectOne(&emailUnsubscriber, "SELECT * FROM `email_unsubscribes` WHERE `email` = ? AND `client_id` = ? AND `campaign_type_id` = ?",
+		normalizeEmail(email), clientID, campaignTypeID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
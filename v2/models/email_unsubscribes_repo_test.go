@@ -0,0 +1,89 @@
+package models_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("EmailUnsubscribesRepository", func() {
+	var (
+		repo  models.EmailUnsubscribesRepository
+		conn  db.ConnectionInterface
+		clock *mocks.Clock
+	)
+
+	BeforeEach(func() {
+		database := db.NewDatabase(sqlDB, db.Config{})
+		helpers.TruncateTables(database)
+		conn = database.Connection()
+
+		clock = mocks.NewClock()
+		clock.NowCall.Returns.Time = time.Now()
+
+		repo = models.NewEmailUnsubscribesRepository(clock)
+	})
+
+	Describe("Insert", func() {
+		It("unsubscribes the email address from the campaign type", func() {
+			err := repo.Insert(conn, "someone@example.com", "some-client-id", "some-campaign-type-id")
+			Expect(err).NotTo(HaveOccurred())
+
+			unsubscribed, err := repo.Get(conn, "someone@example.com", "some-client-id", "some-campaign-type-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(unsubscribed).To(BeTrue())
+
+			unsubscribed, err = repo.Get(conn, "someone@example.com", "some-client-id", "other-campaign-type-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(unsubscribed).To(BeFalse())
+		})
+
+		It("matches email addresses regardless of case", func() {
+			Expect(repo.Insert(conn, "Someone@Example.com", "some-client-id", "some-campaign-type-id")).To(Succeed())
+
+			unsubscribed, err := repo.Get(conn, "someone@example.COM", "some-client-id", "some-campaign-type-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(unsubscribed).To(BeTrue())
+		})
+
+		It("does not fail when the email address is already unsubscribed", func() {
+			Expect(repo.Insert(conn, "someone@example.com", "some-client-id", "some-campaign-type-id")).To(Succeed())
+			Expect(repo.Insert(conn, "someone@example.com", "some-client-id", "some-campaign-type-id")).To(Succeed())
+		})
+
+		Context("when an error occurs", func() {
+			It("returns the error", func() {
+				connection := mocks.NewConnection()
+				connection.ExecCall.Returns.Error = errors.New("some connection error")
+
+				err := repo.Insert(connection, "someone@example.com", "some-client-id", "some-campaign-type-id")
+				Expect(err).To(MatchError(errors.New("some connection error")))
+			})
+		})
+	})
+
+	Describe("Get", func() {
+		It("returns false when the email address is not unsubscribed", func() {
+			unsubscribed, err := repo.Get(conn, "someone@example.com", "some-client-id", "some-campaign-type-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(unsubscribed).To(BeFalse())
+		})
+
+		Context("when an error occurs", func() {
+			It("returns the error", func() {
+				connection := mocks.NewConnection()
+				connection.SelectOneCall.Returns.Error = errors.New("some connection error")
+
+				_, err := repo.Get(connection, "someone@example.com", "some-client-id", "some-campaign-type-id")
+				Expect(err).To(MatchError(errors.New("some connection error")))
+			})
+		})
+	})
+})
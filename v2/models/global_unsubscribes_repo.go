@@ -0,0 +1,47 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// GlobalUnsubscriber rows live in the v1 `global_unsubscribes` table so that
+// a user who opts out of everything is opted out of both API versions.
+type GlobalUnsubscriber struct {
+	Primary   int       `db:"primary"`
+	UserGUID  string    `db:"user_id"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+type GlobalUnsubscribesRepository struct {
+	clock clock
+}
+
+func NewGlobalUnsubscribesRepository(clock clock) GlobalUnsubscribesRepository {
+	return GlobalUnsubscribesRepository{
+		clock: clock,
+	}
+}
+
+func (r GlobalUnsubscribesRepository) Insert(connection ConnectionInterface, userGUID string) error {
+	_, err := connection.Exec("INSERT INTO `global_unsubscribes` (`user_id`, `created_at`) VALUES (?, ?) ON DUPLICATE KEY UPDATE `user_id` = `user_id`", userGUID, r.clock.Now().UTC().Truncate(time.Second))
+	return err
+}
+
+func (r GlobalUnsubscribesRepository) Get(connection ConnectionInterface, userGUID string) (bool, error) {
+	globalUnsubscriber := GlobalUnsubscriber{}
+	err := connection.SelectOne(&globalUnsubscriber, "SELECT * FROM `global_unsubscribes` WHERE `user_id` = ?", userGUID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r GlobalUnsubscribesRepository) Delete(connection ConnectionInterface, userGUID string) error {
+	_, err := connection.Exec("DELETE FROM `global_unsubscribes` WHERE `user_id` = ?", userGUID)
+	return err
+}
@@ -0,0 +1,100 @@
+package models_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GlobalUnsubscribesRepository", func() {
+	var (
+		repo  models.GlobalUnsubscribesRepository
+		conn  db.ConnectionInterface
+		clock *mocks.Clock
+	)
+
+	BeforeEach(func() {
+		database := db.NewDatabase(sqlDB, db.Config{})
+		helpers.TruncateTables(database)
+		conn = database.Connection()
+
+		clock = mocks.NewClock()
+		clock.NowCall.Returns.Time = time.Now()
+
+		repo = models.NewGlobalUnsubscribesRepository(clock)
+	})
+
+	Describe("Insert", func() {
+		It("globally unsubscribes the user", func() {
+			err := repo.Insert(conn, "some-user-guid")
+			Expect(err).NotTo(HaveOccurred())
+
+			unsubscribed, err := repo.Get(conn, "some-user-guid")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(unsubscribed).To(BeTrue())
+		})
+
+		It("does not fail when the user is already unsubscribed", func() {
+			Expect(repo.Insert(conn, "some-user-guid")).To(Succeed())
+			Expect(repo.Insert(conn, "some-user-guid")).To(Succeed())
+		})
+
+		Context("when an error occurs", func() {
+			It("returns the error", func() {
+				connection := mocks.NewConnection()
+				connection.ExecCall.Returns.Error = errors.New("some connection error")
+
+				err := repo.Insert(connection, "some-user-guid")
+				Expect(err).To(MatchError(errors.New("some connection error")))
+			})
+		})
+	})
+
+	Describe("Get", func() {
+		It("returns false when the user is not unsubscribed", func() {
+			unsubscribed, err := repo.Get(conn, "some-user-guid")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(unsubscribed).To(BeFalse())
+		})
+
+		Context("when an error occurs", func() {
+			It("returns the error", func() {
+				connection := mocks.NewConnection()
+				connection.SelectOneCall.Returns.Error = errors.New("some connection error")
+
+				_, err := repo.Get(connection, "some-user-guid")
+				Expect(err).To(MatchError(errors.New("some connection error")))
+			})
+		})
+	})
+
+	Describe("Delete", func() {
+		It("resubscribes the user", func() {
+			Expect(repo.Insert(conn, "some-user-guid")).To(Succeed())
+
+			err := repo.Delete(conn, "some-user-guid")
+			Expect(err).NotTo(HaveOccurred())
+
+			unsubscribed, err := repo.Get(conn, "some-user-guid")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(unsubscribed).To(BeFalse())
+		})
+
+		Context("when an error occurs", func() {
+			It("returns the error", func() {
+				connection := mocks.NewConnection()
+				connection.ExecCall.Returns.Error = errors.New("some connection error")
+
+				err := repo.Delete(connection, "some-user-guid")
+				Expect(err).To(MatchError(errors.New("some connection error")))
+			})
+		})
+	})
+})
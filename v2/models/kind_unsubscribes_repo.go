@@ -0,0 +1,63 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// KindUnsubscriber rows live in the v1 `unsubscribes` table, which records
+// opt-outs from individual v1 notification kinds.
+type KindUnsubscriber struct {
+	UserGUID string `db:"user_id"`
+	ClientID string `db:"client_id"`
+	KindID   string `db:"kind_id"`
+}
+
+type KindUnsubscribesRepository struct {
+	clock clock
+}
+
+func NewKindUnsubscribesRepository(clock clock) KindUnsubscribesRepository {
+	return KindUnsubscribesRepository{
+		clock: clock,
+	}
+}
+
+func (r KindUnsubscribesRepository) Insert(connection ConnectionInterface, unsubscriber KindUnsubscriber) error {
+	_, err := connection.Exec("INSERT INTO `unsubscribes` (`user_id`, `client_id`, `kind_id`, `created_at`) VALUES (?, ?, ?, ?) ON DUPLICATE KEY UPDATE `user_id` = `user_id`",
+		unsubscriber.UserGUID, unsubscriber.ClientID, unsubscriber.KindID, r.clock.Now().UTC().Truncate(time.Second))
+	return err
+}
+
+func (r KindUnsubscribesRepository) Get(connection ConnectionInterface, unsubscriber KindUnsubscriber) (bool, error) {
+	existing := KindUnsubscriber{}
+	err := connection.SelectOne(&existing, "SELECT `user_id`, `client_id`, `kind_id` FROM `unsubscribes` WHERE `user_id` = ? AND `client_id` = ? AND `kind_id` = ?",
+		unsubscriber.UserGUID, unsubscriber.ClientID, unsubscriber.KindID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// KindCritical reports whether the v1 kind can be unsubscribed from, returning
+// a RecordNotFoundError when the client has no such kind.
+func (r KindUnsubscribesRepository) KindCritical(connection ConnectionInterface, clientID, kindID string) (bool, error) {
+	var kind struct {
+		Critical bool `db:"critical"`
+	}
+
+	err := connection.SelectOne(&kind, "SELECT COALESCE(`critical`, false) AS `critical` FROM `kinds` WHERE `id` = ? AND `client_id` = ?", kindID, clientID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, RecordNotFoundError{fmt.Errorf("Kind %q belonging to client %q could not be found", kindID, clientID)}
+		}
+		return false, err
+	}
+
+	return kind.Critical, nil
+}
@@ -0,0 +1,127 @@
+package models_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("KindUnsubscribesRepository", func() {
+	var (
+		repo  models.KindUnsubscribesRepository
+		conn  db.ConnectionInterface
+		clock *mocks.Clock
+	)
+
+	BeforeEach(func() {
+		database := db.NewDatabase(sqlDB, db.Config{})
+		helpers.TruncateTables(database)
+		conn = database.Connection()
+
+		clock = mocks.NewClock()
+		clock.NowCall.Returns.Time = time.Now()
+
+		repo = models.NewKindUnsubscribesRepository(clock)
+	})
+
+	Describe("Insert", func() {
+		It("unsubscribes the user from the kind", func() {
+			err := repo.Insert(conn, models.KindUnsubscriber{
+				UserGUID: "some-user-guid",
+				ClientID: "some-client-id",
+				KindID:   "some-kind-id",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			unsubscribed, err := repo.Get(conn, models.KindUnsubscriber{
+				UserGUID: "some-user-guid",
+				ClientID: "some-client-id",
+				KindID:   "some-kind-id",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(unsubscribed).To(BeTrue())
+		})
+
+		It("does not fail when the user is already unsubscribed", func() {
+			unsubscriber := models.KindUnsubscriber{
+				UserGUID: "some-user-guid",
+				ClientID: "some-client-id",
+				KindID:   "some-kind-id",
+			}
+
+			Expect(repo.Insert(conn, unsubscriber)).To(Succeed())
+			Expect(repo.Insert(conn, unsubscriber)).To(Succeed())
+		})
+
+		Context("when an error occurs", func() {
+			It("returns the error", func() {
+				connection := mocks.NewConnection()
+				connection.ExecCall.Returns.Error = errors.New("some connection error")
+
+				err := repo.Insert(connection, models.KindUnsubscriber{})
+				Expect(err).To(MatchError(errors.New("some connection error")))
+			})
+		})
+	})
+
+	Describe("Get", func() {
+		It("reports whether the user is unsubscribed from the kind", func() {
+			unsubscriber := models.KindUnsubscriber{
+				UserGUID: "some-user-guid",
+				ClientID: "some-client-id",
+				KindID:   "some-kind-id",
+			}
+
+			unsubscribed, err := repo.Get(conn, unsubscriber)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(unsubscribed).To(BeFalse())
+
+			Expect(repo.Insert(conn, unsubscriber)).To(Succeed())
+
+			unsubscribed, err = repo.Get(conn, unsubscriber)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(unsubscribed).To(BeTrue())
+		})
+
+		Context("when an error occurs", func() {
+			It("returns the error", func() {
+				connection := mocks.NewConnection()
+				connection.SelectOneCall.Returns.Error = errors.New("some connection error")
+
+				_, err := repo.Get(connection, models.KindUnsubscriber{})
+				Expect(err).To(MatchError(errors.New("some connection error")))
+			})
+		})
+	})
+
+	Describe("KindCritical", func() {
+		BeforeEach(func() {
+			_, err := conn.Exec("INSERT INTO `kinds` (`id`, `client_id`, `critical`) VALUES (?, ?, ?), (?, ?, ?)",
+				"critical-kind", "some-client-id", true,
+				"some-kind", "some-client-id", false)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("reports whether the kind is critical", func() {
+			critical, err := repo.KindCritical(conn, "some-client-id", "critical-kind")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(critical).To(BeTrue())
+
+			critical, err = repo.KindCritical(conn, "some-client-id", "some-kind")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(critical).To(BeFalse())
+		})
+
+		It("returns a record not found error when the kind does not exist", func() {
+			_, err := repo.KindCritical(conn, "other-client-id", "some-kind")
+			Expect(err).To(MatchError(models.RecordNotFoundError{errors.New(`Kind "some-kind" belonging to client "other-client-id" could not be found`)}))
+		})
+	})
+})
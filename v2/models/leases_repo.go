@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Lease lets one of several instances claim a periodic task. The holder keeps
+// the lease by renewing it before it expires; once it lapses any other
+// instance may take it over.
+type Lease struct {
+	Name      string    `db:"name"`
+	Holder    string    `db:"holder"`
+	ExpiresAt time.Time `db:"expires_at"`
+}
+
+type LeasesRepository struct {
+	clock clock
+}
+
+func NewLeasesRepository(clock clock) LeasesRepository {
+	return LeasesRepository{
+		clock: clock,
+	}
+}
+
+// Acquire takes or renews the named lease for the holder and reports whether
+// the holder has it. A lease held by someone else is only taken once it has
+// expired.
+func (r LeasesRepository) Acquire(connection ConnectionInterface, name, holder string, duration time.Duration) (bool, error) {
+	now := r.clock.Now().UTC().Truncate(time.Second)
+
+	_, err := connection.Exec("INSERT INTO `leases` (`name`, `holder`, `expires_at`) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE `holder` = IF(`expires_at` <= ? OR `holder` = VALUES(`holder`), VALUES(`holder`), `holder`), `expires_at` = IF(`holder` = VALUES(`holder`), VALUES(`expires_at`), `expires_at`)",
+		name, holder, now.Add(duration), now)
+	if err != nil {
+		return false, err
+	}
+
+	lease := Lease{}
+	err = connection.SelectOne(&lease, "SELECT * FROM `leases` WHERE `name` = ?", name)
+	if err != nil {
+		return false, err
+	}
+
+	return lease.Holder == holder, nil
+}
@@ -0,0 +1,92 @@
+package models_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LeasesRepository", func() {
+	var (
+		repo  models.LeasesRepository
+		conn  db.ConnectionInterface
+		clock *mocks.Clock
+	)
+
+	BeforeEach(func() {
+		database := db.NewDatabase(sqlDB, db.Config{})
+		helpers.TruncateTables(database)
+		conn = database.Connection()
+
+		clock = mocks.NewClock()
+		clock.NowCall.Returns.Time = time.Now().UTC().Truncate(time.Second)
+
+		repo = models.NewLeasesRepository(clock)
+	})
+
+	Describe("Acquire", func() {
+		It("grants a lease that nobody holds", func() {
+			acquired, err := repo.Acquire(conn, "some-task", "instance-1", time.Minute)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(acquired).To(BeTrue())
+		})
+
+		It("lets the holder renew its lease", func() {
+			_, err := repo.Acquire(conn, "some-task", "instance-1", time.Minute)
+			Expect(err).NotTo(HaveOccurred())
+
+			clock.NowCall.Returns.Time = clock.NowCall.Returns.Time.Add(30 * time.Second)
+
+			acquired, err := repo.Acquire(conn, "some-task", "instance-1", time.Minute)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(acquired).To(BeTrue())
+		})
+
+		It("refuses a lease that another holder has not let expire", func() {
+			_, err := repo.Acquire(conn, "some-task", "instance-1", time.Minute)
+			Expect(err).NotTo(HaveOccurred())
+
+			clock.NowCall.Returns.Time = clock.NowCall.Returns.Time.Add(30 * time.Second)
+
+			acquired, err := repo.Acquire(conn, "some-task", "instance-2", time.Minute)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(acquired).To(BeFalse())
+
+			acquired, err = repo.Acquire(conn, "other-task", "instance-2", time.Minute)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(acquired).To(BeTrue())
+		})
+
+		It("hands an expired lease to another holder", func() {
+			_, err := repo.Acquire(conn, "some-task", "instance-1", time.Minute)
+			Expect(err).NotTo(HaveOccurred())
+
+			clock.NowCall.Returns.Time = clock.NowCall.Returns.Time.Add(2 * time.Minute)
+
+			acquired, err := repo.Acquire(conn, "some-task", "instance-2", time.Minute)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(acquired).To(BeTrue())
+
+			acquired, err = repo.Acquire(conn, "some-task", "instance-1", time.Minute)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(acquired).To(BeFalse())
+		})
+
+		Context("failure cases", func() {
+			It("returns the error when the database blows up", func() {
+				fakeConnection := mocks.NewConnection()
+				fakeConnection.ExecCall.Returns.Error = errors.New("something bad happened")
+
+				_, err := repo.Acquire(fakeConnection, "some-task", "instance-1", time.Minute)
+				Expect(err).To(MatchError(errors.New("something bad happened")))
+			})
+		})
+	})
+})
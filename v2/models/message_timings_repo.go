@@ -0,0 +1,119 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// MessageTiming records, in seconds, how long a delivered message waited on
+// the queue and how long it took to hand off to the SMTP server.
+type MessageTiming struct {
+	MessageID    string    `db:"message_id"`
+	CampaignID   string    `db:"campaign_id"`
+	QueueWait    float64   `db:"queue_wait"`
+	SendDuration float64   `db:"send_duration"`
+	DeliveredAt  time.Time `db:"delivered_at"`
+}
+
+type Percentiles struct {
+	P50 float64
+	P95 float64
+}
+
+type MessageTimingPercentiles struct {
+	Count        int
+	QueueWait    Percentiles
+	SendDuration Percentiles
+}
+
+type MessageTimingsRepository struct {
+	clock clock
+}
+
+func NewMessageTimingsRepository(clock clock) MessageTimingsRepository {
+	return MessageTimingsRepository{
+		clock: clock,
+	}
+}
+
+// Insert records the timing of a message. A message that is delivered again
+// after a retry replaces its earlier timing.
+func (r MessageTimingsRepository) Insert(conn ConnectionInterface, timing MessageTiming) error {
+	if timing.DeliveredAt.IsZero() {
+		timing.DeliveredAt = r.clock.Now()
+	}
+
+	_, err := conn.Exec("INSERT INTO `message_timings` (`message_id`, `campaign_id`, `queue_wait`, `send_duration`, `delivered_at`) VALUES (?, ?, ?, ?, ?) ON DUPLICATE KEY UPDATE `queue_wait` = VALUES(`queue_wait`), `send_duration` = VALUES(`send_duration`), `delivered_at` = VALUES(`delivered_at`)",
+		timing.MessageID, timing.CampaignID, timing.QueueWait, timing.SendDuration, timing.DeliveredAt)
+
+	return err
+}
+
+// PercentilesByCampaignID reports the nearest-rank p50 and p95 of the campaign
+// timings, so every reported value is one that was actually observed. The
+// values are ranked by the database; only the ones reported are read.
+func (r MessageTimingsRepository) PercentilesByCampaignID(conn ConnectionInterface, campaignID string) (MessageTimingPercentiles, error) {
+	var counts []struct {
+		Count int `db:"count"`
+	}
+	_, err := conn.Select(&counts, "SELECT COUNT(*) AS `count` FROM `message_timings` WHERE `campaign_id` = ?", campaignID)
+	if err != nil {
+		return MessageTimingPercentiles{}, err
+	}
+
+	if len(counts) == 0 || counts[0].Count == 0 {
+		return MessageTimingPercentiles{}, nil
+	}
+
+	result := MessageTimingPercentiles{
+		Count: counts[0].Count,
+	}
+
+	result.QueueWait, err = r.percentiles(conn, campaignID, "queue_wait", result.Count)
+	if err != nil {
+		return MessageTimingPercentiles{}, err
+	}
+
+	result.SendDuration, err = r.percentiles(conn, campaignID, "send_duration", result.Count)
+	if err != nil {
+		return MessageTimingPercentiles{}, err
+	}
+
+	return result, nil
+}
+
+func (r MessageTimingsRepository) percentiles(conn ConnectionInterface, campaignID, column string, count int) (Percentiles, error) {
+	p50, err := r.nearestRank(conn, campaignID, column, count, 50)
+	if err != nil {
+		return Percentiles{}, err
+	}
+
+	p95, err := r.nearestRank(conn, campaignID, column, count, 95)
+	if err != nil {
+		return Percentiles{}, err
+	}
+
+	return Percentiles{P50: p50, P95: p95}, nil
+}
+
+func (r MessageTimingsRepository) nearestRank(conn ConnectionInterface, campaignID, column string, count, percentile int) (float64, error) {
+	rank := (percentile*count + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+
+	var values []struct {
+		Value float64 `db:"value"`
+	}
+	query := fmt.Sprintf("SELECT `%s` AS `value` FROM `message_timings` WHERE `campaign_id` = ? ORDER BY `%s` LIMIT 1 OFFSET ?", column, column)
+	_, err := conn.Select(&values, query, campaignID, rank-1)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(values) == 0 {
+		return 0, nil
+	}
+
+	return values[0].Value, nil
+}
@@ -0,0 +1,115 @@
+package models_test
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MessageTimingsRepository", func() {
+	var (
+		repo       models.MessageTimingsRepository
+		connection db.ConnectionInterface
+		clock      *mocks.Clock
+	)
+
+	BeforeEach(func() {
+		clock = &mocks.Clock{}
+		clock.NowCall.Returns.Time = time.Now().UTC().Truncate(time.Second)
+
+		repo = models.NewMessageTimingsRepository(clock)
+		database := db.NewDatabase(sqlDB, db.Config{})
+		helpers.TruncateTables(database)
+		connection = database.Connection()
+	})
+
+	Describe("Insert", func() {
+		It("replaces the timing of a message that is delivered again", func() {
+			err := repo.Insert(connection, models.MessageTiming{
+				MessageID:    "message-id",
+				CampaignID:   "campaign-id",
+				QueueWait:    1.5,
+				SendDuration: 0.25,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = repo.Insert(connection, models.MessageTiming{
+				MessageID:    "message-id",
+				CampaignID:   "campaign-id",
+				QueueWait:    3,
+				SendDuration: 0.5,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			percentiles, err := repo.PercentilesByCampaignID(connection, "campaign-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(percentiles).To(Equal(models.MessageTimingPercentiles{
+				Count:        1,
+				QueueWait:    models.Percentiles{P50: 3, P95: 3},
+				SendDuration: models.Percentiles{P50: 0.5, P95: 0.5},
+			}))
+		})
+
+		Context("failure cases", func() {
+			It("returns the error when the database blows up", func() {
+				fakeConnection := mocks.NewConnection()
+				fakeConnection.ExecCall.Returns.Error = errors.New("something bad happened")
+
+				err := repo.Insert(fakeConnection, models.MessageTiming{MessageID: "message-id"})
+				Expect(err).To(MatchError(errors.New("something bad happened")))
+			})
+		})
+	})
+
+	Describe("PercentilesByCampaignID", func() {
+		It("reports the nearest-rank p50 and p95 of the campaign timings", func() {
+			for i := 1; i <= 20; i++ {
+				err := repo.Insert(connection, models.MessageTiming{
+					MessageID:    fmt.Sprintf("message-%d", i),
+					CampaignID:   "campaign-id",
+					QueueWait:    float64(i),
+					SendDuration: float64(i) / 10,
+				})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			err := repo.Insert(connection, models.MessageTiming{
+				MessageID:    "other-message",
+				CampaignID:   "other-campaign-id",
+				QueueWait:    100,
+				SendDuration: 100,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			percentiles, err := repo.PercentilesByCampaignID(connection, "campaign-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(percentiles.Count).To(Equal(20))
+			Expect(percentiles.QueueWait).To(Equal(models.Percentiles{P50: 10, P95: 19}))
+			Expect(percentiles.SendDuration.P50).To(BeNumerically("~", 1.0))
+			Expect(percentiles.SendDuration.P95).To(BeNumerically("~", 1.9))
+		})
+
+		It("reports zero values when the campaign has no timings", func() {
+			percentiles, err := repo.PercentilesByCampaignID(connection, "campaign-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(percentiles).To(Equal(models.MessageTimingPercentiles{}))
+		})
+
+		Context("failure cases", func() {
+			It("returns the error when the database blows up", func() {
+				fakeConnection := mocks.NewConnection()
+				fakeConnection.SelectCall.Returns.Error = errors.New("something bad happened")
+
+				_, err := repo.PercentilesByCampaignID(fakeConnection, "campaign-id")
+				Expect(err).To(MatchError(errors.New("something bad happened")))
+			})
+		})
+	})
+})
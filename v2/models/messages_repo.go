@@ -41,30 +41,24 @@ func NewMessagesRepository(clock clock, guidGenerator guidGeneratorFunc) Message
 
 func (mr MessagesRepository) CountByStatus(conn ConnectionInterface, campaignID string) (MessageCounts, error) {
 	var counts []statusCount
-	var messageCounts MessageCounts
 
 	_, err := conn.Select(&counts, "SELECT `status`, COUNT(id) AS `count` FROM `messages` WHERE `campaign_id` = ? GROUP BY `status`", campaignID)
 	if err != nil {
-		return messageCounts, err
+		return MessageCounts{}, err
 	}
 
-	for _, count := range counts {
-		switch count.Status {
-		case "delivered":
-			messageCounts.Delivered = count.Count
-		case "retry":
-			messageCounts.Retry = count.Count
-		case "failed":
-			messageCounts.Failed = count.Count
-		case "queued":
-			messageCounts.Queued = count.Count
-		case "undeliverable":
-			messageCounts.Undeliverable = count.Count
-		}
-		messageCounts.Total += count.Count
+	return tallyStatusCounts(counts), nil
+}
+
+func (mr MessagesRepository) CountByTemplateIDSince(conn ConnectionInterface, templateID, clientID string, since time.Time) (MessageCounts, error) {
+	var counts []statusCount
+
+	_, err := conn.Select(&counts, "SELECT `messages`.`status`, COUNT(`messages`.`id`) AS `count` FROM `messages` INNER JOIN `campaigns` ON `messages`.`campaign_id` = `campaigns`.`id` INNER JOIN `senders` ON `campaigns`.`sender_id` = `senders`.`id` WHERE `campaigns`.`template_id` = ? AND `senders`.`client_id` = ? AND `messages`.`updated_at` >= ? GROUP BY `messages`.`status`", templateID, clientID, since)
+	if err != nil {
+		return MessageCounts{}, err
 	}
 
-	return messageCounts, nil
+	return tallyStatusCounts(counts), nil
 }
 
 func (mr MessagesRepository) MostRecentlyUpdatedByCampaignID(conn ConnectionInterface, campaignID string) (Message, error) {
@@ -105,3 +99,25 @@ func (mr MessagesRepository) Update(conn ConnectionInterface, message Message) (
 
 	return message, nil
 }
+
+func tallyStatusCounts(counts []statusCount) MessageCounts {
+	var messageCounts MessageCounts
+
+	for _, count := range counts {
+		switch count.Status {
+		case "delivered":
+			messageCounts.Delivered = count.Count
+		case "retry":
+			messageCounts.Retry = count.Count
+		case "failed":
+			messageCounts.Failed = count.Count
+		case "queued":
+			messageCounts.Queued = count.Count
+		case "undeliverable":
+			messageCounts.Undeliverable = count.Count
+		}
+		messageCounts.Total += count.Count
+	}
+
+	return messageCounts
+}
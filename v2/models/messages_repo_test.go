@@ -109,6 +109,65 @@ var _ = Describe("MessagesRepository", func() {
 		})
 	})
 
+	Describe("CountByTemplateIDSince", func() {
+		BeforeEach(func() {
+			now := time.Now().UTC().Truncate(time.Second)
+			campaignGUIDs := mocks.NewIDGenerator()
+			campaignGUIDs.GenerateCall.Returns.IDs = []string{"some-campaign-id", "other-campaign-id", "some-sender-id"}
+
+			_, err := models.NewCampaignsRepository(campaignGUIDs.Generate, clock).Insert(conn, models.Campaign{
+				TemplateID: "some-template-id",
+				SenderID:   "some-sender-id",
+				StartTime:  now,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = models.NewCampaignsRepository(campaignGUIDs.Generate, clock).Insert(conn, models.Campaign{
+				TemplateID: "other-template-id",
+				SenderID:   "some-sender-id",
+				StartTime:  now,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = models.NewSendersRepository(campaignGUIDs.Generate).Insert(conn, models.Sender{
+				Name:     "some-sender",
+				ClientID: "some-client-id",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			messages := []models.Message{
+				{ID: "random-guid-1", CampaignID: "some-campaign-id", Status: common.StatusDelivered, UpdatedAt: now},
+				{ID: "random-guid-2", CampaignID: "some-campaign-id", Status: common.StatusFailed, UpdatedAt: now},
+				{ID: "random-guid-3", CampaignID: "some-campaign-id", Status: common.StatusDelivered, UpdatedAt: now.Add(-60 * 24 * time.Hour)},
+				{ID: "random-guid-4", CampaignID: "other-campaign-id", Status: common.StatusDelivered, UpdatedAt: now},
+			}
+			for _, message := range messages {
+				message := message
+				Expect(conn.Insert(&message)).To(Succeed())
+			}
+		})
+
+		It("counts the recent messages sent by campaigns using the template", func() {
+			messageCounts, err := repo.CountByTemplateIDSince(conn, "some-template-id", "some-client-id", time.Now().Add(-30*24*time.Hour))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(messageCounts).To(Equal(models.MessageCounts{
+				Total:     2,
+				Delivered: 1,
+				Failed:    1,
+			}))
+		})
+
+		Context("when an error occurs", func() {
+			It("should return an error", func() {
+				connection := mocks.NewConnection()
+				connection.SelectCall.Returns.Error = errors.New("some connection error")
+
+				_, err := repo.CountByTemplateIDSince(connection, "some-template-id", "some-client-id", time.Now())
+				Expect(err).To(MatchError(errors.New("some connection error")))
+			})
+		})
+	})
+
 	Describe("MostRecentlyUpdatedByCampaignID", func() {
 		var anotherUpdatedAt time.Time
 		BeforeEach(func() {
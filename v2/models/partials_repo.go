@@ -0,0 +1,84 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+type Partial struct {
+	ID       string `db:"id"`
+	Name     string `db:"name"`
+	HTML     string `db:"html"`
+	Text     string `db:"text"`
+	ClientID string `db:"client_id"`
+}
+
+type PartialsRepository struct {
+	generateGUID guidGeneratorFunc
+}
+
+func NewPartialsRepository(guidGenerator guidGeneratorFunc) PartialsRepository {
+	return PartialsRepository{
+		generateGUID: guidGenerator,
+	}
+}
+
+func (r PartialsRepository) Insert(conn ConnectionInterface, partial Partial) (Partial, error) {
+	var err error
+	partial.ID, err = r.generateGUID()
+	if err != nil {
+		return Partial{}, err
+	}
+
+	err = conn.Insert(&partial)
+	if err != nil {
+		if strings.Contains(err.Error(), "Duplicate entry") {
+			return Partial{}, DuplicateRecordError{fmt.Errorf("Partial with name %q already exists", partial.Name)}
+		}
+
+		return Partial{}, err
+	}
+
+	return partial, nil
+}
+
+func (r PartialsRepository) Update(conn ConnectionInterface, partial Partial) (Partial, error) {
+	_, err := conn.Update(&partial)
+	if err != nil {
+		if strings.Contains(err.Error(), "Duplicate entry") {
+			err = DuplicateRecordError{fmt.Errorf("Partial with name %q already exists", partial.Name)}
+		}
+		return partial, err
+	}
+
+	return partial, nil
+}
+
+func (r PartialsRepository) Get(conn ConnectionInterface, partialID string) (Partial, error) {
+	partial := Partial{}
+	err := conn.SelectOne(&partial, "SELECT * FROM `partials` WHERE `id` = ?", partialID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = RecordNotFoundError{fmt.Errorf("Partial with id %q could not be found", partialID)}
+		}
+		return partial, err
+	}
+
+	return partial, nil
+}
+
+func (r PartialsRepository) List(conn ConnectionInterface, clientID string) ([]Partial, error) {
+	partials := []Partial{}
+	_, err := conn.Select(&partials, "SELECT * FROM `partials` WHERE `client_id` = ? ORDER BY `name`", clientID)
+	return partials, err
+}
+
+func (r PartialsRepository) Delete(conn ConnectionInterface, partial Partial) error {
+	_, err := conn.Delete(&partial)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
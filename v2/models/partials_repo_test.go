@@ -0,0 +1,161 @@
+package models_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PartialsRepository", func() {
+	var (
+		repo          models.PartialsRepository
+		conn          db.ConnectionInterface
+		guidGenerator *mocks.IDGenerator
+	)
+
+	BeforeEach(func() {
+		database := db.NewDatabase(sqlDB, db.Config{})
+		helpers.TruncateTables(database)
+
+		guidGenerator = mocks.NewIDGenerator()
+		guidGenerator.GenerateCall.Returns.IDs = []string{"first-random-guid", "second-random-guid"}
+
+		repo = models.NewPartialsRepository(guidGenerator.Generate)
+		conn = database.Connection()
+	})
+
+	Describe("Insert", func() {
+		It("inserts the record into the database", func() {
+			partial, err := repo.Insert(conn, models.Partial{
+				Name:     "footer",
+				HTML:     "<footer>{{.ClientID}}</footer>",
+				Text:     "-- {{.ClientID}}",
+				ClientID: "some-client-id",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(partial).To(Equal(models.Partial{
+				ID:       "first-random-guid",
+				Name:     "footer",
+				HTML:     "<footer>{{.ClientID}}</footer>",
+				Text:     "-- {{.ClientID}}",
+				ClientID: "some-client-id",
+			}))
+		})
+
+		Context("failure cases", func() {
+			It("returns a duplicate record error when the name and client_id are taken", func() {
+				partial := models.Partial{
+					Name:     "footer",
+					ClientID: "some-client-id",
+				}
+
+				_, err := repo.Insert(conn, partial)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = repo.Insert(conn, partial)
+				Expect(err).To(MatchError(models.DuplicateRecordError{errors.New("Partial with name \"footer\" already exists")}))
+			})
+
+			It("returns an error when the guid generator blows up", func() {
+				guidGenerator.GenerateCall.Returns.Error = errors.New("failed to generate")
+
+				_, err := repo.Insert(conn, models.Partial{})
+				Expect(err).To(MatchError(errors.New("failed to generate")))
+			})
+		})
+	})
+
+	Describe("Update", func() {
+		It("updates the record in the database", func() {
+			partial, err := repo.Insert(conn, models.Partial{
+				Name:     "footer",
+				Text:     "old text",
+				ClientID: "some-client-id",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			partial.Text = "new text"
+			_, err = repo.Update(conn, partial)
+			Expect(err).NotTo(HaveOccurred())
+
+			partial, err = repo.Get(conn, partial.ID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(partial.Text).To(Equal("new text"))
+		})
+
+		It("returns a duplicate record error when the name is taken", func() {
+			_, err := repo.Insert(conn, models.Partial{
+				Name:     "footer",
+				ClientID: "some-client-id",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			partial, err := repo.Insert(conn, models.Partial{
+				Name:     "header",
+				ClientID: "some-client-id",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			partial.Name = "footer"
+			_, err = repo.Update(conn, partial)
+			Expect(err).To(MatchError(models.DuplicateRecordError{errors.New("Partial with name \"footer\" already exists")}))
+		})
+	})
+
+	Describe("Get", func() {
+		It("returns a not found error when the partial does not exist", func() {
+			_, err := repo.Get(conn, "missing-partial-id")
+			Expect(err).To(MatchError(models.RecordNotFoundError{errors.New("Partial with id \"missing-partial-id\" could not be found")}))
+		})
+	})
+
+	Describe("List", func() {
+		It("returns the partials belonging to the client ordered by name", func() {
+			guidGenerator.GenerateCall.Returns.IDs = []string{"first-random-guid", "second-random-guid", "third-random-guid"}
+
+			footer, err := repo.Insert(conn, models.Partial{
+				Name:     "footer",
+				ClientID: "some-client-id",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = repo.Insert(conn, models.Partial{
+				Name:     "footer",
+				ClientID: "other-client-id",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			header, err := repo.Insert(conn, models.Partial{
+				Name:     "header",
+				ClientID: "some-client-id",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			partials, err := repo.List(conn, "some-client-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(partials).To(Equal([]models.Partial{footer, header}))
+		})
+	})
+
+	Describe("Delete", func() {
+		It("deletes the partial", func() {
+			partial, err := repo.Insert(conn, models.Partial{
+				Name:     "footer",
+				ClientID: "some-client-id",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = repo.Delete(conn, partial)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = repo.Get(conn, partial.ID)
+			Expect(err).To(BeAssignableToTypeOf(models.RecordNotFoundError{}))
+		})
+	})
+})
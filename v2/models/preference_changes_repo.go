@@ -0,0 +1,131 @@
+package models
+
+import "time"
+
+const (
+	ActorUser      = "user"
+	ActorClient    = "client"
+	ActorRecipient = "recipient"
+
+	SourceAPI    = "api"
+	SourceLink   = "link"
+	SourceImport = "import"
+
+	PreferenceGlobal             = "global"
+	PreferenceKind               = "kind"
+	PreferenceCampaignType       = "campaign_type"
+	PreferenceKindDigest         = "kind_digest"
+	PreferenceCampaignTypeDigest = "campaign_type_digest"
+	PreferenceTimeZone           = "time_zone"
+	PreferenceQuietHours         = "quiet_hours"
+
+	Subscribed   = "subscribed"
+	Unsubscribed = "unsubscribed"
+)
+
+// Actor says who changed a preference and through what. Type is ActorUser
+// for a UAA user token, ActorClient for a client token, or ActorRecipient
+// for someone following a signed link from an email; ID is the user GUID,
+// client ID or recipient respectively.
+type Actor struct {
+	Type   string
+	ID     string
+	Source string
+}
+
+// PreferenceChange rows form the audit trail of subscription, digest and quiet
+// hours changes. The TargetID is the kind ID or campaign type ID, and is empty
+// for a global unsubscribe, time zone or quiet hours. Email is set instead of UserGUID for recipients who were
+// addressed by email.
+type PreferenceChange struct {
+	ID         int64     `db:"id"`
+	UserGUID   string    `db:"user_guid"`
+	Email      string    `db:"email"`
+	ActorType  string    `db:"actor_type"`
+	ActorID    string    `db:"actor_id"`
+	Source     string    `db:"source"`
+	Preference string    `db:"preference"`
+	ClientID   string    `db:"client_id"`
+	TargetID   string    `db:"target_id"`
+	OldValue   string    `db:"old_value"`
+	NewValue   string    `db:"new_value"`
+	CreatedAt  time.Time `db:"created_at"`
+}
+
+func SubscriptionValue(unsubscribed bool) string {
+	if unsubscribed {
+		return Unsubscribed
+	}
+
+	return Subscribed
+}
+
+// QuietHoursChanges lists the time zone and quiet hours changes from previous
+// to current. Quiet hours are written as "start-end", and are empty when
+// unset.
+func QuietHoursChanges(userGUID string, previous, current QuietHours) []PreferenceChange {
+	return []PreferenceChange{
+		{
+			UserGUID:   userGUID,
+			Preference: PreferenceTimeZone,
+			OldValue:   previous.TimeZone,
+			NewValue:   current.TimeZone,
+		},
+		{
+			UserGUID:   userGUID,
+			Preference: PreferenceQuietHours,
+			OldValue:   quietHoursValue(previous),
+			NewValue:   quietHoursValue(current),
+		},
+	}
+}
+
+func quietHoursValue(quietHours QuietHours) string {
+	if quietHours.Start == "" && quietHours.End == "" {
+		return ""
+	}
+
+	return quietHours.Start + "-" + quietHours.End
+}
+
+type PreferenceChangesRepository struct {
+	clock clock
+}
+
+func NewPreferenceChangesRepository(clock clock) PreferenceChangesRepository {
+	return PreferenceChangesRepository{
+		clock: clock,
+	}
+}
+
+// Record stores the change as made by the actor. Changes that leave the
+// value as it was are not recorded.
+func (r PreferenceChangesRepository) Record(connection ConnectionInterface, actor Actor, change PreferenceChange) error {
+	if change.OldValue == change.NewValue {
+		return nil
+	}
+
+	change.ActorType = actor.Type
+	change.ActorID = actor.ID
+	change.Source = actor.Source
+	change.CreatedAt = r.clock.Now().UTC().Truncate(time.Second)
+
+	_, err := connection.Exec("INSERT INTO `preference_changes` (`user_guid`, `email`, `actor_type`, `actor_id`, `source`, `preference`, `client_id`, `target_id`, `old_value`, `new_value`, `created_at`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		change.UserGUID, change.Email, change.ActorType, change.ActorID, change.Source, change.Preference, change.ClientID, change.TargetID, change.OldValue, change.NewValue, change.CreatedAt)
+	return err
+}
+
+// List returns a page of the changes for the user, oldest first, or of every
+// change when the user GUID is empty.
+func (r PreferenceChangesRepository) List(connection ConnectionInterface, userGUID string, offset, limit int) ([]PreferenceChange, error) {
+	changes := []PreferenceChange{}
+
+	var err error
+	if userGUID == "" {
+		_, err = connection.Select(&changes, "SELECT * FROM `preference_changes` ORDER BY `created_at`, `id` LIMIT ? OFFSET ?", limit, offset)
+	} else {
+		_, err = connection.Select(&changes, "SELECT * FROM `preference_changes` WHERE `user_guid` = ? ORDER BY `created_at`, `id` LIMIT ? OFFSET ?", userGUID, limit, offset)
+	}
+
+	return changes, err
+}
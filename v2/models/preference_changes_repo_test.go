@@ -0,0 +1,167 @@
+package models_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PreferenceChangesRepository", func() {
+	var (
+		repo  models.PreferenceChangesRepository
+		conn  db.ConnectionInterface
+		clock *mocks.Clock
+		actor models.Actor
+		now   time.Time
+	)
+
+	BeforeEach(func() {
+		database := db.NewDatabase(sqlDB, db.Config{})
+		helpers.TruncateTables(database)
+		conn = database.Connection()
+
+		now = time.Now().UTC().Truncate(time.Second)
+		clock = mocks.NewClock()
+		clock.NowCall.Returns.Time = now
+
+		actor = models.Actor{
+			Type:   models.ActorClient,
+			ID:     "some-admin-client",
+			Source: models.SourceAPI,
+		}
+
+		repo = models.NewPreferenceChangesRepository(clock)
+	})
+
+	Describe("Record", func() {
+		It("records who made the change and when", func() {
+			err := repo.Record(conn, actor, models.PreferenceChange{
+				UserGUID:   "some-user-guid",
+				Preference: models.PreferenceCampaignType,
+				TargetID:   "some-campaign-type-id",
+				OldValue:   models.Subscribed,
+				NewValue:   models.Unsubscribed,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			changes, err := repo.List(conn, "some-user-guid", 0, 10)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(changes).To(HaveLen(1))
+			Expect(changes[0].ID).NotTo(BeZero())
+			Expect(changes[0].ActorType).To(Equal(models.ActorClient))
+			Expect(changes[0].ActorID).To(Equal("some-admin-client"))
+			Expect(changes[0].Source).To(Equal(models.SourceAPI))
+			Expect(changes[0].Preference).To(Equal(models.PreferenceCampaignType))
+			Expect(changes[0].TargetID).To(Equal("some-campaign-type-id"))
+			Expect(changes[0].OldValue).To(Equal(models.Subscribed))
+			Expect(changes[0].NewValue).To(Equal(models.Unsubscribed))
+			Expect(changes[0].CreatedAt).To(Equal(now))
+		})
+
+		It("does not record changes that leave the value as it was", func() {
+			err := repo.Record(conn, actor, models.PreferenceChange{
+				UserGUID:   "some-user-guid",
+				Preference: models.PreferenceGlobal,
+				OldValue:   models.Unsubscribed,
+				NewValue:   models.Unsubscribed,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			changes, err := repo.List(conn, "some-user-guid", 0, 10)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(changes).To(BeEmpty())
+		})
+
+		Context("when an error occurs", func() {
+			It("returns the error", func() {
+				connection := mocks.NewConnection()
+				connection.ExecCall.Returns.Error = errors.New("some connection error")
+
+				err := repo.Record(connection, actor, models.PreferenceChange{
+					NewValue: models.Unsubscribed,
+				})
+				Expect(err).To(MatchError(errors.New("some connection error")))
+			})
+		})
+	})
+
+	Describe("List", func() {
+		BeforeEach(func() {
+			for _, userGUID := range []string{"some-user-guid", "other-user-guid", "some-user-guid"} {
+				err := repo.Record(conn, actor, models.PreferenceChange{
+					UserGUID:   userGUID,
+					Preference: models.PreferenceGlobal,
+					OldValue:   models.Subscribed,
+					NewValue:   models.Unsubscribed,
+				})
+				Expect(err).NotTo(HaveOccurred())
+			}
+		})
+
+		It("filters the changes by user", func() {
+			changes, err := repo.List(conn, "some-user-guid", 0, 10)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(changes).To(HaveLen(2))
+			Expect(changes[0].ID).To(BeNumerically("<", changes[1].ID))
+		})
+
+		It("lists every change when no user is given", func() {
+			changes, err := repo.List(conn, "", 0, 10)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(changes).To(HaveLen(3))
+		})
+
+		It("returns the requested page", func() {
+			all, err := repo.List(conn, "", 0, 10)
+			Expect(err).NotTo(HaveOccurred())
+
+			changes, err := repo.List(conn, "", 1, 1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(changes).To(Equal(all[1:2]))
+		})
+
+		Context("when an error occurs", func() {
+			It("returns the error", func() {
+				connection := mocks.NewConnection()
+				connection.SelectCall.Returns.Error = errors.New("some connection error")
+
+				_, err := repo.List(connection, "", 0, 10)
+				Expect(err).To(MatchError(errors.New("some connection error")))
+			})
+		})
+	})
+
+	Describe("QuietHoursChanges", func() {
+		It("lists the time zone and quiet hours changes", func() {
+			changes := models.QuietHoursChanges("some-user-guid", models.QuietHours{
+				TimeZone: "America/Chicago",
+			}, models.QuietHours{
+				TimeZone: "Europe/Paris",
+				Start:    "22:00",
+				End:      "07:00",
+			})
+
+			Expect(changes).To(Equal([]models.PreferenceChange{
+				{
+					UserGUID:   "some-user-guid",
+					Preference: models.PreferenceTimeZone,
+					OldValue:   "America/Chicago",
+					NewValue:   "Europe/Paris",
+				},
+				{
+					UserGUID:   "some-user-guid",
+					Preference: models.PreferenceQuietHours,
+					OldValue:   "",
+					NewValue:   "22:00-07:00",
+				},
+			}))
+		})
+	})
+})
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+const clockTimeLayout = "15:04"
+
+// QuietHours records when a user does not want to receive non-critical
+// notifications. Start and End are "HH:MM" times in the user's time zone; a
+// window that ends before it starts runs overnight.
+type QuietHours struct {
+	UserGUID string `db:"user_guid"`
+	TimeZone string `db:"time_zone"`
+	Start    string `db:"start_time"`
+	End      string `db:"end_time"`
+}
+
+func ValidTimeZone(timeZone string) bool {
+	if timeZone == "" {
+		return true
+	}
+
+	_, err := time.LoadLocation(timeZone)
+	return err == nil
+}
+
+func ValidClockTime(clockTime string) bool {
+	_, err := time.Parse(clockTimeLayout, clockTime)
+	return err == nil
+}
+
+type QuietHoursRepository struct{}
+
+func NewQuietHoursRepository() QuietHoursRepository {
+	return QuietHoursRepository{}
+}
+
+func (r QuietHoursRepository) Set(connection ConnectionInterface, quietHours QuietHours) error {
+	if quietHours.TimeZone == "" && quietHours.Start == "" && quietHours.End == "" {
+		_, err := connection.Exec("DELETE FROM `quiet_hours` WHERE `user_guid` = ?", quietHours.UserGUID)
+		return err
+	}
+
+	_, err := connection.Exec("INSERT INTO `quiet_hours` (`user_guid`, `time_zone`, `start_time`, `end_time`) VALUES (?, ?, ?, ?) ON DUPLICATE KEY UPDATE `time_zone` = VALUES(`time_zone`), `start_time` = VALUES(`start_time`), `end_time` = VALUES(`end_time`)",
+		quietHours.UserGUID, quietHours.TimeZone, quietHours.Start, quietHours.End)
+	return err
+}
+
+func (r QuietHoursRepository) Get(connection ConnectionInterface, userGUID string) (QuietHours, error) {
+	quietHours := QuietHours{}
+	err := connection.SelectOne(&quietHours, "SELECT * FROM `quiet_hours` WHERE `user_guid` = ?", userGUID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return QuietHours{UserGUID: userGUID}, nil
+		}
+		return QuietHours{}, err
+	}
+
+	return quietHours, nil
+}
@@ -0,0 +1,120 @@
+package models_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("QuietHoursRepository", func() {
+	var (
+		repo models.QuietHoursRepository
+		conn db.ConnectionInterface
+	)
+
+	BeforeEach(func() {
+		database := db.NewDatabase(sqlDB, db.Config{})
+		helpers.TruncateTables(database)
+		conn = database.Connection()
+
+		repo = models.NewQuietHoursRepository()
+	})
+
+	Describe("Set", func() {
+		It("stores the quiet hours for the user", func() {
+			quietHours := models.QuietHours{
+				UserGUID: "some-user-guid",
+				TimeZone: "America/New_York",
+				Start:    "22:00",
+				End:      "07:00",
+			}
+			Expect(repo.Set(conn, quietHours)).To(Succeed())
+
+			result, err := repo.Get(conn, "some-user-guid")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(quietHours))
+		})
+
+		It("replaces existing quiet hours", func() {
+			quietHours := models.QuietHours{
+				UserGUID: "some-user-guid",
+				TimeZone: "America/New_York",
+				Start:    "22:00",
+				End:      "07:00",
+			}
+			Expect(repo.Set(conn, quietHours)).To(Succeed())
+
+			quietHours.TimeZone = "Europe/Berlin"
+			quietHours.Start = ""
+			quietHours.End = ""
+			Expect(repo.Set(conn, quietHours)).To(Succeed())
+
+			result, err := repo.Get(conn, "some-user-guid")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(quietHours))
+		})
+
+		It("removes the record when everything is cleared", func() {
+			Expect(repo.Set(conn, models.QuietHours{UserGUID: "some-user-guid", TimeZone: "Europe/Berlin"})).To(Succeed())
+			Expect(repo.Set(conn, models.QuietHours{UserGUID: "some-user-guid"})).To(Succeed())
+
+			var quietHours []models.QuietHours
+			_, err := conn.Select(&quietHours, "SELECT * FROM `quiet_hours`")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(quietHours).To(BeEmpty())
+		})
+
+		Context("when an error occurs", func() {
+			It("returns the error", func() {
+				connection := mocks.NewConnection()
+				connection.ExecCall.Returns.Error = errors.New("some connection error")
+
+				err := repo.Set(connection, models.QuietHours{UserGUID: "some-user-guid", TimeZone: "UTC"})
+				Expect(err).To(MatchError(errors.New("some connection error")))
+			})
+		})
+	})
+
+	Describe("Get", func() {
+		It("returns empty quiet hours when the user has none", func() {
+			quietHours, err := repo.Get(conn, "some-user-guid")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(quietHours).To(Equal(models.QuietHours{UserGUID: "some-user-guid"}))
+		})
+
+		Context("when an error occurs", func() {
+			It("returns the error", func() {
+				connection := mocks.NewConnection()
+				connection.SelectOneCall.Returns.Error = errors.New("some connection error")
+
+				_, err := repo.Get(connection, "some-user-guid")
+				Expect(err).To(MatchError(errors.New("some connection error")))
+			})
+		})
+	})
+
+	Describe("ValidTimeZone", func() {
+		It("accepts IANA time zone names", func() {
+			Expect(models.ValidTimeZone("America/New_York")).To(BeTrue())
+			Expect(models.ValidTimeZone("UTC")).To(BeTrue())
+			Expect(models.ValidTimeZone("")).To(BeTrue())
+			Expect(models.ValidTimeZone("Mars/Olympus_Mons")).To(BeFalse())
+		})
+	})
+
+	Describe("ValidClockTime", func() {
+		It("accepts 24 hour HH:MM times", func() {
+			Expect(models.ValidClockTime("07:30")).To(BeTrue())
+			Expect(models.ValidClockTime("23:59")).To(BeTrue())
+			Expect(models.ValidClockTime("24:00")).To(BeFalse())
+			Expect(models.ValidClockTime("7pm")).To(BeFalse())
+			Expect(models.ValidClockTime("")).To(BeFalse())
+		})
+	})
+})
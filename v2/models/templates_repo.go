@@ -18,6 +18,7 @@ type Template struct {
 	ID       string `db:"id"`
 	Name     string `db:"name"`
 	HTML     string `db:"html"`
+	Markdown string `db:"markdown"`
 	Text     string `db:"text"`
 	Subject  string `db:"subject"`
 	Metadata string `db:"metadata"`
@@ -47,6 +47,12 @@ func (r UnsubscribersRepository) Get(connection ConnectionInterface, userGUID, c
 	return unsubscriber, err
 }
 
+func (r UnsubscribersRepository) ListByUserGUID(connection ConnectionInterface, userGUID string) ([]Unsubscriber, error) {
+	unsubscribers := []Unsubscriber{}
+	_, err := connection.Select(&unsubscribers, "SELECT * from `unsubscribers` WHERE user_guid = ?", userGUID)
+	return unsubscribers, err
+}
+
 func (r UnsubscribersRepository) Delete(connection ConnectionInterface, unsubscriber Unsubscriber) error {
 	_, err := connection.Exec("DELETE from `unsubscribers` WHERE user_guid = ? AND campaign_type_id = ?", unsubscriber.UserGUID, unsubscriber.CampaignTypeID)
 	return err
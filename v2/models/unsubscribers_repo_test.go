@@ -105,6 +105,42 @@ var _ = Describe("UnsubscribersRepo", func() {
 		})
 	})
 
+	Describe("ListByUserGUID", func() {
+		It("returns the campaign types the user is unsubscribed from", func() {
+			_, err := repo.Insert(conn, models.Unsubscriber{
+				CampaignTypeID: "some-campaign-type-id",
+				UserGUID:       "some-user-guid",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = repo.Insert(conn, models.Unsubscriber{
+				CampaignTypeID: "some-campaign-type-id",
+				UserGUID:       "other-user-guid",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			unsubscribers, err := repo.ListByUserGUID(conn, "some-user-guid")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(unsubscribers).To(Equal([]models.Unsubscriber{
+				{
+					ID:             "first-random-guid",
+					CampaignTypeID: "some-campaign-type-id",
+					UserGUID:       "some-user-guid",
+				},
+			}))
+		})
+
+		Context("when an unknown error happens", func() {
+			It("returns the error", func() {
+				connection := mocks.NewConnection()
+				connection.SelectCall.Returns.Error = errors.New("some other error")
+
+				_, err := repo.ListByUserGUID(connection, "some-user-guid")
+				Expect(err).To(MatchError(errors.New("some other error")))
+			})
+		})
+	})
+
 	Describe("Delete", func() {
 		It("deletes the specified record", func() {
 			_, err := repo.Insert(conn, models.Unsubscriber{
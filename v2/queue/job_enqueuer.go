@@ -13,9 +13,11 @@ import (
 const StatusQueued = "queued"
 
 type User struct {
-	GUID        string
-	Email       string
-	Endorsement string
+	GUID            string
+	Email           string
+	Endorsement     string
+	ResolvedEmail   string
+	EmailResolvedAt time.Time
 }
 
 type Response struct {
@@ -60,46 +62,93 @@ type Delivery struct {
 	VCAPRequestID   string
 	RequestReceived time.Time
 	CampaignID      string
+	ResolvedEmail   string
+	EmailResolvedAt time.Time
 }
 
 type messagesRepoInserter interface {
 	Insert(models.ConnectionInterface, models.Message) (models.Message, error)
 }
 
+type campaignRecipientsRepo interface {
+	Insert(models.ConnectionInterface, models.CampaignRecipient) error
+	ListExisting(conn models.ConnectionInterface, campaignID string, recipients []string) ([]string, error)
+}
+
 type gobbleInitializer interface {
 	InitializeDBMap(*gorp.DbMap)
 }
 
 type JobEnqueuer struct {
-	queue             enqueuer
-	messagesRepo      messagesRepoInserter
-	gobbleInitializer gobbleInitializer
+	queue                  enqueuer
+	messagesRepo           messagesRepoInserter
+	campaignRecipientsRepo campaignRecipientsRepo
+	gobbleInitializer      gobbleInitializer
 }
 
-func NewJobEnqueuer(queue enqueuer, messagesRepo messagesRepoInserter, gobbleInitializer gobbleInitializer) JobEnqueuer {
+func NewJobEnqueuer(queue enqueuer, messagesRepo messagesRepoInserter, campaignRecipientsRepo campaignRecipientsRepo, gobbleInitializer gobbleInitializer) JobEnqueuer {
 	return JobEnqueuer{
-		queue:             queue,
-		messagesRepo:      messagesRepo,
-		gobbleInitializer: gobbleInitializer,
+		queue:                  queue,
+		messagesRepo:           messagesRepo,
+		campaignRecipientsRepo: campaignRecipientsRepo,
+		gobbleInitializer:      gobbleInitializer,
 	}
 }
 
-func (enqueuer JobEnqueuer) Enqueue(conn ConnectionInterface, users []User, options Options, space cf.CloudControllerSpace, organization cf.CloudControllerOrganization, clientID, uaaHost, scope, vcapRequestID string, reqReceived time.Time, campaignID string) {
+// Enqueue queues a delivery for each user in a single transaction. Users that
+// already have a message queued for the campaign are skipped, so a batch can be
+// enqueued again after a failure without sending anyone a duplicate.
+func (enqueuer JobEnqueuer) Enqueue(conn ConnectionInterface, users []User, options Options, space cf.CloudControllerSpace, organization cf.CloudControllerOrganization, clientID, uaaHost, scope, vcapRequestID string, reqReceived time.Time, campaignID string) error {
 	transaction := conn.Transaction()
 	enqueuer.gobbleInitializer.InitializeDBMap(transaction.GetDbMap())
 
-	transaction.Begin()
+	err := transaction.Begin()
+	if err != nil {
+		return err
+	}
+
+	var recipients []string
+	for _, user := range users {
+		recipients = append(recipients, recipientKey(user))
+	}
+
+	existing, err := enqueuer.campaignRecipientsRepo.ListExisting(transaction, campaignID, recipients)
+	if err != nil {
+		transaction.Rollback()
+		return err
+	}
+
+	queued := map[string]bool{}
+	for _, recipient := range existing {
+		queued[recipient] = true
+	}
 
 	for _, user := range users {
+		recipient := recipientKey(user)
+		if queued[recipient] {
+			continue
+		}
+
 		message, err := enqueuer.messagesRepo.Insert(transaction, models.Message{
 			Status:     StatusQueued,
 			CampaignID: campaignID,
 		})
 		if err != nil {
 			transaction.Rollback()
-			return
+			return err
 		}
 
+		err = enqueuer.campaignRecipientsRepo.Insert(transaction, models.CampaignRecipient{
+			CampaignID: campaignID,
+			Recipient:  recipient,
+			MessageID:  message.ID,
+		})
+		if err != nil {
+			transaction.Rollback()
+			return err
+		}
+		queued[recipient] = true
+
 		options.Endorsement = user.Endorsement
 
 		job := gobble.NewJob(Delivery{
@@ -116,19 +165,24 @@ func (enqueuer JobEnqueuer) Enqueue(conn ConnectionInterface, users []User, opti
 			VCAPRequestID:   vcapRequestID,
 			RequestReceived: reqReceived,
 			CampaignID:      campaignID,
+			ResolvedEmail:   user.ResolvedEmail,
+			EmailResolvedAt: user.EmailResolvedAt,
 		})
 
 		_, err = enqueuer.queue.Enqueue(job, transaction)
 		if err != nil {
 			transaction.Rollback()
-			return
+			return err
 		}
+	}
 
-		recipient := user.Email
-		if recipient == "" {
-			recipient = user.GUID
-		}
+	return transaction.Commit()
+}
+
+func recipientKey(user User) string {
+	if user.GUID != "" {
+		return user.GUID
 	}
 
-	transaction.Commit()
+	return user.Email
 }
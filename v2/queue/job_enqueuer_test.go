@@ -27,6 +27,7 @@ var _ = Describe("JobEnqueuer", func() {
 		conn              *mocks.Connection
 		transaction       *mocks.Transaction
 		messagesRepo      *mocks.MessagesRepository
+		recipientsRepo    *mocks.CampaignRecipientsRepository
 		space             cf.CloudControllerSpace
 		org               cf.CloudControllerOrganization
 		reqReceived       time.Time
@@ -66,7 +67,9 @@ var _ = Describe("JobEnqueuer", func() {
 			},
 		})
 
-		enqueuer = queue.NewJobEnqueuer(gobbleQueue, messagesRepo, gobbleInitializer)
+		recipientsRepo = mocks.NewCampaignRecipientsRepository()
+
+		enqueuer = queue.NewJobEnqueuer(gobbleQueue, messagesRepo, recipientsRepo, gobbleInitializer)
 		space = cf.CloudControllerSpace{Name: "the-space"}
 		org = cf.CloudControllerOrganization{Name: "the-org"}
 		reqReceived, _ = time.Parse(time.RFC3339Nano, "2015-06-08T14:40:12.207187819-07:00")
@@ -184,6 +187,55 @@ var _ = Describe("JobEnqueuer", func() {
 			}))
 		})
 
+		It("carries addresses resolved during the fan-out onto the deliveries", func() {
+			resolvedAt := time.Date(2015, 1, 1, 12, 0, 0, 0, time.UTC)
+			users := []queue.User{{GUID: "user-1", ResolvedEmail: "user-1@example.com", EmailResolvedAt: resolvedAt}}
+			err := enqueuer.Enqueue(conn, users, queue.Options{}, space, org, "the-client", "my-uaa-host", "my.scope", "some-request-id", reqReceived, "some-campaign")
+			Expect(err).NotTo(HaveOccurred())
+
+			var delivery queue.Delivery
+			err = gobbleQueue.EnqueueCall.Receives.Jobs[0].Unmarshal(&delivery)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(delivery.UserGUID).To(Equal("user-1"))
+			Expect(delivery.Email).To(BeEmpty())
+			Expect(delivery.ResolvedEmail).To(Equal("user-1@example.com"))
+			Expect(delivery.EmailResolvedAt).To(Equal(resolvedAt))
+		})
+
+		It("records the recipient of each message", func() {
+			users := []queue.User{{GUID: "user-1"}, {Email: "user-2@example.com"}}
+			err := enqueuer.Enqueue(conn, users, queue.Options{}, space, org, "the-client", "my-uaa-host", "my.scope", "some-request-id", reqReceived, "some-campaign")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(recipientsRepo.ListExistingCall.Receives.Connection).To(Equal(transaction))
+			Expect(recipientsRepo.ListExistingCall.Receives.CampaignID).To(Equal("some-campaign"))
+			Expect(recipientsRepo.ListExistingCall.Receives.Recipients).To(Equal([]string{"user-1", "user-2@example.com"}))
+
+			Expect(recipientsRepo.InsertCall.Receives.Connection).To(Equal(transaction))
+			Expect(recipientsRepo.InsertCall.Receives.Recipients).To(Equal([]models.CampaignRecipient{
+				{CampaignID: "some-campaign", Recipient: "user-1", MessageID: "first-random-guid"},
+				{CampaignID: "some-campaign", Recipient: "user-2@example.com", MessageID: "second-random-guid"},
+			}))
+		})
+
+		It("skips recipients that already have a message queued for the campaign", func() {
+			recipientsRepo.ListExistingCall.Returns.Existing = []string{"user-1", "user-3"}
+
+			users := []queue.User{{GUID: "user-1"}, {GUID: "user-2"}, {GUID: "user-3"}, {GUID: "user-2"}}
+			err := enqueuer.Enqueue(conn, users, queue.Options{}, space, org, "the-client", "my-uaa-host", "my.scope", "some-request-id", reqReceived, "some-campaign")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(gobbleQueue.EnqueueCall.Receives.Jobs).To(HaveLen(1))
+
+			var delivery queue.Delivery
+			err = gobbleQueue.EnqueueCall.Receives.Jobs[0].Unmarshal(&delivery)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(delivery.UserGUID).To(Equal("user-2"))
+
+			Expect(recipientsRepo.InsertCall.CallCount).To(Equal(1))
+		})
+
 		Context("using a transaction", func() {
 			It("initializes the DbMap", func() {
 				users := []queue.User{{GUID: "user-1"}, {GUID: "user-2"}, {GUID: "user-3"}, {GUID: "user-4"}}
@@ -213,6 +265,26 @@ var _ = Describe("JobEnqueuer", func() {
 				Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
 			})
 
+			It("rolls back the transaction when the existing recipients cannot be listed", func() {
+				recipientsRepo.ListExistingCall.Returns.Error = errors.New("BOOM!")
+				users := []queue.User{{GUID: "user-1"}}
+				err := enqueuer.Enqueue(conn, users, queue.Options{}, space, org, "the-client", "my-uaa-host", "my.scope", "some-request-id", reqReceived, "some-campaign")
+				Expect(err).To(MatchError(errors.New("BOOM!")))
+
+				Expect(transaction.CommitCall.WasCalled).To(BeFalse())
+				Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+			})
+
+			It("rolls back the transaction when the recipient cannot be recorded", func() {
+				recipientsRepo.InsertCall.Returns.Error = errors.New("BOOM!")
+				users := []queue.User{{GUID: "user-1"}}
+				err := enqueuer.Enqueue(conn, users, queue.Options{}, space, org, "the-client", "my-uaa-host", "my.scope", "some-request-id", reqReceived, "some-campaign")
+				Expect(err).To(MatchError(errors.New("BOOM!")))
+
+				Expect(transaction.CommitCall.WasCalled).To(BeFalse())
+				Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+			})
+
 			It("rolls back the transaction when there is an error in enqueuing", func() {
 				gobbleQueue.EnqueueCall.Returns.Error = errors.New("BOOM!")
 				users := []queue.User{{GUID: "user-1"}}
@@ -0,0 +1,46 @@
+package audiences
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+)
+
+type Link struct {
+	Href string `json:"href"`
+}
+
+type AudienceResponseLinks struct {
+	Self Link `json:"self"`
+}
+
+type AudienceResponse struct {
+	ID        string                `json:"id"`
+	Name      string                `json:"name"`
+	Version   int                   `json:"version"`
+	Users     []string              `json:"users,omitempty"`
+	Spaces    []string              `json:"spaces,omitempty"`
+	Orgs      []string              `json:"orgs,omitempty"`
+	Emails    []string              `json:"emails,omitempty"`
+	Exclude   map[string][]string   `json:"exclude,omitempty"`
+	CreatedAt time.Time             `json:"created_at"`
+	UpdatedAt time.Time             `json:"updated_at"`
+	Links     AudienceResponseLinks `json:"_links"`
+}
+
+func NewAudienceResponse(audience collections.Audience) AudienceResponse {
+	return AudienceResponse{
+		ID:        audience.ID,
+		Name:      audience.Name,
+		Version:   audience.Version,
+		Users:     audience.Users,
+		Spaces:    audience.Spaces,
+		Orgs:      audience.Orgs,
+		Emails:    audience.Emails,
+		Exclude:   audience.Exclude,
+		CreatedAt: audience.CreatedAt,
+		UpdatedAt: audience.UpdatedAt,
+		Links:     AudienceResponseLinks{Link{fmt.Sprintf("/audiences/%s", audience.ID)}},
+	}
+}
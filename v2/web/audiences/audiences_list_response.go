@@ -0,0 +1,33 @@
+package audiences
+
+import (
+	"fmt"
+
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+)
+
+type AudiencesListResponseLinks struct {
+	Self   Link `json:"self"`
+	Sender Link `json:"sender"`
+}
+
+type AudiencesListResponse struct {
+	Audiences []AudienceResponse         `json:"audiences"`
+	Links     AudiencesListResponseLinks `json:"_links"`
+}
+
+func NewAudiencesListResponse(senderID string, audienceList []collections.Audience) AudiencesListResponse {
+	audiences := []AudienceResponse{}
+
+	for _, a := range audienceList {
+		audiences = append(audiences, NewAudienceResponse(a))
+	}
+
+	return AudiencesListResponse{
+		Audiences: audiences,
+		Links: AudiencesListResponseLinks{
+			Self:   Link{fmt.Sprintf("/senders/%s/audiences", senderID)},
+			Sender: Link{fmt.Sprintf("/senders/%s", senderID)},
+		},
+	}
+}
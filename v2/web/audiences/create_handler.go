@@ -0,0 +1,86 @@
+package audiences
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/ryanmoran/stack"
+)
+
+type collectionSetter interface {
+	Set(conn collections.ConnectionInterface, audience collections.Audience, clientID string) (collections.Audience, error)
+}
+
+type CreateHandler struct {
+	audiences collectionSetter
+}
+
+func NewCreateHandler(audiences collectionSetter) CreateHandler {
+	return CreateHandler{
+		audiences: audiences,
+	}
+}
+
+func (h CreateHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	splitURL := strings.Split(req.URL.Path, "/")
+	senderID := splitURL[len(splitURL)-2]
+
+	var createRequest struct {
+		Name    string              `json:"name"`
+		Users   []string            `json:"users"`
+		Spaces  []string            `json:"spaces"`
+		Orgs    []string            `json:"orgs"`
+		Emails  []string            `json:"emails"`
+		Exclude map[string][]string `json:"exclude"`
+	}
+
+	err := json.NewDecoder(req.Body).Decode(&createRequest)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, `{"errors": [%q]}`, "invalid json body")
+		return
+	}
+
+	if createRequest.Name == "" {
+		w.WriteHeader(422)
+		fmt.Fprintf(w, `{"errors": [%q]}`, `Audience "name" field cannot be empty`)
+		return
+	}
+
+	database := context.Get("database").(DatabaseInterface)
+
+	audience, err := h.audiences.Set(database.Connection(), collections.Audience{
+		Name:     createRequest.Name,
+		SenderID: senderID,
+		Users:    createRequest.Users,
+		Spaces:   createRequest.Spaces,
+		Orgs:     createRequest.Orgs,
+		Emails:   createRequest.Emails,
+		Exclude:  createRequest.Exclude,
+	}, context.Get("client_id").(string))
+	if err != nil {
+		writeSetError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(NewAudienceResponse(audience))
+}
+
+func writeSetError(w http.ResponseWriter, err error) {
+	switch err.(type) {
+	case collections.ValidationError:
+		w.WriteHeader(422)
+	case collections.NotFoundError:
+		w.WriteHeader(http.StatusNotFound)
+	case collections.DuplicateRecordError:
+		w.WriteHeader(http.StatusConflict)
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+
+	fmt.Fprintf(w, `{"errors": [%q]}`, err)
+}
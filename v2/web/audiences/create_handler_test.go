@@ -0,0 +1,161 @@
+package audiences_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/cloudfoundry-incubator/notifications/v2/web/audiences"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CreateHandler", func() {
+	var (
+		handler    audiences.CreateHandler
+		collection *mocks.AudiencesCollection
+		context    stack.Context
+		writer     *httptest.ResponseRecorder
+		request    *http.Request
+		conn       *mocks.Connection
+	)
+
+	BeforeEach(func() {
+		conn = mocks.NewConnection()
+		database := mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = conn
+
+		context = stack.NewContext()
+		context.Set("client_id", "some-client-id")
+		context.Set("database", database)
+
+		writer = httptest.NewRecorder()
+
+		requestBody, err := json.Marshal(map[string]interface{}{
+			"name":   "security-oncall",
+			"users":  []string{"some-user-guid"},
+			"emails": []string{"oncall@example.com"},
+			"exclude": map[string][]string{
+				"users": {"other-user-guid"},
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		request, err = http.NewRequest("POST", "/senders/some-sender-id/audiences", bytes.NewBuffer(requestBody))
+		Expect(err).NotTo(HaveOccurred())
+
+		collection = mocks.NewAudiencesCollection()
+		collection.SetCall.Returns.Audience = collections.Audience{
+			ID:        "some-audience-id",
+			Name:      "security-oncall",
+			SenderID:  "some-sender-id",
+			Version:   1,
+			Users:     []string{"some-user-guid"},
+			Emails:    []string{"oncall@example.com"},
+			Exclude:   map[string][]string{"users": {"other-user-guid"}},
+			CreatedAt: time.Date(2015, time.January, 1, 0, 0, 0, 0, time.UTC),
+			UpdatedAt: time.Date(2015, time.January, 1, 0, 0, 0, 0, time.UTC),
+		}
+
+		handler = audiences.NewCreateHandler(collection)
+	})
+
+	It("creates an audience", func() {
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusCreated))
+		Expect(writer.Body.String()).To(MatchJSON(`{
+			"id": "some-audience-id",
+			"name": "security-oncall",
+			"version": 1,
+			"users": ["some-user-guid"],
+			"emails": ["oncall@example.com"],
+			"exclude": {
+				"users": ["other-user-guid"]
+			},
+			"created_at": "2015-01-01T00:00:00Z",
+			"updated_at": "2015-01-01T00:00:00Z",
+			"_links": {
+				"self": {
+					"href": "/audiences/some-audience-id"
+				}
+			}
+		}`))
+
+		Expect(collection.SetCall.Receives.Connection).To(Equal(conn))
+		Expect(collection.SetCall.Receives.ClientID).To(Equal("some-client-id"))
+		Expect(collection.SetCall.Receives.Audience).To(Equal(collections.Audience{
+			Name:     "security-oncall",
+			SenderID: "some-sender-id",
+			Users:    []string{"some-user-guid"},
+			Emails:   []string{"oncall@example.com"},
+			Exclude:  map[string][]string{"users": {"other-user-guid"}},
+		}))
+	})
+
+	Context("failure cases", func() {
+		It("returns a 400 when the JSON is malformed", func() {
+			request, err := http.NewRequest("POST", "/senders/some-sender-id/audiences", strings.NewReader("%%"))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusBadRequest))
+			Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["invalid json body"]}`))
+		})
+
+		It("returns a 422 when the name is missing", func() {
+			request, err := http.NewRequest("POST", "/senders/some-sender-id/audiences", strings.NewReader(`{"users": ["some-user-guid"]}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(422))
+			Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["Audience \"name\" field cannot be empty"]}`))
+		})
+
+		It("returns a 422 when the definition is invalid", func() {
+			collection.SetCall.Returns.Error = collections.ValidationError{errors.New("An audience must include at least one of users, spaces, orgs or emails")}
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(422))
+			Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["An audience must include at least one of users, spaces, orgs or emails"]}`))
+		})
+
+		It("returns a 404 when the sender cannot be found", func() {
+			collection.SetCall.Returns.Error = collections.NotFoundError{errors.New(`Sender with id "some-sender-id" could not be found`)}
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusNotFound))
+			Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["Sender with id \"some-sender-id\" could not be found"]}`))
+		})
+
+		It("returns a 409 when the name is already taken", func() {
+			collection.SetCall.Returns.Error = collections.DuplicateRecordError{errors.New(`Audience with name "security-oncall" already exists`)}
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusConflict))
+			Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["Audience with name \"security-oncall\" already exists"]}`))
+		})
+
+		It("returns a 500 when the collection fails", func() {
+			collection.SetCall.Returns.Error = errors.New("failed to save")
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusInternalServerError))
+			Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["failed to save"]}`))
+		})
+	})
+})
@@ -0,0 +1,46 @@
+package audiences
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/ryanmoran/stack"
+)
+
+type collectionDeleter interface {
+	Delete(conn collections.ConnectionInterface, audienceID, clientID string) error
+}
+
+type DeleteHandler struct {
+	audiences collectionDeleter
+}
+
+func NewDeleteHandler(audiences collectionDeleter) DeleteHandler {
+	return DeleteHandler{
+		audiences: audiences,
+	}
+}
+
+func (h DeleteHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	splitURL := strings.Split(req.URL.Path, "/")
+	audienceID := splitURL[len(splitURL)-1]
+
+	database := context.Get("database").(DatabaseInterface)
+
+	err := h.audiences.Delete(database.Connection(), audienceID, context.Get("client_id").(string))
+	if err != nil {
+		switch err.(type) {
+		case collections.NotFoundError:
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+
+		fmt.Fprintf(w, `{"errors": [%q]}`, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
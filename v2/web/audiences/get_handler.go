@@ -0,0 +1,47 @@
+package audiences
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/ryanmoran/stack"
+)
+
+type collectionGetter interface {
+	Get(conn collections.ConnectionInterface, audienceID, clientID string) (collections.Audience, error)
+}
+
+type GetHandler struct {
+	audiences collectionGetter
+}
+
+func NewGetHandler(audiences collectionGetter) GetHandler {
+	return GetHandler{
+		audiences: audiences,
+	}
+}
+
+func (h GetHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	splitURL := strings.Split(req.URL.Path, "/")
+	audienceID := splitURL[len(splitURL)-1]
+
+	database := context.Get("database").(DatabaseInterface)
+
+	audience, err := h.audiences.Get(database.Connection(), audienceID, context.Get("client_id").(string))
+	if err != nil {
+		switch err.(type) {
+		case collections.NotFoundError:
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+
+		fmt.Fprintf(w, `{"errors": [%q]}`, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(NewAudienceResponse(audience))
+}
@@ -0,0 +1,102 @@
+package audiences_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/cloudfoundry-incubator/notifications/v2/web/audiences"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GetHandler", func() {
+	var (
+		handler    audiences.GetHandler
+		collection *mocks.AudiencesCollection
+		context    stack.Context
+		writer     *httptest.ResponseRecorder
+		request    *http.Request
+		conn       *mocks.Connection
+	)
+
+	BeforeEach(func() {
+		conn = mocks.NewConnection()
+		database := mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = conn
+
+		context = stack.NewContext()
+		context.Set("client_id", "some-client-id")
+		context.Set("database", database)
+
+		writer = httptest.NewRecorder()
+
+		var err error
+		request, err = http.NewRequest("GET", "/audiences/some-audience-id", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		collection = mocks.NewAudiencesCollection()
+		handler = audiences.NewGetHandler(collection)
+	})
+
+	It("gets an audience", func() {
+		collection.GetCall.Returns.Audience = collections.Audience{
+			ID:       "some-audience-id",
+			Name:     "platform-admins",
+			SenderID: "some-sender-id",
+			Version:  4,
+			Spaces:   []string{"some-space-guid"},
+			Orgs:     []string{"some-org-guid"},
+			Exclude:  map[string][]string{"emails": {"bot@example.com"}},
+		}
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.String()).To(MatchJSON(`{
+			"id": "some-audience-id",
+			"name": "platform-admins",
+			"version": 4,
+			"spaces": ["some-space-guid"],
+			"orgs": ["some-org-guid"],
+			"exclude": {
+				"emails": ["bot@example.com"]
+			},
+			"created_at": "0001-01-01T00:00:00Z",
+			"updated_at": "0001-01-01T00:00:00Z",
+			"_links": {
+				"self": {
+					"href": "/audiences/some-audience-id"
+				}
+			}
+		}`))
+
+		Expect(collection.GetCall.Receives.Connection).To(Equal(conn))
+		Expect(collection.GetCall.Receives.AudienceID).To(Equal("some-audience-id"))
+		Expect(collection.GetCall.Receives.ClientID).To(Equal("some-client-id"))
+	})
+
+	Context("failure cases", func() {
+		It("returns a 404 when the audience cannot be found", func() {
+			collection.GetCall.Returns.Error = collections.NotFoundError{errors.New("it was not found")}
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusNotFound))
+			Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["it was not found"]}`))
+		})
+
+		It("returns a 500 when the collection fails", func() {
+			collection.GetCall.Returns.Error = errors.New("failed to get")
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusInternalServerError))
+			Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["failed to get"]}`))
+		})
+	})
+})
@@ -0,0 +1,13 @@
+package audiences_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestWebV2AudiencesSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "v2/web/audiences")
+}
@@ -0,0 +1,47 @@
+package audiences
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/ryanmoran/stack"
+)
+
+type collectionLister interface {
+	List(conn collections.ConnectionInterface, senderID, clientID string) ([]collections.Audience, error)
+}
+
+type ListHandler struct {
+	audiences collectionLister
+}
+
+func NewListHandler(audiences collectionLister) ListHandler {
+	return ListHandler{
+		audiences: audiences,
+	}
+}
+
+func (h ListHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	splitURL := strings.Split(req.URL.Path, "/")
+	senderID := splitURL[len(splitURL)-2]
+
+	database := context.Get("database").(DatabaseInterface)
+
+	audiences, err := h.audiences.List(database.Connection(), senderID, context.Get("client_id").(string))
+	if err != nil {
+		switch err.(type) {
+		case collections.NotFoundError:
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+
+		fmt.Fprintf(w, `{"errors": [%q]}`, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(NewAudiencesListResponse(senderID, audiences))
+}
@@ -0,0 +1,129 @@
+package audiences_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/cloudfoundry-incubator/notifications/v2/web/audiences"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ListHandler", func() {
+	var (
+		handler    audiences.ListHandler
+		collection *mocks.AudiencesCollection
+		context    stack.Context
+		writer     *httptest.ResponseRecorder
+		request    *http.Request
+		conn       *mocks.Connection
+	)
+
+	BeforeEach(func() {
+		conn = mocks.NewConnection()
+		database := mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = conn
+
+		context = stack.NewContext()
+		context.Set("client_id", "some-client-id")
+		context.Set("database", database)
+
+		writer = httptest.NewRecorder()
+
+		var err error
+		request, err = http.NewRequest("GET", "/senders/some-sender-id/audiences", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		collection = mocks.NewAudiencesCollection()
+		handler = audiences.NewListHandler(collection)
+	})
+
+	It("lists the audiences belonging to the sender", func() {
+		collection.ListCall.Returns.Audiences = []collections.Audience{
+			{
+				ID:       "some-audience-id",
+				Name:     "security-oncall",
+				SenderID: "some-sender-id",
+				Version:  2,
+				Users:    []string{"some-user-guid"},
+			},
+		}
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.String()).To(MatchJSON(`{
+			"audiences": [
+				{
+					"id": "some-audience-id",
+					"name": "security-oncall",
+					"version": 2,
+					"users": ["some-user-guid"],
+					"created_at": "0001-01-01T00:00:00Z",
+					"updated_at": "0001-01-01T00:00:00Z",
+					"_links": {
+						"self": {
+							"href": "/audiences/some-audience-id"
+						}
+					}
+				}
+			],
+			"_links": {
+				"self": {
+					"href": "/senders/some-sender-id/audiences"
+				},
+				"sender": {
+					"href": "/senders/some-sender-id"
+				}
+			}
+		}`))
+
+		Expect(collection.ListCall.Receives.Connection).To(Equal(conn))
+		Expect(collection.ListCall.Receives.SenderID).To(Equal("some-sender-id"))
+		Expect(collection.ListCall.Receives.ClientID).To(Equal("some-client-id"))
+	})
+
+	It("returns an empty list when the sender has no audiences", func() {
+		collection.ListCall.Returns.Audiences = []collections.Audience{}
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.String()).To(MatchJSON(`{
+			"audiences": [],
+			"_links": {
+				"self": {
+					"href": "/senders/some-sender-id/audiences"
+				},
+				"sender": {
+					"href": "/senders/some-sender-id"
+				}
+			}
+		}`))
+	})
+
+	Context("failure cases", func() {
+		It("returns a 404 when the sender cannot be found", func() {
+			collection.ListCall.Returns.Error = collections.NotFoundError{errors.New(`Sender with id "some-sender-id" could not be found`)}
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusNotFound))
+			Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["Sender with id \"some-sender-id\" could not be found"]}`))
+		})
+
+		It("returns a 500 when the collection fails", func() {
+			collection.ListCall.Returns.Error = errors.New("failed to list")
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusInternalServerError))
+			Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["failed to list"]}`))
+		})
+	})
+})
@@ -0,0 +1,25 @@
+package audiences
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/ryanmoran/stack"
+)
+
+type muxer interface {
+	Handle(method, path string, handler stack.Handler, middleware ...stack.Middleware)
+}
+
+type Routes struct {
+	RequestLogging      stack.Middleware
+	Authenticator       stack.Middleware
+	DatabaseAllocator   stack.Middleware
+	AudiencesCollection collections.AudiencesCollection
+}
+
+func (r Routes) Register(m muxer) {
+	m.Handle("POST", "/senders/{sender_id}/audiences", NewCreateHandler(r.AudiencesCollection), r.RequestLogging, r.Authenticator, r.DatabaseAllocator)
+	m.Handle("GET", "/senders/{sender_id}/audiences", NewListHandler(r.AudiencesCollection), r.RequestLogging, r.Authenticator, r.DatabaseAllocator)
+	m.Handle("GET", "/audiences/{audience_id}", NewGetHandler(r.AudiencesCollection), r.RequestLogging, r.Authenticator, r.DatabaseAllocator)
+	m.Handle("PUT", "/audiences/{audience_id}", NewUpdateHandler(r.AudiencesCollection), r.RequestLogging, r.Authenticator, r.DatabaseAllocator)
+	m.Handle("DELETE", "/audiences/{audience_id}", NewDeleteHandler(r.AudiencesCollection), r.RequestLogging, r.Authenticator, r.DatabaseAllocator)
+}
@@ -0,0 +1,64 @@
+package audiences
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/ryanmoran/stack"
+)
+
+type collectionUpdater interface {
+	Update(conn collections.ConnectionInterface, audienceID, clientID string, update collections.AudienceUpdate) (collections.Audience, error)
+}
+
+type UpdateHandler struct {
+	audiences collectionUpdater
+}
+
+func NewUpdateHandler(audiences collectionUpdater) UpdateHandler {
+	return UpdateHandler{
+		audiences: audiences,
+	}
+}
+
+func (h UpdateHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	splitURL := strings.Split(req.URL.Path, "/")
+	audienceID := splitURL[len(splitURL)-1]
+
+	var updateRequest struct {
+		Name    *string              `json:"name"`
+		Users   *[]string            `json:"users"`
+		Spaces  *[]string            `json:"spaces"`
+		Orgs    *[]string            `json:"orgs"`
+		Emails  *[]string            `json:"emails"`
+		Exclude *map[string][]string `json:"exclude"`
+	}
+
+	err := json.NewDecoder(req.Body).Decode(&updateRequest)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, `{"errors": [%q]}`, "invalid json body")
+		return
+	}
+
+	database := context.Get("database").(DatabaseInterface)
+	clientID := context.Get("client_id").(string)
+
+	audience, err := h.audiences.Update(database.Connection(), audienceID, clientID, collections.AudienceUpdate{
+		Name:    updateRequest.Name,
+		Users:   updateRequest.Users,
+		Spaces:  updateRequest.Spaces,
+		Orgs:    updateRequest.Orgs,
+		Emails:  updateRequest.Emails,
+		Exclude: updateRequest.Exclude,
+	})
+	if err != nil {
+		writeSetError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(NewAudienceResponse(audience))
+}
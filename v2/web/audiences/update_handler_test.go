@@ -0,0 +1,152 @@
+package audiences_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/cloudfoundry-incubator/notifications/v2/web/audiences"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("UpdateHandler", func() {
+	var (
+		handler    audiences.UpdateHandler
+		collection *mocks.AudiencesCollection
+		context    stack.Context
+		writer     *httptest.ResponseRecorder
+		conn       *mocks.Connection
+	)
+
+	BeforeEach(func() {
+		conn = mocks.NewConnection()
+		database := mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = conn
+
+		context = stack.NewContext()
+		context.Set("client_id", "some-client-id")
+		context.Set("database", database)
+
+		writer = httptest.NewRecorder()
+
+		collection = mocks.NewAudiencesCollection()
+		collection.UpdateCall.Returns.Audience = collections.Audience{
+			ID:       "some-audience-id",
+			Name:     "security-oncall",
+			SenderID: "some-sender-id",
+			Version:  2,
+			Users:    []string{"other-user-guid"},
+			Emails:   []string{"oncall@example.com"},
+		}
+
+		handler = audiences.NewUpdateHandler(collection)
+	})
+
+	It("saves the given fields of an audience as a new version", func() {
+		request, err := http.NewRequest("PUT", "/audiences/some-audience-id", strings.NewReader(`{"users": ["other-user-guid"]}`))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.String()).To(MatchJSON(`{
+			"id": "some-audience-id",
+			"name": "security-oncall",
+			"version": 2,
+			"users": ["other-user-guid"],
+			"emails": ["oncall@example.com"],
+			"created_at": "0001-01-01T00:00:00Z",
+			"updated_at": "0001-01-01T00:00:00Z",
+			"_links": {
+				"self": {
+					"href": "/audiences/some-audience-id"
+				}
+			}
+		}`))
+
+		users := []string{"other-user-guid"}
+		Expect(collection.UpdateCall.Receives.Connection).To(Equal(conn))
+		Expect(collection.UpdateCall.Receives.AudienceID).To(Equal("some-audience-id"))
+		Expect(collection.UpdateCall.Receives.ClientID).To(Equal("some-client-id"))
+		Expect(collection.UpdateCall.Receives.Update).To(Equal(collections.AudienceUpdate{
+			Users: &users,
+		}))
+	})
+
+	Context("failure cases", func() {
+		It("returns a 400 when the JSON is malformed", func() {
+			request, err := http.NewRequest("PUT", "/audiences/some-audience-id", strings.NewReader("%%"))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusBadRequest))
+			Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["invalid json body"]}`))
+		})
+
+		It("returns a 404 when the audience cannot be found", func() {
+			collection.UpdateCall.Returns.Error = collections.NotFoundError{errors.New("it was not found")}
+
+			request, err := http.NewRequest("PUT", "/audiences/some-audience-id", strings.NewReader(`{}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusNotFound))
+			Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["it was not found"]}`))
+		})
+
+		It("returns a 422 when the name is cleared", func() {
+			collection.UpdateCall.Returns.Error = collections.ValidationError{errors.New(`Audience "name" field cannot be empty`)}
+
+			request, err := http.NewRequest("PUT", "/audiences/some-audience-id", strings.NewReader(`{"name": ""}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(422))
+			Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["Audience \"name\" field cannot be empty"]}`))
+		})
+
+		It("returns a 422 when the updated definition is invalid", func() {
+			collection.UpdateCall.Returns.Error = collections.ValidationError{errors.New("An audience must include at least one of users, spaces, orgs or emails")}
+
+			request, err := http.NewRequest("PUT", "/audiences/some-audience-id", strings.NewReader(`{"users": [], "emails": []}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(422))
+			Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["An audience must include at least one of users, spaces, orgs or emails"]}`))
+		})
+
+		It("returns a 409 when the new name is already taken", func() {
+			collection.UpdateCall.Returns.Error = collections.DuplicateRecordError{errors.New("duplicate")}
+
+			request, err := http.NewRequest("PUT", "/audiences/some-audience-id", strings.NewReader(`{"name": "taken"}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusConflict))
+			Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["duplicate"]}`))
+		})
+
+		It("returns a 500 when the audience cannot be saved", func() {
+			collection.UpdateCall.Returns.Error = collections.PersistenceError{errors.New("database is down")}
+
+			request, err := http.NewRequest("PUT", "/audiences/some-audience-id", strings.NewReader(`{"name": "renamed"}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusInternalServerError))
+		})
+	})
+})
@@ -0,0 +1,49 @@
+package bundles
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/v2/bundle"
+	"github.com/ryanmoran/stack"
+)
+
+const tarContentType = "application/x-tar"
+
+type exporter interface {
+	Export(conn db.ConnectionInterface, clientID string) (bundle.Bundle, error)
+}
+
+type ExportHandler struct {
+	bundler exporter
+}
+
+func NewExportHandler(bundler exporter) ExportHandler {
+	return ExportHandler{
+		bundler: bundler,
+	}
+}
+
+func (h ExportHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	database := context.Get("database").(DatabaseInterface)
+	clientID := context.Get("client_id").(string)
+
+	exported, err := h.bundler.Export(database.Connection(), clientID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"errors": [%q]}`, err)
+		return
+	}
+
+	if strings.Contains(req.Header.Get("Accept"), tarContentType) {
+		w.Header().Set("Content-Type", tarContentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", clientID+"-bundle.tar"))
+		exported.WriteTar(w)
+		return
+	}
+
+	json.NewEncoder(w).Encode(exported)
+}
@@ -0,0 +1,135 @@
+package bundles_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v2/bundle"
+	"github.com/cloudfoundry-incubator/notifications/v2/web/bundles"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ExportHandler", func() {
+	var (
+		handler bundles.ExportHandler
+		bundler *mocks.Bundler
+		context stack.Context
+		writer  *httptest.ResponseRecorder
+		request *http.Request
+		conn    *mocks.Connection
+	)
+
+	BeforeEach(func() {
+		conn = mocks.NewConnection()
+		database := mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = conn
+
+		context = stack.NewContext()
+		context.Set("client_id", "some-client-id")
+		context.Set("database", database)
+
+		writer = httptest.NewRecorder()
+
+		var err error
+		request, err = http.NewRequest("GET", "/bundle", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		bundler = mocks.NewBundler()
+		bundler.ExportCall.Returns.Bundle = bundle.Bundle{
+			Version:  bundle.Version,
+			ClientID: "some-client-id",
+			Templates: []bundle.Template{
+				{ID: "template-id", Name: "a template", Subject: "{{.Subject}}", Text: "text"},
+			},
+			Senders: []bundle.Sender{
+				{
+					ID:   "sender-id",
+					Name: "a sender",
+					CampaignTypes: []bundle.CampaignType{
+						{ID: "campaign-type-id", Name: "a campaign type", TemplateID: "template-id"},
+					},
+				},
+			},
+			V1Templates: []bundle.V1Template{},
+			V1Assignments: []bundle.V1Assignment{
+				{TemplateID: "default"},
+			},
+		}
+
+		handler = bundles.NewExportHandler(bundler)
+	})
+
+	It("exports the client's bundle as JSON", func() {
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.String()).To(MatchJSON(`{
+			"version": 1,
+			"client_id": "some-client-id",
+			"templates": [
+				{
+					"id": "template-id",
+					"name": "a template",
+					"subject": "{{.Subject}}",
+					"text": "text",
+					"html": "",
+					"markdown": "",
+					"metadata": ""
+				}
+			],
+			"senders": [
+				{
+					"id": "sender-id",
+					"name": "a sender",
+					"campaign_types": [
+						{
+							"id": "campaign-type-id",
+							"name": "a campaign type",
+							"description": "",
+							"critical": false,
+							"template_id": "template-id"
+						}
+					]
+				}
+			],
+			"v1_templates": [],
+			"v1_assignments": [
+				{
+					"kind_id": "",
+					"template_id": "default"
+				}
+			]
+		}`))
+
+		Expect(bundler.ExportCall.Receives.Connection).To(Equal(conn))
+		Expect(bundler.ExportCall.Receives.ClientID).To(Equal("some-client-id"))
+	})
+
+	It("exports the client's bundle as a tar archive when asked to", func() {
+		request.Header.Set("Accept", "application/x-tar")
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Header().Get("Content-Type")).To(Equal("application/x-tar"))
+		Expect(writer.Header().Get("Content-Disposition")).To(Equal(`attachment; filename="some-client-id-bundle.tar"`))
+
+		exported, err := bundle.ReadTar(writer.Body)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(exported).To(Equal(bundler.ExportCall.Returns.Bundle))
+	})
+
+	It("returns a 500 when the export fails", func() {
+		bundler.ExportCall.Returns.Error = errors.New("failed to export")
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusInternalServerError))
+		Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["failed to export"]}`))
+	})
+})
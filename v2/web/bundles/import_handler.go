@@ -0,0 +1,107 @@
+package bundles
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/v2/bundle"
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/ryanmoran/stack"
+)
+
+type importer interface {
+	Import(conn db.ConnectionInterface, clientID string, imported bundle.Bundle, dryRun bool) (bundle.Diff, error)
+}
+
+type ImportHandler struct {
+	bundler importer
+}
+
+func NewImportHandler(bundler importer) ImportHandler {
+	return ImportHandler{
+		bundler: bundler,
+	}
+}
+
+func (h ImportHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	var dryRun bool
+	if value := req.URL.Query().Get("dry_run"); value != "" {
+		var err error
+		dryRun, err = strconv.ParseBool(value)
+		if err != nil {
+			w.WriteHeader(422)
+			fmt.Fprintf(w, `{"errors": [%q]}`, `"dry_run" must be a boolean`)
+			return
+		}
+	}
+
+	var (
+		imported bundle.Bundle
+		err      error
+	)
+
+	if strings.HasPrefix(req.Header.Get("Content-Type"), tarContentType) {
+		imported, err = bundle.ReadTar(req.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, `{"errors": [%q]}`, "invalid tar body")
+			return
+		}
+	} else {
+		err = json.NewDecoder(req.Body).Decode(&imported)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, `{"errors": [%q]}`, "invalid json body")
+			return
+		}
+	}
+
+	if (len(imported.V1Templates) > 0 || len(imported.V1Assignments) > 0) && !canWriteV1Templates(context) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprintf(w, `{"errors": [%q]}`, "Forbidden: importing v1 templates requires the notification_templates.write or notifications.manage scope")
+		return
+	}
+
+	database := context.Get("database").(DatabaseInterface)
+
+	diff, err := h.bundler.Import(database.Connection(), context.Get("client_id").(string), imported, dryRun)
+	if err != nil {
+		switch err.(type) {
+		case bundle.ValidationError:
+			w.WriteHeader(422)
+		case collections.DuplicateRecordError:
+			w.WriteHeader(http.StatusConflict)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+
+		fmt.Fprintf(w, `{"errors": [%q]}`, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(diff)
+}
+
+// canWriteV1Templates reports whether the token carries one of the scopes the
+// v1 API requires to write templates and assign them.
+func canWriteV1Templates(context stack.Context) bool {
+	token, ok := context.Get("token").(*jwt.Token)
+	if !ok {
+		return false
+	}
+
+	scopes, _ := token.Claims["scope"].([]interface{})
+	for _, scope := range scopes {
+		switch scope {
+		case "notification_templates.write", "notifications.manage":
+			return true
+		}
+	}
+
+	return false
+}
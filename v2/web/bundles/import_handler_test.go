@@ -0,0 +1,246 @@
+package bundles_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/application"
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v2/bundle"
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/cloudfoundry-incubator/notifications/v2/web/bundles"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func scopedToken(scopes ...string) *jwt.Token {
+	tokenHeader := map[string]interface{}{
+		"alg": "FAST",
+	}
+	tokenClaims := map[string]interface{}{
+		"client_id": "some-client-id",
+		"exp":       int64(3404281214),
+		"scope":     scopes,
+	}
+	token, err := jwt.Parse(helpers.BuildToken(tokenHeader, tokenClaims), func(*jwt.Token) (interface{}, error) {
+		return []byte(application.UAAPublicKey), nil
+	})
+	Expect(err).NotTo(HaveOccurred())
+
+	return token
+}
+
+var _ = Describe("ImportHandler", func() {
+	var (
+		handler     bundles.ImportHandler
+		bundler     *mocks.Bundler
+		context     stack.Context
+		writer      *httptest.ResponseRecorder
+		conn        *mocks.Connection
+		requestBody string
+	)
+
+	BeforeEach(func() {
+		conn = mocks.NewConnection()
+		database := mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = conn
+
+		context = stack.NewContext()
+		context.Set("client_id", "some-client-id")
+		context.Set("database", database)
+		context.Set("token", scopedToken("notifications.write"))
+
+		writer = httptest.NewRecorder()
+
+		requestBody = `{
+			"version": 1,
+			"client_id": "other-client-id",
+			"templates": [
+				{"id": "template-id", "name": "a template", "text": "text"}
+			]
+		}`
+
+		bundler = mocks.NewBundler()
+		bundler.ImportCall.Returns.Diff = bundle.Diff{
+			Changes: []bundle.Change{
+				{
+					Resource: bundle.ResourceTemplate,
+					Action:   bundle.ActionCreate,
+					Name:     "a template",
+					SourceID: "template-id",
+					ID:       "new-template-id",
+				},
+			},
+		}
+
+		handler = bundles.NewImportHandler(bundler)
+	})
+
+	It("imports a JSON bundle and responds with the diff", func() {
+		request, err := http.NewRequest("POST", "/bundle", strings.NewReader(requestBody))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.String()).To(MatchJSON(`{
+			"dry_run": false,
+			"changes": [
+				{
+					"resource": "template",
+					"action": "create",
+					"name": "a template",
+					"source_id": "template-id",
+					"id": "new-template-id"
+				}
+			]
+		}`))
+
+		Expect(bundler.ImportCall.Receives.Connection).To(Equal(conn))
+		Expect(bundler.ImportCall.Receives.ClientID).To(Equal("some-client-id"))
+		Expect(bundler.ImportCall.Receives.DryRun).To(BeFalse())
+		Expect(bundler.ImportCall.Receives.Bundle).To(Equal(bundle.Bundle{
+			Version:  1,
+			ClientID: "other-client-id",
+			Templates: []bundle.Template{
+				{ID: "template-id", Name: "a template", Text: "text"},
+			},
+		}))
+	})
+
+	It("imports a tar bundle", func() {
+		archive := bytes.NewBuffer([]byte{})
+		err := bundle.Bundle{Version: 1, ClientID: "other-client-id"}.WriteTar(archive)
+		Expect(err).NotTo(HaveOccurred())
+
+		request, err := http.NewRequest("POST", "/bundle", archive)
+		Expect(err).NotTo(HaveOccurred())
+		request.Header.Set("Content-Type", "application/x-tar")
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(bundler.ImportCall.Receives.Bundle.ClientID).To(Equal("other-client-id"))
+	})
+
+	It("passes the dry_run flag through", func() {
+		request, err := http.NewRequest("POST", "/bundle?dry_run=true", strings.NewReader(requestBody))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(bundler.ImportCall.Receives.DryRun).To(BeTrue())
+	})
+
+	Context("when the bundle contains v1 templates", func() {
+		BeforeEach(func() {
+			requestBody = `{
+				"version": 1,
+				"client_id": "other-client-id",
+				"v1_templates": [
+					{"id": "v1-template-id", "name": "a v1 template", "text": "text"}
+				]
+			}`
+		})
+
+		It("imports them when the token can write v1 templates", func() {
+			context.Set("token", scopedToken("notifications.write", "notification_templates.write"))
+
+			request, err := http.NewRequest("POST", "/bundle", strings.NewReader(requestBody))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusOK))
+			Expect(bundler.ImportCall.Receives.Bundle.V1Templates).To(HaveLen(1))
+		})
+
+		It("returns a 403 when the token cannot write v1 templates", func() {
+			request, err := http.NewRequest("POST", "/bundle", strings.NewReader(requestBody))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusForbidden))
+			Expect(writer.Body.String()).To(ContainSubstring("notification_templates.write"))
+			Expect(bundler.ImportCall.Receives.Bundle.V1Templates).To(BeEmpty())
+		})
+	})
+
+	Context("failure cases", func() {
+		It("returns a 400 when the JSON is malformed", func() {
+			request, err := http.NewRequest("POST", "/bundle", strings.NewReader("%%"))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusBadRequest))
+			Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["invalid json body"]}`))
+		})
+
+		It("returns a 400 when the tar archive is malformed", func() {
+			request, err := http.NewRequest("POST", "/bundle", strings.NewReader("not a tar"))
+			Expect(err).NotTo(HaveOccurred())
+			request.Header.Set("Content-Type", "application/x-tar")
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusBadRequest))
+			Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["invalid tar body"]}`))
+		})
+
+		It("returns a 422 when dry_run is not a boolean", func() {
+			request, err := http.NewRequest("POST", "/bundle?dry_run=banana", strings.NewReader(requestBody))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(422))
+			Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["\"dry_run\" must be a boolean"]}`))
+		})
+
+		It("returns a 422 when the bundle is invalid", func() {
+			bundler.ImportCall.Returns.Error = bundle.ValidationError{errors.New("unsupported bundle version 2")}
+
+			request, err := http.NewRequest("POST", "/bundle", strings.NewReader(requestBody))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(422))
+			Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["unsupported bundle version 2"]}`))
+		})
+
+		It("returns a 409 when a record conflicts", func() {
+			bundler.ImportCall.Returns.Error = collections.DuplicateRecordError{errors.New("duplicate")}
+
+			request, err := http.NewRequest("POST", "/bundle", strings.NewReader(requestBody))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusConflict))
+			Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["duplicate"]}`))
+		})
+
+		It("returns a 500 when the import fails", func() {
+			bundler.ImportCall.Returns.Error = errors.New("failed to import")
+
+			request, err := http.NewRequest("POST", "/bundle", strings.NewReader(requestBody))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusInternalServerError))
+			Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["failed to import"]}`))
+		})
+	})
+})
@@ -0,0 +1,17 @@
+package bundles_test
+
+import (
+	"testing"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestWebV2BundlesSuite(t *testing.T) {
+	helpers.RegisterFastTokenSigningMethod()
+
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "v2/web/bundles")
+}
@@ -0,0 +1,22 @@
+package bundles
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/v2/bundle"
+	"github.com/ryanmoran/stack"
+)
+
+type muxer interface {
+	Handle(method, path string, handler stack.Handler, middleware ...stack.Middleware)
+}
+
+type Routes struct {
+	RequestLogging    stack.Middleware
+	Authenticator     stack.Middleware
+	DatabaseAllocator stack.Middleware
+	Bundler           bundle.Bundler
+}
+
+func (r Routes) Register(m muxer) {
+	m.Handle("GET", "/bundle", NewExportHandler(r.Bundler), r.RequestLogging, r.Authenticator, r.DatabaseAllocator)
+	m.Handle("POST", "/bundle", NewImportHandler(r.Bundler), r.RequestLogging, r.Authenticator, r.DatabaseAllocator)
+}
@@ -0,0 +1,75 @@
+package bundles_test
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v2/bundle"
+	"github.com/cloudfoundry-incubator/notifications/v2/web/bundles"
+	"github.com/cloudfoundry-incubator/notifications/v2/web/middleware"
+	"github.com/cloudfoundry-incubator/notifications/web"
+	"github.com/pivotal-golang/lager"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Routes", func() {
+	var (
+		logging     middleware.RequestLogging
+		dbAllocator middleware.DatabaseAllocator
+		auth        middleware.Authenticator
+		muxer       web.Muxer
+	)
+
+	BeforeEach(func() {
+		logging = middleware.NewRequestLogging(lager.NewLogger("log-prefix"), mocks.NewClock())
+		auth = middleware.NewAuthenticator("some-public-key", "notifications.write")
+		dbAllocator = middleware.NewDatabaseAllocator(&sql.DB{}, false)
+		muxer = web.NewMuxer()
+		bundles.Routes{
+			RequestLogging:    logging,
+			Authenticator:     auth,
+			DatabaseAllocator: dbAllocator,
+			Bundler:           bundle.Bundler{},
+		}.Register(muxer)
+	})
+
+	It("routes GET /bundle", func() {
+		request, err := http.NewRequest("GET", "/bundle", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		s := muxer.Match(request).(stack.Stack)
+		Expect(s.Handler).To(BeAssignableToTypeOf(bundles.ExportHandler{}))
+		Expect(s.Middleware).To(HaveLen(3))
+
+		requestLogging := s.Middleware[0].(middleware.RequestLogging)
+		Expect(requestLogging).To(Equal(logging))
+
+		authenticator := s.Middleware[1].(middleware.Authenticator)
+		Expect(authenticator).To(Equal(auth))
+
+		databaseAllocator := s.Middleware[2].(middleware.DatabaseAllocator)
+		Expect(databaseAllocator).To(Equal(dbAllocator))
+	})
+
+	It("routes POST /bundle", func() {
+		request, err := http.NewRequest("POST", "/bundle", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		s := muxer.Match(request).(stack.Stack)
+		Expect(s.Handler).To(BeAssignableToTypeOf(bundles.ImportHandler{}))
+		Expect(s.Middleware).To(HaveLen(3))
+
+		requestLogging := s.Middleware[0].(middleware.RequestLogging)
+		Expect(requestLogging).To(Equal(logging))
+
+		authenticator := s.Middleware[1].(middleware.Authenticator)
+		Expect(authenticator).To(Equal(auth))
+
+		databaseAllocator := s.Middleware[2].(middleware.DatabaseAllocator)
+		Expect(databaseAllocator).To(Equal(dbAllocator))
+	})
+})
@@ -0,0 +1,36 @@
+package campaigns
+
+import "github.com/cloudfoundry-incubator/notifications/v2/collections"
+
+type PreviewRecipientResponse struct {
+	GUID  string `json:"guid,omitempty"`
+	Email string `json:"email,omitempty"`
+}
+
+type CampaignPreviewResponse struct {
+	TotalRecipients        int                        `json:"total_recipients"`
+	Audiences              map[string]int             `json:"audiences"`
+	ExcludedRecipients     int                        `json:"excluded_recipients"`
+	UnsubscribedRecipients int                        `json:"unsubscribed_recipients"`
+	UnsubscribesEstimated  bool                       `json:"unsubscribes_estimated"`
+	Sample                 []PreviewRecipientResponse `json:"sample"`
+}
+
+func NewCampaignPreviewResponse(preview collections.CampaignPreview) CampaignPreviewResponse {
+	sample := []PreviewRecipientResponse{}
+	for _, recipient := range preview.Sample {
+		sample = append(sample, PreviewRecipientResponse{
+			GUID:  recipient.GUID,
+			Email: recipient.Email,
+		})
+	}
+
+	return CampaignPreviewResponse{
+		TotalRecipients:        preview.TotalRecipients,
+		Audiences:              preview.Audiences,
+		ExcludedRecipients:     preview.ExcludedRecipients,
+		UnsubscribedRecipients: preview.UnsubscribedRecipients,
+		UnsubscribesEstimated:  preview.UnsubscribesEstimated,
+		Sample:                 sample,
+	}
+}
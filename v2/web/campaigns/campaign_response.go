@@ -18,27 +18,31 @@ type CampaignResponseLinks struct {
 }
 
 type CampaignResponse struct {
-	ID             string                `json:"id"`
-	SendTo         map[string][]string   `json:"send_to"`
-	CampaignTypeID string                `json:"campaign_type_id"`
-	Text           string                `json:"text"`
-	HTML           string                `json:"html"`
-	Subject        string                `json:"subject"`
-	TemplateID     string                `json:"template_id"`
-	ReplyTo        string                `json:"reply_to"`
-	Links          CampaignResponseLinks `json:"_links"`
+	ID             string                 `json:"id"`
+	SendTo         map[string]interface{} `json:"send_to"`
+	CampaignTypeID string                 `json:"campaign_type_id"`
+	Text           string                 `json:"text"`
+	HTML           string                 `json:"html"`
+	Markdown       string                 `json:"markdown"`
+	Subject        string                 `json:"subject"`
+	TemplateID     string                 `json:"template_id"`
+	ReplyTo        string                 `json:"reply_to"`
+	BusinessHours  bool                   `json:"local_business_hours"`
+	Links          CampaignResponseLinks  `json:"_links"`
 }
 
 func NewCampaignResponse(campaign collections.Campaign) CampaignResponse {
 	return CampaignResponse{
 		ID:             campaign.ID,
-		SendTo:         campaign.SendTo,
+		SendTo:         newSendToResponse(campaign.SendTo, campaign.Exclude, campaign.Intersect),
 		CampaignTypeID: campaign.CampaignTypeID,
 		Text:           campaign.Text,
 		HTML:           campaign.HTML,
+		Markdown:       campaign.Markdown,
 		Subject:        campaign.Subject,
 		TemplateID:     campaign.TemplateID,
 		ReplyTo:        campaign.ReplyTo,
+		BusinessHours:  campaign.BusinessHours,
 		Links: CampaignResponseLinks{
 			Self:         Link{fmt.Sprintf("/campaigns/%s", campaign.ID)},
 			Template:     Link{fmt.Sprintf("/templates/%s", campaign.TemplateID)},
@@ -31,11 +31,11 @@ var _ = Describe("CampaignResponse", func() {
 		response := campaigns.NewCampaignResponse(campaign)
 		Expect(response).To(Equal(campaigns.CampaignResponse{
 			ID: "some-campaign-id",
-			SendTo: map[string][]string{
-				"emails": {"me@example.com"},
-				"users":  {"some-user-guid"},
-				"spaces": {"some-space-guid"},
-				"orgs":   {"some-org-guid"},
+			SendTo: map[string]interface{}{
+				"emails": []string{"me@example.com"},
+				"users":  []string{"some-user-guid"},
+				"spaces": []string{"some-space-guid"},
+				"orgs":   []string{"some-org-guid"},
 			},
 			CampaignTypeID: "some-campaign-type-id",
 			Text:           "some-text",
@@ -52,6 +52,29 @@ var _ = Describe("CampaignResponse", func() {
 		}))
 	})
 
+	It("includes audience exclusions and intersections in send_to", func() {
+		campaign := collections.Campaign{
+			ID:        "some-campaign-id",
+			SendTo:    map[string][]string{"orgs": {"some-org-guid"}},
+			Exclude:   map[string][]string{"users": {"some-ops-guid"}},
+			Intersect: map[string][]string{"spaces": {"some-space-guid"}},
+		}
+
+		output, err := json.Marshal(campaigns.NewCampaignResponse(campaign))
+		Expect(err).NotTo(HaveOccurred())
+
+		var response struct {
+			SendTo json.RawMessage `json:"send_to"`
+		}
+		err = json.Unmarshal(output, &response)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.SendTo).To(MatchJSON(`{
+			"orgs": ["some-org-guid"],
+			"exclude": {"users": ["some-ops-guid"]},
+			"intersect": {"spaces": ["some-space-guid"]}
+		}`))
+	})
+
 	It("can marshal into JSON", func() {
 		campaign := collections.Campaign{
 			ID: "some-campaign-id",
@@ -82,9 +105,11 @@ var _ = Describe("CampaignResponse", func() {
 			"campaign_type_id": "some-campaign-type-id",
 			"text": "some-text",
 			"html": "some-html",
+			"markdown": "",
 			"subject": "some-subject",
 			"template_id": "some-template-id",
 			"reply_to": "some-reply-to",
+			"local_business_hours": false,
 			"_links": {
 				"self": {
 					"href": "/campaigns/some-campaign-id"
@@ -12,6 +12,19 @@ type CampaignStatusResponseLinks struct {
 	Campaign Link `json:"campaign"`
 }
 
+type DurationPercentiles struct {
+	P50 float64 `json:"p50"`
+	P95 float64 `json:"p95"`
+}
+
+// CampaignStatusTimings are in seconds. Timings that have not been recorded
+// yet are null.
+type CampaignStatusTimings struct {
+	QueueWait      *DurationPercentiles `json:"queue_wait"`
+	SendDuration   *DurationPercentiles `json:"send_duration"`
+	FanoutDuration *float64             `json:"fanout_duration"`
+}
+
 type CampaignStatusResponse struct {
 	CampaignID            string                      `json:"id"`
 	Status                string                      `json:"status"`
@@ -21,8 +34,10 @@ type CampaignStatusResponse struct {
 	FailedMessages        int                         `json:"failed_messages"`
 	QueuedMessages        int                         `json:"queued_messages"`
 	UndeliverableMessages int                         `json:"undeliverable_messages"`
+	ExcludedRecipients    int                         `json:"excluded_recipients"`
 	StartTime             time.Time                   `json:"start_time"`
 	CompletedTime         *time.Time                  `json:"completed_time"`
+	Timings               CampaignStatusTimings       `json:"timings"`
 	Links                 CampaignStatusResponseLinks `json:"_links"`
 }
 
@@ -36,11 +51,28 @@ func NewCampaignStatusResponse(status collections.CampaignStatus) CampaignStatus
 		FailedMessages:        status.FailedMessages,
 		QueuedMessages:        status.QueuedMessages,
 		UndeliverableMessages: status.UndeliverableMessages,
+		ExcludedRecipients:    status.ExcludedRecipients,
 		StartTime:             status.StartTime,
 		CompletedTime:         status.CompletedTime,
+		Timings: CampaignStatusTimings{
+			QueueWait:      newDurationPercentiles(status.QueueWait),
+			SendDuration:   newDurationPercentiles(status.SendDuration),
+			FanoutDuration: status.FanoutDuration,
+		},
 		Links: CampaignStatusResponseLinks{
 			Self:     Link{fmt.Sprintf("/campaigns/%s/status", status.CampaignID)},
 			Campaign: Link{fmt.Sprintf("/campaigns/%s", status.CampaignID)},
 		},
 	}
 }
+
+func newDurationPercentiles(percentiles *collections.DurationPercentiles) *DurationPercentiles {
+	if percentiles == nil {
+		return nil
+	}
+
+	return &DurationPercentiles{
+		P50: percentiles.P50,
+		P95: percentiles.P95,
+	}
+}
@@ -48,6 +48,7 @@ var _ = Describe("CampaignStatusResponse", func() {
 			UndeliverableMessages: 1,
 			StartTime:             startTime,
 			CompletedTime:         nil,
+			Timings:               campaigns.CampaignStatusTimings{},
 			Links: campaigns.CampaignStatusResponseLinks{
 				Self:     campaigns.Link{"/campaigns/some-campaign-id/status"},
 				Campaign: campaigns.Link{"/campaigns/some-campaign-id"},
@@ -56,6 +57,7 @@ var _ = Describe("CampaignStatusResponse", func() {
 	})
 
 	It("can marshal into JSON", func() {
+		fanoutDuration := 90.0
 		campaignStatus := collections.CampaignStatus{
 			CampaignID:            "some-campaign-id",
 			Status:                "completed",
@@ -65,8 +67,12 @@ var _ = Describe("CampaignStatusResponse", func() {
 			FailedMessages:        1,
 			QueuedMessages:        0,
 			UndeliverableMessages: 2,
+			ExcludedRecipients:    3,
 			StartTime:             startTime,
 			CompletedTime:         &completedTime,
+			QueueWait:             &collections.DurationPercentiles{P50: 1.5, P95: 4},
+			SendDuration:          &collections.DurationPercentiles{P50: 0.25, P95: 0.75},
+			FanoutDuration:        &fanoutDuration,
 		}
 
 		output, err := json.Marshal(campaigns.NewCampaignStatusResponse(campaignStatus))
@@ -80,8 +86,14 @@ var _ = Describe("CampaignStatusResponse", func() {
 			"failed_messages": 1,
 			"queued_messages": 0,
 			"undeliverable_messages": 2,
+			"excluded_recipients": 3,
 			"start_time": "2009-12-11T10:21:45Z",
 			"completed_time": "2009-12-11T10:21:59Z",
+			"timings": {
+				"queue_wait": {"p50": 1.5, "p95": 4},
+				"send_duration": {"p50": 0.25, "p95": 0.75},
+				"fanout_duration": 90
+			},
 			"_links": {
 				"self": {
 					"href": "/campaigns/some-campaign-id/status"
@@ -8,13 +8,17 @@ import (
 	"strings"
 	"time"
 
+	"github.com/cloudfoundry-incubator/notifications/markdown"
 	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/cloudfoundry-incubator/notifications/v2/web/middleware"
 	"github.com/dgrijalva/jwt-go"
+	"github.com/pivotal-golang/lager"
 	"github.com/ryanmoran/stack"
 )
 
 type collectionCreator interface {
 	Create(conn collections.ConnectionInterface, campaign collections.Campaign, clientID string, hasCriticalScope bool) (collections.Campaign, error)
+	Validate(conn collections.ConnectionInterface, campaign collections.Campaign, clientID string, hasCriticalScope bool) (collections.Campaign, error)
 }
 
 type clock interface {
@@ -23,24 +27,29 @@ type clock interface {
 
 type CreateHandler struct {
 	collection collectionCreator
+	previewer  campaignPreviewer
 	clock      clock
 }
 
-func NewCreateHandler(collection collectionCreator, clock clock) CreateHandler {
+func NewCreateHandler(collection collectionCreator, previewer campaignPreviewer, clock clock) CreateHandler {
 	return CreateHandler{
 		collection: collection,
+		previewer:  previewer,
 		clock:      clock,
 	}
 }
 
 type createRequest struct {
-	SendTo         map[string][]string `json:"send_to"`
-	CampaignTypeID string              `json:"campaign_type_id"`
-	Text           string              `json:"text"`
-	HTML           string              `json:"html"`
-	Subject        string              `json:"subject"`
-	TemplateID     string              `json:"template_id"`
-	ReplyTo        string              `json:"reply_to"`
+	SendTo         sendTo `json:"send_to"`
+	CampaignTypeID string `json:"campaign_type_id"`
+	Text           string `json:"text"`
+	HTML           string `json:"html"`
+	Markdown       string `json:"markdown"`
+	Subject        string `json:"subject"`
+	TemplateID     string `json:"template_id"`
+	ReplyTo        string `json:"reply_to"`
+	BusinessHours  bool   `json:"local_business_hours"`
+	DryRun         bool   `json:"dry_run"`
 }
 
 func (h CreateHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
@@ -56,6 +65,8 @@ func (h CreateHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, conte
 		return
 	}
 
+	request.HTML, request.Text = markdown.NewRenderer().Fill(request.Markdown, request.HTML, request.Text)
+
 	if !isValid(request, w, req) {
 		return
 	}
@@ -69,24 +80,41 @@ func (h CreateHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, conte
 	}
 
 	database := context.Get("database").(DatabaseInterface)
+	requestReceived, _ := context.Get(middleware.RequestReceivedTime).(time.Time)
 
-	campaign, err := h.collection.Create(database.Connection(), collections.Campaign{
-		SendTo:         request.SendTo,
+	campaign := collections.Campaign{
+		SendTo:         request.SendTo.Audiences,
+		Exclude:        request.SendTo.Exclude,
+		Intersect:      request.SendTo.Intersect,
 		CampaignTypeID: request.CampaignTypeID,
 		Text:           request.Text,
 		HTML:           request.HTML,
+		Markdown:       request.Markdown,
 		Subject:        request.Subject,
 		TemplateID:     request.TemplateID,
 		ReplyTo:        request.ReplyTo,
 		SenderID:       senderID,
 		StartTime:      h.clock.Now(),
-	}, context.Get("client_id").(string), hasCriticalScope)
+		BusinessHours:  request.BusinessHours,
+
+		RequestReceived: requestReceived,
+	}
+	clientID := context.Get("client_id").(string)
+
+	if request.DryRun {
+		h.dryRun(w, database.Connection(), campaign, clientID, hasCriticalScope, context.Get("logger").(lager.Logger))
+		return
+	}
+
+	campaign, err = h.collection.Create(database.Connection(), campaign, clientID, hasCriticalScope)
 	if err != nil {
 		switch err.(type) {
 		case collections.NotFoundError:
 			w.WriteHeader(http.StatusNotFound)
 		case collections.PermissionsError:
 			w.WriteHeader(http.StatusForbidden)
+		case collections.ValidationError:
+			w.WriteHeader(422)
 		default:
 			w.WriteHeader(http.StatusInternalServerError)
 		}
@@ -99,16 +127,26 @@ func (h CreateHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, conte
 	json.NewEncoder(w).Encode(NewCampaignResponse(campaign))
 }
 
-func isValid(request createRequest, w http.ResponseWriter, req *http.Request) bool {
-	for audienceKey, _ := range request.SendTo {
-		if !contains([]string{"users", "spaces", "orgs", "emails"}, audienceKey) {
-			return invalidResponse(w, fmt.Sprintf(`%q is not a valid audience`, audienceKey))
-		}
+func (h CreateHandler) dryRun(w http.ResponseWriter, conn collections.ConnectionInterface, campaign collections.Campaign, clientID string, hasCriticalScope bool, logger lager.Logger) {
+	campaign, err := h.collection.Validate(conn, campaign, clientID, hasCriticalScope)
+	if err != nil {
+		writePreviewError(w, err)
+		return
+	}
 
-		if audienceKey == "emails" {
-			if matches := regexp.MustCompile(`[^@]*@{1}[^@]*`).MatchString(request.SendTo[audienceKey][0]); !matches { // TODO: loop over audiences
-				return invalidResponse(w, fmt.Sprintf(`%q is not a valid email address`, request.SendTo[audienceKey][0]))
-			}
+	preview, err := h.previewer.Preview(conn, campaign, clientID, logger)
+	if err != nil {
+		writePreviewError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(NewCampaignPreviewResponse(preview))
+}
+
+func isValid(request createRequest, w http.ResponseWriter, req *http.Request) bool {
+	for _, audiences := range []map[string][]string{request.SendTo.Audiences, request.SendTo.Exclude, request.SendTo.Intersect} {
+		if !validAudiences(audiences, w) {
+			return false
 		}
 	}
 
@@ -127,6 +165,24 @@ func isValid(request createRequest, w http.ResponseWriter, req *http.Request) bo
 	return true
 }
 
+func validAudiences(audiences map[string][]string, w http.ResponseWriter) bool {
+	for audienceKey, audienceMembers := range audiences {
+		if !contains([]string{"users", "spaces", "orgs", "emails", "uaa_scopes", "groups", "audiences", "everyone", "org_managers", "org_auditors", "billing_managers", "space_managers", "space_developers", "space_auditors"}, audienceKey) {
+			return invalidResponse(w, fmt.Sprintf(`%q is not a valid audience`, audienceKey))
+		}
+
+		if audienceKey == "emails" {
+			for _, email := range audienceMembers {
+				if matches := regexp.MustCompile(`[^@]*@{1}[^@]*`).MatchString(email); !matches {
+					return invalidResponse(w, fmt.Sprintf(`%q is not a valid email address`, email))
+				}
+			}
+		}
+	}
+
+	return true
+}
+
 func contains(elements []string, element string) bool {
 	for _, elem := range elements {
 		if element == elem {
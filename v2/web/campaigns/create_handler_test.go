@@ -13,7 +13,9 @@ import (
 	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
 	"github.com/cloudfoundry-incubator/notifications/v2/collections"
 	"github.com/cloudfoundry-incubator/notifications/v2/web/campaigns"
+	"github.com/cloudfoundry-incubator/notifications/v2/web/middleware"
 	"github.com/dgrijalva/jwt-go"
+	"github.com/pivotal-golang/lager"
 	"github.com/ryanmoran/stack"
 
 	. "github.com/onsi/ginkgo"
@@ -24,6 +26,8 @@ var _ = Describe("CreateHandler", func() {
 	var (
 		handler             campaigns.CreateHandler
 		campaignsCollection *mocks.CampaignsCollection
+		previewsCollection  *mocks.CampaignPreviewsCollection
+		logger              lager.Logger
 		context             stack.Context
 		writer              *httptest.ResponseRecorder
 		request             *http.Request
@@ -60,6 +64,9 @@ var _ = Describe("CreateHandler", func() {
 		context.Set("database", database)
 		context.Set("client_id", "my-client")
 
+		logger = lager.NewLogger("notifications")
+		context.Set("logger", logger)
+
 		campaignsCollection = mocks.NewCampaignsCollection()
 		campaignsCollection.CreateCall.Returns.Campaign = collections.Campaign{
 			ID: "my-campaign-id",
@@ -76,7 +83,9 @@ var _ = Describe("CreateHandler", func() {
 
 		writer = httptest.NewRecorder()
 
-		handler = campaigns.NewCreateHandler(campaignsCollection, clock)
+		previewsCollection = mocks.NewCampaignPreviewsCollection()
+
+		handler = campaigns.NewCreateHandler(campaignsCollection, previewsCollection, clock)
 	})
 
 	It("sends a campaign to a list of users", func() {
@@ -110,9 +119,11 @@ var _ = Describe("CreateHandler", func() {
 			"campaign_type_id": "some-campaign-type-id",
 			"text":             "come see our new stuff",
 			"html":             "<h1>New stuff</h1>",
+			"markdown": "",
 			"subject":          "Cool New Stuff",
 			"template_id":      "random-template-id",
 			"reply_to":         "reply-to-address",
+			"local_business_hours": false,
 			"_links": {
 				"self": {"href":"/campaigns/my-campaign-id"},
 				"template": {"href":"/templates/random-template-id"},
@@ -136,6 +147,30 @@ var _ = Describe("CreateHandler", func() {
 		}))
 	})
 
+	It("records when the campaign request was received", func() {
+		requestReceived := time.Date(2016, 1, 2, 3, 4, 5, 0, time.UTC)
+		context.Set(middleware.RequestReceivedTime, requestReceived)
+
+		requestBody, err := json.Marshal(map[string]interface{}{
+			"send_to": map[string][]string{
+				"users": {"user-123"},
+			},
+			"campaign_type_id": "some-campaign-type-id",
+			"text":             "come see our new stuff",
+			"subject":          "Cool New Stuff",
+			"template_id":      "random-template-id",
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		request, err = http.NewRequest("POST", "/senders/some-sender-id/campaigns", bytes.NewBuffer(requestBody))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusAccepted))
+		Expect(campaignsCollection.CreateCall.Receives.Campaign.RequestReceived).To(Equal(requestReceived))
+	})
+
 	It("sends a campaign to a list of spaces", func() {
 		campaignsCollection.CreateCall.Returns.Campaign.SendTo = map[string][]string{"spaces": {"space-123", "space-456"}}
 		requestBody, err := json.Marshal(map[string]interface{}{
@@ -168,9 +203,11 @@ var _ = Describe("CreateHandler", func() {
 			"campaign_type_id": "some-campaign-type-id",
 			"text":             "come see our new stuff",
 			"html":             "<h1>New stuff</h1>",
+			"markdown": "",
 			"subject":          "Cool New Stuff",
 			"template_id":      "random-template-id",
 			"reply_to":         "reply-to-address",
+			"local_business_hours": false,
 			"_links": {
 				"self": {"href":"/campaigns/my-campaign-id"},
 				"template": {"href":"/templates/random-template-id"},
@@ -225,9 +262,11 @@ var _ = Describe("CreateHandler", func() {
 			"campaign_type_id": "some-campaign-type-id",
 			"text":             "come see our new stuff",
 			"html":             "<h1>New stuff</h1>",
+			"markdown": "",
 			"subject":          "Cool New Stuff",
 			"template_id":      "random-template-id",
 			"reply_to":         "reply-to-address",
+			"local_business_hours": false,
 			"_links": {
 				"self": {"href":"/campaigns/my-campaign-id"},
 				"template": {"href":"/templates/random-template-id"},
@@ -250,6 +289,129 @@ var _ = Describe("CreateHandler", func() {
 		}))
 	})
 
+	It("sends a campaign with audience exclusions and intersections", func() {
+		campaignsCollection.CreateCall.Returns.Campaign.SendTo = map[string][]string{"orgs": {"org-123"}}
+		campaignsCollection.CreateCall.Returns.Campaign.Exclude = map[string][]string{"users": {"ops-user-guid"}}
+		campaignsCollection.CreateCall.Returns.Campaign.Intersect = map[string][]string{"spaces": {"space-123"}}
+
+		request, err := http.NewRequest("POST", "/senders/some-sender-id/campaigns", bytes.NewBufferString(`{
+			"send_to": {
+				"orgs": ["org-123"],
+				"exclude": {"users": ["ops-user-guid"]},
+				"intersect": {"spaces": ["space-123"]}
+			},
+			"campaign_type_id": "some-campaign-type-id",
+			"text":             "come see our new stuff",
+			"subject":          "Cool New Stuff"
+		}`))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusAccepted))
+
+		campaign := campaignsCollection.CreateCall.Receives.Campaign
+		Expect(campaign.SendTo).To(Equal(map[string][]string{"orgs": {"org-123"}}))
+		Expect(campaign.Exclude).To(Equal(map[string][]string{"users": {"ops-user-guid"}}))
+		Expect(campaign.Intersect).To(Equal(map[string][]string{"spaces": {"space-123"}}))
+
+		var response struct {
+			SendTo json.RawMessage `json:"send_to"`
+		}
+		err = json.Unmarshal(writer.Body.Bytes(), &response)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.SendTo).To(MatchJSON(`{
+			"orgs": ["org-123"],
+			"exclude": {"users": ["ops-user-guid"]},
+			"intersect": {"spaces": ["space-123"]}
+		}`))
+	})
+
+	It("sends a campaign to uaa scopes, everyone and organization and space roles", func() {
+		sendTo := map[string][]string{
+			"uaa_scopes":       {"great.scope"},
+			"everyone":         {},
+			"org_managers":     {"org-123"},
+			"org_auditors":     {"org-123"},
+			"billing_managers": {"org-123"},
+			"space_managers":   {"space-123"},
+			"space_developers": {"space-123"},
+			"space_auditors":   {"space-123"},
+		}
+		requestBody, err := json.Marshal(map[string]interface{}{
+			"send_to":          sendTo,
+			"campaign_type_id": "some-campaign-type-id",
+			"text":             "come see our new stuff",
+			"subject":          "Cool New Stuff",
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		request, err = http.NewRequest("POST", "/senders/some-sender-id/campaigns", bytes.NewBuffer(requestBody))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusAccepted))
+		Expect(campaignsCollection.CreateCall.Receives.Campaign.SendTo).To(Equal(sendTo))
+	})
+
+	Context("when the campaign is sent to a uaa group", func() {
+		BeforeEach(func() {
+			requestBody, err := json.Marshal(map[string]interface{}{
+				"send_to": map[string][]string{
+					"groups": {"some-group"},
+				},
+				"campaign_type_id": "some-campaign-type-id",
+				"text":             "come see our new stuff",
+				"subject":          "Cool New Stuff",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			request, err = http.NewRequest("POST", "/senders/some-sender-id/campaigns", bytes.NewBuffer(requestBody))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("sends the campaign to the members of the group", func() {
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusAccepted))
+			Expect(campaignsCollection.CreateCall.Receives.Campaign.SendTo).To(Equal(map[string][]string{
+				"groups": {"some-group"},
+			}))
+		})
+
+		It("returns a 404 when the group cannot be found", func() {
+			campaignsCollection.CreateCall.Returns.Error = collections.NotFoundError{errors.New(`The group "some-group" cannot be found`)}
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusNotFound))
+			Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["The group \"some-group\" cannot be found"]}`))
+		})
+	})
+
+	It("sends a campaign to a saved audience", func() {
+		requestBody, err := json.Marshal(map[string]interface{}{
+			"send_to": map[string][]string{
+				"audiences": {"some-audience-id@2"},
+			},
+			"campaign_type_id": "some-campaign-type-id",
+			"text":             "come see our new stuff",
+			"subject":          "Cool New Stuff",
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		request, err = http.NewRequest("POST", "/senders/some-sender-id/campaigns", bytes.NewBuffer(requestBody))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusAccepted))
+		Expect(campaignsCollection.CreateCall.Receives.Campaign.SendTo).To(Equal(map[string][]string{
+			"audiences": {"some-audience-id@2"},
+		}))
+	})
+
 	It("sends a campaign to a list of emails", func() {
 		campaignsCollection.CreateCall.Returns.Campaign.SendTo = map[string][]string{"emails": {"test1@example.com", "test2@example.com"}}
 		requestBody, err := json.Marshal(map[string]interface{}{
@@ -282,9 +444,11 @@ var _ = Describe("CreateHandler", func() {
 			"campaign_type_id": "some-campaign-type-id",
 			"text":             "come see our new stuff",
 			"html":             "<h1>New stuff</h1>",
+			"markdown": "",
 			"subject":          "Cool New Stuff",
 			"template_id":      "random-template-id",
 			"reply_to":         "reply-to-address",
+			"local_business_hours": false,
 			"_links": {
 				"self": {"href":"/campaigns/my-campaign-id"},
 				"template": {"href":"/templates/random-template-id"},
@@ -307,6 +471,140 @@ var _ = Describe("CreateHandler", func() {
 		}))
 	})
 
+	It("asks for delivery in the recipients' local business hours", func() {
+		requestBody, err := json.Marshal(map[string]interface{}{
+			"send_to": map[string][]string{
+				"users": {"user-123"},
+			},
+			"campaign_type_id":     "some-campaign-type-id",
+			"text":                 "come see our new stuff",
+			"subject":              "Cool New Stuff",
+			"local_business_hours": true,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		request, err = http.NewRequest("POST", "/senders/some-sender-id/campaigns", bytes.NewBuffer(requestBody))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusAccepted))
+		Expect(campaignsCollection.CreateCall.Receives.Campaign.BusinessHours).To(BeTrue())
+	})
+
+	It("renders the text and html bodies from markdown", func() {
+		requestBody, err := json.Marshal(map[string]interface{}{
+			"send_to": map[string][]string{
+				"users": {"user-123"},
+			},
+			"campaign_type_id": "some-campaign-type-id",
+			"markdown":         "# New stuff\n\ncome see our *new* stuff",
+			"subject":          "Cool New Stuff",
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		request, err = http.NewRequest("POST", "/senders/some-sender-id/campaigns", bytes.NewBuffer(requestBody))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusAccepted))
+		Expect(campaignsCollection.CreateCall.Receives.Campaign.Markdown).To(Equal("# New stuff\n\ncome see our *new* stuff"))
+		Expect(campaignsCollection.CreateCall.Receives.Campaign.HTML).To(Equal("<h1>New stuff</h1>\n<p>come see our <em>new</em> stuff</p>"))
+		Expect(campaignsCollection.CreateCall.Receives.Campaign.Text).To(Equal("New stuff\n=========\n\ncome see our new stuff"))
+	})
+
+	Context("when the request is a dry run", func() {
+		BeforeEach(func() {
+			campaignsCollection.ValidateCall.Returns.Campaign = collections.Campaign{
+				SendTo:         map[string][]string{"orgs": {"some-org-guid"}},
+				CampaignTypeID: "some-campaign-type-id",
+				Text:           "come see our new stuff",
+				Subject:        "Cool New Stuff",
+				TemplateID:     "some-template-id",
+				SenderID:       "some-sender-id",
+			}
+
+			previewsCollection.PreviewCall.Returns.Preview = collections.CampaignPreview{
+				TotalRecipients:        2,
+				Audiences:              map[string]int{"orgs": 3},
+				UnsubscribedRecipients: 1,
+				Sample: []collections.PreviewRecipient{
+					{GUID: "user-123"},
+					{GUID: "user-456"},
+				},
+			}
+
+			requestBody, err := json.Marshal(map[string]interface{}{
+				"send_to": map[string][]string{
+					"orgs": {"some-org-guid"},
+				},
+				"campaign_type_id": "some-campaign-type-id",
+				"text":             "come see our new stuff",
+				"subject":          "Cool New Stuff",
+				"dry_run":          true,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			request, err = http.NewRequest("POST", "/senders/some-sender-id/campaigns", bytes.NewBuffer(requestBody))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("validates and previews the campaign without creating it", func() {
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusOK))
+			Expect(writer.Body.String()).To(MatchJSON(`{
+				"total_recipients": 2,
+				"audiences": {
+					"orgs": 3
+				},
+				"excluded_recipients": 0,
+				"unsubscribed_recipients": 1,
+				"unsubscribes_estimated": false,
+				"sample": [
+					{"guid": "user-123"},
+					{"guid": "user-456"}
+				]
+			}`))
+
+			Expect(campaignsCollection.CreateCall.WasCalled).To(BeFalse())
+
+			Expect(campaignsCollection.ValidateCall.Receives.Connection).To(Equal(conn))
+			Expect(campaignsCollection.ValidateCall.Receives.ClientID).To(Equal("my-client"))
+			Expect(campaignsCollection.ValidateCall.Receives.Campaign.SenderID).To(Equal("some-sender-id"))
+			Expect(campaignsCollection.ValidateCall.Receives.Campaign.SendTo).To(Equal(map[string][]string{"orgs": {"some-org-guid"}}))
+
+			Expect(previewsCollection.PreviewCall.Receives.Connection).To(Equal(conn))
+			Expect(previewsCollection.PreviewCall.Receives.Campaign).To(Equal(campaignsCollection.ValidateCall.Returns.Campaign))
+			Expect(previewsCollection.PreviewCall.Receives.ClientID).To(Equal("my-client"))
+			Expect(previewsCollection.PreviewCall.Receives.Logger).To(Equal(logger))
+		})
+
+		Context("when the campaign is not valid", func() {
+			It("returns the error without previewing", func() {
+				campaignsCollection.ValidateCall.Returns.Error = collections.NotFoundError{errors.New("sender not found")}
+
+				handler.ServeHTTP(writer, request, context)
+
+				Expect(writer.Code).To(Equal(http.StatusNotFound))
+				Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["sender not found"]}`))
+				Expect(previewsCollection.PreviewCall.WasCalled).To(BeFalse())
+			})
+		})
+
+		Context("when the preview fails", func() {
+			It("returns a 500 and the corresponding error", func() {
+				previewsCollection.PreviewCall.Returns.Error = collections.UnknownError{errors.New("uaa is down")}
+
+				handler.ServeHTTP(writer, request, context)
+
+				Expect(writer.Code).To(Equal(http.StatusInternalServerError))
+				Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["uaa is down"]}`))
+			})
+		})
+	})
+
 	Context("when validating user-input", func() {
 		Context("when the campaign_type_id is missing", func() {
 			BeforeEach(func() {
@@ -407,6 +705,25 @@ var _ = Describe("CreateHandler", func() {
 			})
 		})
 
+		Context("when an excluded audience is invalid", func() {
+			It("returns a 422 and states the audience is invalid", func() {
+				request, err := http.NewRequest("POST", "/senders/some-sender-id/campaigns", bytes.NewBufferString(`{
+					"send_to": {
+						"orgs": ["org-123"],
+						"exclude": {"userZ": ["ops-user-guid"]}
+					},
+					"campaign_type_id": "some-campaign-type-id",
+					"text":             "come see our new stuff",
+					"subject":          "Cool New Stuff"
+				}`))
+				Expect(err).NotTo(HaveOccurred())
+
+				handler.ServeHTTP(writer, request, context)
+				Expect(writer.Code).To(Equal(422))
+				Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["\"userZ\" is not a valid audience"]}`))
+			})
+		})
+
 		Context("when the email address is invalid", func() {
 			BeforeEach(func() {
 				requestBody, err := json.Marshal(map[string]interface{}{
@@ -533,6 +850,15 @@ var _ = Describe("CreateHandler", func() {
 			})
 		})
 
+		Context("when the collection returns a validation error", func() {
+			It("returns a 422 and the corresponding error", func() {
+				campaignsCollection.CreateCall.Returns.Error = collections.ValidationError{errors.New("The \"openid\" scope is a default scope and cannot be sent to")}
+				handler.ServeHTTP(writer, request, context)
+				Expect(writer.Code).To(Equal(422))
+				Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["The \"openid\" scope is a default scope and cannot be sent to"]}`))
+			})
+		})
+
 		Context("when the request JSON is not well-formed", func() {
 			It("returns a 400 and states that the request is invalid", func() {
 				request, err := http.NewRequest("POST", "/senders/some-sender-id/campaigns", bytes.NewBufferString("%%%"))
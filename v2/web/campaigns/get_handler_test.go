@@ -87,9 +87,11 @@ var _ = Describe("GetHandler", func() {
 			"campaign_type_id": "some-campaign-type-id",
 			"text":             "come see our new stuff",
 			"html":             "<h1>New stuff</h1>",
+			"markdown": "",
 			"subject":          "Cool New Stuff",
 			"template_id":      "random-template-id",
 			"reply_to":         "reply-to-address",
+			"local_business_hours": false,
 			"_links": {
 				"self": {
 					"href": "/campaigns/some-campaign-id"
@@ -0,0 +1,91 @@
+package campaigns
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/pivotal-golang/lager"
+	"github.com/ryanmoran/stack"
+)
+
+type campaignPreviewer interface {
+	Preview(conn collections.ConnectionInterface, campaign collections.Campaign, clientID string, logger lager.Logger) (collections.CampaignPreview, error)
+}
+
+type PreviewHandler struct {
+	previewer campaignPreviewer
+}
+
+func NewPreviewHandler(previewer campaignPreviewer) PreviewHandler {
+	return PreviewHandler{
+		previewer: previewer,
+	}
+}
+
+type previewRequest struct {
+	SendTo         sendTo `json:"send_to"`
+	CampaignTypeID string `json:"campaign_type_id"`
+}
+
+func (h PreviewHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	splitURL := strings.Split(req.URL.Path, "/")
+	senderID := splitURL[len(splitURL)-3]
+
+	var request previewRequest
+	err := json.NewDecoder(req.Body).Decode(&request)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, `{"errors": [%q]}`, "invalid json body")
+		return
+	}
+
+	for _, audiences := range []map[string][]string{request.SendTo.Audiences, request.SendTo.Exclude, request.SendTo.Intersect} {
+		if !validAudiences(audiences, w) {
+			return
+		}
+	}
+
+	if len(request.SendTo.Audiences) == 0 {
+		invalidResponse(w, "missing send_to")
+		return
+	}
+
+	if request.CampaignTypeID == "" {
+		invalidResponse(w, "missing campaign_type_id")
+		return
+	}
+
+	database := context.Get("database").(DatabaseInterface)
+
+	preview, err := h.previewer.Preview(database.Connection(), collections.Campaign{
+		SendTo:         request.SendTo.Audiences,
+		Exclude:        request.SendTo.Exclude,
+		Intersect:      request.SendTo.Intersect,
+		CampaignTypeID: request.CampaignTypeID,
+		SenderID:       senderID,
+	}, context.Get("client_id").(string), context.Get("logger").(lager.Logger))
+	if err != nil {
+		writePreviewError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(NewCampaignPreviewResponse(preview))
+}
+
+func writePreviewError(w http.ResponseWriter, err error) {
+	switch err.(type) {
+	case collections.NotFoundError:
+		w.WriteHeader(http.StatusNotFound)
+	case collections.PermissionsError:
+		w.WriteHeader(http.StatusForbidden)
+	case collections.ValidationError:
+		w.WriteHeader(422)
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+
+	fmt.Fprintf(w, `{"errors": [%q]}`, err.Error())
+}
@@ -0,0 +1,195 @@
+package campaigns_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/cloudfoundry-incubator/notifications/v2/web/campaigns"
+	"github.com/pivotal-golang/lager"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PreviewHandler", func() {
+	var (
+		handler            campaigns.PreviewHandler
+		previewsCollection *mocks.CampaignPreviewsCollection
+		context            stack.Context
+		writer             *httptest.ResponseRecorder
+		database           *mocks.Database
+		conn               *mocks.Connection
+		logger             lager.Logger
+	)
+
+	BeforeEach(func() {
+		conn = mocks.NewConnection()
+		database = mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = conn
+
+		logger = lager.NewLogger("notifications")
+
+		context = stack.NewContext()
+		context.Set("database", database)
+		context.Set("client_id", "my-client")
+		context.Set("logger", logger)
+
+		previewsCollection = mocks.NewCampaignPreviewsCollection()
+		previewsCollection.PreviewCall.Returns.Preview = collections.CampaignPreview{
+			TotalRecipients:        2,
+			Audiences:              map[string]int{"orgs": 3, "emails": 1},
+			ExcludedRecipients:     1,
+			UnsubscribedRecipients: 1,
+			Sample: []collections.PreviewRecipient{
+				{GUID: "user-123"},
+				{Email: "someone@example.com"},
+			},
+		}
+
+		writer = httptest.NewRecorder()
+
+		handler = campaigns.NewPreviewHandler(previewsCollection)
+	})
+
+	It("previews the recipients of a campaign", func() {
+		request, err := http.NewRequest("POST", "/senders/some-sender-id/campaigns/preview", bytes.NewBufferString(`{
+			"send_to": {
+				"orgs": ["some-org-guid"],
+				"emails": ["someone@example.com"],
+				"exclude": {
+					"users": ["user-789"]
+				}
+			},
+			"campaign_type_id": "some-campaign-type-id"
+		}`))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.String()).To(MatchJSON(`{
+			"total_recipients": 2,
+			"audiences": {
+				"orgs": 3,
+				"emails": 1
+			},
+			"excluded_recipients": 1,
+			"unsubscribed_recipients": 1,
+			"unsubscribes_estimated": false,
+			"sample": [
+				{"guid": "user-123"},
+				{"email": "someone@example.com"}
+			]
+		}`))
+
+		Expect(previewsCollection.PreviewCall.Receives.Connection).To(Equal(conn))
+		Expect(previewsCollection.PreviewCall.Receives.ClientID).To(Equal("my-client"))
+		Expect(previewsCollection.PreviewCall.Receives.Logger).To(Equal(logger))
+		Expect(previewsCollection.PreviewCall.Receives.Campaign).To(Equal(collections.Campaign{
+			SendTo: map[string][]string{
+				"orgs":   {"some-org-guid"},
+				"emails": {"someone@example.com"},
+			},
+			Exclude:        map[string][]string{"users": {"user-789"}},
+			CampaignTypeID: "some-campaign-type-id",
+			SenderID:       "some-sender-id",
+		}))
+	})
+
+	It("previews the recipients of a saved audience", func() {
+		request, err := http.NewRequest("POST", "/senders/some-sender-id/campaigns/preview", bytes.NewBufferString(`{
+			"send_to": {
+				"audiences": ["some-audience-id"]
+			},
+			"campaign_type_id": "some-campaign-type-id"
+		}`))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(previewsCollection.PreviewCall.Receives.Campaign.SendTo).To(Equal(map[string][]string{
+			"audiences": {"some-audience-id"},
+		}))
+	})
+
+	Context("when validating user-input", func() {
+		It("returns a 400 when the request JSON is not well-formed", func() {
+			request, err := http.NewRequest("POST", "/senders/some-sender-id/campaigns/preview", bytes.NewBufferString(`{{`))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusBadRequest))
+			Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["invalid json body"]}`))
+		})
+
+		It("returns a 422 when the send_to is missing", func() {
+			request, err := http.NewRequest("POST", "/senders/some-sender-id/campaigns/preview", bytes.NewBufferString(`{"campaign_type_id": "some-campaign-type-id"}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(422))
+			Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["missing send_to"]}`))
+			Expect(previewsCollection.PreviewCall.WasCalled).To(BeFalse())
+		})
+
+		It("returns a 422 when the campaign_type_id is missing", func() {
+			request, err := http.NewRequest("POST", "/senders/some-sender-id/campaigns/preview", bytes.NewBufferString(`{"send_to": {"users": ["user-123"]}}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(422))
+			Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["missing campaign_type_id"]}`))
+		})
+
+		It("returns a 422 when an audience is invalid", func() {
+			request, err := http.NewRequest("POST", "/senders/some-sender-id/campaigns/preview", bytes.NewBufferString(`{"send_to": {"bananas": ["banana-1"]}, "campaign_type_id": "some-campaign-type-id"}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(422))
+			Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["\"bananas\" is not a valid audience"]}`))
+		})
+	})
+
+	Context("when an error occurs", func() {
+		var request *http.Request
+
+		BeforeEach(func() {
+			var err error
+			request, err = http.NewRequest("POST", "/senders/some-sender-id/campaigns/preview", bytes.NewBufferString(`{"send_to": {"users": ["user-123"]}, "campaign_type_id": "some-campaign-type-id"}`))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		Context("when the collection returns a not found error", func() {
+			It("returns a 404 and the corresponding error", func() {
+				previewsCollection.PreviewCall.Returns.Error = collections.NotFoundError{errors.New("sender not found")}
+
+				handler.ServeHTTP(writer, request, context)
+
+				Expect(writer.Code).To(Equal(http.StatusNotFound))
+				Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["sender not found"]}`))
+			})
+		})
+
+		Context("when the collection returns an unknown error", func() {
+			It("returns a 500 and the corresponding error", func() {
+				previewsCollection.PreviewCall.Returns.Error = collections.UnknownError{errors.New("uaa is down")}
+
+				handler.ServeHTTP(writer, request, context)
+
+				Expect(writer.Code).To(Equal(http.StatusInternalServerError))
+				Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["uaa is down"]}`))
+			})
+		})
+	})
+})
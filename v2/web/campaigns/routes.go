@@ -15,11 +15,13 @@ type Routes struct {
 	DatabaseAllocator          stack.Middleware
 	CampaignsCollection        collections.CampaignsCollection
 	CampaignStatusesCollection collections.CampaignStatusesCollection
+	CampaignPreviewsCollection collections.CampaignPreviewsCollection
 	Clock                      clock
 }
 
 func (r Routes) Register(m muxer) {
-	m.Handle("POST", "/senders/{sender_id}/campaigns", NewCreateHandler(r.CampaignsCollection, r.Clock), r.RequestLogging, r.Authenticator, r.DatabaseAllocator)
+	m.Handle("POST", "/senders/{sender_id}/campaigns", NewCreateHandler(r.CampaignsCollection, r.CampaignPreviewsCollection, r.Clock), r.RequestLogging, r.Authenticator, r.DatabaseAllocator)
+	m.Handle("POST", "/senders/{sender_id}/campaigns/preview", NewPreviewHandler(r.CampaignPreviewsCollection), r.RequestLogging, r.Authenticator, r.DatabaseAllocator)
 	m.Handle("GET", "/campaigns/{campaign_id}", NewGetHandler(r.CampaignsCollection), r.RequestLogging, r.Authenticator, r.DatabaseAllocator)
 	m.Handle("GET", "/campaigns/{campaign_id}/status", NewStatusHandler(r.CampaignStatusesCollection), r.RequestLogging, r.Authenticator, r.DatabaseAllocator)
 }
@@ -56,6 +56,24 @@ var _ = Describe("Routes", func() {
 		Expect(databaseAllocator).To(Equal(dbAllocator))
 	})
 
+	It("routes POST /senders/{sender_id}/campaigns/preview", func() {
+		request, err := http.NewRequest("POST", "/senders/some-sender-id/campaigns/preview", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		s := muxer.Match(request).(stack.Stack)
+		Expect(s.Handler).To(BeAssignableToTypeOf(campaigns.PreviewHandler{}))
+		Expect(s.Middleware).To(HaveLen(3))
+
+		requestLogging := s.Middleware[0].(middleware.RequestLogging)
+		Expect(requestLogging).To(Equal(logging))
+
+		authenticator := s.Middleware[1].(middleware.Authenticator)
+		Expect(authenticator).To(Equal(auth))
+
+		databaseAllocator := s.Middleware[2].(middleware.DatabaseAllocator)
+		Expect(databaseAllocator).To(Equal(dbAllocator))
+	})
+
 	It("routes GET /campaigns/{campaign_id}", func() {
 		request, err := http.NewRequest("GET", "/campaigns/campaign-id", nil)
 		Expect(err).NotTo(HaveOccurred())
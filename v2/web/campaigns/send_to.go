@@ -0,0 +1,62 @@
+package campaigns
+
+import "encoding/json"
+
+type sendTo struct {
+	Audiences map[string][]string
+	Exclude   map[string][]string
+	Intersect map[string][]string
+}
+
+func (s *sendTo) UnmarshalJSON(data []byte) error {
+	var blocks map[string]json.RawMessage
+	err := json.Unmarshal(data, &blocks)
+	if err != nil {
+		return err
+	}
+
+	if blocks == nil {
+		return nil
+	}
+
+	s.Audiences = map[string][]string{}
+	for key, value := range blocks {
+		switch key {
+		case "exclude":
+			err = json.Unmarshal(value, &s.Exclude)
+		case "intersect":
+			err = json.Unmarshal(value, &s.Intersect)
+		default:
+			var members []string
+			err = json.Unmarshal(value, &members)
+			s.Audiences[key] = members
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func newSendToResponse(audiences, exclude, intersect map[string][]string) map[string]interface{} {
+	if audiences == nil && exclude == nil && intersect == nil {
+		return nil
+	}
+
+	response := map[string]interface{}{}
+	for key, members := range audiences {
+		response[key] = members
+	}
+
+	if len(exclude) > 0 {
+		response["exclude"] = exclude
+	}
+
+	if len(intersect) > 0 {
+		response["intersect"] = intersect
+	}
+
+	return response
+}
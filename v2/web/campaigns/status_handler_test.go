@@ -69,6 +69,7 @@ var _ = Describe("Campaign status handler", func() {
 		completedTime, err := time.Parse(time.RFC3339, "2015-09-01T12:34:58-07:00")
 		Expect(err).NotTo(HaveOccurred())
 
+		fanoutDuration := 90.0
 		campaignStatusesCollection.GetCall.Returns.CampaignStatus = collections.CampaignStatus{
 			CampaignID:            "some-campaign-id",
 			Status:                "completed",
@@ -78,8 +79,12 @@ var _ = Describe("Campaign status handler", func() {
 			RetryMessages:         0,
 			FailedMessages:        2,
 			UndeliverableMessages: 1,
+			ExcludedRecipients:    4,
 			StartTime:             startTime,
 			CompletedTime:         &completedTime,
+			QueueWait:             &collections.DurationPercentiles{P50: 1.5, P95: 4},
+			SendDuration:          &collections.DurationPercentiles{P50: 0.25, P95: 0.75},
+			FanoutDuration:        &fanoutDuration,
 		}
 
 		handler.ServeHTTP(writer, request, context)
@@ -94,8 +99,14 @@ var _ = Describe("Campaign status handler", func() {
 			"retry_messages": 0,
 			"failed_messages": 2,
 			"undeliverable_messages": 1,
+			"excluded_recipients": 4,
 			"start_time": "2015-09-01T12:34:56-07:00",
 			"completed_time": "2015-09-01T12:34:58-07:00",
+			"timings": {
+				"queue_wait": {"p50": 1.5, "p95": 4},
+				"send_duration": {"p50": 0.25, "p95": 0.75},
+				"fanout_duration": 90
+			},
 			"_links": {
 				"self": {
 					"href": "/campaigns/some-campaign-id/status"
@@ -140,8 +151,14 @@ var _ = Describe("Campaign status handler", func() {
 				"retry_messages": 1,
 				"failed_messages": 2,
 				"undeliverable_messages": 0,
+				"excluded_recipients": 0,
 				"start_time": "2015-09-01T12:34:56-07:00",
 				"completed_time": null,
+				"timings": {
+					"queue_wait": null,
+					"send_duration": null,
+					"fanout_duration": null
+				},
 				"_links": {
 					"self": {
 						"href": "/campaigns/some-campaign-id/status"
@@ -0,0 +1,35 @@
+package lookupcache
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ryanmoran/stack"
+)
+
+type flusher interface {
+	Flush() error
+}
+
+type FlushHandler struct {
+	cache flusher
+}
+
+func NewFlushHandler(cache flusher) FlushHandler {
+	return FlushHandler{
+		cache: cache,
+	}
+}
+
+// ServeHTTP empties the lookup cache. With the in-memory store this only
+// reaches the instance that handles the request.
+func (h FlushHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	err := h.cache.Flush()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"errors": [%q]}`, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,54 @@
+package lookupcache_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v2/web/lookupcache"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FlushHandler", func() {
+	var (
+		handler lookupcache.FlushHandler
+		cache   *mocks.LookupCache
+		writer  *httptest.ResponseRecorder
+		request *http.Request
+	)
+
+	BeforeEach(func() {
+		cache = mocks.NewLookupCache()
+		handler = lookupcache.NewFlushHandler(cache)
+		writer = httptest.NewRecorder()
+
+		var err error
+		request, err = http.NewRequest("DELETE", "/cache", nil)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("flushes the cache", func() {
+		handler.ServeHTTP(writer, request, stack.NewContext())
+
+		Expect(writer.Code).To(Equal(http.StatusNoContent))
+		Expect(writer.Body.String()).To(BeEmpty())
+		Expect(cache.FlushCall.WasCalled).To(BeTrue())
+	})
+
+	Context("when flushing the cache fails", func() {
+		It("returns a 500", func() {
+			cache.FlushCall.Returns.Error = errors.New("the cache is gone")
+
+			handler.ServeHTTP(writer, request, stack.NewContext())
+
+			Expect(writer.Code).To(Equal(http.StatusInternalServerError))
+			Expect(writer.Body.String()).To(MatchJSON(`{
+				"errors": ["the cache is gone"]
+			}`))
+		})
+	})
+})
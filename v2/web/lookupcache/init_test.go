@@ -0,0 +1,13 @@
+package lookupcache_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestWebV2LookupCacheSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "v2/web/lookupcache")
+}
@@ -0,0 +1,17 @@
+package lookupcache
+
+import "github.com/ryanmoran/stack"
+
+type muxer interface {
+	Handle(method, path string, handler stack.Handler, middleware ...stack.Middleware)
+}
+
+type Routes struct {
+	RequestLogging stack.Middleware
+	Authenticator  stack.Middleware
+	Cache          flusher
+}
+
+func (r Routes) Register(m muxer) {
+	m.Handle("DELETE", "/cache", NewFlushHandler(r.Cache), r.RequestLogging, r.Authenticator)
+}
@@ -0,0 +1,49 @@
+package lookupcache_test
+
+import (
+	"net/http"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v2/web/lookupcache"
+	"github.com/cloudfoundry-incubator/notifications/v2/web/middleware"
+	"github.com/cloudfoundry-incubator/notifications/web"
+	"github.com/pivotal-golang/lager"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Routes", func() {
+	var (
+		logging middleware.RequestLogging
+		auth    middleware.Authenticator
+		muxer   web.Muxer
+	)
+
+	BeforeEach(func() {
+		logging = middleware.NewRequestLogging(lager.NewLogger("log-prefix"), mocks.NewClock())
+		auth = middleware.NewAuthenticator("some-public-key", "notifications.admin")
+		muxer = web.NewMuxer()
+		lookupcache.Routes{
+			RequestLogging: logging,
+			Authenticator:  auth,
+			Cache:          mocks.NewLookupCache(),
+		}.Register(muxer)
+	})
+
+	It("routes DELETE /cache", func() {
+		request, err := http.NewRequest("DELETE", "/cache", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		s := muxer.Match(request).(stack.Stack)
+		Expect(s.Handler).To(BeAssignableToTypeOf(lookupcache.FlushHandler{}))
+		Expect(s.Middleware).To(HaveLen(2))
+
+		requestLogging := s.Middleware[0].(middleware.RequestLogging)
+		Expect(requestLogging).To(Equal(logging))
+
+		authenticator := s.Middleware[1].(middleware.Authenticator)
+		Expect(authenticator).To(Equal(auth))
+	})
+})
@@ -16,6 +16,10 @@ type UnsubscribesAuthenticator struct {
 	UAAPublicKey        string
 	ClientAuthenticator authenticator
 	UserAuthenticator   authenticator
+
+	// ActsOnTokenUser is set for routes that do not name a user and instead
+	// act on the user that owns the token.
+	ActsOnTokenUser bool
 }
 
 func NewUnsubscribesAuthenticator(publicKey string) UnsubscribesAuthenticator {
@@ -26,6 +30,11 @@ func NewUnsubscribesAuthenticator(publicKey string) UnsubscribesAuthenticator {
 	}
 }
 
+func (a UnsubscribesAuthenticator) ForTokenUser() UnsubscribesAuthenticator {
+	a.ActsOnTokenUser = true
+	return a
+}
+
 func (a UnsubscribesAuthenticator) ServeHTTP(writer http.ResponseWriter, request *http.Request, context stack.Context) bool {
 	rawToken := a.getToken(request)
 
@@ -48,7 +57,8 @@ func (a UnsubscribesAuthenticator) ServeHTTP(writer http.ResponseWriter, request
 	if ok {
 		route := strings.Split(request.URL.Path, "/")
 		routeUser := route[len(route)-1]
-		if routeUser != userID {
+
+		if routeUser != userID && !a.ActsOnTokenUser {
 			writer.WriteHeader(http.StatusForbidden)
 			writer.Write([]byte(`{"errors": [ "You are not authorized to perform the requested action" ]}`))
 			return false
@@ -112,6 +112,29 @@ var _ = Describe("Unsubscribes Authenticator", func() {
 			})
 		})
 
+		Context("when the route acts on the user that owns the token", func() {
+			BeforeEach(func() {
+				var err error
+				request.URL, err = url.Parse("/user_preferences")
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("delegates to the userAuthenticator", func() {
+				userAuthenticator.ServeHTTPCall.Returns.Continue = true
+
+				keepGoing := auth.ForTokenUser().ServeHTTP(writer, request, context)
+				Expect(userAuthenticator.ServeHTTPCall.Receives.Request).To(Equal(request))
+				Expect(keepGoing).To(BeTrue())
+			})
+
+			It("returns a 403 when the route was not configured for it", func() {
+				keepGoing := auth.ServeHTTP(writer, request, context)
+				Expect(userAuthenticator.ServeHTTPCall.Receives.Request).To(BeNil())
+				Expect(keepGoing).To(BeFalse())
+				Expect(writer.Code).To(Equal(http.StatusForbidden))
+			})
+		})
+
 		Context("when the user_id does not match the user_guid in the route", func() {
 			It("returns a 403 status code and error message", func() {
 				var err error
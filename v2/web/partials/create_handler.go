@@ -0,0 +1,83 @@
+package partials
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/ryanmoran/stack"
+)
+
+type collectionSetter interface {
+	Set(conn collections.ConnectionInterface, partial collections.Partial) (collections.Partial, error)
+}
+
+type CreateHandler struct {
+	partials collectionSetter
+}
+
+func NewCreateHandler(partials collectionSetter) CreateHandler {
+	return CreateHandler{
+		partials: partials,
+	}
+}
+
+func (h CreateHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	var createRequest struct {
+		Name string `json:"name"`
+		HTML string `json:"html"`
+		Text string `json:"text"`
+	}
+
+	err := json.NewDecoder(req.Body).Decode(&createRequest)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, `{"errors": [%q]}`, "invalid json body")
+		return
+	}
+
+	if createRequest.Name == "" {
+		w.WriteHeader(422)
+		fmt.Fprintf(w, `{"errors": [%q]}`, `Partial "name" field cannot be empty`)
+		return
+	}
+
+	if createRequest.HTML == "" && createRequest.Text == "" {
+		w.WriteHeader(422)
+		fmt.Fprintf(w, `{"errors": [%q]}`, "missing either partial text or html")
+		return
+	}
+
+	for _, body := range []string{createRequest.HTML, createRequest.Text} {
+		if err := common.ValidateTemplate(body); err != nil {
+			w.WriteHeader(422)
+			fmt.Fprintf(w, `{"errors": [%q]}`, "partial cannot be parsed: "+err.Error())
+			return
+		}
+	}
+
+	database := context.Get("database").(DatabaseInterface)
+
+	partial, err := h.partials.Set(database.Connection(), collections.Partial{
+		Name:     createRequest.Name,
+		HTML:     createRequest.HTML,
+		Text:     createRequest.Text,
+		ClientID: context.Get("client_id").(string),
+	})
+	if err != nil {
+		switch err.(type) {
+		case collections.DuplicateRecordError:
+			w.WriteHeader(http.StatusConflict)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+
+		fmt.Fprintf(w, `{"errors": [%q]}`, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(NewPartialResponse(partial))
+}
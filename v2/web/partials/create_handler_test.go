@@ -0,0 +1,148 @@
+package partials_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/cloudfoundry-incubator/notifications/v2/web/partials"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CreateHandler", func() {
+	var (
+		handler    partials.CreateHandler
+		collection *mocks.PartialsCollection
+		context    stack.Context
+		writer     *httptest.ResponseRecorder
+		request    *http.Request
+		conn       *mocks.Connection
+	)
+
+	BeforeEach(func() {
+		conn = mocks.NewConnection()
+		database := mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = conn
+
+		context = stack.NewContext()
+		context.Set("client_id", "some-client-id")
+		context.Set("database", database)
+
+		writer = httptest.NewRecorder()
+
+		requestBody, err := json.Marshal(map[string]interface{}{
+			"name": "footer",
+			"text": "-- {{.ClientID}}",
+			"html": "<footer>{{.ClientID}}</footer>",
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		request, err = http.NewRequest("POST", "/partials", bytes.NewBuffer(requestBody))
+		Expect(err).NotTo(HaveOccurred())
+
+		collection = mocks.NewPartialsCollection()
+		collection.SetCall.Returns.Partial = collections.Partial{
+			ID:       "some-partial-id",
+			Name:     "footer",
+			Text:     "-- {{.ClientID}}",
+			HTML:     "<footer>{{.ClientID}}</footer>",
+			ClientID: "some-client-id",
+		}
+
+		handler = partials.NewCreateHandler(collection)
+	})
+
+	It("creates a partial", func() {
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusCreated))
+		Expect(writer.Body.String()).To(MatchJSON(`{
+			"id": "some-partial-id",
+			"name": "footer",
+			"text": "-- {{.ClientID}}",
+			"html": "<footer>{{.ClientID}}</footer>",
+			"_links": {
+				"self": {
+					"href": "/partials/some-partial-id"
+				}
+			}
+		}`))
+
+		Expect(collection.SetCall.Receives.Connection).To(Equal(conn))
+		Expect(collection.SetCall.Receives.Partial).To(Equal(collections.Partial{
+			Name:     "footer",
+			Text:     "-- {{.ClientID}}",
+			HTML:     "<footer>{{.ClientID}}</footer>",
+			ClientID: "some-client-id",
+		}))
+	})
+
+	Context("failure cases", func() {
+		It("returns a 400 when the JSON is malformed", func() {
+			request, err := http.NewRequest("POST", "/partials", strings.NewReader("%%"))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusBadRequest))
+			Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["invalid json body"]}`))
+		})
+
+		It("returns a 422 when the name is missing", func() {
+			request, err := http.NewRequest("POST", "/partials", strings.NewReader(`{"text": "some text"}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(422))
+			Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["Partial \"name\" field cannot be empty"]}`))
+		})
+
+		It("returns a 422 when both the text and html are missing", func() {
+			request, err := http.NewRequest("POST", "/partials", strings.NewReader(`{"name": "footer"}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(422))
+			Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["missing either partial text or html"]}`))
+		})
+
+		It("returns a 422 when the text or html cannot be parsed", func() {
+			request, err := http.NewRequest("POST", "/partials", strings.NewReader(`{"name": "footer", "html": "{{.Broken"}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(422))
+			Expect(writer.Body.String()).To(ContainSubstring("partial cannot be parsed"))
+			Expect(collection.SetCall.Receives.Partial).To(Equal(collections.Partial{}))
+		})
+
+		It("returns a 409 when the name is already taken", func() {
+			collection.SetCall.Returns.Error = collections.DuplicateRecordError{errors.New("Partial with name \"footer\" already exists")}
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusConflict))
+			Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["Partial with name \"footer\" already exists"]}`))
+		})
+
+		It("returns a 500 when the collection fails", func() {
+			collection.SetCall.Returns.Error = errors.New("failed to save")
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusInternalServerError))
+			Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["failed to save"]}`))
+		})
+	})
+})
@@ -0,0 +1,46 @@
+package partials
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/ryanmoran/stack"
+)
+
+type collectionDeleter interface {
+	Delete(conn collections.ConnectionInterface, partialID, clientID string) error
+}
+
+type DeleteHandler struct {
+	partials collectionDeleter
+}
+
+func NewDeleteHandler(partials collectionDeleter) DeleteHandler {
+	return DeleteHandler{
+		partials: partials,
+	}
+}
+
+func (h DeleteHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	splitURL := strings.Split(req.URL.Path, "/")
+	partialID := splitURL[len(splitURL)-1]
+
+	database := context.Get("database").(DatabaseInterface)
+
+	err := h.partials.Delete(database.Connection(), partialID, context.Get("client_id").(string))
+	if err != nil {
+		switch err.(type) {
+		case collections.NotFoundError:
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+
+		fmt.Fprintf(w, `{"errors": [%q]}`, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
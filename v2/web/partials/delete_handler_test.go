@@ -0,0 +1,76 @@
+package partials_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/cloudfoundry-incubator/notifications/v2/web/partials"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DeleteHandler", func() {
+	var (
+		handler    partials.DeleteHandler
+		collection *mocks.PartialsCollection
+		context    stack.Context
+		writer     *httptest.ResponseRecorder
+		request    *http.Request
+		conn       *mocks.Connection
+	)
+
+	BeforeEach(func() {
+		conn = mocks.NewConnection()
+		database := mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = conn
+
+		context = stack.NewContext()
+		context.Set("client_id", "some-client-id")
+		context.Set("database", database)
+
+		writer = httptest.NewRecorder()
+
+		var err error
+		request, err = http.NewRequest("DELETE", "/partials/some-partial-id", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		collection = mocks.NewPartialsCollection()
+		handler = partials.NewDeleteHandler(collection)
+	})
+
+	It("deletes a partial", func() {
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusNoContent))
+		Expect(writer.Body.String()).To(BeEmpty())
+
+		Expect(collection.DeleteCall.Receives.Connection).To(Equal(conn))
+		Expect(collection.DeleteCall.Receives.PartialID).To(Equal("some-partial-id"))
+		Expect(collection.DeleteCall.Receives.ClientID).To(Equal("some-client-id"))
+	})
+
+	Context("failure cases", func() {
+		It("returns a 404 when the partial cannot be found", func() {
+			collection.DeleteCall.Returns.Error = collections.NotFoundError{errors.New("it was not found")}
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusNotFound))
+			Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["it was not found"]}`))
+		})
+
+		It("returns a 500 when the collection fails", func() {
+			collection.DeleteCall.Returns.Error = errors.New("failed to delete")
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusInternalServerError))
+			Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["failed to delete"]}`))
+		})
+	})
+})
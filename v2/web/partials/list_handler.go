@@ -0,0 +1,37 @@
+package partials
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/ryanmoran/stack"
+)
+
+type collectionLister interface {
+	List(conn collections.ConnectionInterface, clientID string) ([]collections.Partial, error)
+}
+
+type ListHandler struct {
+	partials collectionLister
+}
+
+func NewListHandler(partials collectionLister) ListHandler {
+	return ListHandler{
+		partials: partials,
+	}
+}
+
+func (h ListHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	database := context.Get("database").(DatabaseInterface)
+
+	partials, err := h.partials.List(database.Connection(), context.Get("client_id").(string))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"errors": [%q]}`, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(NewPartialsListResponse(partials))
+}
@@ -0,0 +1,121 @@
+package partials_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/cloudfoundry-incubator/notifications/v2/web/partials"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ListHandler", func() {
+	var (
+		handler    partials.ListHandler
+		collection *mocks.PartialsCollection
+		context    stack.Context
+		writer     *httptest.ResponseRecorder
+		request    *http.Request
+		conn       *mocks.Connection
+	)
+
+	BeforeEach(func() {
+		conn = mocks.NewConnection()
+		database := mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = conn
+
+		context = stack.NewContext()
+		context.Set("client_id", "some-client-id")
+		context.Set("database", database)
+
+		writer = httptest.NewRecorder()
+
+		var err error
+		request, err = http.NewRequest("GET", "/partials", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		collection = mocks.NewPartialsCollection()
+		handler = partials.NewListHandler(collection)
+	})
+
+	It("lists the partials belonging to the client", func() {
+		collection.ListCall.Returns.Partials = []collections.Partial{
+			{
+				ID:   "footer-id",
+				Name: "footer",
+				Text: "footer text",
+			},
+			{
+				ID:   "header-id",
+				Name: "header",
+				HTML: "header html",
+			},
+		}
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.String()).To(MatchJSON(`{
+			"partials": [
+				{
+					"id": "footer-id",
+					"name": "footer",
+					"text": "footer text",
+					"html": "",
+					"_links": {
+						"self": {
+							"href": "/partials/footer-id"
+						}
+					}
+				},
+				{
+					"id": "header-id",
+					"name": "header",
+					"text": "",
+					"html": "header html",
+					"_links": {
+						"self": {
+							"href": "/partials/header-id"
+						}
+					}
+				}
+			],
+			"_links": {
+				"self": {
+					"href": "/partials"
+				}
+			}
+		}`))
+
+		Expect(collection.ListCall.Receives.Connection).To(Equal(conn))
+		Expect(collection.ListCall.Receives.ClientID).To(Equal("some-client-id"))
+	})
+
+	It("returns an empty list when there are no partials", func() {
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.String()).To(MatchJSON(`{
+			"partials": [],
+			"_links": {
+				"self": {
+					"href": "/partials"
+				}
+			}
+		}`))
+	})
+
+	It("returns a 500 when the collection fails", func() {
+		collection.ListCall.Returns.Error = errors.New("failed to list")
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusInternalServerError))
+		Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["failed to list"]}`))
+	})
+})
@@ -0,0 +1,33 @@
+package partials
+
+import (
+	"fmt"
+
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+)
+
+type Link struct {
+	Href string `json:"href"`
+}
+
+type PartialResponseLinks struct {
+	Self Link `json:"self"`
+}
+
+type PartialResponse struct {
+	ID    string               `json:"id"`
+	Name  string               `json:"name"`
+	Text  string               `json:"text"`
+	HTML  string               `json:"html"`
+	Links PartialResponseLinks `json:"_links"`
+}
+
+func NewPartialResponse(partial collections.Partial) PartialResponse {
+	return PartialResponse{
+		ID:    partial.ID,
+		Name:  partial.Name,
+		Text:  partial.Text,
+		HTML:  partial.HTML,
+		Links: PartialResponseLinks{Link{fmt.Sprintf("/partials/%s", partial.ID)}},
+	}
+}
@@ -0,0 +1,25 @@
+package partials
+
+import "github.com/cloudfoundry-incubator/notifications/v2/collections"
+
+type PartialsListResponseLinks struct {
+	Self Link `json:"self"`
+}
+
+type PartialsListResponse struct {
+	Partials []PartialResponse         `json:"partials"`
+	Links    PartialsListResponseLinks `json:"_links"`
+}
+
+func NewPartialsListResponse(partialList []collections.Partial) PartialsListResponse {
+	partials := []PartialResponse{}
+
+	for _, p := range partialList {
+		partials = append(partials, NewPartialResponse(p))
+	}
+
+	return PartialsListResponse{
+		Partials: partials,
+		Links:    PartialsListResponseLinks{Link{"/partials"}},
+	}
+}
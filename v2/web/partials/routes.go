@@ -0,0 +1,25 @@
+package partials
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/ryanmoran/stack"
+)
+
+type muxer interface {
+	Handle(method, path string, handler stack.Handler, middleware ...stack.Middleware)
+}
+
+type Routes struct {
+	RequestLogging     stack.Middleware
+	Authenticator      stack.Middleware
+	DatabaseAllocator  stack.Middleware
+	PartialsCollection collections.PartialsCollection
+}
+
+func (r Routes) Register(m muxer) {
+	m.Handle("GET", "/partials", NewListHandler(r.PartialsCollection), r.RequestLogging, r.Authenticator, r.DatabaseAllocator)
+	m.Handle("POST", "/partials", NewCreateHandler(r.PartialsCollection), r.RequestLogging, r.Authenticator, r.DatabaseAllocator)
+	m.Handle("GET", "/partials/{partial_id}", NewGetHandler(r.PartialsCollection), r.RequestLogging, r.Authenticator, r.DatabaseAllocator)
+	m.Handle("PUT", "/partials/{partial_id}", NewUpdateHandler(r.PartialsCollection), r.RequestLogging, r.Authenticator, r.DatabaseAllocator)
+	m.Handle("DELETE", "/partials/{partial_id}", NewDeleteHandler(r.PartialsCollection), r.RequestLogging, r.Authenticator, r.DatabaseAllocator)
+}
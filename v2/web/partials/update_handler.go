@@ -0,0 +1,107 @@
+package partials
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/ryanmoran/stack"
+)
+
+type collectionSetGetter interface {
+	Set(conn collections.ConnectionInterface, partial collections.Partial) (collections.Partial, error)
+	Get(conn collections.ConnectionInterface, partialID, clientID string) (collections.Partial, error)
+}
+
+type UpdateHandler struct {
+	partials collectionSetGetter
+}
+
+func NewUpdateHandler(partials collectionSetGetter) UpdateHandler {
+	return UpdateHandler{
+		partials: partials,
+	}
+}
+
+func (h UpdateHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	splitURL := strings.Split(req.URL.Path, "/")
+	partialID := splitURL[len(splitURL)-1]
+
+	var updateRequest struct {
+		Name *string `json:"name"`
+		HTML *string `json:"html"`
+		Text *string `json:"text"`
+	}
+
+	err := json.NewDecoder(req.Body).Decode(&updateRequest)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, `{"errors": [%q]}`, "invalid json body")
+		return
+	}
+
+	database := context.Get("database").(DatabaseInterface)
+
+	partial, err := h.partials.Get(database.Connection(), partialID, context.Get("client_id").(string))
+	if err != nil {
+		switch err.(type) {
+		case collections.NotFoundError:
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+
+		fmt.Fprintf(w, `{"errors": [%q]}`, err)
+		return
+	}
+
+	if updateRequest.Name != nil {
+		partial.Name = *updateRequest.Name
+	}
+
+	if updateRequest.HTML != nil {
+		partial.HTML = *updateRequest.HTML
+	}
+
+	if updateRequest.Text != nil {
+		partial.Text = *updateRequest.Text
+	}
+
+	if partial.Name == "" {
+		w.WriteHeader(422)
+		fmt.Fprintf(w, `{"errors": [%q]}`, `Partial "name" field cannot be empty`)
+		return
+	}
+
+	if partial.HTML == "" && partial.Text == "" {
+		w.WriteHeader(422)
+		fmt.Fprintf(w, `{"errors": [%q]}`, "missing either partial text or html")
+		return
+	}
+
+	for _, body := range []string{partial.HTML, partial.Text} {
+		if err := common.ValidateTemplate(body); err != nil {
+			w.WriteHeader(422)
+			fmt.Fprintf(w, `{"errors": [%q]}`, "partial cannot be parsed: "+err.Error())
+			return
+		}
+	}
+
+	partial, err = h.partials.Set(database.Connection(), partial)
+	if err != nil {
+		switch err.(type) {
+		case collections.DuplicateRecordError:
+			w.WriteHeader(http.StatusConflict)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+
+		fmt.Fprintf(w, `{"errors": [%q]}`, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(NewPartialResponse(partial))
+}
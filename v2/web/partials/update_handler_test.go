@@ -0,0 +1,156 @@
+package partials_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/cloudfoundry-incubator/notifications/v2/web/partials"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("UpdateHandler", func() {
+	var (
+		handler    partials.UpdateHandler
+		collection *mocks.PartialsCollection
+		context    stack.Context
+		writer     *httptest.ResponseRecorder
+		conn       *mocks.Connection
+	)
+
+	BeforeEach(func() {
+		conn = mocks.NewConnection()
+		database := mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = conn
+
+		context = stack.NewContext()
+		context.Set("client_id", "some-client-id")
+		context.Set("database", database)
+
+		writer = httptest.NewRecorder()
+
+		collection = mocks.NewPartialsCollection()
+		collection.GetCall.Returns.Partial = collections.Partial{
+			ID:       "some-partial-id",
+			Name:     "footer",
+			Text:     "old text",
+			HTML:     "old html",
+			ClientID: "some-client-id",
+		}
+		collection.SetCall.Returns.Partial = collections.Partial{
+			ID:       "some-partial-id",
+			Name:     "footer",
+			Text:     "new text",
+			HTML:     "old html",
+			ClientID: "some-client-id",
+		}
+
+		handler = partials.NewUpdateHandler(collection)
+	})
+
+	It("updates the given fields of a partial", func() {
+		request, err := http.NewRequest("PUT", "/partials/some-partial-id", strings.NewReader(`{"text": "new text"}`))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.String()).To(MatchJSON(`{
+			"id": "some-partial-id",
+			"name": "footer",
+			"text": "new text",
+			"html": "old html",
+			"_links": {
+				"self": {
+					"href": "/partials/some-partial-id"
+				}
+			}
+		}`))
+
+		Expect(collection.GetCall.Receives.Connection).To(Equal(conn))
+		Expect(collection.GetCall.Receives.PartialID).To(Equal("some-partial-id"))
+		Expect(collection.GetCall.Receives.ClientID).To(Equal("some-client-id"))
+
+		Expect(collection.SetCall.Receives.Connection).To(Equal(conn))
+		Expect(collection.SetCall.Receives.Partial).To(Equal(collections.Partial{
+			ID:       "some-partial-id",
+			Name:     "footer",
+			Text:     "new text",
+			HTML:     "old html",
+			ClientID: "some-client-id",
+		}))
+	})
+
+	Context("failure cases", func() {
+		It("returns a 400 when the JSON is malformed", func() {
+			request, err := http.NewRequest("PUT", "/partials/some-partial-id", strings.NewReader("%%"))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusBadRequest))
+			Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["invalid json body"]}`))
+		})
+
+		It("returns a 404 when the partial cannot be found", func() {
+			collection.GetCall.Returns.Error = collections.NotFoundError{errors.New("it was not found")}
+
+			request, err := http.NewRequest("PUT", "/partials/some-partial-id", strings.NewReader(`{}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusNotFound))
+			Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["it was not found"]}`))
+		})
+
+		It("returns a 422 when the name is cleared", func() {
+			request, err := http.NewRequest("PUT", "/partials/some-partial-id", strings.NewReader(`{"name": ""}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(422))
+			Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["Partial \"name\" field cannot be empty"]}`))
+		})
+
+		It("returns a 422 when both the text and html are cleared", func() {
+			request, err := http.NewRequest("PUT", "/partials/some-partial-id", strings.NewReader(`{"text": "", "html": ""}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(422))
+			Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["missing either partial text or html"]}`))
+		})
+
+		It("returns a 422 when the text or html cannot be parsed", func() {
+			request, err := http.NewRequest("PUT", "/partials/some-partial-id", strings.NewReader(`{"text": "{{.Broken"}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(422))
+			Expect(writer.Body.String()).To(ContainSubstring("partial cannot be parsed"))
+			Expect(collection.SetCall.Receives.Partial).To(Equal(collections.Partial{}))
+		})
+
+		It("returns a 409 when the name is already taken", func() {
+			collection.SetCall.Returns.Error = collections.DuplicateRecordError{errors.New("Partial with name \"header\" already exists")}
+
+			request, err := http.NewRequest("PUT", "/partials/some-partial-id", strings.NewReader(`{"name": "header"}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusConflict))
+			Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["Partial with name \"header\" already exists"]}`))
+		})
+	})
+})
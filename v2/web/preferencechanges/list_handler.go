@@ -0,0 +1,80 @@
+package preferencechanges
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/ryanmoran/stack"
+)
+
+const (
+	DefaultListLimit = 1000
+	MaxListLimit     = 10000
+)
+
+type collectionLister interface {
+	List(conn collections.ConnectionInterface, userGUID string, offset, limit int) ([]collections.PreferenceChange, error)
+}
+
+type ListHandler struct {
+	changes collectionLister
+}
+
+func NewListHandler(changes collectionLister) ListHandler {
+	return ListHandler{
+		changes: changes,
+	}
+}
+
+func (h ListHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	query := req.URL.Query()
+
+	limit := DefaultListLimit
+	if value := query.Get("limit"); value != "" {
+		var err error
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > MaxListLimit {
+			w.WriteHeader(422)
+			fmt.Fprintf(w, `{"errors": [%q]}`, fmt.Sprintf("limit must be an integer between 1 and %d", MaxListLimit))
+			return
+		}
+	}
+
+	var offset int
+	if value := query.Get("offset"); value != "" {
+		var err error
+		offset, err = strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			w.WriteHeader(422)
+			fmt.Fprintf(w, `{"errors": [%q]}`, "offset must be a non-negative integer")
+			return
+		}
+	}
+
+	database := context.Get("database").(DatabaseInterface)
+	userGUID := query.Get("user_guid")
+
+	changes, err := h.changes.List(database.Connection(), userGUID, offset, limit)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"errors": [%q]}`, err)
+		return
+	}
+
+	if len(changes) == limit {
+		next := url.Values{}
+		if userGUID != "" {
+			next.Set("user_guid", userGUID)
+		}
+		next.Set("offset", strconv.Itoa(offset+limit))
+		next.Set("limit", strconv.Itoa(limit))
+
+		w.Header().Set("Link", fmt.Sprintf(`</preference_changes?%s>; rel="next"`, next.Encode()))
+	}
+
+	json.NewEncoder(w).Encode(NewPreferenceChangesListResponse(changes))
+}
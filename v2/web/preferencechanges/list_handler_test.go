@@ -0,0 +1,208 @@
+package preferencechanges_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+	"github.com/cloudfoundry-incubator/notifications/v2/web/preferencechanges"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ListHandler", func() {
+	var (
+		handler    preferencechanges.ListHandler
+		changes    *mocks.PreferenceChangesCollection
+		writer     *httptest.ResponseRecorder
+		context    stack.Context
+		connection *mocks.Connection
+	)
+
+	BeforeEach(func() {
+		createdAt := time.Date(2015, time.October, 1, 12, 0, 0, 0, time.UTC)
+
+		changes = mocks.NewPreferenceChangesCollection()
+		changes.ListCall.Returns.Changes = []collections.PreferenceChange{
+			{
+				UserGUID:   "some-user-guid",
+				ActorType:  models.ActorUser,
+				ActorID:    "some-user-guid",
+				Source:     models.SourceAPI,
+				Preference: models.PreferenceKind,
+				ClientID:   "some-client-id",
+				TargetID:   "some-kind-id",
+				OldValue:   models.Subscribed,
+				NewValue:   models.Unsubscribed,
+				CreatedAt:  createdAt,
+			},
+			{
+				UserGUID:   "some-user-guid",
+				ActorType:  models.ActorClient,
+				ActorID:    "some-client-id",
+				Source:     models.SourceImport,
+				Preference: models.PreferenceCampaignType,
+				TargetID:   "some-campaign-type-id",
+				OldValue:   models.Subscribed,
+				NewValue:   models.Unsubscribed,
+				CreatedAt:  createdAt,
+			},
+			{
+				UserGUID:   "some-user-guid",
+				ActorType:  models.ActorUser,
+				ActorID:    "some-user-guid",
+				Source:     models.SourceAPI,
+				Preference: models.PreferenceCampaignTypeDigest,
+				ClientID:   "some-client-id",
+				TargetID:   "some-campaign-type-id",
+				OldValue:   models.DigestImmediate,
+				NewValue:   models.DigestDaily,
+				CreatedAt:  createdAt,
+			},
+			{
+				Email:      "someone@example.com",
+				ActorType:  models.ActorRecipient,
+				ActorID:    "someone@example.com",
+				Source:     models.SourceLink,
+				Preference: models.PreferenceGlobal,
+				OldValue:   models.Subscribed,
+				NewValue:   models.Unsubscribed,
+				CreatedAt:  createdAt,
+			},
+		}
+
+		connection = mocks.NewConnection()
+		database := mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = connection
+
+		context = stack.NewContext()
+		context.Set("database", database)
+
+		writer = httptest.NewRecorder()
+
+		handler = preferencechanges.NewListHandler(changes)
+	})
+
+	It("lists the preference changes for the user", func() {
+		request, err := http.NewRequest("GET", "/preference_changes?user_guid=some-user-guid", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.String()).To(MatchJSON(`{
+			"preference_changes": [
+				{
+					"user_guid": "some-user-guid",
+					"actor": {"type": "user", "id": "some-user-guid"},
+					"source": "api",
+					"preference": "kind",
+					"client_id": "some-client-id",
+					"kind_id": "some-kind-id",
+					"old_value": "subscribed",
+					"new_value": "unsubscribed",
+					"created_at": "2015-10-01T12:00:00Z"
+				},
+				{
+					"user_guid": "some-user-guid",
+					"actor": {"type": "client", "id": "some-client-id"},
+					"source": "import",
+					"preference": "campaign_type",
+					"campaign_type_id": "some-campaign-type-id",
+					"old_value": "subscribed",
+					"new_value": "unsubscribed",
+					"created_at": "2015-10-01T12:00:00Z"
+				},
+				{
+					"user_guid": "some-user-guid",
+					"actor": {"type": "user", "id": "some-user-guid"},
+					"source": "api",
+					"preference": "campaign_type_digest",
+					"client_id": "some-client-id",
+					"campaign_type_id": "some-campaign-type-id",
+					"old_value": "immediate",
+					"new_value": "daily",
+					"created_at": "2015-10-01T12:00:00Z"
+				},
+				{
+					"email": "someone@example.com",
+					"actor": {"type": "recipient", "id": "someone@example.com"},
+					"source": "link",
+					"preference": "global",
+					"old_value": "subscribed",
+					"new_value": "unsubscribed",
+					"created_at": "2015-10-01T12:00:00Z"
+				}
+			]
+		}`))
+
+		Expect(changes.ListCall.Receives.Connection).To(Equal(connection))
+		Expect(changes.ListCall.Receives.UserGUID).To(Equal("some-user-guid"))
+		Expect(changes.ListCall.Receives.Offset).To(Equal(0))
+		Expect(changes.ListCall.Receives.Limit).To(Equal(preferencechanges.DefaultListLimit))
+		Expect(writer.Header().Get("Link")).To(BeEmpty())
+	})
+
+	It("lists the requested page and links to the next one when the page is full", func() {
+		request, err := http.NewRequest("GET", "/preference_changes?user_guid=some-user-guid&offset=8&limit=4", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(changes.ListCall.Receives.Offset).To(Equal(8))
+		Expect(changes.ListCall.Receives.Limit).To(Equal(4))
+		Expect(writer.Header().Get("Link")).To(Equal(`</preference_changes?limit=4&offset=12&user_guid=some-user-guid>; rel="next"`))
+	})
+
+	It("returns a 422 when the limit is out of range", func() {
+		request, err := http.NewRequest("GET", "/preference_changes?limit=10001", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(422))
+		Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["limit must be an integer between 1 and 10000"]}`))
+	})
+
+	It("returns a 422 when the offset is not a non-negative integer", func() {
+		request, err := http.NewRequest("GET", "/preference_changes?offset=soon", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(422))
+		Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["offset must be a non-negative integer"]}`))
+	})
+
+	It("lists a page of every change when no user is given", func() {
+		changes.ListCall.Returns.Changes = []collections.PreferenceChange{}
+
+		request, err := http.NewRequest("GET", "/preference_changes", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.String()).To(MatchJSON(`{"preference_changes": []}`))
+		Expect(changes.ListCall.Receives.UserGUID).To(BeEmpty())
+	})
+
+	It("returns a 500 when the changes cannot be listed", func() {
+		changes.ListCall.Returns.Error = collections.PersistenceError{errors.New("db is down")}
+
+		request, err := http.NewRequest("GET", "/preference_changes", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusInternalServerError))
+		Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["db is down"]}`))
+	})
+})
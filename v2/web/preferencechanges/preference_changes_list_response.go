@@ -0,0 +1,69 @@
+package preferencechanges
+
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+)
+
+type ActorResponse struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+type PreferenceChangeResponse struct {
+	UserGUID       string        `json:"user_guid,omitempty"`
+	Email          string        `json:"email,omitempty"`
+	Actor          ActorResponse `json:"actor"`
+	Source         string        `json:"source"`
+	Preference     string        `json:"preference"`
+	ClientID       string        `json:"client_id,omitempty"`
+	KindID         string        `json:"kind_id,omitempty"`
+	CampaignTypeID string        `json:"campaign_type_id,omitempty"`
+	OldValue       string        `json:"old_value"`
+	NewValue       string        `json:"new_value"`
+	CreatedAt      time.Time     `json:"created_at"`
+}
+
+type PreferenceChangesListResponse struct {
+	PreferenceChanges []PreferenceChangeResponse `json:"preference_changes"`
+}
+
+func NewPreferenceChangeResponse(change collections.PreferenceChange) PreferenceChangeResponse {
+	response := PreferenceChangeResponse{
+		UserGUID: change.UserGUID,
+		Email:    change.Email,
+		Actor: ActorResponse{
+			Type: change.ActorType,
+			ID:   change.ActorID,
+		},
+		Source:     change.Source,
+		Preference: change.Preference,
+		ClientID:   change.ClientID,
+		OldValue:   change.OldValue,
+		NewValue:   change.NewValue,
+		CreatedAt:  change.CreatedAt,
+	}
+
+	switch change.Preference {
+	case models.PreferenceKind, models.PreferenceKindDigest:
+		response.KindID = change.TargetID
+	case models.PreferenceCampaignType, models.PreferenceCampaignTypeDigest:
+		response.CampaignTypeID = change.TargetID
+	}
+
+	return response
+}
+
+func NewPreferenceChangesListResponse(changeList []collections.PreferenceChange) PreferenceChangesListResponse {
+	changes := []PreferenceChangeResponse{}
+
+	for _, change := range changeList {
+		changes = append(changes, NewPreferenceChangeResponse(change))
+	}
+
+	return PreferenceChangesListResponse{
+		PreferenceChanges: changes,
+	}
+}
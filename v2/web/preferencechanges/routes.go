@@ -0,0 +1,21 @@
+package preferencechanges
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/ryanmoran/stack"
+)
+
+type muxer interface {
+	Handle(method, path string, handler stack.Handler, middleware ...stack.Middleware)
+}
+
+type Routes struct {
+	RequestLogging              stack.Middleware
+	Authenticator               stack.Middleware
+	DatabaseAllocator           stack.Middleware
+	PreferenceChangesCollection collections.PreferenceChangesCollection
+}
+
+func (r Routes) Register(m muxer) {
+	m.Handle("GET", "/preference_changes", NewListHandler(r.PreferenceChangesCollection), r.RequestLogging, r.Authenticator, r.DatabaseAllocator)
+}
@@ -0,0 +1,57 @@
+package preferencechanges_test
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/cloudfoundry-incubator/notifications/v2/web/middleware"
+	"github.com/cloudfoundry-incubator/notifications/v2/web/preferencechanges"
+	"github.com/cloudfoundry-incubator/notifications/web"
+	"github.com/pivotal-golang/lager"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Routes", func() {
+	var (
+		logging     middleware.RequestLogging
+		dbAllocator middleware.DatabaseAllocator
+		auth        middleware.Authenticator
+		muxer       web.Muxer
+	)
+
+	BeforeEach(func() {
+		logging = middleware.NewRequestLogging(lager.NewLogger("log-prefix"), mocks.NewClock())
+		auth = middleware.NewAuthenticator("some-public-key", "notifications.admin")
+		dbAllocator = middleware.NewDatabaseAllocator(&sql.DB{}, false)
+		muxer = web.NewMuxer()
+		preferencechanges.Routes{
+			RequestLogging:              logging,
+			Authenticator:               auth,
+			DatabaseAllocator:           dbAllocator,
+			PreferenceChangesCollection: collections.PreferenceChangesCollection{},
+		}.Register(muxer)
+	})
+
+	It("routes GET /preference_changes", func() {
+		request, err := http.NewRequest("GET", "/preference_changes", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		s := muxer.Match(request).(stack.Stack)
+		Expect(s.Handler).To(BeAssignableToTypeOf(preferencechanges.ListHandler{}))
+		Expect(s.Middleware).To(HaveLen(3))
+
+		requestLogging := s.Middleware[0].(middleware.RequestLogging)
+		Expect(requestLogging).To(Equal(logging))
+
+		authenticator := s.Middleware[1].(middleware.Authenticator)
+		Expect(authenticator).To(Equal(auth))
+
+		databaseAllocator := s.Middleware[2].(middleware.DatabaseAllocator)
+		Expect(databaseAllocator).To(Equal(dbAllocator))
+	})
+})
@@ -5,22 +5,34 @@ import (
 	"database/sql"
 	"net/http"
 
+	"github.com/cloudfoundry-incubator/notifications/cache"
+	"github.com/cloudfoundry-incubator/notifications/cf"
 	"github.com/cloudfoundry-incubator/notifications/db"
 	"github.com/cloudfoundry-incubator/notifications/gobble"
 	"github.com/cloudfoundry-incubator/notifications/metrics"
 	"github.com/cloudfoundry-incubator/notifications/uaa"
 	"github.com/cloudfoundry-incubator/notifications/util"
+	v1models "github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v2/bundle"
 	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/cloudfoundry-incubator/notifications/v2/horde"
 	"github.com/cloudfoundry-incubator/notifications/v2/models"
 	"github.com/cloudfoundry-incubator/notifications/v2/queue"
+	"github.com/cloudfoundry-incubator/notifications/v2/web/audiences"
+	"github.com/cloudfoundry-incubator/notifications/v2/web/bundles"
 	"github.com/cloudfoundry-incubator/notifications/v2/web/campaigns"
 	"github.com/cloudfoundry-incubator/notifications/v2/web/campaigntypes"
 	"github.com/cloudfoundry-incubator/notifications/v2/web/info"
+	"github.com/cloudfoundry-incubator/notifications/v2/web/lookupcache"
 	"github.com/cloudfoundry-incubator/notifications/v2/web/middleware"
+	"github.com/cloudfoundry-incubator/notifications/v2/web/partials"
+	"github.com/cloudfoundry-incubator/notifications/v2/web/preferencechanges"
 	"github.com/cloudfoundry-incubator/notifications/v2/web/root"
 	"github.com/cloudfoundry-incubator/notifications/v2/web/senders"
 	"github.com/cloudfoundry-incubator/notifications/v2/web/templates"
 	"github.com/cloudfoundry-incubator/notifications/v2/web/unsubscribers"
+	"github.com/cloudfoundry-incubator/notifications/v2/web/userpreferences"
 	"github.com/gorilla/mux"
 	"github.com/pivotal-cf-experimental/warrant"
 	"github.com/pivotal-golang/lager"
@@ -53,6 +65,10 @@ type Config struct {
 	UAAClientID     string
 	UAAClientSecret string
 	CCHost          string
+	CCAPIVersion    int
+
+	DefaultUAAScopes []string
+	LookupCache      cache.Cache
 }
 
 func NewRouter(mx muxer, config Config) http.Handler {
@@ -75,6 +91,14 @@ func NewRouter(mx muxer, config Config) http.Handler {
 
 	userFinder := uaa.NewUserFinder(config.UAAClientID, config.UAAClientSecret, warrantUsersService, warrantClientsService)
 
+	uaaClient := uaa.NewCachedZonedUAAClient(uaa.NewZonedUAAClient(config.UAAClientID, config.UAAClientSecret, !config.SkipVerifySSL, config.UAAPublicKey), config.LookupCache)
+	cloudController := cf.NewCachedCloudController(cf.NewCloudControllerForVersion(config.CCHost, config.SkipVerifySSL, config.CCAPIVersion), config.LookupCache)
+	tokenLoader := uaa.NewTokenLoader(uaaClient)
+	spaceLoader := services.NewSpaceLoader(cloudController)
+	organizationLoader := services.NewOrganizationLoader(cloudController)
+	findsUserIDs := services.NewFindsUserIDs(cloudController, uaaClient)
+	allUsers := services.NewAllUsers(uaaClient)
+
 	database := db.NewDatabase(config.SQLDB, db.Config{})
 	campaignEnqueuer := queue.NewCampaignEnqueuer(config.Queue, database, gobble.Initializer{})
 
@@ -84,13 +108,55 @@ func NewRouter(mx muxer, config Config) http.Handler {
 	campaignsRepository := models.NewCampaignsRepository(guidGenerator.Generate, clock)
 	messagesRepository := models.NewMessagesRepository(clock, guidGenerator.Generate)
 	unsubscribersRepository := models.NewUnsubscribersRepository(guidGenerator.Generate)
+	partialsRepository := models.NewPartialsRepository(guidGenerator.Generate)
+	audiencesRepository := models.NewAudiencesRepository(guidGenerator.Generate, clock)
+	globalUnsubscribesRepository := models.NewGlobalUnsubscribesRepository(clock)
+	digestPreferencesRepository := models.NewDigestPreferencesRepository()
+	quietHoursRepository := models.NewQuietHoursRepository()
+	kindUnsubscribesRepository := models.NewKindUnsubscribesRepository(clock)
+	preferenceChangesRepository := models.NewPreferenceChangesRepository(clock)
+	emailUnsubscribesRepository := models.NewEmailUnsubscribesRepository(clock)
+	messageTimingsRepository := models.NewMessageTimingsRepository(clock)
+
+	audienceGenerators := horde.NewGenerators(horde.GeneratorsConfig{
+		UserFinder:       findsUserIDs,
+		OrgFinder:        organizationLoader,
+		SpaceFinder:      spaceLoader,
+		AllUsers:         allUsers,
+		TokenLoader:      tokenLoader,
+		Database:         database,
+		SavedAudiences:   audiencesRepository,
+		UAAHost:          config.UAAHost,
+		DefaultUAAScopes: config.DefaultUAAScopes,
+	})
+	unsubscribeFilter := collections.NewUnsubscribeFilter(unsubscribersRepository, globalUnsubscribesRepository, emailUnsubscribesRepository, campaignTypesRepository)
 
 	sendersCollection := collections.NewSendersCollection(sendersRepository, campaignTypesRepository)
 	templatesCollection := collections.NewTemplatesCollection(templatesRepository)
 	campaignTypesCollection := collections.NewCampaignTypesCollection(campaignTypesRepository, sendersRepository, templatesRepository)
-	campaignsCollection := collections.NewCampaignsCollection(campaignEnqueuer, campaignsRepository, campaignTypesRepository, templatesRepository, sendersRepository)
-	campaignStatusesCollection := collections.NewCampaignStatusesCollection(campaignsRepository, sendersRepository, messagesRepository)
-	unsubscribersCollection := collections.NewUnsubscribersCollection(unsubscribersRepository, campaignTypesRepository, userFinder)
+	campaignsCollection := collections.NewCampaignsCollection(campaignEnqueuer, campaignsRepository, campaignTypesRepository, templatesRepository, sendersRepository, audiencesRepository,
+		horde.NewUAAGroups(findsUserIDs, tokenLoader, config.UAAHost), config.DefaultUAAScopes)
+	campaignStatusesCollection := collections.NewCampaignStatusesCollection(campaignsRepository, sendersRepository, messagesRepository, messageTimingsRepository)
+	campaignPreviewsCollection := collections.NewCampaignPreviewsCollection(audienceGenerators, sendersRepository, campaignTypesRepository, unsubscribeFilter)
+	templateAssociationsCollection := collections.NewTemplateAssociationsCollection(templatesRepository, campaignTypesRepository, campaignsRepository, messagesRepository, clock)
+	unsubscribersCollection := collections.NewUnsubscribersCollection(unsubscribersRepository, campaignTypesRepository, userFinder, preferenceChangesRepository)
+	globalUnsubscribersCollection := collections.NewGlobalUnsubscribersCollection(globalUnsubscribesRepository, userFinder, preferenceChangesRepository)
+	userPreferencesCollection := collections.NewUserPreferencesCollection(campaignTypesRepository, sendersRepository, unsubscribersRepository, globalUnsubscribesRepository, digestPreferencesRepository, quietHoursRepository, preferenceChangesRepository, userFinder)
+	bulkUnsubscribesCollection := collections.NewBulkUnsubscribesCollection(unsubscribersRepository, globalUnsubscribesRepository, kindUnsubscribesRepository,
+		models.NewBulkUnsubscribesRepository(), campaignTypesRepository, preferenceChangesRepository)
+	partialsCollection := collections.NewPartialsCollection(partialsRepository)
+	audiencesCollection := collections.NewAudiencesCollection(audiencesRepository, sendersRepository)
+	preferenceChangesCollection := collections.NewPreferenceChangesCollection(preferenceChangesRepository)
+
+	bundler := bundle.NewBundler(bundle.BundlerConfig{
+		V1Database:              v1models.NewDatabase(config.SQLDB, v1models.Config{}),
+		TemplatesCollection:     templatesCollection,
+		SendersCollection:       sendersCollection,
+		CampaignTypesCollection: campaignTypesCollection,
+		V1ClientsRepository:     v1models.NewClientsRepo(),
+		V1KindsRepository:       v1models.NewKindsRepo(),
+		V1TemplatesRepository:   v1models.NewTemplatesRepo(),
+	})
 
 	root.Routes{
 		RequestLogging: requestLogging,
@@ -116,11 +182,33 @@ func NewRouter(mx muxer, config Config) http.Handler {
 	}.Register(mx)
 
 	templates.Routes{
+		RequestLogging:                 requestLogging,
+		WriteAuthenticator:             notificationsWriteAuthenticator,
+		AdminAuthenticator:             notificationsAdminAuthenticator,
+		DatabaseAllocator:              databaseAllocator,
+		TemplatesCollection:            templatesCollection,
+		TemplateAssociationsCollection: templateAssociationsCollection,
+	}.Register(mx)
+
+	partials.Routes{
+		RequestLogging:     requestLogging,
+		Authenticator:      notificationsWriteAuthenticator,
+		DatabaseAllocator:  databaseAllocator,
+		PartialsCollection: partialsCollection,
+	}.Register(mx)
+
+	audiences.Routes{
 		RequestLogging:      requestLogging,
-		WriteAuthenticator:  notificationsWriteAuthenticator,
-		AdminAuthenticator:  notificationsAdminAuthenticator,
+		Authenticator:       notificationsWriteAuthenticator,
 		DatabaseAllocator:   databaseAllocator,
-		TemplatesCollection: templatesCollection,
+		AudiencesCollection: audiencesCollection,
+	}.Register(mx)
+
+	bundles.Routes{
+		RequestLogging:    requestLogging,
+		Authenticator:     notificationsWriteAuthenticator,
+		DatabaseAllocator: databaseAllocator,
+		Bundler:           bundler,
 	}.Register(mx)
 
 	campaigns.Routes{
@@ -130,13 +218,38 @@ func NewRouter(mx muxer, config Config) http.Handler {
 		DatabaseAllocator:          databaseAllocator,
 		CampaignsCollection:        campaignsCollection,
 		CampaignStatusesCollection: campaignStatusesCollection,
+		CampaignPreviewsCollection: campaignPreviewsCollection,
 	}.Register(mx)
 
 	unsubscribers.Routes{
-		RequestLogging:          requestLogging,
-		Authenticator:           unsubscribesAuthenticator,
-		DatabaseAllocator:       databaseAllocator,
-		UnsubscribersCollection: unsubscribersCollection,
+		RequestLogging:                requestLogging,
+		Authenticator:                 unsubscribesAuthenticator,
+		AdminAuthenticator:            notificationsAdminAuthenticator,
+		DatabaseAllocator:             databaseAllocator,
+		UnsubscribersCollection:       unsubscribersCollection,
+		GlobalUnsubscribersCollection: globalUnsubscribersCollection,
+		BulkUnsubscribesCollection:    bulkUnsubscribesCollection,
+	}.Register(mx)
+
+	userpreferences.Routes{
+		RequestLogging:            requestLogging,
+		Authenticator:             unsubscribesAuthenticator,
+		TokenUserAuthenticator:    unsubscribesAuthenticator.ForTokenUser(),
+		DatabaseAllocator:         databaseAllocator,
+		UserPreferencesCollection: userPreferencesCollection,
+	}.Register(mx)
+
+	preferencechanges.Routes{
+		RequestLogging:              requestLogging,
+		Authenticator:               notificationsAdminAuthenticator,
+		DatabaseAllocator:           databaseAllocator,
+		PreferenceChangesCollection: preferenceChangesCollection,
+	}.Register(mx)
+
+	lookupcache.Routes{
+		RequestLogging: requestLogging,
+		Authenticator:  notificationsAdminAuthenticator,
+		Cache:          config.LookupCache,
 	}.Register(mx)
 
 	return mx
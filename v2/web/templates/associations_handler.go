@@ -0,0 +1,61 @@
+package templates
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/ryanmoran/stack"
+)
+
+const DefaultAssociationsDays = 30
+
+type associationsLister interface {
+	List(conn collections.ConnectionInterface, templateID, clientID string, days int) (collections.TemplateAssociations, error)
+}
+
+type AssociationsHandler struct {
+	associations associationsLister
+}
+
+func NewAssociationsHandler(associations associationsLister) AssociationsHandler {
+	return AssociationsHandler{
+		associations: associations,
+	}
+}
+
+func (h AssociationsHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	splitURL := strings.Split(req.URL.Path, "/")
+	templateID := splitURL[len(splitURL)-2]
+
+	days := DefaultAssociationsDays
+	if value := req.URL.Query().Get("days"); value != "" {
+		var err error
+		days, err = strconv.Atoi(value)
+		if err != nil || days < 1 {
+			w.WriteHeader(422)
+			fmt.Fprintf(w, `{"errors": [%q]}`, "days must be a positive integer")
+			return
+		}
+	}
+
+	database := context.Get("database").(DatabaseInterface)
+	clientID := context.Get("client_id").(string)
+
+	associations, err := h.associations.List(database.Connection(), templateID, clientID, days)
+	if err != nil {
+		switch err.(type) {
+		case collections.NotFoundError:
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		fmt.Fprintf(w, `{"errors": [%q]}`, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(NewAssociationsResponse(templateID, associations))
+}
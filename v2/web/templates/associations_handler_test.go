@@ -0,0 +1,177 @@
+package templates_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/cloudfoundry-incubator/notifications/v2/web/templates"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AssociationsHandler", func() {
+	var (
+		handler      templates.AssociationsHandler
+		writer       *httptest.ResponseRecorder
+		request      *http.Request
+		conn         *mocks.Connection
+		database     *mocks.Database
+		associations *mocks.TemplateAssociationsCollection
+		context      stack.Context
+	)
+
+	BeforeEach(func() {
+		writer = httptest.NewRecorder()
+
+		conn = mocks.NewConnection()
+		database = mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = conn
+
+		context = stack.NewContext()
+		context.Set("database", database)
+		context.Set("client_id", "some-client-id")
+
+		var err error
+		request, err = http.NewRequest("GET", "/templates/some-template-id/associations?days=7", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		startTime, err := time.Parse(time.RFC3339, "2015-09-01T12:34:56Z")
+		Expect(err).NotTo(HaveOccurred())
+
+		associations = mocks.NewTemplateAssociationsCollection()
+		associations.ListCall.Returns.Associations = collections.TemplateAssociations{
+			CampaignTypes: []collections.CampaignType{
+				{
+					ID:          "some-campaign-type-id",
+					Name:        "some-campaign-type",
+					Description: "a campaign type",
+					Critical:    true,
+					TemplateID:  "some-template-id",
+					SenderID:    "some-sender-id",
+				},
+			},
+			Campaigns: []collections.TemplateCampaign{
+				{
+					ID:             "some-campaign-id",
+					CampaignTypeID: "some-campaign-type-id",
+					Status:         "sending",
+					StartTime:      startTime,
+				},
+			},
+			SendCounts: collections.TemplateSendCounts{
+				Days:      7,
+				Total:     5,
+				Delivered: 3,
+				Queued:    1,
+				Failed:    1,
+			},
+		}
+
+		handler = templates.NewAssociationsHandler(associations)
+	})
+
+	It("lists what is using the template", func() {
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.String()).To(MatchJSON(`{
+			"in_use": true,
+			"campaign_types": [
+				{
+					"id": "some-campaign-type-id",
+					"name": "some-campaign-type",
+					"description": "a campaign type",
+					"critical": true,
+					"template_id": "some-template-id",
+					"sender_id": "some-sender-id",
+					"_links": {
+						"self": {"href": "/campaign_types/some-campaign-type-id"}
+					}
+				}
+			],
+			"campaigns": [
+				{
+					"id": "some-campaign-id",
+					"campaign_type_id": "some-campaign-type-id",
+					"status": "sending",
+					"start_time": "2015-09-01T12:34:56Z",
+					"_links": {
+						"self": {"href": "/campaigns/some-campaign-id"}
+					}
+				}
+			],
+			"send_counts": {
+				"days": 7,
+				"total": 5,
+				"delivered": 3,
+				"queued": 1,
+				"retry": 0,
+				"failed": 1,
+				"undeliverable": 0
+			},
+			"_links": {
+				"self": {"href": "/templates/some-template-id/associations"},
+				"template": {"href": "/templates/some-template-id"}
+			}
+		}`))
+
+		Expect(associations.ListCall.Receives.Connection).To(Equal(conn))
+		Expect(associations.ListCall.Receives.TemplateID).To(Equal("some-template-id"))
+		Expect(associations.ListCall.Receives.ClientID).To(Equal("some-client-id"))
+		Expect(associations.ListCall.Receives.Days).To(Equal(7))
+	})
+
+	It("defaults to the last 30 days of sends", func() {
+		var err error
+		request, err = http.NewRequest("GET", "/templates/some-template-id/associations", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(associations.ListCall.Receives.Days).To(Equal(30))
+	})
+
+	Context("failure cases", func() {
+		It("returns a 422 when days is not a positive integer", func() {
+			var err error
+			request, err = http.NewRequest("GET", "/templates/some-template-id/associations?days=banana", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(422))
+			Expect(writer.Body.String()).To(MatchJSON(`{
+				"errors": ["days must be a positive integer"]
+			}`))
+		})
+
+		It("returns a 404 when the template cannot be found", func() {
+			associations.ListCall.Returns.Error = collections.NotFoundError{errors.New(`Template with id "some-template-id" could not be found`)}
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusNotFound))
+			Expect(writer.Body.String()).To(MatchJSON(`{
+				"errors": ["Template with id \"some-template-id\" could not be found"]
+			}`))
+		})
+
+		It("returns a 500 when the associations cannot be listed", func() {
+			associations.ListCall.Returns.Error = collections.PersistenceError{errors.New("something bad happened")}
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusInternalServerError))
+			Expect(writer.Body.String()).To(MatchJSON(`{
+				"errors": ["something bad happened"]
+			}`))
+		})
+	})
+})
@@ -0,0 +1,94 @@
+package templates
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+)
+
+type AssociationsResponseLinks struct {
+	Self     Link `json:"self"`
+	Template Link `json:"template"`
+}
+
+type AssociatedCampaignTypeResponse struct {
+	ID          string                `json:"id"`
+	Name        string                `json:"name"`
+	Description string                `json:"description"`
+	Critical    bool                  `json:"critical"`
+	TemplateID  string                `json:"template_id"`
+	SenderID    string                `json:"sender_id"`
+	Links       TemplateResponseLinks `json:"_links"`
+}
+
+type AssociatedCampaignResponse struct {
+	ID             string                `json:"id"`
+	CampaignTypeID string                `json:"campaign_type_id"`
+	Status         string                `json:"status"`
+	StartTime      time.Time             `json:"start_time"`
+	Links          TemplateResponseLinks `json:"_links"`
+}
+
+type SendCountsResponse struct {
+	Days          int `json:"days"`
+	Total         int `json:"total"`
+	Delivered     int `json:"delivered"`
+	Queued        int `json:"queued"`
+	Retry         int `json:"retry"`
+	Failed        int `json:"failed"`
+	Undeliverable int `json:"undeliverable"`
+}
+
+type AssociationsResponse struct {
+	InUse         bool                             `json:"in_use"`
+	CampaignTypes []AssociatedCampaignTypeResponse `json:"campaign_types"`
+	Campaigns     []AssociatedCampaignResponse     `json:"campaigns"`
+	SendCounts    SendCountsResponse               `json:"send_counts"`
+	Links         AssociationsResponseLinks        `json:"_links"`
+}
+
+func NewAssociationsResponse(templateID string, associations collections.TemplateAssociations) AssociationsResponse {
+	response := AssociationsResponse{
+		InUse:         associations.InUse(),
+		CampaignTypes: []AssociatedCampaignTypeResponse{},
+		Campaigns:     []AssociatedCampaignResponse{},
+		SendCounts: SendCountsResponse{
+			Days:          associations.SendCounts.Days,
+			Total:         associations.SendCounts.Total,
+			Delivered:     associations.SendCounts.Delivered,
+			Queued:        associations.SendCounts.Queued,
+			Retry:         associations.SendCounts.Retry,
+			Failed:        associations.SendCounts.Failed,
+			Undeliverable: associations.SendCounts.Undeliverable,
+		},
+		Links: AssociationsResponseLinks{
+			Self:     Link{fmt.Sprintf("/templates/%s/associations", templateID)},
+			Template: Link{fmt.Sprintf("/templates/%s", templateID)},
+		},
+	}
+
+	for _, campaignType := range associations.CampaignTypes {
+		response.CampaignTypes = append(response.CampaignTypes, AssociatedCampaignTypeResponse{
+			ID:          campaignType.ID,
+			Name:        campaignType.Name,
+			Description: campaignType.Description,
+			Critical:    campaignType.Critical,
+			TemplateID:  campaignType.TemplateID,
+			SenderID:    campaignType.SenderID,
+			Links:       TemplateResponseLinks{Link{fmt.Sprintf("/campaign_types/%s", campaignType.ID)}},
+		})
+	}
+
+	for _, campaign := range associations.Campaigns {
+		response.Campaigns = append(response.Campaigns, AssociatedCampaignResponse{
+			ID:             campaign.ID,
+			CampaignTypeID: campaign.CampaignTypeID,
+			Status:         campaign.Status,
+			StartTime:      campaign.StartTime,
+			Links:          TemplateResponseLinks{Link{fmt.Sprintf("/campaigns/%s", campaign.ID)}},
+		})
+	}
+
+	return response
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/cloudfoundry-incubator/notifications/markdown"
 	"github.com/cloudfoundry-incubator/notifications/v2/collections"
 	"github.com/ryanmoran/stack"
 )
@@ -27,6 +28,7 @@ func (h CreateHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, conte
 	var createRequest struct {
 		Name     string           `json:"name"`
 		HTML     string           `json:"html"`
+		Markdown string           `json:"markdown"`
 		Text     string           `json:"text"`
 		Subject  string           `json:"subject"`
 		Metadata *json.RawMessage `json:"metadata"`
@@ -46,6 +48,8 @@ func (h CreateHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, conte
 		return
 	}
 
+	createRequest.HTML, createRequest.Text = markdown.NewRenderer().Fill(createRequest.Markdown, createRequest.HTML, createRequest.Text)
+
 	if createRequest.HTML == "" && createRequest.Text == "" {
 		w.WriteHeader(422)
 		w.Write([]byte(`{ "errors": ["missing either template text or html"] }`))
@@ -73,6 +77,7 @@ func (h CreateHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, conte
 	template, err := h.templates.Set(database.Connection(), collections.Template{
 		Name:     createRequest.Name,
 		HTML:     createRequest.HTML,
+		Markdown: createRequest.Markdown,
 		Text:     createRequest.Text,
 		Subject:  createRequest.Subject,
 		Metadata: string(*createRequest.Metadata),
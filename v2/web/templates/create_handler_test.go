@@ -70,6 +70,7 @@ var _ = Describe("CreateHandler", func() {
 			"name": "an interesting template",
 			"text": "template text",
 			"html": "template html",
+			"markdown": "",
 			"subject": "template subject",
 			"metadata": {
 				"template": "metadata"
@@ -110,6 +111,7 @@ var _ = Describe("CreateHandler", func() {
 			"name": "an interesting template",
 			"text": "this is my text",
 			"html": "",
+			"markdown": "",
 			"subject": "{{.Subject}}",
 			"metadata": {},
 			"_links": {
@@ -148,6 +150,7 @@ var _ = Describe("CreateHandler", func() {
 			"name": "an interesting template",
 			"text": "",
 			"html": "template html",
+			"markdown": "",
 			"subject": "{{.Subject}}",
 			"metadata": {},
 			"_links": {
@@ -158,6 +161,43 @@ var _ = Describe("CreateHandler", func() {
 		}`))
 	})
 
+	It("creates a template with only name and markdown", func() {
+		requestBody, err := json.Marshal(map[string]interface{}{
+			"name":     "an interesting template",
+			"markdown": "# Hello\n\n{{.HTML}}",
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		request, err = http.NewRequest("POST", "/templates", bytes.NewBuffer(requestBody))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusCreated))
+		Expect(templatesCollection.SetCall.Receives.Template.Markdown).To(Equal("# Hello\n\n{{.HTML}}"))
+		Expect(templatesCollection.SetCall.Receives.Template.HTML).To(Equal("<h1>Hello</h1>\n<p>{{.HTML}}</p>"))
+		Expect(templatesCollection.SetCall.Receives.Template.Text).To(Equal("Hello\n=====\n\n{{.HTML}}"))
+	})
+
+	It("does not derive html or text from markdown when they are provided", func() {
+		requestBody, err := json.Marshal(map[string]interface{}{
+			"name":     "an interesting template",
+			"markdown": "*rendered*",
+			"html":     "template html",
+			"text":     "template text",
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		request, err = http.NewRequest("POST", "/templates", bytes.NewBuffer(requestBody))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusCreated))
+		Expect(templatesCollection.SetCall.Receives.Template.HTML).To(Equal("template html"))
+		Expect(templatesCollection.SetCall.Receives.Template.Text).To(Equal("template text"))
+	})
+
 	It("defaults subject when it is empty string", func() {
 		templatesCollection.SetCall.Returns.Template = collections.Template{
 			ID:       "some-template-id",
@@ -186,6 +226,7 @@ var _ = Describe("CreateHandler", func() {
 			"name": "an interesting template",
 			"text": "",
 			"html": "template html",
+			"markdown": "",
 			"subject": "{{.Subject}}",
 			"metadata": {},
 			"_links": {
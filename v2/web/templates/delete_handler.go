@@ -15,12 +15,14 @@ type collectionsDeleter interface {
 }
 
 type DeleteHandler struct {
-	deleter collectionsDeleter
+	deleter      collectionsDeleter
+	associations associationsLister
 }
 
-func NewDeleteHandler(deleter collectionsDeleter) DeleteHandler {
+func NewDeleteHandler(deleter collectionsDeleter, associations associationsLister) DeleteHandler {
 	return DeleteHandler{
-		deleter: deleter,
+		deleter:      deleter,
+		associations: associations,
 	}
 }
 
@@ -44,6 +46,27 @@ func (h DeleteHandler) ServeHTTP(w http.ResponseWriter, request *http.Request, c
 		return
 	}
 
+	if request.URL.Query().Get("force") != "true" {
+		associations, err := h.associations.List(database.Connection(), templateID, clientID, DefaultAssociationsDays)
+		if err != nil {
+			switch err.(type) {
+			case collections.NotFoundError:
+				w.WriteHeader(http.StatusNotFound)
+			default:
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+
+			fmt.Fprintf(w, `{"errors": [%q]}`, err)
+			return
+		}
+
+		if associations.InUse() {
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprintf(w, `{"errors": [%q]}`, fmt.Sprintf("Template with id %q is still in use; delete with ?force=true to remove it anyway", templateID))
+			return
+		}
+	}
+
 	err = h.deleter.Delete(database.Connection(), templateID)
 	if err != nil {
 		switch err.(type) {
@@ -16,13 +16,14 @@ import (
 
 var _ = Describe("DeleteHandler", func() {
 	var (
-		handler    templates.DeleteHandler
-		writer     *httptest.ResponseRecorder
-		request    *http.Request
-		conn       *mocks.Connection
-		database   *mocks.Database
-		collection *mocks.TemplatesCollection
-		context    stack.Context
+		handler      templates.DeleteHandler
+		writer       *httptest.ResponseRecorder
+		request      *http.Request
+		conn         *mocks.Connection
+		database     *mocks.Database
+		collection   *mocks.TemplatesCollection
+		associations *mocks.TemplateAssociationsCollection
+		context      stack.Context
 	)
 
 	BeforeEach(func() {
@@ -41,7 +42,8 @@ var _ = Describe("DeleteHandler", func() {
 		Expect(err).NotTo(HaveOccurred())
 
 		collection = mocks.NewTemplatesCollection()
-		handler = templates.NewDeleteHandler(collection)
+		associations = mocks.NewTemplateAssociationsCollection()
+		handler = templates.NewDeleteHandler(collection, associations)
 	})
 
 	It("deletes a template", func() {
@@ -59,6 +61,40 @@ var _ = Describe("DeleteHandler", func() {
 
 		Expect(collection.DeleteCall.Receives.Connection).To(Equal(database.Connection()))
 		Expect(collection.DeleteCall.Receives.TemplateID).To(Equal("some-template-id"))
+
+		Expect(associations.ListCall.Receives.Connection).To(Equal(conn))
+		Expect(associations.ListCall.Receives.TemplateID).To(Equal("some-template-id"))
+		Expect(associations.ListCall.Receives.ClientID).To(Equal("some-client-id"))
+	})
+
+	Context("when the template is still in use", func() {
+		BeforeEach(func() {
+			associations.ListCall.Returns.Associations = collections.TemplateAssociations{
+				CampaignTypes: []collections.CampaignType{{ID: "some-campaign-type-id"}},
+			}
+		})
+
+		It("refuses to delete the template", func() {
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusConflict))
+			Expect(writer.Body.String()).To(MatchJSON(`{
+				"errors": ["Template with id \"some-template-id\" is still in use; delete with ?force=true to remove it anyway"]
+			}`))
+			Expect(collection.DeleteCall.Receives.TemplateID).To(BeEmpty())
+		})
+
+		It("deletes the template when forced", func() {
+			var err error
+			request, err = http.NewRequest("DELETE", "/templates/some-template-id?force=true", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusNoContent))
+			Expect(associations.ListCall.Receives.TemplateID).To(BeEmpty())
+			Expect(collection.DeleteCall.Receives.TemplateID).To(Equal("some-template-id"))
+		})
 	})
 
 	Context("failure cases", func() {
@@ -92,6 +128,16 @@ var _ = Describe("DeleteHandler", func() {
 			}`))
 		})
 
+		It("returns a 500 when the associations cannot be listed", func() {
+			associations.ListCall.Returns.Error = collections.PersistenceError{errors.New("something bad happened")}
+
+			handler.ServeHTTP(writer, request, context)
+			Expect(writer.Code).To(Equal(http.StatusInternalServerError))
+			Expect(writer.Body.String()).To(MatchJSON(`{
+				"errors": ["something bad happened"]
+			}`))
+		})
+
 		It("returns a 500 when the delete collection call results in an unknown error", func() {
 			collection.DeleteCall.Returns.Error = errors.New("something bad happened")
 
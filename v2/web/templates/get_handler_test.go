@@ -64,6 +64,7 @@ var _ = Describe("GetHandler", func() {
 			"name": "an interesting template",
 			"text": "template text",
 			"html": "template html",
+			"markdown": "",
 			"subject": "template subject",
 			"metadata": {
 				"template": "metadata"
@@ -67,6 +67,7 @@ var _ = Describe("ListHandler", func() {
 					"name": "an interesting template",
 					"text": "template text",
 					"html": "template html",
+					"markdown": "",
 					"subject": "template subject",
 					"metadata": {
 						"template": "metadata"
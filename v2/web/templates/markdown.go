@@ -0,0 +1,25 @@
+package templates
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/markdown"
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+)
+
+// applyMarkdown stores the new markdown source on the template and renders it
+// into the bodies that the update did not set, so clearing the source also
+// clears the bodies that were rendered from it.
+func applyMarkdown(template collections.Template, source string, htmlSet, textSet bool) collections.Template {
+	template.Markdown = source
+
+	if !htmlSet {
+		template.HTML = ""
+	}
+
+	if !textSet {
+		template.Text = ""
+	}
+
+	template.HTML, template.Text = markdown.NewRenderer().Fill(source, template.HTML, template.Text)
+
+	return template
+}
@@ -10,18 +10,20 @@ type muxer interface {
 }
 
 type Routes struct {
-	RequestLogging      stack.Middleware
-	WriteAuthenticator  stack.Middleware
-	AdminAuthenticator  stack.Middleware
-	DatabaseAllocator   stack.Middleware
-	TemplatesCollection collections.TemplatesCollection
+	RequestLogging                 stack.Middleware
+	WriteAuthenticator             stack.Middleware
+	AdminAuthenticator             stack.Middleware
+	DatabaseAllocator              stack.Middleware
+	TemplatesCollection            collections.TemplatesCollection
+	TemplateAssociationsCollection collections.TemplateAssociationsCollection
 }
 
 func (r Routes) Register(m muxer) {
 	m.Handle("GET", "/templates", NewListHandler(r.TemplatesCollection), r.RequestLogging, r.WriteAuthenticator, r.DatabaseAllocator)
 	m.Handle("POST", "/templates", NewCreateHandler(r.TemplatesCollection), r.RequestLogging, r.WriteAuthenticator, r.DatabaseAllocator)
 	m.Handle("GET", "/templates/{template_id}", NewGetHandler(r.TemplatesCollection), r.RequestLogging, r.WriteAuthenticator, r.DatabaseAllocator)
-	m.Handle("DELETE", "/templates/{template_id}", NewDeleteHandler(r.TemplatesCollection), r.RequestLogging, r.WriteAuthenticator, r.DatabaseAllocator)
+	m.Handle("GET", "/templates/{template_id}/associations", NewAssociationsHandler(r.TemplateAssociationsCollection), r.RequestLogging, r.WriteAuthenticator, r.DatabaseAllocator)
+	m.Handle("DELETE", "/templates/{template_id}", NewDeleteHandler(r.TemplatesCollection, r.TemplateAssociationsCollection), r.RequestLogging, r.WriteAuthenticator, r.DatabaseAllocator)
 	m.Handle("PUT", "/templates/default", NewUpdateDefaultHandler(r.TemplatesCollection), r.RequestLogging, r.AdminAuthenticator, r.DatabaseAllocator)
 	m.Handle("PUT", "/templates/{template_id}", NewUpdateHandler(r.TemplatesCollection), r.RequestLogging, r.WriteAuthenticator, r.DatabaseAllocator)
 }
@@ -76,6 +76,24 @@ var _ = Describe("Routes", func() {
 		Expect(databaseAllocator).To(Equal(dbAllocator))
 	})
 
+	It("routes GET /templates/ID/associations", func() {
+		request, err := http.NewRequest("GET", "/templates/some-template-id/associations", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		s := muxer.Match(request).(stack.Stack)
+		Expect(s.Handler).To(BeAssignableToTypeOf(templates.AssociationsHandler{}))
+		Expect(s.Middleware).To(HaveLen(3))
+
+		requestLogging := s.Middleware[0].(middleware.RequestLogging)
+		Expect(requestLogging).To(Equal(logging))
+
+		authenticator := s.Middleware[1].(middleware.Authenticator)
+		Expect(authenticator).To(Equal(writeAuth))
+
+		databaseAllocator := s.Middleware[2].(middleware.DatabaseAllocator)
+		Expect(databaseAllocator).To(Equal(dbAllocator))
+	})
+
 	It("routes DELETE /templates/ID", func() {
 		request, err := http.NewRequest("DELETE", "/templates/some-template-id", nil)
 		Expect(err).NotTo(HaveOccurred())
@@ -20,6 +20,7 @@ type TemplateResponse struct {
 	Name     string                `json:"name"`
 	Text     string                `json:"text"`
 	HTML     string                `json:"html"`
+	Markdown string                `json:"markdown"`
 	Subject  string                `json:"subject"`
 	Metadata *json.RawMessage      `json:"metadata"`
 	Links    TemplateResponseLinks `json:"_links"`
@@ -32,6 +33,7 @@ func NewTemplateResponse(template collections.Template) TemplateResponse {
 		Name:     template.Name,
 		Text:     template.Text,
 		HTML:     template.HTML,
+		Markdown: template.Markdown,
 		Subject:  template.Subject,
 		Metadata: &metadata,
 		Links:    TemplateResponseLinks{Link{fmt.Sprintf("/templates/%s", template.ID)}},
@@ -53,6 +53,7 @@ var _ = Describe("TemplateResponse", func() {
 			"name": "some-template",
 			"text": "template-text",
 			"html": "template-html",
+			"markdown": "",
 			"subject": "template-subject",
 			"metadata": {
 				"template": "metadata"
@@ -79,6 +79,7 @@ var _ = Describe("TemplatesListResponse", func() {
 					"name": "some-template",
 					"text": "template-text",
 					"html": "template-html",
+					"markdown": "",
 					"subject": "template-subject",
 					"metadata": {
 						"template": "metadata"
@@ -94,6 +95,7 @@ var _ = Describe("TemplatesListResponse", func() {
 					"name": "another-template",
 					"text": "another-template-text",
 					"html": "another-template-html",
+					"markdown": "",
 					"subject": "another-template-subject",
 					"metadata": {
 						"template": "another-metadata"
@@ -22,6 +22,7 @@ func (h UpdateDefaultHandler) ServeHTTP(w http.ResponseWriter, req *http.Request
 	var updateRequest struct {
 		Name     *string          `json:"name"`
 		HTML     *string          `json:"html"`
+		Markdown *string          `json:"markdown"`
 		Text     *string          `json:"text"`
 		Subject  *string          `json:"subject"`
 		Metadata *json.RawMessage `json:"metadata"`
@@ -55,6 +56,10 @@ func (h UpdateDefaultHandler) ServeHTTP(w http.ResponseWriter, req *http.Request
 		template.HTML = *updateRequest.HTML
 	}
 
+	if updateRequest.Markdown != nil {
+		template = applyMarkdown(template, *updateRequest.Markdown, updateRequest.HTML != nil, updateRequest.Text != nil)
+	}
+
 	if updateRequest.Subject != nil {
 		template.Subject = *updateRequest.Subject
 	}
@@ -79,6 +79,7 @@ var _ = Describe("UpdateDefaultHandler", func() {
 			"id":       "default",
 			"name":     "new template name",
 			"html":     "new html",
+			"markdown": "",
 			"text":     "new text",
 			"subject":  "new subject",
 			"metadata": {"template":"new"},
@@ -101,6 +102,25 @@ var _ = Describe("UpdateDefaultHandler", func() {
 		}))
 	})
 
+	Context("when updating the markdown", func() {
+		It("re-renders the html and text from the markdown", func() {
+			requestBody, err := json.Marshal(map[string]interface{}{
+				"markdown": "{{.HTML}}\n\n---",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			request, err = http.NewRequest("PUT", "/templates/default", bytes.NewBuffer(requestBody))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusOK))
+			Expect(templatesCollection.SetCall.Receives.Template.Markdown).To(Equal("{{.HTML}}\n\n---"))
+			Expect(templatesCollection.SetCall.Receives.Template.HTML).To(Equal("<p>{{.HTML}}</p>\n<hr />"))
+			Expect(templatesCollection.SetCall.Receives.Template.Text).To(Equal("{{.HTML}}\n\n----------"))
+		})
+	})
+
 	Context("when omitting fields", func() {
 		BeforeEach(func() {
 			requestBody, err := json.Marshal(map[string]interface{}{})
@@ -127,6 +147,7 @@ var _ = Describe("UpdateDefaultHandler", func() {
 				"id":       "default",
 				"name":     "a default template",
 				"html":     "default html",
+				"markdown": "",
 				"text":     "default text",
 				"subject":  "default subject",
 				"metadata": {"template":"default"},
@@ -177,6 +198,7 @@ var _ = Describe("UpdateDefaultHandler", func() {
 				"id":       "default",
 				"name":     "a default template",
 				"html":     "default html",
+				"markdown": "",
 				"text":     "default text",
 				"subject":  "{{.Subject}}",
 				"metadata": {"template":"default"},
@@ -32,6 +32,7 @@ func (h UpdateHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, conte
 	var updateRequest struct {
 		Name     *string          `json:"name"`
 		HTML     *string          `json:"html"`
+		Markdown *string          `json:"markdown"`
 		Text     *string          `json:"text"`
 		Subject  *string          `json:"subject"`
 		Metadata *json.RawMessage `json:"metadata"`
@@ -72,6 +73,10 @@ func (h UpdateHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, conte
 		template.Text = *updateRequest.Text
 	}
 
+	if updateRequest.Markdown != nil {
+		template = applyMarkdown(template, *updateRequest.Markdown, updateRequest.HTML != nil, updateRequest.Text != nil)
+	}
+
 	if updateRequest.Subject != nil {
 		template.Subject = *updateRequest.Subject
 	}
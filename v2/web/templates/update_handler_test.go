@@ -79,6 +79,7 @@ var _ = Describe("UpdateHandler", func() {
 			"name": "an interesting template",
 			"text": "template text",
 			"html": "template html",
+			"markdown": "",
 			"subject": "template subject",
 			"metadata": {
 				"template": "metadata"
@@ -102,6 +103,64 @@ var _ = Describe("UpdateHandler", func() {
 		}))
 	})
 
+	Context("when updating the markdown", func() {
+		It("re-renders the html and text from the markdown", func() {
+			requestBody, err := json.Marshal(map[string]interface{}{
+				"markdown": "**updated**",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			request, err = http.NewRequest("PUT", "/templates/some-template-id", bytes.NewBuffer(requestBody))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusOK))
+			Expect(templatesCollection.SetCall.Receives.Template.Markdown).To(Equal("**updated**"))
+			Expect(templatesCollection.SetCall.Receives.Template.HTML).To(Equal("<p><strong>updated</strong></p>"))
+			Expect(templatesCollection.SetCall.Receives.Template.Text).To(Equal("updated"))
+		})
+
+		It("keeps html provided in the same request", func() {
+			requestBody, err := json.Marshal(map[string]interface{}{
+				"markdown": "**updated**",
+				"html":     "new html",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			request, err = http.NewRequest("PUT", "/templates/some-template-id", bytes.NewBuffer(requestBody))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusOK))
+			Expect(templatesCollection.SetCall.Receives.Template.HTML).To(Equal("new html"))
+			Expect(templatesCollection.SetCall.Receives.Template.Text).To(Equal("updated"))
+		})
+
+		It("clears the bodies rendered from markdown when the markdown is cleared", func() {
+			templatesCollection.GetCall.Returns.Template.Markdown = "**old**"
+			templatesCollection.GetCall.Returns.Template.HTML = "<p><strong>old</strong></p>"
+			templatesCollection.GetCall.Returns.Template.Text = "old"
+
+			requestBody, err := json.Marshal(map[string]interface{}{
+				"markdown": "",
+				"text":     "plain text",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			request, err = http.NewRequest("PUT", "/templates/some-template-id", bytes.NewBuffer(requestBody))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusOK))
+			Expect(templatesCollection.SetCall.Receives.Template.Markdown).To(BeEmpty())
+			Expect(templatesCollection.SetCall.Receives.Template.HTML).To(BeEmpty())
+			Expect(templatesCollection.SetCall.Receives.Template.Text).To(Equal("plain text"))
+		})
+	})
+
 	Context("when omitting fields", func() {
 		BeforeEach(func() {
 			requestBody, err := json.Marshal(map[string]interface{}{})
@@ -129,6 +188,7 @@ var _ = Describe("UpdateHandler", func() {
 				"name": "an interesting template",
 				"text": "template text",
 				"html": "template html",
+				"markdown": "",
 				"subject": "template subject",
 				"metadata": {
 					"template": "metadata"
@@ -186,6 +246,7 @@ var _ = Describe("UpdateHandler", func() {
 				"name": "an interesting template",
 				"text": "template text",
 				"html": "template html",
+				"markdown": "",
 				"subject": "{{.Subject}}",
 				"metadata": {
 					"template": "metadata"
@@ -0,0 +1,30 @@
+package unsubscribers
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/ryanmoran/stack"
+)
+
+// actorFor identifies who made a preference change for the audit trail: the
+// user when the token belongs to one, otherwise the client.
+func actorFor(context stack.Context) models.Actor {
+	actor := models.Actor{
+		Type:   models.ActorClient,
+		Source: models.SourceAPI,
+	}
+
+	token, ok := context.Get("token").(*jwt.Token)
+	if !ok {
+		return actor
+	}
+
+	if userGUID, ok := token.Claims["user_id"].(string); ok && userGUID != "" {
+		actor.Type = models.ActorUser
+		actor.ID = userGUID
+		return actor
+	}
+
+	actor.ID, _ = token.Claims["client_id"].(string)
+	return actor
+}
@@ -0,0 +1,173 @@
+package unsubscribers
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+)
+
+const (
+	csvContentType    = "text/csv"
+	ndjsonContentType = "application/x-ndjson"
+)
+
+var csvColumns = []string{"user_guid", "campaign_type_id", "client_id", "kind_id", "global"}
+
+type bulkUnsubscribeRow struct {
+	UserGUID       string `json:"user_guid"`
+	CampaignTypeID string `json:"campaign_type_id,omitempty"`
+	ClientID       string `json:"client_id,omitempty"`
+	KindID         string `json:"kind_id,omitempty"`
+	Global         bool   `json:"global,omitempty"`
+}
+
+type bulkFormatError struct {
+	message string
+}
+
+func (e bulkFormatError) Error() string {
+	return e.message
+}
+
+func readCSV(body io.Reader) ([]collections.BulkUnsubscribe, error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return []collections.BulkUnsubscribe{}, nil
+	}
+	if err != nil {
+		return nil, bulkFormatError{"invalid csv header"}
+	}
+
+	for _, column := range header {
+		if !isCSVColumn(column) {
+			return nil, bulkFormatError{fmt.Sprintf("unknown csv column %q", column)}
+		}
+	}
+
+	unsubscribes := []collections.BulkUnsubscribe{}
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, bulkFormatError{fmt.Sprintf("invalid csv on row %d", row)}
+		}
+
+		var unsubscribe collections.BulkUnsubscribe
+		for i, value := range record {
+			if i >= len(header) {
+				return nil, bulkFormatError{fmt.Sprintf("invalid csv on row %d", row)}
+			}
+
+			switch header[i] {
+			case "user_guid":
+				unsubscribe.UserGUID = value
+			case "campaign_type_id":
+				unsubscribe.CampaignTypeID = value
+			case "client_id":
+				unsubscribe.ClientID = value
+			case "kind_id":
+				unsubscribe.KindID = value
+			case "global":
+				if value != "" {
+					unsubscribe.Global, err = strconv.ParseBool(value)
+					if err != nil {
+						return nil, bulkFormatError{fmt.Sprintf("invalid global value %q on row %d", value, row)}
+					}
+				}
+			}
+		}
+
+		unsubscribes = append(unsubscribes, unsubscribe)
+	}
+
+	return unsubscribes, nil
+}
+
+func isCSVColumn(column string) bool {
+	for _, known := range csvColumns {
+		if column == known {
+			return true
+		}
+	}
+
+	return false
+}
+
+func readNDJSON(body io.Reader) ([]collections.BulkUnsubscribe, error) {
+	scanner := bufio.NewScanner(body)
+
+	unsubscribes := []collections.BulkUnsubscribe{}
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var row bulkUnsubscribeRow
+		err := json.Unmarshal([]byte(line), &row)
+		if err != nil {
+			return nil, bulkFormatError{fmt.Sprintf("invalid json on row %d", lineNumber)}
+		}
+
+		unsubscribes = append(unsubscribes, collections.BulkUnsubscribe{
+			UserGUID:       row.UserGUID,
+			CampaignTypeID: row.CampaignTypeID,
+			ClientID:       row.ClientID,
+			KindID:         row.KindID,
+			Global:         row.Global,
+			Row:            lineNumber,
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, bulkFormatError{fmt.Sprintf("invalid json on row %d", lineNumber+1)}
+	}
+
+	return unsubscribes, nil
+}
+
+func writeCSV(w io.Writer, unsubscribes []collections.BulkUnsubscribe) {
+	writer := csv.NewWriter(w)
+	writer.Write(csvColumns)
+
+	for _, unsubscribe := range unsubscribes {
+		writer.Write([]string{
+			unsubscribe.UserGUID,
+			unsubscribe.CampaignTypeID,
+			unsubscribe.ClientID,
+			unsubscribe.KindID,
+			strconv.FormatBool(unsubscribe.Global),
+		})
+	}
+
+	writer.Flush()
+}
+
+func writeNDJSON(w io.Writer, unsubscribes []collections.BulkUnsubscribe) {
+	encoder := json.NewEncoder(w)
+
+	for _, unsubscribe := range unsubscribes {
+		encoder.Encode(bulkUnsubscribeRow{
+			UserGUID:       unsubscribe.UserGUID,
+			CampaignTypeID: unsubscribe.CampaignTypeID,
+			ClientID:       unsubscribe.ClientID,
+			KindID:         unsubscribe.KindID,
+			Global:         unsubscribe.Global,
+		})
+	}
+}
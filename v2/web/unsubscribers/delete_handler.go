@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
 	"github.com/ryanmoran/stack"
 )
 
@@ -14,7 +15,7 @@ type DeleteHandler struct {
 }
 
 type unsubscribersDeleter interface {
-	Delete(conn collections.ConnectionInterface, unsubscriber collections.Unsubscriber) error
+	Delete(conn collections.ConnectionInterface, unsubscriber collections.Unsubscriber, actor models.Actor) error
 }
 
 func NewDeleteHandler(collection unsubscribersDeleter) DeleteHandler {
@@ -32,7 +33,7 @@ func (h DeleteHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, conte
 	err := h.collection.Delete(database.Connection(), collections.Unsubscriber{
 		CampaignTypeID: campaignTypeID,
 		UserGUID:       userGUID,
-	})
+	}, actorFor(context))
 	if err != nil {
 		switch err.(type) {
 		case collections.NotFoundError:
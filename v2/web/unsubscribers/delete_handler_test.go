@@ -7,7 +7,9 @@ import (
 
 	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
 	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
 	"github.com/cloudfoundry-incubator/notifications/v2/web/unsubscribers"
+	"github.com/dgrijalva/jwt-go"
 	"github.com/ryanmoran/stack"
 
 	. "github.com/onsi/ginkgo"
@@ -56,6 +58,20 @@ var _ = Describe("DeleteHandler", func() {
 		Expect(unsubscribersCollection.DeleteCall.Receives.Connection).To(Equal(connection))
 	})
 
+	It("attributes the change to the client that owns the token", func() {
+		context.Set("token", &jwt.Token{Claims: map[string]interface{}{
+			"client_id": "some-client-id",
+		}})
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(unsubscribersCollection.DeleteCall.Receives.Actor).To(Equal(models.Actor{
+			Type:   models.ActorClient,
+			ID:     "some-client-id",
+			Source: models.SourceAPI,
+		}))
+	})
+
 	Context("failure cases", func() {
 		Context("when the Delete call returns a NotFoundError", func() {
 			It("returns a 404 with the error message in JSON", func() {
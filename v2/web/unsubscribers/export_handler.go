@@ -0,0 +1,77 @@
+package unsubscribers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/ryanmoran/stack"
+)
+
+type bulkExporter interface {
+	Export(conn collections.ConnectionInterface, offset, limit int) ([]collections.BulkUnsubscribe, error)
+}
+
+const (
+	DefaultExportLimit = 1000
+	MaxExportLimit     = 10000
+)
+
+type ExportHandler struct {
+	collection bulkExporter
+}
+
+func NewExportHandler(collection bulkExporter) ExportHandler {
+	return ExportHandler{
+		collection: collection,
+	}
+}
+
+func (h ExportHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	limit := DefaultExportLimit
+	if value := req.URL.Query().Get("limit"); value != "" {
+		var err error
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > MaxExportLimit {
+			w.WriteHeader(422)
+			fmt.Fprintf(w, `{"errors": [%q]}`, fmt.Sprintf("limit must be an integer between 1 and %d", MaxExportLimit))
+			return
+		}
+	}
+
+	var offset int
+	if value := req.URL.Query().Get("offset"); value != "" {
+		var err error
+		offset, err = strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			w.WriteHeader(422)
+			fmt.Fprintf(w, `{"errors": [%q]}`, "offset must be a non-negative integer")
+			return
+		}
+	}
+
+	database := context.Get("database").(DatabaseInterface)
+
+	unsubscribes, err := h.collection.Export(database.Connection(), offset, limit)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"errors": [%q]}`, err)
+		return
+	}
+
+	if len(unsubscribes) == limit {
+		w.Header().Set("Link", fmt.Sprintf(`</unsubscribes/export?offset=%d&limit=%d>; rel="next"`, offset+limit, limit))
+	}
+
+	if strings.Contains(req.Header.Get("Accept"), csvContentType) {
+		w.Header().Set("Content-Type", csvContentType)
+		w.Header().Set("Content-Disposition", `attachment; filename="unsubscribes.csv"`)
+		writeCSV(w, unsubscribes)
+		return
+	}
+
+	w.Header().Set("Content-Type", ndjsonContentType)
+	writeNDJSON(w, unsubscribes)
+}
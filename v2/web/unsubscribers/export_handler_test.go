@@ -0,0 +1,139 @@
+package unsubscribers_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/cloudfoundry-incubator/notifications/v2/web/unsubscribers"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ExportHandler", func() {
+	var (
+		handler    unsubscribers.ExportHandler
+		writer     *httptest.ResponseRecorder
+		request    *http.Request
+		context    stack.Context
+		collection *mocks.BulkUnsubscribesCollection
+		database   *mocks.Database
+		connection *mocks.Connection
+	)
+
+	BeforeEach(func() {
+		collection = mocks.NewBulkUnsubscribesCollection()
+		collection.ExportCall.Returns.Unsubscribes = []collections.BulkUnsubscribe{
+			{UserGUID: "some-user-guid", Global: true},
+			{UserGUID: "other-user-guid", ClientID: "some-client-id", KindID: "some-kind-id"},
+			{UserGUID: "third-user-guid", CampaignTypeID: "some-campaign-type-id"},
+		}
+		handler = unsubscribers.NewExportHandler(collection)
+
+		connection = mocks.NewConnection()
+		database = mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = connection
+
+		context = stack.NewContext()
+		context.Set("database", database)
+
+		writer = httptest.NewRecorder()
+
+		var err error
+		request, err = http.NewRequest("GET", "/unsubscribes/export", nil)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("exports every unsubscribe as NDJSON", func() {
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Header().Get("Content-Type")).To(Equal("application/x-ndjson"))
+		Expect(writer.Body.String()).To(Equal(`{"user_guid":"some-user-guid","global":true}
+{"user_guid":"other-user-guid","client_id":"some-client-id","kind_id":"some-kind-id"}
+{"user_guid":"third-user-guid","campaign_type_id":"some-campaign-type-id"}
+`))
+
+		Expect(collection.ExportCall.Receives.Connection).To(Equal(connection))
+		Expect(collection.ExportCall.Receives.Offset).To(Equal(0))
+		Expect(collection.ExportCall.Receives.Limit).To(Equal(unsubscribers.DefaultExportLimit))
+		Expect(writer.Header().Get("Link")).To(BeEmpty())
+	})
+
+	It("exports the requested page and links to the next one when the page is full", func() {
+		var err error
+		request, err = http.NewRequest("GET", "/unsubscribes/export?offset=6&limit=3", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(collection.ExportCall.Receives.Offset).To(Equal(6))
+		Expect(collection.ExportCall.Receives.Limit).To(Equal(3))
+		Expect(writer.Header().Get("Link")).To(Equal(`</unsubscribes/export?offset=9&limit=3>; rel="next"`))
+	})
+
+	It("exports every unsubscribe as CSV when asked to", func() {
+		request.Header.Set("Accept", "text/csv")
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Header().Get("Content-Type")).To(Equal("text/csv"))
+		Expect(writer.Header().Get("Content-Disposition")).To(Equal(`attachment; filename="unsubscribes.csv"`))
+		Expect(writer.Body.String()).To(Equal(`user_guid,campaign_type_id,client_id,kind_id,global
+some-user-guid,,,,true
+other-user-guid,,some-client-id,some-kind-id,false
+third-user-guid,some-campaign-type-id,,,false
+`))
+	})
+
+	Context("when the page is invalid", func() {
+		var exportWith = func(query string) {
+			var err error
+			request, err = http.NewRequest("GET", "/unsubscribes/export?"+query, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+		}
+
+		It("returns a 422 when the limit is not a number", func() {
+			exportWith("limit=lots")
+
+			Expect(writer.Code).To(Equal(422))
+			Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["limit must be an integer between 1 and 10000"]}`))
+		})
+
+		It("returns a 422 when the limit is out of range", func() {
+			exportWith("limit=0")
+			Expect(writer.Code).To(Equal(422))
+
+			writer = httptest.NewRecorder()
+			exportWith("limit=10001")
+			Expect(writer.Code).To(Equal(422))
+			Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["limit must be an integer between 1 and 10000"]}`))
+		})
+
+		It("returns a 422 when the offset is negative", func() {
+			exportWith("offset=-1")
+
+			Expect(writer.Code).To(Equal(422))
+			Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["offset must be a non-negative integer"]}`))
+		})
+	})
+
+	It("returns a 500 when the export fails", func() {
+		collection.ExportCall.Returns.Error = collections.PersistenceError{errors.New("db is down")}
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusInternalServerError))
+		Expect(writer.Body.String()).To(MatchJSON(`{
+			"errors": ["db is down"]
+		}`))
+	})
+})
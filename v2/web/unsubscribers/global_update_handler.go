@@ -0,0 +1,45 @@
+package unsubscribers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+	"github.com/ryanmoran/stack"
+)
+
+type GlobalUpdateHandler struct {
+	collection globalUnsubscribersSetter
+}
+
+type globalUnsubscribersSetter interface {
+	Set(connection collections.ConnectionInterface, userGUID string, actor models.Actor) error
+}
+
+func NewGlobalUpdateHandler(collection globalUnsubscribersSetter) GlobalUpdateHandler {
+	return GlobalUpdateHandler{
+		collection: collection,
+	}
+}
+
+func (h GlobalUpdateHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	splitURL := strings.Split(req.URL.Path, "/")
+	userGUID := splitURL[2]
+
+	database := context.Get("database").(DatabaseInterface)
+	err := h.collection.Set(database.Connection(), userGUID, actorFor(context))
+	if err != nil {
+		switch err.(type) {
+		case collections.NotFoundError:
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		w.Write([]byte(fmt.Sprintf(`{"errors": [%q]}`, err)))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
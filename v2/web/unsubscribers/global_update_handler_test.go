@@ -0,0 +1,91 @@
+package unsubscribers_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+	"github.com/cloudfoundry-incubator/notifications/v2/web/unsubscribers"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GlobalUpdateHandler", func() {
+	var (
+		handler                       unsubscribers.GlobalUpdateHandler
+		writer                        *httptest.ResponseRecorder
+		request                       *http.Request
+		context                       stack.Context
+		globalUnsubscribersCollection *mocks.GlobalUnsubscribersCollection
+		database                      *mocks.Database
+		connection                    *mocks.Connection
+	)
+
+	BeforeEach(func() {
+		var err error
+
+		globalUnsubscribersCollection = mocks.NewGlobalUnsubscribersCollection()
+		handler = unsubscribers.NewGlobalUpdateHandler(globalUnsubscribersCollection)
+
+		connection = mocks.NewConnection()
+		database = mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = connection
+
+		context = stack.NewContext()
+		context.Set("database", database)
+
+		writer = httptest.NewRecorder()
+
+		request, err = http.NewRequest("PUT", "/global_unsubscribers/some-user-guid", nil)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("globally unsubscribes the user", func() {
+		handler.ServeHTTP(writer, request, context)
+		Expect(writer.Code).To(Equal(http.StatusNoContent))
+		Expect(writer.Body.String()).To(BeEmpty())
+
+		Expect(globalUnsubscribersCollection.SetCall.Receives.Connection).To(Equal(connection))
+		Expect(globalUnsubscribersCollection.SetCall.Receives.UserGUID).To(Equal("some-user-guid"))
+	})
+
+	It("attributes the change to the client that owns the token", func() {
+		context.Set("token", &jwt.Token{Claims: map[string]interface{}{
+			"client_id": "some-client-id",
+		}})
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(globalUnsubscribersCollection.SetCall.Receives.Actor).To(Equal(models.Actor{
+			Type:   models.ActorClient,
+			ID:     "some-client-id",
+			Source: models.SourceAPI,
+		}))
+	})
+
+	Context("failure cases", func() {
+		It("returns a 404 when the user cannot be found", func() {
+			globalUnsubscribersCollection.SetCall.Returns.Error = collections.NotFoundError{errors.New(`User "some-user-guid" not found`)}
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusNotFound))
+			Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["User \"some-user-guid\" not found"]}`))
+		})
+
+		It("returns a 500 when an unknown error occurs", func() {
+			globalUnsubscribersCollection.SetCall.Returns.Error = collections.PersistenceError{errors.New("some-error")}
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusInternalServerError))
+			Expect(writer.Body.String()).To(MatchJSON(`{"errors": ["some-error"]}`))
+		})
+	})
+})
@@ -0,0 +1,93 @@
+package unsubscribers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+	"github.com/ryanmoran/stack"
+)
+
+type bulkImporter interface {
+	Import(conn collections.ConnectionInterface, unsubscribes []collections.BulkUnsubscribe, actor models.Actor) (collections.BulkUnsubscribeResult, error)
+}
+
+// MaxImportSize caps the request body of an import, in bytes.
+const MaxImportSize = 10 << 20
+
+type ImportHandler struct {
+	collection bulkImporter
+}
+
+func NewImportHandler(collection bulkImporter) ImportHandler {
+	return ImportHandler{
+		collection: collection,
+	}
+}
+
+func (h ImportHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	body, err := ioutil.ReadAll(io.LimitReader(req.Body, MaxImportSize+1))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, `{"errors": [%q]}`, err)
+		return
+	}
+
+	if len(body) > MaxImportSize {
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		fmt.Fprintf(w, `{"errors": [%q]}`, fmt.Sprintf("imports are limited to %d bytes", MaxImportSize))
+		return
+	}
+
+	var unsubscribes []collections.BulkUnsubscribe
+	if strings.HasPrefix(req.Header.Get("Content-Type"), csvContentType) {
+		unsubscribes, err = readCSV(bytes.NewReader(body))
+	} else {
+		unsubscribes, err = readNDJSON(bytes.NewReader(body))
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, `{"errors": [%q]}`, err)
+		return
+	}
+
+	actor := actorFor(context)
+	actor.Source = models.SourceImport
+
+	database := context.Get("database").(DatabaseInterface)
+
+	result, err := h.collection.Import(database.Connection(), unsubscribes, actor)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"errors": [%q]}`, err)
+		return
+	}
+
+	type rowError struct {
+		Row   int    `json:"row"`
+		Error string `json:"error"`
+	}
+
+	response := struct {
+		Imported int        `json:"imported"`
+		Errors   []rowError `json:"errors"`
+	}{
+		Imported: result.Imported,
+		Errors:   []rowError{},
+	}
+
+	for _, e := range result.Errors {
+		response.Errors = append(response.Errors, rowError{
+			Row:   e.Row,
+			Error: e.Error,
+		})
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
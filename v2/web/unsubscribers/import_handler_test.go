@@ -0,0 +1,196 @@
+package unsubscribers_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+	"github.com/cloudfoundry-incubator/notifications/v2/web/unsubscribers"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ImportHandler", func() {
+	var (
+		handler    unsubscribers.ImportHandler
+		writer     *httptest.ResponseRecorder
+		context    stack.Context
+		collection *mocks.BulkUnsubscribesCollection
+		database   *mocks.Database
+		connection *mocks.Connection
+	)
+
+	BeforeEach(func() {
+		collection = mocks.NewBulkUnsubscribesCollection()
+		collection.ImportCall.Returns.Result = collections.BulkUnsubscribeResult{
+			Imported: 2,
+			Errors: []collections.BulkUnsubscribeRowError{
+				{Row: 3, Error: "missing user_guid"},
+			},
+		}
+		handler = unsubscribers.NewImportHandler(collection)
+
+		connection = mocks.NewConnection()
+		database = mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = connection
+
+		context = stack.NewContext()
+		context.Set("database", database)
+		context.Set("token", &jwt.Token{Claims: map[string]interface{}{
+			"client_id": "some-client-id",
+		}})
+
+		writer = httptest.NewRecorder()
+	})
+
+	It("imports unsubscribes from NDJSON and reports the rows that failed", func() {
+		request, err := http.NewRequest("POST", "/unsubscribes/import", bytes.NewBufferString(`{"user_guid": "some-user-guid", "global": true}
+
+{"user_guid": "other-user-guid", "client_id": "some-client-id", "kind_id": "some-kind-id"}
+{"campaign_type_id": "some-campaign-type-id"}
+`))
+		Expect(err).NotTo(HaveOccurred())
+		request.Header.Set("Content-Type", "application/x-ndjson")
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.String()).To(MatchJSON(`{
+			"imported": 2,
+			"errors": [
+				{"row": 3, "error": "missing user_guid"}
+			]
+		}`))
+
+		Expect(collection.ImportCall.Receives.Connection).To(Equal(connection))
+		Expect(collection.ImportCall.Receives.Unsubscribes).To(Equal([]collections.BulkUnsubscribe{
+			{UserGUID: "some-user-guid", Global: true, Row: 1},
+			{UserGUID: "other-user-guid", ClientID: "some-client-id", KindID: "some-kind-id", Row: 3},
+			{CampaignTypeID: "some-campaign-type-id", Row: 4},
+		}))
+		Expect(collection.ImportCall.Receives.Actor).To(Equal(models.Actor{
+			Type:   models.ActorClient,
+			ID:     "some-client-id",
+			Source: models.SourceImport,
+		}))
+	})
+
+	It("imports unsubscribes from CSV", func() {
+		request, err := http.NewRequest("POST", "/unsubscribes/import", bytes.NewBufferString(`user_guid,campaign_type_id,client_id,kind_id,global
+some-user-guid,,,,true
+other-user-guid,some-campaign-type-id,,,
+third-user-guid,,some-client-id,some-kind-id,false
+`))
+		Expect(err).NotTo(HaveOccurred())
+		request.Header.Set("Content-Type", "text/csv")
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(collection.ImportCall.Receives.Unsubscribes).To(Equal([]collections.BulkUnsubscribe{
+			{UserGUID: "some-user-guid", Global: true},
+			{UserGUID: "other-user-guid", CampaignTypeID: "some-campaign-type-id"},
+			{UserGUID: "third-user-guid", ClientID: "some-client-id", KindID: "some-kind-id"},
+		}))
+	})
+
+	It("accepts CSV with only some of the columns", func() {
+		request, err := http.NewRequest("POST", "/unsubscribes/import", bytes.NewBufferString("campaign_type_id,user_guid\nsome-campaign-type-id,some-user-guid\n"))
+		Expect(err).NotTo(HaveOccurred())
+		request.Header.Set("Content-Type", "text/csv; charset=utf-8")
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(collection.ImportCall.Receives.Unsubscribes).To(Equal([]collections.BulkUnsubscribe{
+			{UserGUID: "some-user-guid", CampaignTypeID: "some-campaign-type-id"},
+		}))
+	})
+
+	Context("failure cases", func() {
+		It("returns a 400 when an NDJSON row is not valid json", func() {
+			request, err := http.NewRequest("POST", "/unsubscribes/import", bytes.NewBufferString("{\"user_guid\": \"some-user-guid\", \"global\": true}\n{{{\n"))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusBadRequest))
+			Expect(writer.Body.String()).To(MatchJSON(`{
+				"errors": ["invalid json on row 2"]
+			}`))
+		})
+
+		It("counts blank lines when numbering an invalid NDJSON row", func() {
+			request, err := http.NewRequest("POST", "/unsubscribes/import", bytes.NewBufferString("{\"user_guid\": \"some-user-guid\", \"global\": true}\n\n\n{{{\n"))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusBadRequest))
+			Expect(writer.Body.String()).To(MatchJSON(`{
+				"errors": ["invalid json on row 4"]
+			}`))
+		})
+
+		It("returns a 413 when the body is too large", func() {
+			body := bytes.Repeat([]byte("\n"), unsubscribers.MaxImportSize+1)
+			request, err := http.NewRequest("POST", "/unsubscribes/import", bytes.NewReader(body))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusRequestEntityTooLarge))
+			Expect(writer.Body.String()).To(MatchJSON(`{
+				"errors": ["imports are limited to 10485760 bytes"]
+			}`))
+			Expect(collection.ImportCall.Receives.Unsubscribes).To(BeNil())
+		})
+
+		It("returns a 400 when the CSV has an unknown column", func() {
+			request, err := http.NewRequest("POST", "/unsubscribes/import", bytes.NewBufferString("user_guid,email\nsome-user-guid,me@example.com\n"))
+			Expect(err).NotTo(HaveOccurred())
+			request.Header.Set("Content-Type", "text/csv")
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusBadRequest))
+			Expect(writer.Body.String()).To(MatchJSON(`{
+				"errors": ["unknown csv column \"email\""]
+			}`))
+		})
+
+		It("returns a 400 when a CSV global value is not a boolean", func() {
+			request, err := http.NewRequest("POST", "/unsubscribes/import", bytes.NewBufferString("user_guid,global\nsome-user-guid,yes please\n"))
+			Expect(err).NotTo(HaveOccurred())
+			request.Header.Set("Content-Type", "text/csv")
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusBadRequest))
+			Expect(writer.Body.String()).To(MatchJSON(`{
+				"errors": ["invalid global value \"yes please\" on row 1"]
+			}`))
+		})
+
+		It("returns a 500 when the import fails", func() {
+			collection.ImportCall.Returns.Error = collections.PersistenceError{errors.New("db is down")}
+
+			request, err := http.NewRequest("POST", "/unsubscribes/import", bytes.NewBufferString(`{"user_guid": "some-user-guid", "global": true}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusInternalServerError))
+			Expect(writer.Body.String()).To(MatchJSON(`{
+				"errors": ["db is down"]
+			}`))
+		})
+	})
+})
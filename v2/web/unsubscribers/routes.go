@@ -10,13 +10,20 @@ type muxer interface {
 }
 
 type Routes struct {
-	RequestLogging          stack.Middleware
-	Authenticator           stack.Middleware
-	DatabaseAllocator       stack.Middleware
-	UnsubscribersCollection collections.UnsubscribersCollection
+	RequestLogging                stack.Middleware
+	Authenticator                 stack.Middleware
+	AdminAuthenticator            stack.Middleware
+	DatabaseAllocator             stack.Middleware
+	UnsubscribersCollection       collections.UnsubscribersCollection
+	GlobalUnsubscribersCollection collections.GlobalUnsubscribersCollection
+	BulkUnsubscribesCollection    collections.BulkUnsubscribesCollection
 }
 
 func (r Routes) Register(m muxer) {
 	m.Handle("PUT", "/campaign_types/{campaign_type_id}/unsubscribers/{user_guid}", NewUpdateHandler(r.UnsubscribersCollection), r.RequestLogging, r.Authenticator, r.DatabaseAllocator)
 	m.Handle("DELETE", "/campaign_types/{campaign_type_id}/unsubscribers/{user_guid}", NewDeleteHandler(r.UnsubscribersCollection), r.RequestLogging, r.Authenticator, r.DatabaseAllocator)
+	m.Handle("PUT", "/global_unsubscribers/{user_guid}", NewGlobalUpdateHandler(r.GlobalUnsubscribersCollection), r.RequestLogging, r.Authenticator, r.DatabaseAllocator)
+	m.Handle("DELETE", "/global_unsubscribers/{user_guid}", NewGlobalDeleteHandler(r.GlobalUnsubscribersCollection), r.RequestLogging, r.Authenticator, r.DatabaseAllocator)
+	m.Handle("POST", "/unsubscribes/import", NewImportHandler(r.BulkUnsubscribesCollection), r.RequestLogging, r.AdminAuthenticator, r.DatabaseAllocator)
+	m.Handle("GET", "/unsubscribes/export", NewExportHandler(r.BulkUnsubscribesCollection), r.RequestLogging, r.AdminAuthenticator, r.DatabaseAllocator)
 }
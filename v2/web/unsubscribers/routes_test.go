@@ -21,19 +21,24 @@ var _ = Describe("Routes", func() {
 		logging     middleware.RequestLogging
 		dbAllocator middleware.DatabaseAllocator
 		auth        middleware.Authenticator
+		adminAuth   middleware.Authenticator
 		muxer       web.Muxer
 	)
 
 	BeforeEach(func() {
 		logging = middleware.NewRequestLogging(lager.NewLogger("log-prefix"), mocks.NewClock())
 		auth = middleware.NewAuthenticator("some-public-key", "notifications.write")
+		adminAuth = middleware.NewAuthenticator("some-public-key", "notifications.admin")
 		dbAllocator = middleware.NewDatabaseAllocator(&sql.DB{}, false)
 		muxer = web.NewMuxer()
 		unsubscribers.Routes{
-			RequestLogging:          logging,
-			Authenticator:           auth,
-			DatabaseAllocator:       dbAllocator,
-			UnsubscribersCollection: collections.UnsubscribersCollection{},
+			RequestLogging:                logging,
+			Authenticator:                 auth,
+			AdminAuthenticator:            adminAuth,
+			DatabaseAllocator:             dbAllocator,
+			UnsubscribersCollection:       collections.UnsubscribersCollection{},
+			GlobalUnsubscribersCollection: collections.GlobalUnsubscribersCollection{},
+			BulkUnsubscribesCollection:    collections.BulkUnsubscribesCollection{},
 		}.Register(muxer)
 	})
 
@@ -72,4 +77,76 @@ var _ = Describe("Routes", func() {
 		databaseAllocator := s.Middleware[2].(middleware.DatabaseAllocator)
 		Expect(databaseAllocator).To(Equal(dbAllocator))
 	})
+
+	It("routes PUT /global_unsubscribers/{user_guid}", func() {
+		request, err := http.NewRequest("PUT", "/global_unsubscribers/some-user-guid", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		s := muxer.Match(request).(stack.Stack)
+		Expect(s.Handler).To(BeAssignableToTypeOf(unsubscribers.GlobalUpdateHandler{}))
+		Expect(s.Middleware).To(HaveLen(3))
+
+		requestLogging := s.Middleware[0].(middleware.RequestLogging)
+		Expect(requestLogging).To(Equal(logging))
+
+		authenticator := s.Middleware[1].(middleware.Authenticator)
+		Expect(authenticator).To(Equal(auth))
+
+		databaseAllocator := s.Middleware[2].(middleware.DatabaseAllocator)
+		Expect(databaseAllocator).To(Equal(dbAllocator))
+	})
+
+	It("routes DELETE /global_unsubscribers/{user_guid}", func() {
+		request, err := http.NewRequest("DELETE", "/global_unsubscribers/some-user-guid", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		s := muxer.Match(request).(stack.Stack)
+		Expect(s.Handler).To(BeAssignableToTypeOf(unsubscribers.GlobalDeleteHandler{}))
+		Expect(s.Middleware).To(HaveLen(3))
+
+		requestLogging := s.Middleware[0].(middleware.RequestLogging)
+		Expect(requestLogging).To(Equal(logging))
+
+		authenticator := s.Middleware[1].(middleware.Authenticator)
+		Expect(authenticator).To(Equal(auth))
+
+		databaseAllocator := s.Middleware[2].(middleware.DatabaseAllocator)
+		Expect(databaseAllocator).To(Equal(dbAllocator))
+	})
+
+	It("routes POST /unsubscribes/import", func() {
+		request, err := http.NewRequest("POST", "/unsubscribes/import", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		s := muxer.Match(request).(stack.Stack)
+		Expect(s.Handler).To(BeAssignableToTypeOf(unsubscribers.ImportHandler{}))
+		Expect(s.Middleware).To(HaveLen(3))
+
+		requestLogging := s.Middleware[0].(middleware.RequestLogging)
+		Expect(requestLogging).To(Equal(logging))
+
+		authenticator := s.Middleware[1].(middleware.Authenticator)
+		Expect(authenticator).To(Equal(adminAuth))
+
+		databaseAllocator := s.Middleware[2].(middleware.DatabaseAllocator)
+		Expect(databaseAllocator).To(Equal(dbAllocator))
+	})
+
+	It("routes GET /unsubscribes/export", func() {
+		request, err := http.NewRequest("GET", "/unsubscribes/export", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		s := muxer.Match(request).(stack.Stack)
+		Expect(s.Handler).To(BeAssignableToTypeOf(unsubscribers.ExportHandler{}))
+		Expect(s.Middleware).To(HaveLen(3))
+
+		requestLogging := s.Middleware[0].(middleware.RequestLogging)
+		Expect(requestLogging).To(Equal(logging))
+
+		authenticator := s.Middleware[1].(middleware.Authenticator)
+		Expect(authenticator).To(Equal(adminAuth))
+
+		databaseAllocator := s.Middleware[2].(middleware.DatabaseAllocator)
+		Expect(databaseAllocator).To(Equal(dbAllocator))
+	})
 })
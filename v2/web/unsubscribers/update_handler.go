@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
 	"github.com/ryanmoran/stack"
 )
 
@@ -14,7 +15,7 @@ type UpdateHandler struct {
 }
 
 type unsubscribersGetSetter interface {
-	Set(connection collections.ConnectionInterface, unsubscriber collections.Unsubscriber) (collections.Unsubscriber, error)
+	Set(connection collections.ConnectionInterface, unsubscriber collections.Unsubscriber, actor models.Actor) (collections.Unsubscriber, error)
 }
 
 func NewUpdateHandler(collection unsubscribersGetSetter) UpdateHandler {
@@ -32,7 +33,7 @@ func (h UpdateHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, conte
 	_, err := h.collection.Set(database.Connection(), collections.Unsubscriber{
 		CampaignTypeID: campaignTypeID,
 		UserGUID:       userGUID,
-	})
+	}, actorFor(context))
 	if err != nil {
 		switch err.(type) {
 		case collections.NotFoundError:
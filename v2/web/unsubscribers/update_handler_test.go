@@ -7,7 +7,9 @@ import (
 
 	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
 	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
 	"github.com/cloudfoundry-incubator/notifications/v2/web/unsubscribers"
+	"github.com/dgrijalva/jwt-go"
 	"github.com/ryanmoran/stack"
 
 	. "github.com/onsi/ginkgo"
@@ -56,6 +58,21 @@ var _ = Describe("UpdateHandler", func() {
 		Expect(unsubscribersCollection.SetCall.Receives.Connection).To(Equal(connection))
 	})
 
+	It("attributes the change to the user that owns the token", func() {
+		context.Set("token", &jwt.Token{Claims: map[string]interface{}{
+			"client_id": "some-client-id",
+			"user_id":   "some-user-guid",
+		}})
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(unsubscribersCollection.SetCall.Receives.Actor).To(Equal(models.Actor{
+			Type:   models.ActorUser,
+			ID:     "some-user-guid",
+			Source: models.SourceAPI,
+		}))
+	})
+
 	Context("when an error occurs", func() {
 		Context("when the Set call returns a NotFoundError", func() {
 			It("returns a 404 with the error message in JSON", func() {
@@ -0,0 +1,49 @@
+package userpreferences
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/ryanmoran/stack"
+)
+
+type preferencesLister interface {
+	List(conn collections.ConnectionInterface, userGUID string) (collections.UserPreferences, error)
+}
+
+type GetHandler struct {
+	preferences preferencesLister
+}
+
+func NewGetHandler(preferences preferencesLister) GetHandler {
+	return GetHandler{
+		preferences: preferences,
+	}
+}
+
+func (h GetHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	userGUID := userGUIDFor(req, context)
+	if userGUID == "" {
+		w.WriteHeader(422)
+		fmt.Fprintf(w, `{"errors": [%q]}`, "a user_guid is required when not using a user token")
+		return
+	}
+
+	database := context.Get("database").(DatabaseInterface)
+
+	preferences, err := h.preferences.List(database.Connection(), userGUID)
+	if err != nil {
+		switch err.(type) {
+		case collections.NotFoundError:
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		fmt.Fprintf(w, `{"errors": [%q]}`, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(NewPreferencesResponse(preferences))
+}
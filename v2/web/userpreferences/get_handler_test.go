@@ -0,0 +1,156 @@
+package userpreferences_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/cloudfoundry-incubator/notifications/v2/web/userpreferences"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GetHandler", func() {
+	var (
+		handler     userpreferences.GetHandler
+		writer      *httptest.ResponseRecorder
+		request     *http.Request
+		conn        *mocks.Connection
+		database    *mocks.Database
+		preferences *mocks.UserPreferencesCollection
+		context     stack.Context
+	)
+
+	BeforeEach(func() {
+		writer = httptest.NewRecorder()
+
+		conn = mocks.NewConnection()
+		database = mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = conn
+
+		context = stack.NewContext()
+		context.Set("database", database)
+		context.Set("token", &jwt.Token{Claims: map[string]interface{}{
+			"client_id": "some-client-id",
+		}})
+
+		var err error
+		request, err = http.NewRequest("GET", "/user_preferences/some-user-guid", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		preferences = mocks.NewUserPreferencesCollection()
+		preferences.ListCall.Returns.Preferences = collections.UserPreferences{
+			UserGUID: "some-user-guid",
+			Senders: []collections.SenderPreferences{
+				{
+					ID:   "some-sender-id",
+					Name: "some-sender",
+					CampaignTypes: []collections.CampaignTypePreference{
+						{ID: "some-campaign-type-id", Name: "some-campaign-type", Description: "a campaign type", Subscribed: true, Digest: "immediate"},
+						{ID: "other-campaign-type-id", Name: "other-campaign-type", Description: "another campaign type", Subscribed: false, Digest: "hourly"},
+					},
+				},
+			},
+		}
+
+		handler = userpreferences.NewGetHandler(preferences)
+	})
+
+	It("returns the preferences of the user named in the route", func() {
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.String()).To(MatchJSON(`{
+			"user_guid": "some-user-guid",
+			"global_unsubscribe": false,
+			"time_zone": "",
+			"quiet_hours": {"start": "", "end": ""},
+			"senders": [
+				{
+					"id": "some-sender-id",
+					"name": "some-sender",
+					"campaign_types": [
+						{
+							"id": "some-campaign-type-id",
+							"name": "some-campaign-type",
+							"description": "a campaign type",
+							"subscribed": true,
+							"digest": "immediate"
+						},
+						{
+							"id": "other-campaign-type-id",
+							"name": "other-campaign-type",
+							"description": "another campaign type",
+							"subscribed": false,
+							"digest": "hourly"
+						}
+					]
+				}
+			],
+			"_links": {
+				"self": {"href": "/user_preferences/some-user-guid"}
+			}
+		}`))
+
+		Expect(preferences.ListCall.Receives.Connection).To(Equal(conn))
+		Expect(preferences.ListCall.Receives.UserGUID).To(Equal("some-user-guid"))
+	})
+
+	It("returns the preferences of the user that owns the token", func() {
+		context.Set("token", &jwt.Token{Claims: map[string]interface{}{
+			"client_id": "some-client-id",
+			"user_id":   "token-user-guid",
+		}})
+
+		var err error
+		request, err = http.NewRequest("GET", "/user_preferences", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(preferences.ListCall.Receives.UserGUID).To(Equal("token-user-guid"))
+	})
+
+	Context("failure cases", func() {
+		It("returns a 422 when a client token does not name a user", func() {
+			var err error
+			request, err = http.NewRequest("GET", "/user_preferences", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(422))
+			Expect(writer.Body.String()).To(MatchJSON(`{
+				"errors": ["a user_guid is required when not using a user token"]
+			}`))
+		})
+
+		It("returns a 404 when the user cannot be found", func() {
+			preferences.ListCall.Returns.Error = collections.NotFoundError{errors.New(`User "some-user-guid" not found`)}
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusNotFound))
+			Expect(writer.Body.String()).To(MatchJSON(`{
+				"errors": ["User \"some-user-guid\" not found"]
+			}`))
+		})
+
+		It("returns a 500 when the preferences cannot be listed", func() {
+			preferences.ListCall.Returns.Error = collections.PersistenceError{errors.New("something bad happened")}
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusInternalServerError))
+			Expect(writer.Body.String()).To(MatchJSON(`{
+				"errors": ["something bad happened"]
+			}`))
+		})
+	})
+})
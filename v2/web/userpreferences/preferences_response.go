@@ -0,0 +1,79 @@
+package userpreferences
+
+import (
+	"fmt"
+
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+)
+
+type Link struct {
+	Href string `json:"href"`
+}
+
+type PreferencesResponseLinks struct {
+	Self Link `json:"self"`
+}
+
+type CampaignTypePreferenceResponse struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Subscribed  bool   `json:"subscribed"`
+	Digest      string `json:"digest"`
+}
+
+type SenderPreferencesResponse struct {
+	ID            string                           `json:"id"`
+	Name          string                           `json:"name"`
+	CampaignTypes []CampaignTypePreferenceResponse `json:"campaign_types"`
+}
+
+type QuietHoursResponse struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+type PreferencesResponse struct {
+	UserGUID          string                      `json:"user_guid"`
+	GlobalUnsubscribe bool                        `json:"global_unsubscribe"`
+	TimeZone          string                      `json:"time_zone"`
+	QuietHours        QuietHoursResponse          `json:"quiet_hours"`
+	Senders           []SenderPreferencesResponse `json:"senders"`
+	Links             PreferencesResponseLinks    `json:"_links"`
+}
+
+func NewPreferencesResponse(preferences collections.UserPreferences) PreferencesResponse {
+	response := PreferencesResponse{
+		UserGUID:          preferences.UserGUID,
+		GlobalUnsubscribe: preferences.GlobalUnsubscribe,
+		TimeZone:          preferences.TimeZone,
+		Senders:           []SenderPreferencesResponse{},
+		Links:             PreferencesResponseLinks{Link{fmt.Sprintf("/user_preferences/%s", preferences.UserGUID)}},
+		QuietHours: QuietHoursResponse{
+			Start: preferences.QuietHours.Start,
+			End:   preferences.QuietHours.End,
+		},
+	}
+
+	for _, sender := range preferences.Senders {
+		senderResponse := SenderPreferencesResponse{
+			ID:            sender.ID,
+			Name:          sender.Name,
+			CampaignTypes: []CampaignTypePreferenceResponse{},
+		}
+
+		for _, campaignType := range sender.CampaignTypes {
+			senderResponse.CampaignTypes = append(senderResponse.CampaignTypes, CampaignTypePreferenceResponse{
+				ID:          campaignType.ID,
+				Name:        campaignType.Name,
+				Description: campaignType.Description,
+				Subscribed:  campaignType.Subscribed,
+				Digest:      campaignType.Digest,
+			})
+		}
+
+		response.Senders = append(response.Senders, senderResponse)
+	}
+
+	return response
+}
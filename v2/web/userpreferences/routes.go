@@ -0,0 +1,25 @@
+package userpreferences
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/ryanmoran/stack"
+)
+
+type muxer interface {
+	Handle(method, path string, handler stack.Handler, middleware ...stack.Middleware)
+}
+
+type Routes struct {
+	RequestLogging            stack.Middleware
+	Authenticator             stack.Middleware
+	TokenUserAuthenticator    stack.Middleware
+	DatabaseAllocator         stack.Middleware
+	UserPreferencesCollection collections.UserPreferencesCollection
+}
+
+func (r Routes) Register(m muxer) {
+	m.Handle("GET", "/user_preferences", NewGetHandler(r.UserPreferencesCollection), r.RequestLogging, r.TokenUserAuthenticator, r.DatabaseAllocator)
+	m.Handle("PATCH", "/user_preferences", NewUpdateHandler(r.UserPreferencesCollection), r.RequestLogging, r.TokenUserAuthenticator, r.DatabaseAllocator)
+	m.Handle("GET", "/user_preferences/{user_guid}", NewGetHandler(r.UserPreferencesCollection), r.RequestLogging, r.Authenticator, r.DatabaseAllocator)
+	m.Handle("PATCH", "/user_preferences/{user_guid}", NewUpdateHandler(r.UserPreferencesCollection), r.RequestLogging, r.Authenticator, r.DatabaseAllocator)
+}
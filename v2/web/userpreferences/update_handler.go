@@ -0,0 +1,127 @@
+package userpreferences
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+	"github.com/ryanmoran/stack"
+)
+
+type preferencesUpdater interface {
+	Update(conn collections.ConnectionInterface, userGUID string, campaignTypePreferences []collections.CampaignTypePreference,
+		globalUnsubscribe *bool, timeZone *string, quietHours *collections.QuietHours, actor models.Actor) (collections.UserPreferences, error)
+}
+
+type UpdateHandler struct {
+	preferences preferencesUpdater
+}
+
+func NewUpdateHandler(preferences preferencesUpdater) UpdateHandler {
+	return UpdateHandler{
+		preferences: preferences,
+	}
+}
+
+func (h UpdateHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	userGUID := userGUIDFor(req, context)
+	if userGUID == "" {
+		w.WriteHeader(422)
+		fmt.Fprintf(w, `{"errors": [%q]}`, "a user_guid is required when not using a user token")
+		return
+	}
+
+	var updateRequest struct {
+		GlobalUnsubscribe *bool   `json:"global_unsubscribe"`
+		TimeZone          *string `json:"time_zone"`
+		QuietHours        *struct {
+			Start string `json:"start"`
+			End   string `json:"end"`
+		} `json:"quiet_hours"`
+		CampaignTypes []struct {
+			ID         string  `json:"id"`
+			Subscribed *bool   `json:"subscribed"`
+			Digest     *string `json:"digest"`
+		} `json:"campaign_types"`
+	}
+
+	err := json.NewDecoder(req.Body).Decode(&updateRequest)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, `{"errors": [%q]}`, "invalid json body")
+		return
+	}
+
+	campaignTypePreferences := []collections.CampaignTypePreference{}
+	for _, campaignType := range updateRequest.CampaignTypes {
+		if campaignType.ID == "" {
+			w.WriteHeader(422)
+			fmt.Fprintf(w, `{"errors": [%q]}`, "missing campaign type id")
+			return
+		}
+
+		if campaignType.Subscribed == nil {
+			w.WriteHeader(422)
+			fmt.Fprintf(w, `{"errors": [%q]}`, fmt.Sprintf("missing subscribed flag for campaign type %q", campaignType.ID))
+			return
+		}
+
+		preference := collections.CampaignTypePreference{
+			ID:         campaignType.ID,
+			Subscribed: *campaignType.Subscribed,
+		}
+
+		if campaignType.Digest != nil {
+			if !collections.ValidDigestFrequency(*campaignType.Digest) {
+				w.WriteHeader(422)
+				fmt.Fprintf(w, `{"errors": [%q]}`, fmt.Sprintf("invalid digest frequency %q for campaign type %q", *campaignType.Digest, campaignType.ID))
+				return
+			}
+
+			preference.Digest = *campaignType.Digest
+		}
+
+		campaignTypePreferences = append(campaignTypePreferences, preference)
+	}
+
+	if updateRequest.TimeZone != nil && !collections.ValidTimeZone(*updateRequest.TimeZone) {
+		w.WriteHeader(422)
+		fmt.Fprintf(w, `{"errors": [%q]}`, fmt.Sprintf("invalid time zone %q", *updateRequest.TimeZone))
+		return
+	}
+
+	var quietHours *collections.QuietHours
+	if updateRequest.QuietHours != nil {
+		start, end := updateRequest.QuietHours.Start, updateRequest.QuietHours.End
+		if (start != "" || end != "") && !(collections.ValidClockTime(start) && collections.ValidClockTime(end)) {
+			w.WriteHeader(422)
+			fmt.Fprintf(w, `{"errors": [%q]}`, "quiet hours must have a start and end in HH:MM format")
+			return
+		}
+
+		quietHours = &collections.QuietHours{
+			Start: start,
+			End:   end,
+		}
+	}
+
+	database := context.Get("database").(DatabaseInterface)
+
+	preferences, err := h.preferences.Update(database.Connection(), userGUID, campaignTypePreferences, updateRequest.GlobalUnsubscribe, updateRequest.TimeZone, quietHours, actorFor(context))
+	if err != nil {
+		switch err.(type) {
+		case collections.NotFoundError:
+			w.WriteHeader(http.StatusNotFound)
+		case collections.PermissionsError:
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		fmt.Fprintf(w, `{"errors": [%q]}`, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(NewPreferencesResponse(preferences))
+}
@@ -0,0 +1,281 @@
+package userpreferences_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v2/collections"
+	"github.com/cloudfoundry-incubator/notifications/v2/models"
+	"github.com/cloudfoundry-incubator/notifications/v2/web/userpreferences"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("UpdateHandler", func() {
+	var (
+		handler     userpreferences.UpdateHandler
+		writer      *httptest.ResponseRecorder
+		request     *http.Request
+		conn        *mocks.Connection
+		database    *mocks.Database
+		preferences *mocks.UserPreferencesCollection
+		context     stack.Context
+	)
+
+	BeforeEach(func() {
+		writer = httptest.NewRecorder()
+
+		conn = mocks.NewConnection()
+		database = mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = conn
+
+		context = stack.NewContext()
+		context.Set("database", database)
+		context.Set("token", &jwt.Token{Claims: map[string]interface{}{
+			"client_id": "some-client-id",
+			"user_id":   "some-user-guid",
+		}})
+
+		requestBody := []byte(`{
+			"global_unsubscribe": true,
+			"time_zone": "America/Chicago",
+			"quiet_hours": {"start": "22:00", "end": "07:00"},
+			"campaign_types": [
+				{"id": "some-campaign-type-id", "subscribed": false, "digest": "daily"},
+				{"id": "other-campaign-type-id", "subscribed": true}
+			]
+		}`)
+
+		var err error
+		request, err = http.NewRequest("PATCH", "/user_preferences", bytes.NewBuffer(requestBody))
+		Expect(err).NotTo(HaveOccurred())
+
+		preferences = mocks.NewUserPreferencesCollection()
+		preferences.UpdateCall.Returns.Preferences = collections.UserPreferences{
+			UserGUID:          "some-user-guid",
+			GlobalUnsubscribe: true,
+			TimeZone:          "America/Chicago",
+			QuietHours: collections.QuietHours{
+				Start: "22:00",
+				End:   "07:00",
+			},
+			Senders: []collections.SenderPreferences{
+				{
+					ID:   "some-sender-id",
+					Name: "some-sender",
+					CampaignTypes: []collections.CampaignTypePreference{
+						{ID: "some-campaign-type-id", Name: "some-campaign-type", Subscribed: false, Digest: "daily"},
+					},
+				},
+			},
+		}
+
+		handler = userpreferences.NewUpdateHandler(preferences)
+	})
+
+	It("updates the user's preferences and returns them", func() {
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.String()).To(MatchJSON(`{
+			"user_guid": "some-user-guid",
+			"global_unsubscribe": true,
+			"time_zone": "America/Chicago",
+			"quiet_hours": {"start": "22:00", "end": "07:00"},
+			"senders": [
+				{
+					"id": "some-sender-id",
+					"name": "some-sender",
+					"campaign_types": [
+						{
+							"id": "some-campaign-type-id",
+							"name": "some-campaign-type",
+							"description": "",
+							"subscribed": false,
+							"digest": "daily"
+						}
+					]
+				}
+			],
+			"_links": {
+				"self": {"href": "/user_preferences/some-user-guid"}
+			}
+		}`))
+
+		Expect(preferences.UpdateCall.Receives.Connection).To(Equal(conn))
+		Expect(preferences.UpdateCall.Receives.UserGUID).To(Equal("some-user-guid"))
+		Expect(preferences.UpdateCall.Receives.CampaignTypePreferences).To(Equal([]collections.CampaignTypePreference{
+			{ID: "some-campaign-type-id", Subscribed: false, Digest: "daily"},
+			{ID: "other-campaign-type-id", Subscribed: true},
+		}))
+		Expect(*preferences.UpdateCall.Receives.GlobalUnsubscribe).To(BeTrue())
+		Expect(*preferences.UpdateCall.Receives.TimeZone).To(Equal("America/Chicago"))
+		Expect(*preferences.UpdateCall.Receives.QuietHours).To(Equal(collections.QuietHours{
+			Start: "22:00",
+			End:   "07:00",
+		}))
+		Expect(preferences.UpdateCall.Receives.Actor).To(Equal(models.Actor{
+			Type:   models.ActorUser,
+			ID:     "some-user-guid",
+			Source: models.SourceAPI,
+		}))
+	})
+
+	It("clears the quiet hours when the start and end are empty", func() {
+		var err error
+		request, err = http.NewRequest("PATCH", "/user_preferences", bytes.NewBuffer([]byte(`{"quiet_hours": {"start": "", "end": ""}}`)))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(*preferences.UpdateCall.Receives.QuietHours).To(Equal(collections.QuietHours{}))
+		Expect(preferences.UpdateCall.Receives.TimeZone).To(BeNil())
+	})
+
+	It("updates the preferences of the user named in the route", func() {
+		var err error
+		request, err = http.NewRequest("PATCH", "/user_preferences/other-user-guid", bytes.NewBuffer([]byte(`{"campaign_types": []}`)))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(preferences.UpdateCall.Receives.UserGUID).To(Equal("other-user-guid"))
+		Expect(preferences.UpdateCall.Receives.GlobalUnsubscribe).To(BeNil())
+		Expect(preferences.UpdateCall.Receives.TimeZone).To(BeNil())
+		Expect(preferences.UpdateCall.Receives.QuietHours).To(BeNil())
+	})
+
+	Context("failure cases", func() {
+		It("returns a 400 when the body is not valid json", func() {
+			var err error
+			request, err = http.NewRequest("PATCH", "/user_preferences", bytes.NewBuffer([]byte(`{{{`)))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusBadRequest))
+			Expect(writer.Body.String()).To(MatchJSON(`{
+				"errors": ["invalid json body"]
+			}`))
+		})
+
+		It("returns a 422 when a campaign type id is missing", func() {
+			var err error
+			request, err = http.NewRequest("PATCH", "/user_preferences", bytes.NewBuffer([]byte(`{"campaign_types": [{"subscribed": true}]}`)))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(422))
+			Expect(writer.Body.String()).To(MatchJSON(`{
+				"errors": ["missing campaign type id"]
+			}`))
+		})
+
+		It("returns a 422 when the subscribed flag is missing", func() {
+			var err error
+			request, err = http.NewRequest("PATCH", "/user_preferences", bytes.NewBuffer([]byte(`{"campaign_types": [{"id": "some-campaign-type-id"}]}`)))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(422))
+			Expect(writer.Body.String()).To(MatchJSON(`{
+				"errors": ["missing subscribed flag for campaign type \"some-campaign-type-id\""]
+			}`))
+		})
+
+		It("returns a 422 when the digest frequency is not valid", func() {
+			var err error
+			request, err = http.NewRequest("PATCH", "/user_preferences", bytes.NewBuffer([]byte(`{"campaign_types": [{"id": "some-campaign-type-id", "subscribed": true, "digest": "weekly"}]}`)))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(422))
+			Expect(writer.Body.String()).To(MatchJSON(`{
+				"errors": ["invalid digest frequency \"weekly\" for campaign type \"some-campaign-type-id\""]
+			}`))
+		})
+
+		It("returns a 422 when the time zone is not valid", func() {
+			var err error
+			request, err = http.NewRequest("PATCH", "/user_preferences", bytes.NewBuffer([]byte(`{"time_zone": "Mars/Olympus_Mons"}`)))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(422))
+			Expect(writer.Body.String()).To(MatchJSON(`{
+				"errors": ["invalid time zone \"Mars/Olympus_Mons\""]
+			}`))
+		})
+
+		It("returns a 422 when the quiet hours are not valid", func() {
+			var err error
+			request, err = http.NewRequest("PATCH", "/user_preferences", bytes.NewBuffer([]byte(`{"quiet_hours": {"start": "10pm", "end": "07:00"}}`)))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(422))
+			Expect(writer.Body.String()).To(MatchJSON(`{
+				"errors": ["quiet hours must have a start and end in HH:MM format"]
+			}`))
+		})
+
+		It("returns a 422 when a client token does not name a user", func() {
+			context.Set("token", &jwt.Token{Claims: map[string]interface{}{
+				"client_id": "some-client-id",
+			}})
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(422))
+			Expect(writer.Body.String()).To(MatchJSON(`{
+				"errors": ["a user_guid is required when not using a user token"]
+			}`))
+		})
+
+		It("returns a 404 when the campaign type cannot be found", func() {
+			preferences.UpdateCall.Returns.Error = collections.NotFoundError{errors.New("Campaign type not found")}
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusNotFound))
+			Expect(writer.Body.String()).To(MatchJSON(`{
+				"errors": ["Campaign type not found"]
+			}`))
+		})
+
+		It("returns a 403 when the campaign type is critical", func() {
+			preferences.UpdateCall.Returns.Error = collections.PermissionsError{errors.New(`Campaign type "some-campaign-type-id" cannot be unsubscribed from`)}
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusForbidden))
+			Expect(writer.Body.String()).To(MatchJSON(`{
+				"errors": ["Campaign type \"some-campaign-type-id\" cannot be unsubscribed from"]
+			}`))
+		})
+
+		It("returns a 500 when the preferences cannot be saved", func() {
+			preferences.UpdateCall.Returns.Error = collections.PersistenceError{errors.New("something bad happened")}
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusInternalServerError))
+			Expect(writer.Body.String()).To(MatchJSON(`{
+				"errors": ["something bad happened"]
+			}`))
+		})
+	})
+})
@@ -0,0 +1,26 @@
+package userpreferences
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/ryanmoran/stack"
+)
+
+// userGUIDFor returns the user named in the route, falling back to the user
+// that owns the token for requests made to /user_preferences.
+func userGUIDFor(req *http.Request, context stack.Context) string {
+	splitURL := strings.Split(req.URL.Path, "/")
+	if len(splitURL) > 2 {
+		return splitURL[2]
+	}
+
+	token, ok := context.Get("token").(*jwt.Token)
+	if !ok {
+		return ""
+	}
+
+	userGUID, _ := token.Claims["user_id"].(string)
+	return userGUID
+}
@@ -0,0 +1,50 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/metrics"
+)
+
+// NewMetricsHandler serves the metrics registry at /metrics and nothing else,
+// for the listener on MetricsPort.
+func NewMetricsHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.DefaultRegistry)
+
+	return mux
+}
+
+// MetricsRouter times every request handled by the wrapped router. The
+// metrics themselves are served on their own port; see Server.Run.
+type MetricsRouter struct {
+	Router http.Handler
+}
+
+func (mr MetricsRouter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	then := time.Now()
+	recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+	mr.Router.ServeHTTP(recorder, req)
+
+	metrics.NewMetric("histogram", map[string]interface{}{
+		"name": "notifications.web.duration",
+		"tags": map[string]string{
+			"method": req.Method,
+			"status": strconv.Itoa(recorder.status),
+		},
+		"value": time.Now().Sub(then).Seconds(),
+	}).Log()
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
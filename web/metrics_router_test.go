@@ -0,0 +1,97 @@
+package web_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/notifications/metrics"
+	"github.com/cloudfoundry-incubator/notifications/web"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MetricsHandler", func() {
+	var (
+		handler http.Handler
+		writer  *httptest.ResponseRecorder
+	)
+
+	BeforeEach(func() {
+		handler = web.NewMetricsHandler()
+		writer = httptest.NewRecorder()
+	})
+
+	It("serves the metrics registry at /metrics", func() {
+		metrics.NewMetric("counter", map[string]interface{}{
+			"name": "notifications.metrics-handler",
+		}).Log()
+
+		request, err := http.NewRequest("GET", "/metrics", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.String()).To(ContainSubstring("notifications_metrics_handler_total 1"))
+	})
+
+	It("does not answer on any other path", func() {
+		for _, path := range []string{"/", "/info", "/notifications", "/metrics/extra"} {
+			writer = httptest.NewRecorder()
+
+			request, err := http.NewRequest("GET", path, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request)
+
+			Expect(writer.Code).To(Equal(http.StatusNotFound), path)
+			Expect(writer.Body.String()).NotTo(ContainSubstring("notifications_"), path)
+		}
+	})
+})
+
+var _ = Describe("MetricsRouter", func() {
+	var (
+		routerCalled bool
+		router       web.MetricsRouter
+		writer       *httptest.ResponseRecorder
+	)
+
+	BeforeEach(func() {
+		routerCalled = false
+		writer = httptest.NewRecorder()
+		router = web.MetricsRouter{
+			Router: http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				routerCalled = true
+				w.WriteHeader(http.StatusTeapot)
+			}),
+		}
+	})
+
+	It("does not serve the metrics on the API port", func() {
+		request, err := http.NewRequest("GET", "/metrics", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		router.ServeHTTP(writer, request)
+
+		Expect(routerCalled).To(BeTrue())
+		Expect(writer.Code).To(Equal(http.StatusTeapot))
+	})
+
+	It("passes every request to the router and times it", func() {
+		request, err := http.NewRequest("POST", "/notifications", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		router.ServeHTTP(writer, request)
+
+		Expect(routerCalled).To(BeTrue())
+		Expect(writer.Code).To(Equal(http.StatusTeapot))
+
+		buffer := bytes.NewBuffer([]byte{})
+		_, err = metrics.DefaultRegistry.WriteTo(buffer)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(buffer.String()).To(ContainSubstring(`notifications_web_duration_seconds_count{method="POST",status="418"} 1`))
+	})
+})
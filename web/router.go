@@ -22,8 +22,14 @@ func NewRouter(mother MotherInterface, config Config) http.Handler {
 		Logger:           config.Logger,
 		VerifySSL:        !config.SkipVerifySSL,
 		CCHost:           config.CCHost,
+		CCAPIVersion:     config.CCAPIVersion,
 		CORSOrigin:       config.CORSOrigin,
 		SQLDB:            config.SQLDB,
+		EncryptionKey:    config.EncryptionKey,
+		LookupCache:      config.LookupCache,
+
+		UAAHost:               config.UAAHost,
+		PreferencesTemplateID: config.PreferencesTemplateID,
 	})
 
 	v2 := v2web.NewRouter(NewMuxer(), v2web.Config{
@@ -37,10 +43,15 @@ func NewRouter(mother MotherInterface, config Config) http.Handler {
 		UAAClientID:      config.UAAClientID,
 		UAAClientSecret:  config.UAAClientSecret,
 		CCHost:           config.CCHost,
+		CCAPIVersion:     config.CCAPIVersion,
+		DefaultUAAScopes: config.DefaultUAAScopes,
+		LookupCache:      config.LookupCache,
 	})
 
-	return VersionRouter{
-		1: v1,
-		2: v2,
+	return MetricsRouter{
+		Router: VersionRouter{
+			1: v1,
+			2: v2,
+		},
 	}
 }
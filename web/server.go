@@ -5,6 +5,8 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/cloudfoundry-incubator/notifications/cache"
+
 	"github.com/pivotal-golang/lager"
 )
 
@@ -12,6 +14,7 @@ type Config struct {
 	DBLoggingEnabled     bool
 	SkipVerifySSL        bool
 	Port                 int
+	MetricsPort          int
 	CORSOrigin           string
 	QueueWaitMaxDuration int
 	SQLDB                *sql.DB
@@ -23,6 +26,11 @@ type Config struct {
 	UAAClientSecret  string
 	DefaultUAAScopes []string
 	CCHost           string
+	CCAPIVersion     int
+	EncryptionKey    []byte
+	LookupCache      cache.Cache
+
+	PreferencesTemplateID string
 }
 
 type Server struct{}
@@ -31,7 +39,23 @@ func NewServer() Server {
 	return Server{}
 }
 
+// Run serves the API on Port. The metrics registry is not authenticated, so
+// it is only served, on a separate listener, when MetricsPort is set. The
+// port defaults to 0, which leaves the metrics listener off.
 func (s Server) Run(mother MotherInterface, config Config) {
+	if config.MetricsPort != 0 {
+		config.Logger.Info("metrics-listen-and-serve", lager.Data{
+			"port": config.MetricsPort,
+		})
+
+		go func() {
+			err := http.ListenAndServe(":"+strconv.Itoa(config.MetricsPort), NewMetricsHandler())
+			config.Logger.Error("metrics-listen-and-serve-failed", err, lager.Data{
+				"port": config.MetricsPort,
+			})
+		}()
+	}
+
 	config.Logger.Info("listen-and-serve", lager.Data{
 		"port": config.Port,
 	})